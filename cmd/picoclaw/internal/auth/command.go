@@ -18,6 +18,7 @@ func NewAuthCommand() *cobra.Command {
 		newModelsCommand(),
 		newWeixinCommand(),
 		newWeComCommand(),
+		newSignalCommand(),
 	)
 
 	return cmd
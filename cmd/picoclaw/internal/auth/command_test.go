@@ -34,6 +34,7 @@ func TestNewAuthCommand(t *testing.T) {
 		"models",
 		"weixin",
 		"wecom",
+		"signal",
 	}
 
 	subcommands := cmd.Commands()
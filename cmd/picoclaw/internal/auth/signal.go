@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mdp/qrterminal/v3"
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/channels/signal"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newSignalCommand() *cobra.Command {
+	var url string
+	var deviceName string
+	var timeout int
+
+	cmd := &cobra.Command{
+		Use:   "signal",
+		Short: "Link a Signal account via QR code",
+		Long: `Link picoclaw to a Signal account as a secondary device.
+
+Requires a signal-cli daemon running in multi-account mode, for example:
+  signal-cli daemon --http 127.0.0.1:8080
+
+A QR code is displayed in the terminal. Scan it from the Signal app under
+Settings > Linked devices. On success, the account number is saved to the
+picoclaw config so you can start the gateway immediately.
+
+Example:
+  picoclaw auth signal`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runSignalLink(url, deviceName, time.Duration(timeout)*time.Second)
+		},
+	}
+
+	cmd.Flags().StringVar(&url, "url", signal.DefaultURL, "signal-cli daemon URL (http://, tcp:// or unix://)")
+	cmd.Flags().StringVar(&deviceName, "device-name", "PicoClaw", "Name shown in the Signal app's linked devices")
+	cmd.Flags().IntVar(&timeout, "timeout", 300, "Link timeout in seconds")
+
+	return cmd
+}
+
+func runSignalLink(url, deviceName string, timeout time.Duration) error {
+	fmt.Println("Requesting Signal device link...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	uri, err := signal.StartLink(ctx, url)
+	if err != nil {
+		return fmt.Errorf("could not start linking (is signal-cli daemon running at %s?): %w", url, err)
+	}
+
+	fmt.Println()
+	fmt.Println("=======================================================")
+	fmt.Println("Scan this QR code in Signal > Settings > Linked devices:")
+	fmt.Println("=======================================================")
+	fmt.Println()
+	qrterminal.GenerateWithConfig(uri, qrterminal.Config{
+		Level:      qrterminal.L,
+		Writer:     os.Stdout,
+		HalfBlocks: true,
+	})
+	fmt.Println()
+	fmt.Println("Waiting for scan...")
+
+	account, err := signal.FinishLink(ctx, url, uri, deviceName)
+	if err != nil {
+		return fmt.Errorf("link failed: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Println("✅ Device linked!")
+	fmt.Printf("   Account : %s\n", account)
+	fmt.Println()
+
+	if err := saveSignalConfig(account, url); err != nil {
+		fmt.Printf("⚠️  Could not auto-save to config: %v\n", err)
+		printManualSignalConfig(account, url)
+		return nil
+	}
+
+	fmt.Println("✓ Config updated. Start the gateway with:")
+	fmt.Println()
+	fmt.Println("  picoclaw gateway")
+	fmt.Println()
+	fmt.Println("To restrict who can message the bot, add phone numbers or UUIDs")
+	fmt.Println("to channels.signal.allow_from in your config.")
+
+	return nil
+}
+
+// saveSignalConfig patches channels.signal in the config and saves it.
+func saveSignalConfig(account, url string) error {
+	cfgPath := internal.GetConfigPath()
+
+	cfg, err := config.LoadConfig(cfgPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	bc := cfg.Channels.GetByType(config.ChannelSignal)
+	if bc == nil {
+		bc = &config.Channel{Type: config.ChannelSignal}
+		cfg.Channels[config.ChannelSignal] = bc
+	}
+	bc.Enabled = true
+
+	if decoded, err := bc.GetDecoded(); err == nil && decoded != nil {
+		if signalCfg, ok := decoded.(*config.SignalSettings); ok {
+			signalCfg.Account = account
+			if url != "" && url != signal.DefaultURL {
+				signalCfg.URL = url
+			}
+		}
+	}
+
+	return config.SaveConfig(cfgPath, cfg)
+}
+
+func printManualSignalConfig(account, url string) {
+	fmt.Println()
+	fmt.Println("Add the following to the channels section of your picoclaw config:")
+	fmt.Println()
+	fmt.Println(`  "signal": {`)
+	fmt.Println(`    "enabled": true,`)
+	fmt.Printf("    \"account\": %q,\n", account)
+	if url != "" && url != signal.DefaultURL {
+		fmt.Printf("    \"url\": %q,\n", url)
+	}
+	fmt.Println(`    "allow_from": []`)
+	fmt.Println(`  }`)
+}
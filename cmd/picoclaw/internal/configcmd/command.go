@@ -0,0 +1,243 @@
+package configcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// baseTarget names the base config, without profile or overlay, on the
+// command line.
+const baseTarget = "base"
+
+func NewConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the effective configuration",
+		Long: `Inspect the configuration after includes, the selected profile
+(--profile or PICOCLAW_PROFILE) and the environment overlay (PICOCLAW_ENV)
+have been merged. Secret values are never printed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		newRenderCommand(),
+		newDiffCommand(),
+		newValidateCommand(),
+	)
+
+	return cmd
+}
+
+func newRenderCommand() *cobra.Command {
+	var showLayers bool
+
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Print the effective merged config as JSON",
+		Args:  cobra.NoArgs,
+		Example: `  picoclaw config render
+  picoclaw --profile edge config render
+  picoclaw config render --layers`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := config.LoadConfigWithOptions(internal.GetConfigPath(), config.DefaultLoadOptions())
+			if err != nil {
+				return err
+			}
+			if showLayers {
+				printLayers(cmd.OutOrStdout(), internal.GetConfigPath(), cfg)
+				return nil
+			}
+			data, err := config.RenderConfig(cfg)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&showLayers, "layers", false, "List the files merged into the config instead")
+
+	return cmd
+}
+
+func newDiffCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff [from] [to]",
+		Short: "Show what a profile changes relative to another",
+		Long: `Compare two effective configs leaf by leaf.
+
+Each side is a profile name, or "base" for the base config without profile
+or environment overlay. Profiles are combined with the active environment
+overlay. With no arguments the base is compared with the active selection;
+with one argument the base is compared with that profile.`,
+		Args: cobra.MaximumNArgs(2),
+		Example: `  picoclaw --profile edge config diff
+  picoclaw config diff edge
+  picoclaw config diff edge cloud`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			active := config.DefaultLoadOptions()
+			from, to := loadOptionsFor(baseTarget, active), active
+			switch len(args) {
+			case 1:
+				to = loadOptionsFor(args[0], active)
+			case 2:
+				from, to = loadOptionsFor(args[0], active), loadOptionsFor(args[1], active)
+			default:
+				if active == (config.LoadOptions{}) {
+					return errors.New("nothing to compare: name a profile, or select one with --profile or PICOCLAW_ENV")
+				}
+			}
+
+			path := internal.GetConfigPath()
+			fromCfg, err := config.LoadConfigWithOptions(path, from)
+			if err != nil {
+				return err
+			}
+			toCfg, err := config.LoadConfigWithOptions(path, to)
+			if err != nil {
+				return err
+			}
+			changes, err := config.DiffConfigs(fromCfg, toCfg)
+			if err != nil {
+				return err
+			}
+			printChanges(cmd.OutOrStdout(), changes)
+			return nil
+		},
+	}
+}
+
+func newValidateCommand() *cobra.Command {
+	var allProfiles bool
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check that the effective config loads cleanly",
+		Args:  cobra.NoArgs,
+		Example: `  picoclaw config validate
+  picoclaw --profile edge config validate
+  picoclaw config validate --all-profiles`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path := internal.GetConfigPath()
+			active := config.DefaultLoadOptions()
+			targets := []config.LoadOptions{active}
+			if allProfiles {
+				profiles, err := config.ConfigProfiles(path)
+				if err != nil {
+					return err
+				}
+				targets = []config.LoadOptions{loadOptionsFor(baseTarget, active)}
+				for _, name := range profiles {
+					targets = append(targets, loadOptionsFor(name, active))
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			failed := 0
+			for _, opts := range targets {
+				if err := validateTarget(out, path, opts); err != nil {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d config(s) invalid", failed, len(targets))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&allProfiles, "all-profiles", false, "Validate the base config and every profile")
+
+	return cmd
+}
+
+func validateTarget(w io.Writer, path string, opts config.LoadOptions) error {
+	label := describeTarget(opts)
+	cfg, err := config.LoadConfigWithOptions(path, opts)
+	if err != nil {
+		fmt.Fprintf(w, "✗ %s: %s\n", label, config.DiagnosticSummary(err))
+		return err
+	}
+	if modelName := cfg.Agents.Defaults.GetModelName(); modelName != "" {
+		if _, err := cfg.GetModelConfig(modelName); err != nil {
+			fmt.Fprintf(w, "✗ %s: default model: %v\n", label, err)
+			return err
+		}
+	}
+	layers := len(cfg.Layers())
+	if layers == 0 {
+		layers = 1
+	}
+	fmt.Fprintf(w, "✓ %s (%d file(s))\n", label, layers)
+	return nil
+}
+
+// loadOptionsFor maps a command-line target to load options, keeping the
+// active environment overlay for profiles.
+func loadOptionsFor(target string, active config.LoadOptions) config.LoadOptions {
+	if target == baseTarget {
+		return config.LoadOptions{}
+	}
+	return config.LoadOptions{Profile: target, Environment: active.Environment}
+}
+
+func describeTarget(opts config.LoadOptions) string {
+	label := baseTarget
+	if opts.Profile != "" {
+		label = "profile " + opts.Profile
+	}
+	if opts.Environment != "" {
+		label += " + env " + opts.Environment
+	}
+	return label
+}
+
+func printLayers(w io.Writer, path string, cfg *config.Config) {
+	layers := cfg.Layers()
+	if len(layers) == 0 {
+		layers = []config.ConfigLayer{{Kind: config.LayerBase, Path: path}}
+	}
+	for _, l := range layers {
+		if l.Name != "" {
+			fmt.Fprintf(w, "%-8s %s (%s)\n", l.Kind, l.Name, l.Path)
+			continue
+		}
+		fmt.Fprintf(w, "%-8s %s\n", l.Kind, l.Path)
+	}
+}
+
+func printChanges(w io.Writer, changes []config.ConfigChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "no differences")
+		return
+	}
+	for _, c := range changes {
+		switch {
+		case c.Old == nil:
+			fmt.Fprintf(w, "+ %s: %s\n", c.Path, formatValue(c.New))
+		case c.New == nil:
+			fmt.Fprintf(w, "- %s: %s\n", c.Path, formatValue(c.Old))
+		default:
+			fmt.Fprintf(w, "~ %s: %s -> %s\n", c.Path, formatValue(c.Old), formatValue(c.New))
+		}
+	}
+}
+
+func formatValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
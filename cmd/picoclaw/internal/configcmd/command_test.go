@@ -0,0 +1,80 @@
+package configcmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestNewConfigCommand(t *testing.T) {
+	cmd := NewConfigCommand()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "config", cmd.Use)
+	assert.NotNil(t, cmd.RunE)
+
+	allowedCommands := []string{"render", "diff", "validate"}
+	subcommands := cmd.Commands()
+	assert.Len(t, subcommands, len(allowedCommands))
+	for _, subcmd := range subcommands {
+		assert.True(t, slices.Contains(allowedCommands, subcmd.Name()), "unexpected subcommand %q", subcmd.Name())
+		assert.NotNil(t, subcmd.RunE)
+		assert.True(t, subcmd.HasExample())
+	}
+}
+
+func setupLayeredConfig(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "version": 3,
+  "gateway": {"port": 18100},
+  "profiles": {
+    "edge": {"gateway": {"port": 18200}},
+    "broken": {"gateway": {"port": "not-a-number"}}
+  }
+}`), 0o600))
+	t.Setenv(config.EnvConfig, path)
+	t.Setenv(config.EnvProfile, "")
+	t.Setenv(config.EnvEnvironment, "")
+}
+
+func runConfigCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := NewConfigCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestConfigDiffAndValidate(t *testing.T) {
+	setupLayeredConfig(t)
+
+	out, err := runConfigCommand(t, "diff", "edge")
+	require.NoError(t, err)
+	assert.Equal(t, "~ gateway.port: 18100 -> 18200\n", out)
+
+	out, err = runConfigCommand(t, "render", "--layers")
+	require.NoError(t, err)
+	assert.Contains(t, out, "base")
+
+	_, err = runConfigCommand(t, "validate")
+	require.NoError(t, err)
+
+	out, err = runConfigCommand(t, "validate", "--all-profiles")
+	require.Error(t, err)
+	assert.Contains(t, out, "✓ base")
+	assert.Contains(t, out, "✓ profile edge")
+	assert.Contains(t, out, "✗ profile broken")
+}
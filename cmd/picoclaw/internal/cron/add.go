@@ -16,6 +16,12 @@ func newAddCommand(storePath func() string) *cobra.Command {
 		cronExp string
 		channel string
 		to      string
+
+		timeout      int64
+		retries      int
+		retryBackoff int64
+		concurrency  string
+		catchUp      string
 	)
 
 	cmd := &cobra.Command{
@@ -35,11 +41,28 @@ func newAddCommand(storePath func() string) *cobra.Command {
 				schedule = cron.CronSchedule{Kind: "cron", Expr: cronExp}
 			}
 
+			policy := cron.CronRunPolicy{
+				MaxRetries:     retries,
+				RetryBackoffMS: retryBackoff * 1000,
+				TimeoutMS:      timeout * 1000,
+				Concurrency:    concurrency,
+				CatchUp:        catchUp,
+			}
+			if err := cron.ValidatePolicy(policy); err != nil {
+				return err
+			}
+
 			cs := cron.NewCronService(storePath(), nil)
 			job, err := cs.AddJob(name, schedule, message, channel, to)
 			if err != nil {
 				return fmt.Errorf("error adding job: %w", err)
 			}
+			if policy != (cron.CronRunPolicy{}) {
+				job.Policy = policy
+				if err := cs.UpdateJob(job); err != nil {
+					return fmt.Errorf("error saving job policy: %w", err)
+				}
+			}
 
 			fmt.Printf("✓ Added job '%s' (%s)\n", job.Name, job.ID)
 
@@ -53,6 +76,11 @@ func newAddCommand(storePath func() string) *cobra.Command {
 	cmd.Flags().StringVarP(&cronExp, "cron", "c", "", "Cron expression (e.g. '0 9 * * *')")
 	cmd.Flags().StringVar(&to, "to", "", "Recipient for delivery")
 	cmd.Flags().StringVar(&channel, "channel", "", "Channel for delivery")
+	cmd.Flags().Int64Var(&timeout, "timeout", 0, "Abort a run after N seconds (0 uses the configured default)")
+	cmd.Flags().IntVar(&retries, "retries", 0, "Retry a failed run up to N times")
+	cmd.Flags().Int64Var(&retryBackoff, "retry-backoff", 0, "Seconds to wait before the first retry, doubled each time")
+	cmd.Flags().StringVar(&concurrency, "concurrency", "", "When a run overlaps the previous one: skip, queue or allow")
+	cmd.Flags().StringVar(&catchUp, "catch-up", "", "Runs missed while the gateway was down: skip or once")
 
 	_ = cmd.MarkFlagRequired("name")
 	_ = cmd.MarkFlagRequired("message")
@@ -35,6 +35,8 @@ func TestNewCronCommand(t *testing.T) {
 		"remove",
 		"enable",
 		"disable",
+		"run",
+		"history",
 	}
 
 	subcommands := cmd.Commands()
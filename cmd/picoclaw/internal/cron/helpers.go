@@ -2,6 +2,7 @@ package cron
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/cron"
@@ -43,6 +44,9 @@ func cronListCmd(storePath string) {
 		fmt.Printf("    Schedule: %s\n", schedule)
 		fmt.Printf("    Status: %s\n", status)
 		fmt.Printf("    Next run: %s\n", nextRun)
+		if job.State.LastStatus != "" {
+			fmt.Printf("    Last run: %s (%dms)\n", job.State.LastStatus, job.State.LastDurationMS)
+		}
 	}
 }
 
@@ -64,3 +68,53 @@ func cronSetJobEnabled(storePath, jobID string, enabled bool) {
 		fmt.Printf("✗ Job %s not found\n", jobID)
 	}
 }
+
+func cronRunCmd(storePath, jobID string) {
+	cs := cron.NewCronService(storePath, nil)
+	job := cs.RequestRun(jobID)
+	if job == nil {
+		fmt.Printf("✗ Job %s not found\n", jobID)
+		return
+	}
+	fmt.Printf("✓ Run requested for job '%s'; the gateway will execute it shortly\n", job.Name)
+}
+
+func cronHistoryCmd(storePath, jobID string, limit int) error {
+	cs := cron.NewCronService(storePath, nil)
+	job := cs.GetJob(jobID)
+	runs, err := cs.History(jobID, limit)
+	if err != nil {
+		return fmt.Errorf("error reading history: %w", err)
+	}
+	if job == nil && len(runs) == 0 {
+		fmt.Printf("✗ Job %s not found\n", jobID)
+		return nil
+	}
+
+	name := jobID
+	if job != nil {
+		name = fmt.Sprintf("%s (%s)", job.Name, job.ID)
+	}
+	if len(runs) == 0 {
+		fmt.Printf("No runs recorded for %s.\n", name)
+		return nil
+	}
+
+	fmt.Printf("\nRun history for %s:\n", name)
+	fmt.Println("----------------")
+	for _, run := range runs {
+		started := time.UnixMilli(run.StartedAtMS).Format("2006-01-02 15:04:05")
+		fmt.Printf("  %s  %-7s  %6dms  %s", started, run.Status, run.DurationMS, run.Trigger)
+		if run.Attempt > 1 {
+			fmt.Printf(" (attempt %d)", run.Attempt)
+		}
+		fmt.Println()
+		if run.Error != "" {
+			fmt.Printf("    Error: %s\n", run.Error)
+		}
+		if run.Output != "" {
+			fmt.Printf("    Output: %s\n", strings.ReplaceAll(run.Output, "\n", " "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package cron
+
+import "github.com/spf13/cobra"
+
+func newHistoryCommand(storePath func() string) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:     "history",
+		Short:   "Show recent runs of a job",
+		Args:    cobra.ExactArgs(1),
+		Example: `picoclaw cron history 1`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cronHistoryCmd(storePath(), args[0], limit)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "l", 10, "Number of runs to show (0 for all)")
+
+	return cmd
+}
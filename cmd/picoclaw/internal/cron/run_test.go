@@ -0,0 +1,20 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRunSubcommand(t *testing.T) {
+	fn := func() string { return "" }
+	cmd := newRunCommand(fn)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "run", cmd.Use)
+	assert.Equal(t, "Run a job now", cmd.Short)
+
+	assert.True(t, cmd.HasExample())
+}
@@ -0,0 +1,47 @@
+package triggers
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/triggers"
+)
+
+func newAddCommand(cfg func() *config.Config) *cobra.Command {
+	var def triggers.Trigger
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a new trigger",
+		Args:  cobra.NoArgs,
+		Example: `picoclaw triggers add --name inbox --kind file --path inbox --channel telegram --to 123456
+picoclaw triggers add --name blog --kind feed --url https://example.com/feed.xml --interval 900
+picoclaw triggers add --name build --kind http --url https://ci.example.com/api/status --json-path state`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			t, err := newService(cfg()).Add(def)
+			if err != nil {
+				return fmt.Errorf("error adding trigger: %w", err)
+			}
+			fmt.Printf("✓ Added trigger '%s' (%s): %s\n", t.Name, t.ID, t.Describe())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&def.Name, "name", "n", "", "Trigger name")
+	cmd.Flags().StringVarP(&def.Kind, "kind", "k", "", "Trigger kind: file, feed or http")
+	cmd.Flags().StringVar(&def.Path, "path", "", "File or directory to watch (kind file)")
+	cmd.Flags().StringVar(&def.URL, "url", "", "URL to poll (kind feed or http)")
+	cmd.Flags().StringVar(&def.JSONPath, "json-path", "", "Dot path of the watched JSON value (kind http)")
+	cmd.Flags().IntVar(&def.IntervalSeconds, "interval", 0, "Poll interval in seconds (default 300)")
+	cmd.Flags().StringVarP(&def.Prompt, "prompt", "p", "", "Prompt template for the agent turn")
+	cmd.Flags().StringVar(&def.Channel, "channel", "", "Channel for delivery (default: last active chat)")
+	cmd.Flags().StringVar(&def.ChatID, "to", "", "Recipient for delivery")
+
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("kind")
+	cmd.MarkFlagsMutuallyExclusive("path", "url")
+
+	return cmd
+}
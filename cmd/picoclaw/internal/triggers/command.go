@@ -0,0 +1,44 @@
+package triggers
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func NewTriggersCommand() *cobra.Command {
+	var cfg *config.Config
+
+	cmd := &cobra.Command{
+		Use:     "triggers",
+		Aliases: []string{"trigger"},
+		Short:   "Manage event triggers",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			var err error
+			cfg, err = internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			return nil
+		},
+	}
+
+	getConfig := func() *config.Config { return cfg }
+
+	cmd.AddCommand(
+		newListCommand(getConfig),
+		newAddCommand(getConfig),
+		newRemoveCommand(getConfig),
+		newEnableCommand(getConfig),
+		newDisableCommand(getConfig),
+	)
+
+	return cmd
+}
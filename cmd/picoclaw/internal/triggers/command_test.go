@@ -0,0 +1,43 @@
+package triggers
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTriggersCommand(t *testing.T) {
+	cmd := NewTriggersCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "Manage event triggers", cmd.Short)
+	assert.True(t, cmd.HasAlias("trigger"))
+	assert.NotNil(t, cmd.PersistentPreRunE)
+
+	allowedCommands := []string{"list", "add", "remove", "enable", "disable"}
+
+	subcommands := cmd.Commands()
+	assert.Len(t, subcommands, len(allowedCommands))
+	for _, subcmd := range subcommands {
+		assert.True(t, slices.Contains(allowedCommands, subcmd.Name()), "unexpected subcommand %q", subcmd.Name())
+		assert.NotNil(t, subcmd.RunE)
+	}
+}
+
+func TestNewAddSubcommand(t *testing.T) {
+	cmd := newAddCommand(nil)
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "add", cmd.Use)
+	assert.True(t, cmd.HasExample())
+
+	for _, name := range []string{"name", "kind", "path", "url", "json-path", "interval", "prompt", "channel", "to"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "missing flag %q", name)
+	}
+
+	cmd.SetArgs([]string{"--name", "x", "--kind", "file", "--path", "a", "--url", "https://example.com"})
+	require.Error(t, cmd.Execute())
+}
@@ -0,0 +1,33 @@
+package triggers
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newEnableCommand(cfg func() *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:     "enable",
+		Short:   "Enable a trigger",
+		Args:    cobra.ExactArgs(1),
+		Example: `picoclaw triggers enable 3f2a9c1d04be`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			triggersSetEnabled(cfg(), args[0], true)
+			return nil
+		},
+	}
+}
+
+func newDisableCommand(cfg func() *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:     "disable",
+		Short:   "Disable a trigger",
+		Args:    cobra.ExactArgs(1),
+		Example: `picoclaw triggers disable 3f2a9c1d04be`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			triggersSetEnabled(cfg(), args[0], false)
+			return nil
+		},
+	}
+}
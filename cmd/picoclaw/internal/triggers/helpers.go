@@ -0,0 +1,76 @@
+package triggers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/triggers"
+)
+
+// newService opens the workspace trigger store with the configured triggers
+// attached, the same view the gateway has.
+func newService(cfg *config.Config) *triggers.Service {
+	workspace := cfg.WorkspacePath()
+	s := triggers.NewService(triggers.StorePath(workspace), triggers.Options{
+		Workspace: workspace,
+	})
+	s.SetConfigured(triggers.FromConfig(cfg.Tools.Triggers.Triggers))
+	return s
+}
+
+func triggersListCmd(cfg *config.Config) {
+	s := newService(cfg)
+	all := s.List()
+	if len(all) == 0 {
+		fmt.Println("No triggers.")
+		return
+	}
+
+	fmt.Println("\nTriggers:")
+	fmt.Println("---------")
+	for _, t := range all {
+		status := "enabled"
+		if !t.Enabled {
+			status = "disabled"
+		}
+		if t.Source == triggers.SourceConfig {
+			status += " (config)"
+		}
+
+		_, state, _ := s.Get(t.ID)
+		lastFired := "never"
+		if state.LastFiredAtMS > 0 {
+			lastFired = time.UnixMilli(state.LastFiredAtMS).Format("2006-01-02 15:04")
+		}
+
+		fmt.Printf("  %s (%s)\n", t.Name, t.ID)
+		fmt.Printf("    Kind: %s — %s\n", t.Kind, t.Describe())
+		fmt.Printf("    Status: %s\n", status)
+		fmt.Printf("    Last fired: %s (%d total)\n", lastFired, state.FireCount)
+		if state.LastError != "" {
+			fmt.Printf("    Last error: %s\n", state.LastError)
+		}
+	}
+}
+
+func triggersRemoveCmd(cfg *config.Config, id string) {
+	if err := newService(cfg).Remove(id); err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return
+	}
+	fmt.Printf("✓ Removed trigger %s\n", id)
+}
+
+func triggersSetEnabled(cfg *config.Config, id string, enabled bool) {
+	t, err := newService(cfg).SetEnabled(id, enabled)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return
+	}
+	status := "enabled"
+	if !enabled {
+		status = "disabled"
+	}
+	fmt.Printf("✓ Trigger '%s' %s\n", t.Name, status)
+}
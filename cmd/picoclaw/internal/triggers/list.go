@@ -0,0 +1,19 @@
+package triggers
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newListCommand(cfg func() *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all triggers",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			triggersListCmd(cfg())
+			return nil
+		},
+	}
+}
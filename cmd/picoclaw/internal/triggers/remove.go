@@ -0,0 +1,20 @@
+package triggers
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newRemoveCommand(cfg func() *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove",
+		Short:   "Remove a trigger by ID",
+		Args:    cobra.ExactArgs(1),
+		Example: `picoclaw triggers remove 3f2a9c1d04be`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			triggersRemoveCmd(cfg(), args[0])
+			return nil
+		},
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/agent"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/auth"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/cliui"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/configcmd"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/cron"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/gateway"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/mcp"
@@ -25,6 +26,7 @@ import (
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/onboard"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/skills"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/status"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/triggers"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/version"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/updater"
@@ -32,6 +34,20 @@ import (
 
 var rootNoColor bool
 
+// profileFlag exports --profile as PICOCLAW_PROFILE while flags are parsed,
+// so every config load in this process, and any gateway it starts, sees the
+// same profile regardless of which subcommand hooks run.
+type profileFlag string
+
+func (p *profileFlag) String() string { return string(*p) }
+
+func (p *profileFlag) Set(v string) error {
+	*p = profileFlag(v)
+	return os.Setenv(config.EnvProfile, v)
+}
+
+func (p *profileFlag) Type() string { return "string" }
+
 func syncCliUIColor(root *cobra.Command) {
 	no, _ := root.PersistentFlags().GetBool("no-color")
 	cliui.Init(no || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb")
@@ -75,6 +91,8 @@ picoclaw --no-color status`,
 
 	cmd.PersistentFlags().BoolVar(&rootNoColor, "no-color", false,
 		"Disable colors (boxed layout unchanged)")
+	cmd.PersistentFlags().Var(new(profileFlag), "profile",
+		"Config profile to layer over the base config (overrides PICOCLAW_PROFILE)")
 
 	cmd.SetHelpFunc(func(c *cobra.Command, _ []string) {
 		syncCliUIColor(c.Root())
@@ -88,6 +106,8 @@ picoclaw --no-color status`,
 		gateway.NewGatewayCommand(),
 		status.NewStatusCommand(),
 		cron.NewCronCommand(),
+		configcmd.NewConfigCommand(),
+		triggers.NewTriggersCommand(),
 		mcp.NewMCPCommand(),
 		migrate.NewMigrateCommand(),
 		skills.NewSkillsCommand(),
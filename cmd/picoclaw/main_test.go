@@ -29,6 +29,7 @@ func TestNewPicoclawCommand(t *testing.T) {
 	assert.True(t, cmd.HasAvailableSubCommands())
 
 	assert.True(t, cmd.PersistentFlags().Lookup("no-color") != nil)
+	assert.True(t, cmd.PersistentFlags().Lookup("profile") != nil)
 
 	assert.Nil(t, cmd.Run)
 	assert.Nil(t, cmd.RunE)
@@ -39,6 +40,7 @@ func TestNewPicoclawCommand(t *testing.T) {
 	allowedCommands := []string{
 		"agent",
 		"auth",
+		"config",
 		"cron",
 		"gateway",
 		"mcp",
@@ -47,6 +49,7 @@ func TestNewPicoclawCommand(t *testing.T) {
 		"onboard",
 		"skills",
 		"status",
+		"triggers",
 		"update",
 		"version",
 	}
@@ -2,6 +2,10 @@
 // the pico_client channel. It accepts connections, prints received messages
 // to stdout, and forwards stdin lines as message.create to all connected clients.
 //
+// Clients that connect with ?protocol=2 get protocol v2: a session.ready
+// handshake, sequence-numbered frames that are replayed after a reconnect with
+// ?last_seq=N, and the session.list/switch/new and turn.cancel requests.
+//
 // Usage:
 //
 //	go run ./examples/pico-echo-server -addr :9090 -token secret
@@ -17,6 +21,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -24,26 +29,44 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+const (
+	protocolLatest = 2
+	replayLimit    = 100
+)
+
 type picoMessage struct {
 	Type      string         `json:"type"`
 	ID        string         `json:"id,omitempty"`
 	SessionID string         `json:"session_id,omitempty"`
+	Seq       uint64         `json:"seq,omitempty"`
 	Timestamp int64          `json:"timestamp,omitempty"`
 	Payload   map[string]any `json:"payload,omitempty"`
 }
 
+// session numbers outgoing frames and keeps the last few for replay.
+type session struct {
+	lastSeq uint64
+	frames  []picoMessage
+}
+
+type client struct {
+	sessionID string
+	protocol  int
+}
+
 var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
 
 type server struct {
-	token string
-	mu    sync.Mutex
-	conns map[*websocket.Conn]string // conn → sessionID
+	token    string
+	mu       sync.Mutex
+	conns    map[*websocket.Conn]*client
+	sessions map[string]*session
 }
 
 func (s *server) handleWS(w http.ResponseWriter, r *http.Request) {
 	if s.token != "" {
 		auth := r.Header.Get("Authorization")
-		if auth != "Bearer "+s.token {
+		if auth != "Bearer "+s.token && r.URL.Query().Get("token") != s.token {
 			http.Error(w, "unauthorized", http.StatusUnauthorized)
 			return
 		}
@@ -55,23 +78,31 @@ func (s *server) handleWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionID := r.URL.Query().Get("session_id")
+	query := r.URL.Query()
+	sessionID := query.Get("session_id")
 	if sessionID == "" {
 		sessionID = fmt.Sprintf("sess-%d", time.Now().UnixMilli())
 	}
+	protocol, _ := strconv.Atoi(query.Get("protocol"))
+	protocol = max(1, min(protocol, protocolLatest))
 
+	c := &client{sessionID: sessionID, protocol: protocol}
 	s.mu.Lock()
-	s.conns[conn] = sessionID
+	s.conns[conn] = c
+	if protocol >= 2 {
+		lastSeq, err := strconv.ParseUint(query.Get("last_seq"), 10, 64)
+		s.sendReadyLocked(conn, c, lastSeq, err == nil)
+	}
 	s.mu.Unlock()
 
-	log.Printf("[+] client connected (session=%s)", sessionID)
+	log.Printf("[+] client connected (session=%s, protocol=%d)", sessionID, protocol)
 
 	defer func() {
 		s.mu.Lock()
 		delete(s.conns, conn)
 		s.mu.Unlock()
 		conn.Close()
-		log.Printf("[-] client disconnected (session=%s)", sessionID)
+		log.Printf("[-] client disconnected (session=%s)", c.sessionID)
 	}()
 
 	for {
@@ -88,42 +119,134 @@ func (s *server) handleWS(w http.ResponseWriter, r *http.Request) {
 			log.Printf("bad json: %v", err)
 			continue
 		}
+		s.handle(conn, c, msg)
+	}
+}
+
+func (s *server) handle(conn *websocket.Conn, c *client, msg picoMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		switch msg.Type {
-		case "ping":
-			pong := picoMessage{Type: "pong", ID: msg.ID, Timestamp: time.Now().UnixMilli()}
-			conn.WriteJSON(pong)
+	switch msg.Type {
+	case "ping":
+		pong := picoMessage{Type: "pong", ID: msg.ID, Timestamp: time.Now().UnixMilli()}
+		conn.WriteJSON(pong)
 
-		case "message.send":
-			content, _ := msg.Payload["content"].(string)
-			fmt.Printf("[%s] %s\n", sessionID, content)
+	case "message.send":
+		content, _ := msg.Payload["content"].(string)
+		fmt.Printf("[%s] %s\n", c.sessionID, content)
 
-		case "typing.start":
-			log.Printf("[%s] typing...", sessionID)
+	case "typing.start":
+		log.Printf("[%s] typing...", c.sessionID)
 
-		case "typing.stop":
-			log.Printf("[%s] stopped typing", sessionID)
+	case "typing.stop":
+		log.Printf("[%s] stopped typing", c.sessionID)
 
-		default:
-			log.Printf("[%s] unknown type: %s", sessionID, msg.Type)
+	case "turn.cancel":
+		log.Printf("[%s] turn cancelled", c.sessionID)
+
+	case "session.list":
+		list := make([]map[string]any, 0, len(s.sessions))
+		for id, sess := range s.sessions {
+			list = append(list, map[string]any{"session_id": id, "last_seq": sess.lastSeq})
+		}
+		conn.WriteJSON(picoMessage{
+			Type:      "session.listed",
+			ID:        msg.ID,
+			SessionID: c.sessionID,
+			Timestamp: time.Now().UnixMilli(),
+			Payload:   map[string]any{"sessions": list},
+		})
+
+	case "session.switch", "session.new":
+		sessionID, _ := msg.Payload["session_id"].(string)
+		if msg.Type == "session.new" || sessionID == "" {
+			sessionID = fmt.Sprintf("sess-%d", time.Now().UnixMilli())
 		}
+		lastSeq, resume := msg.Payload["last_seq"].(float64)
+		log.Printf("[%s] switched to session %s", c.sessionID, sessionID)
+		c.sessionID = sessionID
+		s.sendReadyLocked(conn, c, uint64(lastSeq), resume)
+
+	case "tool.approval.response":
+		log.Printf("[%s] tool approval %v: approved=%v", c.sessionID, msg.Payload["approval_id"], msg.Payload["approved"])
+
+	default:
+		log.Printf("[%s] unknown type: %s", c.sessionID, msg.Type)
 	}
 }
 
-func (s *server) broadcast(content string) {
-	msg := picoMessage{
-		Type:      "message.create",
+// sendReadyLocked answers a v2 handshake or session switch and replays the
+// frames after lastSeq. s.mu must be held.
+func (s *server) sendReadyLocked(conn *websocket.Conn, c *client, lastSeq uint64, resume bool) {
+	sess := s.sessionLocked(c.sessionID)
+	var missed []picoMessage
+	if resume {
+		for _, f := range sess.frames {
+			if f.Seq > lastSeq {
+				missed = append(missed, f)
+			}
+		}
+	}
+	conn.WriteJSON(picoMessage{
+		Type:      "session.ready",
+		SessionID: c.sessionID,
 		Timestamp: time.Now().UnixMilli(),
-		Payload:   map[string]any{"content": content},
+		Payload: map[string]any{
+			"protocol": c.protocol,
+			"last_seq": sess.lastSeq,
+			"resumed":  resume,
+			"replayed": len(missed),
+		},
+	})
+	for _, f := range missed {
+		conn.WriteJSON(f)
 	}
+}
+
+func (s *server) sessionLocked(id string) *session {
+	sess, ok := s.sessions[id]
+	if !ok {
+		sess = &session{}
+		s.sessions[id] = sess
+	}
+	return sess
+}
 
+func (s *server) broadcast(content string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for conn, sid := range s.conns {
-		msg.SessionID = sid
+	newFrame := func(sessionID string) picoMessage {
+		return picoMessage{
+			Type:      "message.create",
+			SessionID: sessionID,
+			Timestamp: time.Now().UnixMilli(),
+			Payload:   map[string]any{"content": content},
+		}
+	}
+
+	// v2 sessions number and keep the frame even when nobody is connected,
+	// so a client that reconnects with last_seq receives it.
+	frames := make(map[string]picoMessage, len(s.sessions))
+	for id, sess := range s.sessions {
+		msg := newFrame(id)
+		sess.lastSeq++
+		msg.Seq = sess.lastSeq
+		sess.frames = append(sess.frames, msg)
+		if len(sess.frames) > replayLimit {
+			sess.frames = sess.frames[len(sess.frames)-replayLimit:]
+		}
+		frames[id] = msg
+	}
+
+	for conn, c := range s.conns {
+		msg, ok := frames[c.sessionID]
+		if !ok {
+			msg = newFrame(c.sessionID)
+		}
 		if err := conn.WriteJSON(msg); err != nil {
-			log.Printf("write to %s failed: %v", sid, err)
+			log.Printf("write to %s failed: %v", c.sessionID, err)
 		}
 	}
 }
@@ -134,8 +257,9 @@ func main() {
 	flag.Parse()
 
 	s := &server{
-		token: *token,
-		conns: make(map[*websocket.Conn]string),
+		token:    *token,
+		conns:    make(map[*websocket.Conn]*client),
+		sessions: make(map[string]*session),
 	}
 
 	http.HandleFunc("/ws", s.handleWS)
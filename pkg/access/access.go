@@ -0,0 +1,420 @@
+// Package access resolves sender roles and the permissions attached to them.
+//
+// A Controller maps canonical sender identities (see pkg/identity) to one of
+// four roles and answers whether a role may use a tool, command, agent or
+// model. It also rate-limits senders per role. A nil or disabled Controller
+// allows everything, so callers need not special-case the feature being off.
+package access
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// Role is a sender's access level.
+type Role string
+
+const (
+	Owner  Role = "owner"
+	Admin  Role = "admin"
+	Member Role = "member"
+	Guest  Role = "guest"
+)
+
+// Roles lists all roles from most to least privileged.
+var Roles = []Role{Owner, Admin, Member, Guest}
+
+// ParseRole returns the role named s (case-insensitive).
+func ParseRole(s string) (Role, bool) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Roles {
+		if r == known {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// Policy is the effective permission set of a role. See config.RolePolicy
+// for the pattern syntax.
+type Policy struct {
+	Tools     []string
+	Commands  []string
+	Agents    []string
+	Models    []string
+	PerMinute int
+	PerHour   int
+}
+
+var all = []string{"*"}
+
+// builtinPolicies are used for any field a config override leaves unset.
+// Members cannot reload config or switch an agent's model for everyone;
+// guests get no tools and only the commands needed to find their way.
+var builtinPolicies = map[Role]Policy{
+	Owner:  {Tools: all, Commands: all, Agents: all, Models: all},
+	Admin:  {Tools: all, Commands: all, Agents: all, Models: all},
+	Member: {Tools: all, Commands: []string{"*", "!reload", "!switch"}, Agents: all, Models: all},
+	Guest: {
+		Tools:     []string{},
+		Commands:  []string{"start", "help", "role"},
+		Agents:    all,
+		Models:    all,
+		PerMinute: 5,
+		PerHour:   60,
+	},
+}
+
+// Grant is one identity-to-role assignment.
+type Grant struct {
+	Identity string
+	Role     Role
+	Runtime  bool // set with /role rather than in config
+}
+
+// Controller resolves roles and checks permissions. It is safe for
+// concurrent use.
+type Controller struct {
+	enabled     bool
+	defaultRole Role
+	users       []Grant
+	policies    map[Role]Policy
+	storePath   string
+	limiter     *rateLimiter
+
+	mu     sync.RWMutex
+	grants map[string]Role
+}
+
+// New builds a Controller from cfg. Runtime grants are loaded from and saved
+// to storePath; an empty storePath keeps them in memory only.
+func New(cfg config.AccessConfig, storePath string) *Controller {
+	c := &Controller{
+		enabled:     cfg.Enabled,
+		defaultRole: Member,
+		policies:    make(map[Role]Policy, len(Roles)),
+		storePath:   storePath,
+		limiter:     newRateLimiter(),
+		grants:      make(map[string]Role),
+	}
+	if cfg.DefaultRole != "" {
+		if r, ok := ParseRole(cfg.DefaultRole); ok {
+			c.defaultRole = r
+		} else {
+			logger.WarnCF("access", "Unknown default_role; using member",
+				map[string]any{"role": cfg.DefaultRole})
+		}
+	}
+	for id, name := range cfg.Users {
+		r, ok := ParseRole(name)
+		if !ok {
+			logger.WarnCF("access", "Ignoring user with unknown role",
+				map[string]any{"identity": id, "role": name})
+			continue
+		}
+		c.users = append(c.users, Grant{Identity: normalizeIdentity(id), Role: r})
+	}
+	sortGrants(c.users)
+
+	for _, r := range Roles {
+		c.policies[r] = builtinPolicies[r]
+	}
+	for name, override := range cfg.Roles {
+		r, ok := ParseRole(name)
+		if !ok {
+			logger.WarnCF("access", "Ignoring policy for unknown role", map[string]any{"role": name})
+			continue
+		}
+		c.policies[r] = mergePolicy(c.policies[r], override)
+	}
+
+	if err := c.load(); err != nil {
+		logger.WarnCF("access", "Failed to load role grants",
+			map[string]any{"path": storePath, "error": err.Error()})
+	}
+	return c
+}
+
+func mergePolicy(p Policy, o config.RolePolicy) Policy {
+	if o.Tools != nil {
+		p.Tools = o.Tools
+	}
+	if o.Commands != nil {
+		p.Commands = o.Commands
+	}
+	if o.Agents != nil {
+		p.Agents = o.Agents
+	}
+	if o.Models != nil {
+		p.Models = o.Models
+	}
+	if o.Rate != nil {
+		p.PerMinute, p.PerHour = o.Rate.PerMinute, o.Rate.PerHour
+	}
+	return p
+}
+
+// Enabled reports whether access control is on.
+func (c *Controller) Enabled() bool {
+	return c != nil && c.enabled
+}
+
+// RoleOf returns the sender's role. Owners listed in config always stay
+// owners so a runtime grant cannot lock them out; otherwise runtime grants
+// win over config, and the highest matching role wins within each.
+func (c *Controller) RoleOf(sender bus.SenderInfo) Role {
+	if !c.Enabled() {
+		return Owner
+	}
+	configRole, fromConfig := bestMatch(sender, c.users)
+	if fromConfig && configRole == Owner {
+		return Owner
+	}
+	c.mu.RLock()
+	runtime := make([]Grant, 0, len(c.grants))
+	for id, r := range c.grants {
+		runtime = append(runtime, Grant{Identity: id, Role: r})
+	}
+	c.mu.RUnlock()
+	if r, ok := bestMatch(sender, runtime); ok {
+		return r
+	}
+	if fromConfig {
+		return configRole
+	}
+	return c.defaultRole
+}
+
+func bestMatch(sender bus.SenderInfo, grants []Grant) (Role, bool) {
+	best, found := Role(""), false
+	for _, g := range grants {
+		if !identity.MatchAllowed(sender, g.Identity) {
+			continue
+		}
+		if !found || rank(g.Role) < rank(best) {
+			best, found = g.Role, true
+		}
+	}
+	return best, found
+}
+
+func rank(r Role) int {
+	for i, known := range Roles {
+		if r == known {
+			return i
+		}
+	}
+	return len(Roles)
+}
+
+// Policy returns the effective policy of role.
+func (c *Controller) Policy(role Role) Policy {
+	if !c.Enabled() {
+		return builtinPolicies[Owner]
+	}
+	return c.policies[role]
+}
+
+// AllowTool reports whether role may call the named tool.
+func (c *Controller) AllowTool(role Role, name string) bool {
+	return !c.Enabled() || matchList(c.policies[role].Tools, name)
+}
+
+// AllowCommand reports whether role may run the named slash command.
+func (c *Controller) AllowCommand(role Role, name string) bool {
+	return !c.Enabled() || matchList(c.policies[role].Commands, name)
+}
+
+// AllowAgent reports whether role may talk to the agent with the given ID.
+func (c *Controller) AllowAgent(role Role, agentID string) bool {
+	return !c.Enabled() || matchList(c.policies[role].Agents, agentID)
+}
+
+// AllowModel reports whether role may use the named model.
+func (c *Controller) AllowModel(role Role, model string) bool {
+	return !c.Enabled() || matchList(c.policies[role].Models, model)
+}
+
+// FallbackModel returns the first model in role's list that is a plain name
+// rather than a pattern, or "" when there is none.
+func (c *Controller) FallbackModel(role Role) string {
+	if !c.Enabled() {
+		return ""
+	}
+	for _, m := range c.policies[role].Models {
+		m = strings.TrimSpace(m)
+		if m != "" && !strings.HasPrefix(m, "!") && !strings.ContainsAny(m, "*?[") {
+			return m
+		}
+	}
+	return ""
+}
+
+// Allow records a message from the sender identified by key and reports
+// whether it is within role's rate limit. When it is not, retryAfter is how
+// long until the oldest message leaves the window.
+func (c *Controller) Allow(role Role, key string) (ok bool, retryAfter time.Duration) {
+	if !c.Enabled() {
+		return true, 0
+	}
+	p := c.policies[role]
+	return c.limiter.allow(string(role)+"\x00"+key, p.PerMinute, p.PerHour)
+}
+
+// matchList reports whether name matches a positive pattern in list and no
+// "!" pattern. Matching is case-insensitive.
+func matchList(list []string, name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	allowed := false
+	for _, pattern := range list {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		negate := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		if pattern == "" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); !ok && pattern != "*" {
+			continue
+		}
+		if negate {
+			return false
+		}
+		allowed = true
+	}
+	return allowed
+}
+
+// Grants returns config and runtime assignments, runtime ones first.
+func (c *Controller) Grants() []Grant {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	out := make([]Grant, 0, len(c.grants)+len(c.users))
+	for id, r := range c.grants {
+		out = append(out, Grant{Identity: id, Role: r, Runtime: true})
+	}
+	c.mu.RUnlock()
+	sortGrants(out)
+	return append(out, c.users...)
+}
+
+// SetRole assigns role to the identity at runtime and persists it.
+func (c *Controller) SetRole(id string, role Role) error {
+	if !c.Enabled() {
+		return errors.New("access control is disabled")
+	}
+	if _, ok := ParseRole(string(role)); !ok {
+		return fmt.Errorf("unknown role %q", role)
+	}
+	id = normalizeIdentity(id)
+	if id == "" {
+		return errors.New("identity is required")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev, had := c.grants[id]
+	c.grants[id] = role
+	if err := c.saveLocked(); err != nil {
+		if had {
+			c.grants[id] = prev
+		} else {
+			delete(c.grants, id)
+		}
+		return err
+	}
+	return nil
+}
+
+// RemoveRole deletes a runtime assignment. Config assignments cannot be
+// removed at runtime. It reports whether a grant existed.
+func (c *Controller) RemoveRole(id string) (bool, error) {
+	if !c.Enabled() {
+		return false, errors.New("access control is disabled")
+	}
+	id = normalizeIdentity(id)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev, ok := c.grants[id]
+	if !ok {
+		return false, nil
+	}
+	delete(c.grants, id)
+	if err := c.saveLocked(); err != nil {
+		c.grants[id] = prev
+		return false, err
+	}
+	return true, nil
+}
+
+type grantFile struct {
+	Grants map[string]Role `json:"grants"`
+}
+
+func (c *Controller) load() error {
+	if c.storePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.storePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var f grantFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	for id, r := range f.Grants {
+		if _, ok := ParseRole(string(r)); ok {
+			c.grants[normalizeIdentity(id)] = r
+		}
+	}
+	return nil
+}
+
+func (c *Controller) saveLocked() error {
+	if c.storePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(grantFile{Grants: c.grants}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.storePath), 0o700); err != nil {
+		return fmt.Errorf("save role grants: %w", err)
+	}
+	if err := fileutil.WriteFileAtomic(c.storePath, data, 0o600); err != nil {
+		return fmt.Errorf("save role grants: %w", err)
+	}
+	return nil
+}
+
+// normalizeIdentity lowercases the platform of canonical IDs so that
+// "Telegram:123" and "telegram:123" are the same grant.
+func normalizeIdentity(id string) string {
+	id = strings.TrimSpace(id)
+	if platform, pid, ok := identity.ParseCanonicalID(id); ok {
+		return identity.BuildCanonicalID(platform, pid)
+	}
+	return id
+}
+
+func sortGrants(g []Grant) {
+	sort.Slice(g, func(i, j int) bool { return g[i].Identity < g[j].Identity })
+}
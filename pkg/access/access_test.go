@@ -0,0 +1,190 @@
+package access
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func tg(id, username string) bus.SenderInfo {
+	return bus.SenderInfo{Platform: "telegram", PlatformID: id, CanonicalID: "telegram:" + id, Username: username}
+}
+
+func TestRoleOf(t *testing.T) {
+	c := New(config.AccessConfig{
+		Enabled:     true,
+		DefaultRole: "guest",
+		Users: map[string]string{
+			"telegram:1": "owner",
+			"@bob":       "admin",
+			"3":          "member",
+			"4":          "nobody",
+		},
+	}, "")
+
+	cases := []struct {
+		sender bus.SenderInfo
+		want   Role
+	}{
+		{tg("1", ""), Owner},
+		{tg("2", "bob"), Admin},
+		{tg("3", ""), Member},
+		{tg("4", ""), Guest}, // unknown role is ignored
+		{bus.SenderInfo{Platform: "discord", PlatformID: "1", CanonicalID: "discord:1"}, Guest},
+	}
+	for _, tc := range cases {
+		if got := c.RoleOf(tc.sender); got != tc.want {
+			t.Errorf("RoleOf(%s) = %s, want %s", tc.sender.CanonicalID, got, tc.want)
+		}
+	}
+}
+
+func TestDisabledAllowsEverything(t *testing.T) {
+	for _, c := range []*Controller{nil, New(config.AccessConfig{}, "")} {
+		if c.Enabled() || !c.AllowTool(Guest, "exec") || !c.AllowCommand(Guest, "reload") {
+			t.Fatalf("disabled controller should allow everything")
+		}
+		if ok, _ := c.Allow(Guest, "x"); !ok {
+			t.Fatalf("disabled controller should not rate limit")
+		}
+	}
+}
+
+func TestPolicyDefaultsAndOverrides(t *testing.T) {
+	c := New(config.AccessConfig{
+		Enabled: true,
+		Roles: map[string]config.RolePolicy{
+			"member": {Tools: []string{"*", "!exec"}, Models: []string{"mini", "gpt-*"}},
+			"guest":  {Tools: []string{"web_*"}},
+		},
+	}, "")
+
+	checks := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"owner reload", c.AllowCommand(Owner, "reload"), true},
+		{"member reload", c.AllowCommand(Member, "reload"), false},
+		{"member help", c.AllowCommand(Member, "help"), true},
+		{"member exec", c.AllowTool(Member, "exec"), false},
+		{"member read_file", c.AllowTool(Member, "read_file"), true},
+		{"member model glob", c.AllowModel(Member, "GPT-4o"), true},
+		{"member model denied", c.AllowModel(Member, "opus"), false},
+		{"guest web_search", c.AllowTool(Guest, "web_search"), true},
+		{"guest exec", c.AllowTool(Guest, "exec"), false},
+		{"guest role", c.AllowCommand(Guest, "role"), true},
+		{"guest clear", c.AllowCommand(Guest, "clear"), false},
+		{"admin slash model", c.AllowModel(Admin, "openrouter/x"), true},
+	}
+	for _, ch := range checks {
+		if ch.got != ch.want {
+			t.Errorf("%s = %v, want %v", ch.name, ch.got, ch.want)
+		}
+	}
+	if got := c.FallbackModel(Member); got != "mini" {
+		t.Errorf("FallbackModel(member) = %q, want mini", got)
+	}
+	if got := c.FallbackModel(Owner); got != "" {
+		t.Errorf("FallbackModel(owner) = %q, want empty", got)
+	}
+}
+
+func TestEmptyListAllowsNothing(t *testing.T) {
+	c := New(config.AccessConfig{
+		Enabled: true,
+		Roles:   map[string]config.RolePolicy{"member": {Commands: []string{}}},
+	}, "")
+	if c.AllowCommand(Member, "help") {
+		t.Fatal("empty commands list should deny every command")
+	}
+	if !c.AllowTool(Member, "exec") {
+		t.Fatal("unset tools list should keep the built-in default")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	c := New(config.AccessConfig{
+		Enabled: true,
+		Roles: map[string]config.RolePolicy{
+			"guest": {Rate: &config.RoleRateConfig{PerMinute: 2, PerHour: 3}},
+		},
+	}, "")
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.limiter.now = func() time.Time { return now }
+
+	step := func(d time.Duration) (bool, time.Duration) {
+		now = now.Add(d)
+		return c.Allow(Guest, "telegram:9")
+	}
+	if ok, _ := step(0); !ok {
+		t.Fatal("first message should pass")
+	}
+	if ok, _ := step(10 * time.Second); !ok {
+		t.Fatal("second message should pass")
+	}
+	ok, wait := step(10 * time.Second)
+	if ok || wait != 40*time.Second {
+		t.Fatalf("third message in a minute: ok=%v wait=%s, want blocked for 40s", ok, wait)
+	}
+	if ok, _ := step(41 * time.Second); !ok {
+		t.Fatal("message after the minute window should pass")
+	}
+	ok, wait = step(2 * time.Minute)
+	if ok || wait <= 0 {
+		t.Fatalf("fourth message in an hour: ok=%v wait=%s, want blocked", ok, wait)
+	}
+	if ok, _ := c.Allow(Guest, "telegram:10"); !ok {
+		t.Fatal("other senders have their own window")
+	}
+	if ok, _ := c.Allow(Member, "telegram:9"); !ok {
+		t.Fatal("members are not rate limited by default")
+	}
+}
+
+func TestRuntimeGrantsPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "roles.json")
+	cfg := config.AccessConfig{
+		Enabled: true,
+		Users:   map[string]string{"telegram:1": "owner", "telegram:2": "member"},
+	}
+	c := New(cfg, path)
+
+	if err := c.SetRole("Telegram:2", Guest); err != nil {
+		t.Fatalf("SetRole: %v", err)
+	}
+	if err := c.SetRole("telegram:1", Guest); err != nil {
+		t.Fatalf("SetRole: %v", err)
+	}
+	if got := c.RoleOf(tg("2", "")); got != Guest {
+		t.Errorf("runtime grant should override config: got %s", got)
+	}
+	if got := c.RoleOf(tg("1", "")); got != Owner {
+		t.Errorf("config owners cannot be demoted: got %s", got)
+	}
+
+	reloaded := New(cfg, path)
+	if got := reloaded.RoleOf(tg("2", "")); got != Guest {
+		t.Errorf("grant not persisted: got %s", got)
+	}
+	grants := reloaded.Grants()
+	if len(grants) != 4 || !grants[0].Runtime || grants[2].Runtime {
+		t.Errorf("Grants() = %+v, want two runtime then two config grants", grants)
+	}
+
+	if removed, err := reloaded.RemoveRole("telegram:2"); err != nil || !removed {
+		t.Fatalf("RemoveRole = %v, %v", removed, err)
+	}
+	if removed, _ := reloaded.RemoveRole("telegram:2"); removed {
+		t.Error("second RemoveRole should report nothing removed")
+	}
+	if got := New(cfg, path).RoleOf(tg("2", "")); got != Member {
+		t.Errorf("after removal role = %s, want config member", got)
+	}
+	if err := reloaded.SetRole("telegram:5", Role("root")); err == nil {
+		t.Error("SetRole with unknown role should fail")
+	}
+}
@@ -0,0 +1,73 @@
+package access
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepEvery bounds how often idle senders are dropped from the limiter.
+const sweepEvery = 10 * time.Minute
+
+// rateLimiter is a sliding-window limiter keyed by sender. It keeps the
+// timestamps of recent messages, at most an hour's worth per key.
+type rateLimiter struct {
+	mu        sync.Mutex
+	hits      map[string][]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{hits: make(map[string][]time.Time), now: time.Now}
+}
+
+func (l *rateLimiter) allow(key string, perMinute, perHour int) (bool, time.Duration) {
+	if perMinute <= 0 && perHour <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > sweepEvery {
+		for k, ts := range l.hits {
+			if len(ts) == 0 || now.Sub(ts[len(ts)-1]) > time.Hour {
+				delete(l.hits, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	ts := trimBefore(l.hits[key], now.Add(-time.Hour))
+	if wait := windowWait(ts, now, time.Minute, perMinute); wait > 0 {
+		l.hits[key] = ts
+		return false, wait
+	}
+	if wait := windowWait(ts, now, time.Hour, perHour); wait > 0 {
+		l.hits[key] = ts
+		return false, wait
+	}
+	l.hits[key] = append(ts, now)
+	return true, 0
+}
+
+// windowWait returns how long until ts holds fewer than limit entries within
+// window of now, or 0 when it already does.
+func windowWait(ts []time.Time, now time.Time, window time.Duration, limit int) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	inWindow := trimBefore(ts, now.Add(-window))
+	if len(inWindow) < limit {
+		return 0
+	}
+	return inWindow[len(inWindow)-limit].Add(window).Sub(now)
+}
+
+func trimBefore(ts []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(ts) && !ts[i].After(cutoff) {
+		i++
+	}
+	return ts[i:]
+}
@@ -0,0 +1,267 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/access"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// newAccessController builds the role controller for cfg.
+func newAccessController(cfg *config.Config) *access.Controller {
+	return access.New(cfg.Access, rolesPath())
+}
+
+// rolesPath is where runtime grants made with /role are saved. It lives
+// under PICOCLAW_HOME rather than a workspace so agents cannot rewrite it
+// with their file tools.
+func rolesPath() string {
+	return filepath.Join(config.GetHome(), "state", "roles.json")
+}
+
+func (al *AgentLoop) accessControl() *access.Controller {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+	return al.access
+}
+
+// senderIdentity returns msg.Sender with the platform fields filled from
+// the message for channels that do not populate them.
+func senderIdentity(msg bus.InboundMessage) bus.SenderInfo {
+	return fillSender(msg.Sender, msg.Channel, msg.SenderID)
+}
+
+// fillSender completes sender from the channel name and sender ID, which
+// may already be in canonical "platform:id" form.
+func fillSender(sender bus.SenderInfo, channel, senderID string) bus.SenderInfo {
+	if sender.Platform == "" {
+		sender.Platform = channel
+	}
+	if sender.PlatformID == "" {
+		sender.PlatformID = senderID
+		if platform, id, ok := identity.ParseCanonicalID(senderID); ok && strings.EqualFold(platform, sender.Platform) {
+			sender.PlatformID = id
+		}
+	}
+	if sender.CanonicalID == "" {
+		sender.CanonicalID = identity.BuildCanonicalID(sender.Platform, sender.PlatformID)
+	}
+	return sender
+}
+
+// senderRole returns the sender's role, or "" when access control is off.
+func (al *AgentLoop) senderRole(msg bus.InboundMessage) access.Role {
+	ac := al.accessControl()
+	if !ac.Enabled() {
+		return ""
+	}
+	return ac.RoleOf(senderIdentity(msg))
+}
+
+// applyAccessPolicy checks the sender's role and rate limit and narrows opts
+// to what the role may use. A non-empty reply means the message is refused.
+func (al *AgentLoop) applyAccessPolicy(msg bus.InboundMessage, agent *AgentInstance, opts *processOptions) string {
+	ac := al.accessControl()
+	if !ac.Enabled() {
+		return ""
+	}
+	sender := senderIdentity(msg)
+	role := ac.RoleOf(sender)
+	opts.Role = role
+
+	key := sender.CanonicalID
+	if key == "" {
+		key = msg.Channel + ":" + msg.ChatID
+	}
+	if ok, wait := ac.Allow(role, key); !ok {
+		logger.InfoCF("agent", "Sender rate limited",
+			map[string]any{"sender": key, "role": string(role), "retry_after": wait.String()})
+		return fmt.Sprintf("You are sending messages too quickly. Try again in %s.",
+			max(wait.Round(time.Second), time.Second))
+	}
+	if !ac.AllowAgent(role, agent.ID) {
+		return fmt.Sprintf("Your role (%s) cannot use agent %q.", role, agent.ID)
+	}
+	if !ac.AllowModel(role, agent.Model) {
+		fallback := ac.FallbackModel(role)
+		if fallback == "" {
+			return fmt.Sprintf("Your role (%s) cannot use model %q.", role, agent.Model)
+		}
+		sel, _, err := al.selectModel(al.GetConfig(), agent, fallback, nil)
+		if err != nil {
+			logger.WarnCF("agent", "Role fallback model unavailable",
+				map[string]any{"role": string(role), "model": fallback, "error": err.Error()})
+			return fmt.Sprintf("Your role (%s) cannot use model %q.", role, agent.Model)
+		}
+		opts.ModelOverride = sel
+	}
+	return ""
+}
+
+// steerOtherSenderReply is the reply to a message that would steer a turn
+// running for another sender or role.
+const steerOtherSenderReply = "A reply for someone else is still being generated in this chat; try again when it finishes."
+
+// steeringRefusal applies the access policy to a message that would be queued
+// as steering for active, and refuses it when active runs for a different
+// sender or role: steering would put words into a turn that uses that
+// sender's tools and model. It returns the reply to send instead, or "".
+func (al *AgentLoop) steeringRefusal(msg bus.InboundMessage, agent *AgentInstance, active *turnState) string {
+	if !al.accessControl().Enabled() {
+		return ""
+	}
+	msg = bus.NormalizeInboundMessage(msg)
+	var opts processOptions
+	if reply := al.applyAccessPolicy(msg, agent, &opts); reply != "" {
+		return reply
+	}
+	closeModelOverride(opts)
+	if active != nil && (active.opts.SenderID != msg.SenderID || active.opts.Role != opts.Role) {
+		logger.InfoCF("agent", "Refused steering from another sender",
+			map[string]any{"sender": msg.SenderID, "turn_sender": active.opts.SenderID})
+		return steerOtherSenderReply
+	}
+	return ""
+}
+
+// closeModelOverride releases a stateful provider created for a role
+// fallback model once the turn is done.
+func closeModelOverride(opts processOptions) {
+	if opts.ModelOverride == nil {
+		return
+	}
+	if stateful, ok := opts.ModelOverride.provider.(providers.StatefulProvider); ok {
+		stateful.Close()
+	}
+}
+
+// roleAllowsTool reports whether role may call the named tool. An empty
+// role (access control off, or an internal turn) allows everything.
+func (al *AgentLoop) roleAllowsTool(role access.Role, name string) bool {
+	return role == "" || al.accessControl().AllowTool(role, name)
+}
+
+func (al *AgentLoop) roleAllowsCommand(role access.Role, name string) bool {
+	return role == "" || al.accessControl().AllowCommand(role, name)
+}
+
+// checkRoleModel returns an error when role may not use model.
+func (al *AgentLoop) checkRoleModel(role access.Role, model string) error {
+	if role == "" || al.accessControl().AllowModel(role, model) {
+		return nil
+	}
+	return fmt.Errorf("your role (%s) cannot use model %q", role, model)
+}
+
+// toolDenial returns why opts forbid the named tool, or "" when it may run.
+func (al *AgentLoop) toolDenial(opts processOptions, name string) string {
+	if !toolAllowed(opts.AllowedTools, name) {
+		return fmt.Sprintf("Tool %q is not allowed for this command", name)
+	}
+	if !al.roleAllowsTool(opts.Role, name) {
+		return fmt.Sprintf("Tool %q is not allowed for your role (%s)", name, opts.Role)
+	}
+	return ""
+}
+
+// attachAccessRuntime limits command runtime callbacks to what the sender's
+// role allows and exposes role management to /role.
+func (al *AgentLoop) attachAccessRuntime(rt *commands.Runtime, opts *processOptions) {
+	ac := al.accessControl()
+	if !ac.Enabled() || opts == nil || opts.Role == "" {
+		return
+	}
+	role := opts.Role
+
+	rt.CanRunCommand = func(name string) bool { return ac.AllowCommand(role, name) }
+	if list := rt.ListDefinitions; list != nil {
+		rt.ListDefinitions = func() []commands.Definition {
+			defs := list()
+			allowed := make([]commands.Definition, 0, len(defs))
+			for _, def := range defs {
+				if ac.AllowCommand(role, def.Name) {
+					allowed = append(allowed, def)
+				}
+			}
+			return allowed
+		}
+	}
+	if switchModel := rt.SwitchModel; switchModel != nil {
+		rt.SwitchModel = func(value string) (string, error) {
+			if err := al.checkRoleModel(role, strings.TrimSpace(value)); err != nil {
+				return "", err
+			}
+			return switchModel(value)
+		}
+	}
+	if retry := rt.RetryLastMessage; retry != nil {
+		rt.RetryLastMessage = func(ctx context.Context, model string) (string, error) {
+			if model = strings.TrimSpace(model); model != "" {
+				if err := al.checkRoleModel(role, model); err != nil {
+					return "", err
+				}
+			}
+			return retry(ctx, model)
+		}
+	}
+	if runPrompt := rt.RunPrompt; runPrompt != nil {
+		rt.RunPrompt = func(ctx context.Context, run commands.PromptRun) (string, error) {
+			if model := strings.TrimSpace(run.Model); model != "" {
+				if err := al.checkRoleModel(role, model); err != nil {
+					return "", err
+				}
+			}
+			return runPrompt(ctx, run)
+		}
+	}
+
+	rt.GetSenderRole = func() (string, string) {
+		var id string
+		if in := opts.Dispatch.InboundContext; in != nil {
+			id = fillSender(bus.SenderInfo{}, in.Channel, in.SenderID).CanonicalID
+		}
+		return id, string(role)
+	}
+	rt.ListRoles = func() ([]commands.RoleGrant, error) {
+		if role != access.Owner && role != access.Admin {
+			return nil, errors.New("only owners and admins can list roles")
+		}
+		grants := ac.Grants()
+		out := make([]commands.RoleGrant, 0, len(grants))
+		for _, g := range grants {
+			out = append(out, commands.RoleGrant{Identity: g.Identity, Role: string(g.Role), Runtime: g.Runtime})
+		}
+		return out, nil
+	}
+	rt.SetRole = func(id, name string) error {
+		if role != access.Owner {
+			return errors.New("only owners can change roles")
+		}
+		target, ok := access.ParseRole(name)
+		if !ok {
+			return fmt.Errorf("unknown role %q; use owner, admin, member or guest", name)
+		}
+		if err := ac.SetRole(id, target); err != nil {
+			return err
+		}
+		logger.InfoCF("agent", "Role assigned",
+			map[string]any{"identity": id, "role": string(target), "by": opts.SenderID})
+		return nil
+	}
+	rt.RemoveRole = func(id string) (bool, error) {
+		if role != access.Owner {
+			return false, errors.New("only owners can change roles")
+		}
+		return ac.RemoveRole(id)
+	}
+}
@@ -0,0 +1,200 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+func newAccessTestLoop(t *testing.T, access config.AccessConfig) (*AgentLoop, *recordingProvider) {
+	t.Helper()
+	t.Setenv(config.EnvHome, t.TempDir())
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		Access: access,
+	}
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	al.RegisterTool(&mockCustomTool{})
+	return al, provider
+}
+
+func sendAs(t *testing.T, al *AgentLoop, senderID, content string) string {
+	t.Helper()
+	response, err := al.processMessage(context.Background(), bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: senderID,
+		ChatID:   "chat-" + senderID,
+		Content:  content,
+	})
+	if err != nil {
+		t.Fatalf("processMessage(%q) error = %v", content, err)
+	}
+	return response
+}
+
+func TestProcessMessage_AccessControlGuestRestrictions(t *testing.T) {
+	al, provider := newAccessTestLoop(t, config.AccessConfig{
+		Enabled:     true,
+		DefaultRole: "guest",
+		Users:       map[string]string{"telegram:1": "owner"},
+		Roles: map[string]config.RolePolicy{
+			"guest": {Rate: &config.RoleRateConfig{PerMinute: 2}},
+		},
+	})
+
+	if got := sendAs(t, al, "telegram:9", "/clear"); got != "You don't have permission to use /clear." {
+		t.Fatalf("guest /clear reply = %q", got)
+	}
+	if got := sendAs(t, al, "telegram:9", "hello"); got != "Mock response" {
+		t.Fatalf("guest message reply = %q", got)
+	}
+	if len(provider.lastTools) != 0 {
+		t.Fatalf("guest was offered %d tools, want none", len(provider.lastTools))
+	}
+	if got := sendAs(t, al, "telegram:9", "again"); !strings.HasPrefix(got, "You are sending messages too quickly") {
+		t.Fatalf("third guest message reply = %q, want rate limit", got)
+	}
+
+	if got := sendAs(t, al, "telegram:1", "hello"); got != "Mock response" {
+		t.Fatalf("owner reply = %q", got)
+	}
+	if len(provider.lastTools) == 0 {
+		t.Fatal("owner should be offered tools")
+	}
+}
+
+func TestProcessMessage_RoleCommandManagesGrants(t *testing.T) {
+	al, _ := newAccessTestLoop(t, config.AccessConfig{
+		Enabled: true,
+		Users:   map[string]string{"telegram:1": "owner"},
+	})
+
+	if got := sendAs(t, al, "telegram:2", "/role set telegram:3 admin"); got != "only owners can change roles" {
+		t.Fatalf("member /role set reply = %q", got)
+	}
+	if got := sendAs(t, al, "telegram:1", "/role set telegram:2 guest"); got != "Set role of telegram:2 to guest" {
+		t.Fatalf("owner /role set reply = %q", got)
+	}
+	if got := sendAs(t, al, "telegram:2", "/role me"); got != "You are telegram:2 with role guest" {
+		t.Fatalf("/role me reply = %q", got)
+	}
+	if got := sendAs(t, al, "telegram:2", "/reload"); got != "You don't have permission to use /reload." {
+		t.Fatalf("guest /reload reply = %q", got)
+	}
+}
+
+func TestRolesPath_OutsideWorkspace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(config.EnvHome, home)
+	cfg := &config.Config{Agents: config.AgentsConfig{Defaults: config.AgentDefaults{
+		Workspace: filepath.Join(home, "workspace"),
+	}}}
+
+	path := rolesPath()
+	if want := filepath.Join(home, "state", "roles.json"); path != want {
+		t.Fatalf("rolesPath() = %q, want %q", path, want)
+	}
+	rel, err := filepath.Rel(cfg.WorkspacePath(), path)
+	if err != nil || !strings.HasPrefix(rel, "..") {
+		t.Fatalf("rolesPath() = %q is inside workspace %q", path, cfg.WorkspacePath())
+	}
+}
+
+func TestSpawnSubTurn_KeepsParentToolRestrictions(t *testing.T) {
+	al, provider := newAccessTestLoop(t, config.AccessConfig{Enabled: true, DefaultRole: "guest"})
+	agent := al.GetRegistry().GetDefaultAgent()
+
+	for name, opts := range map[string]processOptions{
+		"role":          {Role: "guest"},
+		"allowed tools": {AllowedTools: []string{"read_file"}},
+	} {
+		parent := &turnState{
+			ctx:            context.Background(),
+			turnID:         "parent-" + name,
+			pendingResults: make(chan *tools.ToolResult, 1),
+			session:        &ephemeralSessionStore{},
+			agent:          agent,
+			opts:           opts,
+		}
+		if _, err := spawnSubTurn(context.Background(), al, parent, SubTurnConfig{Model: "test-model"}); err != nil {
+			t.Fatalf("%s: spawnSubTurn() error = %v", name, err)
+		}
+		for _, td := range provider.lastTools {
+			if td.Function.Name == "mock_custom" {
+				t.Fatalf("%s: sub-turn was offered mock_custom, which the parent may not use", name)
+			}
+		}
+	}
+}
+
+func TestClaimOrSteer_AppliesAccessPolicyToSteering(t *testing.T) {
+	al, _ := newAccessTestLoop(t, config.AccessConfig{
+		Enabled:     true,
+		DefaultRole: "guest",
+		Users:       map[string]string{"telegram:1": "owner"},
+		Roles: map[string]config.RolePolicy{
+			"guest": {Rate: &config.RoleRateConfig{PerMinute: 1}},
+		},
+	})
+	outbound := al.bus.(*bus.MessageBus).OutboundChan()
+
+	inGroup := func(senderID, content string) (bus.InboundMessage, *messageRoute) {
+		msg := bus.NormalizeInboundMessage(bus.InboundMessage{
+			Context: bus.InboundContext{
+				Channel:  "telegram",
+				ChatID:   "group-1",
+				ChatType: "group",
+				SenderID: senderID,
+			},
+			Content: content,
+		})
+		mr, err := al.routeMessage(msg)
+		if err != nil {
+			t.Fatalf("routeMessage() error = %v", err)
+		}
+		return msg, mr
+	}
+	steer := func(senderID, content string) string {
+		t.Helper()
+		msg, mr := inGroup(senderID, content)
+		al.claimOrSteer(context.Background(), msg, mr)
+		select {
+		case out := <-outbound:
+			return out.Content
+		default:
+			return ""
+		}
+	}
+
+	_, mr := inGroup("telegram:9", "start")
+	al.activeTurnStates.Store(mr.sessionKey, &turnState{
+		turnID: "guest-turn",
+		opts:   processOptions{SenderID: "telegram:9", Role: "guest"},
+	})
+
+	if got := steer("telegram:9", "more"); got != "" {
+		t.Fatalf("guest steering its own turn got reply %q", got)
+	}
+	if got := steer("telegram:9", "even more"); !strings.HasPrefix(got, "You are sending messages too quickly") {
+		t.Fatalf("rate-limited steering reply = %q", got)
+	}
+	if got := steer("telegram:1", "owner words"); got != steerOtherSenderReply {
+		t.Fatalf("owner steering a guest turn reply = %q", got)
+	}
+	if got := al.pendingSteeringCountForScope(mr.sessionKey); got != 1 {
+		t.Fatalf("queued steering messages = %d, want 1", got)
+	}
+}
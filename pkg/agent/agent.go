@@ -10,11 +10,11 @@ import (
 	"context"
 	"fmt"
 	"regexp"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/access"
 	"github.com/sipeed/picoclaw/pkg/agent/interfaces"
 	"github.com/sipeed/picoclaw/pkg/audio/asr"
 	"github.com/sipeed/picoclaw/pkg/bus"
@@ -36,6 +36,8 @@ type AgentLoop struct {
 	cfg      *config.Config
 	registry *AgentRegistry
 	state    *state.Manager
+	access   *access.Controller
+	links    *session.LinkStore
 
 	// Event system (from Incoming)
 	eventBus *EventBus
@@ -62,6 +64,9 @@ type AgentLoop struct {
 	activeTurnStates sync.Map
 	subTurnCounter   atomic.Int64
 
+	// intake orders the routing of inbound messages per chat.
+	intake intakeLanes
+
 	turnSeq        atomic.Uint64
 	activeRequests sync.WaitGroup
 
@@ -96,6 +101,9 @@ type processOptions struct {
 	InboundContext          *bus.InboundContext    // Normalized inbound facts for events/hooks
 	RouteResult             *routing.ResolvedRoute // Route decision snapshot for events/hooks
 	SessionScope            *session.SessionScope  // Session scope snapshot for events/hooks
+	ModelOverride           *modelSelection        // One-turn model override (used by /retry <model>)
+	AllowedTools            []string               // Tool allowlist for this turn; empty means all (used by custom commands)
+	Role                    access.Role            // Sender role under access control; empty means unrestricted
 }
 
 type continuationTarget struct {
@@ -121,6 +129,7 @@ const (
 	metadataKeyReplyToMessage  = "reply_to_message_id"
 	metadataKeyParentPeerKind  = "parent_peer_kind"
 	metadataKeyParentPeerID    = "parent_peer_id"
+	voiceBargeInHint           = "The user started speaking again. Stop here and give a brief final reply; their new message follows."
 )
 
 // registerSharedTools registers tools that are shared across all agents (web, message, spawn).
@@ -151,92 +160,14 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 				return nil
 			}
 
-			// Resolve the session key for this message
-			sessionKey, agentID, ok := al.resolveSteeringTarget(msg)
-			if !ok {
-				// Non-routable message (e.g., system) — process immediately.
-				// Note: system messages are processed in the main goroutine,
-				// so they block the receive loop but guarantee session serialization.
+			// Non-routable (system) messages are processed here, which blocks
+			// the receive loop but guarantees session serialization.
+			if msg.Channel == "system" {
 				al.processMessageSync(ctx, msg)
 				continue
 			}
-
-			// Atomically claim the session key with a unique placeholder sentinel
-			// to prevent a TOCTOU race where multiple messages for the same session
-			// pass the Load check before either registers.
-			// The placeholder ensures GetActiveTurnBySession() never returns nil
-			// during turn setup. Each placeholder has a unique turnID to prevent
-			// cross-worker cleanup issues.
-			placeholder := &turnState{
-				turnID: makePendingTurnID(sessionKey, al.turnSeq.Add(1)),
-				phase:  TurnPhaseSetup,
-			}
-			if _, loaded := al.activeTurnStates.LoadOrStore(sessionKey, placeholder); loaded {
-				// Another turn is already active (or reserved) for this session — enqueue
-				if err := al.enqueueSteeringMessage(sessionKey, agentID, providers.Message{
-					Role:    "user",
-					Content: msg.Content,
-					Media:   append([]string(nil), msg.Media...),
-				}); err != nil {
-					logger.WarnCF("agent", "Failed to enqueue steering message",
-						map[string]any{
-							"error":       err.Error(),
-							"channel":     msg.Channel,
-							"chat_id":     msg.ChatID,
-							"session_key": sessionKey,
-						})
-				}
-				continue
-			}
-
-			// Session claimed — spawn a worker goroutine that acquires a semaphore
-			// slot. The goroutine is spawned immediately so the main loop keeps
-			// draining the inbound channel. The goroutine blocks on the semaphore.
-			go func(m bus.InboundMessage) {
-				// Acquire semaphore slot (blocks if at capacity)
-				select {
-				case al.workerSem <- struct{}{}:
-					// Got slot, start worker
-				case <-ctx.Done():
-					// Context canceled while waiting for a slot — clean up the
-					// placeholder to prevent session-level deadlock.
-					al.activeTurnStates.Delete(sessionKey)
-					return
-				}
-
-				// Safety-net cleanup: if the placeholder was never replaced by a real
-				// turnState (e.g., error before runTurn), delete it here. When runTurn
-				// completes normally, clearActiveTurn deletes the real turnState and
-				// this becomes a no-op (the key is already gone).
-				defer func() {
-					if actual, ok := al.activeTurnStates.Load(sessionKey); ok {
-						if ts, ok := actual.(*turnState); ok && strings.HasPrefix(ts.turnID, pendingTurnPrefix) {
-							// Placeholder still present — runTurn never replaced it.
-							al.activeTurnStates.Delete(sessionKey)
-						}
-					}
-				}()
-
-				defer func() {
-					if r := recover(); r != nil {
-						logger.RecoverPanicNoExit(r)
-						logger.ErrorCF("agent", "Worker goroutine panicked",
-							map[string]any{
-								"session_key": sessionKey,
-								"channel":     m.Channel,
-								"chat_id":     m.ChatID,
-								"panic":       fmt.Sprintf("%v", r),
-							})
-					}
-				}()
-				defer func() { <-al.workerSem }() // Release slot
-
-				if al.channelManager != nil {
-					defer al.channelManager.InvokeTypingStop(m.Channel, m.ChatID)
-				}
-
-				al.runTurnWithSteering(ctx, m)
-			}(msg)
+			// Routing may consult the triage model, so it runs off this loop.
+			al.intakeMessage(ctx, msg)
 
 			// TODO: Re-enable media cleanup after inbound media is properly consumed by the agent.
 			// Currently disabled because files are deleted before the LLM can access their content.
@@ -364,6 +295,11 @@ func (al *AgentLoop) ReloadProviderAndConfig(
 	// Ensure shared tools are re-registered on the new registry
 	registerSharedTools(al, cfg, al.bus, registry, provider)
 
+	// Pick up added or edited workspace commands.
+	cmdRegistry := commands.NewRegistry(commands.WorkspaceDefinitions(cfg.WorkspacePath()))
+	accessControl := newAccessController(cfg)
+	links := installLinkStore()
+
 	// Atomically swap the config and registry under write lock
 	// This ensures readers see a consistent pair
 	al.mu.Lock()
@@ -372,13 +308,18 @@ func (al *AgentLoop) ReloadProviderAndConfig(
 	// Store new values
 	al.cfg = cfg
 	al.registry = registry
+	al.cmdRegistry = cmdRegistry
+	al.access = accessControl
+	al.links = links
 
 	// Also update fallback chain with new config; rebuild rate limiter registry.
 	newRL := providers.NewRateLimiterRegistry()
 	for _, agentID := range registry.ListAgentIDs() {
 		if agent, ok := registry.GetAgent(agentID); ok {
 			newRL.RegisterCandidates(agent.Candidates)
-			newRL.RegisterCandidates(agent.LightCandidates)
+			for _, target := range agent.RouteTargets {
+				newRL.RegisterCandidates(target.Candidates)
+			}
 		}
 	}
 	al.fallback = providers.NewFallbackChain(providers.NewCooldownTracker(), newRL)
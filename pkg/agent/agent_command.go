@@ -5,6 +5,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -14,6 +15,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/session"
 )
 
 func (al *AgentLoop) handleCommand(
@@ -32,12 +34,13 @@ func (al *AgentLoop) handleCommand(
 		return reply, handled
 	}
 
-	if al.cmdRegistry == nil {
+	registry := al.commandRegistry()
+	if registry == nil {
 		return "", false
 	}
 
 	rt := al.buildCommandsRuntime(ctx, agent, opts)
-	executor := commands.NewExecutor(al.cmdRegistry, rt)
+	executor := commands.NewExecutor(registry, rt)
 
 	var commandReply string
 	result := executor.Execute(ctx, commands.Request{
@@ -77,6 +80,10 @@ func (al *AgentLoop) applyExplicitSkillCommand(
 		return false, false, ""
 	}
 
+	if opts != nil && !al.roleAllowsCommand(opts.Role, "use") {
+		return true, true, "You don't have permission to use /use."
+	}
+
 	if agent == nil || agent.ContextBuilder == nil {
 		return true, true, commandsUnavailableSkillMessage()
 	}
@@ -134,9 +141,14 @@ func (al *AgentLoop) buildCommandsRuntime(
 	registry := al.GetRegistry()
 	cfg := al.GetConfig()
 	rt := &commands.Runtime{
-		Config:          cfg,
-		ListAgentIDs:    registry.ListAgentIDs,
-		ListDefinitions: al.cmdRegistry.Definitions,
+		Config:       cfg,
+		ListAgentIDs: registry.ListAgentIDs,
+		ListDefinitions: func() []commands.Definition {
+			if registry := al.commandRegistry(); registry != nil {
+				return registry.Definitions()
+			}
+			return nil
+		},
 		ListMCPServers: func(ctx context.Context) []commands.MCPServerInfo {
 			if cfg == nil {
 				return nil
@@ -292,20 +304,11 @@ func (al *AgentLoop) buildCommandsRuntime(
 		}
 		rt.SwitchModel = func(value string) (string, error) {
 			value = strings.TrimSpace(value)
-			modelCfg, err := resolvedModelConfig(cfg, value, agent.Workspace)
+			sel, modelCfg, err := al.selectModel(cfg, agent, value, agent.Fallbacks)
 			if err != nil {
 				return "", err
 			}
-
-			nextProvider, _, err := providers.CreateProviderFromConfig(modelCfg)
-			if err != nil {
-				return "", fmt.Errorf("failed to initialize model %q: %w", value, err)
-			}
-
-			nextCandidates := resolveModelCandidates(cfg, cfg.Agents.Defaults.Provider, value, agent.Fallbacks)
-			if len(nextCandidates) == 0 {
-				return "", fmt.Errorf("model %q did not resolve to any provider candidates", value)
-			}
+			nextProvider, nextCandidates := sel.provider, sel.candidates
 
 			oldModel := agent.Model
 			oldProvider := agent.Provider
@@ -329,6 +332,53 @@ func (al *AgentLoop) buildCommandsRuntime(
 			return al.contextManager.Clear(ctx, opts.SessionKey)
 		}
 
+		rt.StopTurn = func() (bool, int, error) {
+			if opts == nil {
+				return false, 0, fmt.Errorf("process options not available")
+			}
+			if !al.sessionTurnRunning(opts.SessionKey) {
+				return false, 0, nil
+			}
+			if err := al.InterruptHardSession(opts.SessionKey); err != nil {
+				return false, 0, err
+			}
+			dropped := len(al.dequeueSteeringMessagesForScope(opts.SessionKey))
+			return true, dropped, nil
+		}
+
+		rt.RetryLastMessage = func(ctx context.Context, model string) (string, error) {
+			if opts == nil {
+				return "", fmt.Errorf("process options not available")
+			}
+			return al.retryLastMessage(ctx, agent, *opts, strings.TrimSpace(model))
+		}
+
+		rt.RunPrompt = func(ctx context.Context, run commands.PromptRun) (string, error) {
+			if opts == nil {
+				return "", fmt.Errorf("process options not available")
+			}
+			return al.runCustomPrompt(ctx, agent, *opts, run)
+		}
+
+		rt.CompactContext = func(ctx context.Context) (int, int, error) {
+			if opts == nil || agent.Sessions == nil {
+				return 0, 0, fmt.Errorf("process options not available")
+			}
+			if al.sessionTurnRunning(opts.SessionKey) {
+				return 0, 0, errSessionBusy
+			}
+			before := len(agent.Sessions.GetHistory(opts.SessionKey))
+			if err := al.contextManager.Compact(ctx, &CompactRequest{
+				SessionKey: opts.SessionKey,
+				Reason:     ContextCompressReasonManual,
+				Budget:     agent.ContextWindow,
+				AgentID:    agent.ID,
+			}); err != nil {
+				return 0, 0, err
+			}
+			return before, len(agent.Sessions.GetHistory(opts.SessionKey)), nil
+		}
+
 		rt.AskSideQuestion = func(ctx context.Context, question string) (string, error) {
 			return al.askSideQuestion(ctx, agent, opts, question)
 		}
@@ -342,18 +392,240 @@ func (al *AgentLoop) buildCommandsRuntime(
 				return nil
 			}
 			history := agent.Sessions.GetHistory(opts.SessionKey)
-			return &commands.ContextStats{
+			stats := &commands.ContextStats{
 				UsedTokens:       usage.UsedTokens,
 				TotalTokens:      usage.TotalTokens,
 				CompressAtTokens: usage.CompressAtTokens,
 				UsedPercent:      usage.UsedPercent,
 				MessageCount:     len(history),
 			}
+			if d, ok := agent.routeLog.last(opts.SessionKey); ok {
+				model := d.Model
+				if d.Primary() {
+					model = agent.Model
+				}
+				stats.Routing = &commands.RoutingStats{
+					Tier:    d.Tier,
+					Model:   model,
+					Score:   d.Score,
+					Source:  d.Source,
+					Skipped: d.Skipped,
+				}
+			}
+			return stats
 		}
 	}
+	al.attachLinkRuntime(rt, opts)
+	al.attachAccessRuntime(rt, opts)
 	return rt
 }
 
+// sessionControlCommands may run while a turn for the same session is still
+// active. Everything else queues behind the turn as steering.
+var sessionControlCommands = map[string]struct{}{
+	"stop":    {},
+	"retry":   {},
+	"compact": {},
+}
+
+func (al *AgentLoop) isSessionControlCommand(content string) bool {
+	_, ok := al.sessionControlCommandName(content)
+	return ok
+}
+
+func (al *AgentLoop) sessionControlCommandName(content string) (string, bool) {
+	registry := al.commandRegistry()
+	if registry == nil {
+		return "", false
+	}
+	name, ok := commands.CommandName(content)
+	if !ok {
+		return "", false
+	}
+	def, found := registry.Lookup(name)
+	if !found {
+		return "", false
+	}
+	_, ok = sessionControlCommands[def.Name]
+	return def.Name, ok
+}
+
+// handleBusySessionCommand runs a session control command while another turn
+// owns the session. It shares command dispatch with processMessage but skips
+// the per-round resets that belong to the running turn. mr is the message's
+// resolved route.
+func (al *AgentLoop) handleBusySessionCommand(ctx context.Context, msg bus.InboundMessage, mr *messageRoute) {
+	msg = bus.NormalizeInboundMessage(msg)
+	route, agent, allocation, sessionKey := mr.route, mr.agent, mr.allocation, mr.sessionKey
+
+	// Only /stop acts on the running turn; the rest would race it, and the
+	// running turn may still be a placeholder that sessionTurnRunning ignores.
+	if name, _ := al.sessionControlCommandName(msg.Content); name != "stop" {
+		al.PublishResponseIfNeeded(ctx, msg.Channel, msg.ChatID, sessionKey,
+			fmt.Sprintf("/%s is unavailable: %v", name, errSessionBusy))
+		return
+	}
+
+	opts := processOptions{
+		Dispatch: DispatchRequest{
+			SessionKey:     sessionKey,
+			SessionAliases: buildSessionAliases(sessionKey, append(allocation.SessionAliases, msg.SessionKey)...),
+			InboundContext: cloneInboundContext(&msg.Context),
+			RouteResult:    cloneResolvedRoute(&route),
+			SessionScope:   session.CloneScope(&allocation.Scope),
+			UserMessage:    msg.Content,
+		},
+		SenderID:          msg.SenderID,
+		SenderDisplayName: msg.Sender.DisplayName,
+		Role:              al.senderRole(msg),
+	}
+
+	response, _ := al.handleCommand(ctx, msg, agent, &opts)
+	al.PublishResponseIfNeeded(ctx, msg.Channel, msg.ChatID, sessionKey, response)
+}
+
+// errSessionBusy is returned by session commands that must not run while a
+// turn for the same session is still in flight.
+var errSessionBusy = errors.New("a reply is still being generated in this chat; use /stop first")
+
+// modelSelection is a resolved provider and candidate list for one model.
+type modelSelection struct {
+	model      string
+	provider   providers.LLMProvider
+	candidates []providers.FallbackCandidate
+}
+
+// selectModel resolves value against model_list and builds a fresh provider
+// for it without touching the agent.
+func (al *AgentLoop) selectModel(
+	cfg *config.Config,
+	agent *AgentInstance,
+	value string,
+	fallbacks []string,
+) (*modelSelection, *config.ModelConfig, error) {
+	modelCfg, err := resolvedModelConfig(cfg, value, agent.Workspace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider, _, err := providers.CreateProviderFromConfig(modelCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize model %q: %w", value, err)
+	}
+
+	candidates := resolveModelCandidates(cfg, cfg.Agents.Defaults.Provider, value, fallbacks)
+	if len(candidates) == 0 {
+		return nil, nil, fmt.Errorf("model %q did not resolve to any provider candidates", value)
+	}
+
+	return &modelSelection{model: value, provider: provider, candidates: candidates}, modelCfg, nil
+}
+
+// sessionTurnRunning reports whether a real turn (not the placeholder that
+// Run reserves for the command message itself) is active for sessionKey.
+func (al *AgentLoop) sessionTurnRunning(sessionKey string) bool {
+	ts := al.getActiveTurnState(sessionKey)
+	return ts != nil && !strings.HasPrefix(ts.turnID, pendingTurnPrefix)
+}
+
+// retryLastMessage rolls the session back to just before the most recent user
+// message and runs that message again as a fresh turn.
+func (al *AgentLoop) retryLastMessage(
+	ctx context.Context,
+	agent *AgentInstance,
+	opts processOptions,
+	model string,
+) (string, error) {
+	if agent.Sessions == nil {
+		return "", fmt.Errorf("sessions not initialized")
+	}
+	if al.sessionTurnRunning(opts.SessionKey) {
+		return "", errSessionBusy
+	}
+
+	history := agent.Sessions.GetHistory(opts.SessionKey)
+	last := -1
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		return "", fmt.Errorf("no previous message to retry")
+	}
+	userMsg := history[last]
+
+	if model != "" {
+		sel, _, err := al.selectModel(al.GetConfig(), agent, model, nil)
+		if err != nil {
+			return "", err
+		}
+		if stateful, ok := sel.provider.(providers.StatefulProvider); ok {
+			defer stateful.Close()
+		}
+		opts.ModelOverride = sel
+	}
+
+	agent.Sessions.SetHistory(opts.SessionKey, history[:last])
+	if err := agent.Sessions.Save(opts.SessionKey); err != nil {
+		return "", err
+	}
+
+	logger.InfoCF("agent", "Retrying last user message",
+		map[string]any{
+			"session_key": opts.SessionKey,
+			"dropped":     len(history) - last,
+			"model":       model,
+		})
+
+	opts.Dispatch.UserMessage = userMsg.Content
+	opts.UserMessage = userMsg.Content
+	opts.Dispatch.Media = append([]string(nil), userMsg.Media...)
+	opts.Media = append([]string(nil), userMsg.Media...)
+	return al.runAgentLoop(ctx, agent, opts)
+}
+
+// runCustomPrompt runs a rendered custom command as a regular turn, so the
+// prompt and reply land in the session history like a typed message.
+func (al *AgentLoop) runCustomPrompt(
+	ctx context.Context,
+	agent *AgentInstance,
+	opts processOptions,
+	run commands.PromptRun,
+) (string, error) {
+	if al.sessionTurnRunning(opts.SessionKey) {
+		return "", errSessionBusy
+	}
+	if strings.TrimSpace(run.Prompt) == "" {
+		return "", fmt.Errorf("rendered prompt is empty")
+	}
+
+	if model := strings.TrimSpace(run.Model); model != "" {
+		sel, _, err := al.selectModel(al.GetConfig(), agent, model, nil)
+		if err != nil {
+			return "", err
+		}
+		if stateful, ok := sel.provider.(providers.StatefulProvider); ok {
+			defer stateful.Close()
+		}
+		opts.ModelOverride = sel
+	}
+
+	logger.InfoCF("agent", "Running custom command",
+		map[string]any{
+			"session_key":   opts.SessionKey,
+			"command":       run.Command,
+			"model":         run.Model,
+			"allowed_tools": run.AllowedTools,
+		})
+
+	opts.Dispatch.UserMessage = run.Prompt
+	opts.UserMessage = run.Prompt
+	opts.AllowedTools = run.AllowedTools
+	return al.runAgentLoop(ctx, agent, opts)
+}
+
 func summarizeMCPToolParameters(schema any) []commands.MCPToolParameterInfo {
 	schemaMap := normalizeMCPSchema(schema)
 	properties, ok := schemaMap["properties"].(map[string]any)
@@ -0,0 +1,68 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/document"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const defaultDocumentSummaryChars = 600
+
+// summarizeDocumentsInMessage appends a short summary of each received
+// document (format, page count, opening text) to msg.Content. The media refs
+// are kept, so the file path tag still reaches the LLM for read_document.
+func (al *AgentLoop) summarizeDocumentsInMessage(msg bus.InboundMessage) bus.InboundMessage {
+	if al.mediaStore == nil || len(msg.Media) == 0 || al.cfg == nil {
+		return msg
+	}
+	docCfg := al.cfg.Tools.ReadDocument
+	if !docCfg.AttachmentSummary {
+		return msg
+	}
+	maxChars := docCfg.SummaryChars
+	if maxChars <= 0 {
+		maxChars = defaultDocumentSummaryChars
+	}
+
+	var summaries []string
+	for _, ref := range msg.Media {
+		path, meta, err := al.mediaStore.ResolveWithMeta(ref)
+		if err != nil || !document.Supported(meta.Filename, meta.ContentType) {
+			continue
+		}
+		name := meta.Filename
+		if name == "" {
+			name = ref
+		}
+		doc, err := document.ExtractFile(path, meta.ContentType)
+		if err != nil {
+			logger.WarnCF("agent", "Document extraction failed", map[string]any{
+				"ref":   ref,
+				"file":  name,
+				"error": err.Error(),
+			})
+			summaries = append(summaries, fmt.Sprintf("[document %s: text could not be extracted]", name))
+			continue
+		}
+		summaries = append(summaries, fmt.Sprintf("[document %s: %s]", name, doc.Summary(maxChars)))
+	}
+	if len(summaries) == 0 {
+		return msg
+	}
+
+	if al.cfg.Tools.IsToolEnabled("read_document") {
+		summaries = append(summaries, "(Use read_document with the file path to read the full text.)")
+	}
+	block := strings.Join(summaries, "\n")
+	if strings.TrimSpace(msg.Content) == "" {
+		msg.Content = block
+	} else {
+		msg.Content += "\n\n" + block
+	}
+	return msg
+}
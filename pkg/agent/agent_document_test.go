@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+func writeTestDOCX(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`<w:document xmlns:w="w"><w:body><w:p><w:r><w:t>` + text + `</w:t></w:r></w:p></w:body></w:document>`))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSummarizeDocumentsInMessage(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Tools.ReadDocument.AttachmentSummary = true
+	cfg.Tools.ReadDocument.Enabled = true
+	cfg.Tools.ReadDocument.SummaryChars = 12
+
+	store := media.NewFileMediaStore()
+	al.SetMediaStore(store)
+	dir := t.TempDir()
+	docPath := filepath.Join(dir, "upload")
+	writeTestDOCX(t, docPath, "Minutes of the board meeting")
+	docRef, err := store.Store(docPath, media.MediaMeta{Filename: "minutes.docx"}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgPath := filepath.Join(dir, "photo.jpg")
+	os.WriteFile(imgPath, []byte("jpeg"), 0o644)
+	imgRef, err := store.Store(imgPath, media.MediaMeta{Filename: "photo.jpg", ContentType: "image/jpeg"}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := al.summarizeDocumentsInMessage(bus.InboundMessage{
+		Content: "please review [file: minutes.docx]",
+		Media:   []string{docRef, imgRef},
+	})
+
+	want := "please review [file: minutes.docx]\n\n" +
+		"[document minutes.docx: DOCX, 1 page\nMinutes of t…]\n" +
+		"(Use read_document with the file path to read the full text.)"
+	if msg.Content != want {
+		t.Errorf("Content = %q, want %q", msg.Content, want)
+	}
+	if len(msg.Media) != 2 {
+		t.Errorf("media refs should be kept, got %v", msg.Media)
+	}
+}
+
+func TestSummarizeDocumentsInMessage_Disabled(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	store := media.NewFileMediaStore()
+	al.SetMediaStore(store)
+	docPath := filepath.Join(t.TempDir(), "a.docx")
+	writeTestDOCX(t, docPath, "text")
+	ref, _ := store.Store(docPath, media.MediaMeta{Filename: "a.docx"}, "test")
+
+	msg := al.summarizeDocumentsInMessage(bus.InboundMessage{Content: "hi", Media: []string{ref}})
+	if strings.Contains(msg.Content, "[document") {
+		t.Errorf("summary added while disabled: %q", msg.Content)
+	}
+}
@@ -65,8 +65,15 @@ func (al *AgentLoop) logEvent(evt Event) {
 	}
 
 	appendEventContextFields(fields, evt.Context)
+	appendEventPayloadFields(fields, evt.Payload)
 
-	switch payload := evt.Payload.(type) {
+	logger.InfoCF("eventbus", fmt.Sprintf("Agent event: %s", evt.Kind.String()), fields)
+}
+
+// appendEventPayloadFields adds the loggable fields of an event payload.
+// Message and argument contents are reduced to their lengths.
+func appendEventPayloadFields(fields map[string]any, payload any) {
+	switch payload := payload.(type) {
 	case TurnStartPayload:
 		fields["user_len"] = len(payload.UserMessage)
 		fields["media_count"] = payload.MediaCount
@@ -130,6 +137,13 @@ func (al *AgentLoop) logEvent(evt Event) {
 	case SubTurnSpawnPayload:
 		fields["child_agent_id"] = payload.AgentID
 		fields["label"] = payload.Label
+		if payload.Peer != "" {
+			fields["peer"] = payload.Peer
+		}
+	case SubTurnProgressPayload:
+		fields["peer"] = payload.Peer
+		fields["task_id"] = payload.TaskID
+		fields["tool"] = payload.Tool
 	case SubTurnEndPayload:
 		fields["child_agent_id"] = payload.AgentID
 		fields["status"] = payload.Status
@@ -141,8 +155,6 @@ func (al *AgentLoop) logEvent(evt Event) {
 		fields["stage"] = payload.Stage
 		fields["error"] = payload.Message
 	}
-
-	logger.InfoCF("eventbus", fmt.Sprintf("Agent event: %s", evt.Kind.String()), fields)
 }
 
 // MountHook registers an in-process hook on the agent loop.
@@ -17,6 +17,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/swarm"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
@@ -35,7 +36,9 @@ func NewAgentLoop(
 	for _, agentID := range registry.ListAgentIDs() {
 		if agent, ok := registry.GetAgent(agentID); ok {
 			rl.RegisterCandidates(agent.Candidates)
-			rl.RegisterCandidates(agent.LightCandidates)
+			for _, target := range agent.RouteTargets {
+				rl.RegisterCandidates(target.Candidates)
+			}
 		}
 	}
 	fallbackChain := providers.NewFallbackChain(cooldown, rl)
@@ -60,9 +63,11 @@ func NewAgentLoop(
 		cfg:         cfg,
 		registry:    registry,
 		state:       stateManager,
+		access:      newAccessController(cfg),
+		links:       installLinkStore(),
 		eventBus:    eventBus,
 		fallback:    fallbackChain,
-		cmdRegistry: commands.NewRegistry(commands.BuiltinDefinitions()),
+		cmdRegistry: commands.NewRegistry(commands.WorkspaceDefinitions(cfg.WorkspacePath())),
 		steering:    newSteeringQueue(parseSteeringMode(cfg.Agents.Defaults.SteeringMode)),
 		workerSem:   make(chan struct{}, workerPoolSize),
 	}
@@ -93,6 +98,11 @@ func registerSharedTools(
 		}
 	}
 
+	var peers *swarm.Peers
+	if cfg.Swarm.Enabled {
+		peers = swarm.NewPeers(cfg.Swarm.Peers)
+	}
+
 	for _, agentID := range registry.ListAgentIDs() {
 		agent, ok := registry.GetAgent(agentID)
 		if !ok {
@@ -301,6 +311,16 @@ func registerSharedTools(
 				return spawnSubTurn(ctx, al, parentTS, cfg)
 			})
 
+			// Let spawn hand tasks to remote PicoClaw peers in swarm mode.
+			if peerNames := peers.Names(); len(peerNames) > 0 {
+				subagentManager.SetRemoteDelegator(peerNames, func(
+					ctx context.Context,
+					peer, task, label, targetAgentID string,
+				) (*tools.ToolResult, error) {
+					return al.delegateToPeer(ctx, peers, peer, task, label, targetAgentID)
+				})
+			}
+
 			// Clone the parent's tool registry so subagents can use all
 			// tools registered so far (file, web, etc.) but NOT spawn/
 			// spawn_status which are added below — preventing recursive
@@ -5,7 +5,9 @@ package agent
 import (
 	"github.com/sipeed/picoclaw/pkg/audio/asr"
 	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/commands"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
@@ -21,6 +23,14 @@ func (al *AgentLoop) RegisterTool(tool tools.Tool) {
 
 func (al *AgentLoop) SetChannelManager(cm *channels.Manager) {
 	al.channelManager = cm
+	if cm == nil {
+		al.UnmountHook(channelToolApprovalHookName)
+		return
+	}
+	if err := al.MountHook(NamedHook(channelToolApprovalHookName, channelToolApprover{channels: cm})); err != nil {
+		logger.WarnCF("agent", "Failed to mount channel tool approval hook",
+			map[string]any{"error": err.Error()})
+	}
 }
 
 func (al *AgentLoop) GetRegistry() *AgentRegistry {
@@ -35,6 +45,12 @@ func (al *AgentLoop) GetConfig() *config.Config {
 	return al.cfg
 }
 
+func (al *AgentLoop) commandRegistry() *commands.Registry {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+	return al.cmdRegistry
+}
+
 func (al *AgentLoop) SetMediaStore(s media.MediaStore) {
 	al.mediaStore = s
 
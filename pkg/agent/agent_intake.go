@@ -0,0 +1,183 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// intakeLanes keeps the messages of one chat in arrival order while their
+// routes are resolved concurrently. Resolving a route may call the triage
+// model, so it must not run on the bus receive loop; a message only claims
+// its session once the previous message of the same chat has.
+type intakeLanes struct {
+	mu    sync.Mutex
+	tails map[string]chan struct{}
+}
+
+// enter appends a message to the lane of key. The caller waits on prev
+// (nil for an empty lane) before claiming its session and calls leave with
+// done afterwards.
+func (l *intakeLanes) enter(key string) (prev, done chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.tails == nil {
+		l.tails = make(map[string]chan struct{})
+	}
+	prev = l.tails[key]
+	done = make(chan struct{})
+	l.tails[key] = done
+	return prev, done
+}
+
+func (l *intakeLanes) leave(key string, done chan struct{}) {
+	close(done)
+	l.mu.Lock()
+	if l.tails[key] == done {
+		delete(l.tails, key)
+	}
+	l.mu.Unlock()
+}
+
+// intakeMessage resolves msg's route off the receive loop, then claims its
+// session for a new turn or queues it as steering for the running one.
+func (al *AgentLoop) intakeMessage(ctx context.Context, msg bus.InboundMessage) {
+	key := msg.Channel + "\x00" + msg.ChatID
+	prev, done := al.intake.enter(key)
+
+	go func() {
+		defer al.intake.leave(key, done)
+
+		mr, routeErr := al.routeMessage(bus.NormalizeInboundMessage(msg))
+
+		if prev != nil {
+			select {
+			case <-prev:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if routeErr != nil {
+			// Let processMessage report the routing failure to the chat.
+			al.processMessageSync(ctx, msg)
+			return
+		}
+		al.claimOrSteer(ctx, msg, mr)
+	}()
+}
+
+// claimOrSteer starts a turn for msg on the session of mr, or hands msg to
+// the turn that already owns that session.
+func (al *AgentLoop) claimOrSteer(ctx context.Context, msg bus.InboundMessage, mr *messageRoute) {
+	sessionKey := mr.sessionKey
+
+	// Atomically claim the session key with a unique placeholder sentinel
+	// to prevent a TOCTOU race where multiple messages for the same session
+	// pass the Load check before either registers.
+	// The placeholder ensures GetActiveTurnBySession() never returns nil
+	// during turn setup. Each placeholder has a unique turnID to prevent
+	// cross-worker cleanup issues.
+	// The placeholder records who the turn runs for, so steering can be
+	// checked against it before the real turnState replaces it.
+	placeholder := &turnState{
+		turnID: makePendingTurnID(sessionKey, al.turnSeq.Add(1)),
+		phase:  TurnPhaseSetup,
+		opts: processOptions{
+			SenderID: bus.NormalizeInboundMessage(msg).SenderID,
+			Role:     al.senderRole(msg),
+		},
+	}
+	if actual, loaded := al.activeTurnStates.LoadOrStore(sessionKey, placeholder); loaded {
+		// /stop and friends must reach the command handler right away;
+		// queued as steering they would wait on the turn they target.
+		if al.isSessionControlCommand(msg.Content) {
+			al.handleBusySessionCommand(ctx, msg, mr)
+			return
+		}
+		active, _ := actual.(*turnState)
+		if reply := al.steeringRefusal(msg, mr.agent, active); reply != "" {
+			al.PublishResponseIfNeeded(ctx, msg.Channel, msg.ChatID, sessionKey, reply)
+			return
+		}
+		// Speaking over a running voice turn is a barge-in: let the turn
+		// wrap up so the new utterance is answered next.
+		if msg.Context.Raw["is_voice"] == "true" {
+			if err := al.InterruptGracefulSession(sessionKey, voiceBargeInHint); err != nil {
+				logger.DebugCF("agent", "Voice barge-in did not interrupt turn",
+					map[string]any{"session_key": sessionKey, "error": err.Error()})
+			}
+		}
+		// Another turn is already active (or reserved) for this session — enqueue
+		if err := al.enqueueSteeringMessage(sessionKey, mr.agent.ID, providers.Message{
+			Role:    "user",
+			Content: msg.Content,
+			Media:   append([]string(nil), msg.Media...),
+		}); err != nil {
+			logger.WarnCF("agent", "Failed to enqueue steering message",
+				map[string]any{
+					"error":       err.Error(),
+					"channel":     msg.Channel,
+					"chat_id":     msg.ChatID,
+					"session_key": sessionKey,
+				})
+		}
+		return
+	}
+
+	// Session claimed — spawn a worker goroutine that acquires a semaphore
+	// slot. The goroutine is spawned immediately so the intake lane is
+	// released for the chat's next message. The goroutine blocks on the
+	// semaphore.
+	go func(m bus.InboundMessage) {
+		// Acquire semaphore slot (blocks if at capacity)
+		select {
+		case al.workerSem <- struct{}{}:
+			// Got slot, start worker
+		case <-ctx.Done():
+			// Context canceled while waiting for a slot — clean up the
+			// placeholder to prevent session-level deadlock.
+			al.activeTurnStates.Delete(sessionKey)
+			return
+		}
+
+		// Safety-net cleanup: if the placeholder was never replaced by a real
+		// turnState (e.g., error before runTurn), delete it here. When runTurn
+		// completes normally, clearActiveTurn deletes the real turnState and
+		// this becomes a no-op (the key is already gone).
+		defer func() {
+			if actual, ok := al.activeTurnStates.Load(sessionKey); ok {
+				if ts, ok := actual.(*turnState); ok && strings.HasPrefix(ts.turnID, pendingTurnPrefix) {
+					// Placeholder still present — runTurn never replaced it.
+					al.activeTurnStates.Delete(sessionKey)
+				}
+			}
+		}()
+
+		defer func() {
+			if r := recover(); r != nil {
+				logger.RecoverPanicNoExit(r)
+				logger.ErrorCF("agent", "Worker goroutine panicked",
+					map[string]any{
+						"session_key": sessionKey,
+						"channel":     m.Channel,
+						"chat_id":     m.ChatID,
+						"panic":       fmt.Sprintf("%v", r),
+					})
+			}
+		}()
+		defer func() { <-al.workerSem }() // Release slot
+
+		if al.channelManager != nil {
+			defer al.channelManager.InvokeTypingStop(m.Channel, m.ChatID)
+		}
+
+		al.runTurnWithSteering(ctx, m, mr)
+	}(msg)
+}
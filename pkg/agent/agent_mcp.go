@@ -129,59 +129,7 @@ func (al *AgentLoop) ensureMCPInitialized(ctx context.Context) error {
 
 		for serverName, conn := range servers {
 			uniqueTools += len(conn.Tools)
-
-			// Determine whether this server's tools should be deferred (hidden).
-			// Per-server "deferred" field takes precedence over the global Discovery.Enabled.
-			serverCfg := al.cfg.Tools.MCP.Servers[serverName]
-			registerAsHidden := serverIsDeferred(al.cfg.Tools.MCP.Discovery.Enabled, serverCfg)
-
-			for _, agentID := range agentIDs {
-				agent, ok := al.registry.GetAgent(agentID)
-				if !ok || agent.ContextBuilder == nil {
-					continue
-				}
-				if err := agent.ContextBuilder.RegisterPromptContributor(mcpServerPromptContributor{
-					serverName: serverName,
-					toolCount:  len(conn.Tools),
-					deferred:   registerAsHidden,
-				}); err != nil {
-					logger.WarnCF("agent", "Failed to register MCP prompt contributor",
-						map[string]any{
-							"agent_id": agentID,
-							"server":   serverName,
-							"error":    err.Error(),
-						})
-				}
-			}
-
-			for _, tool := range conn.Tools {
-				for _, agentID := range agentIDs {
-					agent, ok := al.registry.GetAgent(agentID)
-					if !ok {
-						continue
-					}
-
-					mcpTool := tools.NewMCPTool(mcpManager, serverName, tool)
-					mcpTool.SetWorkspace(agent.Workspace)
-					mcpTool.SetMaxInlineTextRunes(al.cfg.Tools.MCP.GetMaxInlineTextChars())
-
-					if registerAsHidden {
-						agent.Tools.RegisterHidden(mcpTool)
-					} else {
-						agent.Tools.Register(mcpTool)
-					}
-
-					totalRegistrations++
-					logger.DebugCF("agent", "Registered MCP tool",
-						map[string]any{
-							"agent_id": agentID,
-							"server":   serverName,
-							"tool":     tool.Name,
-							"name":     mcpTool.Name(),
-							"deferred": registerAsHidden,
-						})
-				}
-			}
+			totalRegistrations += al.registerMCPServerTools(mcpManager, serverName, conn)
 		}
 		logger.InfoCF("agent", "MCP tools registered successfully",
 			map[string]any{
@@ -245,6 +193,73 @@ func (al *AgentLoop) ensureMCPInitialized(ctx context.Context) error {
 	return al.mcp.getInitErr()
 }
 
+// registerMCPServerTools registers one server's prompt contributor and tools
+// with every agent and returns the number of tool registrations. Registering
+// again after a reconnect replaces the previous entries.
+func (al *AgentLoop) registerMCPServerTools(
+	mcpManager *mcp.Manager,
+	serverName string,
+	conn *mcp.ServerConnection,
+) int {
+	registrations := 0
+	agentIDs := al.registry.ListAgentIDs()
+
+	// Determine whether this server's tools should be deferred (hidden).
+	// Per-server "deferred" field takes precedence over the global Discovery.Enabled.
+	serverCfg := al.cfg.Tools.MCP.Servers[serverName]
+	registerAsHidden := serverIsDeferred(al.cfg.Tools.MCP.Discovery.Enabled, serverCfg)
+
+	for _, agentID := range agentIDs {
+		agent, ok := al.registry.GetAgent(agentID)
+		if !ok || agent.ContextBuilder == nil {
+			continue
+		}
+		if err := agent.ContextBuilder.RegisterPromptContributor(mcpServerPromptContributor{
+			serverName: serverName,
+			toolCount:  len(conn.Tools),
+			deferred:   registerAsHidden,
+		}); err != nil {
+			logger.WarnCF("agent", "Failed to register MCP prompt contributor",
+				map[string]any{
+					"agent_id": agentID,
+					"server":   serverName,
+					"error":    err.Error(),
+				})
+		}
+	}
+
+	for _, tool := range conn.Tools {
+		for _, agentID := range agentIDs {
+			agent, ok := al.registry.GetAgent(agentID)
+			if !ok {
+				continue
+			}
+
+			mcpTool := tools.NewMCPTool(mcpManager, serverName, tool)
+			mcpTool.SetWorkspace(agent.Workspace)
+			mcpTool.SetMaxInlineTextRunes(al.cfg.Tools.MCP.GetMaxInlineTextChars())
+
+			if registerAsHidden {
+				agent.Tools.RegisterHidden(mcpTool)
+			} else {
+				agent.Tools.Register(mcpTool)
+			}
+
+			registrations++
+			logger.DebugCF("agent", "Registered MCP tool",
+				map[string]any{
+					"agent_id": agentID,
+					"server":   serverName,
+					"tool":     tool.Name,
+					"name":     mcpTool.Name(),
+					"deferred": registerAsHidden,
+				})
+		}
+	}
+
+	return registrations
+}
+
 // serverIsDeferred reports whether an MCP server's tools should be registered
 // as hidden (deferred/discovery mode).
 //
@@ -15,19 +15,22 @@ import (
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
-func (al *AgentLoop) buildContinuationTarget(msg bus.InboundMessage) (*continuationTarget, error) {
+// buildContinuationTarget returns where queued steering for msg continues.
+// mr is msg's already resolved route, or nil to resolve it here.
+func (al *AgentLoop) buildContinuationTarget(msg bus.InboundMessage, mr *messageRoute) (*continuationTarget, error) {
 	if msg.Channel == "system" {
 		return nil, nil
 	}
 
-	route, _, err := al.resolveMessageRoute(msg)
-	if err != nil {
-		return nil, err
+	if mr == nil {
+		var err error
+		if mr, err = al.routeMessage(msg); err != nil {
+			return nil, err
+		}
 	}
-	allocation := al.allocateRouteSession(route, msg)
 
 	return &continuationTarget{
-		SessionKey: resolveScopeKey(allocation.SessionKey, msg.SessionKey),
+		SessionKey: mr.sessionKey,
 		Channel:    msg.Channel,
 		ChatID:     msg.ChatID,
 	}, nil
@@ -103,6 +106,14 @@ func (al *AgentLoop) ProcessHeartbeat(
 }
 
 func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage) (string, error) {
+	return al.processRoutedMessage(ctx, msg, nil)
+}
+
+// processRoutedMessage is processMessage for a message whose route intake
+// already resolved, so triage is not consulted again. A nil mr, or a voice
+// message whose content only became known through transcription, is routed
+// here.
+func (al *AgentLoop) processRoutedMessage(ctx context.Context, msg bus.InboundMessage, mr *messageRoute) (string, error) {
 	msg = bus.NormalizeInboundMessage(msg)
 
 	// Add message preview to log (show full content for error messages)
@@ -125,6 +136,7 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 
 	var hadAudio bool
 	msg, hadAudio = al.transcribeAudioInMessage(ctx, msg)
+	msg = al.summarizeDocumentsInMessage(msg)
 
 	// For audio messages the placeholder was deferred by the channel.
 	// Now that transcription (and optional feedback) is done, send it.
@@ -137,16 +149,15 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		return al.processSystemMessage(ctx, msg)
 	}
 
-	route, agent, routeErr := al.resolveMessageRoute(msg)
-	if routeErr != nil {
-		return "", routeErr
+	if mr == nil || hadAudio {
+		var routeErr error
+		if mr, routeErr = al.routeMessage(msg); routeErr != nil {
+			return "", routeErr
+		}
 	}
+	route, agent, allocation := mr.route, mr.agent, mr.allocation
 
-	allocation := al.allocateRouteSession(route, msg)
-
-	// Resolve session key from the route allocation, while preserving explicit
-	// agent-scoped keys supplied by the caller.
-	scopeKey := resolveScopeKey(allocation.SessionKey, msg.SessionKey)
+	scopeKey := mr.sessionKey
 	sessionKey := scopeKey
 
 	// Reset message-tool state for this round so we don't skip publishing due to a previous round.
@@ -185,6 +196,13 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		AllowInterimPicoPublish: true,
 	}
 
+	if reply := al.applyAccessPolicy(msg, agent, &opts); reply != "" {
+		return reply, nil
+	}
+	if opts.ModelOverride != nil {
+		defer closeModelOverride(opts)
+	}
+
 	// context-dependent commands check their own Runtime fields and report
 	// "unavailable" when the required capability is nil.
 	if response, handled := al.handleCommand(ctx, msg, agent, &opts); handled {
@@ -203,10 +221,36 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 	return al.runAgentLoop(ctx, agent, opts)
 }
 
+// messageRoute is a message's resolved route and session. Intake resolves it
+// once and hands it to the worker, so a message reaches the triage model at
+// most once on its way to a turn.
+type messageRoute struct {
+	route      routing.ResolvedRoute
+	agent      *AgentInstance
+	allocation session.Allocation
+	// sessionKey is the allocated session key, or the explicit agent-scoped
+	// key the message was sent with.
+	sessionKey string
+}
+
+func (al *AgentLoop) routeMessage(msg bus.InboundMessage) (*messageRoute, error) {
+	route, agent, err := al.resolveMessageRoute(msg)
+	if err != nil {
+		return nil, err
+	}
+	allocation := al.allocateRouteSession(route, msg)
+	return &messageRoute{
+		route:      route,
+		agent:      agent,
+		allocation: allocation,
+		sessionKey: resolveScopeKey(allocation.SessionKey, msg.SessionKey),
+	}, nil
+}
+
 func (al *AgentLoop) resolveMessageRoute(msg bus.InboundMessage) (routing.ResolvedRoute, *AgentInstance, error) {
 	registry := al.GetRegistry()
 	inboundCtx := normalizedInboundContext(msg)
-	route := registry.ResolveRoute(inboundCtx)
+	route := registry.ResolveMessageRoute(context.Background(), inboundCtx, al.dispatchMessage(msg))
 
 	agent, ok := registry.GetAgent(route.AgentID)
 	if !ok {
@@ -219,6 +263,24 @@ func (al *AgentLoop) resolveMessageRoute(msg bus.InboundMessage) (routing.Resolv
 	return route, agent, nil
 }
 
+// dispatchMessage collects the content facts dispatch rules can match on.
+func (al *AgentLoop) dispatchMessage(msg bus.InboundMessage) routing.DispatchMessage {
+	dm := routing.DispatchMessage{Text: msg.Content}
+	for _, ref := range msg.Media {
+		var filename, contentType string
+		if al.mediaStore != nil {
+			if _, meta, err := al.mediaStore.ResolveWithMeta(ref); err == nil {
+				filename, contentType = meta.Filename, meta.ContentType
+			}
+		}
+		if filename == "" {
+			filename = ref
+		}
+		dm.Attachments = append(dm.Attachments, inferMediaType(filename, contentType))
+	}
+	return dm
+}
+
 func (al *AgentLoop) allocateRouteSession(route routing.ResolvedRoute, msg bus.InboundMessage) session.Allocation {
 	return session.AllocateRouteSession(session.AllocationInput{
 		AgentID:       route.AgentID,
@@ -18,9 +18,9 @@ func (al *AgentLoop) processMessageSync(ctx context.Context, msg bus.InboundMess
 	al.publishResponseOrError(ctx, msg.Channel, msg.ChatID, msg.SessionKey, response, err)
 }
 
-func (al *AgentLoop) runTurnWithSteering(ctx context.Context, initialMsg bus.InboundMessage) {
+func (al *AgentLoop) runTurnWithSteering(ctx context.Context, initialMsg bus.InboundMessage, mr *messageRoute) {
 	// Process the initial message
-	response, err := al.processMessage(ctx, initialMsg)
+	response, err := al.processRoutedMessage(ctx, initialMsg, mr)
 	if err != nil {
 		if !al.maybePublishError(ctx, initialMsg.Channel, initialMsg.ChatID, initialMsg.SessionKey, err) {
 			return // context canceled
@@ -30,7 +30,7 @@ func (al *AgentLoop) runTurnWithSteering(ctx context.Context, initialMsg bus.Inb
 	finalResponse := response
 
 	// Build continuation target
-	target, targetErr := al.buildContinuationTarget(initialMsg)
+	target, targetErr := al.buildContinuationTarget(initialMsg, mr)
 	if targetErr != nil {
 		logger.WarnCF("agent", "Failed to build steering continuation target",
 			map[string]any{
@@ -80,17 +80,3 @@ func (al *AgentLoop) runTurnWithSteering(ctx context.Context, initialMsg bus.Inb
 		al.PublishResponseIfNeeded(ctx, target.Channel, target.ChatID, target.SessionKey, finalResponse)
 	}
 }
-
-func (al *AgentLoop) resolveSteeringTarget(msg bus.InboundMessage) (string, string, bool) {
-	if msg.Channel == "system" {
-		return "", "", false
-	}
-
-	route, agent, err := al.resolveMessageRoute(msg)
-	if err != nil || agent == nil {
-		return "", "", false
-	}
-	allocation := al.allocateRouteSession(route, msg)
-
-	return resolveScopeKey(allocation.SessionKey, msg.SessionKey), agent.ID, true
-}
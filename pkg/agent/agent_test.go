@@ -13,6 +13,7 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -83,6 +84,7 @@ func newStartedTestChannelManager(
 
 type recordingProvider struct {
 	lastMessages []providers.Message
+	lastTools    []providers.ToolDefinition
 	lastModel    string
 }
 
@@ -94,6 +96,7 @@ func (r *recordingProvider) Chat(
 	opts map[string]any,
 ) (*providers.LLMResponse, error) {
 	r.lastMessages = append([]providers.Message(nil), messages...)
+	r.lastTools = append([]providers.ToolDefinition(nil), tools...)
 	r.lastModel = model
 	return &providers.LLMResponse{
 		Content:   "Mock response",
@@ -2976,6 +2979,116 @@ func TestProcessMessage_ModelRoutingUsesLightProvider(t *testing.T) {
 	}
 }
 
+func TestProcessMessage_ModelRoutingJudgeSelectsTier(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	heavyCalls := 0
+	heavyServer := newStrictChatCompletionTestServer(t, "heavy", "llama3.3:70b", "heavy reply", &heavyCalls)
+	defer heavyServer.Close()
+
+	localCalls := 0
+	localServer := newStrictChatCompletionTestServer(t, "local", "qwen2.5:0.5b", "local reply", &localCalls)
+	defer localServer.Close()
+
+	judgeCalls := 0
+	judgeServer := newStrictChatCompletionTestServer(t, "judge", "qwen2.5:0.5b", "9", &judgeCalls)
+	defer judgeServer.Close()
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				ModelName:         "llama-main",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				Routing: &config.RoutingConfig{
+					Enabled: true,
+					Tiers: []config.RoutingTierConfig{
+						{Name: "local", Model: "qwen-local", MaxScore: 0.5, Tools: true},
+						{Name: "frontier", Model: "llama-main"},
+					},
+					Classifier: &config.RoutingClassifierConfig{Model: "qwen-judge"},
+				},
+			},
+		},
+		ModelList: []*config.ModelConfig{
+			{
+				ModelName: "llama-main",
+				Model:     "ollama/llama3.3:70b",
+				APIBase:   heavyServer.URL,
+				APIKeys:   config.SimpleSecureStrings("heavy-key"),
+			},
+			{
+				ModelName: "qwen-local",
+				Model:     "ollama/qwen2.5:0.5b",
+				APIBase:   localServer.URL,
+				APIKeys:   config.SimpleSecureStrings("local-key"),
+			},
+			{
+				ModelName: "qwen-judge",
+				Model:     "ollama/qwen2.5:0.5b",
+				APIBase:   judgeServer.URL,
+				APIKeys:   config.SimpleSecureStrings("judge-key"),
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	provider, _, err := providers.CreateProvider(cfg)
+	if err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+	al := NewAgentLoop(cfg, msgBus, provider)
+	helper := testHelper{al: al}
+
+	// Short, but the judge grades it 9/10, so the frontier tier serves it.
+	resp := helper.executeAndGetResponse(t, context.Background(), bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "user1",
+		ChatID:   "chat1",
+		Content:  "prove it",
+	})
+	if resp != "heavy reply" {
+		t.Fatalf("response = %q, want %q", resp, "heavy reply")
+	}
+	if judgeCalls != 1 || heavyCalls != 1 || localCalls != 0 {
+		t.Fatalf("calls judge/heavy/local = %d/%d/%d, want 1/1/0", judgeCalls, heavyCalls, localCalls)
+	}
+
+	agent := al.GetRegistry().GetDefaultAgent()
+	if len(agent.routeLog.decisions) != 1 {
+		t.Fatalf("recorded decisions = %d, want 1", len(agent.routeLog.decisions))
+	}
+	for _, d := range agent.routeLog.decisions {
+		if d.Tier != "frontier" || d.Source != routing.SourceJudge || d.Score != 0.9 {
+			t.Fatalf("decision = %+v, want frontier via judge at 0.9", d)
+		}
+	}
+}
+
+func TestRouteDecisionLog_ForgetsOldestSession(t *testing.T) {
+	l := newRouteDecisionLog()
+	l.limit = 2
+
+	l.record("s1", routing.Decision{Tier: "a"})
+	l.record("s2", routing.Decision{Tier: "b"})
+	l.record("s1", routing.Decision{Tier: "c"})
+	l.record("s3", routing.Decision{Tier: "d"})
+
+	if _, ok := l.last("s1"); ok {
+		t.Fatal("s1 should have been evicted")
+	}
+	if d, ok := l.last("s2"); !ok || d.Tier != "b" {
+		t.Fatalf("s2 = %+v, %v", d, ok)
+	}
+	if d, ok := l.last("s3"); !ok || d.Tier != "d" {
+		t.Fatalf("s3 = %+v, %v", d, ok)
+	}
+	if len(l.decisions) != 2 {
+		t.Fatalf("decisions = %d, want 2", len(l.decisions))
+	}
+}
+
 // TestProcessMessage_FallbackUsesPerCandidateProvider is the loop-level test for
 // bug #2140. It verifies that when the primary model returns a rate-limit error
 // the fallback closure routes the retry to the fallback model's own provider
@@ -5446,6 +5559,103 @@ func TestParallelMessageProcessing_DifferentSessionsProcessedConcurrently(t *tes
 	t.Logf("Maximum concurrent executions: %d", maxConcurrent)
 }
 
+// blockingTriageProvider answers triage prompts with "ops", holding messages
+// that contain "slow" until release is closed.
+type blockingTriageProvider struct {
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (p *blockingTriageProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	p.calls.Add(1)
+	if strings.Contains(messages[len(messages)-1].Content, "slow") {
+		select {
+		case <-p.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return &providers.LLMResponse{Content: "ops"}, nil
+}
+
+func (p *blockingTriageProvider) GetDefaultModel() string { return "triage" }
+
+func TestRun_TriageDoesNotBlockOtherChats(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				MaxParallelTurns:  2,
+			},
+			List: []config.AgentConfig{
+				{ID: "main", Default: true},
+				{ID: "ops", Description: "Server incidents"},
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	al := NewAgentLoop(cfg, msgBus, &simpleMockProvider{response: "done"})
+	defer al.Close()
+
+	triage := &blockingTriageProvider{release: make(chan struct{})}
+	al.GetRegistry().resolver.SetTriage(routing.NewTriage(triage, routing.TriageConfig{Model: "triage"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	publish := func(chatID, content string) {
+		t.Helper()
+		if err := msgBus.PublishInbound(ctx, bus.InboundMessage{
+			Context: bus.InboundContext{
+				Channel:   "telegram",
+				ChatID:    chatID,
+				ChatType:  "direct",
+				SenderID:  chatID,
+				MessageID: chatID + "-1",
+			},
+			Content: content,
+		}); err != nil {
+			t.Fatalf("PublishInbound: %v", err)
+		}
+	}
+	nextReply := func() bus.OutboundMessage {
+		t.Helper()
+		select {
+		case out := <-msgBus.OutboundChan():
+			return out
+		case <-time.After(3 * time.Second):
+			t.Fatal("timed out waiting for a reply")
+			return bus.OutboundMessage{}
+		}
+	}
+
+	publish("chat-a", "slow question")
+	publish("chat-b", "quick question")
+
+	if out := nextReply(); out.ChatID != "chat-b" {
+		t.Fatalf("first reply went to %q, want chat-b while chat-a is in triage", out.ChatID)
+	}
+	close(triage.release)
+	if out := nextReply(); out.ChatID != "chat-a" {
+		t.Fatalf("second reply went to %q, want chat-a", out.ChatID)
+	}
+	if got := triage.calls.Load(); got != 2 {
+		t.Fatalf("triage calls = %d, want one per message", got)
+	}
+}
+
 func TestParallelMessageProcessing_SameSessionProcessedSequentially(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "agent-test-*")
 	if err != nil {
@@ -5581,3 +5791,158 @@ func (p *concurrentMockProvider) Chat(
 func (p *concurrentMockProvider) GetDefaultModel() string {
 	return "test-model"
 }
+
+func TestProcessMessage_RetryCommandReplaysLastUserMessage(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	defaultAgent := al.GetRegistry().GetDefaultAgent()
+	if defaultAgent == nil {
+		t.Fatal("expected default agent")
+	}
+
+	msg := bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "telegram:123",
+		ChatID:   "chat-1",
+		Content:  "/retry",
+	}
+	route, _, err := al.resolveMessageRoute(msg)
+	if err != nil {
+		t.Fatalf("resolveMessageRoute() error = %v", err)
+	}
+	sessionKey := resolveScopeKey(al.allocateRouteSession(route, msg).SessionKey, msg.SessionKey)
+	defaultAgent.Sessions.SetHistory(sessionKey, []providers.Message{
+		{Role: "user", Content: "first"},
+		{Role: "assistant", Content: "first answer"},
+		{Role: "user", Content: "second"},
+		{Role: "assistant", Content: "bad answer"},
+	})
+
+	response, err := al.processMessage(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if response != "Mock response" {
+		t.Fatalf("response = %q, want retried LLM reply", response)
+	}
+
+	last := provider.lastMessages[len(provider.lastMessages)-1]
+	if last.Role != "user" || last.Content != "second" {
+		t.Fatalf("last provider message = %+v, want replayed user message", last)
+	}
+
+	want := []providers.Message{
+		{Role: "user", Content: "first"},
+		{Role: "assistant", Content: "first answer"},
+		{Role: "user", Content: "second"},
+		{Role: "assistant", Content: "Mock response"},
+	}
+	history := defaultAgent.Sessions.GetHistory(sessionKey)
+	if len(history) != len(want) {
+		t.Fatalf("history len = %d, want %d: %#v", len(history), len(want), history)
+	}
+	for i := range want {
+		if history[i].Role != want[i].Role || history[i].Content != want[i].Content {
+			t.Fatalf("history[%d] = %+v, want %+v", i, history[i], want[i])
+		}
+	}
+}
+
+func TestProcessMessage_CustomCommandRunsRenderedPrompt(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, "commands"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "commands", "summarize.md"), []byte(`---
+args: [path]
+allowedTools: ["mock_*"]
+---
+Read {{path}} and summarize it.
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         workspace,
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	al.RegisterTool(&mockCustomTool{})
+	al.RegisterTool(&handledUserTool{})
+
+	response, err := al.processMessage(context.Background(), bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "telegram:123",
+		ChatID:   "chat-1",
+		Content:  "/summarize notes.md",
+	})
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if response != "Mock response" {
+		t.Fatalf("response = %q, want LLM reply", response)
+	}
+
+	last := provider.lastMessages[len(provider.lastMessages)-1]
+	if last.Role != "user" || last.Content != "Read notes.md and summarize it." {
+		t.Fatalf("last provider message = %+v, want rendered prompt", last)
+	}
+	if len(provider.lastTools) != 1 || provider.lastTools[0].Function.Name != "mock_custom" {
+		names := make([]string, 0, len(provider.lastTools))
+		for _, td := range provider.lastTools {
+			names = append(names, td.Function.Name)
+		}
+		t.Fatalf("offered tools = %v, want only mock_custom", names)
+	}
+}
+
+func TestProcessMessage_RetryCommandWithoutHistory(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	provider := &countingMockProvider{response: "LLM reply"}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	response, err := al.processMessage(context.Background(), bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "telegram:123",
+		ChatID:   "chat-1",
+		Content:  "/retry",
+	})
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if response != "Failed to retry: no previous message to retry" {
+		t.Fatalf("response = %q", response)
+	}
+	if provider.calls != 0 {
+		t.Fatalf("LLM should not be called, calls=%d", provider.calls)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"maps"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -201,6 +202,23 @@ func cloneEventArguments(args map[string]any) map[string]any {
 	return cloned
 }
 
+// toolAllowed reports whether name matches the allowlist. Entries may be
+// path.Match globs (e.g. "mcp_github_*"); an empty allowlist allows all tools.
+func toolAllowed(allowed []string, name string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, pattern := range allowed {
+		if pattern == name {
+			return true
+		}
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
 func hookDeniedToolContent(prefix, reason string) string {
 	if reason == "" {
 		return prefix
@@ -511,10 +529,10 @@ func hasMediaRefs(messages []providers.Message) bool {
 	return false
 }
 
-func sideQuestionModelName(agent *AgentInstance, usedLight bool) string {
-	if usedLight && len(agent.LightCandidates) > 0 {
-		// Use the first light candidate's model
-		return agent.LightCandidates[0].Model
+func sideQuestionModelName(agent *AgentInstance, target *RouteTarget) string {
+	if target != nil && len(target.Candidates) > 0 {
+		// Use the first candidate of the routed tier's model
+		return target.Candidates[0].Model
 	}
 	return agent.Model
 }
@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+)
+
+const channelToolApprovalHookName = "channel_tool_approval"
+
+// channelToolApprover forwards tool approval to the channel a turn came from
+// when that channel lets its clients approve tool calls interactively.
+// Turns from other channels are approved without a round trip.
+type channelToolApprover struct {
+	channels *channels.Manager
+}
+
+func (a channelToolApprover) ApproveTool(ctx context.Context, req *ToolApprovalRequest) (ApprovalDecision, error) {
+	if a.channels == nil || req == nil || req.Context == nil || req.Context.Inbound == nil {
+		return ApprovalDecision{Approved: true}, nil
+	}
+	inbound := req.Context.Inbound
+	ch, ok := a.channels.GetChannel(inbound.Channel)
+	if !ok {
+		return ApprovalDecision{Approved: true}, nil
+	}
+	approver, ok := ch.(channels.ToolApprovalCapable)
+	if !ok {
+		return ApprovalDecision{Approved: true}, nil
+	}
+
+	decision, err := approver.RequestToolApproval(ctx, channels.ToolApprovalRequest{
+		ChatID:    inbound.ChatID,
+		AgentID:   req.Meta.AgentID,
+		Tool:      req.Tool,
+		Arguments: req.Arguments,
+	})
+	if err != nil {
+		return ApprovalDecision{}, err
+	}
+	return ApprovalDecision{Approved: decision.Approved, Reason: decision.Reason}, nil
+}
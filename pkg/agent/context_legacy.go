@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -52,6 +53,8 @@ func (m *legacyContextManager) Compact(_ context.Context, req *CompactRequest) e
 		}
 	case ContextCompressReasonSummarize:
 		m.maybeSummarize(req.SessionKey)
+	case ContextCompressReasonManual:
+		return m.summarizeNow(req.AgentID, req.SessionKey)
 	}
 	return nil
 }
@@ -97,12 +100,42 @@ func (m *legacyContextManager) maybeSummarize(sessionKey string) {
 					}
 				}()
 				logger.Debug("Memory threshold reached. Optimizing conversation history...")
-				m.summarizeSession(agent, sessionKey)
+				if err := m.summarizeSession(agent, sessionKey, false); err != nil {
+					logger.WarnCF("agent", "Background summarization failed", map[string]any{
+						"session_key": sessionKey,
+						"error":       err.Error(),
+					})
+				}
 			}()
 		}
 	}
 }
 
+// summarizeNow runs summarization synchronously regardless of thresholds.
+// It shares the dedup key with maybeSummarize so a manual request never races
+// a background summarization of the same session. Unlike the background path
+// it does not fall back to a truncated transcript when the model fails: the
+// history is left untouched and the error is returned. agentID selects the
+// agent whose sessions are summarized; "" means the default agent.
+func (m *legacyContextManager) summarizeNow(agentID, sessionKey string) error {
+	agent := m.al.registry.GetDefaultAgent()
+	if agentID != "" {
+		var ok bool
+		if agent, ok = m.al.registry.GetAgent(agentID); !ok {
+			return fmt.Errorf("agent %q not found", agentID)
+		}
+	}
+	if agent == nil {
+		return fmt.Errorf("no default agent")
+	}
+	summarizeKey := agent.ID + ":" + sessionKey
+	if _, loading := m.summarizing.LoadOrStore(summarizeKey, true); loading {
+		return fmt.Errorf("summarization already in progress")
+	}
+	defer m.summarizing.Delete(summarizeKey)
+	return m.summarizeSession(agent, sessionKey, true)
+}
+
 type compressionResult struct {
 	DroppedMessages   int
 	RemainingMessages int
@@ -168,7 +201,10 @@ func (m *legacyContextManager) forceCompression(sessionKey string) (compressionR
 	}, true
 }
 
-func (m *legacyContextManager) summarizeSession(agent *AgentInstance, sessionKey string) {
+// summarizeSession replaces the older part of the session history with a
+// summary. Short histories are left alone and return nil. With strict set, a
+// failed model call aborts instead of storing the fallback summary.
+func (m *legacyContextManager) summarizeSession(agent *AgentInstance, sessionKey string, strict bool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
@@ -176,12 +212,12 @@ func (m *legacyContextManager) summarizeSession(agent *AgentInstance, sessionKey
 	summary := agent.Sessions.GetSummary(sessionKey)
 
 	if len(history) <= 4 {
-		return
+		return nil
 	}
 
 	safeCut := findSafeBoundary(history, len(history)-4)
 	if safeCut <= 0 {
-		return
+		return nil
 	}
 	keepCount := len(history) - safeCut
 	toSummarize := history[:safeCut]
@@ -203,7 +239,7 @@ func (m *legacyContextManager) summarizeSession(agent *AgentInstance, sessionKey
 	}
 
 	if len(validMessages) == 0 {
-		return
+		return fmt.Errorf("no messages small enough to summarize")
 	}
 
 	const (
@@ -219,8 +255,11 @@ func (m *legacyContextManager) summarizeSession(agent *AgentInstance, sessionKey
 		part1 := validMessages[:mid]
 		part2 := validMessages[mid:]
 
-		s1, _ := m.summarizeBatch(ctx, agent, part1, "")
-		s2, _ := m.summarizeBatch(ctx, agent, part2, "")
+		s1, err1 := m.summarizeBatch(ctx, agent, part1, "")
+		s2, err2 := m.summarizeBatch(ctx, agent, part2, "")
+		if err := errors.Join(err1, err2); err != nil && strict {
+			return fmt.Errorf("summarization failed: %w", err)
+		}
 
 		mergePrompt := fmt.Sprintf(
 			"Merge these two conversation summaries into one cohesive summary:\n\n1: %s\n\n2: %s",
@@ -230,32 +269,43 @@ func (m *legacyContextManager) summarizeSession(agent *AgentInstance, sessionKey
 		resp, err := m.retryLLMCall(ctx, agent, mergePrompt, llmMaxRetries)
 		if err == nil && resp.Content != "" {
 			finalSummary = resp.Content
+		} else if strict {
+			if err == nil {
+				err = errors.New("empty response")
+			}
+			return fmt.Errorf("merging summaries failed: %w", err)
 		} else {
 			finalSummary = s1 + " " + s2
 		}
 	} else {
-		finalSummary, _ = m.summarizeBatch(ctx, agent, validMessages, summary)
+		var err error
+		finalSummary, err = m.summarizeBatch(ctx, agent, validMessages, summary)
+		if err != nil && strict {
+			return fmt.Errorf("summarization failed: %w", err)
+		}
 	}
 
 	if omitted && finalSummary != "" {
 		finalSummary += "\n[Note: Some oversized messages were omitted from this summary for efficiency.]"
 	}
 
-	if finalSummary != "" {
-		agent.Sessions.SetSummary(sessionKey, finalSummary)
-		agent.Sessions.TruncateHistory(sessionKey, keepCount)
-		agent.Sessions.Save(sessionKey)
-		m.al.emitEvent(
-			EventKindSessionSummarize,
-			m.al.newTurnEventScope(agent.ID, sessionKey, nil).meta(0, "summarizeSession", "turn.session.summarize"),
-			SessionSummarizePayload{
-				SummarizedMessages: len(validMessages),
-				KeptMessages:       keepCount,
-				SummaryLen:         len(finalSummary),
-				OmittedOversized:   omitted,
-			},
-		)
+	if finalSummary == "" {
+		return fmt.Errorf("summarization produced an empty summary")
 	}
+	agent.Sessions.SetSummary(sessionKey, finalSummary)
+	agent.Sessions.TruncateHistory(sessionKey, keepCount)
+	saveErr := agent.Sessions.Save(sessionKey)
+	m.al.emitEvent(
+		EventKindSessionSummarize,
+		m.al.newTurnEventScope(agent.ID, sessionKey, nil).meta(0, "summarizeSession", "turn.session.summarize"),
+		SessionSummarizePayload{
+			SummarizedMessages: len(validMessages),
+			KeptMessages:       keepCount,
+			SummaryLen:         len(finalSummary),
+			OmittedOversized:   omitted,
+		},
+	)
+	return saveErr
 }
 
 func (m *legacyContextManager) findNearestUserMessage(messages []providers.Message, mid int) int {
@@ -349,6 +399,9 @@ func (m *legacyContextManager) summarizeBatch(
 	if err == nil && response.Content != "" {
 		return strings.TrimSpace(response.Content), nil
 	}
+	if err == nil {
+		err = errors.New("empty response")
+	}
 
 	var fallback strings.Builder
 	fallback.WriteString("Conversation summary: ")
@@ -377,7 +430,8 @@ func (m *legacyContextManager) summarizeBatch(
 		}
 		fallback.WriteString(fmt.Sprintf("%s: %s", msg.Role, content))
 	}
-	return fallback.String(), nil
+	// The fallback is usable, but report why it was needed.
+	return fallback.String(), err
 }
 
 func (m *legacyContextManager) estimateTokens(messages []providers.Message) int {
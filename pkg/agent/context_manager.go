@@ -18,7 +18,8 @@ type ContextManager interface {
 	Assemble(ctx context.Context, req *AssembleRequest) (*AssembleResponse, error)
 
 	// Compact compresses conversation history.
-	// Called after turn completes (may be async internally) and on context overflow (sync).
+	// Called after turn completes (may be async internally), on context overflow (sync)
+	// and when the user issues /compact (sync).
 	Compact(ctx context.Context, req *CompactRequest) error
 
 	// Ingest records a message into the ContextManager's own storage.
@@ -46,8 +47,9 @@ type AssembleResponse struct {
 // CompactRequest is the input to Compact.
 type CompactRequest struct {
 	SessionKey string                // session identifier
-	Reason     ContextCompressReason // proactive_budget | llm_retry | summarize
+	Reason     ContextCompressReason // proactive_budget | llm_retry | summarize | manual
 	Budget     int                   // context window budget (used for retry aggressive compaction)
+	AgentID    string                // agent whose sessions hold SessionKey; "" = default agent
 }
 
 // IngestRequest is the input to Ingest.
@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"strings"
 	"sync"
@@ -450,6 +451,41 @@ func TestLegacyCompact_PostTurn_BelowThreshold(t *testing.T) {
 	}
 }
 
+func TestLegacyCompact_Manual_ReportsProviderFailure(t *testing.T) {
+	cfg := testConfig(t)
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &errorProvider{errType: "timeout"})
+
+	defaultAgent := al.registry.GetDefaultAgent()
+	if defaultAgent == nil {
+		t.Fatal("expected default agent")
+	}
+
+	history := []providers.Message{
+		{Role: "user", Content: "Question one"},
+		{Role: "assistant", Content: "Answer one"},
+		{Role: "user", Content: "Question two"},
+		{Role: "assistant", Content: "Answer two"},
+		{Role: "user", Content: "Question three"},
+		{Role: "assistant", Content: "Answer three"},
+	}
+	defaultAgent.Sessions.SetHistory("session-manual", history)
+
+	err := al.contextManager.Compact(context.Background(), &CompactRequest{
+		SessionKey: "session-manual",
+		Reason:     ContextCompressReasonManual,
+	})
+	if err == nil {
+		t.Fatal("expected error when the summarization model fails")
+	}
+
+	if got := defaultAgent.Sessions.GetHistory("session-manual"); len(got) != len(history) {
+		t.Fatalf("expected unchanged history, got %d messages (was %d)", len(got), len(history))
+	}
+	if summary := defaultAgent.Sessions.GetSummary("session-manual"); summary != "" {
+		t.Fatalf("expected no summary, got %q", summary)
+	}
+}
+
 func TestLegacyCompact_PostTurn_ExceedsMessageThreshold(t *testing.T) {
 	cfg := &config.Config{
 		Agents: config.AgentsConfig{
@@ -765,3 +801,54 @@ func newCMTestAgentLoop(cfg *config.Config) *AgentLoop {
 	msgBus := bus.NewMessageBus()
 	return NewAgentLoop(cfg, msgBus, &simpleMockProvider{response: "test"})
 }
+
+func TestCompactCommand_SummarizesRoutedAgentSessions(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Agents.List = []config.AgentConfig{
+		{ID: "main", Default: true},
+		{ID: "ops"},
+	}
+	cfg.Agents.Dispatch = &config.DispatchConfig{
+		Rules: []config.DispatchRule{
+			{Name: "ops-chat", Agent: "ops", When: config.DispatchSelector{Channel: "telegram"}},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &simpleMockProvider{response: "summary"})
+
+	msg := bus.NormalizeInboundMessage(bus.InboundMessage{
+		Context: bus.InboundContext{
+			Channel:  "telegram",
+			ChatID:   "42",
+			ChatType: "direct",
+			SenderID: "42",
+		},
+		Content: "/compact",
+	})
+	mr, err := al.routeMessage(msg)
+	if err != nil {
+		t.Fatalf("routeMessage() error = %v", err)
+	}
+	if mr.agent.ID != "ops" {
+		t.Fatalf("routed agent = %q, want ops", mr.agent.ID)
+	}
+
+	var history []providers.Message
+	for i := 0; i < 4; i++ {
+		history = append(history,
+			providers.Message{Role: "user", Content: fmt.Sprintf("Question %d", i)},
+			providers.Message{Role: "assistant", Content: fmt.Sprintf("Answer %d", i)},
+		)
+	}
+	mr.agent.Sessions.SetHistory(mr.sessionKey, history)
+
+	response, err := al.processMessage(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if !strings.HasPrefix(response, "Compacted chat history: 8 → ") {
+		t.Fatalf("/compact reply = %q, want the ops session compacted", response)
+	}
+	if summary := mr.agent.Sessions.GetSummary(mr.sessionKey); summary == "" {
+		t.Fatal("expected a summary on the ops agent's session")
+	}
+}
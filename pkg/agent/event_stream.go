@@ -0,0 +1,187 @@
+package agent
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// EventStreamPath is where the gateway serves the live agent event stream.
+const EventStreamPath = "/events/agent"
+
+const eventStreamBuffer = 256
+
+var eventStreamKeepAlive = 15 * time.Second
+
+// streamEvent is the JSON form of an Event sent to event stream clients.
+// Data holds the same fields the event log records, so message and tool
+// argument contents are never streamed.
+type streamEvent struct {
+	Kind         string         `json:"kind"`
+	Time         time.Time      `json:"time"`
+	AgentID      string         `json:"agent_id,omitempty"`
+	TurnID       string         `json:"turn_id,omitempty"`
+	ParentTurnID string         `json:"parent_turn_id,omitempty"`
+	SessionKey   string         `json:"session_key,omitempty"`
+	Iteration    int            `json:"iteration"`
+	Source       string         `json:"source,omitempty"`
+	Trace        string         `json:"trace,omitempty"`
+	Channel      string         `json:"channel,omitempty"`
+	ChatID       string         `json:"chat_id,omitempty"`
+	Data         map[string]any `json:"data,omitempty"`
+}
+
+func newStreamEvent(evt Event) streamEvent {
+	out := streamEvent{
+		Kind:         evt.Kind.String(),
+		Time:         evt.Time,
+		AgentID:      evt.Meta.AgentID,
+		TurnID:       evt.Meta.TurnID,
+		ParentTurnID: evt.Meta.ParentTurnID,
+		SessionKey:   evt.Meta.SessionKey,
+		Iteration:    evt.Meta.Iteration,
+		Source:       evt.Meta.Source,
+		Trace:        evt.Meta.TracePath,
+	}
+	if evt.Context != nil && evt.Context.Inbound != nil {
+		out.Channel = evt.Context.Inbound.Channel
+		out.ChatID = evt.Context.Inbound.ChatID
+	}
+	data := make(map[string]any)
+	appendEventPayloadFields(data, evt.Payload)
+	if len(data) > 0 {
+		out.Data = data
+	}
+	return out
+}
+
+// eventFilter selects the events sent to one stream client.
+type eventFilter struct {
+	sessionKey string
+	agentID    string
+	kinds      map[EventKind]bool // nil = every kind except llm_delta
+}
+
+// parseEventFilter reads the session, agent and kind query parameters.
+// kind is a comma-separated list of event kind names.
+func parseEventFilter(q url.Values) (eventFilter, error) {
+	f := eventFilter{
+		sessionKey: strings.TrimSpace(q.Get("session")),
+		agentID:    strings.TrimSpace(q.Get("agent")),
+	}
+	for _, name := range strings.Split(q.Get("kind"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		kind, ok := parseEventKind(name)
+		if !ok {
+			return eventFilter{}, fmt.Errorf("unknown event kind %q", name)
+		}
+		if f.kinds == nil {
+			f.kinds = make(map[EventKind]bool)
+		}
+		f.kinds[kind] = true
+	}
+	return f, nil
+}
+
+func parseEventKind(name string) (EventKind, bool) {
+	for i, known := range eventKindNames {
+		if known == name {
+			return EventKind(i), true
+		}
+	}
+	return 0, false
+}
+
+func (f eventFilter) match(evt Event) bool {
+	if f.sessionKey != "" && evt.Meta.SessionKey != f.sessionKey {
+		return false
+	}
+	if f.agentID != "" && evt.Meta.AgentID != f.agentID {
+		return false
+	}
+	if f.kinds == nil {
+		// Streaming deltas arrive per token; clients must ask for them.
+		return evt.Kind != EventKindLLMDelta
+	}
+	return f.kinds[evt.Kind]
+}
+
+// authorizedRequest reports whether r carries token as a bearer token. An
+// empty token disables the check.
+func authorizedRequest(r *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+	given, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// EventStreamHandler serves the agent EventBus as Server-Sent Events. Each
+// event is one "data:" line of JSON. When token is set, requests must carry
+// it as a bearer token, as for the gateway's /reload endpoint.
+func (al *AgentLoop) EventStreamHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !authorizedRequest(r, token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		filter, err := parseEventFilter(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		// The shared gateway server has a write timeout; streams outlive it.
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		sub := al.SubscribeEvents(eventStreamBuffer)
+		defer al.UnsubscribeEvents(sub.ID)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, ": connected\n\n")
+		if rc.Flush() != nil {
+			return
+		}
+
+		keepAlive := time.NewTicker(eventStreamKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": ping\n\n")
+			case evt, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				if !filter.match(evt) {
+					continue
+				}
+				data, err := json.Marshal(newStreamEvent(evt))
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "data: %s\n\n", data)
+			}
+			if rc.Flush() != nil {
+				return
+			}
+		}
+	})
+}
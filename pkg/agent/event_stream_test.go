@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStreamHandler_FiltersAndRedacts(t *testing.T) {
+	al := &AgentLoop{eventBus: NewEventBus()}
+	defer al.eventBus.Close()
+	srv := httptest.NewServer(al.EventStreamHandler("secret"))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status without token = %d, want 401", resp.StatusCode)
+	}
+
+	get := func(query string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"?"+query, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		return resp
+	}
+
+	resp = get("kind=nonsense")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status for unknown kind = %d, want 400", resp.StatusCode)
+	}
+
+	resp = get("session=s1")
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	lines := make(chan string, 16)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	next := func() string {
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatal("stream closed")
+				}
+				if line != "" {
+					return line
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("timed out waiting for stream")
+			}
+		}
+	}
+	if line := next(); line != ": connected" {
+		t.Fatalf("first line = %q", line)
+	}
+
+	al.eventBus.Emit(Event{Kind: EventKindLLMDelta, Meta: EventMeta{SessionKey: "s1"}})
+	al.eventBus.Emit(Event{Kind: EventKindTurnStart, Meta: EventMeta{SessionKey: "s2"}})
+	al.eventBus.Emit(Event{
+		Kind:    EventKindToolExecStart,
+		Meta:    EventMeta{AgentID: "main", TurnID: "main-turn-1", SessionKey: "s1"},
+		Payload: ToolExecStartPayload{Tool: "exec", Arguments: map[string]any{"command": "cat password.txt"}},
+	})
+
+	line := next()
+	data, ok := strings.CutPrefix(line, "data: ")
+	if !ok {
+		t.Fatalf("line = %q, want a data line", line)
+	}
+	if strings.Contains(data, "password") {
+		t.Fatalf("tool arguments leaked into stream: %s", data)
+	}
+	var evt streamEvent
+	if err := json.Unmarshal([]byte(data), &evt); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if evt.Kind != "tool_exec_start" || evt.TurnID != "main-turn-1" || evt.Data["tool"] != "exec" {
+		t.Fatalf("event = %+v", evt)
+	}
+	if evt.Data["args_count"] != float64(1) {
+		t.Fatalf("args_count = %v, want 1", evt.Data["args_count"])
+	}
+}
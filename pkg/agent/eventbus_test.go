@@ -512,7 +512,9 @@ func TestAgentLoop_EmitsSessionSummarizeEvent(t *testing.T) {
 	defer al.UnsubscribeEvents(sub.ID)
 
 	lcm := &legacyContextManager{al: al}
-	lcm.summarizeSession(defaultAgent, "session-1")
+	if err := lcm.summarizeSession(defaultAgent, "session-1", false); err != nil {
+		t.Fatalf("summarizeSession: %v", err)
+	}
 
 	events := collectEventStream(sub.C)
 	summaryEvt, ok := findEvent(events, EventKindSessionSummarize)
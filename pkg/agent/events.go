@@ -45,6 +45,8 @@ const (
 	EventKindSubTurnResultDelivered
 	// EventKindSubTurnOrphan is emitted when a sub-turn result cannot be delivered.
 	EventKindSubTurnOrphan
+	// EventKindSubTurnProgress is emitted when a sub-turn on a remote peer reports progress.
+	EventKindSubTurnProgress
 	// EventKindError is emitted when a turn encounters an execution error.
 	EventKindError
 
@@ -70,6 +72,7 @@ var eventKindNames = [...]string{
 	"subturn_end",
 	"subturn_result_delivered",
 	"subturn_orphan",
+	"subturn_progress",
 	"error",
 }
 
@@ -169,6 +172,8 @@ const (
 	ContextCompressReasonRetry ContextCompressReason = "llm_retry"
 	// ContextCompressReasonSummarize indicates post-turn async summarization.
 	ContextCompressReasonSummarize ContextCompressReason = "summarize"
+	// ContextCompressReasonManual indicates a user-requested /compact.
+	ContextCompressReasonManual ContextCompressReason = "manual"
 )
 
 // ContextCompressPayload describes a forced history compression.
@@ -242,6 +247,7 @@ type SubTurnSpawnPayload struct {
 	AgentID      string
 	Label        string
 	ParentTurnID string
+	Peer         string // remote peer running the sub-turn; empty = local
 }
 
 // SubTurnEndPayload describes the completion of a child turn.
@@ -250,6 +256,16 @@ type SubTurnEndPayload struct {
 	Status  string
 }
 
+// SubTurnProgressPayload describes progress reported by a sub-turn running on
+// a remote peer.
+type SubTurnProgressPayload struct {
+	Peer    string
+	TaskID  string
+	AgentID string
+	Tool    string
+	Message string
+}
+
 // SubTurnResultDeliveredPayload describes delivery of a sub-turn result.
 type SubTurnResultDeliveredPayload struct {
 	TargetChannel string
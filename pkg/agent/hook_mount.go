@@ -35,6 +35,12 @@ func (r *hookRuntime) setMounted(names []string) {
 	r.mu.Unlock()
 }
 
+func (r *hookRuntime) getMounted() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.mounted...)
+}
+
 func (r *hookRuntime) reset(al *AgentLoop) {
 	r.mu.Lock()
 	names := append([]string(nil), r.mounted...)
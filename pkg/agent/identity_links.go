@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+// installLinkStore opens the /link store under PICOCLAW_HOME and makes it
+// the one session allocation consults.
+func installLinkStore() *session.LinkStore {
+	store := session.NewLinkStore(session.LinkStorePath(config.GetHome()))
+	session.SetLinkStore(store)
+	return store
+}
+
+// attachLinkRuntime exposes /link and /unlink for the sender of opts.
+func (al *AgentLoop) attachLinkRuntime(rt *commands.Runtime, opts *processOptions) {
+	al.mu.RLock()
+	store := al.links
+	al.mu.RUnlock()
+	if store == nil || opts == nil || opts.Dispatch.InboundContext == nil {
+		return
+	}
+	in := opts.Dispatch.InboundContext
+	id := session.LinkIdentity(in.Channel, in.SenderID)
+	if id == "" {
+		return
+	}
+
+	// A code shown in a group could be redeemed by anyone there. An unset
+	// chat type counts as direct, as in session allocation.
+	direct := in.ChatType == "" || strings.EqualFold(in.ChatType, "direct")
+
+	rt.IssueLinkCode = func() (string, error) {
+		if !direct {
+			return "", session.ErrLinkNotDirect
+		}
+		return store.IssueCode(id)
+	}
+	rt.ConfirmLink = func(code string) (string, error) {
+		if !direct {
+			return "", session.ErrLinkNotDirect
+		}
+		canonical, err := store.Confirm(code, id)
+		if err != nil {
+			if !errors.Is(err, session.ErrLinkCodeInvalid) && !errors.Is(err, session.ErrLinkSelf) &&
+				!errors.Is(err, session.ErrLinkAttempts) {
+				logger.WarnCF("agent", "Failed to save identity link",
+					map[string]any{"identity": id, "error": err.Error()})
+			}
+			return "", err
+		}
+		logger.InfoCF("agent", "Identity linked",
+			map[string]any{"identity": id, "canonical": canonical})
+		return canonical, nil
+	}
+	rt.Unlink = func() (bool, error) {
+		return store.Unlink(id)
+	}
+}
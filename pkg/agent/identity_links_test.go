@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+func TestProcessMessage_LinkCommandSharesDirectSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(config.EnvHome, home)
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		Session: config.SessionConfig{Dimensions: []string{"chat"}},
+	}
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	t.Cleanup(func() { session.SetLinkStore(nil) })
+
+	send := func(channel, sender, content string) string {
+		t.Helper()
+		resp, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel:  channel,
+			SenderID: sender,
+			ChatID:   "dm-" + sender,
+			Content:  content,
+		})
+		if err != nil {
+			t.Fatalf("processMessage(%s, %q) error = %v", channel, content, err)
+		}
+		return resp
+	}
+
+	reply := send("telegram", "123", "/link")
+	fields := strings.Fields(strings.TrimPrefix(reply, "Send /link "))
+	if !strings.HasPrefix(reply, "Send /link ") || len(fields) == 0 {
+		t.Fatalf("/link reply = %q", reply)
+	}
+	if got := send("discord", "456", "/link "+fields[0]); !strings.HasPrefix(got, "Linked with telegram:123.") {
+		t.Fatalf("/link <code> reply = %q", got)
+	}
+	if _, err := os.Stat(session.LinkStorePath(home)); err != nil {
+		t.Fatalf("link store not saved under PICOCLAW_HOME: %v", err)
+	}
+	if _, err := os.Stat(session.LinkStorePath(cfg.WorkspacePath())); !os.IsNotExist(err) {
+		t.Fatalf("link store written inside the workspace: %v", err)
+	}
+
+	send("telegram", "123", "remember the word pineapple")
+	send("discord", "456", "what was the word?")
+	found := false
+	for _, m := range provider.lastMessages {
+		if m.Role == "user" && strings.Contains(m.Content, "pineapple") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("discord turn did not see the telegram history: %+v", provider.lastMessages)
+	}
+
+	if got := send("discord", "456", "/unlink"); !strings.HasPrefix(got, "Unlinked.") {
+		t.Fatalf("/unlink reply = %q", got)
+	}
+	if got := send("discord", "456", "/unlink"); got != "This account is not linked." {
+		t.Fatalf("second /unlink reply = %q", got)
+	}
+}
+
+func TestProcessMessage_LinkCommandRefusedInGroups(t *testing.T) {
+	t.Setenv(config.EnvHome, t.TempDir())
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &recordingProvider{})
+	t.Cleanup(func() { session.SetLinkStore(nil) })
+
+	for _, content := range []string{"/link", "/link ABCDEFGH"} {
+		resp, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Context: bus.InboundContext{
+				Channel:  "telegram",
+				ChatID:   "group-1",
+				ChatType: "group",
+				SenderID: "123",
+			},
+			Content: content,
+		})
+		if err != nil {
+			t.Fatalf("processMessage(%q) error = %v", content, err)
+		}
+		if want := "Link failed: " + session.ErrLinkNotDirect.Error(); resp != want {
+			t.Fatalf("%s in a group reply = %q, want %q", content, resp, want)
+		}
+	}
+}
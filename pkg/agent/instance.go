@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/isolation"
@@ -19,6 +21,24 @@ import (
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
+// contextWindowDetectTimeout bounds the provider query used to detect a
+// model's context window at agent creation.
+const contextWindowDetectTimeout = 5 * time.Second
+
+// contextWindowRetryAfter is how long a failed detection is remembered
+// before the provider is asked again, e.g. after Ollama has been started.
+const contextWindowRetryAfter = time.Minute
+
+type detectedContextWindow struct {
+	window int
+	at     time.Time
+}
+
+// contextWindowCache holds detection results keyed by protocol, api_base and
+// model so agents rebuilt on reload, or sharing a model, do not repeat the
+// provider round trip. Failures are cached as 0 for contextWindowRetryAfter.
+var contextWindowCache sync.Map
+
 // AgentInstance represents a fully configured agent with its own workspace,
 // session manager, context builder, and tool registry.
 type AgentInstance struct {
@@ -42,20 +62,20 @@ type AgentInstance struct {
 	SkillsFilter              []string
 	Candidates                []providers.FallbackCandidate
 
-	// Router is non-nil when model routing is configured and the light model
-	// was successfully resolved. It scores each incoming message and decides
-	// whether to route to LightCandidates or stay with Candidates.
+	// Router is non-nil when model routing is configured and every tier's
+	// model was successfully resolved. It scores each incoming message and
+	// picks the tier that serves the turn.
 	Router *routing.Router
-	// LightCandidates holds the resolved provider candidates for the light model.
-	// Pre-computed at agent creation to avoid repeated model_list lookups at runtime.
-	LightCandidates []providers.FallbackCandidate
-	// LightProvider is the concrete provider instance for the configured light model.
-	// It is only used when routing selects the light tier for a turn.
-	LightProvider providers.LLMProvider
+	// RouteTargets holds the resolved candidates and provider for each
+	// non-primary routing tier, keyed by the tier's model_name.
+	RouteTargets map[string]*RouteTarget
 	// CandidateProviders maps "provider/model" keys to per-candidate LLMProvider
 	// instances. This allows each fallback model to use its own api_base and api_key
 	// from model_list, instead of inheriting the primary model's provider config.
 	CandidateProviders map[string]providers.LLMProvider
+
+	// routeLog keeps the latest routing decision per session for /context.
+	routeLog *routeDecisionLog
 }
 
 // NewAgentInstance creates an agent instance from config.
@@ -95,6 +115,10 @@ func NewAgentInstance(
 			toolsRegistry.Register(tools.NewReadFileBytesTool(workspace, readRestrict, maxReadFileSize, allowReadPaths))
 		}
 	}
+	if cfg.Tools.IsToolEnabled("read_document") {
+		toolsRegistry.Register(tools.NewReadDocumentTool(
+			workspace, readRestrict, cfg.Tools.ReadDocument.MaxChars, allowReadPaths))
+	}
 	if cfg.Tools.IsToolEnabled("write_file") {
 		toolsRegistry.Register(tools.NewWriteFileTool(workspace, restrict, allowWritePaths))
 	}
@@ -152,6 +176,9 @@ func NewAgentInstance(
 	}
 
 	contextWindow := defaults.ContextWindow
+	if contextWindow == 0 {
+		contextWindow = detectContextWindow(cfg, model, workspace)
+	}
 	if contextWindow == 0 {
 		// Default heuristic: 4x the output token limit.
 		// Most models have context windows well above their output limits
@@ -189,38 +216,9 @@ func NewAgentInstance(
 	candidateProviders := make(map[string]providers.LLMProvider)
 	populateCandidateProvidersFromNames(cfg, workspace, fallbacks, candidateProviders)
 
-	// Model routing setup: pre-resolve light model candidates at creation time
+	// Model routing setup: pre-resolve tier models at creation time
 	// to avoid repeated model_list lookups on every incoming message.
-	var router *routing.Router
-	var lightCandidates []providers.FallbackCandidate
-	var lightProvider providers.LLMProvider
-	if rc := defaults.Routing; rc != nil && rc.Enabled && rc.LightModel != "" {
-		resolved := resolveModelCandidates(cfg, defaults.Provider, rc.LightModel, nil)
-		if len(resolved) > 0 {
-			lightModelCfg, err := resolvedModelConfig(cfg, rc.LightModel, workspace)
-			if err != nil {
-				logger.WarnCF("agent", "Routing light model config invalid; routing disabled",
-					map[string]any{"light_model": rc.LightModel, "agent_id": agentID, "error": err.Error()})
-			} else {
-				lp, _, err := providers.CreateProviderFromConfig(lightModelCfg)
-				if err != nil {
-					logger.WarnCF("agent", "Routing light model provider init failed; routing disabled",
-						map[string]any{"light_model": rc.LightModel, "agent_id": agentID, "error": err.Error()})
-				} else {
-					router = routing.New(routing.RouterConfig{
-						LightModel: rc.LightModel,
-						Threshold:  rc.Threshold,
-					})
-					lightCandidates = resolved
-					lightProvider = lp
-					populateCandidateProvidersFromNames(cfg, workspace, []string{rc.LightModel}, candidateProviders)
-				}
-			}
-		} else {
-			logger.WarnCF("agent", "Routing light model not found; routing disabled",
-				map[string]any{"light_model": rc.LightModel, "agent_id": agentID})
-		}
-	}
+	router, routeTargets := newModelRouter(cfg, defaults, agentID, model, workspace, candidateProviders)
 
 	return &AgentInstance{
 		ID:                        agentID,
@@ -243,10 +241,64 @@ func NewAgentInstance(
 		SkillsFilter:              skillsFilter,
 		Candidates:                candidates,
 		Router:                    router,
-		LightCandidates:           lightCandidates,
-		LightProvider:             lightProvider,
+		RouteTargets:              routeTargets,
 		CandidateProviders:        candidateProviders,
+		routeLog:                  newRouteDecisionLog(),
+	}
+}
+
+// detectContextWindow asks the primary model's provider for its context
+// window when the provider supports it (e.g. Ollama /api/show). It returns 0
+// when the provider cannot report one so the caller falls back to the
+// max_tokens heuristic.
+func detectContextWindow(cfg *config.Config, model, workspace string) int {
+	if cfg == nil || strings.TrimSpace(model) == "" {
+		return 0
+	}
+	mc, err := resolvedModelConfig(cfg, model, workspace)
+	if err != nil {
+		return 0
+	}
+	protocol, modelID := providers.ExtractProtocol(mc)
+	if !providers.CanDetectContextWindow(protocol) {
+		return 0
+	}
+	key := protocol + "|" + mc.APIBase + "|" + modelID
+	if v, ok := contextWindowCache.Load(key); ok {
+		cached := v.(detectedContextWindow)
+		if cached.window > 0 || time.Since(cached.at) < contextWindowRetryAfter {
+			return cached.window
+		}
+	}
+	window := queryContextWindow(mc, model)
+	contextWindowCache.Store(key, detectedContextWindow{window: window, at: time.Now()})
+	return window
+}
+
+func queryContextWindow(mc *config.ModelConfig, model string) int {
+	p, modelID, err := providers.CreateProviderFromConfig(mc)
+	if err != nil {
+		return 0
+	}
+	if sp, ok := p.(providers.StatefulProvider); ok {
+		defer sp.Close()
+	}
+	cwp, ok := p.(providers.ContextWindowProvider)
+	if !ok {
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), contextWindowDetectTimeout)
+	defer cancel()
+	window, err := cwp.ContextWindow(ctx, modelID)
+	if err != nil {
+		logger.WarnCF("agent", "Context window detection failed; using heuristic",
+			map[string]any{"model": model, "error": err.Error()})
+		return 0
 	}
+	logger.InfoCF("agent", "Detected model context window",
+		map[string]any{"model": model, "context_window": window})
+	return window
 }
 
 // populateCandidateProvidersFromNames resolves each model name (alias or
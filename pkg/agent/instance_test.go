@@ -2,9 +2,13 @@ package agent
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -616,3 +620,32 @@ func TestNewAgentInstance_InvalidExecConfigDoesNotExit(t *testing.T) {
 		t.Fatal("read_file tool should still be registered")
 	}
 }
+
+func TestDetectContextWindow_CachesResult(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/show" {
+			http.NotFound(w, r)
+			return
+		}
+		hits.Add(1)
+		fmt.Fprint(w, `{"model_info":{"llama.context_length":32768}}`)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		ModelList: []*config.ModelConfig{
+			{ModelName: "local", Model: "ollama-native/llama3.1:8b", APIBase: server.URL},
+		},
+	}
+	workspace := t.TempDir()
+
+	for i := 0; i < 3; i++ {
+		if got := detectContextWindow(cfg, "local", workspace); got != 32768 {
+			t.Fatalf("detectContextWindow() = %d, want 32768", got)
+		}
+	}
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("/api/show called %d times, want 1", n)
+	}
+}
@@ -0,0 +1,221 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+// RouteTarget is a routing tier's model, pre-resolved at agent creation to
+// avoid repeated model_list lookups on every incoming message.
+type RouteTarget struct {
+	// ModelName is the tier's model_name from model_list.
+	ModelName string
+	// Candidates holds the resolved provider candidates for the model.
+	Candidates []providers.FallbackCandidate
+	// Provider is the concrete provider instance for the model.
+	Provider providers.LLMProvider
+}
+
+// maxRouteDecisions bounds how many sessions routeDecisionLog remembers.
+const maxRouteDecisions = 1024
+
+// routeDecisionLog remembers the latest routing decision per session so that
+// /context can explain which tier served the last turn. It keeps at most
+// limit sessions; the session recorded longest ago is forgotten first.
+type routeDecisionLog struct {
+	mu        sync.Mutex
+	decisions map[string]routing.Decision
+	order     []string // ring of session keys; order[next] is the oldest once full
+	next      int
+	limit     int
+}
+
+func newRouteDecisionLog() *routeDecisionLog {
+	return &routeDecisionLog{decisions: make(map[string]routing.Decision), limit: maxRouteDecisions}
+}
+
+func (l *routeDecisionLog) record(sessionKey string, d routing.Decision) {
+	if l == nil || sessionKey == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.decisions[sessionKey]; !ok {
+		if len(l.order) < l.limit {
+			l.order = append(l.order, sessionKey)
+		} else {
+			delete(l.decisions, l.order[l.next])
+			l.order[l.next] = sessionKey
+			l.next = (l.next + 1) % l.limit
+		}
+	}
+	l.decisions[sessionKey] = d
+}
+
+func (l *routeDecisionLog) last(sessionKey string) (routing.Decision, bool) {
+	if l == nil {
+		return routing.Decision{}, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	d, ok := l.decisions[sessionKey]
+	return d, ok
+}
+
+// newModelRouter builds the router and resolves every non-primary tier. Any
+// tier that fails to resolve disables routing entirely, so turns never land
+// on an unintended tier. A failing classifier only falls back to rules.
+func newModelRouter(
+	cfg *config.Config,
+	defaults *config.AgentDefaults,
+	agentID, primaryModel, workspace string,
+	candidateProviders map[string]providers.LLMProvider,
+) (*routing.Router, map[string]*RouteTarget) {
+	rc := defaults.Routing
+	if rc == nil || !rc.Enabled || (rc.LightModel == "" && len(rc.Tiers) == 0) {
+		return nil, nil
+	}
+
+	rcfg := routing.RouterConfig{
+		LightModel: rc.LightModel,
+		Threshold:  rc.Threshold,
+	}
+	for _, t := range rc.Tiers {
+		rcfg.Tiers = append(rcfg.Tiers, routing.Tier{
+			Name:          t.Name,
+			Model:         t.Model,
+			MaxScore:      t.MaxScore,
+			Vision:        t.Vision,
+			Tools:         t.Tools,
+			ContextWindow: t.ContextWindow,
+		})
+	}
+	for i, t := range rcfg.Tiers {
+		// Naming the primary model explicitly is the same as leaving it empty.
+		if t.Model == primaryModel {
+			rcfg.Tiers[i].Model = ""
+		}
+	}
+	if rcfg.Judge = newRoutingJudge(cfg, rc.Classifier, agentID, workspace); rcfg.Judge != nil {
+		logger.InfoCF("agent", "Model routing classifier enabled",
+			map[string]any{"agent_id": agentID, "model": rc.Classifier.Model})
+	}
+	router := routing.New(rcfg)
+
+	targets := make(map[string]*RouteTarget)
+	for _, t := range router.Tiers() {
+		if t.Model == "" {
+			continue
+		}
+		if _, ok := targets[t.Model]; ok {
+			continue
+		}
+		target, err := resolveRouteTarget(cfg, defaults.Provider, t.Model, workspace)
+		if err != nil {
+			logger.WarnCF("agent", "Routing tier model unavailable; routing disabled",
+				map[string]any{"tier": t.Name, "model": t.Model, "agent_id": agentID, "error": err.Error()})
+			return nil, nil
+		}
+		targets[t.Model] = target
+	}
+	for name := range targets {
+		populateCandidateProvidersFromNames(cfg, workspace, []string{name}, candidateProviders)
+	}
+	return router, targets
+}
+
+func resolveRouteTarget(cfg *config.Config, defaultProvider, modelName, workspace string) (*RouteTarget, error) {
+	resolved := resolveModelCandidates(cfg, defaultProvider, modelName, nil)
+	if len(resolved) == 0 {
+		return nil, fmt.Errorf("model %q not found in model_list", modelName)
+	}
+	modelCfg, err := resolvedModelConfig(cfg, modelName, workspace)
+	if err != nil {
+		return nil, err
+	}
+	p, _, err := providers.CreateProviderFromConfig(modelCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &RouteTarget{ModelName: modelName, Candidates: resolved, Provider: p}, nil
+}
+
+func newRoutingJudge(
+	cfg *config.Config,
+	cc *config.RoutingClassifierConfig,
+	agentID, workspace string,
+) *routing.Judge {
+	if cc == nil || cc.Model == "" {
+		return nil
+	}
+	modelCfg, err := resolvedModelConfig(cfg, cc.Model, workspace)
+	if err != nil {
+		logger.WarnCF("agent", "Routing classifier model invalid; using rule classifier",
+			map[string]any{"model": cc.Model, "agent_id": agentID, "error": err.Error()})
+		return nil
+	}
+	p, modelID, err := providers.CreateProviderFromConfig(modelCfg)
+	if err != nil {
+		logger.WarnCF("agent", "Routing classifier provider init failed; using rule classifier",
+			map[string]any{"model": cc.Model, "agent_id": agentID, "error": err.Error()})
+		return nil
+	}
+	return routing.NewJudge(p, routing.JudgeConfig{
+		Model:     modelID,
+		Timeout:   time.Duration(cc.TimeoutSeconds) * time.Second,
+		CacheSize: cc.CacheSize,
+		CacheTTL:  time.Duration(cc.CacheTTLSeconds) * time.Second,
+	})
+}
+
+// selectCandidates picks the model tier for a turn. It returns the
+// candidates and model to call, the tier's target (nil when the primary
+// model serves the turn) and the decision (nil when routing is off).
+func (al *AgentLoop) selectCandidates(
+	ctx context.Context,
+	agent *AgentInstance,
+	userMsg string,
+	history []providers.Message,
+) ([]providers.FallbackCandidate, string, *RouteTarget, *routing.Decision) {
+	primary := func() ([]providers.FallbackCandidate, string) {
+		return agent.Candidates, resolvedCandidateModel(agent.Candidates, agent.Model)
+	}
+	if agent.Router == nil {
+		candidates, model := primary()
+		return candidates, model, nil, nil
+	}
+
+	d := agent.Router.Select(ctx, userMsg, history)
+	fields := map[string]any{
+		"agent_id": agent.ID,
+		"tier":     d.Tier,
+		"score":    d.Score,
+		"source":   d.Source,
+	}
+	if len(d.Skipped) > 0 {
+		fields["skipped"] = d.Skipped
+	}
+	if d.JudgeErr != nil {
+		logger.WarnCF("agent", "Model routing classifier failed; using rules",
+			map[string]any{"agent_id": agent.ID, "error": d.JudgeErr.Error()})
+	}
+
+	target := agent.RouteTargets[d.Model]
+	if d.Primary() || target == nil {
+		fields["model"] = agent.Model
+		logger.DebugCF("agent", "Model routing: primary model selected", fields)
+		candidates, model := primary()
+		return candidates, model, nil, &d
+	}
+
+	fields["model"] = d.Model
+	logger.InfoCF("agent", "Model routing: tier model selected", fields)
+	return target.Candidates, resolvedCandidateModel(target.Candidates, d.Model), target, &d
+}
@@ -79,10 +79,27 @@ func (p *Pipeline) SetupTurn(ctx context.Context, ts *turnState) (*turnExecution
 		ts.ingestMessage(ctx, p.al, rootMsg)
 	}
 
-	activeCandidates, activeModel, usedLight := p.al.selectCandidates(ts.agent, ts.userMessage, messages)
+	activeCandidates, activeModel, routeTarget, decision := p.al.selectCandidates(ctx, ts.agent, ts.userMessage, messages)
 	activeProvider := ts.agent.Provider
-	if usedLight && ts.agent.LightProvider != nil {
-		activeProvider = ts.agent.LightProvider
+	usedLight := routeTarget != nil
+	if usedLight {
+		activeProvider = routeTarget.Provider
+	}
+	if decision != nil {
+		ts.agent.routeLog.record(ts.sessionKey, *decision)
+	}
+	// A tier model the sender's role may not use falls back to the primary.
+	if usedLight && p.al.checkRoleModel(ts.opts.Role, routeTarget.ModelName) != nil {
+		activeCandidates = ts.agent.Candidates
+		activeModel = resolvedCandidateModel(ts.agent.Candidates, ts.agent.Model)
+		activeProvider = ts.agent.Provider
+		usedLight = false
+	}
+	if override := ts.opts.ModelOverride; override != nil {
+		activeCandidates = override.candidates
+		activeModel = resolvedCandidateModel(override.candidates, override.model)
+		activeProvider = override.provider
+		usedLight = false
 	}
 
 	exec := newTurnExecution(
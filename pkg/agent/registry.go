@@ -1,7 +1,9 @@
 package agent
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -27,6 +29,9 @@ func NewAgentRegistry(
 		agents:   make(map[string]*AgentInstance),
 		resolver: routing.NewRouteResolver(cfg),
 	}
+	if triage := newDispatchTriage(cfg); triage != nil {
+		registry.resolver.SetTriage(triage)
+	}
 
 	agentConfigs := cfg.Agents.List
 	if len(agentConfigs) == 0 {
@@ -70,6 +75,16 @@ func (r *AgentRegistry) ResolveRoute(inbound bus.InboundContext) routing.Resolve
 	return r.resolver.ResolveRoute(inbound)
 }
 
+// ResolveMessageRoute determines which agent handles the message, taking its
+// content into account for content selectors and triage.
+func (r *AgentRegistry) ResolveMessageRoute(
+	ctx context.Context,
+	inbound bus.InboundContext,
+	msg routing.DispatchMessage,
+) routing.ResolvedRoute {
+	return r.resolver.ResolveMessageRoute(ctx, inbound, msg)
+}
+
 // ListAgentIDs returns all registered agent IDs.
 func (r *AgentRegistry) ListAgentIDs() []string {
 	r.mu.RLock()
@@ -139,3 +154,38 @@ func (r *AgentRegistry) GetDefaultAgent() *AgentInstance {
 	}
 	return nil
 }
+
+// newDispatchTriage creates the triage model used when no dispatch rule
+// matches. It returns nil when triage is off or its model cannot be set up,
+// in which case unmatched messages go to the default agent.
+func newDispatchTriage(cfg *config.Config) *routing.Triage {
+	if cfg.Agents.Dispatch == nil {
+		return nil
+	}
+	tc := cfg.Agents.Dispatch.Triage
+	if tc == nil || !tc.Enabled {
+		return nil
+	}
+	if tc.Model == "" {
+		logger.WarnCF("agent", "Dispatch triage enabled without a model; triage disabled", nil)
+		return nil
+	}
+	modelCfg, err := resolvedModelConfig(cfg, tc.Model, resolveAgentWorkspace(nil, &cfg.Agents.Defaults))
+	if err != nil {
+		logger.WarnCF("agent", "Dispatch triage model invalid; triage disabled",
+			map[string]any{"model": tc.Model, "error": err.Error()})
+		return nil
+	}
+	p, modelID, err := providers.CreateProviderFromConfig(modelCfg)
+	if err != nil {
+		logger.WarnCF("agent", "Dispatch triage provider init failed; triage disabled",
+			map[string]any{"model": tc.Model, "error": err.Error()})
+		return nil
+	}
+	logger.InfoCF("agent", "Dispatch triage enabled", map[string]any{"model": tc.Model})
+	return routing.NewTriage(p, routing.TriageConfig{
+		Model:    modelID,
+		Timeout:  time.Duration(tc.TimeoutSeconds) * time.Second,
+		CacheTTL: time.Duration(tc.CacheTTLSeconds) * time.Second,
+	})
+}
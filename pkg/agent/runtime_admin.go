@@ -0,0 +1,218 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/mcp"
+)
+
+// RuntimeAdminPath is the prefix under which the gateway serves runtime
+// status and control endpoints for the web dashboard:
+//
+//	GET  /runtime/status                 MCP servers and mounted hooks
+//	POST /runtime/mcp/{name}/restart     reconnect one MCP server
+//	POST /runtime/sessions/continue      run a turn in an existing session
+const RuntimeAdminPath = "/runtime/"
+
+// errMCPServerUnavailable marks restart requests for servers that are not
+// configured or not enabled.
+var errMCPServerUnavailable = errors.New("MCP server unavailable")
+
+// errInvalidContinueRequest marks continue requests that do not name an
+// explicit session or carry no message.
+var errInvalidContinueRequest = errors.New("invalid continue request")
+
+// ContinueSessionRequest runs one turn in an existing session on behalf of
+// the dashboard. Channel and ChatID come from the session scope so the turn
+// sees the same context as the original conversation.
+type ContinueSessionRequest struct {
+	SessionKey string `json:"session_key"`
+	Channel    string `json:"channel"`
+	ChatID     string `json:"chat_id"`
+	Content    string `json:"content"`
+	// Deliver also sends the reply to the original channel and chat.
+	Deliver bool `json:"deliver,omitempty"`
+}
+
+// ContinueSessionResponse carries the agent reply of a continued session.
+type ContinueSessionResponse struct {
+	Response string `json:"response"`
+}
+
+// RuntimeStatus reports the live MCP and hook state of the agent loop.
+type RuntimeStatus struct {
+	MCP   MCPRuntimeStatus   `json:"mcp"`
+	Hooks HooksRuntimeStatus `json:"hooks"`
+}
+
+// MCPRuntimeStatus lists every MCP server that connected or failed to.
+type MCPRuntimeStatus struct {
+	Running   bool               `json:"running"`
+	InitError string             `json:"init_error,omitempty"`
+	Servers   []mcp.ServerStatus `json:"servers"`
+}
+
+// HooksRuntimeStatus lists mounted hooks and the builtin hooks this binary
+// can mount.
+type HooksRuntimeStatus struct {
+	Mounted   []string `json:"mounted"`
+	Builtins  []string `json:"builtins"`
+	InitError string   `json:"init_error,omitempty"`
+}
+
+// RuntimeStatus returns the current MCP and hook state.
+func (al *AgentLoop) RuntimeStatus() RuntimeStatus {
+	status := RuntimeStatus{
+		MCP:   MCPRuntimeStatus{Servers: []mcp.ServerStatus{}},
+		Hooks: HooksRuntimeStatus{Mounted: al.hookRuntime.getMounted(), Builtins: builtinHookNames()},
+	}
+	if manager := al.mcp.getManager(); manager != nil {
+		status.MCP.Running = true
+		status.MCP.Servers = manager.Status()
+	}
+	if err := al.mcp.getInitErr(); err != nil {
+		status.MCP.InitError = err.Error()
+	}
+	if err := al.hookRuntime.getInitErr(); err != nil {
+		status.Hooks.InitError = err.Error()
+	}
+	return status
+}
+
+// RestartMCPServer reconnects one configured MCP server and re-registers its
+// tools with every agent. When MCP failed to start altogether, every server
+// is initialized again instead.
+func (al *AgentLoop) RestartMCPServer(ctx context.Context, name string) error {
+	cfg := al.GetConfig()
+	serverCfg, ok := cfg.Tools.MCP.Servers[name]
+	if !ok {
+		return fmt.Errorf("%w: %q is not configured", errMCPServerUnavailable, name)
+	}
+	if !cfg.Tools.IsToolEnabled("mcp") || !serverCfg.Enabled {
+		return fmt.Errorf("%w: %q is disabled", errMCPServerUnavailable, name)
+	}
+
+	manager := al.mcp.getManager()
+	if manager == nil {
+		al.mcp.reset()
+		return al.ensureMCPInitialized(ctx)
+	}
+
+	if serverCfg.EnvFile != "" && !filepath.IsAbs(serverCfg.EnvFile) {
+		workspace := cfg.WorkspacePath()
+		if agent := al.GetRegistry().GetDefaultAgent(); agent != nil && agent.Workspace != "" {
+			workspace = agent.Workspace
+		}
+		serverCfg.EnvFile = filepath.Join(workspace, serverCfg.EnvFile)
+	}
+
+	conn, err := manager.RestartServer(ctx, name, serverCfg)
+	if err != nil {
+		return err
+	}
+	registrations := al.registerMCPServerTools(manager, name, conn)
+	logger.InfoCF("agent", "MCP server restarted",
+		map[string]any{
+			"server":              name,
+			"tool_count":          len(conn.Tools),
+			"total_registrations": registrations,
+		})
+	return nil
+}
+
+// ContinueSession appends a message to an existing session and runs the
+// agent. The session key must be explicit so routing keeps it instead of
+// allocating a new session for the channel and chat.
+func (al *AgentLoop) ContinueSession(ctx context.Context, req ContinueSessionRequest) (string, error) {
+	sessionKey := strings.TrimSpace(req.SessionKey)
+	if !isExplicitSessionKey(sessionKey) {
+		return "", fmt.Errorf("%w: %q is not an explicit session key", errInvalidContinueRequest, sessionKey)
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return "", fmt.Errorf("%w: content is empty", errInvalidContinueRequest)
+	}
+	channel, chatID := strings.TrimSpace(req.Channel), strings.TrimSpace(req.ChatID)
+	if channel == "" {
+		channel, chatID = "cli", "direct"
+	}
+
+	response, err := al.ProcessDirectWithChannel(ctx, req.Content, sessionKey, channel, chatID)
+	if err != nil {
+		return "", err
+	}
+	if req.Deliver && chatID != "" {
+		al.PublishResponseIfNeeded(ctx, channel, chatID, sessionKey, response)
+	}
+	return response, nil
+}
+
+// RuntimeAdminHandler serves RuntimeAdminPath. It uses the same bearer token
+// check as EventStreamHandler.
+func (al *AgentLoop) RuntimeAdminHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+RuntimeAdminPath+"status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(al.RuntimeStatus())
+	})
+	mux.HandleFunc("POST "+RuntimeAdminPath+"mcp/{name}/restart", func(w http.ResponseWriter, r *http.Request) {
+		// Stdio servers live as long as the context they were started with,
+		// so the connection must outlive this request.
+		ctx := context.WithoutCancel(r.Context())
+		if err := al.RestartMCPServer(ctx, r.PathValue("name")); err != nil {
+			status := http.StatusBadGateway
+			if errors.Is(err, errMCPServerUnavailable) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(al.RuntimeStatus().MCP)
+	})
+	mux.HandleFunc("POST "+RuntimeAdminPath+"sessions/continue", func(w http.ResponseWriter, r *http.Request) {
+		var req ContinueSessionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		response, err := al.ContinueSession(r.Context(), req)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, errInvalidContinueRequest) {
+				status = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ContinueSessionResponse{Response: response})
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorizedRequest(r, token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func builtinHookNames() []string {
+	builtinHookRegistryMu.RLock()
+	defer builtinHookRegistryMu.RUnlock()
+
+	names := make([]string, 0, len(builtinHookRegistry))
+	for name := range builtinHookRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
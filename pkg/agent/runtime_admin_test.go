@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestRuntimeAdminHandler(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Tools.MCP.Enabled = true
+	cfg.Tools.MCP.Servers = map[string]config.MCPServerConfig{
+		"off": {Enabled: false, Command: "true"},
+	}
+	al.hookRuntime.setMounted([]string{"audit"})
+
+	srv := httptest.NewServer(al.RuntimeAdminHandler("secret"))
+	defer srv.Close()
+
+	do := func(method, path, token string) *http.Response {
+		req, _ := http.NewRequest(method, srv.URL+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		return resp
+	}
+
+	resp := do(http.MethodGet, "/runtime/status", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status without token = %d, want 401", resp.StatusCode)
+	}
+
+	resp = do(http.MethodGet, "/runtime/status", "secret")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var status RuntimeStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if status.MCP.Running || status.MCP.Servers == nil {
+		t.Fatalf("mcp status = %+v, want not running with empty server list", status.MCP)
+	}
+	if len(status.Hooks.Mounted) != 1 || status.Hooks.Mounted[0] != "audit" {
+		t.Fatalf("mounted hooks = %v", status.Hooks.Mounted)
+	}
+
+	for _, name := range []string{"missing", "off"} {
+		resp = do(http.MethodPost, "/runtime/mcp/"+name+"/restart", "secret")
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("restart %s = %d, want 404", name, resp.StatusCode)
+		}
+	}
+}
+
+func TestContinueSession(t *testing.T) {
+	al, _, msgBus, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	for _, req := range []ContinueSessionRequest{
+		{SessionKey: "not-explicit", Content: "hi"},
+		{SessionKey: "agent:main:telegram:direct:42", Content: "  "},
+	} {
+		if _, err := al.ContinueSession(ctx, req); !errors.Is(err, errInvalidContinueRequest) {
+			t.Fatalf("ContinueSession(%+v) error = %v, want errInvalidContinueRequest", req, err)
+		}
+	}
+
+	sessionKey := "agent:main:telegram:direct:42"
+	response, err := al.ContinueSession(ctx, ContinueSessionRequest{
+		SessionKey: sessionKey,
+		Channel:    "telegram",
+		ChatID:     "42",
+		Content:    "pick up where we left off",
+		Deliver:    true,
+	})
+	if err != nil {
+		t.Fatalf("ContinueSession: %v", err)
+	}
+	if response != "Mock response" {
+		t.Fatalf("response = %q, want Mock response", response)
+	}
+
+	history := al.GetRegistry().GetDefaultAgent().Sessions.GetHistory(sessionKey)
+	if len(history) == 0 || history[0].Content != "pick up where we left off" {
+		t.Fatalf("history = %+v, want the continued message in %s", history, sessionKey)
+	}
+
+	select {
+	case out := <-msgBus.OutboundChan():
+		if out.Channel != "telegram" || out.ChatID != "42" || out.Content != "Mock response" {
+			t.Fatalf("outbound = %+v, want reply delivered to telegram:42", out)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the reply to be delivered to the original chat")
+	}
+}
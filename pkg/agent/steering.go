@@ -405,13 +405,31 @@ func (al *AgentLoop) InterruptGraceful(hint string) error {
 	if ts == nil {
 		return fmt.Errorf("no active turn")
 	}
+	return al.interruptGraceful(ts, "InterruptGraceful", hint)
+}
+
+// InterruptGracefulSession asks the active turn of sessionKey to stop calling
+// tools and wrap up with a final answer, e.g. when the user talks over a
+// voice reply.
+func (al *AgentLoop) InterruptGracefulSession(sessionKey, hint string) error {
+	ts := al.getActiveTurnState(sessionKey)
+	if ts == nil {
+		return fmt.Errorf("no active turn for session %s", sessionKey)
+	}
+	if strings.HasPrefix(ts.turnID, pendingTurnPrefix) {
+		return fmt.Errorf("turn is still initializing for session %s", sessionKey)
+	}
+	return al.interruptGraceful(ts, "InterruptGracefulSession", hint)
+}
+
+func (al *AgentLoop) interruptGraceful(ts *turnState, source, hint string) error {
 	if !ts.requestGracefulInterrupt(hint) {
 		return fmt.Errorf("turn %s cannot accept graceful interrupt", ts.turnID)
 	}
 
 	al.emitEvent(
 		EventKindInterruptReceived,
-		ts.eventMeta("InterruptGraceful", "turn.interrupt.received"),
+		ts.eventMeta(source, "turn.interrupt.received"),
 		InterruptReceivedPayload{
 			Kind:    InterruptKindGraceful,
 			HintLen: len(hint),
@@ -430,7 +448,22 @@ func (al *AgentLoop) InterruptHard() error {
 	if ts == nil {
 		return fmt.Errorf("no active turn")
 	}
-	if strings.HasPrefix(ts.turnID, "pending-") {
+	return al.interruptHard(ts, "InterruptHard")
+}
+
+// InterruptHardSession aborts the active turn of sessionKey only. Unlike
+// HardAbort it goes through the turn's own abort path, so the turn rolls back
+// its session history and emits its end events as usual.
+func (al *AgentLoop) InterruptHardSession(sessionKey string) error {
+	ts := al.getActiveTurnState(sessionKey)
+	if ts == nil {
+		return fmt.Errorf("no active turn for session %s", sessionKey)
+	}
+	return al.interruptHard(ts, "InterruptHardSession")
+}
+
+func (al *AgentLoop) interruptHard(ts *turnState, source string) error {
+	if strings.HasPrefix(ts.turnID, pendingTurnPrefix) {
 		return fmt.Errorf("turn is still initializing for session %s", ts.sessionKey)
 	}
 	if !ts.requestHardAbort() {
@@ -439,7 +472,7 @@ func (al *AgentLoop) InterruptHard() error {
 
 	al.emitEvent(
 		EventKindInterruptReceived,
-		ts.eventMeta("InterruptHard", "turn.interrupt.received"),
+		ts.eventMeta(source, "turn.interrupt.received"),
 		InterruptReceivedPayload{
 			Kind: InterruptKindHard,
 		},
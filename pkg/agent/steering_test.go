@@ -1379,6 +1379,81 @@ func TestAgentLoop_InterruptHard_RestoresSession(t *testing.T) {
 	}
 }
 
+func TestAgentLoop_StopCommand_AbortsOnlyOwnSession(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	provider := &toolCallProvider{
+		toolCalls: []providers.ToolCall{
+			{
+				ID:   "call_1",
+				Type: "function",
+				Name: "cancel_tool",
+				Function: &providers.FunctionCall{
+					Name:      "cancel_tool",
+					Arguments: "{}",
+				},
+				Arguments: map[string]any{},
+			},
+		},
+		finalResp: "should not happen",
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	started := make(chan struct{})
+	al.RegisterTool(&interruptibleTool{name: "cancel_tool", started: started})
+	busyKey := session.BuildMainSessionKey(routing.DefaultAgentID)
+	otherKey := "agent:" + routing.DefaultAgentID + ":other"
+
+	resultCh := make(chan string, 1)
+	go func() {
+		resp, _ := al.ProcessDirectWithChannel(context.Background(), "do work", busyKey, "test", "chat1")
+		resultCh <- resp
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for interruptible tool to start")
+	}
+
+	reply, err := al.ProcessDirectWithChannel(context.Background(), "/stop", otherKey, "test", "chat2")
+	if err != nil {
+		t.Fatalf("/stop in other session failed: %v", err)
+	}
+	if reply != "Nothing is running in this chat." {
+		t.Fatalf("unexpected /stop reply for idle session: %q", reply)
+	}
+	if al.GetActiveTurnBySession(busyKey) == nil {
+		t.Fatal("/stop in another session must not abort the busy turn")
+	}
+
+	reply, err = al.ProcessDirectWithChannel(context.Background(), "/stop", busyKey, "test", "chat1")
+	if err != nil {
+		t.Fatalf("/stop failed: %v", err)
+	}
+	if reply != "Stopped." {
+		t.Fatalf("unexpected /stop reply: %q", reply)
+	}
+
+	select {
+	case resp := <-resultCh:
+		if resp != "" {
+			t.Fatalf("expected no final response after /stop, got %q", resp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for stopped turn")
+	}
+}
+
 // capturingMockProvider captures messages sent to Chat for inspection.
 type capturingMockProvider struct {
 	response  string
@@ -1553,3 +1628,31 @@ func init() {
 	// with the proper argument serialization.
 	_ = json.Marshal
 }
+
+func TestInterruptGracefulSession_TargetsOnlyThatSession(t *testing.T) {
+	al := &AgentLoop{}
+	if err := al.InterruptGracefulSession("voice", "hint"); err == nil {
+		t.Fatal("expected error without an active turn")
+	}
+
+	pending := &turnState{turnID: makePendingTurnID("voice", 1), sessionKey: "voice"}
+	al.activeTurnStates.Store("voice", pending)
+	if err := al.InterruptGracefulSession("voice", "hint"); err == nil {
+		t.Fatal("expected error while the turn is still initializing")
+	}
+
+	voice := &turnState{turnID: "turn-voice", sessionKey: "voice"}
+	other := &turnState{turnID: "turn-other", sessionKey: "other"}
+	al.activeTurnStates.Store("voice", voice)
+	al.activeTurnStates.Store("other", other)
+
+	if err := al.InterruptGracefulSession("voice", "user spoke"); err != nil {
+		t.Fatalf("InterruptGracefulSession failed: %v", err)
+	}
+	if requested, hint := voice.gracefulInterruptRequested(); !requested || hint != "user spoke" {
+		t.Fatalf("voice turn interrupt = %v %q", requested, hint)
+	}
+	if requested, _ := other.gracefulInterruptRequested(); requested {
+		t.Fatal("other session's turn must not be interrupted")
+	}
+}
@@ -359,9 +359,12 @@ func spawnSubTurn(
 		InboundContext: cloneInboundContext(parentTS.opts.Dispatch.InboundContext),
 	}
 	opts := processOptions{
-		Dispatch:                dispatch,
-		SenderID:                parentTS.opts.Dispatch.SenderID(),
-		SenderDisplayName:       parentTS.opts.SenderDisplayName,
+		Dispatch:          dispatch,
+		SenderID:          parentTS.opts.Dispatch.SenderID(),
+		SenderDisplayName: parentTS.opts.SenderDisplayName,
+		// The child acts for the parent's sender and keeps its tool limits.
+		Role:                    parentTS.opts.Role,
+		AllowedTools:            append([]string(nil), parentTS.opts.AllowedTools...),
 		SystemPromptOverride:    cfg.ActualSystemPrompt,
 		InitialSteeringMessages: cfg.InitialMessages,
 		DefaultResponse:         "",
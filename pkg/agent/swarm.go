@@ -0,0 +1,227 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/swarm"
+	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// swarmSessionPrefix namespaces the throwaway sessions of delegated tasks.
+const swarmSessionPrefix = "swarm:"
+
+// SwarmServer returns the peer API server for this agent loop, or nil when
+// swarm mode is disabled or has no token configured.
+func (al *AgentLoop) SwarmServer() *swarm.Server {
+	cfg := al.GetConfig()
+	if cfg == nil || !cfg.Swarm.Enabled {
+		return nil
+	}
+	token := cfg.Swarm.Token.String()
+	if token == "" {
+		logger.WarnCF("swarm", "Swarm enabled without a token; peer API disabled", nil)
+		return nil
+	}
+	if len(cfg.Swarm.AllowAgents) == 0 {
+		logger.WarnCF("swarm", "Swarm enabled without allow_agents; peers cannot run any local agent", nil)
+	}
+	return swarm.NewServer(
+		swarmName(cfg.Swarm.Name),
+		token,
+		&swarmExecutor{al: al, allow: cfg.Swarm.AllowAgents},
+		swarm.WithVersion(cfg.BuildInfo.Version),
+		swarm.WithTaskTimeout(time.Duration(cfg.Swarm.Timeout)*time.Second),
+	)
+}
+
+func swarmName(name string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return "picoclaw"
+}
+
+// swarmExecutor runs tasks delegated by remote peers on local agents. Only
+// agents matching allow are exposed; an empty list exposes none and "*"
+// exposes all.
+type swarmExecutor struct {
+	al    *AgentLoop
+	allow []string
+}
+
+func (e *swarmExecutor) allowed(agentID string) bool {
+	for _, pattern := range e.allow {
+		if pattern == "*" {
+			return true
+		}
+		if ok, err := path.Match(pattern, agentID); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *swarmExecutor) Agents() []swarm.AgentInfo {
+	registry := e.al.GetRegistry()
+	var agents []swarm.AgentInfo
+	for _, id := range registry.ListAgentIDs() {
+		agent, ok := registry.GetAgent(id)
+		if !ok || !e.allowed(id) {
+			continue
+		}
+		agents = append(agents, swarm.AgentInfo{
+			ID:    id,
+			Name:  agent.Name,
+			Model: agent.Model,
+			Tools: agent.Tools.List(),
+		})
+	}
+	return agents
+}
+
+func (e *swarmExecutor) RunTask(
+	ctx context.Context,
+	taskID string,
+	req swarm.TaskRequest,
+	progress func(swarm.Event),
+) (string, error) {
+	al := e.al
+	registry := al.GetRegistry()
+
+	agent := registry.GetDefaultAgent()
+	if req.AgentID != "" {
+		var ok bool
+		if agent, ok = registry.GetAgent(req.AgentID); !ok {
+			return "", fmt.Errorf("unknown agent %q", req.AgentID)
+		}
+	}
+	if agent == nil {
+		return "", fmt.Errorf("no default agent")
+	}
+	if !e.allowed(agent.ID) {
+		return "", fmt.Errorf("agent %q is not available to peers", agent.ID)
+	}
+
+	if err := al.ensureHooksInitialized(ctx); err != nil {
+		return "", err
+	}
+	if err := al.ensureMCPInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	sessionKey := swarmSessionPrefix + taskID
+	sub := al.SubscribeEvents(64)
+	defer al.UnsubscribeEvents(sub.ID)
+	go forwardSwarmProgress(sub.C, sessionKey, agent.ID, progress)
+
+	userMessage := "You are a subagent running a task delegated by another PicoClaw instance. " +
+		"Complete the task independently and report the result.\n\nTask: " + req.Task
+	return al.runAgentLoop(ctx, agent, processOptions{
+		Dispatch: DispatchRequest{
+			SessionKey:  sessionKey,
+			UserMessage: userMessage,
+		},
+		DefaultResponse:      defaultResponse,
+		SuppressToolFeedback: true,
+		NoHistory:            true,
+	})
+}
+
+// forwardSwarmProgress turns the tool events of one delegated turn into
+// progress events for the delegating peer. It returns when events closes.
+func forwardSwarmProgress(events <-chan Event, sessionKey, agentID string, progress func(swarm.Event)) {
+	for evt := range events {
+		if evt.Meta.SessionKey != sessionKey {
+			continue
+		}
+		switch payload := evt.Payload.(type) {
+		case ToolExecStartPayload:
+			progress(swarm.Event{AgentID: agentID, Tool: payload.Tool, Message: "started"})
+		case ToolExecEndPayload:
+			msg := "finished"
+			if payload.IsError {
+				msg = "failed"
+			}
+			progress(swarm.Event{AgentID: agentID, Tool: payload.Tool, Message: msg, IsError: payload.IsError})
+		}
+	}
+}
+
+// delegateToPeer runs a spawn task on a remote peer. The remote run shows up
+// in this loop's event stream as a sub-turn of the calling turn.
+func (al *AgentLoop) delegateToPeer(
+	ctx context.Context,
+	peers *swarm.Peers,
+	peer, task, label, agentID string,
+) (*tools.ToolResult, error) {
+	client, ok := peers.Get(peer)
+	if !ok {
+		return nil, fmt.Errorf("unknown peer %q", peer)
+	}
+
+	cfg := al.GetConfig()
+	timeout := time.Duration(cfg.Swarm.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = swarm.DefaultTaskTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	meta := EventMeta{Source: "delegateToPeer", TracePath: "subturn.remote"}
+	parentTurnID := ""
+	if parentTS := turnStateFromContext(ctx); parentTS != nil {
+		meta = parentTS.eventMeta("delegateToPeer", "subturn.remote")
+		parentTurnID = parentTS.turnID
+	}
+	meta.ParentTurnID = parentTurnID
+	meta.TurnID = al.generateSubTurnID()
+
+	al.emitEvent(EventKindSubTurnSpawn, meta, SubTurnSpawnPayload{
+		AgentID:      agentID,
+		Label:        label,
+		ParentTurnID: parentTurnID,
+		Peer:         client.Name(),
+	})
+
+	result, err := client.Delegate(ctx, swarm.TaskRequest{
+		Task:    task,
+		Label:   label,
+		AgentID: agentID,
+		Origin:  swarmName(cfg.Swarm.Name),
+	}, func(evt swarm.Event) {
+		if evt.Type != swarm.EventProgress {
+			return
+		}
+		al.emitEvent(EventKindSubTurnProgress, meta, SubTurnProgressPayload{
+			Peer:    client.Name(),
+			TaskID:  evt.TaskID,
+			AgentID: evt.AgentID,
+			Tool:    evt.Tool,
+			Message: evt.Message,
+		})
+	})
+
+	status := "completed"
+	if err != nil {
+		status = "error"
+	}
+	al.emitEvent(EventKindSubTurnEnd, meta, SubTurnEndPayload{AgentID: agentID, Status: status})
+	if err != nil {
+		return nil, err
+	}
+
+	name := label
+	if name == "" {
+		name = utils.Truncate(task, 40)
+	}
+	return &tools.ToolResult{
+		ForLLM:  fmt.Sprintf("Subagent '%s' on peer %s completed: %s", name, client.Name(), result),
+		ForUser: result,
+	}, nil
+}
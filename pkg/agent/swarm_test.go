@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/swarm"
+)
+
+func TestSwarmServer_DisabledWithoutToken(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	if al.SwarmServer() != nil {
+		t.Fatal("expected no server while swarm is disabled")
+	}
+	cfg.Swarm.Enabled = true
+	if al.SwarmServer() != nil {
+		t.Fatal("expected no server without a token")
+	}
+}
+
+func TestSwarmExecutor_AllowList(t *testing.T) {
+	cases := []struct {
+		allow []string
+		want  bool
+	}{
+		{nil, false},
+		{[]string{}, false},
+		{[]string{"*"}, true},
+		{[]string{"main"}, true},
+		{[]string{"research-*"}, false},
+	}
+	for _, tc := range cases {
+		e := &swarmExecutor{allow: tc.allow}
+		if got := e.allowed("main"); got != tc.want {
+			t.Errorf("allowed(main) with %q = %v, want %v", tc.allow, got, tc.want)
+		}
+	}
+}
+
+func TestDelegateToPeer_RunsTaskOnRemoteLoop(t *testing.T) {
+	remote, remoteCfg, _, _, cleanupRemote := newTestAgentLoop(t)
+	defer cleanupRemote()
+	remoteCfg.Swarm.Enabled = true
+	remoteCfg.Swarm.Name = "lab"
+	remoteCfg.Swarm.Token = *config.NewSecureString("secret")
+	remoteCfg.Swarm.AllowAgents = []string{"*"}
+	srv := httptest.NewServer(remote.SwarmServer())
+	defer srv.Close()
+
+	local, _, _, _, cleanupLocal := newTestAgentLoop(t)
+	defer cleanupLocal()
+	sub := local.SubscribeEvents(16)
+	defer local.UnsubscribeEvents(sub.ID)
+
+	peers := swarm.NewPeers(config.SwarmPeers{
+		{Name: "lab", URL: srv.URL, Token: *config.NewSecureString("secret")},
+	})
+	result, err := local.delegateToPeer(context.Background(), peers, "lab", "say hi", "greet", "")
+	if err != nil {
+		t.Fatalf("delegateToPeer() error = %v", err)
+	}
+	if result.ForUser != "Mock response" {
+		t.Fatalf("result = %+v, want the remote agent's answer", result)
+	}
+
+	var spawned, ended bool
+	for len(sub.C) > 0 {
+		evt := <-sub.C
+		switch p := evt.Payload.(type) {
+		case SubTurnSpawnPayload:
+			spawned = p.Peer == "lab"
+		case SubTurnEndPayload:
+			ended = p.Status == "completed"
+		}
+	}
+	if !spawned || !ended {
+		t.Fatalf("sub-turn events: spawned=%v ended=%v", spawned, ended)
+	}
+}
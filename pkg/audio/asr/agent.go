@@ -12,32 +12,83 @@ import (
 	"github.com/pion/rtp"
 	"github.com/pion/webrtc/v3/pkg/media/oggwriter"
 
+	"github.com/sipeed/picoclaw/pkg/audio"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
 type speechAccumulator struct {
-	writer      *oggwriter.OggWriter
+	writer      *oggwriter.OggWriter // opus chunks
+	pcm         *os.File             // pcm chunks, WAV header written on close
+	pcmBytes    int
+	sampleRate  int
 	file        string
 	lastAudioAt time.Time
 	mu          sync.Mutex
 	closed      bool
 	chatID      string
+	chatType    string
 	speakerID   string
 	sessionID   string
 	channel     string
 }
 
+func newSpeechAccumulator(key string, chunk bus.AudioChunk) (*speechAccumulator, error) {
+	acc := &speechAccumulator{
+		sampleRate:  chunk.SampleRate,
+		lastAudioAt: time.Now(),
+		chatID:      chunk.ChatID,
+		chatType:    chunk.ChatType,
+		speakerID:   chunk.SpeakerID,
+		sessionID:   chunk.SessionID,
+		channel:     chunk.Channel,
+	}
+	base := filepath.Join(os.TempDir(), fmt.Sprintf("voice_%s_%d", key, time.Now().UnixNano()))
+
+	if chunk.Format == "pcm" {
+		acc.file = base + ".wav"
+		f, err := os.OpenFile(acc.file, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		// Reserve the header; its sizes are only known once speech ends.
+		if _, err := f.Write(audio.WAVHeader(0, acc.sampleRate)); err != nil {
+			f.Close()
+			os.Remove(acc.file)
+			return nil, err
+		}
+		acc.pcm = f
+		return acc, nil
+	}
+
+	acc.file = base + ".ogg"
+	writer, err := oggwriter.New(acc.file, uint32(chunk.SampleRate), uint16(chunk.Channels))
+	if err != nil {
+		return nil, err
+	}
+	acc.writer = writer
+	return acc, nil
+}
+
 func (a *speechAccumulator) Push(chunk bus.AudioChunk) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	if a.closed {
+	if a.closed || len(chunk.Data) == 0 {
 		return
 	}
 
 	a.lastAudioAt = time.Now()
 
+	if a.pcm != nil {
+		n, err := a.pcm.Write(chunk.Data)
+		a.pcmBytes += n
+		if err != nil {
+			logger.ErrorCF("voice-agent", "Failed to write PCM", map[string]any{"error": err})
+		}
+		return
+	}
+
 	pkt := &rtp.Packet{
 		Header: rtp.Header{
 			SequenceNumber: uint16(chunk.Sequence),
@@ -55,9 +106,18 @@ func (a *speechAccumulator) Push(chunk bus.AudioChunk) {
 func (a *speechAccumulator) Close() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	if !a.closed {
+	if a.closed {
+		return
+	}
+	a.closed = true
+	if a.writer != nil {
 		a.writer.Close()
-		a.closed = true
+	}
+	if a.pcm != nil {
+		if _, err := a.pcm.WriteAt(audio.WAVHeader(a.pcmBytes, a.sampleRate), 0); err != nil {
+			logger.ErrorCF("voice-agent", "Failed to finalize WAV", map[string]any{"error": err})
+		}
+		a.pcm.Close()
 	}
 }
 
@@ -106,16 +166,19 @@ func (a *Agent) listenChunks(ctx context.Context) {
 			if !ok {
 				return
 			}
-			a.handleChunk(chunk)
+			if done := a.handleChunk(chunk); done != nil {
+				go a.processUtterance(ctx, done)
+			}
 		}
 	}
 }
 
-func (a *Agent) handleChunk(chunk bus.AudioChunk) {
-	// Only accept Opus-encoded audio
-	if chunk.Format != "opus" {
+// handleChunk adds chunk to its speaker's utterance. It returns the closed
+// utterance when the chunk marks the end of speech.
+func (a *Agent) handleChunk(chunk bus.AudioChunk) *speechAccumulator {
+	if chunk.Format != "opus" && chunk.Format != "pcm" {
 		logger.DebugCF("voice-agent", "Ignoring unsupported audio format", map[string]any{"format": chunk.Format})
-		return
+		return nil
 	}
 
 	key := fmt.Sprintf("%s_%s", chunk.SessionID, chunk.SpeakerID)
@@ -123,29 +186,37 @@ func (a *Agent) handleChunk(chunk bus.AudioChunk) {
 	a.mu.Lock()
 	acc, exists := a.sessions[key]
 	if !exists {
-		filename := filepath.Join(os.TempDir(), fmt.Sprintf("voice_%s_%d.ogg", key, time.Now().UnixNano()))
-		writer, err := oggwriter.New(filename, uint32(chunk.SampleRate), uint16(chunk.Channels))
-		if err != nil {
+		if chunk.EndOfSpeech && len(chunk.Data) == 0 {
 			a.mu.Unlock()
-			logger.ErrorCF("voice-agent", "Failed to create OggWriter", map[string]any{"error": err})
-			return
+			return nil
 		}
-
-		acc = &speechAccumulator{
-			writer:      writer,
-			file:        filename,
-			lastAudioAt: time.Now(),
-			chatID:      chunk.ChatID,
-			speakerID:   chunk.SpeakerID,
-			sessionID:   chunk.SessionID,
-			channel:     chunk.Channel,
+		var err error
+		acc, err = newSpeechAccumulator(key, chunk)
+		if err != nil {
+			a.mu.Unlock()
+			logger.ErrorCF("voice-agent", "Failed to create utterance file", map[string]any{"error": err})
+			return nil
 		}
 		a.sessions[key] = acc
-		logger.DebugCF("voice-agent", "Started accumulating voice", map[string]any{"key": key, "file": filename})
+		logger.DebugCF("voice-agent", "Started accumulating voice", map[string]any{"key": key, "file": acc.file})
 	}
 	a.mu.Unlock()
 
 	acc.Push(chunk)
+	if !chunk.EndOfSpeech {
+		return nil
+	}
+
+	a.mu.Lock()
+	if a.sessions[key] != acc {
+		// The silence timer already took this utterance.
+		a.mu.Unlock()
+		return nil
+	}
+	delete(a.sessions, key)
+	a.mu.Unlock()
+	acc.Close()
+	return acc
 }
 
 func (a *Agent) vadTick(ctx context.Context) {
@@ -213,10 +284,8 @@ func (a *Agent) processUtterance(ctx context.Context, acc *speechAccumulator) {
 		channelType = "discord" // fallback for legacy chunks
 	}
 
-	text := strings.ToLower(strings.TrimSpace(res.Text))
-	if strings.Contains(text, "leave the voice channel") || strings.Contains(text, "leave voice") ||
-		strings.Contains(text, "disconnect voice") || strings.Contains(text, "leave the channel") ||
-		strings.Contains(text, "leave channel") {
+	// Leave phrases only mean something where the bot joins a voice channel.
+	if channelType == "discord" && isLeaveCommand(res.Text) {
 		logger.InfoCF("voice-agent", "Voice command triggered: leave", nil)
 		if err := a.bus.PublishVoiceControl(ctx, bus.VoiceControl{
 			SessionID: acc.sessionID,
@@ -234,13 +303,18 @@ func (a *Agent) processUtterance(ctx context.Context, acc *speechAccumulator) {
 		return
 	}
 
+	chatType := acc.chatType
+	if chatType == "" {
+		chatType = "channel"
+	}
+
 	oralPrompt := "\n\n[SYSTEM]: The user just spoke this to you over voice chat. Please reply in a highly concise, conversational, oral style suitable for text-to-speech. Do not use markdown, emojis, asterisks, or code blocks. Speak naturally."
 
 	if err := a.bus.PublishInbound(ctx, bus.InboundMessage{
 		Context: bus.InboundContext{
 			Channel:  channelType,
 			ChatID:   acc.chatID,
-			ChatType: "channel",
+			ChatType: chatType,
 			SenderID: acc.speakerID,
 			Raw: map[string]string{
 				"is_voice": "true",
@@ -251,3 +325,23 @@ func (a *Agent) processUtterance(ctx context.Context, acc *speechAccumulator) {
 		logger.ErrorCF("voice-agent", "Failed to publish inbound message", map[string]any{"error": err})
 	}
 }
+
+// leavePhrases are the spoken commands that make the bot leave a voice
+// channel.
+var leavePhrases = []string{
+	"leave the voice channel",
+	"leave voice",
+	"disconnect voice",
+	"leave the channel",
+	"leave channel",
+}
+
+func isLeaveCommand(text string) bool {
+	text = strings.ToLower(strings.TrimSpace(text))
+	for _, phrase := range leavePhrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
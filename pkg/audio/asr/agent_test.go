@@ -87,7 +87,7 @@ func TestAgentHandleChunkIgnoresUnsupportedFormat(t *testing.T) {
 
 	agent := NewAgent(mb, &fakeTranscriber{})
 
-	chunk := bus.AudioChunk{Format: "pcm"}
+	chunk := bus.AudioChunk{Format: "mp3"}
 	agent.handleChunk(chunk)
 
 	agent.mu.Lock()
@@ -146,6 +146,41 @@ func TestAgentProcessUtteranceLeaveCommand(t *testing.T) {
 	}
 }
 
+func TestAgentProcessUtteranceLeavePhraseOnPicoIsMessage(t *testing.T) {
+	t.Parallel()
+
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	agent := NewAgent(mb, &fakeTranscriber{text: "you can leave channel settings as they are"})
+
+	filePath := filepath.Join(t.TempDir(), "voice.ogg")
+	if err := os.WriteFile(filePath, []byte("data"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	acc := &speechAccumulator{
+		file:      filePath,
+		chatID:    "pico:sess",
+		speakerID: "speaker",
+		sessionID: "sess",
+		channel:   "pico",
+	}
+
+	agent.processUtterance(context.Background(), acc)
+
+	select {
+	case ctrl := <-mb.VoiceControlsChan():
+		t.Fatalf("unexpected voice control on pico: %#v", ctrl)
+	case msg := <-mb.InboundChan():
+		if msg.Channel != "pico" || !strings.Contains(msg.Content, "leave channel") {
+			t.Fatalf("unexpected inbound message: %#v", msg)
+		}
+	case <-time.After(250 * time.Millisecond):
+		t.Fatal("expected inbound publish")
+	}
+}
+
 func TestAgentCheckSilencePublishesInboundAndCleansUp(t *testing.T) {
 	t.Parallel()
 
@@ -194,3 +229,59 @@ func TestAgentCheckSilencePublishesInboundAndCleansUp(t *testing.T) {
 
 	waitForFileRemoval(t, filePath, 500*time.Millisecond)
 }
+
+func TestAgentHandleChunkPCMEndOfSpeech(t *testing.T) {
+	t.Parallel()
+
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	tr := &fakeTranscriber{text: "what time is it"}
+	agent := NewAgent(mb, tr)
+
+	chunk := bus.AudioChunk{
+		SessionID:  "pico_voice_s1",
+		SpeakerID:  "pico-user",
+		ChatID:     "pico:s1",
+		Channel:    "pico",
+		ChatType:   "direct",
+		SampleRate: 16000,
+		Channels:   1,
+		Format:     "pcm",
+		Data:       make([]byte, 640),
+	}
+	if done := agent.handleChunk(chunk); done != nil {
+		t.Fatal("utterance should stay open without end_of_speech")
+	}
+	chunk.Data = make([]byte, 320)
+	chunk.EndOfSpeech = true
+	acc := agent.handleChunk(chunk)
+	if acc == nil {
+		t.Fatal("end_of_speech should close the utterance")
+	}
+
+	agent.mu.Lock()
+	count := len(agent.sessions)
+	agent.mu.Unlock()
+	if count != 0 {
+		t.Fatalf("closed utterance should leave the session table, got %d", count)
+	}
+
+	data, err := os.ReadFile(acc.file)
+	if err != nil {
+		t.Fatalf("read utterance: %v", err)
+	}
+	if string(data[:4]) != "RIFF" || len(data) != 44+960 {
+		t.Fatalf("utterance is not a WAV of the pushed PCM: %d bytes", len(data))
+	}
+
+	agent.processUtterance(context.Background(), acc)
+	select {
+	case msg := <-mb.InboundChan():
+		if msg.Channel != "pico" || msg.ChatID != "pico:s1" || msg.Context.ChatType != "direct" {
+			t.Fatalf("unexpected inbound context: %+v", msg.Context)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("expected inbound publish")
+	}
+}
@@ -112,6 +112,10 @@ func DetectTranscriber(cfg *config.Config) Transcriber {
 		return nil
 	}
 
+	if cfg.Voice.ASRCommand.Enabled() {
+		return NewCommandTranscriber(cfg.Voice.ASRCommand)
+	}
+
 	if modelName := strings.TrimSpace(cfg.Voice.ModelName); modelName != "" {
 		modelCfg, err := cfg.GetModelConfig(modelName)
 		if err == nil {
@@ -0,0 +1,183 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/audio"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const defaultASRSampleRate = 16000
+
+// whisperTimestamp matches the "[00:00:00.000 --> 00:00:02.000]" prefix
+// whisper.cpp prints unless run with -nt.
+var whisperTimestamp = regexp.MustCompile(`(?m)^\s*\[[0-9:.]+\s*-->\s*[0-9:.]+\]\s*`)
+
+// CommandTranscriber runs a local speech recognizer such as whisper.cpp or
+// vosk as a subprocess. The transcript is read from {output} (or
+// {output}.txt) when the template names it, otherwise from stdout.
+type CommandTranscriber struct {
+	command    []string
+	convert    []string
+	format     string
+	sampleRate int
+	timeout    time.Duration
+}
+
+func NewCommandTranscriber(cfg config.VoiceCommandConfig) *CommandTranscriber {
+	if !cfg.Enabled() {
+		return nil
+	}
+	format := strings.ToLower(strings.TrimSpace(cfg.Format))
+	if format == "" {
+		format = "wav"
+	}
+	rate := cfg.SampleRate
+	if rate <= 0 {
+		rate = defaultASRSampleRate
+	}
+	logger.DebugCF("voice", "Creating command transcriber", map[string]any{
+		"command": cfg.Command[0],
+		"format":  format,
+		"convert": len(cfg.Convert) > 0,
+	})
+	return &CommandTranscriber{
+		command:    cfg.Command,
+		convert:    cfg.Convert,
+		format:     format,
+		sampleRate: rate,
+		timeout:    time.Duration(cfg.TimeoutSeconds) * time.Second,
+	}
+}
+
+func (t *CommandTranscriber) Name() string {
+	return "command:" + filepath.Base(t.command[0])
+}
+
+func (t *CommandTranscriber) Transcribe(ctx context.Context, audioFilePath string) (*TranscriptionResponse, error) {
+	workDir, err := audio.NewWorkDir("picoclaw-asr-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create work dir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	input, err := t.prepareInput(ctx, audioFilePath, workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	output := filepath.Join(workDir, "transcript")
+	vars := map[string]string{
+		"input":       input,
+		"output":      output,
+		"sample_rate": strconv.Itoa(t.sampleRate),
+	}
+	var stdin io.Reader
+	if !audio.UsesPlaceholder(t.command, "input") {
+		data, err := os.ReadFile(input)
+		if err != nil {
+			return nil, err
+		}
+		stdin = bytes.NewReader(data)
+	}
+
+	started := time.Now()
+	stdout, err := audio.RunCommand(ctx, audio.ExpandCommand(t.command, vars), stdin, t.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("transcription command failed: %w", err)
+	}
+
+	raw := stdout
+	if audio.UsesPlaceholder(t.command, "output") {
+		raw, err = os.ReadFile(output)
+		if os.IsNotExist(err) {
+			raw, err = os.ReadFile(output + ".txt")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("transcription output not found: %w", err)
+		}
+	}
+
+	text := parseTranscript(raw)
+	logger.InfoCF("voice", "Command transcription completed", map[string]any{
+		"command":     t.command[0],
+		"text_length": len(text),
+		"elapsed":     time.Since(started).String(),
+	})
+	return &TranscriptionResponse{Text: text}, nil
+}
+
+// prepareInput copies the audio into workDir in the format the engine
+// expects, running the convert command when the source format differs.
+func (t *CommandTranscriber) prepareInput(ctx context.Context, src, workDir string) (string, error) {
+	srcFormat, err := audio.SniffFileFormat(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read audio: %w", err)
+	}
+	input := filepath.Join(workDir, "input."+t.format)
+
+	if srcFormat == t.format || (srcFormat == "" && len(t.convert) == 0) {
+		return input, copyFile(src, input)
+	}
+	if len(t.convert) == 0 {
+		return "", fmt.Errorf("audio is %s but the recognizer expects %s; set voice.asr_command.convert", srcFormat, t.format)
+	}
+
+	source := filepath.Join(workDir, "source"+filepath.Ext(src))
+	if err := copyFile(src, source); err != nil {
+		return "", err
+	}
+	argv := audio.ExpandCommand(t.convert, map[string]string{
+		"input":       source,
+		"output":      input,
+		"sample_rate": strconv.Itoa(t.sampleRate),
+	})
+	if _, err := audio.RunCommand(ctx, argv, nil, t.timeout); err != nil {
+		return "", fmt.Errorf("audio conversion failed: %w", err)
+	}
+	return input, nil
+}
+
+// parseTranscript accepts plain text, whisper.cpp output with timestamps,
+// or JSON with a top-level "text" field (vosk, whisper -oj style servers).
+func parseTranscript(raw []byte) string {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var result struct {
+			Text string `json:"text"`
+		}
+		if json.Unmarshal(trimmed, &result) == nil && result.Text != "" {
+			return strings.TrimSpace(result.Text)
+		}
+	}
+	text := whisperTimestamp.ReplaceAllString(string(trimmed), "")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,98 @@
+package asr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func writeAudio(t *testing.T, name, header string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("command backends are exercised with sh")
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(header+"audio-data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const wavHeader = "RIFF\x00\x00\x00\x00WAVEfmt "
+
+func TestCommandTranscriber_StdoutWithTimestamps(t *testing.T) {
+	path := writeAudio(t, "voice.wav", wavHeader)
+	tr := NewCommandTranscriber(config.VoiceCommandConfig{
+		Command: []string{"sh", "-c", `test -s "$0" && printf '[00:00:00.000 --> 00:00:01.500]  hello\n[00:00:01.500 --> 00:00:02.000]  world\n'`, "{input}"},
+	})
+
+	resp, err := tr.Transcribe(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Transcribe() error: %v", err)
+	}
+	if resp.Text != "hello world" {
+		t.Errorf("Text = %q, want %q", resp.Text, "hello world")
+	}
+	if tr.Name() != "command:sh" {
+		t.Errorf("Name() = %q", tr.Name())
+	}
+}
+
+func TestCommandTranscriber_OutputFileAndStdin(t *testing.T) {
+	path := writeAudio(t, "voice.wav", wavHeader)
+	// No {input}: audio arrives on stdin. whisper.cpp style: -of base writes base.txt.
+	tr := NewCommandTranscriber(config.VoiceCommandConfig{
+		Command: []string{"sh", "-c", `grep -q audio-data && echo "from file" > "$0.txt"`, "{output}"},
+	})
+
+	resp, err := tr.Transcribe(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Transcribe() error: %v", err)
+	}
+	if resp.Text != "from file" {
+		t.Errorf("Text = %q", resp.Text)
+	}
+}
+
+func TestCommandTranscriber_ConvertsOtherFormats(t *testing.T) {
+	path := writeAudio(t, "voice.ogg", "OggS")
+
+	tr := NewCommandTranscriber(config.VoiceCommandConfig{
+		Command: []string{"sh", "-c", `echo "$0"`, "{input}"},
+	})
+	if _, err := tr.Transcribe(context.Background(), path); err == nil ||
+		!strings.Contains(err.Error(), "asr_command.convert") {
+		t.Fatalf("expected conversion hint, got %v", err)
+	}
+
+	tr = NewCommandTranscriber(config.VoiceCommandConfig{
+		Command: []string{"sh", "-c", `echo "$(basename "$0")"`, "{input}"},
+		Convert: []string{"cp", "{input}", "{output}"},
+	})
+	resp, err := tr.Transcribe(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Transcribe() error: %v", err)
+	}
+	if resp.Text != "input.wav" {
+		t.Errorf("engine should receive the converted file, got %q", resp.Text)
+	}
+}
+
+func TestParseTranscriptJSON(t *testing.T) {
+	if got := parseTranscript([]byte(`{"text": " bonjour "}`)); got != "bonjour" {
+		t.Errorf("parseTranscript(json) = %q", got)
+	}
+}
+
+func TestDetectTranscriberPrefersCommand(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Voice.ASRCommand = config.VoiceCommandConfig{Command: []string{"whisper-cli"}}
+	if _, ok := DetectTranscriber(cfg).(*CommandTranscriber); !ok {
+		t.Fatal("expected CommandTranscriber when asr_command is set")
+	}
+}
@@ -0,0 +1,100 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/isolation"
+)
+
+// DefaultCommandTimeout bounds one local speech engine invocation.
+const DefaultCommandTimeout = 120 * time.Second
+
+// ExpandCommand substitutes {name} placeholders in an argv template.
+// Values are inserted as whole arguments or argument fragments and never
+// pass through a shell.
+func ExpandCommand(template []string, vars map[string]string) []string {
+	pairs := make([]string, 0, len(vars)*2)
+	for name, value := range vars {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	// A single-pass replacer keeps placeholders inside substituted values
+	// (e.g. spoken text containing "{output}") from being expanded again.
+	r := strings.NewReplacer(pairs...)
+	args := make([]string, len(template))
+	for i, arg := range template {
+		args[i] = r.Replace(arg)
+	}
+	return args
+}
+
+// UsesPlaceholder reports whether any argument of template refers to {name}.
+func UsesPlaceholder(template []string, name string) bool {
+	for _, arg := range template {
+		if strings.Contains(arg, "{"+name+"}") {
+			return true
+		}
+	}
+	return false
+}
+
+// RunCommand runs argv through the shared isolation wrapper and returns its
+// stdout. stderr is included in the error when the command fails.
+func RunCommand(ctx context.Context, argv []string, stdin io.Reader, timeout time.Duration) ([]byte, error) {
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := isolation.Run(cmd); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%s timed out after %s", argv[0], timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", argv[0], err, truncate(msg, 500))
+		}
+		return nil, fmt.Errorf("%s: %w", argv[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// NewWorkDir creates a scratch directory for one engine invocation. When
+// process isolation is enabled it lives in the instance's redirected temp
+// directory, which isolated commands can always reach.
+func NewWorkDir(prefix string) (string, error) {
+	base := ""
+	if isolation.CurrentConfig().Enabled {
+		root, err := isolation.ResolveInstanceRoot()
+		if err != nil {
+			return "", err
+		}
+		base = isolation.ResolveUserEnv(root).Tmp
+		if err := os.MkdirAll(base, 0o700); err != nil {
+			return "", err
+		}
+	}
+	return os.MkdirTemp(base, prefix)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
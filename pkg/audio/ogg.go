@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
 )
 
 // DecodeOggOpus reads an Ogg format stream and extracts individual Opus payloads.
@@ -55,3 +56,52 @@ func DecodeOggOpus(r io.Reader, onFrame func([]byte) error) error {
 		}
 	}
 }
+
+// SniffFormat identifies common audio containers from the first bytes of a
+// file. It returns "ogg", "wav", "mp3", "flac", "webm", "m4a", or "" when
+// the format is not recognized.
+func SniffFormat(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return "ogg"
+	case len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		return "wav"
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return "flac"
+	case bytes.HasPrefix(head, []byte("ID3")),
+		len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0:
+		return "mp3"
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "webm"
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		return "m4a"
+	}
+	return ""
+}
+
+// SniffFileFormat is SniffFormat applied to the start of a file.
+func SniffFileFormat(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head := make([]byte, 16)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return SniffFormat(head[:n]), nil
+}
+
+// CountOggOpusFrames returns the number of Opus frames in an Ogg stream. It
+// is used to check that converted audio really is Ogg/Opus before it is
+// handed to a channel that streams it frame by frame.
+func CountOggOpusFrames(r io.Reader) (int, error) {
+	n := 0
+	err := DecodeOggOpus(r, func([]byte) error {
+		n++
+		return nil
+	})
+	return n, err
+}
@@ -144,3 +144,31 @@ func TestDecodeOggOpus_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestSniffFormat(t *testing.T) {
+	tests := map[string]string{
+		"OggS\x00\x02":                 "ogg",
+		"RIFF\x24\x00\x00\x00WAVEfmt ": "wav",
+		"ID3\x04":                      "mp3",
+		"\xFF\xFB\x90":                 "mp3",
+		"fLaC":                         "flac",
+		"\x1A\x45\xDF\xA3":             "webm",
+		"\x00\x00\x00\x20ftypM4A ":     "m4a",
+		"hello":                        "",
+	}
+	for head, want := range tests {
+		if got := SniffFormat([]byte(head)); got != want {
+			t.Errorf("SniffFormat(%q) = %q, want %q", head, got, want)
+		}
+	}
+}
+
+func TestWriteWAV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteWAV(&buf, []byte{1, 2, 3, 4}, 16000); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 48 || SniffFormat(buf.Bytes()) != "wav" {
+		t.Fatalf("unexpected WAV output: %q", buf.Bytes())
+	}
+}
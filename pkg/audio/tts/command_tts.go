@@ -0,0 +1,208 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/audio"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const defaultTTSSampleRate = 22050
+
+var audioContentTypes = map[string]string{
+	"ogg":  "audio/ogg",
+	"mp3":  "audio/mpeg",
+	"wav":  "audio/wav",
+	"flac": "audio/flac",
+}
+
+// AudioFormatter is implemented by providers whose output is not Ogg/Opus.
+type AudioFormatter interface {
+	AudioFormat() (ext, contentType string)
+}
+
+// CommandTTSProvider runs a local synthesizer such as piper or espeak-ng as
+// a subprocess. Text is passed through {text} or stdin, audio is read from
+// {output} or stdout, and an optional convert command transcodes it (by
+// default to Ogg/Opus, which voice channels play directly).
+type CommandTTSProvider struct {
+	command       []string
+	convert       []string
+	format        string
+	convertFormat string
+	sampleRate    int
+	streaming     bool
+	timeout       time.Duration
+}
+
+func NewCommandTTSProvider(cfg config.VoiceCommandConfig) *CommandTTSProvider {
+	if !cfg.Enabled() {
+		return nil
+	}
+	format := strings.ToLower(strings.TrimSpace(cfg.Format))
+	if format == "" {
+		format = "wav"
+	}
+	convertFormat := strings.ToLower(strings.TrimSpace(cfg.ConvertFormat))
+	if convertFormat == "" {
+		convertFormat = "ogg"
+	}
+	rate := cfg.SampleRate
+	if rate <= 0 {
+		rate = defaultTTSSampleRate
+	}
+	return &CommandTTSProvider{
+		command:       cfg.Command,
+		convert:       cfg.Convert,
+		format:        format,
+		convertFormat: convertFormat,
+		sampleRate:    rate,
+		streaming:     cfg.Streaming,
+		timeout:       time.Duration(cfg.TimeoutSeconds) * time.Second,
+	}
+}
+
+func (p *CommandTTSProvider) Name() string {
+	return "command-tts"
+}
+
+// AudioFormat reports the extension and MIME type of synthesized audio.
+func (p *CommandTTSProvider) AudioFormat() (string, string) {
+	format := p.outputFormat()
+	contentType, ok := audioContentTypes[format]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+	return "." + format, contentType
+}
+
+func (p *CommandTTSProvider) outputFormat() string {
+	if len(p.convert) > 0 {
+		return p.convertFormat
+	}
+	if p.format == "pcm" {
+		return "wav"
+	}
+	return p.format
+}
+
+// Synthesize speaks text. With streaming enabled and a format whose files
+// can be concatenated (Ogg, MP3), the text is split into sentences and each
+// one is synthesized while the previous one is already being read.
+func (p *CommandTTSProvider) Synthesize(ctx context.Context, text string) (io.ReadCloser, error) {
+	sentences := []string{text}
+	if format := p.outputFormat(); p.streaming && (format == "ogg" || format == "mp3") {
+		if split := audio.SplitSentences(text); len(split) > 0 {
+			sentences = split
+		}
+	}
+
+	// Synthesize the first sentence up front so configuration errors are
+	// returned to the caller instead of surfacing as a broken stream.
+	first, err := p.synthesizeOne(ctx, sentences[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(sentences) == 1 {
+		return io.NopCloser(bytes.NewReader(first)), nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		if _, err := pw.Write(first); err != nil {
+			return
+		}
+		for _, sentence := range sentences[1:] {
+			data, err := p.synthesizeOne(ctx, sentence)
+			if err != nil {
+				logger.WarnCF("voice", "Command TTS failed mid-stream", map[string]any{"error": err.Error()})
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(data); err != nil {
+				return // reader closed
+			}
+		}
+		pw.Close()
+	}()
+	return pr, nil
+}
+
+func (p *CommandTTSProvider) synthesizeOne(ctx context.Context, text string) ([]byte, error) {
+	workDir, err := audio.NewWorkDir("picoclaw-tts-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create work dir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	output := filepath.Join(workDir, "speech."+p.format)
+	vars := map[string]string{
+		"text":        text,
+		"output":      output,
+		"sample_rate": strconv.Itoa(p.sampleRate),
+	}
+	var stdin io.Reader
+	if !audio.UsesPlaceholder(p.command, "text") {
+		stdin = strings.NewReader(text)
+	}
+
+	data, err := audio.RunCommand(ctx, audio.ExpandCommand(p.command, vars), stdin, p.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("tts command failed: %w", err)
+	}
+	if audio.UsesPlaceholder(p.command, "output") {
+		if data, err = os.ReadFile(output); err != nil {
+			return nil, fmt.Errorf("tts output not found: %w", err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("tts command produced no audio")
+	}
+
+	format := p.format
+	if format == "pcm" {
+		var wav bytes.Buffer
+		if err := audio.WriteWAV(&wav, data, p.sampleRate); err != nil {
+			return nil, err
+		}
+		data, format = wav.Bytes(), "wav"
+	}
+
+	if len(p.convert) > 0 {
+		source := filepath.Join(workDir, "engine."+format)
+		if err := os.WriteFile(source, data, 0o600); err != nil {
+			return nil, err
+		}
+		converted := filepath.Join(workDir, "converted."+p.convertFormat)
+		argv := audio.ExpandCommand(p.convert, map[string]string{
+			"input":       source,
+			"output":      converted,
+			"sample_rate": strconv.Itoa(p.sampleRate),
+		})
+		if _, err := audio.RunCommand(ctx, argv, nil, p.timeout); err != nil {
+			return nil, fmt.Errorf("audio conversion failed: %w", err)
+		}
+		if data, err = os.ReadFile(converted); err != nil {
+			return nil, fmt.Errorf("converted audio not found: %w", err)
+		}
+		format = p.convertFormat
+	}
+
+	if format == "ogg" {
+		// Voice channels stream Ogg output frame by frame as Opus.
+		frames, err := audio.CountOggOpusFrames(bytes.NewReader(data))
+		if err != nil || frames == 0 || !bytes.Contains(data[:min(len(data), 512)], []byte("OpusHead")) {
+			return nil, fmt.Errorf("tts output is not Ogg/Opus audio")
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,98 @@
+package tts
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func skipWithoutSh(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("command backends are exercised with sh")
+	}
+}
+
+func synthesizeAll(t *testing.T, p TTSProvider, text string) string {
+	t.Helper()
+	stream, err := p.Synthesize(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Synthesize() error: %v", err)
+	}
+	defer stream.Close()
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	return string(data)
+}
+
+func TestCommandTTS_OutputFileAndTextPlaceholder(t *testing.T) {
+	skipWithoutSh(t)
+	p := NewCommandTTSProvider(config.VoiceCommandConfig{
+		Command: []string{"sh", "-c", `printf 'RIFF%s' "$1" > "$0"`, "{output}", "{text}"},
+	})
+
+	got := synthesizeAll(t, p, "say {output}")
+	if got != "RIFFsay {output}" {
+		t.Errorf("audio = %q; placeholders inside the text must stay literal", got)
+	}
+	if ext, ct := p.AudioFormat(); ext != ".wav" || ct != "audio/wav" {
+		t.Errorf("AudioFormat() = %q, %q", ext, ct)
+	}
+}
+
+func TestCommandTTS_PCMIsWrappedAsWAV(t *testing.T) {
+	skipWithoutSh(t)
+	p := NewCommandTTSProvider(config.VoiceCommandConfig{
+		Command:    []string{"sh", "-c", `cat >/dev/null; printf '\001\002\003\004'`},
+		Format:     "pcm",
+		SampleRate: 16000,
+	})
+
+	got := synthesizeAll(t, p, "hello")
+	if !strings.HasPrefix(got, "RIFF") || !strings.Contains(got, "WAVE") || !strings.HasSuffix(got, "\x01\x02\x03\x04") {
+		t.Errorf("expected WAV-wrapped PCM, got %q", got)
+	}
+}
+
+func TestCommandTTS_StreamsSentences(t *testing.T) {
+	skipWithoutSh(t)
+	p := NewCommandTTSProvider(config.VoiceCommandConfig{
+		Command:   []string{"sh", "-c", `printf '<%s>' "$(cat)"`},
+		Format:    "mp3",
+		Streaming: true,
+	})
+
+	got := synthesizeAll(t, p, "The first sentence is long enough. The second one is as well.")
+	want := "<The first sentence is long enough.><The second one is as well.>"
+	if got != want {
+		t.Errorf("audio = %q, want %q", got, want)
+	}
+}
+
+func TestCommandTTS_RejectsNonOpusOgg(t *testing.T) {
+	skipWithoutSh(t)
+	p := NewCommandTTSProvider(config.VoiceCommandConfig{
+		Command: []string{"sh", "-c", `printf 'not ogg'`},
+		Format:  "wav",
+		Convert: []string{"cp", "{input}", "{output}"},
+	})
+
+	if _, err := p.Synthesize(context.Background(), "hello"); err == nil ||
+		!strings.Contains(err.Error(), "Ogg/Opus") {
+		t.Fatalf("expected Ogg/Opus validation error, got %v", err)
+	}
+}
+
+func TestDetectTTSPrefersCommand(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Voice.TTSCommand = config.VoiceCommandConfig{Command: []string{"piper"}}
+	if _, ok := DetectTTS(cfg).(*CommandTTSProvider); !ok {
+		t.Fatal("expected CommandTTSProvider when tts_command is set")
+	}
+}
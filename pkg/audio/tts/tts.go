@@ -42,6 +42,10 @@ func DetectTTS(cfg *config.Config) TTSProvider {
 		return nil
 	}
 
+	if cfg.Voice.TTSCommand.Enabled() {
+		return NewCommandTTSProvider(cfg.Voice.TTSCommand)
+	}
+
 	if modelName := strings.TrimSpace(cfg.Voice.TTSModelName); modelName != "" {
 		if mc, err := cfg.GetModelConfig(modelName); err == nil {
 			if provider := providerFromModelConfig(mc); provider != nil {
@@ -60,6 +64,18 @@ func DetectTTS(cfg *config.Config) TTSProvider {
 	return nil
 }
 
+// OutputFormat reports the file extension and MIME type of the audio
+// provider synthesizes. Providers default to Ogg/Opus.
+func OutputFormat(provider TTSProvider) (ext, contentType string) {
+	if provider.Name() == "mimo-tts" {
+		return ".mp3", "audio/mpeg"
+	}
+	if f, ok := provider.(AudioFormatter); ok {
+		return f.AudioFormat()
+	}
+	return ".ogg", "audio/ogg"
+}
+
 // SynthesizeAndStore synthesizes text to speech and registers it in the media store, returning the media reference.
 func SynthesizeAndStore(
 	ctx context.Context,
@@ -94,12 +110,7 @@ func SynthesizeAndStore(
 		return "", fmt.Errorf("failed to create media temp dir: %w", err)
 	}
 
-	fileExt := ".ogg"
-	contentType := "audio/ogg"
-	if provider.Name() == "mimo-tts" {
-		fileExt = ".mp3"
-		contentType = "audio/mpeg"
-	}
+	fileExt, contentType := OutputFormat(provider)
 
 	file, err := os.CreateTemp(media.TempDir(), "tts-*"+fileExt)
 	if err != nil {
@@ -0,0 +1,130 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// VADEvent is a speech boundary reported by VAD.Feed.
+type VADEvent int
+
+const (
+	VADNone VADEvent = iota
+	VADSpeechStart
+	VADSpeechEnd
+)
+
+// VAD segments a stream of frames into utterances. It does not look at audio
+// itself: callers classify each frame as voiced or not (see PCM16RMS) and
+// the detector applies timing, so short clicks do not open an utterance and
+// short pauses do not close one.
+type VAD struct {
+	minSpeech time.Duration
+	hangover  time.Duration
+
+	speaking bool
+	voiced   time.Duration
+	silent   time.Duration
+}
+
+// NewVAD returns a detector that starts an utterance after minSpeech of
+// voiced audio and ends it after hangover of silence.
+func NewVAD(minSpeech, hangover time.Duration) *VAD {
+	return &VAD{minSpeech: minSpeech, hangover: hangover}
+}
+
+// Feed advances the detector by one frame of length d.
+func (v *VAD) Feed(voiced bool, d time.Duration) VADEvent {
+	if voiced {
+		v.voiced += d
+		v.silent = 0
+	} else {
+		v.silent += d
+	}
+
+	if !v.speaking {
+		if v.silent >= v.minSpeech {
+			v.voiced = 0
+		}
+		if v.voiced >= v.minSpeech {
+			v.speaking = true
+			v.silent = 0
+			return VADSpeechStart
+		}
+		return VADNone
+	}
+	if v.silent >= v.hangover {
+		v.Reset()
+		return VADSpeechEnd
+	}
+	return VADNone
+}
+
+// Speaking reports whether an utterance is open.
+func (v *VAD) Speaking() bool {
+	return v.speaking
+}
+
+// Reset drops any open utterance.
+func (v *VAD) Reset() {
+	v.speaking = false
+	v.voiced = 0
+	v.silent = 0
+}
+
+// PCM16RMS returns the RMS level of 16-bit little-endian samples, scaled so
+// that full-scale audio is 1.
+func PCM16RMS(pcm []byte) float64 {
+	n := len(pcm) / 2
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		s := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) / 32768
+		sum += s * s
+	}
+	return math.Sqrt(sum / float64(n))
+}
+
+// PCM16Duration returns the length of n bytes of 16-bit PCM.
+func PCM16Duration(n, sampleRate, channels int) time.Duration {
+	if sampleRate <= 0 || channels <= 0 {
+		return 0
+	}
+	samples := n / (2 * channels)
+	return time.Duration(samples) * time.Second / time.Duration(sampleRate)
+}
+
+// OpusPacketDuration returns the audio length of an Opus packet, read from
+// its TOC byte (RFC 6716, section 3.1). Malformed packets report zero.
+func OpusPacketDuration(packet []byte) time.Duration {
+	if len(packet) == 0 {
+		return 0
+	}
+	toc := packet[0]
+	config := toc >> 3
+
+	var frame time.Duration
+	switch {
+	case config < 12: // SILK
+		frame = []time.Duration{10, 20, 40, 60}[config%4] * time.Millisecond
+	case config < 16: // hybrid
+		frame = []time.Duration{10, 20}[config%2] * time.Millisecond
+	default: // CELT
+		frame = []time.Duration{2500, 5000, 10000, 20000}[config%4] * time.Microsecond
+	}
+
+	switch toc & 0x3 {
+	case 0:
+		return frame
+	case 1, 2:
+		return 2 * frame
+	default:
+		if len(packet) < 2 {
+			return 0
+		}
+		return time.Duration(packet[1]&0x3F) * frame
+	}
+}
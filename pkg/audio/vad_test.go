@@ -0,0 +1,79 @@
+package audio
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestVAD_SegmentsSpeech(t *testing.T) {
+	v := NewVAD(60*time.Millisecond, 200*time.Millisecond)
+	frame := 20 * time.Millisecond
+
+	// A single loud frame (a click) must not open an utterance.
+	if ev := v.Feed(true, frame); ev != VADNone {
+		t.Fatalf("click: got %v", ev)
+	}
+	for i := 0; i < 5; i++ {
+		v.Feed(false, frame)
+	}
+
+	var events []VADEvent
+	feed := func(voiced bool, n int) {
+		for i := 0; i < n; i++ {
+			if ev := v.Feed(voiced, frame); ev != VADNone {
+				events = append(events, ev)
+			}
+		}
+	}
+	feed(true, 10)
+	feed(false, 5) // a pause shorter than the hangover
+	feed(true, 5)
+	feed(false, 10)
+
+	if len(events) != 2 || events[0] != VADSpeechStart || events[1] != VADSpeechEnd {
+		t.Fatalf("events = %v, want one start and one end", events)
+	}
+	if v.Speaking() {
+		t.Error("detector should be idle after the utterance ends")
+	}
+}
+
+func TestPCM16RMS(t *testing.T) {
+	if got := PCM16RMS(make([]byte, 320)); got != 0 {
+		t.Errorf("silence RMS = %v", got)
+	}
+	loud := make([]byte, 320)
+	for i := 0; i < len(loud); i += 2 {
+		s := int16(16384)
+		if i%4 == 0 {
+			s = -16384
+		}
+		binary.LittleEndian.PutUint16(loud[i:], uint16(s))
+	}
+	if got := PCM16RMS(loud); got < 0.49 || got > 0.51 {
+		t.Errorf("half-scale RMS = %v, want 0.5", got)
+	}
+	if got := PCM16Duration(640, 16000, 1); got != 20*time.Millisecond {
+		t.Errorf("PCM16Duration = %v", got)
+	}
+}
+
+func TestOpusPacketDuration(t *testing.T) {
+	tests := []struct {
+		packet []byte
+		want   time.Duration
+	}{
+		{[]byte{31 << 3}, 20 * time.Millisecond},      // CELT 20ms, one frame
+		{[]byte{16 << 3}, 2500 * time.Microsecond},    // CELT 2.5ms
+		{[]byte{1<<3 | 1}, 40 * time.Millisecond},     // SILK 20ms, two frames
+		{[]byte{3<<3 | 3, 3}, 180 * time.Millisecond}, // SILK 60ms, three frames
+		{[]byte{13 << 3}, 20 * time.Millisecond},      // hybrid 20ms
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		if got := OpusPacketDuration(tt.packet); got != tt.want {
+			t.Errorf("OpusPacketDuration(%x) = %v, want %v", tt.packet, got, tt.want)
+		}
+	}
+}
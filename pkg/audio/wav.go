@@ -0,0 +1,41 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// WriteWAV writes 16-bit little-endian mono PCM samples as a WAV file.
+// Engines such as piper can emit raw PCM; wrapping it gives every converter
+// and channel a self-describing file.
+func WriteWAV(w io.Writer, pcm []byte, sampleRate int) error {
+	if _, err := w.Write(WAVHeader(len(pcm), sampleRate)); err != nil {
+		return err
+	}
+	_, err := w.Write(pcm)
+	return err
+}
+
+// WAVHeader returns the 44-byte header for dataLen bytes of 16-bit mono PCM.
+func WAVHeader(dataLen, sampleRate int) []byte {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+	)
+	blockAlign := channels * bitsPerSample / 8
+	header := make([]byte, 44)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(36+dataLen))
+	copy(header[8:], "WAVE")
+	copy(header[12:], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)
+	binary.LittleEndian.PutUint16(header[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(header[22:], channels)
+	binary.LittleEndian.PutUint32(header[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(header[28:], uint32(sampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(header[32:], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(header[34:], bitsPerSample)
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], uint32(dataLen))
+	return header
+}
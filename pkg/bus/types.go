@@ -112,8 +112,14 @@ type AudioChunk struct {
 	Timestamp  uint32 `json:"timestamp"`
 	SampleRate int    `json:"sample_rate"`
 	Channels   int    `json:"channels"`
-	Format     string `json:"format"` // "opus", "pcm", etc
+	Format     string `json:"format"` // "opus", "pcm" (16-bit little-endian)
 	Data       []byte `json:"data"`
+
+	// ChatType is the inbound chat type for the transcript ("channel" if empty).
+	ChatType string `json:"chat_type,omitempty"`
+	// EndOfSpeech marks the last chunk of an utterance when the channel runs
+	// its own VAD, so the transcript is produced without waiting for silence.
+	EndOfSpeech bool `json:"end_of_speech,omitempty"`
 }
 
 // VoiceControl represents state or commands for voice sessions.
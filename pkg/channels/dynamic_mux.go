@@ -41,16 +41,23 @@ func (dm *dynamicServeMux) Unhandle(pattern string) {
 // ServeHTTP dispatches the request to the handler whose pattern best matches
 // the request URL path. It supports both exact path matches and subtree
 // (trailing-slash) prefix matches, choosing the longest prefix on collision.
+// The lock is released before the handler runs, so long-lived requests such
+// as event streams do not block registration.
 func (dm *dynamicServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h := dm.match(r.URL.Path); h != nil {
+		h.ServeHTTP(w, r)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (dm *dynamicServeMux) match(path string) http.Handler {
 	dm.mu.RLock()
 	defer dm.mu.RUnlock()
 
-	path := r.URL.Path
-
 	// Exact match first.
 	if h, ok := dm.handlers[path]; ok {
-		h.ServeHTTP(w, r)
-		return
+		return h
 	}
 
 	// Longest subtree prefix match (patterns ending with "/").
@@ -64,11 +71,5 @@ func (dm *dynamicServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
-
-	if bestHandler != nil {
-		bestHandler.ServeHTTP(w, r)
-		return
-	}
-
-	http.NotFound(w, r)
+	return bestHandler
 }
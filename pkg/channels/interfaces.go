@@ -68,3 +68,24 @@ type PlaceholderRecorder interface {
 type CommandRegistrarCapable interface {
 	RegisterCommands(ctx context.Context, defs []commands.Definition) error
 }
+
+// ToolApprovalCapable is implemented by channels whose clients can approve or
+// deny a tool call before it runs. Channels decide which tools need approval;
+// tools that need none are approved immediately.
+type ToolApprovalCapable interface {
+	RequestToolApproval(ctx context.Context, req ToolApprovalRequest) (ToolApprovalDecision, error)
+}
+
+// ToolApprovalRequest describes a pending tool call in a chat.
+type ToolApprovalRequest struct {
+	ChatID    string
+	AgentID   string
+	Tool      string
+	Arguments map[string]any
+}
+
+// ToolApprovalDecision is the client's answer to a ToolApprovalRequest.
+type ToolApprovalDecision struct {
+	Approved bool
+	Reason   string
+}
@@ -557,14 +557,42 @@ func (c *LINEChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessag
 	}
 
 	// LINE Messaging API requires publicly accessible URLs for media messages.
-	// Since we only have local file paths, send caption text as fallback.
+	// When the store can sign URLs, images are sent as image messages and
+	// other files as links; otherwise only the caption text is sent.
+	signer, _ := store.(media.URLSigner)
 	for _, part := range msg.Parts {
 		caption := part.Caption
 		if caption == "" {
 			caption = fmt.Sprintf("[%s: %s]", part.Type, part.Filename)
 		}
 
-		if err := c.sendPush(ctx, msg.ChatID, caption, ""); err != nil {
+		var messages []map[string]string
+		if signer != nil {
+			if mediaURL, err := signer.SignedURL(part.Ref); err == nil {
+				if part.Type == "image" {
+					messages = append(messages, map[string]string{
+						"type":               "image",
+						"originalContentUrl": mediaURL,
+						"previewImageUrl":    mediaURL,
+					})
+					if part.Caption == "" {
+						caption = ""
+					}
+				} else {
+					caption += "\n" + mediaURL
+				}
+			} else {
+				logger.DebugCF("line", "Signed media URL unavailable", map[string]any{
+					"ref":   part.Ref,
+					"error": err.Error(),
+				})
+			}
+		}
+		if caption != "" {
+			messages = append(messages, buildTextMessage(caption, ""))
+		}
+
+		if err := c.pushMessages(ctx, msg.ChatID, messages); err != nil {
 			return nil, err
 		}
 	}
@@ -596,9 +624,14 @@ func (c *LINEChannel) sendReply(ctx context.Context, replyToken, content, quoteT
 
 // sendPush sends a message using the LINE Push API.
 func (c *LINEChannel) sendPush(ctx context.Context, to, content, quoteToken string) error {
+	return c.pushMessages(ctx, to, []map[string]string{buildTextMessage(content, quoteToken)})
+}
+
+// pushMessages sends one or more message objects using the LINE Push API.
+func (c *LINEChannel) pushMessages(ctx context.Context, to string, messages []map[string]string) error {
 	payload := map[string]any{
 		"to":       to,
-		"messages": []map[string]string{buildTextMessage(content, quoteToken)},
+		"messages": messages,
 	}
 
 	return c.callAPI(ctx, linePushEndpoint, payload)
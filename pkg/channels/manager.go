@@ -688,6 +688,23 @@ func (m *Manager) SetupHTTPServerListeners(listeners []net.Listener, addr string
 	m.httpListeners = append([]net.Listener(nil), listeners...)
 }
 
+// RegisterHTTPHandler mounts a non-channel handler, such as the swarm peer
+// API, on the shared HTTP server. It must be called after SetupHTTPServer.
+func (m *Manager) RegisterHTTPHandler(pattern string, handler http.Handler) {
+	if m.mux == nil {
+		return
+	}
+	m.mux.Handle(pattern, handler)
+}
+
+// UnregisterHTTPHandler removes a handler added with RegisterHTTPHandler.
+func (m *Manager) UnregisterHTTPHandler(pattern string) {
+	if m.mux == nil {
+		return
+	}
+	m.mux.Unhandle(pattern)
+}
+
 // registerHTTPHandlersLocked registers webhook and health-check handlers for
 // all channels currently in m.channels. Caller must hold m.mu (or ensure
 // exclusive access).
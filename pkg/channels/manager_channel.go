@@ -102,6 +102,27 @@ func hiddenValues(key string, value map[string]any, ch *config.Channel) {
 			}
 		}
 		value["webhooks"] = webhooks
+	case "webhook":
+		if settings, ok := v.(*config.WebhookSettings); ok {
+			secrets := make(map[string]string, len(settings.Hooks))
+			for name, hook := range settings.Hooks {
+				secrets[name] = hook.Secret.String()
+			}
+			value["hook_secrets"] = secrets
+		}
+	case "mattermost":
+		if settings, ok := v.(*config.MattermostSettings); ok {
+			value["token"] = settings.Token.String()
+		}
+	case "rocketchat":
+		if settings, ok := v.(*config.RocketChatSettings); ok {
+			value["auth_token"] = settings.AuthToken.String()
+			value["password"] = settings.Password.String()
+		}
+	case "xmpp":
+		if settings, ok := v.(*config.XMPPSettings); ok {
+			value["password"] = settings.Password.String()
+		}
 	}
 }
 
@@ -0,0 +1,175 @@
+package mattermost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+)
+
+type mmUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type mmTeam struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type mmPost struct {
+	ID        string         `json:"id,omitempty"`
+	ChannelID string         `json:"channel_id"`
+	UserID    string         `json:"user_id,omitempty"`
+	RootID    string         `json:"root_id,omitempty"`
+	Message   string         `json:"message"`
+	Type      string         `json:"type,omitempty"`
+	FileIDs   []string       `json:"file_ids,omitempty"`
+	Props     map[string]any `json:"props,omitempty"`
+}
+
+type mmFileInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type mmCommand struct {
+	ID               string `json:"id,omitempty"`
+	TeamID           string `json:"team_id"`
+	Trigger          string `json:"trigger"`
+	Method           string `json:"method"`
+	URL              string `json:"url"`
+	Token            string `json:"token,omitempty"`
+	DisplayName      string `json:"display_name,omitempty"`
+	Description      string `json:"description,omitempty"`
+	AutoComplete     bool   `json:"auto_complete"`
+	AutoCompleteDesc string `json:"auto_complete_desc,omitempty"`
+	AutoCompleteHint string `json:"auto_complete_hint,omitempty"`
+}
+
+// apiURL joins path onto the server's /api/v4 prefix.
+func (c *MattermostChannel) apiURL(path string) string {
+	return c.baseURL + "/api/v4" + path
+}
+
+// do performs an authenticated JSON request. A non-nil body is encoded as
+// JSON; a non-nil out receives the decoded response.
+func (c *MattermostChannel) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL(path), reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.send(req, out)
+}
+
+func (c *MattermostChannel) send(req *http.Request, out any) error {
+	req.Header.Set("Authorization", "Bearer "+c.config.Token.String())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return channels.ClassifyNetError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		var apiErr struct {
+			ID      string `json:"id"`
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return channels.ClassifySendError(resp.StatusCode, fmt.Errorf("mattermost %s %s: %d %s",
+			req.Method, req.URL.Path, resp.StatusCode, apiErr.Message))
+	}
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *MattermostChannel) createPost(ctx context.Context, post mmPost) (string, error) {
+	var created mmPost
+	if err := c.do(ctx, http.MethodPost, "/posts", post, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (c *MattermostChannel) patchPost(ctx context.Context, postID, message string) error {
+	return c.do(ctx, http.MethodPut, "/posts/"+postID+"/patch", map[string]string{"message": message}, nil)
+}
+
+func (c *MattermostChannel) deletePost(ctx context.Context, postID string) error {
+	return c.do(ctx, http.MethodDelete, "/posts/"+postID, nil, nil)
+}
+
+// uploadFile uploads a local file to channelID and returns its file ID for
+// attaching to a post.
+func (c *MattermostChannel) uploadFile(ctx context.Context, channelID, localPath, filename string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if filename == "" {
+		filename = filepath.Base(localPath)
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.WriteField("channel_id", channelID); err != nil {
+		return "", err
+	}
+	part, err := w.CreateFormFile("files", filename)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL("/files"), &buf)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	var resp struct {
+		FileInfos []mmFileInfo `json:"file_infos"`
+	}
+	if err := c.send(req, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.FileInfos) == 0 {
+		return "", fmt.Errorf("mattermost upload returned no file info")
+	}
+	return resp.FileInfos[0].ID, nil
+}
@@ -0,0 +1,262 @@
+package mattermost
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+var commandRegistrationBackoff = []time.Duration{
+	5 * time.Second,
+	15 * time.Second,
+	60 * time.Second,
+	5 * time.Minute,
+	10 * time.Minute,
+}
+
+func commandRegistrationDelay(attempt int) time.Duration {
+	base := commandRegistrationBackoff[min(attempt, len(commandRegistrationBackoff)-1)]
+	// Full jitter in [0.5, 1.0) to avoid synchronized retries across instances.
+	return time.Duration(float64(base) * (0.5 + rand.Float64()*0.5))
+}
+
+// WebhookPath is where Mattermost delivers slash command callbacks.
+func (c *MattermostChannel) WebhookPath() string {
+	if c.config.WebhookPath != "" {
+		return c.config.WebhookPath
+	}
+	return "/webhook/" + c.Name()
+}
+
+// commandCallbackURL is the public URL registered for every slash command.
+func (c *MattermostChannel) commandCallbackURL() string {
+	return strings.TrimRight(c.config.CommandURL, "/") + c.WebhookPath()
+}
+
+// RegisterCommands creates a custom slash command for each definition in
+// every team the bot belongs to, reusing commands it registered earlier.
+// Triggers Mattermost refuses (e.g. built-ins such as /help) are skipped;
+// only transient failures are returned so the caller retries.
+func (c *MattermostChannel) RegisterCommands(ctx context.Context, defs []commands.Definition) error {
+	if c.config.CommandURL == "" {
+		return fmt.Errorf("mattermost command_url is required to register slash commands")
+	}
+	callback := c.commandCallbackURL()
+
+	var teams []mmTeam
+	if err := c.do(ctx, http.MethodGet, "/users/me/teams", nil, &teams); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, team := range teams {
+		var existing []mmCommand
+		query := "/commands?custom_only=true&team_id=" + url.QueryEscape(team.ID)
+		if err := c.do(ctx, http.MethodGet, query, nil, &existing); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		byTrigger := make(map[string]mmCommand, len(existing))
+		for _, cmd := range existing {
+			if cmd.URL == callback {
+				byTrigger[cmd.Trigger] = cmd
+			}
+		}
+
+		for _, def := range defs {
+			if def.Name == "" || def.Description == "" {
+				continue
+			}
+			if cmd, ok := byTrigger[def.Name]; ok {
+				c.addCommandToken(cmd.Token, cmd.Trigger)
+				continue
+			}
+
+			var created mmCommand
+			err := c.do(ctx, http.MethodPost, "/commands", mmCommand{
+				TeamID:           team.ID,
+				Trigger:          def.Name,
+				Method:           "P",
+				URL:              callback,
+				DisplayName:      def.Name,
+				Description:      def.Description,
+				AutoComplete:     true,
+				AutoCompleteDesc: def.Description,
+				AutoCompleteHint: def.Usage,
+			}, &created)
+			switch {
+			case err == nil:
+				c.addCommandToken(created.Token, created.Trigger)
+			case errors.Is(err, channels.ErrSendFailed):
+				logger.WarnCF("mattermost", "Slash command rejected; skipping", map[string]any{
+					"team":    team.Name,
+					"command": def.Name,
+					"error":   err.Error(),
+				})
+			default:
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *MattermostChannel) addCommandToken(token, trigger string) {
+	if token == "" {
+		return
+	}
+	c.tokenMu.Lock()
+	c.commandTokens[token] = trigger
+	c.tokenMu.Unlock()
+}
+
+func (c *MattermostChannel) validCommandToken(token string) bool {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	for known := range c.commandTokens {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *MattermostChannel) startCommandRegistration(ctx context.Context, defs []commands.Definition) {
+	if len(defs) == 0 {
+		return
+	}
+
+	regCtx, cancel := context.WithCancel(ctx)
+	c.commandRegCancel = cancel
+
+	// Registration runs asynchronously so message intake is never blocked by
+	// temporary API failures. Retry stops on success or channel shutdown.
+	go func() {
+		for attempt := 0; ; attempt++ {
+			err := c.RegisterCommands(regCtx, defs)
+			if err == nil {
+				logger.InfoCF("mattermost", "Mattermost slash commands registered", map[string]any{
+					"count": len(defs),
+				})
+				return
+			}
+
+			delay := commandRegistrationDelay(attempt)
+			logger.WarnCF("mattermost", "Mattermost command registration failed; will retry", map[string]any{
+				"error":       err.Error(),
+				"retry_after": delay.String(),
+			})
+			select {
+			case <-regCtx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+	}()
+}
+
+// ServeHTTP handles slash command callbacks, which Mattermost posts as a
+// form signed with the command's token.
+func (c *MattermostChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	token := r.PostForm.Get("token")
+	if token == "" {
+		token, _ = strings.CutPrefix(r.Header.Get("Authorization"), "Token ")
+	}
+	if !c.validCommandToken(token) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	userID := r.PostForm.Get("user_id")
+	channelID := r.PostForm.Get("channel_id")
+	command := strings.TrimSpace(r.PostForm.Get("command"))
+	if userID == "" || channelID == "" || command == "" {
+		http.Error(w, "missing fields", http.StatusBadRequest)
+		return
+	}
+
+	// Acknowledge with an empty ephemeral response; the reply arrives as a post.
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"response_type":"ephemeral","text":""}`))
+
+	sender := bus.SenderInfo{
+		Platform:    "mattermost",
+		PlatformID:  userID,
+		CanonicalID: identity.BuildCanonicalID("mattermost", userID),
+		Username:    r.PostForm.Get("user_name"),
+	}
+	if !c.IsAllowedSender(sender) {
+		logger.DebugCF("mattermost", "Slash command rejected by allowlist", map[string]any{
+			"user_id": userID,
+		})
+		return
+	}
+
+	content := command
+	if text := strings.TrimSpace(r.PostForm.Get("text")); text != "" {
+		content += " " + text
+	}
+
+	chatType := "channel"
+	// Direct message channels are named "<userA>__<userB>".
+	if strings.Contains(r.PostForm.Get("channel_name"), "__") {
+		chatType = "direct"
+	}
+	teamID := r.PostForm.Get("team_id")
+	rootID := r.PostForm.Get("root_id")
+	chatID := channelID
+	if rootID != "" {
+		chatID = channelID + "/" + rootID
+	}
+
+	logger.DebugCF("mattermost", "Slash command received", map[string]any{
+		"sender_id": userID,
+		"command":   utils.Truncate(content, 50),
+	})
+
+	inboundCtx := bus.InboundContext{
+		Channel:   c.Name(),
+		Account:   c.botUserID,
+		ChatID:    channelID,
+		ChatType:  chatType,
+		TopicID:   rootID,
+		SenderID:  userID,
+		Mentioned: true,
+		Raw: map[string]string{
+			"channel_id": channelID,
+			"team_id":    teamID,
+			"trigger_id": r.PostForm.Get("trigger_id"),
+			"is_command": "true",
+			"platform":   "mattermost",
+		},
+	}
+	if teamID != "" {
+		inboundCtx.SpaceID = teamID
+		inboundCtx.SpaceType = "team"
+	}
+
+	c.HandleInboundContext(c.ctx, chatID, content, nil, inboundCtx, sender)
+}
@@ -0,0 +1,33 @@
+package mattermost
+
+import (
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func init() {
+	channels.RegisterFactory(
+		config.ChannelMattermost,
+		func(channelName, channelType string, cfg *config.Config, b *bus.MessageBus) (channels.Channel, error) {
+			bc := cfg.Channels[channelName]
+			decoded, err := bc.GetDecoded()
+			if err != nil {
+				return nil, err
+			}
+			c, ok := decoded.(*config.MattermostSettings)
+			if !ok {
+				return nil, channels.ErrSendFailed
+			}
+			ch, err := NewMattermostChannel(bc, c, b)
+			if err != nil {
+				return nil, err
+			}
+			if channelName != config.ChannelMattermost {
+				ch.SetName(channelName)
+			}
+			ch.workspace = cfg.WorkspacePath()
+			return ch, nil
+		},
+	)
+}
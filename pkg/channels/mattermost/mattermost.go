@@ -0,0 +1,578 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	// maxMessageLength is Mattermost's default post size limit in runes.
+	maxMessageLength  = 16383
+	maxTypingDuration = 5 * time.Minute
+	streamThrottle    = time.Second
+	reactionEmoji     = "eyes"
+)
+
+// MattermostChannel connects a bot account to a Mattermost server. Inbound
+// posts arrive over the WebSocket event stream; replies, edits, reactions
+// and uploads go through the REST API v4.
+type MattermostChannel struct {
+	*channels.BaseChannel
+	bc        *config.Channel
+	config    *config.MattermostSettings
+	baseURL   string
+	client    *http.Client
+	workspace string
+
+	botUserID   string
+	botUsername string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	connMu sync.Mutex
+	conn   *websocket.Conn
+
+	// commandTokens holds the verification tokens of the slash commands
+	// this bot registered, keyed by token.
+	tokenMu          sync.RWMutex
+	commandTokens    map[string]string
+	commandRegCancel context.CancelFunc
+}
+
+func NewMattermostChannel(
+	bc *config.Channel,
+	cfg *config.MattermostSettings,
+	messageBus *bus.MessageBus,
+) (*MattermostChannel, error) {
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.URL), "/")
+	if baseURL == "" || cfg.Token.String() == "" {
+		return nil, fmt.Errorf("mattermost url and token are required")
+	}
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return nil, fmt.Errorf("mattermost url must start with http:// or https://")
+	}
+
+	base := channels.NewBaseChannel(config.ChannelMattermost, cfg, messageBus, bc.AllowFrom,
+		channels.WithMaxMessageLength(maxMessageLength),
+		channels.WithGroupTrigger(bc.GroupTrigger),
+		channels.WithReasoningChannelID(bc.ReasoningChannelID),
+	)
+
+	return &MattermostChannel{
+		BaseChannel:   base,
+		bc:            bc,
+		config:        cfg,
+		baseURL:       baseURL,
+		client:        &http.Client{Timeout: 30 * time.Second},
+		commandTokens: make(map[string]string),
+	}, nil
+}
+
+func (c *MattermostChannel) Start(ctx context.Context) error {
+	logger.InfoC("mattermost", "Starting Mattermost channel")
+
+	c.ctx, c.cancel = context.WithCancel(ctx)
+
+	var me mmUser
+	if err := c.do(c.ctx, http.MethodGet, "/users/me", nil, &me); err != nil {
+		c.cancel()
+		return fmt.Errorf("mattermost auth failed: %w", err)
+	}
+	c.botUserID = me.ID
+	c.botUsername = me.Username
+
+	logger.InfoCF("mattermost", "Mattermost bot connected", map[string]any{
+		"user_id":  c.botUserID,
+		"username": c.botUsername,
+	})
+
+	go c.runWebSocket()
+
+	if c.config.CommandURL != "" {
+		c.startCommandRegistration(c.ctx, commands.WorkspaceDefinitions(c.workspace))
+	}
+
+	c.SetRunning(true)
+	logger.InfoC("mattermost", "Mattermost channel started")
+	return nil
+}
+
+func (c *MattermostChannel) Stop(ctx context.Context) error {
+	logger.InfoC("mattermost", "Stopping Mattermost channel")
+
+	if c.commandRegCancel != nil {
+		c.commandRegCancel()
+	}
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	c.connMu.Lock()
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+	c.connMu.Unlock()
+
+	c.SetRunning(false)
+	logger.InfoC("mattermost", "Mattermost channel stopped")
+	return nil
+}
+
+func (c *MattermostChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+
+	channelID, rootID := resolveOutboundTarget(msg.ChatID, &msg.Context)
+	if channelID == "" {
+		return nil, fmt.Errorf("invalid mattermost chat ID: %s", msg.ChatID)
+	}
+	if rootID == "" && msg.ReplyToMessageID != "" {
+		rootID = msg.ReplyToMessageID
+	}
+
+	postID, err := c.createPost(ctx, mmPost{ChannelID: channelID, RootID: rootID, Message: msg.Content})
+	if err != nil {
+		return nil, fmt.Errorf("mattermost send: %w", err)
+	}
+
+	logger.DebugCF("mattermost", "Message sent", map[string]any{
+		"channel_id": channelID,
+		"root_id":    rootID,
+	})
+	return []string{postID}, nil
+}
+
+// SendMedia implements the channels.MediaSender interface. All parts are
+// uploaded first and attached to a single post carrying the captions.
+func (c *MattermostChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+
+	channelID, rootID := resolveOutboundTarget(msg.ChatID, &msg.Context)
+	if channelID == "" {
+		return nil, fmt.Errorf("invalid mattermost chat ID: %s", msg.ChatID)
+	}
+
+	store := c.GetMediaStore()
+	if store == nil {
+		return nil, fmt.Errorf("no media store available: %w", channels.ErrSendFailed)
+	}
+
+	var fileIDs []string
+	var captions []string
+	for _, part := range msg.Parts {
+		localPath, err := store.Resolve(part.Ref)
+		if err != nil {
+			logger.ErrorCF("mattermost", "Failed to resolve media ref", map[string]any{
+				"ref":   part.Ref,
+				"error": err.Error(),
+			})
+			continue
+		}
+
+		fileID, err := c.uploadFile(ctx, channelID, localPath, part.Filename)
+		if err != nil {
+			logger.ErrorCF("mattermost", "Failed to upload media", map[string]any{
+				"filename": part.Filename,
+				"error":    err.Error(),
+			})
+			return nil, fmt.Errorf("mattermost send media: %w", err)
+		}
+		fileIDs = append(fileIDs, fileID)
+		if part.Caption != "" {
+			captions = append(captions, part.Caption)
+		}
+	}
+	if len(fileIDs) == 0 {
+		return nil, nil
+	}
+
+	postID, err := c.createPost(ctx, mmPost{
+		ChannelID: channelID,
+		RootID:    rootID,
+		Message:   strings.Join(captions, "\n"),
+		FileIDs:   fileIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mattermost send media: %w", err)
+	}
+	return []string{postID}, nil
+}
+
+// EditMessage implements channels.MessageEditor.
+func (c *MattermostChannel) EditMessage(ctx context.Context, chatID string, messageID string, content string) error {
+	if strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("mattermost post ID is empty")
+	}
+	return c.patchPost(ctx, messageID, content)
+}
+
+// DeleteMessage implements channels.MessageDeleter.
+func (c *MattermostChannel) DeleteMessage(ctx context.Context, chatID string, messageID string) error {
+	if strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("mattermost post ID is empty")
+	}
+	return c.deletePost(ctx, messageID)
+}
+
+// SendPlaceholder implements channels.PlaceholderCapable.
+func (c *MattermostChannel) SendPlaceholder(ctx context.Context, chatID string) (string, error) {
+	if !c.bc.Placeholder.Enabled {
+		return "", nil
+	}
+
+	channelID, rootID := parseChatID(chatID)
+	if channelID == "" {
+		return "", fmt.Errorf("invalid mattermost chat ID: %s", chatID)
+	}
+	return c.createPost(ctx, mmPost{
+		ChannelID: channelID,
+		RootID:    rootID,
+		Message:   c.bc.Placeholder.GetRandomText(),
+	})
+}
+
+// ReactToMessage implements channels.ReactionCapable.
+// It adds an "eyes" (👀) reaction to the inbound post and returns an undo
+// function that removes it.
+func (c *MattermostChannel) ReactToMessage(ctx context.Context, chatID, messageID string) (func(), error) {
+	if messageID == "" {
+		return func() {}, nil
+	}
+
+	err := c.do(ctx, http.MethodPost, "/reactions", map[string]string{
+		"user_id":    c.botUserID,
+		"post_id":    messageID,
+		"emoji_name": reactionEmoji,
+	}, nil)
+	if err != nil {
+		return func() {}, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			path := "/users/" + c.botUserID + "/posts/" + messageID + "/reactions/" + reactionEmoji
+			_ = c.do(context.Background(), http.MethodDelete, path, nil, nil)
+		})
+	}, nil
+}
+
+// StartTyping implements channels.TypingCapable. Mattermost clients hide the
+// indicator after a few seconds, so it is refreshed until stopped.
+func (c *MattermostChannel) StartTyping(ctx context.Context, chatID string) (func(), error) {
+	channelID, rootID := parseChatID(chatID)
+	if channelID == "" {
+		return func() {}, fmt.Errorf("invalid mattermost chat ID: %s", chatID)
+	}
+
+	body := map[string]string{"channel_id": channelID, "parent_id": rootID}
+	_ = c.do(ctx, http.MethodPost, "/users/me/typing", body, nil)
+
+	typingCtx, cancel := context.WithCancel(ctx)
+	maxCtx, maxCancel := context.WithTimeout(typingCtx, maxTypingDuration)
+	go func() {
+		defer maxCancel()
+		ticker := time.NewTicker(4 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-maxCtx.Done():
+				return
+			case <-ticker.C:
+				_ = c.do(maxCtx, http.MethodPost, "/users/me/typing", body, nil)
+			}
+		}
+	}()
+
+	return cancel, nil
+}
+
+// BeginStream implements channels.StreamingCapable by posting the first
+// chunk and patching the same post as more output arrives.
+func (c *MattermostChannel) BeginStream(ctx context.Context, chatID string) (channels.Streamer, error) {
+	if !c.config.Streaming {
+		return nil, fmt.Errorf("streaming disabled in config")
+	}
+
+	channelID, rootID := parseChatID(chatID)
+	if channelID == "" {
+		return nil, fmt.Errorf("invalid mattermost chat ID: %s", chatID)
+	}
+	return &mattermostStreamer{channel: c, channelID: channelID, rootID: rootID}, nil
+}
+
+// mattermostStreamer shows partial output by editing a single post. If an
+// update fails it stops editing, and Finalize still delivers the final text.
+type mattermostStreamer struct {
+	channel   *MattermostChannel
+	channelID string
+	rootID    string
+	postID    string
+	lastAt    time.Time
+	failed    bool
+	mu        sync.Mutex
+}
+
+func (s *mattermostStreamer) Update(ctx context.Context, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failed || strings.TrimSpace(content) == "" {
+		return nil
+	}
+	now := time.Now()
+	if s.postID != "" && now.Sub(s.lastAt) < streamThrottle {
+		return nil
+	}
+
+	var err error
+	if s.postID == "" {
+		s.postID, err = s.channel.createPost(ctx, mmPost{ChannelID: s.channelID, RootID: s.rootID, Message: content})
+	} else {
+		err = s.channel.patchPost(ctx, s.postID, content)
+	}
+	if err != nil {
+		logger.WarnCF("mattermost", "Stream update failed, disabling streaming", map[string]any{
+			"error": err.Error(),
+		})
+		s.failed = true
+		return nil
+	}
+	s.lastAt = now
+	return nil
+}
+
+func (s *mattermostStreamer) Finalize(ctx context.Context, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.postID != "" {
+		if err := s.channel.patchPost(ctx, s.postID, content); err == nil {
+			return nil
+		}
+		// Replace the stale partial post rather than leave it half-written.
+		_ = s.channel.deletePost(ctx, s.postID)
+		s.postID = ""
+	}
+
+	id, err := s.channel.createPost(ctx, mmPost{ChannelID: s.channelID, RootID: s.rootID, Message: content})
+	if err != nil {
+		return fmt.Errorf("mattermost finalize: %w", err)
+	}
+	s.postID = id
+	return nil
+}
+
+func (s *mattermostStreamer) Cancel(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.postID != "" {
+		_ = s.channel.deletePost(ctx, s.postID)
+		s.postID = ""
+	}
+}
+
+// handlePosted turns a "posted" WebSocket event into an inbound message.
+func (c *MattermostChannel) handlePosted(data mmPostedData) {
+	var post mmPost
+	if err := json.Unmarshal([]byte(data.Post), &post); err != nil {
+		logger.WarnCF("mattermost", "Failed to decode posted event", map[string]any{"error": err.Error()})
+		return
+	}
+	if post.UserID == "" || post.UserID == c.botUserID || post.Type != "" {
+		return
+	}
+
+	sender := bus.SenderInfo{
+		Platform:    "mattermost",
+		PlatformID:  post.UserID,
+		CanonicalID: identity.BuildCanonicalID("mattermost", post.UserID),
+		Username:    strings.TrimPrefix(data.SenderName, "@"),
+	}
+	if !c.IsAllowedSender(sender) {
+		logger.DebugCF("mattermost", "Message rejected by allowlist", map[string]any{
+			"user_id": post.UserID,
+		})
+		return
+	}
+
+	chatType := chatTypeFor(data.ChannelType)
+	mentioned := c.isMentioned(data, post.Message)
+	content := c.stripBotMention(post.Message)
+
+	if chatType != "direct" {
+		respond, cleaned := c.ShouldRespondInGroup(mentioned, content)
+		if !respond {
+			return
+		}
+		content = cleaned
+	}
+
+	chatID := post.ChannelID
+	if post.RootID != "" {
+		chatID = post.ChannelID + "/" + post.RootID
+	}
+
+	var mediaPaths []string
+	scope := channels.BuildMediaScope("mattermost", chatID, post.ID)
+	for _, fileID := range post.FileIDs {
+		localPath, name := c.downloadFile(fileID)
+		if localPath == "" {
+			continue
+		}
+		mediaPaths = append(mediaPaths, c.storeMedia(localPath, name, scope))
+		content += fmt.Sprintf("\n[file: %s]", name)
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+
+	logger.DebugCF("mattermost", "Received message", map[string]any{
+		"sender_id": post.UserID,
+		"chat_id":   chatID,
+		"preview":   utils.Truncate(content, 50),
+	})
+
+	inboundCtx := bus.InboundContext{
+		Channel:   c.Name(),
+		Account:   c.botUserID,
+		ChatID:    post.ChannelID,
+		ChatType:  chatType,
+		TopicID:   post.RootID,
+		SenderID:  post.UserID,
+		MessageID: post.ID,
+		Mentioned: mentioned,
+		Raw: map[string]string{
+			"post_id":      post.ID,
+			"channel_id":   post.ChannelID,
+			"channel_name": data.ChannelName,
+			"root_id":      post.RootID,
+			"team_id":      data.TeamID,
+			"platform":     "mattermost",
+		},
+	}
+	if data.TeamID != "" {
+		inboundCtx.SpaceID = data.TeamID
+		inboundCtx.SpaceType = "team"
+	}
+
+	c.HandleInboundContext(c.ctx, chatID, content, mediaPaths, inboundCtx, sender)
+}
+
+func (c *MattermostChannel) downloadFile(fileID string) (string, string) {
+	var info mmFileInfo
+	if err := c.do(c.ctx, http.MethodGet, "/files/"+fileID+"/info", nil, &info); err != nil {
+		logger.ErrorCF("mattermost", "Failed to get file info", map[string]any{
+			"file_id": fileID,
+			"error":   err.Error(),
+		})
+		return "", ""
+	}
+	if info.Name == "" {
+		info.Name = fileID
+	}
+
+	localPath := utils.DownloadFile(c.apiURL("/files/"+fileID), info.Name, utils.DownloadOptions{
+		LoggerPrefix: "mattermost",
+		ExtraHeaders: map[string]string{
+			"Authorization": "Bearer " + c.config.Token.String(),
+		},
+	})
+	return localPath, info.Name
+}
+
+func (c *MattermostChannel) storeMedia(localPath, filename, scope string) string {
+	if store := c.GetMediaStore(); store != nil {
+		ref, err := store.Store(localPath, media.MediaMeta{
+			Filename:      filename,
+			Source:        "mattermost",
+			CleanupPolicy: media.CleanupPolicyDeleteOnCleanup,
+		}, scope)
+		if err == nil {
+			return ref
+		}
+	}
+	return localPath
+}
+
+func (c *MattermostChannel) isMentioned(data mmPostedData, message string) bool {
+	if data.Mentions != "" {
+		var ids []string
+		if err := json.Unmarshal([]byte(data.Mentions), &ids); err == nil {
+			for _, id := range ids {
+				if id == c.botUserID {
+					return true
+				}
+			}
+		}
+	}
+	return c.botUsername != "" && strings.Contains(strings.ToLower(message), "@"+strings.ToLower(c.botUsername))
+}
+
+func (c *MattermostChannel) stripBotMention(text string) string {
+	if c.botUsername == "" {
+		return strings.TrimSpace(text)
+	}
+	mention := "@" + c.botUsername
+	for {
+		idx := strings.Index(strings.ToLower(text), strings.ToLower(mention))
+		if idx < 0 {
+			break
+		}
+		text = text[:idx] + text[idx+len(mention):]
+	}
+	return strings.TrimSpace(text)
+}
+
+// chatTypeFor maps Mattermost channel types (D, G, O, P) to peer kinds.
+func chatTypeFor(channelType string) string {
+	switch channelType {
+	case "D":
+		return "direct"
+	case "G":
+		return "group"
+	default:
+		return "channel"
+	}
+}
+
+// parseChatID splits "channelID" or "channelID/rootID".
+func parseChatID(chatID string) (channelID, rootID string) {
+	channelID, rootID, _ = strings.Cut(strings.TrimSpace(chatID), "/")
+	return channelID, rootID
+}
+
+func resolveOutboundTarget(chatID string, outboundCtx *bus.InboundContext) (channelID, rootID string) {
+	target := strings.TrimSpace(chatID)
+	if target == "" && outboundCtx != nil {
+		target = strings.TrimSpace(outboundCtx.ChatID)
+	}
+	channelID, rootID = parseChatID(target)
+	if rootID == "" && outboundCtx != nil {
+		rootID = strings.TrimSpace(outboundCtx.TopicID)
+	}
+	return channelID, rootID
+}
@@ -0,0 +1,325 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeServer is a minimal Mattermost API v4 stand-in.
+type fakeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string // "METHOD path body"
+	events   chan string
+	commands []mmCommand
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	fs := &fakeServer{events: make(chan string, 4)}
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/users/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, `{"message":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(mmUser{ID: "bot1", Username: "picobot"})
+	})
+	mux.HandleFunc("GET /api/v4/websocket", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var challenge map[string]any
+		if conn.ReadJSON(&challenge) != nil || challenge["action"] != "authentication_challenge" {
+			return
+		}
+		_ = conn.WriteJSON(map[string]any{"status": "OK", "seq_reply": 1})
+		for ev := range fs.events {
+			if conn.WriteMessage(websocket.TextMessage, []byte(ev)) != nil {
+				return
+			}
+		}
+	})
+	mux.HandleFunc("POST /api/v4/posts", func(w http.ResponseWriter, r *http.Request) {
+		fs.record(r)
+		_ = json.NewEncoder(w).Encode(mmPost{ID: "post1"})
+	})
+	mux.HandleFunc("PUT /api/v4/posts/{id}/patch", func(w http.ResponseWriter, r *http.Request) {
+		fs.record(r)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("DELETE /api/v4/posts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fs.record(r)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /api/v4/users/me/teams", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]mmTeam{{ID: "team1", Name: "eng"}})
+	})
+	mux.HandleFunc("GET /api/v4/commands", func(w http.ResponseWriter, r *http.Request) {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(fs.commands)
+	})
+	mux.HandleFunc("POST /api/v4/commands", func(w http.ResponseWriter, r *http.Request) {
+		var cmd mmCommand
+		_ = json.NewDecoder(r.Body).Decode(&cmd)
+		if cmd.Trigger == "help" {
+			http.Error(w, `{"message":"trigger already in use"}`, http.StatusBadRequest)
+			return
+		}
+		cmd.ID = "cmd-" + cmd.Trigger
+		cmd.Token = "token-" + cmd.Trigger
+		fs.mu.Lock()
+		fs.commands = append(fs.commands, cmd)
+		fs.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(cmd)
+	})
+
+	fs.Server = httptest.NewServer(mux)
+	t.Cleanup(func() {
+		close(fs.events)
+		fs.Close()
+	})
+	return fs
+}
+
+func (fs *fakeServer) record(r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	fs.mu.Lock()
+	fs.requests = append(fs.requests, r.Method+" "+r.URL.Path+" "+strings.TrimSpace(string(body)))
+	fs.mu.Unlock()
+}
+
+func (fs *fakeServer) recorded() []string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return append([]string(nil), fs.requests...)
+}
+
+func newTestChannel(t *testing.T, fs *fakeServer, settings config.MattermostSettings) (*MattermostChannel, *bus.MessageBus) {
+	t.Helper()
+	mb := bus.NewMessageBus()
+	t.Cleanup(mb.Close)
+
+	settings.URL = fs.URL
+	settings.Token = *config.NewSecureString("tok")
+	ch, err := NewMattermostChannel(&config.Channel{}, &settings, mb)
+	if err != nil {
+		t.Fatalf("NewMattermostChannel: %v", err)
+	}
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+	return ch, mb
+}
+
+func postedEvent(t *testing.T, channelType string, post mmPost, mentions ...string) string {
+	t.Helper()
+	postJSON, _ := json.Marshal(post)
+	mentionJSON, _ := json.Marshal(mentions)
+	data, _ := json.Marshal(map[string]any{
+		"event": "posted",
+		"data": mmPostedData{
+			ChannelType: channelType,
+			TeamID:      "team1",
+			Post:        string(postJSON),
+			Mentions:    string(mentionJSON),
+		},
+	})
+	return string(data)
+}
+
+func TestNewMattermostChannel_Validation(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	cases := map[string]config.MattermostSettings{
+		"missing url":   {Token: *config.NewSecureString("tok")},
+		"missing token": {URL: "https://chat.example.com"},
+		"bad scheme":    {URL: "chat.example.com", Token: *config.NewSecureString("tok")},
+	}
+	for name, cfg := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewMattermostChannel(&config.Channel{}, &cfg, mb); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestParseChatIDAndOutboundTarget(t *testing.T) {
+	if ch, root := parseChatID("chan1/root1"); ch != "chan1" || root != "root1" {
+		t.Fatalf("parseChatID = %q, %q", ch, root)
+	}
+	if ch, root := parseChatID("chan1"); ch != "chan1" || root != "" {
+		t.Fatalf("parseChatID = %q, %q", ch, root)
+	}
+
+	ch, root := resolveOutboundTarget("chan1", &bus.InboundContext{TopicID: "root9"})
+	if ch != "chan1" || root != "root9" {
+		t.Fatalf("resolveOutboundTarget = %q, %q, want chan1, root9", ch, root)
+	}
+}
+
+func TestWebSocketPostedEventMapsThreadToTopic(t *testing.T) {
+	fs := newFakeServer(t)
+	_, mb := newTestChannel(t, fs, config.MattermostSettings{})
+
+	// Own posts and system messages are ignored.
+	fs.events <- postedEvent(t, "O", mmPost{ID: "p0", ChannelID: "town", UserID: "bot1", Message: "echo"})
+	fs.events <- postedEvent(t, "O", mmPost{ID: "p1", ChannelID: "town", UserID: "u1", Type: "system_join_channel"})
+	fs.events <- postedEvent(t, "O", mmPost{
+		ID:        "p2",
+		ChannelID: "town",
+		UserID:    "u1",
+		RootID:    "root1",
+		Message:   "@picobot what's up?",
+	}, "bot1")
+
+	select {
+	case msg := <-mb.InboundChan():
+		if msg.ChatID != "town" || msg.Context.TopicID != "root1" {
+			t.Fatalf("chat = %q topic = %q, want town and root1", msg.ChatID, msg.Context.TopicID)
+		}
+		if msg.Content != "what's up?" || !msg.Context.Mentioned {
+			t.Fatalf("content = %q mentioned = %v", msg.Content, msg.Context.Mentioned)
+		}
+		if msg.Context.MessageID != "p2" || msg.Context.ChatType != "channel" {
+			t.Fatalf("context = %+v", msg.Context)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no inbound message published")
+	}
+}
+
+func TestSendRepliesInThread(t *testing.T) {
+	fs := newFakeServer(t)
+	ch, _ := newTestChannel(t, fs, config.MattermostSettings{})
+
+	ids, err := ch.Send(context.Background(), bus.OutboundMessage{
+		ChatID:  "town",
+		Context: bus.InboundContext{ChatID: "town", TopicID: "root1"},
+		Content: "hello",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "post1" {
+		t.Fatalf("ids = %v", ids)
+	}
+
+	reqs := fs.recorded()
+	if len(reqs) != 1 || !strings.Contains(reqs[0], `"root_id":"root1"`) ||
+		!strings.Contains(reqs[0], `"message":"hello"`) {
+		t.Fatalf("requests = %v", reqs)
+	}
+}
+
+func TestStreamerPostsThenPatches(t *testing.T) {
+	fs := newFakeServer(t)
+	ch, _ := newTestChannel(t, fs, config.MattermostSettings{Streaming: true})
+
+	s, err := ch.BeginStream(context.Background(), "town/root1")
+	if err != nil {
+		t.Fatalf("BeginStream: %v", err)
+	}
+	ctx := context.Background()
+	_ = s.Update(ctx, "par")
+	_ = s.Update(ctx, "parti") // throttled
+	if err := s.Finalize(ctx, "partial done"); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+
+	reqs := fs.recorded()
+	if len(reqs) != 2 {
+		t.Fatalf("requests = %v, want create + patch", reqs)
+	}
+	if !strings.HasPrefix(reqs[0], "POST /api/v4/posts ") ||
+		reqs[1] != `PUT /api/v4/posts/post1/patch {"message":"partial done"}` {
+		t.Fatalf("requests = %v", reqs)
+	}
+}
+
+func TestBeginStreamDisabled(t *testing.T) {
+	fs := newFakeServer(t)
+	ch, _ := newTestChannel(t, fs, config.MattermostSettings{})
+	if _, err := ch.BeginStream(context.Background(), "town"); err == nil {
+		t.Fatal("expected error when streaming is disabled")
+	}
+}
+
+func TestRegisterCommandsAndCallback(t *testing.T) {
+	fs := newFakeServer(t)
+	ch, mb := newTestChannel(t, fs, config.MattermostSettings{})
+	ch.config.CommandURL = "https://bot.example.com/"
+
+	defs := []commands.Definition{
+		{Name: "help", Description: "Show help"},
+		{Name: "clear", Description: "Clear history"},
+		{Name: "hidden"},
+	}
+	if err := ch.RegisterCommands(context.Background(), defs); err != nil {
+		t.Fatalf("RegisterCommands: %v", err)
+	}
+	if len(fs.commands) != 1 || fs.commands[0].URL != "https://bot.example.com/webhook/mattermost" {
+		t.Fatalf("registered commands = %+v", fs.commands)
+	}
+	// A second run reuses the existing command instead of creating a duplicate.
+	if err := ch.RegisterCommands(context.Background(), defs); err != nil || len(fs.commands) != 1 {
+		t.Fatalf("re-register: err = %v, commands = %d", err, len(fs.commands))
+	}
+
+	form := url.Values{
+		"token":      {"wrong"},
+		"user_id":    {"u1"},
+		"channel_id": {"town"},
+		"command":    {"/clear"},
+	}
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, formRequest(form))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bad token status = %d", rec.Code)
+	}
+
+	form.Set("token", "token-clear")
+	form.Set("text", "all")
+	rec = httptest.NewRecorder()
+	ch.ServeHTTP(rec, formRequest(form))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	select {
+	case msg := <-mb.InboundChan():
+		if msg.Content != "/clear all" || msg.ChatID != "town" {
+			t.Fatalf("inbound = %q in %q", msg.Content, msg.ChatID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no inbound message published")
+	}
+}
+
+func formRequest(form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/webhook/mattermost", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
@@ -0,0 +1,156 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	wsPingInterval = 30 * time.Second
+	wsReadTimeout  = 90 * time.Second
+	wsMaxBackoff   = time.Minute
+)
+
+type mmEvent struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// mmPostedData is the payload of a "posted" event. The post and the list of
+// mentioned user IDs are themselves JSON-encoded strings.
+type mmPostedData struct {
+	ChannelType string `json:"channel_type"`
+	ChannelName string `json:"channel_name"`
+	SenderName  string `json:"sender_name"`
+	TeamID      string `json:"team_id"`
+	Post        string `json:"post"`
+	Mentions    string `json:"mentions"`
+}
+
+// websocketURL converts the server URL to its ws(s):// event endpoint.
+func (c *MattermostChannel) websocketURL() string {
+	if rest, ok := strings.CutPrefix(c.baseURL, "https://"); ok {
+		return "wss://" + rest + "/api/v4/websocket"
+	}
+	return "ws://" + strings.TrimPrefix(c.baseURL, "http://") + "/api/v4/websocket"
+}
+
+// runWebSocket keeps the event stream connected until the channel stops,
+// reconnecting with exponential backoff.
+func (c *MattermostChannel) runWebSocket() {
+	backoff := time.Second
+	for c.ctx.Err() == nil {
+		started := time.Now()
+		err := c.listen()
+		if c.ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > wsMaxBackoff {
+			backoff = time.Second
+		}
+		logger.WarnCF("mattermost", "WebSocket disconnected; reconnecting", map[string]any{
+			"error":       fmt.Sprint(err),
+			"retry_after": backoff.String(),
+		})
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, wsMaxBackoff)
+	}
+}
+
+func (c *MattermostChannel) listen() error {
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second, Proxy: http.ProxyFromEnvironment}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+c.config.Token.String())
+
+	conn, resp, err := dialer.DialContext(c.ctx, c.websocketURL(), header)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	c.connMu.Lock()
+	c.conn = conn
+	c.connMu.Unlock()
+	defer func() {
+		c.connMu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+		}
+		c.connMu.Unlock()
+	}()
+
+	if err := conn.WriteJSON(map[string]any{
+		"seq":    1,
+		"action": "authentication_challenge",
+		"data":   map[string]string{"token": c.config.Token.String()},
+	}); err != nil {
+		return err
+	}
+
+	extend := func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+	}
+	_ = extend("")
+	conn.SetPongHandler(extend)
+	conn.SetPingHandler(func(data string) error {
+		_ = extend(data)
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(5*time.Second))
+	})
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(wsPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+				_ = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second))
+			}
+		}
+	}()
+
+	logger.InfoC("mattermost", "WebSocket connected")
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		_ = extend("")
+
+		var ev mmEvent
+		if err := json.Unmarshal(data, &ev); err != nil || ev.Event == "" {
+			// Sequence replies (e.g. to the auth challenge) carry no event.
+			continue
+		}
+		if ev.Event != "posted" {
+			continue
+		}
+
+		var posted mmPostedData
+		if err := json.Unmarshal(ev.Data, &posted); err != nil {
+			continue
+		}
+		c.handlePosted(posted)
+	}
+}
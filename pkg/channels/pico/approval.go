@@ -0,0 +1,133 @@
+package pico
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// pendingApproval is a tool.approval.request waiting for its response.
+type pendingApproval struct {
+	sessionID string
+	decision  chan channels.ToolApprovalDecision
+}
+
+// requiresApproval reports whether tool matches the configured tool_approval list.
+func (c *PicoChannel) requiresApproval(tool string) bool {
+	for _, pattern := range c.config.ToolApproval {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == tool {
+			return true
+		}
+		if ok, err := path.Match(pattern, tool); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasV2Connection reports whether any client in the session can answer
+// protocol v2 requests.
+func (c *PicoChannel) hasV2Connection(sessionID string) bool {
+	for _, pc := range c.sessionConnectionsSnapshot(sessionID) {
+		if pc.protocol >= ProtocolV2 {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestToolApproval implements channels.ToolApprovalCapable. It sends a
+// tool.approval.request to the session and blocks until a client answers
+// with tool.approval.response or ctx ends. Calls are denied when no v2 client
+// is connected, since nobody could answer.
+func (c *PicoChannel) RequestToolApproval(
+	ctx context.Context,
+	req channels.ToolApprovalRequest,
+) (channels.ToolApprovalDecision, error) {
+	if !c.requiresApproval(req.Tool) {
+		return channels.ToolApprovalDecision{Approved: true}, nil
+	}
+
+	sessionID := strings.TrimPrefix(req.ChatID, "pico:")
+	if !c.hasV2Connection(sessionID) {
+		return channels.ToolApprovalDecision{
+			Approved: false,
+			Reason:   "no connected client can approve this tool call",
+		}, nil
+	}
+
+	approvalID := uuid.New().String()
+	pending := &pendingApproval{
+		sessionID: sessionID,
+		decision:  make(chan channels.ToolApprovalDecision, 1),
+	}
+	c.approvalsMu.Lock()
+	if c.approvals == nil {
+		c.approvals = make(map[string]*pendingApproval)
+	}
+	c.approvals[approvalID] = pending
+	c.approvalsMu.Unlock()
+	defer func() {
+		c.approvalsMu.Lock()
+		delete(c.approvals, approvalID)
+		c.approvalsMu.Unlock()
+	}()
+
+	payload := map[string]any{
+		"approval_id": approvalID,
+		"tool":        req.Tool,
+		"arguments":   req.Arguments,
+	}
+	if req.AgentID != "" {
+		payload["agent_id"] = req.AgentID
+	}
+	if err := c.broadcastToSession(req.ChatID, newMessage(TypeToolApprovalRequest, payload)); err != nil {
+		return channels.ToolApprovalDecision{}, err
+	}
+
+	select {
+	case decision := <-pending.decision:
+		return decision, nil
+	case <-ctx.Done():
+		return channels.ToolApprovalDecision{
+			Approved: false,
+			Reason:   "tool approval timed out",
+		}, nil
+	}
+}
+
+// handleToolApprovalResponse resolves a pending approval. Only clients in the
+// session that received the request may answer it.
+func (c *PicoChannel) handleToolApprovalResponse(pc *picoConn, msg PicoMessage) {
+	approvalID, _ := msg.Payload["approval_id"].(string)
+	approved, _ := msg.Payload["approved"].(bool)
+	reason, _ := msg.Payload["reason"].(string)
+
+	c.approvalsMu.Lock()
+	pending, ok := c.approvals[approvalID]
+	if ok && pending.sessionID == pc.sessionID {
+		delete(c.approvals, approvalID)
+	}
+	c.approvalsMu.Unlock()
+
+	if !ok || pending.sessionID != pc.sessionID {
+		pc.writeJSON(newErrorWithPayload("unknown_approval", "no pending approval with this id", map[string]any{
+			"request_id":  msg.ID,
+			"approval_id": approvalID,
+		}))
+		return
+	}
+
+	logger.InfoCF("pico", "Tool approval answered", map[string]any{
+		"session_id":  pc.sessionID,
+		"approval_id": approvalID,
+		"approved":    approved,
+	})
+	pending.decision <- channels.ToolApprovalDecision{Approved: approved, Reason: strings.TrimSpace(reason)}
+}
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,11 +25,13 @@ import (
 // PicoClientChannel connects to a remote Pico Protocol WebSocket server.
 type PicoClientChannel struct {
 	*channels.BaseChannel
-	config *config.PicoClientSettings
-	conn   *picoConn
-	mu     sync.Mutex
-	ctx    context.Context
-	cancel context.CancelFunc
+	config    *config.PicoClientSettings
+	conn      *picoConn
+	sessionID string
+	lastSeq   atomic.Uint64 // last frame seq seen, sent as last_seq on reconnect
+	mu        sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
 }
 
 // NewPicoClientChannel creates a new Pico Protocol client channel.
@@ -41,9 +46,15 @@ func NewPicoClientChannel(
 
 	base := channels.NewBaseChannel("pico_client", cfg, messageBus, bc.AllowFrom)
 
+	sessionID := cfg.SessionID
+	if sessionID == "" {
+		sessionID = uuid.New().String()
+	}
+
 	return &PicoClientChannel{
 		BaseChannel: base,
 		config:      cfg,
+		sessionID:   sessionID,
 	}, nil
 }
 
@@ -86,7 +97,12 @@ func (c *PicoClientChannel) dial() error {
 		header.Set("Authorization", "Bearer "+c.config.Token.String())
 	}
 
-	ws, resp, err := websocket.DefaultDialer.DialContext(c.ctx, c.config.URL, header)
+	dialURL, err := c.dialURL()
+	if err != nil {
+		return err
+	}
+
+	ws, resp, err := websocket.DefaultDialer.DialContext(c.ctx, dialURL, header)
 	if resp != nil && resp.Body != nil {
 		resp.Body.Close()
 	}
@@ -99,12 +115,10 @@ func (c *PicoClientChannel) dial() error {
 	pc := &picoConn{
 		id:        uuid.New().String(),
 		conn:      ws,
-		sessionID: c.config.SessionID,
+		sessionID: c.sessionID,
+		protocol:  ProtocolV2,
 		cancel:    connCancel,
 	}
-	if pc.sessionID == "" {
-		pc.sessionID = uuid.New().String()
-	}
 
 	c.mu.Lock()
 	c.conn = pc
@@ -114,6 +128,25 @@ func (c *PicoClientChannel) dial() error {
 	return nil
 }
 
+// dialURL adds the protocol version, session and resume cursor to the
+// configured URL. Servers that only speak v1 ignore the extra parameters.
+func (c *PicoClientChannel) dialURL() (string, error) {
+	u, err := url.Parse(c.config.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid pico_client url: %w", err)
+	}
+	q := u.Query()
+	q.Set("protocol", strconv.Itoa(ProtocolV2))
+	if q.Get("session_id") == "" {
+		q.Set("session_id", c.sessionID)
+	}
+	if seq := c.lastSeq.Load(); seq > 0 {
+		q.Set("last_seq", strconv.FormatUint(seq, 10))
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
 // reconnectLoop re-dials when the connection drops.
 func (c *PicoClientChannel) reconnectLoop() {
 	for {
@@ -229,9 +262,22 @@ func (c *PicoClientChannel) pingLoop(connCtx context.Context, pc *picoConn, inte
 // sends message.send (user input). We treat message.create from the server
 // as inbound user messages to feed into the agent loop.
 func (c *PicoClientChannel) handleInbound(pc *picoConn, msg PicoMessage) {
+	if msg.Seq > 0 {
+		// Frames replayed after a reconnect may overlap what we already saw.
+		if msg.Seq <= c.lastSeq.Load() {
+			return
+		}
+		c.lastSeq.Store(msg.Seq)
+	}
+
 	switch msg.Type {
 	case TypePong:
 		// response to our ping, ignore
+	case TypeSessionReady:
+		// A server that restarted numbers frames from scratch; follow it.
+		if seq, ok := payloadSeq(msg.Payload, "last_seq"); ok && seq < c.lastSeq.Load() {
+			c.lastSeq.Store(0)
+		}
 	case TypeMessageCreate:
 		// Server sent us a message — treat as inbound
 		c.handleServerMessage(pc, msg)
@@ -1,6 +1,8 @@
 package pico
 
 import (
+	"github.com/sipeed/picoclaw/pkg/audio/asr"
+	"github.com/sipeed/picoclaw/pkg/audio/tts"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -23,6 +25,10 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
+			if c.Voice {
+				ch.asrAvailable = asr.DetectTranscriber(cfg) != nil
+				ch.tts = tts.DetectTTS(cfg)
+			}
 			if channelName != config.ChannelPico {
 				ch.SetName(channelName)
 			}
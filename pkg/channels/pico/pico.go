@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -18,6 +19,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 
+	"github.com/sipeed/picoclaw/pkg/audio/tts"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -31,9 +33,11 @@ type picoConn struct {
 	id        string
 	conn      *websocket.Conn
 	sessionID string
+	protocol  int // negotiated protocol version
 	writeMu   sync.Mutex
 	closed    atomic.Bool
-	cancel    context.CancelFunc // cancels per-connection goroutines (e.g. pingLoop)
+	cancel    context.CancelFunc          // cancels per-connection goroutines (e.g. pingLoop)
+	voice     atomic.Pointer[voiceStream] // set while the client is in voice mode
 }
 
 var allowedInlineImageMIMETypes = map[string]struct{}{
@@ -105,6 +109,17 @@ type PicoChannel struct {
 	cancel             context.CancelFunc
 	progress           *channels.ToolFeedbackAnimator
 	deleteMessageFn    func(context.Context, string, string) error
+	streams            map[string]*sessionStream // sessionID -> v2 resume stream
+	streamsMu          sync.Mutex
+	approvals          map[string]*pendingApproval // approvalID -> pending tool approval
+	approvalsMu        sync.Mutex
+	bus                *bus.MessageBus
+	tts                tts.TTSProvider
+	asrAvailable       bool                      // a transcriber is configured for voice mode
+	playback           map[string]*voicePlayback // sessionID -> reply audio since the user last spoke
+	playbackSeq        uint64
+	speakFn            func(context.Context, string, string)
+	voiceMu            sync.Mutex
 }
 
 // NewPicoChannel creates a new Pico Protocol channel.
@@ -144,6 +159,10 @@ func NewPicoChannel(
 		},
 		connections:        make(map[string]*picoConn),
 		sessionConnections: make(map[string]map[string]*picoConn),
+		streams:            make(map[string]*sessionStream),
+		approvals:          make(map[string]*pendingApproval),
+		bus:                messageBus,
+		playback:           make(map[string]*voicePlayback),
 	}
 	ch.progress = channels.NewToolFeedbackAnimator(ch.EditMessage)
 	ch.deleteMessageFn = ch.DeleteMessage
@@ -151,7 +170,12 @@ func NewPicoChannel(
 }
 
 // createAndAddConnection checks MaxConnections and registers a connection atomically.
-func (c *PicoChannel) createAndAddConnection(conn *websocket.Conn, sessionID string, maxConns int) (*picoConn, error) {
+func (c *PicoChannel) createAndAddConnection(
+	conn *websocket.Conn,
+	sessionID string,
+	protocol int,
+	maxConns int,
+) (*picoConn, error) {
 	c.connsMu.Lock()
 	defer c.connsMu.Unlock()
 	if len(c.connections) >= maxConns {
@@ -170,6 +194,7 @@ func (c *PicoChannel) createAndAddConnection(conn *websocket.Conn, sessionID str
 		id:        connID,
 		conn:      conn,
 		sessionID: sessionID,
+		protocol:  protocol,
 	}
 
 	c.connections[pc.id] = pc
@@ -204,6 +229,26 @@ func (c *PicoChannel) removeConnection(connID string) *picoConn {
 	return pc
 }
 
+// moveConnection re-indexes a connection under another session.
+func (c *PicoChannel) moveConnection(pc *picoConn, sessionID string) {
+	c.connsMu.Lock()
+	defer c.connsMu.Unlock()
+
+	if bySession, ok := c.sessionConnections[pc.sessionID]; ok {
+		delete(bySession, pc.id)
+		if len(bySession) == 0 {
+			delete(c.sessionConnections, pc.sessionID)
+		}
+	}
+	pc.sessionID = sessionID
+	bySession, ok := c.sessionConnections[sessionID]
+	if !ok {
+		bySession = make(map[string]*picoConn)
+		c.sessionConnections[sessionID] = bySession
+	}
+	bySession[pc.id] = pc
+}
+
 // takeAllConnections snapshots and clears all connection indexes.
 func (c *PicoChannel) takeAllConnections() []*picoConn {
 	c.connsMu.Lock()
@@ -310,6 +355,7 @@ func (c *PicoChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]stri
 	}
 	trackedMsgID, hasTrackedMsg := c.currentToolFeedbackMessage(msg.ChatID)
 	if outboundMessageFinalizesTrackedToolFeedback(msg) {
+		c.speakReply(msg.ChatID, msg.Content)
 		if msgIDs, handled := c.FinalizeToolFeedbackMessage(ctx, msg); handled {
 			return msgIDs, nil
 		}
@@ -346,6 +392,7 @@ func (c *PicoChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]stri
 	if err := c.broadcastToSession(msg.ChatID, outMsg); err != nil {
 		return nil, err
 	}
+	c.sendUsageUpdate(msg.ChatID, msg.ContextUsage)
 	if isToolFeedback {
 		c.RecordToolFeedbackMessage(msg.ChatID, msgID, msg.Content)
 	} else if hasTrackedMsg && outboundMessageFinalizesTrackedToolFeedback(msg) {
@@ -680,13 +727,56 @@ func (c *PicoChannel) handleMediaDownload(w http.ResponseWriter, r *http.Request
 }
 
 // broadcastToSession sends a message to all connections with a matching session.
+//
+// In sessions a v2 client has joined, resumable frames are numbered and kept
+// for replay, so they count as delivered even when no client is connected.
 func (c *PicoChannel) broadcastToSession(chatID string, msg PicoMessage) error {
 	// chatID format: "pico:<sessionID>"
 	sessionID := strings.TrimPrefix(chatID, "pico:")
 	msg.SessionID = sessionID
 
+	stream := c.stream(sessionID)
+	if stream == nil || !isResumableType(msg.Type) {
+		return c.writeToSession(sessionID, msg)
+	}
+
+	stream.mu.Lock()
+	msg = stream.append(msg, c.resumeBufferSize())
+	conns := c.sessionConnectionsSnapshot(sessionID)
+	ticket := stream.ticket()
+	stream.mu.Unlock()
+
+	stream.deliver(ticket, func() {
+		if err := c.writeToConns(sessionID, conns, msg); err != nil {
+			logger.DebugCF("pico", "Frame buffered for resume", map[string]any{
+				"session_id": sessionID,
+				"seq":        msg.Seq,
+			})
+		}
+	})
+	return nil
+}
+
+// sendUsageUpdate sends a usage.update frame to v2 sessions after a reply
+// that carries context usage.
+func (c *PicoChannel) sendUsageUpdate(chatID string, u *bus.ContextUsage) {
+	if u == nil || c.stream(strings.TrimPrefix(chatID, "pico:")) == nil {
+		return
+	}
+	payload := map[string]any{}
+	setContextUsagePayload(payload, u)
+	_ = c.broadcastToSession(chatID, newMessage(TypeUsageUpdate, payload))
+}
+
+// writeToSession writes msg to every connection of the session.
+func (c *PicoChannel) writeToSession(sessionID string, msg PicoMessage) error {
+	return c.writeToConns(sessionID, c.sessionConnectionsSnapshot(sessionID), msg)
+}
+
+// writeToConns writes msg to conns, the connections of sessionID.
+func (c *PicoChannel) writeToConns(sessionID string, conns []*picoConn, msg PicoMessage) error {
 	var sent bool
-	for _, pc := range c.sessionConnectionsSnapshot(sessionID) {
+	for _, pc := range conns {
 		if err := pc.writeJSON(msg); err != nil {
 			logger.DebugCF("pico", "Write to connection failed", map[string]any{
 				"conn_id": pc.id,
@@ -741,12 +831,23 @@ func (c *PicoChannel) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Determine session ID from query param or generate one
-	sessionID := r.URL.Query().Get("session_id")
+	query := r.URL.Query()
+	sessionID := query.Get("session_id")
 	if sessionID == "" {
 		sessionID = uuid.New().String()
 	}
+	protocol := negotiateProtocol(query.Get("protocol"))
 
-	pc, err := c.createAndAddConnection(conn, sessionID, maxConns)
+	var pc *picoConn
+	if protocol >= ProtocolV2 {
+		lastSeq, resumeErr := strconv.ParseUint(query.Get("last_seq"), 10, 64)
+		stream := c.ensureStream(sessionID)
+		pc, err = c.join(stream, lastSeq, resumeErr == nil, func() (*picoConn, error) {
+			return c.createAndAddConnection(conn, sessionID, protocol, maxConns)
+		})
+	} else {
+		pc, err = c.createAndAddConnection(conn, sessionID, protocol, maxConns)
+	}
 	if err != nil {
 		_ = conn.WriteControl(
 			websocket.CloseMessage,
@@ -760,6 +861,7 @@ func (c *PicoChannel) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	logger.InfoCF("pico", "WebSocket client connected", map[string]any{
 		"conn_id":    pc.id,
 		"session_id": sessionID,
+		"protocol":   protocol,
 	})
 
 	go c.readLoop(pc)
@@ -815,6 +917,7 @@ func (c *PicoChannel) readLoop(pc *picoConn) {
 	defer func() {
 		pc.close()
 		if removed := c.removeConnection(pc.id); removed != nil {
+			c.touchStream(removed.sessionID)
 			logger.InfoCF("pico", "WebSocket client disconnected", map[string]any{
 				"conn_id":    removed.id,
 				"session_id": removed.sessionID,
@@ -847,7 +950,7 @@ func (c *PicoChannel) readLoop(pc *picoConn) {
 		default:
 		}
 
-		_, rawMsg, err := pc.conn.ReadMessage()
+		msgType, rawMsg, err := pc.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
 				logger.DebugCF("pico", "WebSocket read error", map[string]any{
@@ -860,6 +963,11 @@ func (c *PicoChannel) readLoop(pc *picoConn) {
 
 		_ = pc.conn.SetReadDeadline(time.Now().Add(readTimeout))
 
+		if msgType == websocket.BinaryMessage {
+			c.handleVoiceFrame(pc, rawMsg)
+			continue
+		}
+
 		var msg PicoMessage
 		if err := json.Unmarshal(rawMsg, &msg); err != nil {
 			errMsg := newError("invalid_message", "failed to parse message")
@@ -908,6 +1016,16 @@ func (c *PicoChannel) handleMessage(pc *picoConn, msg PicoMessage) {
 	case TypeMediaSend:
 		c.handleMessageSend(pc, msg)
 
+	case TypeTurnCancel, TypeSessionList, TypeSessionSwitch, TypeSessionNew, TypeToolApprovalResponse,
+		TypeVoiceStart, TypeVoiceStop:
+		if pc.protocol < ProtocolV2 {
+			pc.writeJSON(newErrorWithPayload("unsupported_type",
+				fmt.Sprintf("%s requires protocol %d", msg.Type, ProtocolV2),
+				map[string]any{"request_id": msg.ID}))
+			return
+		}
+		c.handleV2Message(pc, msg)
+
 	default:
 		errMsg := newError("unknown_type", fmt.Sprintf("unknown message type: %s", msg.Type))
 		pc.writeJSON(errMsg)
@@ -939,27 +1057,26 @@ func (c *PicoChannel) handleMessageSend(pc *picoConn, msg PicoMessage) {
 		sessionID = pc.sessionID
 	}
 
-	chatID := "pico:" + sessionID
-	senderID := "pico-user"
-
-	metadata := map[string]string{
-		"platform":   "pico",
-		"session_id": sessionID,
-		"conn_id":    pc.id,
-	}
-
 	logger.DebugCF("pico", "Received message", map[string]any{
 		"session_id": sessionID,
 		"preview":    truncate(content, 50),
 		"media":      len(media),
 	})
 
-	sender := bus.SenderInfo{
-		Platform:    "pico",
-		PlatformID:  senderID,
-		CanonicalID: identity.BuildCanonicalID("pico", senderID),
+	c.dispatchInbound(pc, sessionID, msg.ID, content, media)
+}
+
+// dispatchInbound hands client input for sessionID to the agent.
+func (c *PicoChannel) dispatchInbound(pc *picoConn, sessionID, messageID, content string, media []string) {
+	chatID := "pico:" + sessionID
+
+	metadata := map[string]string{
+		"platform":   "pico",
+		"session_id": sessionID,
+		"conn_id":    pc.id,
 	}
 
+	sender := picoSender()
 	if !c.IsAllowedSender(sender) {
 		return
 	}
@@ -968,14 +1085,25 @@ func (c *PicoChannel) handleMessageSend(pc *picoConn, msg PicoMessage) {
 		Channel:   "pico",
 		ChatID:    chatID,
 		ChatType:  "direct",
-		SenderID:  senderID,
-		MessageID: msg.ID,
+		SenderID:  picoSenderID,
+		MessageID: messageID,
 		Raw:       metadata,
 	}
 
 	c.HandleInboundContext(c.ctx, chatID, content, media, inboundCtx, sender)
 }
 
+// picoSenderID is the sender of everything typed or spoken into the channel.
+const picoSenderID = "pico-user"
+
+func picoSender() bus.SenderInfo {
+	return bus.SenderInfo{
+		Platform:    "pico",
+		PlatformID:  picoSenderID,
+		CanonicalID: identity.BuildCanonicalID("pico", picoSenderID),
+	}
+}
+
 // truncate truncates a string to maxLen runes.
 func truncate(s string, maxLen int) string {
 	runes := []rune(s)
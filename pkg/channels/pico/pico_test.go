@@ -253,7 +253,7 @@ func TestCreateAndAddConnection_RespectsMaxConnectionsConcurrently(t *testing.T)
 		go func() {
 			defer wg.Done()
 
-			pc, err := ch.createAndAddConnection(nil, sessionID, maxConns)
+			pc, err := ch.createAndAddConnection(nil, sessionID, ProtocolV1, maxConns)
 			mu.Lock()
 			defer mu.Unlock()
 
@@ -287,7 +287,7 @@ func TestCreateAndAddConnection_RespectsMaxConnectionsConcurrently(t *testing.T)
 func TestRemoveConnection_CleansBothIndexes(t *testing.T) {
 	ch := newTestPicoChannel(t)
 
-	pc, err := ch.createAndAddConnection(nil, "session-cleanup", 10)
+	pc, err := ch.createAndAddConnection(nil, "session-cleanup", ProtocolV1, 10)
 	if err != nil {
 		t.Fatalf("createAndAddConnection: %v", err)
 	}
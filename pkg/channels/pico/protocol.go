@@ -1,10 +1,21 @@
 package pico
 
 import (
+	"strconv"
 	"strings"
 	"time"
 )
 
+// Protocol versions. Clients opt in to v2 with the "protocol" query parameter
+// on the WebSocket URL; everything else is served as v1.
+const (
+	ProtocolV1 = 1
+	ProtocolV2 = 2
+
+	// ProtocolLatest is the highest version this server speaks.
+	ProtocolLatest = ProtocolV2
+)
+
 // Protocol message types.
 const (
 	// TypeMessageSend is sent from client to server.
@@ -12,6 +23,15 @@ const (
 	TypeMediaSend   = "media.send"
 	TypePing        = "ping"
 
+	// Protocol v2 client-to-server types.
+	TypeTurnCancel           = "turn.cancel"
+	TypeSessionList          = "session.list"
+	TypeSessionSwitch        = "session.switch"
+	TypeSessionNew           = "session.new"
+	TypeToolApprovalResponse = "tool.approval.response"
+	TypeVoiceStart           = "voice.start"
+	TypeVoiceStop            = "voice.stop"
+
 	// TypeMessageCreate is sent from server to client.
 	TypeMessageCreate = "message.create"
 	TypeMessageUpdate = "message.update"
@@ -22,6 +42,15 @@ const (
 	TypeError         = "error"
 	TypePong          = "pong"
 
+	// Protocol v2 server-to-client types.
+	TypeSessionReady        = "session.ready"
+	TypeSessionListed       = "session.listed"
+	TypeToolApprovalRequest = "tool.approval.request"
+	TypeUsageUpdate         = "usage.update"
+	TypeVoiceState          = "voice.state"
+	TypeVoiceAudio          = "voice.audio"
+	TypeVoiceInterrupt      = "voice.interrupt"
+
 	PayloadKeyContent   = "content"
 	PayloadKeyThought   = "thought"
 	PayloadKeyKind      = "kind"
@@ -31,15 +60,53 @@ const (
 	MessageKindToolCalls = "tool_calls"
 )
 
+// protocolCapabilities lists the v2 features advertised in session.ready.
+var protocolCapabilities = []string{
+	TypeTurnCancel,
+	TypeSessionList,
+	TypeSessionSwitch,
+	TypeSessionNew,
+	"tool.approval",
+	"usage",
+	"resume",
+}
+
 // PicoMessage is the wire format for all Pico Protocol messages.
+//
+// Seq is set on server-to-client frames of v2 sessions. It increases by one
+// per frame within a session, so a reconnecting client can pass the last seq
+// it saw and receive only the frames it missed.
 type PicoMessage struct {
 	Type      string         `json:"type"`
 	ID        string         `json:"id,omitempty"`
 	SessionID string         `json:"session_id,omitempty"`
+	Seq       uint64         `json:"seq,omitempty"`
 	Timestamp int64          `json:"timestamp,omitempty"`
 	Payload   map[string]any `json:"payload,omitempty"`
 }
 
+// negotiateProtocol picks the protocol version for a connection from the
+// version the client asked for. Unknown or missing values fall back to v1 and
+// newer versions are capped at ProtocolLatest.
+func negotiateProtocol(requested string) int {
+	v, err := strconv.Atoi(strings.TrimSpace(requested))
+	if err != nil || v < ProtocolV1 {
+		return ProtocolV1
+	}
+	return min(v, ProtocolLatest)
+}
+
+// isResumableType reports whether frames of this type are buffered for replay.
+// Typing indicators and request/response frames are ephemeral.
+func isResumableType(msgType string) bool {
+	switch msgType {
+	case TypeMessageCreate, TypeMessageUpdate, TypeMessageDelete, TypeMediaCreate,
+		TypeToolApprovalRequest, TypeUsageUpdate:
+		return true
+	}
+	return false
+}
+
 // newMessage creates a PicoMessage with the given type and payload.
 func newMessage(msgType string, payload map[string]any) PicoMessage {
 	return PicoMessage{
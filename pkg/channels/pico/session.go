@@ -0,0 +1,97 @@
+package pico
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// stopCommand is what turn.cancel is translated to. The agent handles /stop
+// immediately, even while a turn for the session is still running.
+const stopCommand = "/stop"
+
+// handleV2Message processes the client-to-server frames added in protocol v2.
+func (c *PicoChannel) handleV2Message(pc *picoConn, msg PicoMessage) {
+	switch msg.Type {
+	case TypeTurnCancel:
+		sessionID := msg.SessionID
+		if sessionID == "" {
+			sessionID = pc.sessionID
+		}
+		logger.DebugCF("pico", "Turn cancel requested", map[string]any{
+			"session_id": sessionID,
+			"conn_id":    pc.id,
+		})
+		c.dispatchInbound(pc, sessionID, msg.ID, stopCommand, nil)
+
+	case TypeSessionList:
+		reply := newMessage(TypeSessionListed, map[string]any{
+			"sessions": c.sessionSummaries(),
+		})
+		reply.ID = msg.ID
+		reply.SessionID = pc.sessionID
+		pc.writeJSON(reply)
+
+	case TypeSessionSwitch:
+		sessionID, _ := msg.Payload["session_id"].(string)
+		sessionID = strings.TrimSpace(sessionID)
+		if sessionID == "" {
+			pc.writeJSON(newErrorWithPayload("invalid_session", "session_id is required", map[string]any{
+				"request_id": msg.ID,
+			}))
+			return
+		}
+		lastSeq, resume := payloadSeq(msg.Payload, "last_seq")
+		c.switchSession(pc, sessionID, lastSeq, resume)
+
+	case TypeSessionNew:
+		c.switchSession(pc, uuid.New().String(), 0, false)
+
+	case TypeToolApprovalResponse:
+		c.handleToolApprovalResponse(pc, msg)
+
+	case TypeVoiceStart:
+		c.handleVoiceStart(pc, msg)
+
+	case TypeVoiceStop:
+		c.handleVoiceStop(pc, msg)
+	}
+}
+
+// switchSession moves pc to sessionID and answers with session.ready, plus
+// the frames after lastSeq when resuming.
+func (c *PicoChannel) switchSession(pc *picoConn, sessionID string, lastSeq uint64, resume bool) {
+	previous := pc.sessionID
+	stream := c.ensureStream(sessionID)
+
+	_, _ = c.join(stream, lastSeq, resume, func() (*picoConn, error) {
+		c.moveConnection(pc, sessionID)
+		return pc, nil
+	})
+
+	c.touchStream(previous)
+	logger.InfoCF("pico", "WebSocket client switched session", map[string]any{
+		"conn_id":      pc.id,
+		"from_session": previous,
+		"session_id":   sessionID,
+	})
+}
+
+// payloadSeq reads a non-negative sequence number from a JSON payload.
+func payloadSeq(payload map[string]any, key string) (uint64, bool) {
+	switch v := payload[key].(type) {
+	case float64:
+		if v >= 0 {
+			return uint64(v), true
+		}
+	case int:
+		if v >= 0 {
+			return uint64(v), true
+		}
+	case uint64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,296 @@
+package pico
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func startV2TestChannel(t *testing.T, settings *config.PicoSettings) (*PicoChannel, *bus.MessageBus, string) {
+	t.Helper()
+
+	settings.SetToken("test-token")
+	mb := bus.NewMessageBus()
+	ch, err := NewPicoChannel(&config.Channel{Type: config.ChannelPico, Enabled: true}, settings, mb)
+	if err != nil {
+		t.Fatalf("NewPicoChannel: %v", err)
+	}
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	server := httptest.NewServer(ch)
+	t.Cleanup(func() {
+		ch.Stop(context.Background())
+		server.Close()
+	})
+	return ch, mb, "ws" + strings.TrimPrefix(server.URL, "http") + "/pico/ws"
+}
+
+func dialPico(t *testing.T, wsURL string) *websocket.Conn {
+	t.Helper()
+	header := http.Header{"Authorization": {"Bearer test-token"}}
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, header)
+	if err != nil {
+		t.Fatalf("Dial(%s) error = %v", wsURL, err)
+	}
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readFrame(t *testing.T, conn *websocket.Conn) PicoMessage {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg PicoMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	return msg
+}
+
+func waitForConnCount(t *testing.T, ch *PicoChannel, want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for ch.currentConnCount() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("connection count = %d, want %d", ch.currentConnCount(), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNegotiateProtocol(t *testing.T) {
+	cases := map[string]int{"": ProtocolV1, "1": ProtocolV1, "2": ProtocolV2, "9": ProtocolLatest, "x": ProtocolV1}
+	for in, want := range cases {
+		if got := negotiateProtocol(in); got != want {
+			t.Errorf("negotiateProtocol(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestWebSocketV2_ResumeReplaysMissedFrames(t *testing.T) {
+	ch, _, wsURL := startV2TestChannel(t, &config.PicoSettings{})
+	ctx := context.Background()
+
+	conn := dialPico(t, wsURL+"?protocol=2&session_id=s1")
+	ready := readFrame(t, conn)
+	if ready.Type != TypeSessionReady || ready.SessionID != "s1" {
+		t.Fatalf("first frame = %+v, want session.ready for s1", ready)
+	}
+	if got, _ := ready.Payload["protocol"].(float64); got != ProtocolV2 {
+		t.Fatalf("negotiated protocol = %v, want 2", ready.Payload["protocol"])
+	}
+
+	for _, text := range []string{"one", "two"} {
+		if _, err := ch.Send(ctx, bus.OutboundMessage{ChatID: "pico:s1", Content: text}); err != nil {
+			t.Fatalf("Send(%q) error = %v", text, err)
+		}
+	}
+	if first, second := readFrame(t, conn), readFrame(t, conn); first.Seq != 1 || second.Seq != 2 {
+		t.Fatalf("seqs = %d,%d, want 1,2", first.Seq, second.Seq)
+	}
+
+	conn.Close()
+	waitForConnCount(t, ch, 0)
+
+	if _, err := ch.Send(ctx, bus.OutboundMessage{ChatID: "pico:s1", Content: "three"}); err != nil {
+		t.Fatalf("Send() while disconnected error = %v, want buffered", err)
+	}
+
+	conn = dialPico(t, wsURL+"?protocol=2&session_id=s1&last_seq=2")
+	ready = readFrame(t, conn)
+	if ready.Type != TypeSessionReady || ready.Payload["resumed"] != true || ready.Payload["replayed"] != float64(1) {
+		t.Fatalf("resume ready = %+v", ready)
+	}
+	missed := readFrame(t, conn)
+	if missed.Seq != 3 || missed.Payload[PayloadKeyContent] != "three" {
+		t.Fatalf("replayed frame = %+v, want seq 3 \"three\"", missed)
+	}
+}
+
+func TestBroadcastToSession_DoesNotHoldStreamLockWhileWriting(t *testing.T) {
+	ch, _, wsURL := startV2TestChannel(t, &config.PicoSettings{})
+
+	conn := dialPico(t, wsURL+"?protocol=2&session_id=s1")
+	readFrame(t, conn)
+	stream := ch.stream("s1")
+
+	// Stand in for a slow client: later deliveries wait behind this one.
+	stream.mu.Lock()
+	ticket := stream.ticket()
+	stream.mu.Unlock()
+	release := make(chan struct{})
+	go stream.deliver(ticket, func() { <-release })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = ch.broadcastToSession("pico:s1", newMessage(TypeMessageCreate, map[string]any{PayloadKeyContent: "hi"}))
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stream.mu.Lock()
+		seq := stream.lastSeq
+		stream.mu.Unlock()
+		if seq == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("frame was never numbered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !stream.mu.TryLock() {
+		t.Fatal("stream.mu is held while the frame waits to be written")
+	}
+	stream.mu.Unlock()
+	ch.pruneStreams(time.Now())
+
+	close(release)
+	<-done
+	if got := readFrame(t, conn); got.Seq != 1 {
+		t.Fatalf("delivered seq = %d, want 1", got.Seq)
+	}
+}
+
+func TestWebSocketV1_UnchangedAndRejectsV2Frames(t *testing.T) {
+	ch, _, wsURL := startV2TestChannel(t, &config.PicoSettings{})
+
+	conn := dialPico(t, wsURL+"?session_id=legacy")
+	waitForConnCount(t, ch, 1)
+
+	if _, err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "pico:legacy", Content: "hi"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if msg := readFrame(t, conn); msg.Type != TypeMessageCreate || msg.Seq != 0 {
+		t.Fatalf("v1 frame = %+v, want unnumbered message.create", msg)
+	}
+
+	if err := conn.WriteJSON(PicoMessage{Type: TypeTurnCancel, ID: "req-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := readFrame(t, conn); msg.Type != TypeError || msg.Payload["code"] != "unsupported_type" {
+		t.Fatalf("reply = %+v, want unsupported_type error", msg)
+	}
+
+	conn.Close()
+	waitForConnCount(t, ch, 0)
+	if _, err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "pico:legacy", Content: "gone"}); err == nil {
+		t.Fatal("expected v1 send without connections to fail")
+	}
+}
+
+func TestWebSocketV2_SessionNewSwitchAndList(t *testing.T) {
+	_, _, wsURL := startV2TestChannel(t, &config.PicoSettings{})
+
+	conn := dialPico(t, wsURL+"?protocol=2&session_id=first")
+	readFrame(t, conn)
+
+	if err := conn.WriteJSON(PicoMessage{Type: TypeSessionNew, ID: "new-1"}); err != nil {
+		t.Fatal(err)
+	}
+	created := readFrame(t, conn)
+	if created.Type != TypeSessionReady || created.SessionID == "" || created.SessionID == "first" {
+		t.Fatalf("session.new reply = %+v", created)
+	}
+
+	if err := conn.WriteJSON(PicoMessage{Type: TypeSessionList, ID: "list-1"}); err != nil {
+		t.Fatal(err)
+	}
+	listed := readFrame(t, conn)
+	sessions, _ := listed.Payload["sessions"].([]any)
+	if listed.Type != TypeSessionListed || listed.ID != "list-1" || len(sessions) != 2 {
+		t.Fatalf("session.list reply = %+v", listed)
+	}
+
+	if err := conn.WriteJSON(PicoMessage{
+		Type:    TypeSessionSwitch,
+		Payload: map[string]any{"session_id": "first"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if switched := readFrame(t, conn); switched.Type != TypeSessionReady || switched.SessionID != "first" {
+		t.Fatalf("session.switch reply = %+v", switched)
+	}
+}
+
+func TestWebSocketV2_TurnCancelDispatchesStop(t *testing.T) {
+	_, mb, wsURL := startV2TestChannel(t, &config.PicoSettings{})
+
+	conn := dialPico(t, wsURL+"?protocol=2&session_id=busy")
+	readFrame(t, conn)
+	if err := conn.WriteJSON(PicoMessage{Type: TypeTurnCancel, ID: "cancel-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-mb.InboundChan():
+		if msg.Content != "/stop" || msg.ChatID != "pico:busy" {
+			t.Fatalf("inbound = %+v, want /stop for pico:busy", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for /stop")
+	}
+}
+
+func TestRequestToolApproval_RoundTrip(t *testing.T) {
+	ch, _, wsURL := startV2TestChannel(t, &config.PicoSettings{ToolApproval: []string{"exec", "mcp_*"}})
+	ctx := context.Background()
+
+	decision, err := ch.RequestToolApproval(ctx, channels.ToolApprovalRequest{ChatID: "pico:s1", Tool: "read_file"})
+	if err != nil || !decision.Approved {
+		t.Fatalf("unlisted tool decision = %+v, %v; want approved", decision, err)
+	}
+	decision, err = ch.RequestToolApproval(ctx, channels.ToolApprovalRequest{ChatID: "pico:s1", Tool: "exec"})
+	if err != nil || decision.Approved {
+		t.Fatalf("decision without client = %+v, %v; want denied", decision, err)
+	}
+
+	conn := dialPico(t, wsURL+"?protocol=2&session_id=s1")
+	readFrame(t, conn)
+
+	done := make(chan channels.ToolApprovalDecision, 1)
+	go func() {
+		d, _ := ch.RequestToolApproval(ctx, channels.ToolApprovalRequest{
+			ChatID:    "pico:s1",
+			Tool:      "mcp_github_create_issue",
+			Arguments: map[string]any{"title": "bug"},
+		})
+		done <- d
+	}()
+
+	req := readFrame(t, conn)
+	approvalID, _ := req.Payload["approval_id"].(string)
+	if req.Type != TypeToolApprovalRequest || approvalID == "" || req.Payload["tool"] != "mcp_github_create_issue" {
+		t.Fatalf("approval request = %+v", req)
+	}
+	if err := conn.WriteJSON(PicoMessage{
+		Type: TypeToolApprovalResponse,
+		Payload: map[string]any{
+			"approval_id": approvalID,
+			"approved":    false,
+			"reason":      "not now",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case d := <-done:
+		if d.Approved || d.Reason != "not now" {
+			t.Fatalf("decision = %+v, want denied with reason", d)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for approval decision")
+	}
+}
@@ -0,0 +1,282 @@
+package pico
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultResumeBufferSize = 256
+	defaultResumeTTL        = 10 * time.Minute
+)
+
+// sessionStream sequences the frames of one v2 session and keeps the most
+// recent ones so a reconnecting client can resume where it left off.
+//
+// mu is held while a frame is numbered and its recipients are picked, and
+// while a connection joins the session and its replay is collected, so a
+// joining client never sees a frame both live and in its replay. No network
+// write happens under mu: the writer takes a delivery ticket under mu and
+// writes after releasing it, in ticket order.
+type sessionStream struct {
+	mu         sync.Mutex
+	lastSeq    uint64
+	frames     []PicoMessage // oldest first
+	lastActive time.Time
+	tickets    uint64 // guarded by mu
+
+	sendMu    sync.Mutex
+	sendCond  *sync.Cond
+	delivered uint64 // guarded by sendMu
+}
+
+func newSessionStream() *sessionStream {
+	s := &sessionStream{lastActive: time.Now()}
+	s.sendCond = sync.NewCond(&s.sendMu)
+	return s
+}
+
+// append numbers msg, records it and returns the numbered frame.
+// Callers must hold s.mu.
+func (s *sessionStream) append(msg PicoMessage, limit int) PicoMessage {
+	s.lastSeq++
+	msg.Seq = s.lastSeq
+	s.frames = append(s.frames, msg)
+	if over := len(s.frames) - limit; over > 0 {
+		s.frames = append(s.frames[:0:0], s.frames[over:]...)
+	}
+	s.lastActive = time.Now()
+	return msg
+}
+
+// since returns the buffered frames after seq. gap is true when frames the
+// client has not seen were already dropped, or when seq is ahead of the
+// stream (the server restarted and numbering began again).
+// Callers must hold s.mu.
+func (s *sessionStream) since(seq uint64) (frames []PicoMessage, gap bool) {
+	if seq > s.lastSeq {
+		return append([]PicoMessage(nil), s.frames...), true
+	}
+	if len(s.frames) > 0 && seq+1 < s.frames[0].Seq {
+		gap = true
+	}
+	for _, f := range s.frames {
+		if f.Seq > seq {
+			frames = append(frames, f)
+		}
+	}
+	return frames, gap
+}
+
+func (c *PicoChannel) resumeBufferSize() int {
+	if c.config.ResumeBufferSize > 0 {
+		return c.config.ResumeBufferSize
+	}
+	return defaultResumeBufferSize
+}
+
+func (c *PicoChannel) resumeTTL() time.Duration {
+	if c.config.ResumeTTL > 0 {
+		return time.Duration(c.config.ResumeTTL) * time.Second
+	}
+	return defaultResumeTTL
+}
+
+// stream returns the stream of a v2 session, or nil for sessions that only
+// ever had v1 clients.
+func (c *PicoChannel) stream(sessionID string) *sessionStream {
+	c.streamsMu.Lock()
+	defer c.streamsMu.Unlock()
+	return c.streams[sessionID]
+}
+
+// ensureStream returns the stream of sessionID, creating it if needed, and
+// drops streams that have been idle without connections for longer than the
+// resume TTL.
+func (c *PicoChannel) ensureStream(sessionID string) *sessionStream {
+	c.pruneStreams(time.Now())
+
+	c.streamsMu.Lock()
+	defer c.streamsMu.Unlock()
+	if c.streams == nil {
+		c.streams = make(map[string]*sessionStream)
+	}
+	s, ok := c.streams[sessionID]
+	if !ok {
+		s = newSessionStream()
+		c.streams[sessionID] = s
+	}
+	return s
+}
+
+func (c *PicoChannel) pruneStreams(now time.Time) {
+	ttl := c.resumeTTL()
+
+	c.streamsMu.Lock()
+	streams := make(map[string]*sessionStream, len(c.streams))
+	for sessionID, s := range c.streams {
+		streams[sessionID] = s
+	}
+	c.streamsMu.Unlock()
+
+	for sessionID, s := range streams {
+		if len(c.sessionConnectionsSnapshot(sessionID)) > 0 {
+			continue
+		}
+		s.mu.Lock()
+		expired := now.Sub(s.lastActive) > ttl
+		s.mu.Unlock()
+		if !expired {
+			continue
+		}
+		c.streamsMu.Lock()
+		if c.streams[sessionID] == s {
+			delete(c.streams, sessionID)
+		}
+		c.streamsMu.Unlock()
+	}
+}
+
+// touchStream marks a session as active, e.g. when its last client leaves,
+// so the resume TTL counts from the disconnect.
+func (c *PicoChannel) touchStream(sessionID string) {
+	if s := c.stream(sessionID); s != nil {
+		s.mu.Lock()
+		s.lastActive = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// readyFrames returns session.ready for pc followed by the frames after
+// lastSeq when the client asked to resume. Callers must hold stream.mu and
+// must have already indexed pc under its session.
+func (c *PicoChannel) readyFrames(pc *picoConn, stream *sessionStream, lastSeq uint64, resume bool) []PicoMessage {
+	var (
+		missed []PicoMessage
+		gap    bool
+	)
+	if resume {
+		missed, gap = stream.since(lastSeq)
+	}
+	stream.lastActive = time.Now()
+
+	ready := newMessage(TypeSessionReady, map[string]any{
+		"protocol":     pc.protocol,
+		"capabilities": c.capabilities(),
+		"last_seq":     stream.lastSeq,
+		"resumed":      resume && !gap,
+		"gap":          gap,
+		"replayed":     len(missed),
+	})
+	ready.SessionID = pc.sessionID
+	return append([]PicoMessage{ready}, missed...)
+}
+
+// ticket reserves the next delivery slot. Callers must hold s.mu and must
+// pass the ticket to deliver exactly once.
+func (s *sessionStream) ticket() uint64 {
+	t := s.tickets
+	s.tickets++
+	return t
+}
+
+// deliver runs write once every earlier ticket has been delivered, so frames
+// reach each connection in the order they were numbered. Callers must not
+// hold s.mu.
+func (s *sessionStream) deliver(ticket uint64, write func()) {
+	s.sendMu.Lock()
+	for s.delivered != ticket {
+		s.sendCond.Wait()
+	}
+	s.sendMu.Unlock()
+
+	defer func() {
+		s.sendMu.Lock()
+		s.delivered++
+		s.sendCond.Broadcast()
+		s.sendMu.Unlock()
+	}()
+	write()
+}
+
+// join indexes pc under the stream's session with add and sends it
+// session.ready plus any replay, without a live frame slipping in between.
+func (c *PicoChannel) join(
+	stream *sessionStream, lastSeq uint64, resume bool, add func() (*picoConn, error),
+) (*picoConn, error) {
+	stream.mu.Lock()
+	pc, err := add()
+	if err != nil {
+		stream.mu.Unlock()
+		return nil, err
+	}
+	frames := c.readyFrames(pc, stream, lastSeq, resume)
+	ticket := stream.ticket()
+	stream.mu.Unlock()
+
+	stream.deliver(ticket, func() {
+		for _, frame := range frames {
+			if err := pc.writeJSON(frame); err != nil {
+				return
+			}
+		}
+	})
+	return pc, nil
+}
+
+// sessionSummaries lists the sessions this channel currently knows about:
+// those with live connections and v2 sessions still inside the resume TTL.
+func (c *PicoChannel) sessionSummaries() []map[string]any {
+	type summary struct {
+		id          string
+		connections int
+		lastSeq     uint64
+		lastActive  time.Time
+	}
+	byID := make(map[string]*summary)
+
+	c.connsMu.RLock()
+	for sessionID, conns := range c.sessionConnections {
+		byID[sessionID] = &summary{id: sessionID, connections: len(conns), lastActive: time.Now()}
+	}
+	c.connsMu.RUnlock()
+
+	c.streamsMu.Lock()
+	for sessionID, s := range c.streams {
+		sum, ok := byID[sessionID]
+		if !ok {
+			sum = &summary{id: sessionID}
+			byID[sessionID] = sum
+		}
+		s.mu.Lock()
+		sum.lastSeq = s.lastSeq
+		if sum.connections == 0 {
+			sum.lastActive = s.lastActive
+		}
+		s.mu.Unlock()
+	}
+	c.streamsMu.Unlock()
+
+	list := make([]*summary, 0, len(byID))
+	for _, sum := range byID {
+		list = append(list, sum)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].lastActive.Equal(list[j].lastActive) {
+			return list[i].lastActive.After(list[j].lastActive)
+		}
+		return list[i].id < list[j].id
+	})
+
+	out := make([]map[string]any, 0, len(list))
+	for _, sum := range list {
+		out = append(out, map[string]any{
+			"session_id":  sum.id,
+			"connections": sum.connections,
+			"last_seq":    sum.lastSeq,
+			"last_active": sum.lastActive.UnixMilli(),
+		})
+	}
+	return out
+}
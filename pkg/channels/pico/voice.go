@@ -0,0 +1,407 @@
+package pico
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/audio"
+	"github.com/sipeed/picoclaw/pkg/audio/tts"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	voiceFormatPCM  = "pcm"
+	voiceFormatOpus = "opus"
+
+	voiceStateListening = "listening" // waiting for the user to speak
+	voiceStateSpeech    = "speech"    // the user is speaking
+	voiceStateSpeaking  = "speaking"  // reply audio is being sent
+
+	defaultVoiceThreshold = 0.02
+	defaultVoiceSilence   = 700 * time.Millisecond
+	voiceMinSpeech        = 120 * time.Millisecond
+	// voicePreRoll is how much audio before the detected start of speech is
+	// kept, so the first syllable is not clipped.
+	voicePreRoll = 300 * time.Millisecond
+	// opusSilenceBytes is the size at or below which an Opus packet counts
+	// as silence. Opus has no cheap energy measure, but DTX and comfort-noise
+	// packets are a few bytes while speech packets are tens of bytes.
+	opusSilenceBytes = 12
+	opusClockRate    = 48000
+)
+
+// voiceStream is the voice mode state of one connection. It is only touched
+// by the connection's read loop.
+type voiceStream struct {
+	format     string
+	sampleRate int
+	channels   int
+	threshold  float64
+	vad        *audio.VAD
+
+	pending    []bus.AudioChunk // pre-roll held until speech is confirmed
+	pendingDur []time.Duration
+	seq        uint64
+	timestamp  uint32 // Opus RTP clock
+}
+
+// voicePlayback tracks reply audio sent to a session since the user last
+// spoke, so speaking again can cut it off.
+type voicePlayback struct {
+	id     uint64
+	cancel context.CancelFunc
+}
+
+// voiceEnabled reports whether clients may start voice mode.
+func (c *PicoChannel) voiceEnabled() bool {
+	return c.config.Voice && c.asrAvailable
+}
+
+// capabilities lists the v2 features advertised in session.ready.
+func (c *PicoChannel) capabilities() []string {
+	if !c.voiceEnabled() {
+		return protocolCapabilities
+	}
+	return append(slices.Clip(protocolCapabilities), "voice")
+}
+
+// VoiceCapabilities implements channels.VoiceCapabilityProvider. It reports
+// only what is actually configured: ASR when voice mode can start and TTS
+// when a speech provider was detected.
+func (c *PicoChannel) VoiceCapabilities() channels.VoiceCapabilities {
+	return channels.VoiceCapabilities{ASR: c.voiceEnabled(), TTS: c.tts != nil}
+}
+
+// handleVoiceStart switches the connection into voice mode. Audio then
+// arrives as binary WebSocket frames in the negotiated format.
+func (c *PicoChannel) handleVoiceStart(pc *picoConn, msg PicoMessage) {
+	fail := func(code, message string) {
+		pc.writeJSON(newErrorWithPayload(code, message, map[string]any{"request_id": msg.ID}))
+	}
+	if !c.voiceEnabled() {
+		fail("voice_unavailable", "voice mode is not enabled on this server")
+		return
+	}
+	if !c.IsAllowedSender(picoSender()) {
+		fail("forbidden", "voice mode is not allowed for this sender")
+		return
+	}
+
+	format, _ := msg.Payload["format"].(string)
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = voiceFormatPCM
+	}
+	sampleRate := payloadInt(msg.Payload, "sample_rate")
+	numChannels := max(payloadInt(msg.Payload, "channels"), 1)
+
+	switch format {
+	case voiceFormatPCM:
+		if sampleRate <= 0 {
+			sampleRate = 16000
+		}
+		if numChannels != 1 {
+			fail("invalid_voice_format", "pcm audio must be mono")
+			return
+		}
+	case voiceFormatOpus:
+		// Opus timestamps always run at 48 kHz whatever the input rate.
+		sampleRate = opusClockRate
+		if numChannels > 2 {
+			fail("invalid_voice_format", "opus audio must be mono or stereo")
+			return
+		}
+	default:
+		fail("invalid_voice_format", fmt.Sprintf("unsupported voice format: %s", format))
+		return
+	}
+
+	threshold := c.config.VoiceThreshold
+	if threshold <= 0 {
+		threshold = defaultVoiceThreshold
+	}
+	silence := time.Duration(c.config.VoiceSilenceMS) * time.Millisecond
+	if silence <= 0 {
+		silence = defaultVoiceSilence
+	}
+	pc.voice.Store(&voiceStream{
+		format:     format,
+		sampleRate: sampleRate,
+		channels:   numChannels,
+		threshold:  threshold,
+		vad:        audio.NewVAD(voiceMinSpeech, silence),
+	})
+
+	logger.InfoCF("pico", "Voice mode started", map[string]any{
+		"conn_id":     pc.id,
+		"session_id":  pc.sessionID,
+		"format":      format,
+		"sample_rate": sampleRate,
+	})
+	reply := newMessage(TypeVoiceState, map[string]any{
+		"state":       voiceStateListening,
+		"format":      format,
+		"sample_rate": sampleRate,
+		"tts":         c.tts != nil,
+	})
+	reply.ID = msg.ID
+	reply.SessionID = pc.sessionID
+	pc.writeJSON(reply)
+}
+
+// handleVoiceStop leaves voice mode, finishing any utterance in progress.
+func (c *PicoChannel) handleVoiceStop(pc *picoConn, msg PicoMessage) {
+	vs := pc.voice.Swap(nil)
+	if vs != nil && vs.vad.Speaking() {
+		c.publishVoiceChunk(pc, vs, nil, true)
+	}
+	c.stopPlayback(pc.sessionID)
+
+	reply := newMessage(TypeVoiceState, map[string]any{"state": "stopped"})
+	reply.ID = msg.ID
+	reply.SessionID = pc.sessionID
+	pc.writeJSON(reply)
+}
+
+// handleVoiceFrame runs one binary audio frame through the VAD. Frames are
+// forwarded to the speech recognizer only while the user is speaking.
+func (c *PicoChannel) handleVoiceFrame(pc *picoConn, data []byte) {
+	vs := pc.voice.Load()
+	if vs == nil {
+		pc.writeJSON(newError("voice_not_started", "send voice.start before audio frames"))
+		return
+	}
+	if len(data) == 0 {
+		return
+	}
+
+	var voiced bool
+	var dur time.Duration
+	if vs.format == voiceFormatOpus {
+		dur = audio.OpusPacketDuration(data)
+		voiced = len(data) > opusSilenceBytes
+	} else {
+		dur = audio.PCM16Duration(len(data), vs.sampleRate, vs.channels)
+		voiced = audio.PCM16RMS(data) >= vs.threshold
+	}
+	if dur <= 0 {
+		return
+	}
+
+	wasSpeaking := vs.vad.Speaking()
+	switch vs.vad.Feed(voiced, dur) {
+	case audio.VADSpeechStart:
+		c.bargeIn(pc.sessionID)
+		c.writeVoiceState(pc, voiceStateSpeech)
+		for _, chunk := range vs.pending {
+			c.publish(chunk)
+		}
+		vs.pending, vs.pendingDur = nil, nil
+		c.publishVoiceChunk(pc, vs, data, false)
+
+	case audio.VADSpeechEnd:
+		c.publishVoiceChunk(pc, vs, data, true)
+		c.writeVoiceState(pc, voiceStateListening)
+
+	default:
+		if wasSpeaking {
+			c.publishVoiceChunk(pc, vs, data, false)
+			return
+		}
+		vs.hold(vs.chunk(pc, data, dur), dur)
+	}
+}
+
+// hold keeps chunk as pre-roll, dropping the oldest beyond voicePreRoll.
+func (vs *voiceStream) hold(chunk bus.AudioChunk, dur time.Duration) {
+	vs.pending = append(vs.pending, chunk)
+	vs.pendingDur = append(vs.pendingDur, dur)
+	var total time.Duration
+	for _, d := range vs.pendingDur {
+		total += d
+	}
+	for len(vs.pending) > 1 && total-vs.pendingDur[0] >= voicePreRoll {
+		total -= vs.pendingDur[0]
+		vs.pending, vs.pendingDur = vs.pending[1:], vs.pendingDur[1:]
+	}
+}
+
+// chunk wraps a frame for the speech recognizer.
+func (vs *voiceStream) chunk(pc *picoConn, data []byte, dur time.Duration) bus.AudioChunk {
+	vs.seq++
+	chunk := bus.AudioChunk{
+		SessionID:  "pico_voice_" + pc.id,
+		SpeakerID:  picoSenderID,
+		ChatID:     "pico:" + pc.sessionID,
+		Channel:    "pico",
+		ChatType:   "direct",
+		Sequence:   vs.seq,
+		Timestamp:  vs.timestamp,
+		SampleRate: vs.sampleRate,
+		Channels:   vs.channels,
+		Format:     vs.format,
+		Data:       data,
+	}
+	if vs.format == voiceFormatOpus {
+		vs.timestamp += uint32(dur * opusClockRate / time.Second)
+	}
+	return chunk
+}
+
+func (c *PicoChannel) publishVoiceChunk(pc *picoConn, vs *voiceStream, data []byte, end bool) {
+	var dur time.Duration
+	if vs.format == voiceFormatOpus {
+		dur = audio.OpusPacketDuration(data)
+	}
+	chunk := vs.chunk(pc, data, dur)
+	chunk.EndOfSpeech = end
+	c.publish(chunk)
+}
+
+func (c *PicoChannel) publish(chunk bus.AudioChunk) {
+	if err := c.bus.PublishAudioChunk(c.ctx, chunk); err != nil {
+		logger.DebugCF("pico", "Failed to publish audio chunk", map[string]any{"error": err.Error()})
+	}
+}
+
+// bargeIn stops reply audio when the user starts speaking over it. The
+// agent sees the new utterance as a voice message for a busy session and
+// interrupts the running turn itself.
+func (c *PicoChannel) bargeIn(sessionID string) {
+	if !c.stopPlayback(sessionID) {
+		return
+	}
+	logger.DebugCF("pico", "Voice barge-in", map[string]any{"session_id": sessionID})
+	c.writeVoice(sessionID, newMessage(TypeVoiceInterrupt, nil))
+}
+
+// stopPlayback cancels reply audio for sessionID and reports whether any
+// was sent since the user last spoke.
+func (c *PicoChannel) stopPlayback(sessionID string) bool {
+	c.voiceMu.Lock()
+	defer c.voiceMu.Unlock()
+	p, ok := c.playback[sessionID]
+	if !ok {
+		return false
+	}
+	p.cancel()
+	delete(c.playback, sessionID)
+	return true
+}
+
+// speakReply synthesizes a final reply for the voice connections of its
+// session, sentence by sentence.
+func (c *PicoChannel) speakReply(chatID, content string) {
+	sessionID := strings.TrimPrefix(chatID, "pico:")
+	if c.tts == nil || strings.TrimSpace(content) == "" || !c.hasVoiceConnection(sessionID) {
+		return
+	}
+
+	c.voiceMu.Lock()
+	if p, ok := c.playback[sessionID]; ok {
+		p.cancel()
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.playbackSeq++
+	id := c.playbackSeq
+	c.playback[sessionID] = &voicePlayback{id: id, cancel: cancel}
+	speak := c.speakFn
+	c.voiceMu.Unlock()
+
+	if speak == nil {
+		speak = c.speak
+	}
+	go speak(ctx, sessionID, content)
+}
+
+func (c *PicoChannel) speak(ctx context.Context, sessionID, text string) {
+	sentences := audio.SplitSentences(text)
+	if len(sentences) == 0 {
+		return
+	}
+	ext, contentType := tts.OutputFormat(c.tts)
+
+	c.writeVoice(sessionID, newMessage(TypeVoiceState, map[string]any{"state": voiceStateSpeaking}))
+	for i, sentence := range sentences {
+		if ctx.Err() != nil {
+			return
+		}
+		data, err := c.synthesize(ctx, sentence)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.ErrorCF("pico", "TTS synthesize failed", map[string]any{
+					"error":    err.Error(),
+					"sentence": i,
+				})
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		c.writeVoice(sessionID, newMessage(TypeVoiceAudio, map[string]any{
+			"index":        i,
+			"final":        i == len(sentences)-1,
+			"text":         sentence,
+			"format":       strings.TrimPrefix(ext, "."),
+			"content_type": contentType,
+			"data":         base64.StdEncoding.EncodeToString(data),
+		}))
+	}
+	if ctx.Err() == nil {
+		c.writeVoice(sessionID, newMessage(TypeVoiceState, map[string]any{"state": voiceStateListening}))
+	}
+}
+
+func (c *PicoChannel) synthesize(ctx context.Context, text string) ([]byte, error) {
+	stream, err := c.tts.Synthesize(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return io.ReadAll(stream)
+}
+
+// hasVoiceConnection reports whether any connection of the session is in
+// voice mode.
+func (c *PicoChannel) hasVoiceConnection(sessionID string) bool {
+	for _, pc := range c.sessionConnectionsSnapshot(sessionID) {
+		if pc.voice.Load() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// writeVoice sends an ephemeral voice frame to the session's voice
+// connections. Voice frames are never buffered for resume.
+func (c *PicoChannel) writeVoice(sessionID string, msg PicoMessage) {
+	msg.SessionID = sessionID
+	for _, pc := range c.sessionConnectionsSnapshot(sessionID) {
+		if pc.voice.Load() != nil {
+			pc.writeJSON(msg)
+		}
+	}
+}
+
+func (c *PicoChannel) writeVoiceState(pc *picoConn, state string) {
+	msg := newMessage(TypeVoiceState, map[string]any{"state": state})
+	msg.SessionID = pc.sessionID
+	pc.writeJSON(msg)
+}
+
+// payloadInt reads a non-negative integer from a JSON payload.
+func payloadInt(payload map[string]any, key string) int {
+	n, ok := payloadSeq(payload, key)
+	if !ok {
+		return 0
+	}
+	return int(n)
+}
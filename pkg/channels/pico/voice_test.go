@@ -0,0 +1,189 @@
+package pico
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+type stubTTS struct{}
+
+func (stubTTS) Name() string { return "stub-tts" }
+
+func (stubTTS) Synthesize(_ context.Context, text string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("audio:" + text)), nil
+}
+
+// pcmFrame returns 20 ms of 16 kHz mono PCM at the given amplitude.
+func pcmFrame(amplitude int16) []byte {
+	frame := make([]byte, 640)
+	for i := 0; i < len(frame); i += 2 {
+		s := amplitude
+		if i%4 == 0 {
+			s = -amplitude
+		}
+		binary.LittleEndian.PutUint16(frame[i:], uint16(s))
+	}
+	return frame
+}
+
+func startVoiceTestConn(t *testing.T, sessionID string) (*PicoChannel, *bus.MessageBus, *websocket.Conn) {
+	t.Helper()
+	ch, mb, wsURL := startV2TestChannel(t, &config.PicoSettings{Voice: true})
+	ch.asrAvailable = true
+	ch.tts = stubTTS{}
+
+	conn := dialPico(t, wsURL+"?protocol=2&session_id="+sessionID)
+	ready := readFrame(t, conn)
+	caps, _ := ready.Payload["capabilities"].([]any)
+	if !slices.Contains(caps, any("voice")) {
+		t.Fatalf("capabilities = %v, want voice", caps)
+	}
+
+	if err := conn.WriteJSON(PicoMessage{
+		Type:    TypeVoiceStart,
+		ID:      "v1",
+		Payload: map[string]any{"format": "pcm", "sample_rate": 16000},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if state := readFrame(t, conn); state.Type != TypeVoiceState || state.Payload["state"] != voiceStateListening {
+		t.Fatalf("voice.start reply = %+v", state)
+	}
+	return ch, mb, conn
+}
+
+func writeFrames(t *testing.T, conn *websocket.Conn, frame []byte, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := conn.WriteMessage(websocket.BinaryMessage, frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readUntil reads frames until one of msgType arrives.
+func readUntil(t *testing.T, conn *websocket.Conn, msgType string) PicoMessage {
+	t.Helper()
+	for {
+		if msg := readFrame(t, conn); msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
+func TestVoice_SegmentsSpeechForASR(t *testing.T) {
+	_, mb, conn := startVoiceTestConn(t, "s1")
+
+	writeFrames(t, conn, pcmFrame(0), 30)
+	writeFrames(t, conn, pcmFrame(8000), 20)
+	writeFrames(t, conn, pcmFrame(0), 50)
+
+	if state := readUntil(t, conn, TypeVoiceState); state.Payload["state"] != voiceStateSpeech {
+		t.Fatalf("state = %v, want speech", state.Payload["state"])
+	}
+
+	var chunks []bus.AudioChunk
+	timeout := time.After(2 * time.Second)
+	for len(chunks) == 0 || !chunks[len(chunks)-1].EndOfSpeech {
+		select {
+		case chunk := <-mb.AudioChunksChan():
+			chunks = append(chunks, chunk)
+		case <-timeout:
+			t.Fatalf("no end of speech after %d chunks", len(chunks))
+		}
+	}
+	first := chunks[0]
+	if first.ChatID != "pico:s1" || first.Channel != "pico" || first.ChatType != "direct" || first.Format != "pcm" {
+		t.Fatalf("unexpected chunk: %+v", first)
+	}
+	// Pre-roll, the voiced frames and the hangover are forwarded; the
+	// leading silence is not.
+	if len(chunks) <= 20 || len(chunks) >= 100 {
+		t.Fatalf("forwarded %d chunks", len(chunks))
+	}
+	if state := readUntil(t, conn, TypeVoiceState); state.Payload["state"] != voiceStateListening {
+		t.Fatalf("state = %v, want listening", state.Payload["state"])
+	}
+}
+
+func TestVoice_SpeaksRepliesAndBargesIn(t *testing.T) {
+	ch, _, conn := startVoiceTestConn(t, "s2")
+	ctx := context.Background()
+
+	if _, err := ch.Send(ctx, bus.OutboundMessage{
+		ChatID:  "pico:s2",
+		Content: "thinking",
+		Context: bus.InboundContext{Raw: map[string]string{"message_kind": MessageKindThought}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ch.Send(ctx, bus.OutboundMessage{ChatID: "pico:s2", Content: "It is exactly noon in your time zone right now. Is there anything else I can help you with today?"}); err != nil {
+		t.Fatal(err)
+	}
+
+	first := readUntil(t, conn, TypeVoiceAudio)
+	data, _ := base64.StdEncoding.DecodeString(first.Payload["data"].(string))
+	if string(data) != "audio:It is exactly noon in your time zone right now." || first.Payload["format"] != "ogg" {
+		t.Fatalf("first voice.audio = %+v", first.Payload)
+	}
+	if last := readUntil(t, conn, TypeVoiceAudio); last.Payload["final"] != true {
+		t.Fatalf("second voice.audio = %+v", last.Payload)
+	}
+
+	writeFrames(t, conn, pcmFrame(8000), 10)
+	readUntil(t, conn, TypeVoiceInterrupt)
+
+	ch.voiceMu.Lock()
+	_, playing := ch.playback["s2"]
+	ch.voiceMu.Unlock()
+	if playing {
+		t.Error("barge-in should clear the session's playback")
+	}
+}
+
+func TestVoice_StartRejectedWhenDisabled(t *testing.T) {
+	_, _, wsURL := startV2TestChannel(t, &config.PicoSettings{})
+	conn := dialPico(t, wsURL+"?protocol=2&session_id=s3")
+	readFrame(t, conn)
+
+	if err := conn.WriteJSON(PicoMessage{Type: TypeVoiceStart, ID: "v1"}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := readFrame(t, conn); msg.Type != TypeError || msg.Payload["code"] != "voice_unavailable" {
+		t.Fatalf("reply = %+v, want voice_unavailable", msg)
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, pcmFrame(8000)); err != nil {
+		t.Fatal(err)
+	}
+	if msg := readFrame(t, conn); msg.Payload["code"] != "voice_not_started" {
+		t.Fatalf("reply = %+v, want voice_not_started", msg)
+	}
+}
+
+func TestVoiceCapabilities_ReflectConfiguredProviders(t *testing.T) {
+	ch, _, _ := startV2TestChannel(t, &config.PicoSettings{Voice: true})
+	if caps := ch.VoiceCapabilities(); caps.ASR || caps.TTS {
+		t.Fatalf("caps without providers = %+v, want none", caps)
+	}
+
+	ch.asrAvailable = true
+	if caps := ch.VoiceCapabilities(); !caps.ASR || caps.TTS {
+		t.Fatalf("caps with ASR only = %+v", caps)
+	}
+
+	ch.tts = stubTTS{}
+	if caps := ch.VoiceCapabilities(); !caps.ASR || !caps.TTS {
+		t.Fatalf("caps with ASR and TTS = %+v", caps)
+	}
+}
@@ -0,0 +1,227 @@
+package rocketchat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+)
+
+type rcUser struct {
+	ID       string `json:"_id"`
+	Username string `json:"username"`
+	Name     string `json:"name,omitempty"`
+}
+
+type rcAttachment struct {
+	Title     string `json:"title"`
+	TitleLink string `json:"title_link"`
+	ImageURL  string `json:"image_url"`
+	AudioURL  string `json:"audio_url"`
+	VideoURL  string `json:"video_url"`
+}
+
+type rcMessage struct {
+	ID          string         `json:"_id"`
+	RoomID      string         `json:"rid"`
+	Msg         string         `json:"msg"`
+	ThreadID    string         `json:"tmid,omitempty"`
+	Type        string         `json:"t,omitempty"`
+	User        rcUser         `json:"u"`
+	EditedAt    any            `json:"editedAt,omitempty"`
+	Mentions    []rcUser       `json:"mentions,omitempty"`
+	Attachments []rcAttachment `json:"attachments,omitempty"`
+}
+
+// rcRoomInfo is the second argument of a stream-room-messages event.
+type rcRoomInfo struct {
+	RoomType string `json:"roomType"` // d, c, p or l
+	RoomName string `json:"roomName"`
+}
+
+// do performs an authenticated JSON request against /api/v1. Rocket.Chat
+// reports failures as {"success": false, "error": "..."}.
+func (c *RocketChatChannel) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api/v1"+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.send(req, out)
+}
+
+func (c *RocketChatChannel) send(req *http.Request, out any) error {
+	c.authHeaders(req.Header)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return channels.ClassifyNetError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		var apiErr struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &apiErr) == nil {
+			if apiErr.Error != "" {
+				msg = apiErr.Error
+			} else if apiErr.Message != "" {
+				msg = apiErr.Message
+			}
+		}
+		return channels.ClassifySendError(resp.StatusCode, fmt.Errorf("rocketchat %s %s: %d %s",
+			req.Method, req.URL.Path, resp.StatusCode, msg))
+	}
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *RocketChatChannel) authHeaders(h http.Header) {
+	c.authMu.RLock()
+	defer c.authMu.RUnlock()
+	h.Set("X-User-Id", c.userID)
+	h.Set("X-Auth-Token", c.authToken)
+}
+
+// login exchanges username and password for a session token.
+func (c *RocketChatChannel) login(ctx context.Context) error {
+	var resp struct {
+		Data struct {
+			UserID    string `json:"userId"`
+			AuthToken string `json:"authToken"`
+		} `json:"data"`
+	}
+	err := c.do(ctx, http.MethodPost, "/login", map[string]string{
+		"user":     c.config.Username,
+		"password": c.config.Password.String(),
+	}, &resp)
+	if err != nil {
+		return err
+	}
+	if resp.Data.UserID == "" || resp.Data.AuthToken == "" {
+		return fmt.Errorf("rocketchat login returned no credentials")
+	}
+
+	c.authMu.Lock()
+	c.userID = resp.Data.UserID
+	c.authToken = resp.Data.AuthToken
+	c.authMu.Unlock()
+	return nil
+}
+
+func (c *RocketChatChannel) sendMessage(ctx context.Context, roomID, threadID, text string) (string, error) {
+	var resp struct {
+		Message rcMessage `json:"message"`
+	}
+	msg := map[string]string{"rid": roomID, "msg": text}
+	if threadID != "" {
+		msg["tmid"] = threadID
+	}
+	if err := c.do(ctx, http.MethodPost, "/chat.sendMessage", map[string]any{"message": msg}, &resp); err != nil {
+		return "", err
+	}
+	return resp.Message.ID, nil
+}
+
+func (c *RocketChatChannel) updateMessage(ctx context.Context, roomID, messageID, text string) error {
+	return c.do(ctx, http.MethodPost, "/chat.update", map[string]string{
+		"roomId": roomID,
+		"msgId":  messageID,
+		"text":   text,
+	}, nil)
+}
+
+func (c *RocketChatChannel) deleteMessage(ctx context.Context, roomID, messageID string) error {
+	return c.do(ctx, http.MethodPost, "/chat.delete", map[string]string{
+		"roomId": roomID,
+		"msgId":  messageID,
+	}, nil)
+}
+
+func (c *RocketChatChannel) react(ctx context.Context, messageID string, add bool) error {
+	return c.do(ctx, http.MethodPost, "/chat.react", map[string]any{
+		"messageId":   messageID,
+		"emoji":       reactionEmoji,
+		"shouldReact": add,
+	}, nil)
+}
+
+// uploadFile posts a local file to roomID with an optional caption.
+func (c *RocketChatChannel) uploadFile(
+	ctx context.Context,
+	roomID, threadID, localPath, filename, caption string,
+) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if filename == "" {
+		filename = filepath.Base(localPath)
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return "", err
+	}
+	if caption != "" {
+		if err := w.WriteField("msg", caption); err != nil {
+			return "", err
+		}
+	}
+	if threadID != "" {
+		if err := w.WriteField("tmid", threadID); err != nil {
+			return "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/rooms.upload/"+roomID, &buf)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	var resp struct {
+		Message rcMessage `json:"message"`
+	}
+	if err := c.send(req, &resp); err != nil {
+		return "", err
+	}
+	return resp.Message.ID, nil
+}
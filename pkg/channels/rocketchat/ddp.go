@@ -0,0 +1,182 @@
+package rocketchat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	wsReadTimeout = 90 * time.Second
+	wsMaxBackoff  = time.Minute
+	loginCallID   = "login"
+	subscribeID   = "my-messages"
+)
+
+// ddpFrame is the subset of DDP (Meteor's WebSocket protocol) fields this
+// channel reads.
+type ddpFrame struct {
+	Msg        string          `json:"msg"`
+	ID         string          `json:"id"`
+	Collection string          `json:"collection"`
+	Error      json.RawMessage `json:"error"`
+	Fields     struct {
+		EventName string            `json:"eventName"`
+		Args      []json.RawMessage `json:"args"`
+	} `json:"fields"`
+}
+
+// websocketURL converts the server URL to its ws(s):// DDP endpoint.
+func (c *RocketChatChannel) websocketURL() string {
+	if rest, ok := strings.CutPrefix(c.baseURL, "https://"); ok {
+		return "wss://" + rest + "/websocket"
+	}
+	return "ws://" + strings.TrimPrefix(c.baseURL, "http://") + "/websocket"
+}
+
+// writeDDP serializes writes to the current connection.
+func (c *RocketChatChannel) writeDDP(v any) error {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	if c.conn == nil {
+		return errors.New("rocketchat websocket not connected")
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return c.conn.WriteJSON(v)
+}
+
+// callMethod invokes a DDP method without waiting for its result.
+func (c *RocketChatChannel) callMethod(method string, params ...any) error {
+	return c.writeDDP(map[string]any{
+		"msg":    "method",
+		"method": method,
+		"id":     strconv.FormatUint(c.callSeq.Add(1), 10),
+		"params": params,
+	})
+}
+
+// runWebSocket keeps the realtime stream connected until the channel stops,
+// reconnecting with exponential backoff.
+func (c *RocketChatChannel) runWebSocket() {
+	backoff := time.Second
+	for c.ctx.Err() == nil {
+		started := time.Now()
+		err := c.listen()
+		if c.ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > wsMaxBackoff {
+			backoff = time.Second
+		}
+		logger.WarnCF("rocketchat", "WebSocket disconnected; reconnecting", map[string]any{
+			"error":       fmt.Sprint(err),
+			"retry_after": backoff.String(),
+		})
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, wsMaxBackoff)
+	}
+}
+
+func (c *RocketChatChannel) listen() error {
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second, Proxy: http.ProxyFromEnvironment}
+	conn, resp, err := dialer.DialContext(c.ctx, c.websocketURL(), nil)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	c.connMu.Lock()
+	c.conn = conn
+	c.connMu.Unlock()
+	defer func() {
+		c.connMu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+		}
+		c.connMu.Unlock()
+	}()
+
+	c.authMu.RLock()
+	token := c.authToken
+	c.authMu.RUnlock()
+
+	handshake := []any{
+		map[string]any{"msg": "connect", "version": "1", "support": []string{"1"}},
+		map[string]any{
+			"msg":    "method",
+			"method": "login",
+			"id":     loginCallID,
+			"params": []any{map[string]string{"resume": token}},
+		},
+		map[string]any{
+			"msg":    "sub",
+			"id":     subscribeID,
+			"name":   "stream-room-messages",
+			"params": []any{"__my_messages__", false},
+		},
+	}
+	for _, frame := range handshake {
+		if err := c.writeDDP(frame); err != nil {
+			return err
+		}
+	}
+
+	logger.InfoC("rocketchat", "WebSocket connected")
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var frame ddpFrame
+		if err := json.Unmarshal(data, &frame); err != nil {
+			continue
+		}
+
+		switch frame.Msg {
+		case "ping":
+			if err := c.writeDDP(map[string]string{"msg": "pong"}); err != nil {
+				return err
+			}
+		case "result":
+			if frame.ID == loginCallID && len(frame.Error) > 0 {
+				return fmt.Errorf("rocketchat realtime login failed: %s", frame.Error)
+			}
+		case "nosub":
+			if frame.ID == subscribeID {
+				return fmt.Errorf("rocketchat subscription rejected: %s", frame.Error)
+			}
+		case "changed":
+			if frame.Collection != "stream-room-messages" || len(frame.Fields.Args) == 0 {
+				continue
+			}
+			var msg rcMessage
+			if err := json.Unmarshal(frame.Fields.Args[0], &msg); err != nil {
+				continue
+			}
+			var room rcRoomInfo
+			if len(frame.Fields.Args) > 1 {
+				_ = json.Unmarshal(frame.Fields.Args[1], &room)
+			}
+			c.handleMessage(msg, room)
+		}
+	}
+}
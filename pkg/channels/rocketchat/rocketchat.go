@@ -0,0 +1,648 @@
+package rocketchat
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	// maxMessageLength is Rocket.Chat's default Message_MaxAllowedSize.
+	maxMessageLength     = 5000
+	maxTypingDuration    = 5 * time.Minute
+	streamThrottle       = time.Second
+	reactionEmoji        = ":eyes:"
+	defaultCommandPrefix = "!"
+	seenCapacity         = 512
+)
+
+// RocketChatChannel connects a bot user to a Rocket.Chat server. Inbound
+// messages arrive over the DDP realtime API; replies, edits, reactions and
+// uploads go through the REST API.
+type RocketChatChannel struct {
+	*channels.BaseChannel
+	bc        *config.Channel
+	config    *config.RocketChatSettings
+	baseURL   string
+	client    *http.Client
+	workspace string
+
+	authMu    sync.RWMutex
+	userID    string
+	authToken string
+	username  string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	connMu  sync.Mutex
+	conn    *websocket.Conn
+	callSeq atomic.Uint64
+
+	// commandNames holds the registered command names and aliases; only
+	// these are rewritten from CommandPrefix to "/".
+	cmdMu        sync.RWMutex
+	commandNames map[string]struct{}
+
+	// seen drops re-broadcasts of a message (e.g. on reaction changes).
+	seenMu    sync.Mutex
+	seen      map[string]struct{}
+	seenOrder []string
+}
+
+func NewRocketChatChannel(
+	bc *config.Channel,
+	cfg *config.RocketChatSettings,
+	messageBus *bus.MessageBus,
+) (*RocketChatChannel, error) {
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.URL), "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("rocketchat url is required")
+	}
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return nil, fmt.Errorf("rocketchat url must start with http:// or https://")
+	}
+	hasToken := cfg.UserID != "" && cfg.AuthToken.String() != ""
+	hasPassword := cfg.Username != "" && cfg.Password.String() != ""
+	if !hasToken && !hasPassword {
+		return nil, fmt.Errorf("rocketchat needs user_id and auth_token, or username and password")
+	}
+
+	base := channels.NewBaseChannel(config.ChannelRocketChat, cfg, messageBus, bc.AllowFrom,
+		channels.WithMaxMessageLength(maxMessageLength),
+		channels.WithGroupTrigger(bc.GroupTrigger),
+		channels.WithReasoningChannelID(bc.ReasoningChannelID),
+	)
+
+	return &RocketChatChannel{
+		BaseChannel:  base,
+		bc:           bc,
+		config:       cfg,
+		baseURL:      baseURL,
+		client:       &http.Client{Timeout: 30 * time.Second},
+		userID:       cfg.UserID,
+		authToken:    cfg.AuthToken.String(),
+		commandNames: make(map[string]struct{}),
+		seen:         make(map[string]struct{}),
+	}, nil
+}
+
+func (c *RocketChatChannel) Start(ctx context.Context) error {
+	logger.InfoC("rocketchat", "Starting Rocket.Chat channel")
+
+	c.ctx, c.cancel = context.WithCancel(ctx)
+
+	if c.config.AuthToken.String() == "" {
+		if err := c.login(c.ctx); err != nil {
+			c.cancel()
+			return fmt.Errorf("rocketchat login failed: %w", err)
+		}
+	}
+
+	var me rcUser
+	if err := c.do(c.ctx, http.MethodGet, "/me", nil, &me); err != nil {
+		c.cancel()
+		return fmt.Errorf("rocketchat auth failed: %w", err)
+	}
+	c.authMu.Lock()
+	c.userID = me.ID
+	c.username = me.Username
+	c.authMu.Unlock()
+
+	logger.InfoCF("rocketchat", "Rocket.Chat bot connected", map[string]any{
+		"user_id":  me.ID,
+		"username": me.Username,
+	})
+
+	if err := c.RegisterCommands(c.ctx, commands.WorkspaceDefinitions(c.workspace)); err != nil {
+		logger.WarnCF("rocketchat", "Failed to register commands", map[string]any{"error": err.Error()})
+	}
+
+	go c.runWebSocket()
+
+	c.SetRunning(true)
+	logger.InfoC("rocketchat", "Rocket.Chat channel started")
+	return nil
+}
+
+func (c *RocketChatChannel) Stop(ctx context.Context) error {
+	logger.InfoC("rocketchat", "Stopping Rocket.Chat channel")
+
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	c.connMu.Lock()
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+	c.connMu.Unlock()
+
+	c.SetRunning(false)
+	logger.InfoC("rocketchat", "Rocket.Chat channel stopped")
+	return nil
+}
+
+// RegisterCommands implements channels.CommandRegistrarCapable. Rocket.Chat
+// has no API for bot users to add slash commands, so the definitions are
+// exposed as CommandPrefix commands instead ("!help" becomes "/help").
+func (c *RocketChatChannel) RegisterCommands(ctx context.Context, defs []commands.Definition) error {
+	names := make(map[string]struct{}, len(defs))
+	for _, def := range defs {
+		if def.Name == "" {
+			continue
+		}
+		names[strings.ToLower(def.Name)] = struct{}{}
+		for _, alias := range def.Aliases {
+			names[strings.ToLower(alias)] = struct{}{}
+		}
+	}
+
+	c.cmdMu.Lock()
+	c.commandNames = names
+	c.cmdMu.Unlock()
+	return nil
+}
+
+func (c *RocketChatChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+
+	roomID, threadID := resolveOutboundTarget(msg.ChatID, &msg.Context)
+	if roomID == "" {
+		return nil, fmt.Errorf("invalid rocketchat chat ID: %s", msg.ChatID)
+	}
+	if threadID == "" && msg.ReplyToMessageID != "" {
+		threadID = msg.ReplyToMessageID
+	}
+
+	id, err := c.sendMessage(ctx, roomID, threadID, msg.Content)
+	if err != nil {
+		return nil, fmt.Errorf("rocketchat send: %w", err)
+	}
+
+	logger.DebugCF("rocketchat", "Message sent", map[string]any{
+		"room_id":   roomID,
+		"thread_id": threadID,
+	})
+	return []string{id}, nil
+}
+
+// SendMedia implements the channels.MediaSender interface.
+func (c *RocketChatChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+
+	roomID, threadID := resolveOutboundTarget(msg.ChatID, &msg.Context)
+	if roomID == "" {
+		return nil, fmt.Errorf("invalid rocketchat chat ID: %s", msg.ChatID)
+	}
+
+	store := c.GetMediaStore()
+	if store == nil {
+		return nil, fmt.Errorf("no media store available: %w", channels.ErrSendFailed)
+	}
+
+	var ids []string
+	for _, part := range msg.Parts {
+		localPath, err := store.Resolve(part.Ref)
+		if err != nil {
+			logger.ErrorCF("rocketchat", "Failed to resolve media ref", map[string]any{
+				"ref":   part.Ref,
+				"error": err.Error(),
+			})
+			continue
+		}
+
+		id, err := c.uploadFile(ctx, roomID, threadID, localPath, part.Filename, part.Caption)
+		if err != nil {
+			logger.ErrorCF("rocketchat", "Failed to upload media", map[string]any{
+				"filename": part.Filename,
+				"error":    err.Error(),
+			})
+			return nil, fmt.Errorf("rocketchat send media: %w", err)
+		}
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// EditMessage implements channels.MessageEditor.
+func (c *RocketChatChannel) EditMessage(ctx context.Context, chatID string, messageID string, content string) error {
+	roomID, _ := parseChatID(chatID)
+	if roomID == "" || strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("rocketchat room and message ID are required")
+	}
+	return c.updateMessage(ctx, roomID, messageID, content)
+}
+
+// DeleteMessage implements channels.MessageDeleter.
+func (c *RocketChatChannel) DeleteMessage(ctx context.Context, chatID string, messageID string) error {
+	roomID, _ := parseChatID(chatID)
+	if roomID == "" || strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("rocketchat room and message ID are required")
+	}
+	return c.deleteMessage(ctx, roomID, messageID)
+}
+
+// SendPlaceholder implements channels.PlaceholderCapable.
+func (c *RocketChatChannel) SendPlaceholder(ctx context.Context, chatID string) (string, error) {
+	if !c.bc.Placeholder.Enabled {
+		return "", nil
+	}
+
+	roomID, threadID := parseChatID(chatID)
+	if roomID == "" {
+		return "", fmt.Errorf("invalid rocketchat chat ID: %s", chatID)
+	}
+	return c.sendMessage(ctx, roomID, threadID, c.bc.Placeholder.GetRandomText())
+}
+
+// ReactToMessage implements channels.ReactionCapable.
+// It adds an "eyes" (👀) reaction to the inbound message and returns an undo
+// function that removes it.
+func (c *RocketChatChannel) ReactToMessage(ctx context.Context, chatID, messageID string) (func(), error) {
+	if messageID == "" {
+		return func() {}, nil
+	}
+	if err := c.react(ctx, messageID, true); err != nil {
+		return func() {}, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			_ = c.react(context.Background(), messageID, false)
+		})
+	}, nil
+}
+
+// StartTyping implements channels.TypingCapable through the realtime API's
+// user-activity stream.
+func (c *RocketChatChannel) StartTyping(ctx context.Context, chatID string) (func(), error) {
+	roomID, threadID := parseChatID(chatID)
+	if roomID == "" {
+		return func() {}, fmt.Errorf("invalid rocketchat chat ID: %s", chatID)
+	}
+
+	c.authMu.RLock()
+	username := c.username
+	c.authMu.RUnlock()
+
+	event := roomID + "/user-activity"
+	extra := map[string]string{}
+	if threadID != "" {
+		extra["tmid"] = threadID
+	}
+	if err := c.callMethod("stream-notify-room", event, username, []string{"user-typing"}, extra); err != nil {
+		return func() {}, err
+	}
+
+	typingCtx, cancel := context.WithCancel(ctx)
+	maxCtx, maxCancel := context.WithTimeout(typingCtx, maxTypingDuration)
+	go func() {
+		defer maxCancel()
+		ticker := time.NewTicker(4 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-maxCtx.Done():
+				_ = c.callMethod("stream-notify-room", event, username, []string{}, extra)
+				return
+			case <-ticker.C:
+				_ = c.callMethod("stream-notify-room", event, username, []string{"user-typing"}, extra)
+			}
+		}
+	}()
+
+	return cancel, nil
+}
+
+// BeginStream implements channels.StreamingCapable by sending the first
+// chunk and updating the same message as more output arrives.
+func (c *RocketChatChannel) BeginStream(ctx context.Context, chatID string) (channels.Streamer, error) {
+	if !c.config.Streaming {
+		return nil, fmt.Errorf("streaming disabled in config")
+	}
+
+	roomID, threadID := parseChatID(chatID)
+	if roomID == "" {
+		return nil, fmt.Errorf("invalid rocketchat chat ID: %s", chatID)
+	}
+	return &rocketChatStreamer{channel: c, roomID: roomID, threadID: threadID}, nil
+}
+
+// rocketChatStreamer shows partial output by editing a single message. If an
+// update fails it stops editing, and Finalize still delivers the final text.
+type rocketChatStreamer struct {
+	channel   *RocketChatChannel
+	roomID    string
+	threadID  string
+	messageID string
+	lastAt    time.Time
+	failed    bool
+	mu        sync.Mutex
+}
+
+func (s *rocketChatStreamer) Update(ctx context.Context, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failed || strings.TrimSpace(content) == "" {
+		return nil
+	}
+	now := time.Now()
+	if s.messageID != "" && now.Sub(s.lastAt) < streamThrottle {
+		return nil
+	}
+
+	var err error
+	if s.messageID == "" {
+		s.messageID, err = s.channel.sendMessage(ctx, s.roomID, s.threadID, content)
+	} else {
+		err = s.channel.updateMessage(ctx, s.roomID, s.messageID, content)
+	}
+	if err != nil {
+		logger.WarnCF("rocketchat", "Stream update failed, disabling streaming", map[string]any{
+			"error": err.Error(),
+		})
+		s.failed = true
+		return nil
+	}
+	s.lastAt = now
+	return nil
+}
+
+func (s *rocketChatStreamer) Finalize(ctx context.Context, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.messageID != "" {
+		if err := s.channel.updateMessage(ctx, s.roomID, s.messageID, content); err == nil {
+			return nil
+		}
+		// Replace the stale partial message rather than leave it half-written.
+		_ = s.channel.deleteMessage(ctx, s.roomID, s.messageID)
+		s.messageID = ""
+	}
+
+	id, err := s.channel.sendMessage(ctx, s.roomID, s.threadID, content)
+	if err != nil {
+		return fmt.Errorf("rocketchat finalize: %w", err)
+	}
+	s.messageID = id
+	return nil
+}
+
+func (s *rocketChatStreamer) Cancel(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.messageID != "" {
+		_ = s.channel.deleteMessage(ctx, s.roomID, s.messageID)
+		s.messageID = ""
+	}
+}
+
+// handleMessage turns a stream-room-messages event into an inbound message.
+func (c *RocketChatChannel) handleMessage(msg rcMessage, room rcRoomInfo) {
+	c.authMu.RLock()
+	botID, botUsername := c.userID, c.username
+	c.authMu.RUnlock()
+
+	// System messages carry a type; edits and reaction updates re-broadcast
+	// an existing message.
+	if msg.ID == "" || msg.User.ID == "" || msg.User.ID == botID || msg.Type != "" || msg.EditedAt != nil {
+		return
+	}
+	if !c.markSeen(msg.ID) {
+		return
+	}
+
+	sender := bus.SenderInfo{
+		Platform:    "rocketchat",
+		PlatformID:  msg.User.ID,
+		CanonicalID: identity.BuildCanonicalID("rocketchat", msg.User.ID),
+		Username:    msg.User.Username,
+		DisplayName: msg.User.Name,
+	}
+	if !c.IsAllowedSender(sender) {
+		logger.DebugCF("rocketchat", "Message rejected by allowlist", map[string]any{
+			"user_id": msg.User.ID,
+		})
+		return
+	}
+
+	chatType := chatTypeFor(room.RoomType)
+	mentioned := false
+	for _, m := range msg.Mentions {
+		if m.ID == botID || (botUsername != "" && strings.EqualFold(m.Username, botUsername)) {
+			mentioned = true
+			break
+		}
+	}
+	text := stripMention(msg.Msg, botUsername)
+	content := c.rewriteCommand(text)
+	// A prefixed command addresses the bot as directly as a mention does.
+	isCommand := content != text
+
+	if chatType != "direct" {
+		respond, cleaned := c.ShouldRespondInGroup(mentioned || isCommand, content)
+		if !respond {
+			return
+		}
+		content = cleaned
+	}
+
+	chatID := msg.RoomID
+	if msg.ThreadID != "" {
+		chatID = msg.RoomID + "/" + msg.ThreadID
+	}
+
+	var mediaPaths []string
+	scope := channels.BuildMediaScope("rocketchat", chatID, msg.ID)
+	for _, att := range msg.Attachments {
+		localPath, name := c.downloadAttachment(att)
+		if localPath == "" {
+			continue
+		}
+		mediaPaths = append(mediaPaths, c.storeMedia(localPath, name, scope))
+		content += fmt.Sprintf("\n[file: %s]", name)
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+
+	logger.DebugCF("rocketchat", "Received message", map[string]any{
+		"sender_id": msg.User.ID,
+		"chat_id":   chatID,
+		"preview":   utils.Truncate(content, 50),
+	})
+
+	inboundCtx := bus.InboundContext{
+		Channel:   c.Name(),
+		Account:   botID,
+		ChatID:    msg.RoomID,
+		ChatType:  chatType,
+		TopicID:   msg.ThreadID,
+		SenderID:  msg.User.ID,
+		MessageID: msg.ID,
+		Mentioned: mentioned,
+		Raw: map[string]string{
+			"message_id": msg.ID,
+			"room_id":    msg.RoomID,
+			"room_name":  room.RoomName,
+			"room_type":  room.RoomType,
+			"tmid":       msg.ThreadID,
+			"platform":   "rocketchat",
+		},
+	}
+
+	c.HandleInboundContext(c.ctx, chatID, content, mediaPaths, inboundCtx, sender)
+}
+
+// markSeen records id and reports whether it was new.
+func (c *RocketChatChannel) markSeen(id string) bool {
+	c.seenMu.Lock()
+	defer c.seenMu.Unlock()
+
+	if _, ok := c.seen[id]; ok {
+		return false
+	}
+	c.seen[id] = struct{}{}
+	c.seenOrder = append(c.seenOrder, id)
+	if len(c.seenOrder) > seenCapacity {
+		delete(c.seen, c.seenOrder[0])
+		c.seenOrder = c.seenOrder[1:]
+	}
+	return true
+}
+
+// rewriteCommand turns "<prefix>name args" into "/name args" when name is a
+// registered command, since Rocket.Chat clients reject unknown slash commands.
+func (c *RocketChatChannel) rewriteCommand(content string) string {
+	prefix := c.config.CommandPrefix
+	if prefix == "" {
+		prefix = defaultCommandPrefix
+	}
+	rest, ok := strings.CutPrefix(content, prefix)
+	if !ok || prefix == "/" {
+		return content
+	}
+
+	name, _, _ := strings.Cut(rest, " ")
+	c.cmdMu.RLock()
+	_, known := c.commandNames[strings.ToLower(name)]
+	c.cmdMu.RUnlock()
+	if !known {
+		return content
+	}
+	return "/" + rest
+}
+
+func (c *RocketChatChannel) downloadAttachment(att rcAttachment) (string, string) {
+	link := cmp.Or(att.TitleLink, att.ImageURL, att.AudioURL, att.VideoURL)
+	if link == "" {
+		return "", ""
+	}
+	if strings.HasPrefix(link, "/") {
+		link = c.baseURL + link
+	} else if !strings.HasPrefix(link, c.baseURL+"/") {
+		// Only fetch files hosted by the server itself with our credentials.
+		return "", ""
+	}
+
+	name := att.Title
+	if name == "" {
+		name = link[strings.LastIndex(link, "/")+1:]
+	}
+
+	c.authMu.RLock()
+	headers := map[string]string{"X-User-Id": c.userID, "X-Auth-Token": c.authToken}
+	c.authMu.RUnlock()
+
+	localPath := utils.DownloadFile(link, name, utils.DownloadOptions{
+		LoggerPrefix: "rocketchat",
+		ExtraHeaders: headers,
+	})
+	return localPath, name
+}
+
+func (c *RocketChatChannel) storeMedia(localPath, filename, scope string) string {
+	if store := c.GetMediaStore(); store != nil {
+		ref, err := store.Store(localPath, media.MediaMeta{
+			Filename:      filename,
+			Source:        "rocketchat",
+			CleanupPolicy: media.CleanupPolicyDeleteOnCleanup,
+		}, scope)
+		if err == nil {
+			return ref
+		}
+	}
+	return localPath
+}
+
+func stripMention(text, username string) string {
+	if username == "" {
+		return strings.TrimSpace(text)
+	}
+	mention := "@" + strings.ToLower(username)
+	for {
+		idx := strings.Index(strings.ToLower(text), mention)
+		if idx < 0 {
+			break
+		}
+		text = text[:idx] + text[idx+len(mention):]
+	}
+	return strings.TrimSpace(text)
+}
+
+// chatTypeFor maps Rocket.Chat room types (d, c, p, l) to peer kinds.
+func chatTypeFor(roomType string) string {
+	switch roomType {
+	case "d", "l":
+		return "direct"
+	default:
+		return "channel"
+	}
+}
+
+// parseChatID splits "roomID" or "roomID/threadID".
+func parseChatID(chatID string) (roomID, threadID string) {
+	roomID, threadID, _ = strings.Cut(strings.TrimSpace(chatID), "/")
+	return roomID, threadID
+}
+
+func resolveOutboundTarget(chatID string, outboundCtx *bus.InboundContext) (roomID, threadID string) {
+	target := strings.TrimSpace(chatID)
+	if target == "" && outboundCtx != nil {
+		target = strings.TrimSpace(outboundCtx.ChatID)
+	}
+	roomID, threadID = parseChatID(target)
+	if threadID == "" && outboundCtx != nil {
+		threadID = strings.TrimSpace(outboundCtx.TopicID)
+	}
+	return roomID, threadID
+}
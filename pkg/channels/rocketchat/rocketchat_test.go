@@ -0,0 +1,291 @@
+package rocketchat
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeServer is a minimal Rocket.Chat REST + DDP stand-in.
+type fakeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string // "path body"
+	frames   []string // DDP frames received from the client
+	events   chan string
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	fs := &fakeServer{events: make(chan string, 4)}
+	upgrader := websocket.Upgrader{}
+
+	authed := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("X-User-Id") != "bot1" || r.Header.Get("X-Auth-Token") != "session" {
+			http.Error(w, `{"success":false,"error":"unauthorized"}`, http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["user"] != "picobot" || body["password"] != "pw" {
+			http.Error(w, `{"status":"error","error":"Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"userId":"bot1","authToken":"session"}}`))
+	})
+	mux.HandleFunc("GET /api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		if authed(w, r) {
+			_, _ = w.Write([]byte(`{"_id":"bot1","username":"picobot","success":true}`))
+		}
+	})
+	for _, path := range []string{"chat.sendMessage", "chat.update", "chat.delete", "chat.react"} {
+		mux.HandleFunc("POST /api/v1/"+path, func(w http.ResponseWriter, r *http.Request) {
+			if !authed(w, r) {
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			fs.mu.Lock()
+			fs.requests = append(fs.requests, path+" "+strings.TrimSpace(string(body)))
+			fs.mu.Unlock()
+			_, _ = w.Write([]byte(`{"success":true,"message":{"_id":"msg1"}}`))
+		})
+	}
+	mux.HandleFunc("GET /websocket", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// connect, login, sub
+		for range 3 {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			fs.mu.Lock()
+			fs.frames = append(fs.frames, string(data))
+			fs.mu.Unlock()
+		}
+		_ = conn.WriteJSON(map[string]any{"msg": "ping"})
+		for ev := range fs.events {
+			if conn.WriteMessage(websocket.TextMessage, []byte(ev)) != nil {
+				return
+			}
+		}
+	})
+
+	fs.Server = httptest.NewServer(mux)
+	t.Cleanup(func() {
+		close(fs.events)
+		fs.Close()
+	})
+	return fs
+}
+
+func (fs *fakeServer) recorded() []string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return append([]string(nil), fs.requests...)
+}
+
+func newTestChannel(t *testing.T, fs *fakeServer, settings config.RocketChatSettings) (*RocketChatChannel, *bus.MessageBus) {
+	t.Helper()
+	mb := bus.NewMessageBus()
+	t.Cleanup(mb.Close)
+
+	settings.URL = fs.URL
+	settings.Username = "picobot"
+	settings.Password = *config.NewSecureString("pw")
+	ch, err := NewRocketChatChannel(&config.Channel{}, &settings, mb)
+	if err != nil {
+		t.Fatalf("NewRocketChatChannel: %v", err)
+	}
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+	return ch, mb
+}
+
+func changedEvent(t *testing.T, msg rcMessage, roomType string) string {
+	t.Helper()
+	data, _ := json.Marshal(map[string]any{
+		"msg":        "changed",
+		"collection": "stream-room-messages",
+		"id":         "id",
+		"fields": map[string]any{
+			"eventName": "__my_messages__",
+			"args":      []any{msg, rcRoomInfo{RoomType: roomType, RoomName: "general"}},
+		},
+	})
+	return string(data)
+}
+
+func TestNewRocketChatChannel_Validation(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	cases := map[string]config.RocketChatSettings{
+		"missing url":         {UserID: "u", AuthToken: *config.NewSecureString("t")},
+		"bad scheme":          {URL: "chat.example.com", UserID: "u", AuthToken: *config.NewSecureString("t")},
+		"missing credentials": {URL: "https://chat.example.com"},
+		"token without user":  {URL: "https://chat.example.com", AuthToken: *config.NewSecureString("t")},
+	}
+	for name, cfg := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewRocketChatChannel(&config.Channel{}, &cfg, mb); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestRealtimeMessageMapsThreadToTopic(t *testing.T) {
+	fs := newFakeServer(t)
+	_, mb := newTestChannel(t, fs, config.RocketChatSettings{})
+
+	msg := rcMessage{
+		ID:       "m1",
+		RoomID:   "room1",
+		ThreadID: "t1",
+		Msg:      "@picobot status?",
+		User:     rcUser{ID: "u1", Username: "alice"},
+		Mentions: []rcUser{{ID: "bot1", Username: "picobot"}},
+	}
+	// Own and system messages are ignored; a re-broadcast is delivered once.
+	fs.events <- changedEvent(t, rcMessage{ID: "m0", RoomID: "room1", Msg: "echo", User: rcUser{ID: "bot1"}}, "c")
+	fs.events <- changedEvent(t, rcMessage{ID: "s1", RoomID: "room1", Type: "uj", User: rcUser{ID: "u1"}}, "c")
+	fs.events <- changedEvent(t, msg, "c")
+	fs.events <- changedEvent(t, msg, "c")
+
+	select {
+	case in := <-mb.InboundChan():
+		if in.ChatID != "room1" || in.Context.TopicID != "t1" {
+			t.Fatalf("chat = %q topic = %q, want room1 and t1", in.ChatID, in.Context.TopicID)
+		}
+		if in.Content != "status?" || !in.Context.Mentioned || in.Context.ChatType != "channel" {
+			t.Fatalf("inbound = %q %+v", in.Content, in.Context)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no inbound message published")
+	}
+
+	select {
+	case in := <-mb.InboundChan():
+		t.Fatalf("duplicate inbound message: %q", in.Content)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	fs.mu.Lock()
+	frames := append([]string(nil), fs.frames...)
+	fs.mu.Unlock()
+	if len(frames) != 3 || !strings.Contains(frames[1], `"resume":"session"`) ||
+		!strings.Contains(frames[2], "__my_messages__") {
+		t.Fatalf("handshake frames = %v", frames)
+	}
+}
+
+func TestRewriteCommand(t *testing.T) {
+	ch := &RocketChatChannel{config: &config.RocketChatSettings{}, commandNames: map[string]struct{}{}}
+	_ = ch.RegisterCommands(context.Background(), []commands.Definition{
+		{Name: "help", Description: "Show help"},
+		{Name: "clear", Aliases: []string{"reset"}},
+	})
+
+	tests := map[string]string{
+		"!help":          "/help",
+		"!reset now":     "/reset now",
+		"!important":     "!important",
+		"hello !help":    "hello !help",
+		"/already slash": "/already slash",
+	}
+	for in, want := range tests {
+		if got := ch.rewriteCommand(in); got != want {
+			t.Errorf("rewriteCommand(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	ch.config.CommandPrefix = "."
+	if got := ch.rewriteCommand(".help"); got != "/help" {
+		t.Errorf("custom prefix: got %q", got)
+	}
+}
+
+func TestSendRepliesInThread(t *testing.T) {
+	fs := newFakeServer(t)
+	ch, _ := newTestChannel(t, fs, config.RocketChatSettings{})
+
+	ids, err := ch.Send(context.Background(), bus.OutboundMessage{
+		ChatID:  "room1",
+		Context: bus.InboundContext{ChatID: "room1", TopicID: "t1"},
+		Content: "hello",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "msg1" {
+		t.Fatalf("ids = %v", ids)
+	}
+
+	reqs := fs.recorded()
+	if len(reqs) != 1 || reqs[0] != `chat.sendMessage {"message":{"msg":"hello","rid":"room1","tmid":"t1"}}` {
+		t.Fatalf("requests = %v", reqs)
+	}
+}
+
+func TestStreamerSendsThenUpdates(t *testing.T) {
+	fs := newFakeServer(t)
+	ch, _ := newTestChannel(t, fs, config.RocketChatSettings{Streaming: true})
+
+	s, err := ch.BeginStream(context.Background(), "room1")
+	if err != nil {
+		t.Fatalf("BeginStream: %v", err)
+	}
+	ctx := context.Background()
+	_ = s.Update(ctx, "par")
+	_ = s.Update(ctx, "parti") // throttled
+	if err := s.Finalize(ctx, "partial done"); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+
+	reqs := fs.recorded()
+	if len(reqs) != 2 || !strings.HasPrefix(reqs[0], "chat.sendMessage ") ||
+		reqs[1] != `chat.update {"msgId":"msg1","roomId":"room1","text":"partial done"}` {
+		t.Fatalf("requests = %v", reqs)
+	}
+}
+
+func TestReactToMessageUndoIsIdempotent(t *testing.T) {
+	fs := newFakeServer(t)
+	ch, _ := newTestChannel(t, fs, config.RocketChatSettings{})
+
+	undo, err := ch.ReactToMessage(context.Background(), "room1", "m1")
+	if err != nil {
+		t.Fatalf("ReactToMessage: %v", err)
+	}
+	undo()
+	undo()
+
+	reqs := fs.recorded()
+	if len(reqs) != 2 || !strings.Contains(reqs[0], `"shouldReact":true`) ||
+		!strings.Contains(reqs[1], `"shouldReact":false`) {
+		t.Fatalf("requests = %v", reqs)
+	}
+}
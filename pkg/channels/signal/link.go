@@ -0,0 +1,51 @@
+package signal
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// StartLink asks the signal-cli daemon at url to begin linking a new device
+// and returns the sgnl://linkdevice URI to show as a QR code. The daemon must
+// run in multi-account mode (without -a) to accept link requests.
+func StartLink(ctx context.Context, url string) (string, error) {
+	rpc, err := newRPCClient(url)
+	if err != nil {
+		return "", err
+	}
+	defer rpc.close()
+
+	var result struct {
+		DeviceLinkURI string `json:"deviceLinkUri"`
+	}
+	if err := rpc.call(ctx, "startLink", nil, &result); err != nil {
+		return "", err
+	}
+	if result.DeviceLinkURI == "" {
+		return "", errors.New("signal-cli returned no device link URI")
+	}
+	return result.DeviceLinkURI, nil
+}
+
+// FinishLink waits until the link URI has been scanned from the primary
+// device and returns the phone number of the linked account.
+func FinishLink(ctx context.Context, url, uri, deviceName string) (string, error) {
+	rpc, err := newRPCClient(url)
+	if err != nil {
+		return "", err
+	}
+	defer rpc.close()
+
+	params := map[string]any{"deviceLinkUri": uri}
+	if name := strings.TrimSpace(deviceName); name != "" {
+		params["deviceName"] = name
+	}
+	var result struct {
+		Number string `json:"number"`
+	}
+	if err := rpc.call(ctx, "finishLink", params, &result); err != nil {
+		return "", err
+	}
+	return result.Number, nil
+}
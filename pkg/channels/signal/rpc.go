@@ -0,0 +1,354 @@
+package signal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+)
+
+// DefaultURL is where `signal-cli daemon --http` listens by default.
+const DefaultURL = "http://127.0.0.1:8080"
+
+// maxFrameSize bounds one JSON-RPC frame; attachments travel base64-encoded.
+const maxFrameSize = 150 << 20
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("signal-cli error %d: %s", e.Code, e.Message)
+}
+
+// rpcFrame is a JSON-RPC response or notification.
+type rpcFrame struct {
+	ID     string          `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *rpcError       `json:"error,omitempty"`
+}
+
+// notifyFunc receives server notifications such as "receive".
+type notifyFunc func(method string, params json.RawMessage)
+
+// rpcClient is a JSON-RPC connection to a signal-cli daemon.
+type rpcClient interface {
+	// call invokes method and decodes its result into out when non-nil.
+	call(ctx context.Context, method string, params, out any) error
+	// listen delivers notifications until ctx ends or the stream breaks.
+	listen(ctx context.Context, notify notifyFunc) error
+	close()
+}
+
+// newRPCClient picks the transport from the URL scheme.
+func newRPCClient(rawURL string) (rpcClient, error) {
+	if rawURL == "" {
+		rawURL = DefaultURL
+	}
+	switch {
+	case strings.HasPrefix(rawURL, "http://"), strings.HasPrefix(rawURL, "https://"):
+		return &httpClient{baseURL: strings.TrimRight(rawURL, "/"), client: &http.Client{}}, nil
+	case strings.HasPrefix(rawURL, "tcp://"):
+		return newStreamClient("tcp", strings.TrimPrefix(rawURL, "tcp://")), nil
+	case strings.HasPrefix(rawURL, "unix://"):
+		return newStreamClient("unix", strings.TrimPrefix(rawURL, "unix://")), nil
+	default:
+		return nil, fmt.Errorf("signal url %q must start with http://, https://, tcp:// or unix://", rawURL)
+	}
+}
+
+var requestSeq atomic.Uint64
+
+func newRequest(method string, params any) map[string]any {
+	req := map[string]any{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"id":      strconv.FormatUint(requestSeq.Add(1), 10),
+	}
+	if params != nil {
+		req["params"] = params
+	}
+	return req
+}
+
+func decodeResult(frame rpcFrame, out any) error {
+	if frame.Error != nil {
+		return frame.Error
+	}
+	if out == nil || len(frame.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(frame.Result, out)
+}
+
+// httpClient talks to `signal-cli daemon --http`: calls are POSTed to
+// /api/v1/rpc and notifications stream from /api/v1/events as SSE.
+type httpClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func (c *httpClient) call(ctx context.Context, method string, params, out any) error {
+	body, err := json.Marshal(newRequest(method, params))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/rpc", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return channels.ClassifyNetError(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFrameSize))
+	if err != nil {
+		return channels.ClassifyNetError(err)
+	}
+	var frame rpcFrame
+	if err := json.Unmarshal(data, &frame); err != nil {
+		if resp.StatusCode >= http.StatusBadRequest {
+			return channels.ClassifySendError(resp.StatusCode,
+				fmt.Errorf("signal-cli %s: %d %s", method, resp.StatusCode, strings.TrimSpace(string(data))))
+		}
+		return fmt.Errorf("signal-cli %s: invalid response: %w", method, err)
+	}
+	return decodeResult(frame, out)
+}
+
+func (c *httpClient) listen(ctx context.Context, notify notifyFunc) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/events", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("signal-cli events: unexpected status %d", resp.StatusCode)
+	}
+
+	reader := bufio.NewReaderSize(resp.Body, 64<<10)
+	var event string
+	var data bytes.Buffer
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				dispatchEvent(event, data.Bytes(), notify)
+			}
+			event = ""
+			data.Reset()
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+}
+
+// dispatchEvent accepts both bare notification params and full JSON-RPC
+// notifications as SSE payloads.
+func dispatchEvent(event string, data []byte, notify notifyFunc) {
+	var frame rpcFrame
+	if json.Unmarshal(data, &frame) == nil && frame.Method != "" {
+		notify(frame.Method, frame.Params)
+		return
+	}
+	if event == "" {
+		event = "receive"
+	}
+	notify(event, append(json.RawMessage(nil), data...))
+}
+
+func (c *httpClient) close() {}
+
+// streamClient talks newline-delimited JSON-RPC over the daemon's TCP or
+// UNIX socket. Responses are matched to calls by ID.
+type streamClient struct {
+	network string
+	address string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	done    chan struct{}
+	err     error
+	pending map[string]chan rpcFrame
+	notify  notifyFunc
+}
+
+func newStreamClient(network, address string) *streamClient {
+	return &streamClient{network: network, address: address, pending: make(map[string]chan rpcFrame)}
+}
+
+// connect returns the live connection, dialing a new one when needed.
+func (c *streamClient) connect(ctx context.Context) (net.Conn, chan struct{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		return c.conn, c.done, nil
+	}
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, nil, channels.ClassifyNetError(err)
+	}
+	c.conn = conn
+	c.done = make(chan struct{})
+	go c.readLoop(conn, c.done)
+	return conn, c.done, nil
+}
+
+func (c *streamClient) readLoop(conn net.Conn, done chan struct{}) {
+	reader := bufio.NewReaderSize(conn, 64<<10)
+	var err error
+	for {
+		var line []byte
+		line, err = reader.ReadBytes('\n')
+		if err != nil {
+			break
+		}
+		if len(line) > maxFrameSize {
+			err = errors.New("signal-cli frame too large")
+			break
+		}
+		var frame rpcFrame
+		if json.Unmarshal(line, &frame) != nil {
+			continue
+		}
+
+		c.mu.Lock()
+		if frame.Method != "" {
+			notify := c.notify
+			c.mu.Unlock()
+			if notify != nil {
+				notify(frame.Method, frame.Params)
+			}
+			continue
+		}
+		ch, ok := c.pending[frame.ID]
+		delete(c.pending, frame.ID)
+		c.mu.Unlock()
+		if ok {
+			ch <- frame
+		}
+	}
+
+	c.mu.Lock()
+	if c.conn == conn {
+		c.conn = nil
+	}
+	c.err = err
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+	c.mu.Unlock()
+	conn.Close()
+	close(done)
+}
+
+func (c *streamClient) call(ctx context.Context, method string, params, out any) error {
+	conn, done, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := newRequest(method, params)
+	id := req["id"].(string)
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	reply := make(chan rpcFrame, 1)
+
+	c.mu.Lock()
+	c.pending[id] = reply
+	_ = conn.SetWriteDeadline(time.Now().Add(30 * time.Second))
+	_, err = conn.Write(append(data, '\n'))
+	c.mu.Unlock()
+	if err != nil {
+		conn.Close()
+		return channels.ClassifyNetError(err)
+	}
+
+	select {
+	case frame, ok := <-reply:
+		if !ok {
+			return channels.ClassifyNetError(errors.New("signal-cli connection closed"))
+		}
+		return decodeResult(frame, out)
+	case <-done:
+		return channels.ClassifyNetError(errors.New("signal-cli connection closed"))
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (c *streamClient) listen(ctx context.Context, notify notifyFunc) error {
+	c.mu.Lock()
+	c.notify = notify
+	c.mu.Unlock()
+
+	conn, done, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		conn.Close()
+		return ctx.Err()
+	case <-done:
+		c.mu.Lock()
+		err := c.err
+		c.mu.Unlock()
+		if err == nil {
+			err = io.EOF
+		}
+		return err
+	}
+}
+
+func (c *streamClient) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
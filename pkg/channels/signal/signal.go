@@ -0,0 +1,710 @@
+package signal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	// maxMessageLength keeps replies under the size Signal clients send
+	// inline rather than as a long-text attachment.
+	maxMessageLength  = 2000
+	maxTypingDuration = 5 * time.Minute
+	// Signal clients expire typing indicators after 15 seconds.
+	typingRefresh  = 10 * time.Second
+	reactionEmoji  = "👀"
+	groupPrefix    = "group:"
+	maxBackoff     = time.Minute
+	mentionReplace = "￼"
+)
+
+type signalAddress struct {
+	Number string `json:"number,omitempty"`
+	UUID   string `json:"uuid,omitempty"`
+}
+
+type signalAttachment struct {
+	ID          string `json:"id"`
+	ContentType string `json:"contentType"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	VoiceNote   bool   `json:"voiceNote"`
+}
+
+type signalMention struct {
+	Name   string `json:"name"`
+	Number string `json:"number"`
+	UUID   string `json:"uuid"`
+	Start  int    `json:"start"`
+	Length int    `json:"length"`
+}
+
+type signalQuote struct {
+	ID           int64  `json:"id"`
+	Author       string `json:"author"`
+	AuthorNumber string `json:"authorNumber"`
+	AuthorUUID   string `json:"authorUuid"`
+	Text         string `json:"text"`
+}
+
+type signalDataMessage struct {
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+	GroupInfo *struct {
+		GroupID string `json:"groupId"`
+	} `json:"groupInfo"`
+	Quote        *signalQuote       `json:"quote"`
+	Attachments  []signalAttachment `json:"attachments"`
+	Mentions     []signalMention    `json:"mentions"`
+	Reaction     json.RawMessage    `json:"reaction"`
+	RemoteDelete json.RawMessage    `json:"remoteDelete"`
+}
+
+type signalEnvelope struct {
+	Source       string             `json:"source"`
+	SourceNumber string             `json:"sourceNumber"`
+	SourceUUID   string             `json:"sourceUuid"`
+	SourceName   string             `json:"sourceName"`
+	Timestamp    int64              `json:"timestamp"`
+	DataMessage  *signalDataMessage `json:"dataMessage"`
+}
+
+type receiveParams struct {
+	Account  string         `json:"account"`
+	Envelope signalEnvelope `json:"envelope"`
+}
+
+// SignalChannel relays messages through a signal-cli daemon linked to (or
+// registered as) the bot's Signal account.
+type SignalChannel struct {
+	*channels.BaseChannel
+	config *config.SignalSettings
+	rpc    rpcClient
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Notifications are queued and handled on a worker goroutine so that
+	// handlers can issue RPC calls on the connection that delivered them.
+	queueMu     sync.Mutex
+	queue       []json.RawMessage
+	queueSignal chan struct{}
+}
+
+func NewSignalChannel(
+	bc *config.Channel,
+	cfg *config.SignalSettings,
+	messageBus *bus.MessageBus,
+) (*SignalChannel, error) {
+	if strings.TrimSpace(cfg.Account) == "" {
+		return nil, fmt.Errorf("signal account is required (run `picoclaw auth signal` to link a device)")
+	}
+	rpc, err := newRPCClient(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	base := channels.NewBaseChannel(config.ChannelSignal, cfg, messageBus, bc.AllowFrom,
+		channels.WithMaxMessageLength(maxMessageLength),
+		channels.WithGroupTrigger(bc.GroupTrigger),
+		channels.WithReasoningChannelID(bc.ReasoningChannelID),
+	)
+
+	return &SignalChannel{
+		BaseChannel: base,
+		config:      cfg,
+		rpc:         rpc,
+		queueSignal: make(chan struct{}, 1),
+	}, nil
+}
+
+func (c *SignalChannel) Start(ctx context.Context) error {
+	logger.InfoCF("signal", "Starting Signal channel", map[string]any{
+		"account": c.config.Account,
+	})
+
+	c.ctx, c.cancel = context.WithCancel(ctx)
+
+	go c.worker()
+	go c.receiveLoop()
+
+	c.SetRunning(true)
+	logger.InfoC("signal", "Signal channel started")
+	return nil
+}
+
+func (c *SignalChannel) Stop(ctx context.Context) error {
+	logger.InfoC("signal", "Stopping Signal channel")
+
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.rpc.close()
+
+	c.SetRunning(false)
+	logger.InfoC("signal", "Signal channel stopped")
+	return nil
+}
+
+func (c *SignalChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+
+	params, err := c.target(msg.ChatID)
+	if err != nil {
+		return nil, err
+	}
+	params["message"] = msg.Content
+	c.addQuote(params, msg.ReplyToMessageID)
+
+	id, err := c.send(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("signal send: %w", err)
+	}
+	return []string{id}, nil
+}
+
+// SendMedia implements the channels.MediaSender interface. Files are sent
+// inline as data URIs so the daemon does not need access to local paths.
+func (c *SignalChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+
+	store := c.GetMediaStore()
+	if store == nil {
+		return nil, fmt.Errorf("no media store available: %w", channels.ErrSendFailed)
+	}
+
+	var attachments []string
+	var captions []string
+	for _, part := range msg.Parts {
+		localPath, err := store.Resolve(part.Ref)
+		if err != nil {
+			logger.ErrorCF("signal", "Failed to resolve media ref", map[string]any{
+				"ref":   part.Ref,
+				"error": err.Error(),
+			})
+			continue
+		}
+		uri, err := dataURI(localPath, part.Filename, part.ContentType)
+		if err != nil {
+			logger.ErrorCF("signal", "Failed to read media", map[string]any{
+				"path":  localPath,
+				"error": err.Error(),
+			})
+			continue
+		}
+		attachments = append(attachments, uri)
+		if part.Caption != "" {
+			captions = append(captions, part.Caption)
+		}
+	}
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+
+	params, err := c.target(msg.ChatID)
+	if err != nil {
+		return nil, err
+	}
+	params["attachments"] = attachments
+	if len(captions) > 0 {
+		params["message"] = strings.Join(captions, "\n")
+	}
+
+	id, err := c.send(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("signal send media: %w", err)
+	}
+	return []string{id}, nil
+}
+
+// ReactToMessage implements channels.ReactionCapable.
+// It adds an "eyes" (👀) reaction to the inbound message and returns an undo
+// function that removes it.
+func (c *SignalChannel) ReactToMessage(ctx context.Context, chatID, messageID string) (func(), error) {
+	author, ts, ok := parseMessageID(messageID)
+	if !ok {
+		return func() {}, nil
+	}
+	params, err := c.target(chatID)
+	if err != nil {
+		return func() {}, err
+	}
+	params["emoji"] = reactionEmoji
+	params["targetAuthor"] = author
+	params["targetTimestamp"] = ts
+
+	if err := c.rpc.call(ctx, "sendReaction", params, nil); err != nil {
+		return func() {}, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			params["remove"] = true
+			_ = c.rpc.call(context.Background(), "sendReaction", params, nil)
+		})
+	}, nil
+}
+
+// StartTyping implements channels.TypingCapable.
+func (c *SignalChannel) StartTyping(ctx context.Context, chatID string) (func(), error) {
+	params, err := c.target(chatID)
+	if err != nil {
+		return func() {}, err
+	}
+	if err := c.rpc.call(ctx, "sendTyping", params, nil); err != nil {
+		return func() {}, err
+	}
+
+	typingCtx, cancel := context.WithCancel(ctx)
+	maxCtx, maxCancel := context.WithTimeout(typingCtx, maxTypingDuration)
+	go func() {
+		defer maxCancel()
+		ticker := time.NewTicker(typingRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-maxCtx.Done():
+				stop := withParam(params, "stop", true)
+				_ = c.rpc.call(context.Background(), "sendTyping", stop, nil)
+				return
+			case <-ticker.C:
+				_ = c.rpc.call(maxCtx, "sendTyping", params, nil)
+			}
+		}
+	}()
+
+	return cancel, nil
+}
+
+// target builds the recipient parameters for a chat ID: a phone number or
+// UUID for direct chats, or "group:<id>" for groups.
+func (c *SignalChannel) target(chatID string) (map[string]any, error) {
+	chatID = strings.TrimSpace(chatID)
+	params := map[string]any{"account": c.config.Account}
+	switch {
+	case chatID == "":
+		return nil, fmt.Errorf("signal chat ID is empty: %w", channels.ErrSendFailed)
+	case strings.HasPrefix(chatID, groupPrefix):
+		params["groupId"] = strings.TrimPrefix(chatID, groupPrefix)
+	default:
+		params["recipient"] = []string{chatID}
+	}
+	return params, nil
+}
+
+// addQuote quotes the message replyTo refers to, if it is a Signal message ID.
+func (c *SignalChannel) addQuote(params map[string]any, replyTo string) {
+	if author, ts, ok := parseMessageID(replyTo); ok {
+		params["quoteAuthor"] = author
+		params["quoteTimestamp"] = ts
+	}
+}
+
+func (c *SignalChannel) send(ctx context.Context, params map[string]any) (string, error) {
+	var result struct {
+		Timestamp int64 `json:"timestamp"`
+	}
+	if err := c.rpc.call(ctx, "send", params, &result); err != nil {
+		return "", err
+	}
+	return formatMessageID(c.config.Account, result.Timestamp), nil
+}
+
+// receiveLoop keeps the notification stream open until the channel stops,
+// reconnecting with exponential backoff.
+func (c *SignalChannel) receiveLoop() {
+	backoff := time.Second
+	for c.ctx.Err() == nil {
+		started := time.Now()
+		err := c.rpc.listen(c.ctx, func(method string, params json.RawMessage) {
+			if method == "receive" {
+				c.enqueue(params)
+			}
+		})
+		if c.ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > maxBackoff {
+			backoff = time.Second
+		}
+		logger.WarnCF("signal", "signal-cli event stream closed; reconnecting", map[string]any{
+			"error":       fmt.Sprint(err),
+			"retry_after": backoff.String(),
+		})
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func (c *SignalChannel) enqueue(params json.RawMessage) {
+	c.queueMu.Lock()
+	c.queue = append(c.queue, params)
+	c.queueMu.Unlock()
+
+	select {
+	case c.queueSignal <- struct{}{}:
+	default:
+	}
+}
+
+func (c *SignalChannel) worker() {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-c.queueSignal:
+		}
+
+		for {
+			c.queueMu.Lock()
+			if len(c.queue) == 0 {
+				c.queueMu.Unlock()
+				break
+			}
+			params := c.queue[0]
+			c.queue = c.queue[1:]
+			c.queueMu.Unlock()
+
+			var rp receiveParams
+			if err := json.Unmarshal(params, &rp); err != nil {
+				logger.WarnCF("signal", "Failed to decode receive notification", map[string]any{
+					"error": err.Error(),
+				})
+				continue
+			}
+			c.handleEnvelope(rp)
+		}
+	}
+}
+
+// handleEnvelope turns an incoming data message into an inbound message.
+func (c *SignalChannel) handleEnvelope(rp receiveParams) {
+	if rp.Account != "" && rp.Account != c.config.Account {
+		return
+	}
+	env := rp.Envelope
+	dm := env.DataMessage
+	// Receipts, typing and sync messages carry no data message; reactions
+	// and deletions are not prompts.
+	if dm == nil || len(dm.Reaction) > 0 || len(dm.RemoteDelete) > 0 {
+		return
+	}
+
+	senderID := firstNonEmpty(env.SourceNumber, env.SourceUUID, env.Source)
+	if senderID == "" || senderID == c.config.Account {
+		return
+	}
+
+	sender := bus.SenderInfo{
+		Platform:    "signal",
+		PlatformID:  senderID,
+		CanonicalID: identity.BuildCanonicalID("signal", senderID),
+		DisplayName: env.SourceName,
+	}
+	if !c.IsAllowedSender(sender) {
+		logger.DebugCF("signal", "Message rejected by allowlist", map[string]any{
+			"sender": senderID,
+		})
+		return
+	}
+
+	chatID := senderID
+	chatType := "direct"
+	if dm.GroupInfo != nil && dm.GroupInfo.GroupID != "" {
+		chatID = groupPrefix + dm.GroupInfo.GroupID
+		chatType = "group"
+	}
+
+	timestamp := dm.Timestamp
+	if timestamp == 0 {
+		timestamp = env.Timestamp
+	}
+	messageID := formatMessageID(senderID, timestamp)
+
+	content, mentioned := c.resolveMentions(dm.Message, dm.Mentions)
+	if chatType == "group" {
+		respond, cleaned := c.ShouldRespondInGroup(mentioned, content)
+		if !respond {
+			return
+		}
+		content = cleaned
+	}
+
+	var mediaPaths []string
+	scope := channels.BuildMediaScope("signal", chatID, messageID)
+	for _, att := range dm.Attachments {
+		ref, annotation := c.fetchAttachment(chatID, att, scope)
+		if ref == "" {
+			continue
+		}
+		mediaPaths = append(mediaPaths, ref)
+		if content != "" {
+			content += "\n"
+		}
+		content += annotation
+	}
+
+	inboundCtx := bus.InboundContext{
+		Channel:   c.Name(),
+		Account:   c.config.Account,
+		ChatID:    chatID,
+		ChatType:  chatType,
+		SenderID:  senderID,
+		MessageID: messageID,
+		Mentioned: mentioned,
+		Raw: map[string]string{
+			"timestamp": strconv.FormatInt(timestamp, 10),
+			"source":    senderID,
+			"platform":  "signal",
+		},
+	}
+	if q := dm.Quote; q != nil {
+		author := firstNonEmpty(q.AuthorNumber, q.AuthorUUID, q.Author)
+		inboundCtx.ReplyToMessageID = formatMessageID(author, q.ID)
+		inboundCtx.ReplyToSenderID = author
+		content = c.prependQuote(content, author, q.Text)
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+
+	logger.DebugCF("signal", "Received message", map[string]any{
+		"sender":  senderID,
+		"chat_id": chatID,
+		"preview": utils.Truncate(content, 50),
+	})
+
+	c.HandleInboundContext(c.ctx, chatID, content, mediaPaths, inboundCtx, sender)
+}
+
+func (c *SignalChannel) prependQuote(content, author, quoted string) string {
+	quoted = strings.TrimSpace(quoted)
+	if quoted == "" {
+		return content
+	}
+	role := "user"
+	if author == c.config.Account {
+		role = "assistant"
+	}
+	if strings.TrimSpace(content) == "" {
+		return fmt.Sprintf("[quoted %s message from %s]: %s", role, author, quoted)
+	}
+	return fmt.Sprintf("[quoted %s message from %s]: %s\n\n%s", role, author, quoted, content)
+}
+
+// resolveMentions replaces Signal's mention placeholders with @names,
+// dropping mentions of the bot itself, and reports whether the bot was
+// mentioned. Mention offsets are in UTF-16 code units.
+func (c *SignalChannel) resolveMentions(text string, mentions []signalMention) (string, bool) {
+	if len(mentions) == 0 {
+		return strings.TrimSpace(text), false
+	}
+
+	units := utf16.Encode([]rune(text))
+	sorted := slices.Clone(mentions)
+	slices.SortFunc(sorted, func(a, b signalMention) int { return b.Start - a.Start })
+
+	mentioned := false
+	for _, m := range sorted {
+		if m.Start < 0 || m.Length < 0 || m.Start+m.Length > len(units) {
+			continue
+		}
+		replacement := "@" + firstNonEmpty(m.Name, m.Number, m.UUID)
+		if m.Number == c.config.Account {
+			mentioned = true
+			replacement = ""
+		}
+		tail := slices.Clone(units[m.Start+m.Length:])
+		units = append(append(units[:m.Start], utf16.Encode([]rune(replacement))...), tail...)
+	}
+
+	out := strings.ReplaceAll(string(utf16.Decode(units)), mentionReplace, "")
+	return strings.TrimSpace(out), mentioned
+}
+
+// fetchAttachment downloads an attachment through the daemon and stores it
+// in the media store. Voice notes are annotated so the agent transcribes them.
+func (c *SignalChannel) fetchAttachment(chatID string, att signalAttachment, scope string) (string, string) {
+	params, err := c.target(chatID)
+	if err != nil {
+		return "", ""
+	}
+	params["id"] = att.ID
+
+	var result json.RawMessage
+	if err := c.rpc.call(c.ctx, "getAttachment", params, &result); err != nil {
+		logger.ErrorCF("signal", "Failed to fetch attachment", map[string]any{
+			"id":    att.ID,
+			"error": err.Error(),
+		})
+		return "", ""
+	}
+	encoded := decodeAttachmentResult(result)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) == 0 {
+		logger.ErrorCF("signal", "Invalid attachment data", map[string]any{"id": att.ID})
+		return "", ""
+	}
+
+	isAudio := strings.HasPrefix(att.ContentType, "audio/")
+	isVoice := att.VoiceNote || (isAudio && att.Filename == "")
+	name := att.Filename
+	if name == "" {
+		name = "attachment" + extensionFor(att.ContentType)
+		if isVoice {
+			name = "voice" + extensionFor(att.ContentType)
+		}
+	}
+
+	dir := filepath.Join(os.TempDir(), "picoclaw_media")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", ""
+	}
+	f, err := os.CreateTemp(dir, "signal-*-"+utils.SanitizeFilename(name))
+	if err != nil {
+		return "", ""
+	}
+	_, werr := f.Write(data)
+	cerr := f.Close()
+	if werr != nil || cerr != nil {
+		os.Remove(f.Name())
+		return "", ""
+	}
+
+	ref := f.Name()
+	if store := c.GetMediaStore(); store != nil {
+		if stored, err := store.Store(f.Name(), media.MediaMeta{
+			Filename:      name,
+			ContentType:   att.ContentType,
+			Source:        "signal",
+			CleanupPolicy: media.CleanupPolicyDeleteOnCleanup,
+		}, scope); err == nil {
+			ref = stored
+		}
+	}
+
+	switch {
+	case isVoice:
+		return ref, "[voice]"
+	case isAudio:
+		return ref, fmt.Sprintf("[audio: %s]", name)
+	case strings.HasPrefix(att.ContentType, "image/"):
+		return ref, fmt.Sprintf("[image: %s]", name)
+	default:
+		return ref, fmt.Sprintf("[file: %s]", name)
+	}
+}
+
+// decodeAttachmentResult accepts getAttachment results as a bare base64
+// string or as {"data": "<base64>"}.
+func decodeAttachmentResult(result json.RawMessage) string {
+	var s string
+	if json.Unmarshal(result, &s) == nil {
+		return s
+	}
+	var obj struct {
+		Data string `json:"data"`
+	}
+	_ = json.Unmarshal(result, &obj)
+	return obj.Data
+}
+
+func extensionFor(contentType string) string {
+	switch contentType {
+	case "audio/aac":
+		return ".aac"
+	case "audio/ogg":
+		return ".ogg"
+	case "audio/mpeg":
+		return ".mp3"
+	}
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+func dataURI(path, filename, contentType string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if filename == "" {
+		filename = filepath.Base(path)
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+		if i := strings.IndexByte(contentType, ';'); i >= 0 {
+			contentType = contentType[:i]
+		}
+	}
+	return fmt.Sprintf("data:%s;filename=%s;base64,%s",
+		contentType, filename, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// formatMessageID identifies a Signal message by author and timestamp,
+// which is how quotes, reactions and deletions address it.
+func formatMessageID(author string, timestamp int64) string {
+	return author + "/" + strconv.FormatInt(timestamp, 10)
+}
+
+func parseMessageID(id string) (author string, timestamp int64, ok bool) {
+	author, ts, found := strings.Cut(id, "/")
+	if !found || author == "" {
+		return "", 0, false
+	}
+	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || timestamp <= 0 {
+		return "", 0, false
+	}
+	return author, timestamp, true
+}
+
+func withParam(params map[string]any, key string, value any) map[string]any {
+	out := make(map[string]any, len(params)+1)
+	for k, v := range params {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
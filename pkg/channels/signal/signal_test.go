@@ -0,0 +1,359 @@
+package signal
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const botNumber = "+15550000000"
+
+// fakeDaemon is a minimal signal-cli `daemon --tcp` stand-in.
+type fakeDaemon struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	requests []map[string]any
+	conns    []net.Conn
+}
+
+func newFakeDaemon(t *testing.T) *fakeDaemon {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	d := &fakeDaemon{ln: ln}
+	go d.serve()
+	t.Cleanup(func() {
+		ln.Close()
+		d.mu.Lock()
+		for _, c := range d.conns {
+			c.Close()
+		}
+		d.mu.Unlock()
+	})
+	return d
+}
+
+func (d *fakeDaemon) url() string { return "tcp://" + d.ln.Addr().String() }
+
+func (d *fakeDaemon) serve() {
+	for {
+		conn, err := d.ln.Accept()
+		if err != nil {
+			return
+		}
+		d.mu.Lock()
+		d.conns = append(d.conns, conn)
+		d.mu.Unlock()
+		go d.handle(conn)
+	}
+}
+
+func (d *fakeDaemon) handle(conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var req map[string]any
+		if json.Unmarshal(scanner.Bytes(), &req) != nil {
+			continue
+		}
+		d.mu.Lock()
+		d.requests = append(d.requests, req)
+		d.mu.Unlock()
+
+		var result any = map[string]any{}
+		switch req["method"] {
+		case "send":
+			result = map[string]any{"timestamp": 1700000000500}
+		case "getAttachment":
+			result = map[string]any{"data": base64.StdEncoding.EncodeToString([]byte("OggS-voice"))}
+		}
+		resp, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": req["id"], "result": result})
+		d.write(conn, resp)
+	}
+}
+
+func (d *fakeDaemon) write(conn net.Conn, line []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, _ = conn.Write(append(line, '\n'))
+}
+
+// notify pushes a receive notification to every connected client.
+func (d *fakeDaemon) notify(t *testing.T, envelope map[string]any) {
+	t.Helper()
+	data, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "receive",
+		"params":  map[string]any{"account": botNumber, "envelope": envelope},
+	})
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		d.mu.Lock()
+		conns := append([]net.Conn(nil), d.conns...)
+		d.mu.Unlock()
+		if len(conns) > 0 {
+			for _, c := range conns {
+				d.write(c, data)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("client never connected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (d *fakeDaemon) calls(method string) []map[string]any {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out []map[string]any
+	for _, r := range d.requests {
+		if r["method"] == method {
+			out = append(out, r["params"].(map[string]any))
+		}
+	}
+	return out
+}
+
+func newTestChannel(t *testing.T, d *fakeDaemon, bc *config.Channel) (*SignalChannel, *bus.MessageBus) {
+	t.Helper()
+	mb := bus.NewMessageBus()
+	t.Cleanup(mb.Close)
+
+	if bc == nil {
+		bc = &config.Channel{}
+	}
+	ch, err := NewSignalChannel(bc, &config.SignalSettings{Account: botNumber, URL: d.url()}, mb)
+	if err != nil {
+		t.Fatalf("NewSignalChannel: %v", err)
+	}
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+	return ch, mb
+}
+
+func receive(t *testing.T, mb *bus.MessageBus) bus.InboundMessage {
+	t.Helper()
+	select {
+	case in := <-mb.InboundChan():
+		return in
+	case <-time.After(3 * time.Second):
+		t.Fatal("no inbound message published")
+	}
+	return bus.InboundMessage{}
+}
+
+func TestNewSignalChannel_Validation(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	if _, err := NewSignalChannel(&config.Channel{}, &config.SignalSettings{}, mb); err == nil {
+		t.Fatal("expected error for missing account")
+	}
+	if _, err := NewSignalChannel(&config.Channel{}, &config.SignalSettings{
+		Account: botNumber, URL: "ws://localhost",
+	}, mb); err == nil {
+		t.Fatal("expected error for unsupported scheme")
+	}
+}
+
+func TestDirectMessageWithQuote(t *testing.T) {
+	d := newFakeDaemon(t)
+	_, mb := newTestChannel(t, d, nil)
+
+	// Own messages, receipts and reactions are ignored.
+	d.notify(t, map[string]any{"sourceNumber": botNumber, "dataMessage": map[string]any{"message": "echo"}})
+	d.notify(t, map[string]any{"sourceNumber": "+15551111111", "receiptMessage": map[string]any{}})
+	d.notify(t, map[string]any{"sourceNumber": "+15551111111", "dataMessage": map[string]any{
+		"reaction": map[string]any{"emoji": "👍"},
+	}})
+	d.notify(t, map[string]any{
+		"sourceNumber": "+15551111111",
+		"sourceName":   "Alice",
+		"dataMessage": map[string]any{
+			"timestamp": 1700000000123,
+			"message":   "why?",
+			"quote": map[string]any{
+				"id":           1700000000001,
+				"authorNumber": botNumber,
+				"text":         "it is raining",
+			},
+		},
+	})
+
+	in := receive(t, mb)
+	if in.ChatID != "+15551111111" || in.Context.ChatType != "direct" {
+		t.Fatalf("chat = %q type = %q", in.ChatID, in.Context.ChatType)
+	}
+	if in.Context.MessageID != "+15551111111/1700000000123" {
+		t.Fatalf("message id = %q", in.Context.MessageID)
+	}
+	if in.Context.ReplyToMessageID != botNumber+"/1700000000001" {
+		t.Fatalf("reply to = %q", in.Context.ReplyToMessageID)
+	}
+	want := "[quoted assistant message from " + botNumber + "]: it is raining\n\nwhy?"
+	if in.Content != want {
+		t.Fatalf("content = %q, want %q", in.Content, want)
+	}
+}
+
+func TestGroupMentionAndVoiceNote(t *testing.T) {
+	d := newFakeDaemon(t)
+	_, mb := newTestChannel(t, d, &config.Channel{
+		GroupTrigger: config.GroupTriggerConfig{MentionOnly: true},
+	})
+
+	group := map[string]any{"groupId": "grp=="}
+	// Not mentioned: ignored in mention-only groups.
+	d.notify(t, map[string]any{"sourceNumber": "+15552222222", "dataMessage": map[string]any{
+		"timestamp": 1, "message": "chatter", "groupInfo": group,
+	}})
+	d.notify(t, map[string]any{"sourceNumber": "+15552222222", "dataMessage": map[string]any{
+		"timestamp": 2,
+		"message":   "😀 ￼ ask ￼ please",
+		"groupInfo": group,
+		"mentions": []map[string]any{
+			// Offsets are UTF-16 units: the emoji takes two.
+			{"number": botNumber, "start": 3, "length": 1},
+			{"name": "Bob", "number": "+15553333333", "start": 9, "length": 1},
+		},
+		"attachments": []map[string]any{{"id": "att1", "contentType": "audio/aac"}},
+	}})
+
+	in := receive(t, mb)
+	if in.ChatID != "group:grp==" || in.Context.ChatType != "group" || !in.Context.Mentioned {
+		t.Fatalf("inbound = %q %+v", in.ChatID, in.Context)
+	}
+	if in.Content != "😀  ask @Bob please\n[voice]" {
+		t.Fatalf("content = %q", in.Content)
+	}
+	if len(in.Media) != 1 {
+		t.Fatalf("media = %v", in.Media)
+	}
+
+	fetches := d.calls("getAttachment")
+	if len(fetches) != 1 || fetches[0]["groupId"] != "grp==" || fetches[0]["id"] != "att1" {
+		t.Fatalf("getAttachment calls = %v", fetches)
+	}
+}
+
+func TestSendQuotesReplyAndReacts(t *testing.T) {
+	d := newFakeDaemon(t)
+	ch, _ := newTestChannel(t, d, nil)
+	ctx := context.Background()
+
+	ids, err := ch.Send(ctx, bus.OutboundMessage{
+		ChatID:           "group:grp==",
+		Content:          "hello",
+		ReplyToMessageID: "+15551111111/1700000000123",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != botNumber+"/1700000000500" {
+		t.Fatalf("ids = %v", ids)
+	}
+	sends := d.calls("send")
+	if len(sends) != 1 {
+		t.Fatalf("send calls = %v", sends)
+	}
+	p := sends[0]
+	if p["account"] != botNumber || p["groupId"] != "grp==" || p["message"] != "hello" ||
+		p["quoteAuthor"] != "+15551111111" || p["quoteTimestamp"] != float64(1700000000123) {
+		t.Fatalf("send params = %v", sends[0])
+	}
+
+	undo, err := ch.ReactToMessage(ctx, "+15551111111", "+15551111111/1700000000123")
+	if err != nil {
+		t.Fatalf("ReactToMessage: %v", err)
+	}
+	undo()
+	undo()
+	reactions := d.calls("sendReaction")
+	if len(reactions) != 2 || reactions[0]["remove"] != nil || reactions[1]["remove"] != true {
+		t.Fatalf("sendReaction calls = %v", reactions)
+	}
+	if recipients, _ := reactions[0]["recipient"].([]any); len(recipients) != 1 || recipients[0] != "+15551111111" {
+		t.Fatalf("reaction recipient = %v", reactions[0]["recipient"])
+	}
+}
+
+func TestHTTPTransport(t *testing.T) {
+	var mu sync.Mutex
+	var methods []string
+	events := make(chan string, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/rpc", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		methods = append(methods, req["method"].(string))
+		mu.Unlock()
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%q,"result":{"deviceLinkUri":"sgnl://linkdevice?uuid=x"}}`, req["id"])
+	})
+	mux.HandleFunc("GET /api/v1/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		select {
+		case ev := <-events:
+			fmt.Fprintf(w, "event:receive\ndata:%s\n\n", ev)
+			w.(http.Flusher).Flush()
+		case <-r.Context().Done():
+			return
+		}
+		<-r.Context().Done()
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	uri, err := StartLink(context.Background(), srv.URL)
+	if err != nil || uri != "sgnl://linkdevice?uuid=x" {
+		t.Fatalf("StartLink = %q, %v", uri, err)
+	}
+
+	rpc, err := newRPCClient(srv.URL)
+	if err != nil {
+		t.Fatalf("newRPCClient: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got := make(chan string, 1)
+	go func() {
+		_ = rpc.listen(ctx, func(method string, params json.RawMessage) {
+			got <- method + " " + string(params)
+		})
+	}()
+	events <- `{"account":"+1","envelope":{}}`
+
+	select {
+	case ev := <-got:
+		if !strings.HasPrefix(ev, `receive {"account":"+1"`) {
+			t.Fatalf("event = %q", ev)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no event delivered")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(methods) != 1 || methods[0] != "startLink" {
+		t.Fatalf("methods = %v", methods)
+	}
+}
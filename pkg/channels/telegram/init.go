@@ -19,7 +19,12 @@ func init() {
 			if !ok {
 				return nil, channels.ErrSendFailed
 			}
-			return NewTelegramChannel(bc, c, b)
+			ch, err := NewTelegramChannel(bc, c, b)
+			if err != nil {
+				return nil, err
+			}
+			ch.workspace = cfg.WorkspacePath()
+			return ch, nil
 		},
 	)
 }
@@ -54,6 +54,8 @@ type TelegramChannel struct {
 	tgCfg    *config.TelegramSettings
 	progress *channels.ToolFeedbackAnimator
 
+	workspace string // source of custom commands for the bot menu
+
 	registerFunc      func(context.Context, []commands.Definition) error
 	commandRegDelayFn func(int) time.Duration
 	commandRegCancel  context.CancelFunc
@@ -146,7 +148,7 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 		"username": c.bot.Username(),
 	})
 
-	c.startCommandRegistration(c.ctx, commands.BuiltinDefinitions())
+	c.startCommandRegistration(c.ctx, commands.WorkspaceDefinitions(c.workspace))
 
 	go func() {
 		if err = bh.Start(); err != nil {
@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// Hook authentication modes.
+const (
+	// AuthGitHub checks X-Hub-Signature-256: sha256=<hex HMAC-SHA256 of the body>.
+	AuthGitHub = "github"
+	// AuthStripe checks Stripe-Signature: t=<unix>,v1=<hex HMAC-SHA256 of "t.body">.
+	AuthStripe = "stripe"
+	// AuthHMAC checks a hex or base64 HMAC-SHA256 of the body in SignatureHeader.
+	AuthHMAC = "hmac"
+	// AuthBearer checks Authorization: Bearer <secret>.
+	AuthBearer = "bearer"
+	// AuthNone accepts unauthenticated requests. It must be set explicitly.
+	AuthNone = "none"
+)
+
+const (
+	defaultSignatureHeader = "X-Signature"
+	// stripeTolerance bounds the age of a signed Stripe timestamp to limit replays.
+	stripeTolerance = 5 * time.Minute
+)
+
+var errBadSignature = errors.New("signature mismatch")
+
+func validateAuth(name string, hc config.WebhookHook) error {
+	switch hc.Auth {
+	case AuthGitHub, AuthStripe, AuthHMAC, AuthBearer:
+		if hc.Secret.String() == "" {
+			return fmt.Errorf("webhook: hook %q uses %s auth but has no secret", name, hc.Auth)
+		}
+	case AuthNone:
+	case "":
+		return fmt.Errorf("webhook: hook %q needs a secret or auth \"none\" to accept unsigned requests", name)
+	default:
+		return fmt.Errorf("webhook: hook %q has unknown auth %q (want github, stripe, hmac, bearer or none)", name, hc.Auth)
+	}
+	return nil
+}
+
+// verify authenticates a request against the hook's auth mode.
+func verify(hc config.WebhookHook, header http.Header, body []byte, now time.Time) error {
+	secret := []byte(hc.Secret.String())
+
+	switch hc.Auth {
+	case AuthNone:
+		return nil
+	case AuthBearer:
+		token, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), secret) != 1 {
+			return errors.New("invalid bearer token")
+		}
+		return nil
+	case AuthGitHub:
+		sig, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+		if !ok {
+			return errors.New("missing X-Hub-Signature-256 header")
+		}
+		return checkHex(sig, sign(secret, body))
+	case AuthStripe:
+		return verifyStripe(header.Get("Stripe-Signature"), secret, body, now)
+	case AuthHMAC:
+		name := hc.SignatureHeader
+		if name == "" {
+			name = defaultSignatureHeader
+		}
+		sig := strings.TrimSpace(header.Get(name))
+		if sig == "" {
+			return fmt.Errorf("missing %s header", name)
+		}
+		sig = strings.TrimPrefix(sig, "sha256=")
+		expected := sign(secret, body)
+		if checkHex(sig, expected) == nil {
+			return nil
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(sig); err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+		return errBadSignature
+	default:
+		return fmt.Errorf("unknown auth %q", hc.Auth)
+	}
+}
+
+func verifyStripe(value string, secret, body []byte, now time.Time) error {
+	if value == "" {
+		return errors.New("missing Stripe-Signature header")
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = val
+		case "v1":
+			signatures = append(signatures, val)
+		}
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return errors.New("malformed Stripe-Signature header")
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > stripeTolerance || age < -stripeTolerance {
+		return errors.New("stripe signature timestamp outside tolerance")
+	}
+
+	signed := make([]byte, 0, len(timestamp)+1+len(body))
+	signed = append(signed, timestamp...)
+	signed = append(signed, '.')
+	signed = append(signed, body...)
+	expected := sign(secret, signed)
+	for _, sig := range signatures {
+		if checkHex(sig, expected) == nil {
+			return nil
+		}
+	}
+	return errBadSignature
+}
+
+func sign(secret, data []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func checkHex(sig string, expected []byte) error {
+	decoded, err := hex.DecodeString(strings.TrimSpace(sig))
+	if err != nil || !hmac.Equal(decoded, expected) {
+		return errBadSignature
+	}
+	return nil
+}
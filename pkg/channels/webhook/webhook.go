@@ -0,0 +1,394 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// Limit request body to prevent memory exhaustion (DoS).
+	maxWebhookBodySize = 1 << 20 // 1 MiB
+
+	defaultSyncTimeout = 60 * time.Second
+	maxSyncTimeout     = 10 * time.Minute
+
+	defaultTemplate = `Webhook "{{hook}}" received a request:
+{{json .}}`
+)
+
+// hook is a configured endpoint with its parsed prompt template.
+type hook struct {
+	name    string
+	path    string
+	cfg     config.WebhookHook
+	tmpl    *template.Template
+	timeout time.Duration
+}
+
+// pendingReply waits for the agent's reply to a synchronous request.
+type pendingReply struct {
+	messageID string
+	reply     chan string
+}
+
+// WebhookChannel is a generic inbound channel. Each configured hook accepts
+// authenticated HTTP POSTs, renders the payload into a prompt and publishes
+// it as a message from the hook's own chat, so agents.dispatch.rules can
+// route each hook to a different agent. Replies are returned in the HTTP
+// response for synchronous hooks and dropped otherwise.
+type WebhookChannel struct {
+	*channels.BaseChannel
+	config *config.WebhookSettings
+	hooks  map[string]*hook // sub-path -> hook
+
+	mu      sync.Mutex
+	pending map[string][]*pendingReply // chat ID (hook name) -> waiters, oldest first
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewWebhookChannel creates a new webhook channel instance.
+func NewWebhookChannel(
+	bc *config.Channel,
+	cfg *config.WebhookSettings,
+	messageBus *bus.MessageBus,
+) (*WebhookChannel, error) {
+	if len(cfg.Hooks) == 0 {
+		return nil, fmt.Errorf("webhook: at least one hook is required")
+	}
+
+	hooks := make(map[string]*hook, len(cfg.Hooks))
+	for name, hc := range cfg.Hooks {
+		h, err := newHook(name, hc)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := hooks[h.path]; ok {
+			return nil, fmt.Errorf("webhook: hooks %q and %q share path %q", other.name, name, h.path)
+		}
+		hooks[h.path] = h
+	}
+
+	base := channels.NewBaseChannel(
+		config.ChannelWebhook,
+		cfg,
+		messageBus,
+		[]string{"*"}, // Requests are authenticated per hook; "*" suppresses the "allows EVERYONE" audit warning
+		channels.WithReasoningChannelID(bc.ReasoningChannelID),
+	)
+
+	return &WebhookChannel{
+		BaseChannel: base,
+		config:      cfg,
+		hooks:       hooks,
+		pending:     make(map[string][]*pendingReply),
+	}, nil
+}
+
+func newHook(name string, hc config.WebhookHook) (*hook, error) {
+	if strings.TrimSpace(name) == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("webhook: invalid hook name %q", name)
+	}
+
+	if hc.Auth == "" && hc.Secret.String() != "" {
+		hc.Auth = AuthGitHub
+	}
+	if err := validateAuth(name, hc); err != nil {
+		return nil, err
+	}
+
+	path := strings.Trim(hc.Path, "/")
+	if path == "" {
+		path = name
+	}
+
+	text := hc.Template
+	if strings.TrimSpace(text) == "" {
+		text = defaultTemplate
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs(name, nil, nil)).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: hook %q has an invalid template: %w", name, err)
+	}
+
+	timeout := defaultSyncTimeout
+	if hc.TimeoutSeconds > 0 {
+		timeout = min(time.Duration(hc.TimeoutSeconds)*time.Second, maxSyncTimeout)
+	}
+
+	return &hook{name: name, path: path, cfg: hc, tmpl: tmpl, timeout: timeout}, nil
+}
+
+// templateFuncs returns the helpers available to hook templates. The
+// request-bound helpers are rebound for every request.
+func templateFuncs(hookName string, headers http.Header, query map[string][]string) template.FuncMap {
+	return template.FuncMap{
+		"hook": func() string { return hookName },
+		"header": func(name string) string {
+			return headers.Get(name)
+		},
+		"query": func(name string) string {
+			if values := query[name]; len(values) > 0 {
+				return values[0]
+			}
+			return ""
+		},
+		"json": func(v any) string {
+			data, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				return fmt.Sprint(v)
+			}
+			return string(data)
+		},
+	}
+}
+
+// Start initializes the webhook channel.
+func (c *WebhookChannel) Start(ctx context.Context) error {
+	c.ctx, c.cancel = context.WithCancel(ctx)
+
+	paths := make([]string, 0, len(c.hooks))
+	for path := range c.hooks {
+		paths = append(paths, c.WebhookPath()+path)
+	}
+	sort.Strings(paths)
+	logger.InfoCF("webhook", "Webhook channel started", map[string]any{
+		"channel": c.Name(),
+		"hooks":   paths,
+	})
+
+	c.SetRunning(true)
+	return nil
+}
+
+// Stop gracefully stops the webhook channel. Pending synchronous requests
+// are answered with 503.
+func (c *WebhookChannel) Stop(ctx context.Context) error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.SetRunning(false)
+	logger.InfoCF("webhook", "Webhook channel stopped", map[string]any{"channel": c.Name()})
+	return nil
+}
+
+// WebhookPath returns the subtree all hooks are mounted under.
+func (c *WebhookChannel) WebhookPath() string {
+	if base := strings.Trim(c.config.BasePath, "/"); base != "" {
+		return "/" + base + "/"
+	}
+	if name := c.Name(); name != config.ChannelWebhook {
+		return "/hooks/" + name + "/"
+	}
+	return "/hooks/"
+}
+
+// Send delivers a reply to the synchronous request waiting on the chat. The
+// reply is matched by the message ID of the request; messages sent outside
+// the turn, e.g. by the message tool, go to the oldest waiter. Without a
+// waiter the reply is dropped.
+func (c *WebhookChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+
+	// Thoughts and tool progress are not the answer.
+	if kind := strings.TrimSpace(msg.Context.Raw["message_kind"]); kind != "" {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	waiters := c.pending[msg.ChatID]
+	idx := -1
+	for i, p := range waiters {
+		if p.messageID == msg.Context.MessageID {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 && len(waiters) > 0 {
+		idx = 0
+	}
+	var waiter *pendingReply
+	if idx >= 0 {
+		waiter = waiters[idx]
+		c.removeWaiterLocked(msg.ChatID, waiter)
+	}
+	c.mu.Unlock()
+
+	if waiter == nil {
+		logger.DebugCF("webhook", "No pending request for reply, dropping", map[string]any{
+			"hook": msg.ChatID,
+		})
+		return nil, nil
+	}
+	waiter.reply <- msg.Content
+	return nil, nil
+}
+
+// ServeHTTP implements http.Handler for the shared HTTP server.
+func (c *WebhookChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h, ok := c.hooks[strings.Trim(strings.TrimPrefix(r.URL.Path, c.WebhookPath()), "/")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !c.IsRunning() {
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize+1))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxWebhookBodySize {
+		logger.WarnCF("webhook", "Webhook request body too large, rejected", map[string]any{"hook": h.name})
+		http.Error(w, "Request entity too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err := verify(h.cfg, r.Header, body, time.Now()); err != nil {
+		logger.WarnCF("webhook", "Webhook request rejected", map[string]any{
+			"hook":  h.name,
+			"error": err.Error(),
+		})
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	prompt, err := h.render(r, body)
+	if err != nil {
+		logger.WarnCF("webhook", "Failed to render webhook template", map[string]any{
+			"hook":  h.name,
+			"error": err.Error(),
+		})
+		http.Error(w, "Unprocessable payload", http.StatusUnprocessableEntity)
+		return
+	}
+	if prompt == "" {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+		return
+	}
+
+	messageID := uuid.NewString()
+	var waiter *pendingReply
+	if h.cfg.Sync {
+		waiter = &pendingReply{messageID: messageID, reply: make(chan string, 1)}
+		c.mu.Lock()
+		c.pending[h.name] = append(c.pending[h.name], waiter)
+		c.mu.Unlock()
+	}
+
+	inboundCtx := bus.InboundContext{
+		Channel:   c.Name(),
+		ChatID:    h.name,
+		ChatType:  "channel",
+		SenderID:  h.name,
+		MessageID: messageID,
+		Raw: map[string]string{
+			"webhook_path": r.URL.Path,
+		},
+	}
+	sender := bus.SenderInfo{
+		Platform:    config.ChannelWebhook,
+		PlatformID:  h.name,
+		CanonicalID: "webhook:" + h.name,
+		DisplayName: h.name,
+	}
+	c.HandleMessageWithContext(r.Context(), h.name, prompt, nil, inboundCtx, sender)
+
+	if waiter == nil {
+		writeJSON(w, http.StatusAccepted, map[string]string{"id": messageID, "status": "accepted"})
+		return
+	}
+
+	// The shared server's write timeout is shorter than most agent turns.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(h.timeout + 5*time.Second))
+
+	timer := time.NewTimer(h.timeout)
+	defer timer.Stop()
+	select {
+	case reply := <-waiter.reply:
+		writeJSON(w, http.StatusOK, map[string]string{"id": messageID, "reply": reply})
+	case <-timer.C:
+		c.cancelWaiter(h.name, waiter)
+		writeJSON(w, http.StatusGatewayTimeout, map[string]string{"id": messageID, "status": "timeout"})
+	case <-r.Context().Done():
+		c.cancelWaiter(h.name, waiter)
+	case <-c.ctx.Done():
+		c.cancelWaiter(h.name, waiter)
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+	}
+}
+
+// render executes the hook template with the decoded JSON payload as dot.
+// Bodies that are not JSON are passed as a string. An empty result means the
+// template filtered the event out.
+func (h *hook) render(r *http.Request, body []byte) (string, error) {
+	var payload any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		payload = string(body)
+	}
+
+	tmpl, err := h.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	tmpl.Funcs(templateFuncs(h.name, r.Header, r.URL.Query()))
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func (c *WebhookChannel) cancelWaiter(chatID string, waiter *pendingReply) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeWaiterLocked(chatID, waiter)
+}
+
+func (c *WebhookChannel) removeWaiterLocked(chatID string, waiter *pendingReply) {
+	waiters := c.pending[chatID]
+	for i, p := range waiters {
+		if p == waiter {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(c.pending, chatID)
+		return
+	}
+	c.pending[chatID] = waiters
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,284 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func hexHMAC(secret, data string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newTestChannel(t *testing.T, hooks map[string]config.WebhookHook) (*WebhookChannel, *bus.MessageBus) {
+	t.Helper()
+	mb := bus.NewMessageBus()
+	t.Cleanup(mb.Close)
+
+	ch, err := NewWebhookChannel(&config.Channel{}, &config.WebhookSettings{Hooks: hooks}, mb)
+	if err != nil {
+		t.Fatalf("NewWebhookChannel: %v", err)
+	}
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+	return ch, mb
+}
+
+func TestVerify(t *testing.T) {
+	body := `{"action":"opened"}`
+	now := time.Unix(1_700_000_000, 0)
+	ts := fmt.Sprint(now.Unix())
+
+	tests := []struct {
+		name    string
+		hook    config.WebhookHook
+		headers map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "github valid",
+			hook:    config.WebhookHook{Auth: AuthGitHub, Secret: *config.NewSecureString("s3cret")},
+			headers: map[string]string{"X-Hub-Signature-256": "sha256=" + hexHMAC("s3cret", body)},
+		},
+		{
+			name:    "github wrong secret",
+			hook:    config.WebhookHook{Auth: AuthGitHub, Secret: *config.NewSecureString("s3cret")},
+			headers: map[string]string{"X-Hub-Signature-256": "sha256=" + hexHMAC("other", body)},
+			wantErr: true,
+		},
+		{
+			name:    "github missing header",
+			hook:    config.WebhookHook{Auth: AuthGitHub, Secret: *config.NewSecureString("s3cret")},
+			wantErr: true,
+		},
+		{
+			name: "stripe valid",
+			hook: config.WebhookHook{Auth: AuthStripe, Secret: *config.NewSecureString("whsec")},
+			headers: map[string]string{
+				"Stripe-Signature": "t=" + ts + ",v1=deadbeef,v1=" + hexHMAC("whsec", ts+"."+body),
+			},
+		},
+		{
+			name: "stripe stale timestamp",
+			hook: config.WebhookHook{Auth: AuthStripe, Secret: *config.NewSecureString("whsec")},
+			headers: map[string]string{
+				"Stripe-Signature": "t=1600000000,v1=" + hexHMAC("whsec", "1600000000."+body),
+			},
+			wantErr: true,
+		},
+		{
+			name: "hmac custom header",
+			hook: config.WebhookHook{
+				Auth:            AuthHMAC,
+				Secret:          *config.NewSecureString("k"),
+				SignatureHeader: "X-Custom-Sig",
+			},
+			headers: map[string]string{"X-Custom-Sig": hexHMAC("k", body)},
+		},
+		{
+			name:    "bearer valid",
+			hook:    config.WebhookHook{Auth: AuthBearer, Secret: *config.NewSecureString("tok")},
+			headers: map[string]string{"Authorization": "Bearer tok"},
+		},
+		{
+			name:    "bearer invalid",
+			hook:    config.WebhookHook{Auth: AuthBearer, Secret: *config.NewSecureString("tok")},
+			headers: map[string]string{"Authorization": "Bearer nope"},
+			wantErr: true,
+		},
+		{
+			name: "none",
+			hook: config.WebhookHook{Auth: AuthNone},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.headers {
+				header.Set(k, v)
+			}
+			err := verify(tt.hook, header, []byte(body), now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewWebhookChannel_Validation(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	cases := map[string]map[string]config.WebhookHook{
+		"no hooks":     {},
+		"no auth":      {"a": {}},
+		"unknown auth": {"a": {Auth: "basic"}},
+		"missing key":  {"a": {Auth: AuthBearer}},
+		"bad template": {"a": {Auth: AuthNone, Template: "{{.x"}},
+		"same path":    {"a": {Auth: AuthNone, Path: "x"}, "b": {Auth: AuthNone, Path: "/x/"}},
+	}
+	for name, hooks := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewWebhookChannel(&config.Channel{}, &config.WebhookSettings{Hooks: hooks}, mb); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestServeHTTP_AsyncPublishesRenderedPrompt(t *testing.T) {
+	ch, mb := newTestChannel(t, map[string]config.WebhookHook{
+		"github": {
+			Secret:   *config.NewSecureString("s3cret"),
+			Template: `{{header "X-GitHub-Event"}}: {{.action}} {{.pull_request.title}} ({{hook}})`,
+		},
+	})
+
+	body := `{"action":"opened","pull_request":{"title":"Fix typo"}}`
+	req := httptest.NewRequest(http.MethodPost, "/hooks/github", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hexHMAC("s3cret", body))
+	rec := httptest.NewRecorder()
+
+	ch.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+
+	select {
+	case msg := <-mb.InboundChan():
+		if msg.Content != "pull_request: opened Fix typo (github)" {
+			t.Fatalf("content = %q", msg.Content)
+		}
+		if msg.Channel != "webhook" || msg.ChatID != "github" {
+			t.Fatalf("routing = %s/%s, want webhook/github", msg.Channel, msg.ChatID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no inbound message published")
+	}
+}
+
+func TestServeHTTP_RejectsBadSignatureAndUnknownHook(t *testing.T) {
+	ch, _ := newTestChannel(t, map[string]config.WebhookHook{
+		"github": {Auth: AuthGitHub, Secret: *config.NewSecureString("s3cret")},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/hooks/github", strings.NewReader(`{}`))
+	req.Header.Set("X-Hub-Signature-256", "sha256=00")
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("bad signature status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	rec = httptest.NewRecorder()
+	ch.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/hooks/other", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown hook status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestServeHTTP_SyncReturnsReply(t *testing.T) {
+	ch, mb := newTestChannel(t, map[string]config.WebhookHook{
+		"ask": {Auth: AuthNone, Sync: true, Template: "{{.question}}", TimeoutSeconds: 5},
+	})
+
+	go func() {
+		in := <-mb.InboundChan()
+		// A thought is not the answer.
+		thought := bus.OutboundMessage{
+			ChatID:  in.ChatID,
+			Context: in.Context,
+			Content: "thinking...",
+		}
+		thought.Context.Raw = map[string]string{"message_kind": "thought"}
+		_, _ = ch.Send(context.Background(), thought)
+		_, _ = ch.Send(context.Background(), bus.OutboundMessage{
+			ChatID:  in.ChatID,
+			Context: in.Context,
+			Content: "answer to " + in.Content,
+		})
+	}()
+
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/hooks/ask", strings.NewReader(`{"question":"ping"}`)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp["reply"] != "answer to ping" || resp["id"] == "" {
+		t.Fatalf("response = %v", resp)
+	}
+}
+
+func TestServeHTTP_SyncTimeout(t *testing.T) {
+	ch, _ := newTestChannel(t, map[string]config.WebhookHook{
+		"ask": {Auth: AuthNone, Sync: true, TimeoutSeconds: 1},
+	})
+
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/hooks/ask", strings.NewReader(`{}`)))
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusGatewayTimeout)
+	}
+	if len(ch.pending) != 0 {
+		t.Fatalf("pending waiters left behind: %v", ch.pending)
+	}
+}
+
+func TestWebhookPath(t *testing.T) {
+	ch, _ := newTestChannel(t, map[string]config.WebhookHook{"a": {Auth: AuthNone}})
+	if got := ch.WebhookPath(); got != "/hooks/" {
+		t.Fatalf("default path = %q", got)
+	}
+
+	ch.SetName("ci")
+	if got := ch.WebhookPath(); got != "/hooks/ci/" {
+		t.Fatalf("named instance path = %q", got)
+	}
+
+	ch.config.BasePath = "/in/bound"
+	if got := ch.WebhookPath(); got != "/in/bound/" {
+		t.Fatalf("base_path = %q", got)
+	}
+}
+
+func TestServeHTTP_EmptyPromptIsIgnored(t *testing.T) {
+	ch, mb := newTestChannel(t, map[string]config.WebhookHook{
+		"gh": {Auth: AuthNone, Template: `{{if eq .action "opened"}}opened{{end}}`},
+	})
+
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/hooks/gh", strings.NewReader(`{"action":"closed"}`)))
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "ignored") {
+		t.Fatalf("status = %d body = %s, want ignored", rec.Code, rec.Body.String())
+	}
+	select {
+	case msg := <-mb.InboundChan():
+		t.Fatalf("unexpected inbound message: %q", msg.Content)
+	default:
+	}
+}
@@ -0,0 +1,174 @@
+package xmpp
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// saslMechanism is one side of a SASL exchange. next receives the server's
+// challenge (nil for the initial response) and returns the client's reply.
+type saslMechanism interface {
+	name() string
+	next(challenge []byte) ([]byte, error)
+	// verify checks the additional data sent with <success/>.
+	verify(data []byte) error
+}
+
+// chooseMechanism picks the strongest mechanism both sides support. PLAIN is
+// only offered over TLS, which negotiate always requires.
+func chooseMechanism(offered []string, username, password string) (saslMechanism, error) {
+	has := func(name string) bool {
+		for _, m := range offered {
+			if strings.EqualFold(strings.TrimSpace(m), name) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case has("SCRAM-SHA-256"):
+		return newSCRAM("SCRAM-SHA-256", sha256.New, username, password), nil
+	case has("SCRAM-SHA-1"):
+		return newSCRAM("SCRAM-SHA-1", sha1.New, username, password), nil
+	case has("PLAIN"):
+		return &plainAuth{username: username, password: password}, nil
+	default:
+		return nil, fmt.Errorf("no supported SASL mechanism in %v", offered)
+	}
+}
+
+type plainAuth struct {
+	username string
+	password string
+}
+
+func (p *plainAuth) name() string { return "PLAIN" }
+
+func (p *plainAuth) next(challenge []byte) ([]byte, error) {
+	if challenge != nil {
+		return nil, errors.New("unexpected PLAIN challenge")
+	}
+	return []byte("\x00" + p.username + "\x00" + p.password), nil
+}
+
+func (p *plainAuth) verify([]byte) error { return nil }
+
+// scramAuth implements SCRAM (RFC 5802) without channel binding.
+type scramAuth struct {
+	mech     string
+	hash     func() hash.Hash
+	username string
+	password string
+
+	clientNonce     string
+	clientFirstBare string
+	serverSignature []byte
+	step            int
+}
+
+func newSCRAM(mech string, h func() hash.Hash, username, password string) *scramAuth {
+	nonce := make([]byte, 18)
+	_, _ = rand.Read(nonce)
+	return &scramAuth{
+		mech:        mech,
+		hash:        h,
+		username:    username,
+		password:    password,
+		clientNonce: base64.RawStdEncoding.EncodeToString(nonce),
+	}
+}
+
+func (s *scramAuth) name() string { return s.mech }
+
+func (s *scramAuth) next(challenge []byte) ([]byte, error) {
+	s.step++
+	switch s.step {
+	case 1:
+		user := strings.NewReplacer("=", "=3D", ",", "=2C").Replace(s.username)
+		s.clientFirstBare = "n=" + user + ",r=" + s.clientNonce
+		return []byte("n,," + s.clientFirstBare), nil
+	case 2:
+		return s.clientFinal(string(challenge))
+	default:
+		// Some servers send the final verifier as a challenge.
+		return []byte{}, s.verify(challenge)
+	}
+}
+
+func (s *scramAuth) clientFinal(serverFirst string) ([]byte, error) {
+	attrs := parseSCRAMAttrs(serverFirst)
+	nonce, salt64, iterStr := attrs["r"], attrs["s"], attrs["i"]
+	if !strings.HasPrefix(nonce, s.clientNonce) || len(nonce) == len(s.clientNonce) {
+		return nil, errors.New("scram: server nonce does not extend client nonce")
+	}
+	salt, err := base64.StdEncoding.DecodeString(salt64)
+	if err != nil {
+		return nil, fmt.Errorf("scram: invalid salt: %w", err)
+	}
+	iterations, err := strconv.Atoi(iterStr)
+	if err != nil || iterations < 1 {
+		return nil, fmt.Errorf("scram: invalid iteration count %q", iterStr)
+	}
+
+	saltedPassword, err := pbkdf2.Key(s.hash, s.password, salt, iterations, s.hash().Size())
+	if err != nil {
+		return nil, fmt.Errorf("scram: %w", err)
+	}
+	clientKey := s.hmac(saltedPassword, "Client Key")
+	serverKey := s.hmac(saltedPassword, "Server Key")
+	h := s.hash()
+	h.Write(clientKey)
+	storedKey := h.Sum(nil)
+
+	clientFinalBare := "c=biws,r=" + nonce
+	authMessage := s.clientFirstBare + "," + serverFirst + "," + clientFinalBare
+	clientSignature := s.hmac(storedKey, authMessage)
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ clientSignature[i]
+	}
+	s.serverSignature = s.hmac(serverKey, authMessage)
+
+	return []byte(clientFinalBare + ",p=" + base64.StdEncoding.EncodeToString(proof)), nil
+}
+
+func (s *scramAuth) verify(data []byte) error {
+	if s.serverSignature == nil {
+		return errors.New("scram: authentication finished early")
+	}
+	attrs := parseSCRAMAttrs(string(data))
+	if e := attrs["e"]; e != "" {
+		return fmt.Errorf("scram: server error %q", e)
+	}
+	got, err := base64.StdEncoding.DecodeString(attrs["v"])
+	if err != nil || subtle.ConstantTimeCompare(got, s.serverSignature) != 1 {
+		return errors.New("scram: server signature mismatch")
+	}
+	return nil
+}
+
+func (s *scramAuth) hmac(key []byte, msg string) []byte {
+	m := hmac.New(s.hash, key)
+	m.Write([]byte(msg))
+	return m.Sum(nil)
+}
+
+func parseSCRAMAttrs(msg string) map[string]string {
+	attrs := make(map[string]string)
+	for _, part := range strings.Split(msg, ",") {
+		if k, v, ok := strings.Cut(part, "="); ok && len(k) == 1 {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
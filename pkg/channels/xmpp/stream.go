@@ -0,0 +1,494 @@
+package xmpp
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	nsStream     = "http://etherx.jabber.org/streams"
+	nsClient     = "jabber:client"
+	nsTLS        = "urn:ietf:params:xml:ns:xmpp-tls"
+	nsSASL       = "urn:ietf:params:xml:ns:xmpp-sasl"
+	nsBind       = "urn:ietf:params:xml:ns:xmpp-bind"
+	nsSession    = "urn:ietf:params:xml:ns:xmpp-session"
+	nsMUC        = "http://jabber.org/protocol/muc"
+	nsMUCUser    = "http://jabber.org/protocol/muc#user"
+	nsChatStates = "http://jabber.org/protocol/chatstates"
+	nsCorrect    = "urn:xmpp:message-correct:0"
+	nsRetract    = "urn:xmpp:message-retract:1"
+	nsFallback   = "urn:xmpp:fallback:0"
+	nsDelay      = "urn:xmpp:delay"
+	nsOOB        = "jabber:x:oob"
+	nsPing       = "urn:xmpp:ping"
+	nsDiscoInfo  = "http://jabber.org/protocol/disco#info"
+	nsDiscoItems = "http://jabber.org/protocol/disco#items"
+	nsUpload     = "urn:xmpp:http:upload:0"
+	nsStanzas    = "urn:ietf:params:xml:ns:xmpp-stanzas"
+
+	resource     = "picoclaw"
+	writeTimeout = 30 * time.Second
+)
+
+// errAuth marks SASL failures, which retrying with the same credentials
+// cannot fix.
+var errAuth = errors.New("xmpp authentication failed")
+
+type streamFeatures struct {
+	StartTLS   *struct{} `xml:"urn:ietf:params:xml:ns:xmpp-tls starttls"`
+	Mechanisms *struct {
+		List []string `xml:"mechanism"`
+	} `xml:"urn:ietf:params:xml:ns:xmpp-sasl mechanisms"`
+	Bind    *struct{} `xml:"urn:ietf:params:xml:ns:xmpp-bind bind"`
+	Session *struct {
+		Optional *struct{} `xml:"optional"`
+	} `xml:"urn:ietf:params:xml:ns:xmpp-session session"`
+}
+
+type stanzaError struct {
+	Type      string `xml:"type,attr"`
+	Condition struct {
+		XMLName xml.Name
+	} `xml:",any"`
+	Text string `xml:"text"`
+}
+
+func (e *stanzaError) Error() string {
+	msg := e.Type + " " + e.Condition.XMLName.Local
+	if e.Text != "" {
+		msg += ": " + e.Text
+	}
+	return "xmpp error " + strings.TrimSpace(msg)
+}
+
+type oobData struct {
+	URL  string `xml:"url"`
+	Desc string `xml:"desc,omitempty"`
+}
+
+// message is an inbound <message/> stanza.
+type message struct {
+	From    string       `xml:"from,attr"`
+	To      string       `xml:"to,attr"`
+	ID      string       `xml:"id,attr"`
+	Type    string       `xml:"type,attr"`
+	Body    string       `xml:"body"`
+	Subject *string      `xml:"subject"`
+	Error   *stanzaError `xml:"error"`
+	Delay   *struct{}    `xml:"urn:xmpp:delay delay"`
+	Replace *struct {
+		ID string `xml:"id,attr"`
+	} `xml:"urn:xmpp:message-correct:0 replace"`
+	OOB []oobData `xml:"jabber:x:oob x"`
+}
+
+// presence is an inbound <presence/> stanza.
+type presence struct {
+	From    string       `xml:"from,attr"`
+	Type    string       `xml:"type,attr"`
+	Error   *stanzaError `xml:"error"`
+	MUCUser *struct {
+		Status []struct {
+			Code int `xml:"code,attr"`
+		} `xml:"status"`
+	} `xml:"http://jabber.org/protocol/muc#user x"`
+}
+
+// iq is an inbound <iq/> stanza; its payload is kept as raw XML.
+type iq struct {
+	XMLName xml.Name     `xml:"iq"`
+	From    string       `xml:"from,attr"`
+	ID      string       `xml:"id,attr"`
+	Type    string       `xml:"type,attr"`
+	Payload []byte       `xml:",innerxml"`
+	Error   *stanzaError `xml:"error"`
+}
+
+// element is a namespaced child whose name is set at runtime, such as a chat
+// state.
+type element struct {
+	XMLName xml.Name
+}
+
+// outMessage is an outbound <message/> stanza.
+type outMessage struct {
+	XMLName   xml.Name `xml:"message"`
+	To        string   `xml:"to,attr"`
+	Type      string   `xml:"type,attr"`
+	ID        string   `xml:"id,attr,omitempty"`
+	Body      string   `xml:"body,omitempty"`
+	ChatState *element
+	Replace   *struct {
+		ID string `xml:"id,attr"`
+	} `xml:"urn:xmpp:message-correct:0 replace,omitempty"`
+	Retract *struct {
+		ID string `xml:"id,attr"`
+	} `xml:"urn:xmpp:message-retract:1 retract,omitempty"`
+	Fallback *struct {
+		For string `xml:"for,attr"`
+	} `xml:"urn:xmpp:fallback:0 fallback,omitempty"`
+	OOB *oobData `xml:"jabber:x:oob x,omitempty"`
+}
+
+// conn is a negotiated client-to-server stream.
+type conn struct {
+	raw net.Conn
+	dec *xml.Decoder
+	jid string // full JID bound by the server
+
+	wmu sync.Mutex
+}
+
+func (c *conn) writeRaw(s string) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_ = c.raw.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := io.WriteString(c.raw, s)
+	return err
+}
+
+func (c *conn) send(v any) error {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeRaw(string(data))
+}
+
+func (c *conn) close() {
+	_ = c.writeRaw("</stream:stream>")
+	c.raw.Close()
+}
+
+// openStream sends a stream header and reads the server's header and
+// features.
+func (c *conn) openStream(domain string) (*streamFeatures, error) {
+	c.dec = xml.NewDecoder(c.raw)
+	header := fmt.Sprintf("<?xml version='1.0'?><stream:stream to='%s' xmlns='%s' xmlns:stream='%s' version='1.0'>",
+		xmlEscape(domain), nsClient, nsStream)
+	if err := c.writeRaw(header); err != nil {
+		return nil, err
+	}
+
+	for {
+		se, err := c.nextElement()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case se.Name.Space == nsStream && se.Name.Local == "stream":
+			continue
+		case se.Name.Space == nsStream && se.Name.Local == "features":
+			var f streamFeatures
+			if err := c.dec.DecodeElement(&f, &se); err != nil {
+				return nil, err
+			}
+			return &f, nil
+		default:
+			return nil, fmt.Errorf("unexpected <%s> before stream features", se.Name.Local)
+		}
+	}
+}
+
+// nextElement returns the next start element, failing on stream errors and
+// the end of the stream.
+func (c *conn) nextElement() (xml.StartElement, error) {
+	for {
+		tok, err := c.dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space == nsStream && t.Name.Local == "error" {
+				var se struct {
+					Condition struct {
+						XMLName xml.Name
+					} `xml:",any"`
+					Text string `xml:"text"`
+				}
+				_ = c.dec.DecodeElement(&se, &t)
+				return t, fmt.Errorf("xmpp stream error: %s %s", se.Condition.XMLName.Local, se.Text)
+			}
+			return t, nil
+		case xml.EndElement:
+			if t.Name.Space == nsStream && t.Name.Local == "stream" {
+				return xml.StartElement{}, io.EOF
+			}
+		}
+	}
+}
+
+// dialOptions holds what negotiate needs to open a session.
+type dialOptions struct {
+	jid       string // bare JID
+	password  string
+	server    string // host:port, empty for SRV lookup
+	directTLS bool
+	tlsConfig *tls.Config
+}
+
+// dial connects and negotiates TLS, SASL and resource binding.
+func dial(ctx context.Context, opts dialOptions) (*conn, error) {
+	local, domain, _ := splitJID(opts.jid)
+
+	dialer := net.Dialer{Timeout: 15 * time.Second, KeepAlive: 30 * time.Second}
+	raw, err := dialer.DialContext(ctx, "tcp", resolveServer(ctx, domain, opts.server, opts.directTLS))
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := opts.tlsConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: domain, MinVersion: tls.VersionTLS12}
+	}
+
+	c := &conn{raw: raw}
+	ok := false
+	defer func() {
+		if !ok {
+			raw.Close()
+		}
+	}()
+	// Negotiation must not outlive ctx.
+	stop := context.AfterFunc(ctx, func() { _ = raw.SetDeadline(time.Now()) })
+	defer stop()
+
+	secure := false
+	if opts.directTLS {
+		tlsConn := tls.Client(raw, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, fmt.Errorf("tls handshake: %w", err)
+		}
+		c.raw, secure = tlsConn, true
+	}
+
+	features, err := c.openStream(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	if !secure {
+		if features.StartTLS == nil {
+			return nil, errors.New("server does not offer STARTTLS")
+		}
+		if err := c.writeRaw("<starttls xmlns='" + nsTLS + "'/>"); err != nil {
+			return nil, err
+		}
+		se, err := c.nextElement()
+		if err != nil {
+			return nil, err
+		}
+		if se.Name.Local != "proceed" {
+			return nil, errors.New("server refused STARTTLS")
+		}
+		_ = c.dec.Skip()
+		tlsConn := tls.Client(c.raw, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, fmt.Errorf("tls handshake: %w", err)
+		}
+		c.raw = tlsConn
+		if features, err = c.openStream(domain); err != nil {
+			return nil, err
+		}
+	}
+
+	if features.Mechanisms == nil {
+		return nil, errors.New("server offers no SASL mechanisms")
+	}
+	if err := c.authenticate(features.Mechanisms.List, local, opts.password); err != nil {
+		return nil, err
+	}
+
+	if features, err = c.openStream(domain); err != nil {
+		return nil, err
+	}
+	if features.Bind == nil {
+		return nil, errors.New("server does not offer resource binding")
+	}
+	if err := c.bind(); err != nil {
+		return nil, err
+	}
+	if features.Session != nil && features.Session.Optional == nil {
+		if _, err := c.syncIQ("set", "", "sess", "<session xmlns='"+nsSession+"'/>"); err != nil {
+			return nil, err
+		}
+	}
+
+	_ = c.raw.SetDeadline(time.Time{})
+	ok = true
+	return c, nil
+}
+
+func (c *conn) authenticate(offered []string, username, password string) error {
+	mech, err := chooseMechanism(offered, username, password)
+	if err != nil {
+		return err
+	}
+	initial, err := mech.next(nil)
+	if err != nil {
+		return err
+	}
+	if err := c.writeRaw(fmt.Sprintf("<auth xmlns='%s' mechanism='%s'>%s</auth>",
+		nsSASL, mech.name(), saslPayload(initial))); err != nil {
+		return err
+	}
+
+	for {
+		se, err := c.nextElement()
+		if err != nil {
+			return err
+		}
+		var body struct {
+			Text      string `xml:",chardata"`
+			Condition struct {
+				XMLName xml.Name
+			} `xml:",any"`
+		}
+		if err := c.dec.DecodeElement(&body, &se); err != nil {
+			return err
+		}
+		data, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(body.Text))
+
+		switch se.Name.Local {
+		case "challenge":
+			resp, err := mech.next(data)
+			if err != nil {
+				_ = c.writeRaw("<abort xmlns='" + nsSASL + "'/>")
+				return fmt.Errorf("%w: %v", errAuth, err)
+			}
+			if err := c.writeRaw(fmt.Sprintf("<response xmlns='%s'>%s</response>", nsSASL, saslPayload(resp))); err != nil {
+				return err
+			}
+		case "success":
+			if err := mech.verify(data); err != nil && mech.name() != "PLAIN" {
+				// Without a valid server signature the server may be an impostor.
+				return fmt.Errorf("%w: %v", errAuth, err)
+			}
+			return nil
+		case "failure":
+			return fmt.Errorf("%w: %s", errAuth, body.Condition.XMLName.Local)
+		default:
+			return fmt.Errorf("unexpected <%s> during SASL", se.Name.Local)
+		}
+	}
+}
+
+func (c *conn) bind() error {
+	payload, err := c.syncIQ("set", "", "bind",
+		"<bind xmlns='"+nsBind+"'><resource>"+resource+"</resource></bind>")
+	if err != nil {
+		return err
+	}
+	var b struct {
+		JID string `xml:"jid"`
+	}
+	if err := xml.Unmarshal(payload, &b); err != nil || b.JID == "" {
+		return errors.New("server did not return a bound JID")
+	}
+	c.jid = b.JID
+	return nil
+}
+
+// syncIQ sends an IQ and reads its reply before the read loop starts.
+func (c *conn) syncIQ(typ, to, id, payload string) ([]byte, error) {
+	stanza := "<iq type='" + typ + "' id='" + id + "'"
+	if to != "" {
+		stanza += " to='" + xmlEscape(to) + "'"
+	}
+	if err := c.writeRaw(stanza + ">" + payload + "</iq>"); err != nil {
+		return nil, err
+	}
+	for {
+		se, err := c.nextElement()
+		if err != nil {
+			return nil, err
+		}
+		if se.Name.Local != "iq" {
+			_ = c.dec.Skip()
+			continue
+		}
+		var reply iq
+		if err := c.dec.DecodeElement(&reply, &se); err != nil {
+			return nil, err
+		}
+		if reply.ID != id {
+			continue
+		}
+		if reply.Type == "error" {
+			if reply.Error != nil {
+				return nil, reply.Error
+			}
+			return nil, fmt.Errorf("iq %s failed", id)
+		}
+		return reply.Payload, nil
+	}
+}
+
+// resolveServer returns the address to dial: the configured server, the
+// highest-priority SRV target, or the domain on the default port.
+func resolveServer(ctx context.Context, domain, server string, directTLS bool) string {
+	if server != "" {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			port := "5222"
+			if directTLS {
+				port = "5223"
+			}
+			return net.JoinHostPort(server, port)
+		}
+		return server
+	}
+
+	service, port := "xmpp-client", "5222"
+	if directTLS {
+		service, port = "xmpps-client", "5223"
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if _, addrs, err := net.DefaultResolver.LookupSRV(lookupCtx, service, "tcp", domain); err == nil {
+		for _, a := range addrs {
+			if target := strings.TrimSuffix(a.Target, "."); target != "" {
+				return net.JoinHostPort(target, strconv.Itoa(int(a.Port)))
+			}
+		}
+	}
+	return net.JoinHostPort(domain, port)
+}
+
+func saslPayload(data []byte) string {
+	if len(data) == 0 {
+		return "="
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// splitJID splits local@domain/resource.
+func splitJID(jid string) (local, domain, res string) {
+	bare, res, _ := strings.Cut(jid, "/")
+	if l, d, ok := strings.Cut(bare, "@"); ok {
+		return l, d, res
+	}
+	return "", bare, res
+}
+
+// bareJID strips the resource and lowercases the local and domain parts.
+func bareJID(jid string) string {
+	bare, _, _ := strings.Cut(jid, "/")
+	return strings.ToLower(bare)
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
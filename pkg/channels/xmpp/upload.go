@@ -0,0 +1,202 @@
+package xmpp
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// errNoUpload means the server has no HTTP File Upload service.
+var errNoUpload = errors.New("xmpp server does not support HTTP file upload")
+
+type uploadSlot struct {
+	Put struct {
+		URL     string `xml:"url,attr"`
+		Headers []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"header"`
+	} `xml:"put"`
+	Get struct {
+		URL string `xml:"url,attr"`
+	} `xml:"get"`
+}
+
+// SendMedia implements the channels.MediaSender interface. Files are uploaded
+// through the server's HTTP File Upload service (XEP-0363) and shared as
+// links with out-of-band data, which clients display inline.
+func (c *XMPPChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+
+	store := c.GetMediaStore()
+	if store == nil {
+		return nil, fmt.Errorf("no media store available: %w", channels.ErrSendFailed)
+	}
+
+	var ids []string
+	for _, part := range msg.Parts {
+		localPath, err := store.Resolve(part.Ref)
+		if err != nil {
+			logger.ErrorCF("xmpp", "Failed to resolve media ref", map[string]any{
+				"ref":   part.Ref,
+				"error": err.Error(),
+			})
+			continue
+		}
+
+		getURL, err := c.uploadFile(ctx, localPath, part.Filename, part.ContentType)
+		if err != nil {
+			logger.ErrorCF("xmpp", "Failed to upload media", map[string]any{
+				"filename": part.Filename,
+				"error":    err.Error(),
+			})
+			return ids, fmt.Errorf("xmpp send media: %w", err)
+		}
+
+		// The body must be exactly the URL for clients to inline the file,
+		// so captions go in a message of their own.
+		if part.Caption != "" {
+			id := c.nextID()
+			if err := c.sendMessage(msg.ChatID, outMessage{ID: id, Body: part.Caption}); err != nil {
+				return ids, err
+			}
+			ids = append(ids, id)
+		}
+		id := c.nextID()
+		if err := c.sendMessage(msg.ChatID, outMessage{
+			ID:   id,
+			Body: getURL,
+			OOB:  &oobData{URL: getURL},
+		}); err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// uploadFile requests an upload slot, PUTs the file and returns its
+// download URL.
+func (c *XMPPChannel) uploadFile(ctx context.Context, localPath, filename, contentType string) (string, error) {
+	service, err := c.discoverUpload(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	if filename == "" {
+		filename = filepath.Base(localPath)
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	request := fmt.Sprintf("<request xmlns='%s' filename='%s' size='%d' content-type='%s'/>",
+		nsUpload, xmlEscape(filename), info.Size(), xmlEscape(contentType))
+	payload, err := c.sendIQ(ctx, "get", service, request)
+	if err != nil {
+		return "", fmt.Errorf("upload slot: %w", err)
+	}
+	var slot uploadSlot
+	if err := xml.Unmarshal(payload, &slot); err != nil || slot.Put.URL == "" || slot.Get.URL == "" {
+		return "", errors.New("upload slot: invalid response")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, slot.Put.URL, f)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", contentType)
+	for _, h := range slot.Put.Headers {
+		// XEP-0363 only lets the server set these headers.
+		switch http.CanonicalHeaderKey(h.Name) {
+		case "Authorization", "Cookie", "Expires":
+			req.Header.Set(h.Name, strings.TrimSpace(h.Value))
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", channels.ClassifyNetError(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", channels.ClassifySendError(resp.StatusCode,
+			fmt.Errorf("upload PUT: status %d", resp.StatusCode))
+	}
+	return slot.Get.URL, nil
+}
+
+// discoverUpload finds the upload service among the server's disco items
+// and caches it.
+func (c *XMPPChannel) discoverUpload(ctx context.Context) (string, error) {
+	c.uploadMu.Lock()
+	defer c.uploadMu.Unlock()
+	if c.uploadService != "" {
+		return c.uploadService, nil
+	}
+
+	_, domain, _ := splitJID(c.jid)
+	candidates := []string{domain}
+	payload, err := c.sendIQ(ctx, "get", domain, "<query xmlns='"+nsDiscoItems+"'/>")
+	if err != nil {
+		return "", fmt.Errorf("disco items: %w", err)
+	}
+	var items struct {
+		Items []struct {
+			JID string `xml:"jid,attr"`
+		} `xml:"item"`
+	}
+	_ = xml.Unmarshal(payload, &items)
+	for _, item := range items.Items {
+		candidates = append(candidates, item.JID)
+	}
+
+	for _, jid := range candidates {
+		payload, err := c.sendIQ(ctx, "get", jid, "<query xmlns='"+nsDiscoInfo+"'/>")
+		if err != nil {
+			continue
+		}
+		var info struct {
+			Features []struct {
+				Var string `xml:"var,attr"`
+			} `xml:"feature"`
+		}
+		_ = xml.Unmarshal(payload, &info)
+		for _, f := range info.Features {
+			if f.Var == nsUpload {
+				c.uploadService = jid
+				logger.DebugCF("xmpp", "Found HTTP upload service", map[string]any{
+					"service": jid,
+				})
+				return jid, nil
+			}
+		}
+	}
+	return "", errNoUpload
+}
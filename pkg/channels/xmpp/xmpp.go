@@ -0,0 +1,808 @@
+package xmpp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	// maxMessageLength stays well under common server stanza limits
+	// (ejabberd defaults to 64 KiB).
+	maxMessageLength  = 10000
+	streamThrottle    = time.Second
+	keepaliveInterval = time.Minute
+	maxBackoff        = time.Minute
+)
+
+// XMPPChannel connects to an XMPP server as a client, answering 1:1 chats
+// and the multi-user chat rooms it joins.
+type XMPPChannel struct {
+	*channels.BaseChannel
+	bc     *config.Channel
+	config *config.XMPPSettings
+	jid    string // bare JID
+	nick   string
+	rooms  map[string]struct{}
+
+	// tlsConfig overrides the default TLS settings; tests use it to trust
+	// their server certificate.
+	tlsConfig  *tls.Config
+	httpClient *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	connMu sync.RWMutex
+	conn   *conn
+
+	pendingMu sync.Mutex
+	pending   map[string]chan *iq
+
+	uploadMu      sync.Mutex
+	uploadService string
+
+	idPrefix string
+	idSeq    atomic.Uint64
+}
+
+func NewXMPPChannel(
+	bc *config.Channel,
+	cfg *config.XMPPSettings,
+	messageBus *bus.MessageBus,
+) (*XMPPChannel, error) {
+	jid := bareJID(strings.TrimSpace(cfg.JID))
+	local, domain, _ := splitJID(jid)
+	if local == "" || domain == "" {
+		return nil, fmt.Errorf("xmpp jid must look like user@example.com")
+	}
+	if cfg.Password.String() == "" {
+		return nil, fmt.Errorf("xmpp password is required")
+	}
+
+	nick := strings.TrimSpace(cfg.Nick)
+	if nick == "" {
+		nick = local
+	}
+	rooms := make(map[string]struct{}, len(cfg.Rooms))
+	for _, room := range cfg.Rooms {
+		if room = bareJID(strings.TrimSpace(room)); room != "" {
+			rooms[room] = struct{}{}
+		}
+	}
+
+	base := channels.NewBaseChannel(config.ChannelXMPP, cfg, messageBus, bc.AllowFrom,
+		channels.WithMaxMessageLength(maxMessageLength),
+		channels.WithGroupTrigger(bc.GroupTrigger),
+		channels.WithReasoningChannelID(bc.ReasoningChannelID),
+	)
+
+	prefix := make([]byte, 4)
+	_, _ = rand.Read(prefix)
+
+	return &XMPPChannel{
+		BaseChannel: base,
+		bc:          bc,
+		config:      cfg,
+		jid:         jid,
+		nick:        nick,
+		rooms:       rooms,
+		httpClient:  &http.Client{Timeout: 2 * time.Minute},
+		pending:     make(map[string]chan *iq),
+		idPrefix:    "pc" + hex.EncodeToString(prefix),
+	}, nil
+}
+
+func (c *XMPPChannel) Start(ctx context.Context) error {
+	logger.InfoCF("xmpp", "Starting XMPP channel", map[string]any{
+		"jid": c.jid,
+	})
+
+	c.ctx, c.cancel = context.WithCancel(ctx)
+
+	conn, err := c.connect()
+	if err != nil {
+		c.cancel()
+		return fmt.Errorf("xmpp connect failed: %w", err)
+	}
+	go c.run(conn)
+
+	c.SetRunning(true)
+	logger.InfoC("xmpp", "XMPP channel started")
+	return nil
+}
+
+func (c *XMPPChannel) Stop(ctx context.Context) error {
+	logger.InfoC("xmpp", "Stopping XMPP channel")
+
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.connMu.Lock()
+	if c.conn != nil {
+		c.conn.close()
+		c.conn = nil
+	}
+	c.connMu.Unlock()
+
+	c.SetRunning(false)
+	logger.InfoC("xmpp", "XMPP channel stopped")
+	return nil
+}
+
+func (c *XMPPChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+
+	id := c.nextID()
+	if err := c.sendMessage(msg.ChatID, outMessage{
+		ID:        id,
+		Body:      msg.Content,
+		ChatState: chatState("active"),
+	}); err != nil {
+		return nil, err
+	}
+	return []string{id}, nil
+}
+
+// EditMessage implements channels.MessageEditor with a last message
+// correction (XEP-0308). Corrections always reference the original ID.
+func (c *XMPPChannel) EditMessage(ctx context.Context, chatID, messageID, content string) error {
+	return c.sendMessage(chatID, outMessage{
+		ID:   c.nextID(),
+		Body: content,
+		Replace: &struct {
+			ID string `xml:"id,attr"`
+		}{ID: messageID},
+	})
+}
+
+// SendPlaceholder implements channels.PlaceholderCapable.
+func (c *XMPPChannel) SendPlaceholder(ctx context.Context, chatID string) (string, error) {
+	if !c.bc.Placeholder.Enabled {
+		return "", nil
+	}
+
+	id := c.nextID()
+	if err := c.sendMessage(chatID, outMessage{ID: id, Body: c.bc.Placeholder.GetRandomText()}); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// StartTyping implements channels.TypingCapable with chat state
+// notifications (XEP-0085). Clients show "composing" until the next state.
+func (c *XMPPChannel) StartTyping(ctx context.Context, chatID string) (func(), error) {
+	if err := c.sendMessage(chatID, outMessage{ChatState: chatState("composing")}); err != nil {
+		return func() {}, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			_ = c.sendMessage(chatID, outMessage{ChatState: chatState("active")})
+		})
+	}, nil
+}
+
+// BeginStream implements channels.StreamingCapable by sending the first
+// chunk and correcting it as more text arrives.
+func (c *XMPPChannel) BeginStream(ctx context.Context, chatID string) (channels.Streamer, error) {
+	if !c.config.Streaming {
+		return nil, fmt.Errorf("streaming disabled in config")
+	}
+	if _, _, err := c.route(chatID); err != nil {
+		return nil, err
+	}
+	return &xmppStreamer{channel: c, chatID: chatID}, nil
+}
+
+// xmppStreamer shows partial output by correcting a single message. If an
+// update fails it stops editing, and Finalize still delivers the final text.
+type xmppStreamer struct {
+	channel   *XMPPChannel
+	chatID    string
+	messageID string
+	lastAt    time.Time
+	failed    bool
+	mu        sync.Mutex
+}
+
+func (s *xmppStreamer) Update(ctx context.Context, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failed || strings.TrimSpace(content) == "" {
+		return nil
+	}
+	now := time.Now()
+	if s.messageID != "" && now.Sub(s.lastAt) < streamThrottle {
+		return nil
+	}
+
+	var err error
+	if s.messageID == "" {
+		id := s.channel.nextID()
+		if err = s.channel.sendMessage(s.chatID, outMessage{ID: id, Body: content}); err == nil {
+			s.messageID = id
+		}
+	} else {
+		err = s.channel.EditMessage(ctx, s.chatID, s.messageID, content)
+	}
+	if err != nil {
+		logger.WarnCF("xmpp", "Stream update failed, disabling streaming", map[string]any{
+			"error": err.Error(),
+		})
+		s.failed = true
+		return nil
+	}
+	s.lastAt = now
+	return nil
+}
+
+func (s *xmppStreamer) Finalize(ctx context.Context, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.messageID != "" {
+		if err := s.channel.EditMessage(ctx, s.chatID, s.messageID, content); err != nil {
+			return fmt.Errorf("xmpp finalize: %w", err)
+		}
+		return nil
+	}
+
+	id := s.channel.nextID()
+	if err := s.channel.sendMessage(s.chatID, outMessage{
+		ID:        id,
+		Body:      content,
+		ChatState: chatState("active"),
+	}); err != nil {
+		return fmt.Errorf("xmpp finalize: %w", err)
+	}
+	s.messageID = id
+	return nil
+}
+
+// Cancel retracts the partial message (XEP-0424).
+func (s *xmppStreamer) Cancel(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.messageID == "" {
+		return
+	}
+	_ = s.channel.sendMessage(s.chatID, outMessage{
+		ID:   s.channel.nextID(),
+		Body: "[message retracted]",
+		Retract: &struct {
+			ID string `xml:"id,attr"`
+		}{ID: s.messageID},
+		Fallback: &struct {
+			For string `xml:"for,attr"`
+		}{For: nsRetract},
+	})
+	s.messageID = ""
+}
+
+// route returns the live connection and message type for a chat ID: a room
+// JID is a groupchat; a bare or occupant JID is a 1:1 chat.
+func (c *XMPPChannel) route(chatID string) (*conn, string, error) {
+	chatID = strings.TrimSpace(chatID)
+	if chatID == "" {
+		return nil, "", fmt.Errorf("xmpp chat ID is empty: %w", channels.ErrSendFailed)
+	}
+
+	c.connMu.RLock()
+	conn := c.conn
+	c.connMu.RUnlock()
+	if conn == nil {
+		return nil, "", fmt.Errorf("xmpp not connected: %w", channels.ErrTemporary)
+	}
+
+	msgType := "chat"
+	if _, _, res := splitJID(chatID); res == "" && c.isRoom(chatID) {
+		msgType = "groupchat"
+	}
+	return conn, msgType, nil
+}
+
+func (c *XMPPChannel) sendMessage(chatID string, msg outMessage) error {
+	conn, msgType, err := c.route(chatID)
+	if err != nil {
+		return err
+	}
+	msg.To = chatID
+	msg.Type = msgType
+	if err := conn.send(msg); err != nil {
+		return channels.ClassifyNetError(err)
+	}
+	return nil
+}
+
+// sendIQ sends an IQ request and waits for its result payload.
+func (c *XMPPChannel) sendIQ(ctx context.Context, typ, to, payload string) ([]byte, error) {
+	c.connMu.RLock()
+	conn := c.conn
+	c.connMu.RUnlock()
+	if conn == nil {
+		return nil, fmt.Errorf("xmpp not connected: %w", channels.ErrTemporary)
+	}
+
+	id := c.nextID()
+	reply := make(chan *iq, 1)
+	c.pendingMu.Lock()
+	c.pending[id] = reply
+	c.pendingMu.Unlock()
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+	}()
+
+	stanza := "<iq type='" + typ + "' id='" + id + "'"
+	if to != "" {
+		stanza += " to='" + xmlEscape(to) + "'"
+	}
+	if err := conn.writeRaw(stanza + ">" + payload + "</iq>"); err != nil {
+		return nil, channels.ClassifyNetError(err)
+	}
+
+	select {
+	case res, ok := <-reply:
+		if !ok {
+			return nil, fmt.Errorf("xmpp connection closed: %w", channels.ErrTemporary)
+		}
+		if res.Type == "error" {
+			if res.Error != nil {
+				return nil, res.Error
+			}
+			return nil, errors.New("xmpp iq failed")
+		}
+		return res.Payload, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *XMPPChannel) connect() (*conn, error) {
+	conn, err := dial(c.ctx, dialOptions{
+		jid:       c.jid,
+		password:  c.config.Password.String(),
+		server:    strings.TrimSpace(c.config.Server),
+		directTLS: c.config.DirectTLS,
+		tlsConfig: c.tlsConfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.writeRaw("<presence/>"); err != nil {
+		conn.close()
+		return nil, err
+	}
+	for room := range c.rooms {
+		join := fmt.Sprintf("<presence to='%s'><x xmlns='%s'><history maxstanzas='0'/></x></presence>",
+			xmlEscape(room+"/"+c.nick), nsMUC)
+		if err := conn.writeRaw(join); err != nil {
+			conn.close()
+			return nil, err
+		}
+	}
+
+	logger.InfoCF("xmpp", "Connected", map[string]any{
+		"jid":   conn.jid,
+		"rooms": len(c.rooms),
+	})
+	return conn, nil
+}
+
+// run serves the stream until the channel stops, reconnecting with
+// exponential backoff.
+func (c *XMPPChannel) run(conn *conn) {
+	backoff := time.Second
+	for {
+		if conn != nil {
+			started := time.Now()
+			err := c.serve(conn)
+			if c.ctx.Err() != nil {
+				return
+			}
+			if time.Since(started) > maxBackoff {
+				backoff = time.Second
+			}
+			logger.WarnCF("xmpp", "Stream closed; reconnecting", map[string]any{
+				"error":       fmt.Sprint(err),
+				"retry_after": backoff.String(),
+			})
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+
+		var err error
+		if conn, err = c.connect(); err != nil {
+			logger.WarnCF("xmpp", "Reconnect failed", map[string]any{
+				"error": err.Error(),
+			})
+			conn = nil
+		}
+	}
+}
+
+// serve reads stanzas until the stream breaks.
+func (c *XMPPChannel) serve(conn *conn) error {
+	c.connMu.Lock()
+	c.conn = conn
+	c.connMu.Unlock()
+
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		c.connMu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+		}
+		c.connMu.Unlock()
+		conn.raw.Close()
+		c.failPending()
+	}()
+
+	// Whitespace keepalives stop idle NATs and servers from dropping us.
+	go func() {
+		ticker := time.NewTicker(keepaliveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = conn.writeRaw(" ")
+			}
+		}
+	}()
+
+	for {
+		se, err := conn.nextElement()
+		if err != nil {
+			return err
+		}
+		switch se.Name.Local {
+		case "message":
+			var m message
+			if err := conn.dec.DecodeElement(&m, &se); err != nil {
+				return err
+			}
+			c.handleMessage(m)
+		case "presence":
+			var p presence
+			if err := conn.dec.DecodeElement(&p, &se); err != nil {
+				return err
+			}
+			c.handlePresence(conn, p)
+		case "iq":
+			var q iq
+			if err := conn.dec.DecodeElement(&q, &se); err != nil {
+				return err
+			}
+			c.handleIQ(conn, &q)
+		default:
+			if err := conn.dec.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *XMPPChannel) failPending() {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+}
+
+func (c *XMPPChannel) handleIQ(conn *conn, q *iq) {
+	switch q.Type {
+	case "result", "error":
+		c.pendingMu.Lock()
+		ch, ok := c.pending[q.ID]
+		delete(c.pending, q.ID)
+		c.pendingMu.Unlock()
+		if ok {
+			ch <- q
+		}
+		return
+	}
+
+	var child element
+	_ = xml.Unmarshal(q.Payload, &child)
+	reply := "<iq type='result' id='" + xmlEscape(q.ID) + "' to='" + xmlEscape(q.From) + "'"
+	switch {
+	case q.Type == "get" && child.XMLName.Space == nsPing:
+		_ = conn.writeRaw(reply + "/>")
+	case q.Type == "get" && child.XMLName.Space == nsDiscoInfo:
+		var features strings.Builder
+		for _, ns := range []string{nsDiscoInfo, nsChatStates, nsCorrect, nsOOB, nsPing} {
+			features.WriteString("<feature var='" + ns + "'/>")
+		}
+		_ = conn.writeRaw(reply + "><query xmlns='" + nsDiscoInfo + "'>" +
+			"<identity category='client' type='bot' name='PicoClaw'/>" + features.String() + "</query></iq>")
+	default:
+		_ = conn.writeRaw("<iq type='error' id='" + xmlEscape(q.ID) + "' to='" + xmlEscape(q.From) + "'>" +
+			"<error type='cancel'><service-unavailable xmlns='" + nsStanzas + "'/></error></iq>")
+	}
+}
+
+func (c *XMPPChannel) handlePresence(conn *conn, p presence) {
+	bare := bareJID(p.From)
+	switch {
+	case p.Type == "error" && c.isRoom(bare):
+		logger.ErrorCF("xmpp", "Could not join room", map[string]any{
+			"room":  bare,
+			"error": fmt.Sprint(p.Error),
+		})
+	case p.Type == "subscribe":
+		// Approve contact requests from allowed users so they can see the
+		// bot online.
+		sender := bus.SenderInfo{
+			Platform:    "xmpp",
+			PlatformID:  bare,
+			CanonicalID: identity.BuildCanonicalID("xmpp", bare),
+		}
+		if c.IsAllowedSender(sender) {
+			_ = conn.writeRaw("<presence type='subscribed' to='" + xmlEscape(bare) + "'/>")
+		}
+	case p.MUCUser != nil && c.isRoom(bare):
+		for _, s := range p.MUCUser.Status {
+			if s.Code == 110 {
+				logger.InfoCF("xmpp", "Joined room", map[string]any{"room": bare})
+			}
+		}
+	}
+}
+
+// handleMessage turns a chat or groupchat message into an inbound message.
+func (c *XMPPChannel) handleMessage(m message) {
+	// Errors, room history, corrections of earlier messages and bodiless
+	// notifications (chat states, receipts) are not prompts.
+	if m.Type == "error" || m.Delay != nil || m.Replace != nil {
+		return
+	}
+	body := strings.TrimSpace(m.Body)
+	if body == "" && len(m.OOB) == 0 {
+		return
+	}
+
+	bare := bareJID(m.From)
+	_, _, nickname := splitJID(m.From)
+
+	var chatID, chatType, senderID, displayName string
+	switch {
+	case m.Type == "groupchat":
+		// Room subjects and status come from the bare room JID; our own
+		// messages are echoed back with our nick.
+		if !c.isRoom(bare) || nickname == "" || nickname == c.nick || m.Subject != nil {
+			return
+		}
+		chatID, chatType, senderID, displayName = bare, "group", m.From, nickname
+	case c.isRoom(bare):
+		// Private message from a room occupant.
+		if nickname == "" {
+			return
+		}
+		chatID, chatType, senderID, displayName = m.From, "direct", m.From, nickname
+	default:
+		if bare == c.jid {
+			return
+		}
+		local, _, _ := splitJID(bare)
+		chatID, chatType, senderID, displayName = bare, "direct", bare, local
+	}
+
+	sender := bus.SenderInfo{
+		Platform:    "xmpp",
+		PlatformID:  senderID,
+		CanonicalID: identity.BuildCanonicalID("xmpp", senderID),
+		Username:    displayName,
+		DisplayName: displayName,
+	}
+	if !c.IsAllowedSender(sender) {
+		logger.DebugCF("xmpp", "Message rejected by allowlist", map[string]any{
+			"sender": senderID,
+		})
+		return
+	}
+
+	content := body
+	mentioned := false
+	if chatType == "group" {
+		mentioned = isMentioned(content, c.nick)
+		if mentioned {
+			content = stripMention(content, c.nick)
+		}
+		respond, cleaned := c.ShouldRespondInGroup(mentioned, content)
+		if !respond {
+			return
+		}
+		content = cleaned
+	}
+
+	messageID := m.ID
+	if messageID == "" {
+		messageID = c.nextID()
+	}
+
+	var mediaPaths []string
+	scope := channels.BuildMediaScope("xmpp", chatID, messageID)
+	for _, oob := range m.OOB {
+		ref, annotation := c.downloadOOB(oob.URL, scope)
+		if ref == "" {
+			continue
+		}
+		// Clients send the URL as the body so others can still open it.
+		if strings.TrimSpace(content) == strings.TrimSpace(oob.URL) {
+			content = ""
+		}
+		mediaPaths = append(mediaPaths, ref)
+		if content != "" {
+			content += "\n"
+		}
+		content += annotation
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+
+	inboundCtx := bus.InboundContext{
+		Channel:   c.Name(),
+		Account:   c.jid,
+		ChatID:    chatID,
+		ChatType:  chatType,
+		SenderID:  senderID,
+		MessageID: messageID,
+		Mentioned: mentioned,
+		Raw: map[string]string{
+			"from":     m.From,
+			"type":     m.Type,
+			"platform": "xmpp",
+		},
+	}
+
+	logger.DebugCF("xmpp", "Received message", map[string]any{
+		"sender":  senderID,
+		"chat_id": chatID,
+		"preview": utils.Truncate(content, 50),
+	})
+
+	c.HandleInboundContext(c.ctx, chatID, content, mediaPaths, inboundCtx, sender)
+}
+
+// downloadOOB fetches an out-of-band file (usually an HTTP upload link) and
+// stores it in the media store.
+func (c *XMPPChannel) downloadOOB(rawURL, scope string) (string, string) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", ""
+	}
+	name, _ := url.PathUnescape(path.Base(u.Path))
+	if name == "" || name == "/" || name == "." {
+		name = "attachment"
+	}
+
+	localPath := utils.DownloadFile(u.String(), name, utils.DownloadOptions{LoggerPrefix: "xmpp"})
+	if localPath == "" {
+		return "", ""
+	}
+
+	ref := localPath
+	if store := c.GetMediaStore(); store != nil {
+		if stored, err := store.Store(localPath, media.MediaMeta{
+			Filename:      name,
+			Source:        "xmpp",
+			CleanupPolicy: media.CleanupPolicyDeleteOnCleanup,
+		}, scope); err == nil {
+			ref = stored
+		}
+	}
+
+	switch {
+	case utils.IsAudioFile(name, ""):
+		return ref, fmt.Sprintf("[audio: %s]", name)
+	case isImage(name):
+		return ref, fmt.Sprintf("[image: %s]", name)
+	default:
+		return ref, fmt.Sprintf("[file: %s]", name)
+	}
+}
+
+func (c *XMPPChannel) isRoom(jid string) bool {
+	_, ok := c.rooms[bareJID(jid)]
+	return ok
+}
+
+func (c *XMPPChannel) nextID() string {
+	return c.idPrefix + "-" + strconv.FormatUint(c.idSeq.Add(1), 10)
+}
+
+func chatState(state string) *element {
+	return &element{XMLName: xml.Name{Space: nsChatStates, Local: state}}
+}
+
+// isMentioned reports whether nick appears in text as a whole word,
+// optionally prefixed with @.
+func isMentioned(text, nick string) bool {
+	return mentionIndex(text, nick) >= 0
+}
+
+func mentionIndex(text, nick string) int {
+	if nick == "" {
+		return -1
+	}
+	lower, target := strings.ToLower(text), strings.ToLower(nick)
+	for offset := 0; ; {
+		i := strings.Index(lower[offset:], target)
+		if i < 0 {
+			return -1
+		}
+		start, end := offset+i, offset+i+len(target)
+		if isBoundary(lower, start-1) && isBoundary(lower, end) {
+			return start
+		}
+		offset = start + 1
+	}
+}
+
+func isBoundary(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return true
+	}
+	r := rune(s[i])
+	return r < 0x80 && !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+}
+
+// stripMention removes an address to nick at the start of the message
+// ("nick: hi", "@nick hi"); mentions inside the text are kept.
+func stripMention(text, nick string) string {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(text), "@")
+	if len(trimmed) >= len(nick) && strings.EqualFold(trimmed[:len(nick)], nick) && isBoundary(trimmed, len(nick)) {
+		rest := strings.TrimLeft(trimmed[len(nick):], ":, ")
+		return strings.TrimSpace(rest)
+	}
+	return strings.TrimSpace(text)
+}
+
+func isImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp", ".heic":
+		return true
+	}
+	return false
+}
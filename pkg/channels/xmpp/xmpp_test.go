@@ -0,0 +1,462 @@
+package xmpp
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+// rawStanza captures any top-level stanza for assertions.
+type rawStanza struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+}
+
+func (s rawStanza) attr(name string) string {
+	for _, a := range s.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// fakeServer is a minimal XMPP server (STARTTLS, SASL PLAIN, bind, disco and
+// HTTP upload slots) plus the HTTP endpoint that receives uploads.
+type fakeServer struct {
+	ln        net.Listener
+	tlsServer *tls.Config
+	tlsClient *tls.Config
+	http      *httptest.Server
+
+	mu      sync.Mutex
+	stanzas []rawStanza
+	auth    string
+	conn    net.Conn
+	uploads []string // "authorization body"
+	ready   chan struct{}
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	fs := &fakeServer{ready: make(chan struct{})}
+
+	fs.http = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fs.mu.Lock()
+		fs.uploads = append(fs.uploads, r.Header.Get("Authorization")+" "+r.Header.Get("X-Evil")+string(body))
+		fs.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	fs.http.StartTLS()
+	t.Cleanup(fs.http.Close)
+
+	// The httptest certificate is valid for example.com.
+	fs.tlsServer = &tls.Config{Certificates: fs.http.TLS.Certificates}
+	fs.tlsClient = &tls.Config{
+		RootCAs:    fs.http.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs,
+		ServerName: "example.com",
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	fs.ln = ln
+	t.Cleanup(func() {
+		ln.Close()
+		fs.mu.Lock()
+		if fs.conn != nil {
+			fs.conn.Close()
+		}
+		fs.mu.Unlock()
+	})
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			fs.serve(conn)
+		}
+	}()
+	return fs
+}
+
+const header = "<?xml version='1.0'?><stream:stream xmlns='jabber:client' " +
+	"xmlns:stream='http://etherx.jabber.org/streams' from='example.com' id='s1' version='1.0'>"
+
+func nextStart(dec *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se, nil
+		}
+	}
+}
+
+// restart reads the client's stream header and answers with features.
+func restart(conn net.Conn, features string) (*xml.Decoder, error) {
+	dec := xml.NewDecoder(conn)
+	if _, err := nextStart(dec); err != nil {
+		return nil, err
+	}
+	_, err := io.WriteString(conn, header+"<stream:features>"+features+"</stream:features>")
+	return dec, err
+}
+
+func (fs *fakeServer) serve(conn net.Conn) {
+	dec, err := restart(conn, "<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'><required/></starttls>")
+	if err != nil {
+		return
+	}
+	if se, err := nextStart(dec); err != nil || se.Name.Local != "starttls" {
+		return
+	}
+	_, _ = io.WriteString(conn, "<proceed xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>")
+	tlsConn := tls.Server(conn, fs.tlsServer)
+	if tlsConn.Handshake() != nil {
+		return
+	}
+	conn = tlsConn
+
+	dec, err = restart(conn, "<mechanisms xmlns='urn:ietf:params:xml:ns:xmpp-sasl'><mechanism>PLAIN</mechanism></mechanisms>")
+	if err != nil {
+		return
+	}
+	var auth struct {
+		Text string `xml:",chardata"`
+	}
+	se, err := nextStart(dec)
+	if err != nil || dec.DecodeElement(&auth, &se) != nil {
+		return
+	}
+	plain, _ := base64.StdEncoding.DecodeString(auth.Text)
+	fs.mu.Lock()
+	fs.auth = string(plain)
+	fs.mu.Unlock()
+	_, _ = io.WriteString(conn, "<success xmlns='urn:ietf:params:xml:ns:xmpp-sasl'/>")
+
+	if dec, err = restart(conn, "<bind xmlns='urn:ietf:params:xml:ns:xmpp-bind'/>"); err != nil {
+		return
+	}
+	fs.mu.Lock()
+	fs.conn = conn
+	fs.mu.Unlock()
+
+	for {
+		se, err := nextStart(dec)
+		if err != nil {
+			return
+		}
+		var st rawStanza
+		if dec.DecodeElement(&st, &se) != nil {
+			return
+		}
+		fs.mu.Lock()
+		fs.stanzas = append(fs.stanzas, st)
+		fs.mu.Unlock()
+
+		if st.XMLName.Local == "presence" && st.attr("to") == "" {
+			close(fs.ready)
+		}
+		if st.XMLName.Local == "iq" {
+			fs.send(fs.iqReply(st))
+		}
+	}
+}
+
+func (fs *fakeServer) iqReply(st rawStanza) string {
+	open := "<iq type='result' id='" + st.attr("id") + "' from='" + st.attr("to") + "'>"
+	switch {
+	case strings.Contains(st.Inner, nsBind):
+		return open + "<bind xmlns='" + nsBind + "'><jid>bot@example.com/picoclaw</jid></bind></iq>"
+	case strings.Contains(st.Inner, nsDiscoItems):
+		return open + "<query xmlns='" + nsDiscoItems + "'><item jid='upload.example.com'/></query></iq>"
+	case strings.Contains(st.Inner, nsDiscoInfo) && st.attr("to") == "upload.example.com":
+		return open + "<query xmlns='" + nsDiscoInfo + "'><feature var='" + nsUpload + "'/></query></iq>"
+	case strings.Contains(st.Inner, nsUpload):
+		return open + "<slot xmlns='" + nsUpload + "'><put url='" + fs.http.URL + "/put/report.txt'>" +
+			"<header name='Authorization'>Bearer slot</header><header name='X-Evil'>1</header></put>" +
+			"<get url='https://files.example.com/report.txt'/></slot></iq>"
+	}
+	return open + "</iq>"
+}
+
+func (fs *fakeServer) send(stanza string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.conn != nil {
+		_, _ = io.WriteString(fs.conn, stanza)
+	}
+}
+
+func (fs *fakeServer) recorded(name string) []rawStanza {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	var out []rawStanza
+	for _, st := range fs.stanzas {
+		if st.XMLName.Local == name {
+			out = append(out, st)
+		}
+	}
+	return out
+}
+
+func newTestChannel(t *testing.T, fs *fakeServer, bc *config.Channel, settings config.XMPPSettings) (*XMPPChannel, *bus.MessageBus) {
+	t.Helper()
+	mb := bus.NewMessageBus()
+	t.Cleanup(mb.Close)
+
+	if bc == nil {
+		bc = &config.Channel{}
+	}
+	settings.JID = "bot@example.com"
+	settings.Password = *config.NewSecureString("secret")
+	settings.Server = fs.ln.Addr().String()
+	ch, err := NewXMPPChannel(bc, &settings, mb)
+	if err != nil {
+		t.Fatalf("NewXMPPChannel: %v", err)
+	}
+	ch.tlsConfig = fs.tlsClient
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+
+	select {
+	case <-fs.ready:
+	case <-time.After(3 * time.Second):
+		t.Fatal("no initial presence")
+	}
+	return ch, mb
+}
+
+func TestNewXMPPChannel_Validation(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	cases := map[string]config.XMPPSettings{
+		"missing jid":      {Password: *config.NewSecureString("p")},
+		"domain only":      {JID: "example.com", Password: *config.NewSecureString("p")},
+		"missing password": {JID: "bot@example.com"},
+	}
+	for name, cfg := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewXMPPChannel(&config.Channel{}, &cfg, mb); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestSCRAMSHA1(t *testing.T) {
+	// Example exchange from RFC 5802, section 5.
+	s := newSCRAM("SCRAM-SHA-1", sha1.New, "user", "pencil")
+	s.clientNonce = "fyko+d2lbbFgONRv9qkxdawL"
+
+	first, _ := s.next(nil)
+	if string(first) != "n,,n=user,r=fyko+d2lbbFgONRv9qkxdawL" {
+		t.Fatalf("client-first = %q", first)
+	}
+	final, err := s.next([]byte("r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,s=QSXCR+Q6sek8bf92,i=4096"))
+	if err != nil {
+		t.Fatalf("client-final: %v", err)
+	}
+	if string(final) != "c=biws,r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,p=v0X8v3Bz2T0CJGbJQyF0X+HI4Ts=" {
+		t.Fatalf("client-final = %q", final)
+	}
+	if err := s.verify([]byte("v=rmF9pqV8S7suAoZWja4dJRkFsKQ=")); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if err := s.verify([]byte("v=AAAA")); err == nil {
+		t.Fatal("expected signature mismatch")
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		text      string
+		mentioned bool
+		stripped  string
+	}{
+		{"picobot: status?", true, "status?"},
+		{"@PicoBot, help", true, "help"},
+		{"ask picobot later", true, "ask picobot later"},
+		{"picobots unite", false, "picobots unite"},
+		{"hello", false, "hello"},
+	}
+	for _, tt := range tests {
+		if got := isMentioned(tt.text, "picobot"); got != tt.mentioned {
+			t.Errorf("isMentioned(%q) = %v", tt.text, got)
+		}
+		if got := stripMention(tt.text, "picobot"); got != tt.stripped {
+			t.Errorf("stripMention(%q) = %q, want %q", tt.text, got, tt.stripped)
+		}
+	}
+}
+
+func TestInboundDirectAndRoomMessages(t *testing.T) {
+	fs := newFakeServer(t)
+	_, mb := newTestChannel(t, fs, &config.Channel{
+		GroupTrigger: config.GroupTriggerConfig{MentionOnly: true},
+	}, config.XMPPSettings{Nick: "picobot", Rooms: []string{"Lounge@conference.example.com"}})
+
+	fs.mu.Lock()
+	auth := fs.auth
+	fs.mu.Unlock()
+	if auth != "\x00bot\x00secret" {
+		t.Fatalf("PLAIN auth = %q", auth)
+	}
+	joins := fs.recorded("presence")
+	if len(joins) != 2 || joins[1].attr("to") != "lounge@conference.example.com/picobot" ||
+		!strings.Contains(joins[1].Inner, nsMUC) {
+		t.Fatalf("presences = %+v", joins)
+	}
+
+	room := "lounge@conference.example.com"
+	// History, our own echo, unmentioned chatter and chat states are ignored.
+	fs.send("<message type='groupchat' from='" + room + "/alice' id='h1'><body>picobot: old</body>" +
+		"<delay xmlns='urn:xmpp:delay' stamp='2024-01-01T00:00:00Z'/></message>")
+	fs.send("<message type='groupchat' from='" + room + "/picobot' id='e1'><body>echo</body></message>")
+	fs.send("<message type='groupchat' from='" + room + "/alice' id='g0'><body>chatter</body></message>")
+	fs.send("<message type='chat' from='alice@example.com/phone'><composing xmlns='" + nsChatStates + "'/></message>")
+	fs.send("<message type='groupchat' from='" + room + "/alice' id='g1'><body>picobot: status?</body></message>")
+	fs.send("<message type='chat' from='Alice@example.com/phone' id='d1'><body>hi &amp; bye</body></message>")
+
+	in := receiveInbound(t, mb)
+	if in.ChatID != room || in.Context.ChatType != "group" || in.Content != "status?" || !in.Context.Mentioned {
+		t.Fatalf("group inbound = %q %q %+v", in.ChatID, in.Content, in.Context)
+	}
+	if in.Context.SenderID != room+"/alice" || in.Context.MessageID != "g1" {
+		t.Fatalf("group sender = %q id = %q", in.Context.SenderID, in.Context.MessageID)
+	}
+
+	in = receiveInbound(t, mb)
+	if in.ChatID != "alice@example.com" || in.Context.ChatType != "direct" || in.Content != "hi & bye" {
+		t.Fatalf("direct inbound = %q %q %+v", in.ChatID, in.Content, in.Context)
+	}
+}
+
+func TestSendStreamsWithCorrections(t *testing.T) {
+	fs := newFakeServer(t)
+	ch, _ := newTestChannel(t, fs, nil, config.XMPPSettings{
+		Streaming: true,
+		Rooms:     []string{"lounge@conference.example.com"},
+	})
+	ctx := context.Background()
+
+	stop, err := ch.StartTyping(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatalf("StartTyping: %v", err)
+	}
+	stop()
+	stop()
+
+	s, err := ch.BeginStream(ctx, "lounge@conference.example.com")
+	if err != nil {
+		t.Fatalf("BeginStream: %v", err)
+	}
+	_ = s.Update(ctx, "par")
+	_ = s.Update(ctx, "parti") // throttled
+	if err := s.Finalize(ctx, "partial done"); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+
+	waitFor(t, func() bool { return len(fs.recorded("message")) == 4 })
+	msgs := fs.recorded("message")
+	if !strings.Contains(msgs[0].Inner, "composing") || !strings.Contains(msgs[1].Inner, "<active") ||
+		msgs[0].attr("type") != "chat" {
+		t.Fatalf("typing = %+v", msgs[:2])
+	}
+	first, final := msgs[2], msgs[3]
+	if first.attr("type") != "groupchat" || !strings.Contains(first.Inner, "<body>par</body>") {
+		t.Fatalf("first chunk = %+v", first)
+	}
+	if !strings.Contains(final.Inner, "<body>partial done</body>") ||
+		!strings.Contains(final.Inner, `<replace xmlns="`+nsCorrect+`" id="`+first.attr("id")+`">`) {
+		t.Fatalf("correction = %+v", final)
+	}
+}
+
+func TestSendMediaUsesHTTPUpload(t *testing.T) {
+	fs := newFakeServer(t)
+	ch, _ := newTestChannel(t, fs, nil, config.XMPPSettings{})
+	ch.httpClient = fs.http.Client()
+
+	store := media.NewFileMediaStore()
+	ch.SetMediaStore(store)
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := store.Store(path, media.MediaMeta{Filename: "report.txt"}, "test")
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	ids, err := ch.SendMedia(context.Background(), bus.OutboundMediaMessage{
+		ChatID: "alice@example.com",
+		Parts:  []bus.MediaPart{{Ref: ref, Filename: "report.txt", Caption: "here you go"}},
+	})
+	if err != nil {
+		t.Fatalf("SendMedia: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("ids = %v", ids)
+	}
+
+	fs.mu.Lock()
+	uploads := append([]string(nil), fs.uploads...)
+	fs.mu.Unlock()
+	if len(uploads) != 1 || uploads[0] != "Bearer slot data" {
+		t.Fatalf("uploads = %q", uploads)
+	}
+
+	waitFor(t, func() bool { return len(fs.recorded("message")) == 2 })
+	msgs := fs.recorded("message")
+	if !strings.Contains(msgs[0].Inner, "here you go") ||
+		!strings.Contains(msgs[1].Inner, "<body>https://files.example.com/report.txt</body>") ||
+		!strings.Contains(msgs[1].Inner, `<x xmlns="`+nsOOB+`"><url>https://files.example.com/report.txt</url></x>`) {
+		t.Fatalf("messages = %+v", msgs)
+	}
+}
+
+func receiveInbound(t *testing.T, mb *bus.MessageBus) bus.InboundMessage {
+	t.Helper()
+	select {
+	case in := <-mb.InboundChan():
+		return in
+	case <-time.After(3 * time.Second):
+		t.Fatal("no inbound message published")
+	}
+	return bus.InboundMessage{}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -15,8 +15,14 @@ func BuiltinDefinitions() []Definition {
 		switchCommand(),
 		checkCommand(),
 		clearCommand(),
+		stopCommand(),
+		retryCommand(),
+		compactCommand(),
 		contextCommand(),
 		subagentsCommand(),
 		reloadCommand(),
+		roleCommand(),
+		linkCommand(),
+		unlinkCommand(),
 	}
 }
@@ -350,3 +350,79 @@ func TestBuiltinBtwCommand_PreservesQuestionWhitespace(t *testing.T) {
 		t.Fatalf("/btw outcome=%v, want=%v", res.Outcome, OutcomeHandled)
 	}
 }
+
+func TestBuiltinStopCommand_Replies(t *testing.T) {
+	tests := []struct {
+		name    string
+		stopped bool
+		dropped int
+		want    string
+	}{
+		{name: "idle", want: "Nothing is running in this chat."},
+		{name: "stopped", stopped: true, want: "Stopped."},
+		{name: "dropped queue", stopped: true, dropped: 2, want: "Stopped. Discarded 2 queued message(s)."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := NewExecutor(NewRegistry(BuiltinDefinitions()), &Runtime{
+				StopTurn: func() (bool, int, error) { return tt.stopped, tt.dropped, nil },
+			})
+			var reply string
+			res := ex.Execute(context.Background(), Request{
+				Text:  "/stop",
+				Reply: func(text string) error { reply = text; return nil },
+			})
+			if res.Outcome != OutcomeHandled {
+				t.Fatalf("/stop outcome=%v, want=%v", res.Outcome, OutcomeHandled)
+			}
+			if reply != tt.want {
+				t.Fatalf("/stop reply=%q, want=%q", reply, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuiltinRetryCommand_PassesModel(t *testing.T) {
+	var gotModel string
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), &Runtime{
+		RetryLastMessage: func(_ context.Context, model string) (string, error) {
+			gotModel = model
+			return "again", nil
+		},
+	})
+
+	var reply string
+	ex.Execute(context.Background(), Request{
+		Text:  "/retry gpt-5-mini",
+		Reply: func(text string) error { reply = text; return nil },
+	})
+	if gotModel != "gpt-5-mini" {
+		t.Fatalf("model=%q, want %q", gotModel, "gpt-5-mini")
+	}
+	if reply != "again" {
+		t.Fatalf("/retry reply=%q, want %q", reply, "again")
+	}
+}
+
+func TestBuiltinCompactCommand_ReportsCounts(t *testing.T) {
+	counts := [2]int{12, 4}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), &Runtime{
+		CompactContext: func(context.Context) (int, int, error) { return counts[0], counts[1], nil },
+	})
+	run := func() string {
+		var reply string
+		ex.Execute(context.Background(), Request{
+			Text:  "/compact",
+			Reply: func(text string) error { reply = text; return nil },
+		})
+		return reply
+	}
+
+	if got := run(); got != "Compacted chat history: 12 → 4 messages." {
+		t.Fatalf("/compact reply=%q", got)
+	}
+	counts = [2]int{3, 3}
+	if got := run(); got != "Nothing to compact yet." {
+		t.Fatalf("/compact reply=%q, want nothing-to-compact", got)
+	}
+}
@@ -17,7 +17,7 @@ func btwCommand() Definition {
 				return req.Reply(unavailableMsg)
 			}
 
-			question := sideQuestionText(req.Text)
+			question := commandArgText(req.Text)
 			if question == "" {
 				return req.Reply("Usage: /btw <question>")
 			}
@@ -35,7 +35,9 @@ func btwCommand() Definition {
 	}
 }
 
-func sideQuestionText(input string) string {
+// commandArgText returns the raw text after the command token, preserving
+// its inner whitespace.
+func commandArgText(input string) string {
 	input = strings.TrimSpace(input)
 	if input == "" {
 		return ""
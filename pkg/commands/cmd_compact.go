@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+)
+
+func compactCommand() Definition {
+	return Definition{
+		Name:        "compact",
+		Description: "Summarize and compress the chat history now",
+		Usage:       "/compact",
+		Handler: func(ctx context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.CompactContext == nil {
+				return req.Reply(unavailableMsg)
+			}
+			before, after, err := rt.CompactContext(ctx)
+			if err != nil {
+				return req.Reply("Failed to compact chat history: " + err.Error())
+			}
+			if after >= before {
+				return req.Reply("Nothing to compact yet.")
+			}
+			return req.Reply(fmt.Sprintf("Compacted chat history: %d → %d messages.", before, after))
+		},
+	}
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
 func contextCommand() Definition {
@@ -29,7 +30,7 @@ func formatContextStats(s *ContextStats) string {
 		remaining = 0
 	}
 	usedWindowPercent := s.UsedTokens * 100 / max(s.TotalTokens, 1)
-	return fmt.Sprintf(
+	out := fmt.Sprintf(
 		"Context usage  \nMessages: %d  \nUsed: ~%d / %d tokens (%d%%)  \nCompress at: %d tokens  \nCompression progress: %d%%  \nRemaining: ~%d tokens",
 		s.MessageCount,
 		s.UsedTokens,
@@ -39,4 +40,11 @@ func formatContextStats(s *ContextStats) string {
 		s.UsedPercent,
 		remaining,
 	)
+	if r := s.Routing; r != nil {
+		out += fmt.Sprintf("  \nRouting: %s (%s), score %.2f via %s", r.Tier, r.Model, r.Score, r.Source)
+		if len(r.Skipped) > 0 {
+			out += "  \nSkipped tiers: " + strings.Join(r.Skipped, ", ")
+		}
+	}
+	return out
 }
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+)
+
+func linkCommand() Definition {
+	return Definition{
+		Name:        "link",
+		Description: "Link this account with your account on another channel",
+		Usage:       "/link [code]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.IssueLinkCode == nil || rt.ConfirmLink == nil {
+				return req.Reply(unavailableMsg)
+			}
+			if code := nthToken(req.Text, 1); code != "" {
+				canonical, err := rt.ConfirmLink(code)
+				if err != nil {
+					return req.Reply("Link failed: " + err.Error())
+				}
+				return req.Reply(fmt.Sprintf(
+					"Linked with %s. From now on, direct chats on your linked accounts share one conversation.", canonical))
+			}
+			code, err := rt.IssueLinkCode()
+			if err != nil {
+				return req.Reply("Link failed: " + err.Error())
+			}
+			return req.Reply(fmt.Sprintf(
+				"Send /link %s from your other account within 10 minutes to link it with this one.", code))
+		},
+	}
+}
+
+func unlinkCommand() Definition {
+	return Definition{
+		Name:        "unlink",
+		Description: "Remove this account from its linked accounts",
+		Usage:       "/unlink",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.Unlink == nil {
+				return req.Reply(unavailableMsg)
+			}
+			removed, err := rt.Unlink()
+			if err != nil {
+				return req.Reply("Unlink failed: " + err.Error())
+			}
+			if !removed {
+				return req.Reply("This account is not linked.")
+			}
+			return req.Reply("Unlinked. This account has its own conversations again.")
+		},
+	}
+}
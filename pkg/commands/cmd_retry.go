@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"context"
+	"strings"
+)
+
+func retryCommand() Definition {
+	return Definition{
+		Name:        "retry",
+		Description: "Re-run your last message, optionally with another model",
+		Usage:       "/retry [model]",
+		Handler: func(ctx context.Context, req Request, rt *Runtime) error {
+			const emptyAnswerMsg = "The model returned an empty response. This may indicate a provider error or token limit."
+
+			if rt == nil || rt.RetryLastMessage == nil {
+				return req.Reply(unavailableMsg)
+			}
+
+			answer, err := rt.RetryLastMessage(ctx, nthToken(req.Text, 1))
+			if err != nil {
+				return req.Reply("Failed to retry: " + err.Error())
+			}
+			if strings.TrimSpace(answer) == "" {
+				return req.Reply(emptyAnswerMsg)
+			}
+			return req.Reply(answer)
+		},
+	}
+}
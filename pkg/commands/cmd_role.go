@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const roleDisabledMsg = "Access control is not enabled."
+
+func roleCommand() Definition {
+	return Definition{
+		Name:        "role",
+		Description: "Show or manage sender roles",
+		SubCommands: []SubCommand{
+			{
+				Name:        "me",
+				Description: "Show your identity and role",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.GetSenderRole == nil {
+						return req.Reply(roleDisabledMsg)
+					}
+					id, role := rt.GetSenderRole()
+					if id == "" {
+						return req.Reply(fmt.Sprintf("Your role: %s", role))
+					}
+					return req.Reply(fmt.Sprintf("You are %s with role %s", id, role))
+				},
+			},
+			{
+				Name:        "list",
+				Description: "List role assignments",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.ListRoles == nil {
+						return req.Reply(roleDisabledMsg)
+					}
+					grants, err := rt.ListRoles()
+					if err != nil {
+						return req.Reply(err.Error())
+					}
+					if len(grants) == 0 {
+						return req.Reply("No roles assigned; every sender has the default role.")
+					}
+					lines := make([]string, 0, len(grants)+1)
+					lines = append(lines, "Role assignments:")
+					for _, g := range grants {
+						source := "config"
+						if g.Runtime {
+							source = "runtime"
+						}
+						lines = append(lines, fmt.Sprintf("- %s: %s (%s)", g.Identity, g.Role, source))
+					}
+					return req.Reply(strings.Join(lines, "\n"))
+				},
+			},
+			{
+				Name:        "set",
+				Description: "Assign a role (owners only)",
+				ArgsUsage:   "<identity> <role>",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.SetRole == nil {
+						return req.Reply(roleDisabledMsg)
+					}
+					id, role := nthToken(req.Text, 2), nthToken(req.Text, 3)
+					if id == "" || role == "" {
+						return req.Reply("Usage: /role set <identity> <owner|admin|member|guest>")
+					}
+					if err := rt.SetRole(id, role); err != nil {
+						return req.Reply(err.Error())
+					}
+					return req.Reply(fmt.Sprintf("Set role of %s to %s", id, strings.ToLower(role)))
+				},
+			},
+			{
+				Name:        "remove",
+				Description: "Remove a runtime role assignment (owners only)",
+				ArgsUsage:   "<identity>",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.RemoveRole == nil {
+						return req.Reply(roleDisabledMsg)
+					}
+					id := nthToken(req.Text, 2)
+					if id == "" {
+						return req.Reply("Usage: /role remove <identity>")
+					}
+					removed, err := rt.RemoveRole(id)
+					if err != nil {
+						return req.Reply(err.Error())
+					}
+					if !removed {
+						return req.Reply(fmt.Sprintf("%s has no runtime role assignment", id))
+					}
+					return req.Reply(fmt.Sprintf("Removed role assignment for %s", id))
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func runRole(t *testing.T, rt *Runtime, text string) string {
+	t.Helper()
+	var reply string
+	res := NewExecutor(NewRegistry(BuiltinDefinitions()), rt).Execute(context.Background(), Request{
+		Text:  text,
+		Reply: func(s string) error { reply = s; return nil },
+	})
+	if res.Outcome != OutcomeHandled {
+		t.Fatalf("%s: outcome=%v, want handled", text, res.Outcome)
+	}
+	return reply
+}
+
+func TestRoleCommand(t *testing.T) {
+	var setID, setRole string
+	rt := &Runtime{
+		GetSenderRole: func() (string, string) { return "telegram:1", "owner" },
+		ListRoles: func() ([]RoleGrant, error) {
+			return []RoleGrant{{Identity: "telegram:2", Role: "admin", Runtime: true}, {Identity: "@carol", Role: "guest"}}, nil
+		},
+		SetRole: func(id, role string) error {
+			setID, setRole = id, role
+			return nil
+		},
+		RemoveRole: func(id string) (bool, error) { return id == "telegram:2", nil },
+	}
+
+	cases := map[string]string{
+		"/role me":                   "You are telegram:1 with role owner",
+		"/role list":                 "Role assignments:\n- telegram:2: admin (runtime)\n- @carol: guest (config)",
+		"/role set telegram:2 Admin": "Set role of telegram:2 to admin",
+		"/role set telegram:2":       "Usage: /role set <identity> <owner|admin|member|guest>",
+		"/role remove telegram:2":    "Removed role assignment for telegram:2",
+		"/role remove telegram:3":    "telegram:3 has no runtime role assignment",
+		"/role remove":               "Usage: /role remove <identity>",
+	}
+	for text, want := range cases {
+		if got := runRole(t, rt, text); got != want {
+			t.Errorf("%s: reply=%q, want %q", text, got, want)
+		}
+	}
+	if setID != "telegram:2" || setRole != "Admin" {
+		t.Errorf("SetRole got (%q, %q)", setID, setRole)
+	}
+
+	rt.SetRole = func(string, string) error { return errors.New("only owners can change roles") }
+	if got := runRole(t, rt, "/role set telegram:2 guest"); got != "only owners can change roles" {
+		t.Errorf("denied set reply=%q", got)
+	}
+	if got := runRole(t, &Runtime{}, "/role me"); got != roleDisabledMsg {
+		t.Errorf("disabled reply=%q", got)
+	}
+}
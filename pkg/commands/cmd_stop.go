@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+)
+
+func stopCommand() Definition {
+	return Definition{
+		Name:        "stop",
+		Description: "Abort the running turn in this chat",
+		Usage:       "/stop",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.StopTurn == nil {
+				return req.Reply(unavailableMsg)
+			}
+			stopped, dropped, err := rt.StopTurn()
+			if err != nil {
+				return req.Reply("Failed to stop: " + err.Error())
+			}
+			if !stopped {
+				return req.Reply("Nothing is running in this chat.")
+			}
+			if dropped > 0 {
+				return req.Reply(fmt.Sprintf("Stopped. Discarded %d queued message(s).", dropped))
+			}
+			return req.Reply("Stopped.")
+		},
+	}
+}
@@ -0,0 +1,282 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// CustomCommandsDir is the workspace subdirectory scanned for user-defined
+// slash commands.
+const CustomCommandsDir = "commands"
+
+// customCommandNameRe matches the names every platform menu accepts
+// (Telegram BotCommand is the strictest: 1-32 chars of [a-z0-9_]).
+var customCommandNameRe = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// customPlaceholderRe matches $ARGUMENTS, $<digits> and {{name}} placeholders.
+// Digits are matched greedily so "$100" is seen whole and left alone; only
+// $1..$9 are positional.
+var customPlaceholderRe = regexp.MustCompile(`\$ARGUMENTS|\$[0-9]+|\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// CustomCommand is a slash command defined by a Markdown prompt template in
+// <workspace>/commands/<name>.md, where workspace is the default agent's.
+// The optional YAML frontmatter supports:
+//
+//	---
+//	name: standup
+//	description: Summarize yesterday's work
+//	args: [project, days]
+//	allowedTools: [read_file, exec]
+//	model: gpt-5.4
+//	---
+//
+// The body is the prompt. $ARGUMENTS expands to everything after the command,
+// $1..$9 to single words, and {{name}} to the declared arg of that name (the
+// last declared arg takes the rest of the input). When the body has no
+// placeholders, the arguments are appended to it.
+type CustomCommand struct {
+	Name         string
+	Description  string
+	Args         []string
+	AllowedTools []string
+	Model        string
+	Template     string
+	Path         string
+}
+
+// PromptRun asks the agent to run a rendered prompt as a normal turn in the
+// caller's session.
+type PromptRun struct {
+	Command      string
+	Prompt       string
+	Model        string   // optional model_list name overriding the agent model
+	AllowedTools []string // optional tool allowlist; empty means all tools
+}
+
+// LoadCustomCommands reads every commands/*.md file in workspace. Invalid
+// files are logged and skipped so one typo never disables the rest.
+func LoadCustomCommands(workspace string) []CustomCommand {
+	if strings.TrimSpace(workspace) == "" {
+		return nil
+	}
+	paths, err := filepath.Glob(filepath.Join(workspace, CustomCommandsDir, "*.md"))
+	if err != nil || len(paths) == 0 {
+		return nil
+	}
+	sort.Strings(paths)
+
+	seen := make(map[string]struct{}, len(paths))
+	out := make([]CustomCommand, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.WarnCF("commands", "Failed to read custom command",
+				map[string]any{"path": path, "error": err.Error()})
+			continue
+		}
+		cmd, err := ParseCustomCommand(path, string(data))
+		if err != nil {
+			logger.WarnCF("commands", "Skipping invalid custom command",
+				map[string]any{"path": path, "error": err.Error()})
+			continue
+		}
+		if _, dup := seen[cmd.Name]; dup {
+			logger.WarnCF("commands", "Skipping duplicate custom command",
+				map[string]any{"path": path, "name": cmd.Name})
+			continue
+		}
+		seen[cmd.Name] = struct{}{}
+		out = append(out, cmd)
+	}
+	return out
+}
+
+// ParseCustomCommand parses one command file. The command name defaults to
+// the file name without extension.
+func ParseCustomCommand(path, content string) (CustomCommand, error) {
+	frontmatter, body := splitCommandFrontmatter(content)
+
+	var meta struct {
+		Name         string   `yaml:"name"`
+		Description  string   `yaml:"description"`
+		Args         []string `yaml:"args"`
+		AllowedTools []string `yaml:"allowedTools"`
+		Model        string   `yaml:"model"`
+	}
+	if strings.TrimSpace(frontmatter) != "" {
+		if err := yaml.Unmarshal([]byte(frontmatter), &meta); err != nil {
+			return CustomCommand{}, err
+		}
+	}
+
+	name := strings.TrimSpace(meta.Name)
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	name = normalizeCommandName(name)
+	if !customCommandNameRe.MatchString(name) {
+		return CustomCommand{}, fmt.Errorf("invalid command name %q: use 1-32 lowercase letters, digits or underscores", name)
+	}
+
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return CustomCommand{}, fmt.Errorf("command template is empty")
+	}
+
+	description := strings.TrimSpace(meta.Description)
+	if description == "" {
+		description = "Custom command from " + CustomCommandsDir + "/" + filepath.Base(path)
+	}
+
+	return CustomCommand{
+		Name:         name,
+		Description:  description,
+		Args:         trimNonEmpty(meta.Args),
+		AllowedTools: trimNonEmpty(meta.AllowedTools),
+		Model:        strings.TrimSpace(meta.Model),
+		Template:     body,
+		Path:         path,
+	}, nil
+}
+
+// Render substitutes args into the command template.
+func (c CustomCommand) Render(args string) string {
+	args = strings.TrimSpace(args)
+	words := strings.Fields(args)
+
+	named := make(map[string]string, len(c.Args))
+	for i, name := range c.Args {
+		switch {
+		case i >= len(words):
+			named[name] = ""
+		case i == len(c.Args)-1:
+			named[name] = strings.Join(words[i:], " ")
+		default:
+			named[name] = words[i]
+		}
+	}
+
+	substituted := false
+	out := customPlaceholderRe.ReplaceAllStringFunc(c.Template, func(match string) string {
+		switch {
+		case match == "$ARGUMENTS":
+			substituted = true
+			return args
+		case strings.HasPrefix(match, "$"):
+			n, _ := strconv.Atoi(match[1:])
+			if len(match) != 2 || n == 0 {
+				return match
+			}
+			substituted = true
+			if n <= len(words) {
+				return words[n-1]
+			}
+			return ""
+		}
+		name := customPlaceholderRe.FindStringSubmatch(match)[1]
+		value, ok := named[name]
+		if !ok {
+			return match
+		}
+		substituted = true
+		return value
+	})
+
+	if !substituted && args != "" {
+		out += "\n\n" + args
+	}
+	return out
+}
+
+// Usage returns "/name <arg> ..." built from the declared args.
+func (c CustomCommand) Usage() string {
+	usage := "/" + c.Name
+	for _, arg := range c.Args {
+		usage += " <" + arg + ">"
+	}
+	return usage
+}
+
+// Definition adapts the command to the shared registry.
+func (c CustomCommand) Definition() Definition {
+	return Definition{
+		Name:        c.Name,
+		Description: c.Description,
+		Usage:       c.Usage(),
+		Handler: func(ctx context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.RunPrompt == nil {
+				return req.Reply(unavailableMsg)
+			}
+			reply, err := rt.RunPrompt(ctx, PromptRun{
+				Command:      c.Name,
+				Prompt:       c.Render(commandArgText(req.Text)),
+				Model:        c.Model,
+				AllowedTools: append([]string(nil), c.AllowedTools...),
+			})
+			if err != nil {
+				return req.Reply("/" + c.Name + " failed: " + err.Error())
+			}
+			return req.Reply(reply)
+		},
+	}
+}
+
+// WorkspaceDefinitions returns the builtin commands followed by the custom
+// commands found in workspace. Custom commands never shadow a builtin.
+func WorkspaceDefinitions(workspace string) []Definition {
+	defs := BuiltinDefinitions()
+	taken := make(map[string]struct{}, len(defs))
+	for _, def := range defs {
+		taken[normalizeCommandName(def.Name)] = struct{}{}
+		for _, alias := range def.Aliases {
+			taken[normalizeCommandName(alias)] = struct{}{}
+		}
+	}
+
+	for _, cmd := range LoadCustomCommands(workspace) {
+		if _, ok := taken[cmd.Name]; ok {
+			logger.WarnCF("commands", "Custom command conflicts with a builtin; ignoring",
+				map[string]any{"name": cmd.Name, "path": cmd.Path})
+			continue
+		}
+		defs = append(defs, cmd.Definition())
+	}
+	return defs
+}
+
+func splitCommandFrontmatter(content string) (frontmatter, body string) {
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	lines := strings.Split(normalized, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return "", content
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return strings.Join(lines[1:i], "\n"), strings.Join(lines[i+1:], "\n")
+		}
+	}
+	return "", content
+}
+
+func trimNonEmpty(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCustomCommand(t *testing.T, workspace, file, content string) {
+	t.Helper()
+	dir := filepath.Join(workspace, CustomCommandsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", file, err)
+	}
+}
+
+func TestParseCustomCommand_Frontmatter(t *testing.T) {
+	cmd, err := ParseCustomCommand("/ws/commands/standup.md", `---
+name: Daily
+description: Summarize work
+args: [project, days]
+allowedTools: [read_file, "mcp_github_*"]
+model: gpt-5.4
+---
+
+Summarize {{project}} for the last {{days}} days.
+`)
+	if err != nil {
+		t.Fatalf("ParseCustomCommand() error = %v", err)
+	}
+	if cmd.Name != "daily" {
+		t.Fatalf("Name = %q, want daily", cmd.Name)
+	}
+	if cmd.Description != "Summarize work" || cmd.Model != "gpt-5.4" {
+		t.Fatalf("unexpected metadata: %+v", cmd)
+	}
+	if len(cmd.AllowedTools) != 2 || cmd.AllowedTools[1] != "mcp_github_*" {
+		t.Fatalf("AllowedTools = %v", cmd.AllowedTools)
+	}
+	if got := cmd.Usage(); got != "/daily <project> <days>" {
+		t.Fatalf("Usage() = %q", got)
+	}
+}
+
+func TestParseCustomCommand_DefaultsAndValidation(t *testing.T) {
+	cmd, err := ParseCustomCommand("/ws/commands/review.md", "Review the diff.")
+	if err != nil {
+		t.Fatalf("ParseCustomCommand() error = %v", err)
+	}
+	if cmd.Name != "review" || cmd.Description != "Custom command from commands/review.md" {
+		t.Fatalf("unexpected defaults: %+v", cmd)
+	}
+
+	if _, err := ParseCustomCommand("/ws/commands/bad-name.md", "body"); err == nil {
+		t.Fatal("expected error for name with hyphen")
+	}
+	if _, err := ParseCustomCommand("/ws/commands/empty.md", "---\nname: empty\n---\n\n"); err == nil {
+		t.Fatal("expected error for empty template")
+	}
+}
+
+func TestCustomCommandRender(t *testing.T) {
+	cases := []struct {
+		name     string
+		cmd      CustomCommand
+		args     string
+		expected string
+	}{
+		{
+			name:     "arguments",
+			cmd:      CustomCommand{Template: "Translate: $ARGUMENTS"},
+			args:     "hello  world",
+			expected: "Translate: hello  world",
+		},
+		{
+			name:     "positional",
+			cmd:      CustomCommand{Template: "$2 then $1, missing [$3]"},
+			args:     "a b",
+			expected: "b then a, missing []",
+		},
+		{
+			name:     "longer numbers are not positional",
+			cmd:      CustomCommand{Template: "It costs $100, not $10 or $0; ask $1"},
+			args:     "bob",
+			expected: "It costs $100, not $10 or $0; ask bob",
+		},
+		{
+			name:     "named last takes rest",
+			cmd:      CustomCommand{Args: []string{"lang", "text"}, Template: "To {{lang}}: {{ text }}"},
+			args:     "fr good morning",
+			expected: "To fr: good morning",
+		},
+		{
+			name:     "unknown placeholder kept",
+			cmd:      CustomCommand{Template: "{{other}} $ARGUMENTS"},
+			args:     "x",
+			expected: "{{other}} x",
+		},
+		{
+			name:     "appended without placeholders",
+			cmd:      CustomCommand{Template: "Summarize this."},
+			args:     "some text",
+			expected: "Summarize this.\n\nsome text",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.cmd.Render(tc.args); got != tc.expected {
+				t.Fatalf("Render(%q) = %q, want %q", tc.args, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestWorkspaceDefinitions_SkipsBuiltinConflictsAndInvalidFiles(t *testing.T) {
+	workspace := t.TempDir()
+	writeCustomCommand(t, workspace, "standup.md", "Write my standup for $ARGUMENTS")
+	writeCustomCommand(t, workspace, "help.md", "Shadow help")
+	writeCustomCommand(t, workspace, "Bad Name.md", "ignored")
+	writeCustomCommand(t, workspace, "notes.txt", "ignored")
+
+	defs := WorkspaceDefinitions(workspace)
+	if len(defs) != len(BuiltinDefinitions())+1 {
+		t.Fatalf("len(defs) = %d, want builtins + 1", len(defs))
+	}
+	help := findDefinitionByName(t, defs, "help")
+	if help.Description == "Shadow help" || help.Usage != "/help" {
+		t.Fatalf("custom command shadowed /help: %+v", help)
+	}
+	standup := findDefinitionByName(t, defs, "standup")
+	if standup.Usage != "/standup" {
+		t.Fatalf("standup usage = %q", standup.Usage)
+	}
+}
+
+func TestCustomCommandDefinition_RunsPrompt(t *testing.T) {
+	cmd := CustomCommand{
+		Name:         "standup",
+		Template:     "Write my standup for $ARGUMENTS",
+		Model:        "fast",
+		AllowedTools: []string{"read_file"},
+	}
+
+	var got PromptRun
+	rt := &Runtime{
+		RunPrompt: func(ctx context.Context, run PromptRun) (string, error) {
+			got = run
+			return "done", nil
+		},
+	}
+	ex := NewExecutor(NewRegistry([]Definition{cmd.Definition()}), rt)
+
+	var reply string
+	res := ex.Execute(context.Background(), Request{
+		Text: "/standup  project  alpha",
+		Reply: func(text string) error {
+			reply = text
+			return nil
+		},
+	})
+	if res.Outcome != OutcomeHandled {
+		t.Fatalf("outcome = %v, want handled", res.Outcome)
+	}
+	if reply != "done" {
+		t.Fatalf("reply = %q, want done", reply)
+	}
+	if got.Command != "standup" || got.Prompt != "Write my standup for project  alpha" {
+		t.Fatalf("unexpected run: %+v", got)
+	}
+	if got.Model != "fast" || len(got.AllowedTools) != 1 || got.AllowedTools[0] != "read_file" {
+		t.Fatalf("unexpected run options: %+v", got)
+	}
+}
@@ -56,6 +56,11 @@ func (e *Executor) executeDefinition(ctx context.Context, req Request, def Defin
 		req.Reply = func(string) error { return nil }
 	}
 
+	if e.rt != nil && e.rt.CanRunCommand != nil && !e.rt.CanRunCommand(def.Name) {
+		err := req.Reply(fmt.Sprintf(permissionDeniedMsg, def.Name))
+		return ExecuteResult{Outcome: OutcomeHandled, Command: def.Name, Err: err}
+	}
+
 	// Simple command — no sub-commands
 	if len(def.SubCommands) == 0 {
 		if def.Handler == nil {
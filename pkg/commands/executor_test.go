@@ -258,3 +258,25 @@ func TestExecutor_SubCommand_NilHandler_ReturnsPassthrough(t *testing.T) {
 		t.Fatalf("outcome=%v, want=%v", res.Outcome, OutcomePassthrough)
 	}
 }
+
+func TestExecutor_CanRunCommandDenies(t *testing.T) {
+	called := false
+	defs := []Definition{{
+		Name:    "reload",
+		Handler: func(context.Context, Request, *Runtime) error { called = true; return nil },
+	}}
+	rt := &Runtime{CanRunCommand: func(name string) bool { return name != "reload" }}
+	ex := NewExecutor(NewRegistry(defs), rt)
+
+	var reply string
+	res := ex.Execute(context.Background(), Request{
+		Text:  "/reload",
+		Reply: func(text string) error { reply = text; return nil },
+	})
+	if res.Outcome != OutcomeHandled || called {
+		t.Fatalf("outcome=%v called=%v, want handled without running the command", res.Outcome, called)
+	}
+	if reply != "You don't have permission to use /reload." {
+		t.Fatalf("reply=%q", reply)
+	}
+}
@@ -15,7 +15,10 @@ type Request struct {
 	Reply    func(text string) error
 }
 
-const unavailableMsg = "Command unavailable in current context."
+const (
+	unavailableMsg      = "Command unavailable in current context."
+	permissionDeniedMsg = "You don't have permission to use /%s."
+)
 
 var commandPrefixes = []string{"/", "!"}
 
@@ -34,6 +34,16 @@ type ContextStats struct {
 	CompressAtTokens int // compression threshold
 	UsedPercent      int // 0-100
 	MessageCount     int
+	Routing          *RoutingStats // nil when model routing is off or no turn ran yet
+}
+
+// RoutingStats describes the model routing decision for the latest turn.
+type RoutingStats struct {
+	Tier    string
+	Model   string
+	Score   float64
+	Source  string   // rules, judge or judge-cache
+	Skipped []string // cheaper tiers skipped for missing capabilities
 }
 
 // Runtime provides runtime dependencies to command handlers. It is constructed
@@ -54,5 +64,29 @@ type Runtime struct {
 	SwitchModel        func(value string) (oldModel string, err error)
 	SwitchChannel      func(value string) error
 	ClearHistory       func() error
+	StopTurn           func() (stopped bool, dropped int, err error)            // Session-scoped hard abort
+	RetryLastMessage   func(ctx context.Context, model string) (string, error)  // Re-run last user message
+	CompactContext     func(ctx context.Context) (before, after int, err error) // Force compaction now
+	RunPrompt          func(ctx context.Context, run PromptRun) (string, error) // Run a custom command prompt
 	ReloadConfig       func() error
+
+	// Access control. CanRunCommand is nil when every command is allowed;
+	// the role functions are nil when access control is off.
+	CanRunCommand func(name string) bool
+	GetSenderRole func() (identity, role string)
+	ListRoles     func() ([]RoleGrant, error)
+	SetRole       func(identity, role string) error
+	RemoveRole    func(identity string) (removed bool, err error)
+
+	// Cross-channel identity linking for the current sender.
+	IssueLinkCode func() (code string, err error)
+	ConfirmLink   func(code string) (canonical string, err error)
+	Unlink        func() (removed bool, err error)
+}
+
+// RoleGrant is one identity-to-role assignment shown by /role list.
+type RoleGrant struct {
+	Identity string
+	Role     string
+	Runtime  bool // granted with /role rather than in config
 }
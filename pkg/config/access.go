@@ -0,0 +1,34 @@
+package config
+
+// AccessConfig assigns roles to senders and limits what each role may do.
+//
+// Roles are owner, admin, member and guest. Users maps sender identities
+// ("telegram:123", "123", "@alice", "123|alice") to a role; everyone else
+// gets DefaultRole. Roles overrides the built-in policy of a role field by
+// field. Owners may also grant roles at runtime with /role; those grants are
+// stored in the workspace and take precedence over Users.
+type AccessConfig struct {
+	Enabled     bool                  `json:"enabled"`
+	DefaultRole string                `json:"default_role,omitempty"` // role for unlisted senders, "" = member
+	Users       map[string]string     `json:"users,omitempty"`        // identity -> role
+	Roles       map[string]RolePolicy `json:"roles,omitempty"`        // per-role overrides of the built-in policy
+}
+
+// RolePolicy lists what a role may use. Each list holds names or glob
+// patterns ("*", "web_*"); a leading "!" excludes matches. A nil list keeps
+// the built-in default and an empty list allows nothing. The lists are not
+// omitempty so that an explicit [] survives a save.
+type RolePolicy struct {
+	Tools    []string        `json:"tools"`
+	Commands []string        `json:"commands"`
+	Agents   []string        `json:"agents"`
+	Models   []string        `json:"models"` // model_name entries; the first concrete one replaces a disallowed agent model
+	Rate     *RoleRateConfig `json:"rate_limit,omitempty"`
+}
+
+// RoleRateConfig caps how many messages one sender of the role may send.
+// Zero means unlimited.
+type RoleRateConfig struct {
+	PerMinute int `json:"per_minute,omitempty"`
+	PerHour   int `json:"per_hour,omitempty"`
+}
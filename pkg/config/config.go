@@ -44,11 +44,15 @@ type Config struct {
 	Heartbeat HeartbeatConfig `json:"heartbeat"           yaml:"-"`
 	Devices   DevicesConfig   `json:"devices"             yaml:"-"`
 	Voice     VoiceConfig     `json:"voice"               yaml:"-"`
+	Swarm     SwarmConfig     `json:"swarm"               yaml:"swarm,omitempty"`
+	Access    AccessConfig    `json:"access,omitempty"    yaml:"-"`
 	// BuildInfo contains build-time version information
 	BuildInfo BuildInfo `json:"build_info,omitempty" yaml:"-"`
 
 	// cache for sensitive values and compiled regex (computed once)
 	sensitiveCache *SensitiveDataCache
+	// layers records the files a composed config was merged from
+	layers []ConfigLayer
 }
 
 // IsolationConfig controls subprocess isolation for commands started by PicoClaw.
@@ -185,13 +189,14 @@ func (m AgentModelConfig) MarshalJSON() ([]byte, error) {
 }
 
 type AgentConfig struct {
-	ID        string            `json:"id"`
-	Default   bool              `json:"default,omitempty"`
-	Name      string            `json:"name,omitempty"`
-	Workspace string            `json:"workspace,omitempty"`
-	Model     *AgentModelConfig `json:"model,omitempty"`
-	Skills    []string          `json:"skills,omitempty"`
-	Subagents *SubagentsConfig  `json:"subagents,omitempty"`
+	ID          string            `json:"id"`
+	Default     bool              `json:"default,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"` // what the agent handles; read by dispatch triage
+	Workspace   string            `json:"workspace,omitempty"`
+	Model       *AgentModelConfig `json:"model,omitempty"`
+	Skills      []string          `json:"skills,omitempty"`
+	Subagents   *SubagentsConfig  `json:"subagents,omitempty"`
 }
 
 type SubagentsConfig struct {
@@ -201,6 +206,20 @@ type SubagentsConfig struct {
 
 type DispatchConfig struct {
 	Rules []DispatchRule `json:"rules,omitempty"`
+	// Roles maps a role name to sender IDs ("telegram:123", "123" or an
+	// identity_links name), matched by DispatchSelector.SenderRole.
+	Roles map[string][]string `json:"roles,omitempty"`
+	// Triage lets a lightweight model pick the agent for messages that no
+	// rule matches, based on each agent's description.
+	Triage *DispatchTriageConfig `json:"triage,omitempty"`
+}
+
+// DispatchTriageConfig configures model-based agent selection.
+type DispatchTriageConfig struct {
+	Enabled         bool   `json:"enabled"`
+	Model           string `json:"model"`                       // model_name from model_list; should be small and fast
+	TimeoutSeconds  int    `json:"timeout_seconds,omitempty"`   // 0 means use default (10s)
+	CacheTTLSeconds int    `json:"cache_ttl_seconds,omitempty"` // 0 means use default (10m)
 }
 
 type DispatchRule struct {
@@ -218,6 +237,21 @@ type DispatchSelector struct {
 	Topic     string `json:"topic,omitempty"`
 	Sender    string `json:"sender,omitempty"`
 	Mentioned *bool  `json:"mentioned,omitempty"`
+
+	Prefix     string              `json:"prefix,omitempty"`      // message starts with this text (case-insensitive)
+	Regex      string              `json:"regex,omitempty"`       // RE2 pattern matched against the message text
+	Language   string              `json:"language,omitempty"`    // detected language code, e.g. "en", "zh", "ru"
+	Attachment string              `json:"attachment,omitempty"`  // image, audio, video, file or any
+	SenderRole string              `json:"sender_role,omitempty"` // role name from DispatchConfig.Roles
+	Time       *DispatchTimeWindow `json:"time,omitempty"`
+}
+
+// DispatchTimeWindow restricts a rule to certain days and hours.
+type DispatchTimeWindow struct {
+	Days     []string `json:"days,omitempty"`     // mon..sun; empty means every day
+	Start    string   `json:"start,omitempty"`    // "HH:MM"; empty means 00:00
+	End      string   `json:"end,omitempty"`      // "HH:MM"; empty means 24:00, earlier than start wraps past midnight
+	Timezone string   `json:"timezone,omitempty"` // IANA name; empty means local time
 }
 
 type SessionConfig struct {
@@ -231,10 +265,37 @@ type SessionConfig struct {
 // Messages scoring below Threshold are sent to LightModel; all others use the
 // agent's primary model. This reduces cost and latency for simple tasks without
 // requiring any keyword matching — all scoring is language-agnostic.
+//
+// Tiers generalizes the light/primary pair into an ordered ladder of models,
+// each with its own score bound and capabilities. Classifier optionally asks
+// a small model to grade complexity instead of using the structural rules.
 type RoutingConfig struct {
-	Enabled    bool    `json:"enabled"`
-	LightModel string  `json:"light_model"` // model_name from model_list to use for simple tasks
-	Threshold  float64 `json:"threshold"`   // complexity score in [0,1]; score >= threshold → primary model
+	Enabled    bool                     `json:"enabled"`
+	LightModel string                   `json:"light_model"`          // model_name from model_list to use for simple tasks
+	Threshold  float64                  `json:"threshold"`            // complexity score in [0,1]; score >= threshold → primary model
+	Tiers      []RoutingTierConfig      `json:"tiers,omitempty"`      // overrides light_model/threshold when set
+	Classifier *RoutingClassifierConfig `json:"classifier,omitempty"` // LLM judge; rules are used when nil
+}
+
+// RoutingTierConfig is one rung of the model routing ladder. A turn uses the
+// cheapest tier whose max_score is above its complexity score and whose
+// capabilities cover the turn; the tier with the highest bound is the catch-all.
+type RoutingTierConfig struct {
+	Name          string  `json:"name"`
+	Model         string  `json:"model,omitempty"`          // model_name from model_list; empty = agent's primary model
+	MaxScore      float64 `json:"max_score,omitempty"`      // exclusive upper score bound; 0 = catch-all
+	Vision        bool    `json:"vision,omitempty"`         // accepts image/audio input
+	Tools         bool    `json:"tools,omitempty"`          // reliable tool calling; required during tool workflows
+	ContextWindow int     `json:"context_window,omitempty"` // tokens; 0 = unlimited
+}
+
+// RoutingClassifierConfig configures the LLM judge that grades message
+// complexity for model routing. Grades are cached by message content.
+type RoutingClassifierConfig struct {
+	Model           string `json:"model"`                       // model_name from model_list; should be small and fast
+	TimeoutSeconds  int    `json:"timeout_seconds,omitempty"`   // 0 means use default (5s)
+	CacheSize       int    `json:"cache_size,omitempty"`        // 0 means use default (256); negative disables
+	CacheTTLSeconds int    `json:"cache_ttl_seconds,omitempty"` // 0 means use default (1h)
 }
 
 // SubTurnConfig configures the SubTurn execution system.
@@ -455,13 +516,19 @@ func (c *WeixinSettings) SetToken(token string) {
 }
 
 type PicoSettings struct {
-	Token           SecureString `json:"token,omitzero"              yaml:"token,omitempty" env:"PICOCLAW_CHANNELS_PICO_TOKEN"`
-	AllowTokenQuery bool         `json:"allow_token_query,omitempty" yaml:"-"`
-	AllowOrigins    []string     `json:"allow_origins,omitempty"     yaml:"-"`
-	PingInterval    int          `json:"ping_interval,omitempty"     yaml:"-"`
-	ReadTimeout     int          `json:"read_timeout,omitempty"      yaml:"-"`
-	WriteTimeout    int          `json:"write_timeout,omitempty"     yaml:"-"`
-	MaxConnections  int          `json:"max_connections,omitempty"   yaml:"-"`
+	Token            SecureString `json:"token,omitzero"               yaml:"token,omitempty" env:"PICOCLAW_CHANNELS_PICO_TOKEN"`
+	AllowTokenQuery  bool         `json:"allow_token_query,omitempty"  yaml:"-"`
+	AllowOrigins     []string     `json:"allow_origins,omitempty"      yaml:"-"`
+	PingInterval     int          `json:"ping_interval,omitempty"      yaml:"-"`
+	ReadTimeout      int          `json:"read_timeout,omitempty"       yaml:"-"`
+	WriteTimeout     int          `json:"write_timeout,omitempty"      yaml:"-"`
+	MaxConnections   int          `json:"max_connections,omitempty"    yaml:"-"`
+	ResumeBufferSize int          `json:"resume_buffer_size,omitempty" yaml:"-"` // frames kept per v2 session for replay
+	ResumeTTL        int          `json:"resume_ttl,omitempty"         yaml:"-"` // seconds an idle v2 session keeps its buffer
+	ToolApproval     []string     `json:"tool_approval,omitempty"      yaml:"-"` // tools (globs) a v2 client must approve
+	Voice            bool         `json:"voice,omitempty"              yaml:"-"` // accept v2 voice mode (needs an ASR provider)
+	VoiceThreshold   float64      `json:"voice_threshold,omitempty"    yaml:"-"` // PCM RMS level (0-1) counted as speech
+	VoiceSilenceMS   int          `json:"voice_silence_ms,omitempty"   yaml:"-"` // silence that ends a voice utterance
 }
 
 // SetToken sets the Pico token and marks it as dirty for security saving
@@ -512,6 +579,68 @@ type TeamsWebhookTarget struct {
 	Title      string       `json:"title,omitempty"      yaml:"-"`
 }
 
+// WebhookSettings configures the generic inbound webhook channel. Each hook
+// is mounted at BasePath + its path on the gateway's shared HTTP server.
+type WebhookSettings struct {
+	BasePath string                 `json:"base_path,omitempty" yaml:"-"`
+	Hooks    map[string]WebhookHook `json:"hooks"               yaml:"hooks,omitempty"`
+}
+
+// WebhookHook is one inbound endpoint of the webhook channel.
+type WebhookHook struct {
+	Path            string       `json:"path,omitempty"             yaml:"-"` // sub-path under base_path, default: hook name
+	Auth            string       `json:"auth,omitempty"             yaml:"-"` // github, stripe, hmac, bearer or none
+	Secret          SecureString `json:"secret,omitzero"            yaml:"secret,omitempty"`
+	SignatureHeader string       `json:"signature_header,omitempty" yaml:"-"` // hmac only, default X-Signature
+	Template        string       `json:"template,omitempty"         yaml:"-"` // text/template over the JSON payload
+	Sync            bool         `json:"sync,omitempty"             yaml:"-"` // return the agent reply in the HTTP response
+	TimeoutSeconds  int          `json:"timeout_seconds,omitempty"  yaml:"-"` // sync reply timeout, default 60
+}
+
+// MattermostSettings configures the Mattermost bot channel. Events arrive over
+// the server's WebSocket API; slash commands call back into the gateway at
+// CommandURL + WebhookPath.
+type MattermostSettings struct {
+	URL         string       `json:"url"                    yaml:"-"               env:"PICOCLAW_CHANNELS_MATTERMOST_URL"`
+	Token       SecureString `json:"token,omitzero"         yaml:"token,omitempty" env:"PICOCLAW_CHANNELS_MATTERMOST_TOKEN"`
+	Streaming   bool         `json:"streaming"              yaml:"-"               env:"PICOCLAW_CHANNELS_MATTERMOST_STREAMING"`
+	CommandURL  string       `json:"command_url,omitempty"  yaml:"-"               env:"PICOCLAW_CHANNELS_MATTERMOST_COMMAND_URL"`
+	WebhookPath string       `json:"webhook_path,omitempty" yaml:"-"               env:"PICOCLAW_CHANNELS_MATTERMOST_WEBHOOK_PATH"`
+}
+
+// RocketChatSettings configures the Rocket.Chat bot channel. Authenticate
+// with a personal access token (UserID + AuthToken) or Username + Password.
+type RocketChatSettings struct {
+	URL           string       `json:"url"                      yaml:"-"                    env:"PICOCLAW_CHANNELS_ROCKETCHAT_URL"`
+	UserID        string       `json:"user_id,omitempty"        yaml:"-"                    env:"PICOCLAW_CHANNELS_ROCKETCHAT_USER_ID"`
+	AuthToken     SecureString `json:"auth_token,omitzero"      yaml:"auth_token,omitempty" env:"PICOCLAW_CHANNELS_ROCKETCHAT_AUTH_TOKEN"`
+	Username      string       `json:"username,omitempty"       yaml:"-"                    env:"PICOCLAW_CHANNELS_ROCKETCHAT_USERNAME"`
+	Password      SecureString `json:"password,omitzero"        yaml:"password,omitempty"   env:"PICOCLAW_CHANNELS_ROCKETCHAT_PASSWORD"`
+	Streaming     bool         `json:"streaming"                yaml:"-"                    env:"PICOCLAW_CHANNELS_ROCKETCHAT_STREAMING"`
+	CommandPrefix string       `json:"command_prefix,omitempty" yaml:"-"                    env:"PICOCLAW_CHANNELS_ROCKETCHAT_COMMAND_PREFIX"`
+}
+
+// SignalSettings configures the Signal channel, which talks to a signal-cli
+// daemon over JSON-RPC. URL is http(s)://host:port for `daemon --http`,
+// tcp://host:port for `daemon --tcp` or unix:///path for `daemon --socket`.
+type SignalSettings struct {
+	Account string `json:"account" yaml:"-" env:"PICOCLAW_CHANNELS_SIGNAL_ACCOUNT"`
+	URL     string `json:"url"     yaml:"-" env:"PICOCLAW_CHANNELS_SIGNAL_URL"`
+}
+
+// XMPPSettings configures the XMPP (Jabber) channel. Server is host:port and
+// defaults to the JID's domain, resolved through SRV records. Rooms are
+// multi-user chat room JIDs joined as Nick.
+type XMPPSettings struct {
+	JID       string              `json:"jid"               yaml:"-"                  env:"PICOCLAW_CHANNELS_XMPP_JID"`
+	Password  SecureString        `json:"password,omitzero" yaml:"password,omitempty" env:"PICOCLAW_CHANNELS_XMPP_PASSWORD"`
+	Server    string              `json:"server,omitempty"  yaml:"-"                  env:"PICOCLAW_CHANNELS_XMPP_SERVER"`
+	DirectTLS bool                `json:"direct_tls"        yaml:"-"                  env:"PICOCLAW_CHANNELS_XMPP_DIRECT_TLS"`
+	Nick      string              `json:"nick,omitempty"    yaml:"-"                  env:"PICOCLAW_CHANNELS_XMPP_NICK"`
+	Rooms     FlexibleStringSlice `json:"rooms"             yaml:"-"                  env:"PICOCLAW_CHANNELS_XMPP_ROOMS"`
+	Streaming bool                `json:"streaming"         yaml:"-"                  env:"PICOCLAW_CHANNELS_XMPP_STREAMING"`
+}
+
 type HeartbeatConfig struct {
 	Enabled  bool `json:"enabled"  env:"PICOCLAW_HEARTBEAT_ENABLED"`
 	Interval int  `json:"interval" env:"PICOCLAW_HEARTBEAT_INTERVAL"` // minutes, min 5
@@ -527,6 +656,35 @@ type VoiceConfig struct {
 	TTSModelName      string `json:"tts_model_name,omitempty"     env:"PICOCLAW_VOICE_TTS_MODEL_NAME"`
 	EchoTranscription bool   `json:"echo_transcription"           env:"PICOCLAW_VOICE_ECHO_TRANSCRIPTION"`
 	ElevenLabsAPIKey  string `json:"elevenlabs_api_key,omitempty" env:"PICOCLAW_VOICE_ELEVENLABS_API_KEY"`
+	// ASRCommand and TTSCommand run local speech engines (whisper.cpp, vosk,
+	// piper, espeak-ng, ...) instead of an HTTP API. When set they take
+	// precedence over model_name and tts_model_name.
+	ASRCommand VoiceCommandConfig `json:"asr_command,omitzero"`
+	TTSCommand VoiceCommandConfig `json:"tts_command,omitzero"`
+}
+
+// VoiceCommandConfig describes a local speech engine invocation. Command and
+// Convert are argv templates; {input}, {output} and {text} are replaced with
+// the audio input path, the expected output path and the text to speak.
+type VoiceCommandConfig struct {
+	Command []string `json:"command,omitempty"`
+	// Format is the audio format the engine reads (ASR) or writes (TTS):
+	// wav, ogg, mp3, flac or pcm (raw 16-bit mono). Defaults to wav.
+	Format     string `json:"format,omitempty"`
+	SampleRate int    `json:"sample_rate,omitempty"` // for pcm, default 16000 (ASR) / 22050 (TTS)
+	// Convert optionally transcodes audio (e.g. with ffmpeg): channel audio
+	// into Format for ASR, engine output into ConvertFormat for TTS.
+	Convert       []string `json:"convert,omitempty"`
+	ConvertFormat string   `json:"convert_format,omitempty"` // TTS only, default ogg
+	// Streaming synthesizes long replies sentence by sentence so playback
+	// can start before the whole text is spoken. TTS only.
+	Streaming      bool `json:"streaming,omitempty"`
+	TimeoutSeconds int  `json:"timeout_seconds,omitempty"` // per invocation, default 120
+}
+
+// Enabled reports whether a command is configured.
+func (c VoiceCommandConfig) Enabled() bool {
+	return len(c.Command) > 0 && strings.TrimSpace(c.Command[0]) != ""
 }
 
 // ModelConfig represents a model-centric provider configuration.
@@ -560,6 +718,9 @@ type ModelConfig struct {
 	ExtraBody      map[string]any    `json:"extra_body,omitempty"`     // Additional fields to inject into request body
 	CustomHeaders  map[string]string `json:"custom_headers,omitempty"` // Additional headers to inject into every HTTP request
 
+	// Ollama holds options for the native Ollama protocol. Ignored by other providers.
+	Ollama *OllamaModelConfig `json:"ollama,omitempty"`
+
 	APIKeys SecureStrings `json:"api_keys,omitzero" yaml:"api_keys,omitempty"` // API authentication keys (multiple keys for failover)
 
 	// Enabled indicates whether this model entry is active. When omitted in
@@ -574,6 +735,45 @@ type ModelConfig struct {
 	isVirtual bool
 }
 
+// OllamaModelConfig configures the native Ollama provider.
+type OllamaModelConfig struct {
+	// NumCtx is sent as options.num_ctx on every request. When zero, the
+	// Modelfile or server default applies.
+	NumCtx int `json:"num_ctx,omitempty"`
+	// KeepAlive controls how long the model stays loaded after a request,
+	// e.g. "10m", "-1" (forever) or "0" (unload immediately).
+	KeepAlive string `json:"keep_alive,omitempty"`
+	// AutoPull pulls the model from the registry when the server reports it missing.
+	AutoPull bool `json:"auto_pull,omitempty"`
+}
+
+// DiscoveryWildcard is the model ID that marks a model_list entry as a
+// template for runtime discovery (e.g. "ollama/*"). Discovered models are
+// added as virtual entries that inherit the template's settings.
+const DiscoveryWildcard = "*"
+
+// IsDiscoveryTemplate reports whether this entry asks for runtime model
+// discovery instead of naming a single model.
+func (c *ModelConfig) IsDiscoveryTemplate() bool {
+	model := strings.TrimSpace(c.Model)
+	if model == DiscoveryWildcard {
+		return true
+	}
+	_, rest, found := strings.Cut(model, "/")
+	return found && strings.TrimSpace(rest) == DiscoveryWildcard
+}
+
+// DiscoveredModel returns a virtual copy of a discovery template bound to a
+// concrete model. The copy is never persisted by SaveConfig.
+func (c *ModelConfig) DiscoveredModel(modelName, model string) *ModelConfig {
+	clone := *c
+	clone.ModelName = modelName
+	clone.Model = model
+	clone.Fallbacks = nil
+	clone.isVirtual = true
+	return &clone
+}
+
 // APIKey returns the first API key from apiKeys
 func (c *ModelConfig) APIKey() string {
 	if len(c.APIKeys) > 0 {
@@ -747,9 +947,15 @@ type WebToolsConfig struct {
 }
 
 type CronToolsConfig struct {
-	ToolConfig         `     envPrefix:"PICOCLAW_TOOLS_CRON_"`
-	ExecTimeoutMinutes int  `                                 json:"exec_timeout_minutes" env:"PICOCLAW_TOOLS_CRON_EXEC_TIMEOUT_MINUTES"` // 0 means no timeout
-	AllowCommand       bool `                                 json:"allow_command"        env:"PICOCLAW_TOOLS_CRON_ALLOW_COMMAND"`
+	ToolConfig          `       envPrefix:"PICOCLAW_TOOLS_CRON_"`
+	ExecTimeoutMinutes  int    `                                 json:"exec_timeout_minutes"            env:"PICOCLAW_TOOLS_CRON_EXEC_TIMEOUT_MINUTES"` // 0 means no timeout
+	AllowCommand        bool   `                                 json:"allow_command"                   env:"PICOCLAW_TOOLS_CRON_ALLOW_COMMAND"`
+	MaxRetries          int    `                                 json:"max_retries,omitempty"           env:"PICOCLAW_TOOLS_CRON_MAX_RETRIES"`           // default retries per failed run
+	RetryBackoffSeconds int    `                                 json:"retry_backoff_seconds,omitempty" env:"PICOCLAW_TOOLS_CRON_RETRY_BACKOFF_SECONDS"` // doubled on every retry
+	JobTimeoutMinutes   int    `                                 json:"job_timeout_minutes,omitempty"   env:"PICOCLAW_TOOLS_CRON_JOB_TIMEOUT_MINUTES"`   // 0 means no timeout
+	Concurrency         string `                                 json:"concurrency,omitempty"           env:"PICOCLAW_TOOLS_CRON_CONCURRENCY"`           // skip, queue or allow
+	CatchUp             string `                                 json:"catch_up,omitempty"              env:"PICOCLAW_TOOLS_CRON_CATCH_UP"`              // skip or once
+	HistoryLimit        int    `                                 json:"history_limit,omitempty"         env:"PICOCLAW_TOOLS_CRON_HISTORY_LIMIT"`         // runs kept per job
 }
 
 type ExecConfig struct {
@@ -776,6 +982,23 @@ type MediaCleanupConfig struct {
 	Interval   int `                                    json:"interval_minutes" env:"PICOCLAW_MEDIA_CLEANUP_INTERVAL"`
 }
 
+// MediaStoreConfig switches received and generated media to a persistent,
+// content-addressed store so media:// refs survive restarts. When
+// Persistent is false the in-memory store and MediaCleanup apply.
+type MediaStoreConfig struct {
+	Persistent bool   `json:"persistent"    env:"PICOCLAW_MEDIA_STORE_PERSISTENT"`
+	Dir        string `json:"dir,omitempty" env:"PICOCLAW_MEDIA_STORE_DIR"` // default: $PICOCLAW_HOME/media
+	// RetentionDays expires refs after this many days. 0 keeps them until released.
+	RetentionDays int `json:"retention_days" env:"PICOCLAW_MEDIA_STORE_RETENTION_DAYS"`
+	ScopeQuotaMB  int `json:"scope_quota_mb" env:"PICOCLAW_MEDIA_STORE_SCOPE_QUOTA_MB"` // 0 = unlimited
+	MaxTotalMB    int `json:"max_total_mb"   env:"PICOCLAW_MEDIA_STORE_MAX_TOTAL_MB"`   // 0 = unlimited
+	// PublicURL is the externally reachable gateway URL, e.g.
+	// https://bot.example.com. When set, the gateway serves signed media
+	// links under /media/ for channels that can only send media by URL.
+	PublicURL  string `json:"public_url,omitempty" env:"PICOCLAW_MEDIA_STORE_PUBLIC_URL"`
+	URLTTLSecs int    `json:"url_ttl_seconds"      env:"PICOCLAW_MEDIA_STORE_URL_TTL_SECONDS"`
+}
+
 type ReadFileToolConfig struct {
 	Enabled         bool   `json:"enabled"`
 	Mode            string `json:"mode"`
@@ -798,6 +1021,15 @@ func (c ReadFileToolConfig) EffectiveMode() string {
 	}
 }
 
+type ReadDocumentToolConfig struct {
+	Enabled  bool `json:"enabled"`
+	MaxChars int  `json:"max_chars"`
+	// AttachmentSummary adds a short summary of each received document to
+	// the inbound message, so the agent knows what arrived before reading it.
+	AttachmentSummary bool `json:"attachment_summary"`
+	SummaryChars      int  `json:"summary_chars"`
+}
+
 type ToolsConfig struct {
 	AllowReadPaths  []string `json:"allow_read_paths"  yaml:"-" env:"PICOCLAW_TOOLS_ALLOW_READ_PATHS"`
 	AllowWritePaths []string `json:"allow_write_paths" yaml:"-" env:"PICOCLAW_TOOLS_ALLOW_WRITE_PATHS"`
@@ -808,30 +1040,33 @@ type ToolsConfig struct {
 	// FilterMinLength is the minimum content length required for filtering.
 	// Content shorter than this will be returned unchanged for performance.
 	// Default: 8
-	FilterMinLength int                `json:"filter_min_length" yaml:"-"                env:"PICOCLAW_TOOLS_FILTER_MIN_LENGTH"`
-	Web             WebToolsConfig     `json:"web"               yaml:"web,omitempty"`
-	Cron            CronToolsConfig    `json:"cron"              yaml:"-"`
-	Exec            ExecConfig         `json:"exec"              yaml:"-"`
-	Skills          SkillsToolsConfig  `json:"skills"            yaml:"skills,omitempty"`
-	MediaCleanup    MediaCleanupConfig `json:"media_cleanup"     yaml:"-"`
-	MCP             MCPConfig          `json:"mcp"               yaml:"-"`
-	AppendFile      ToolConfig         `json:"append_file"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_APPEND_FILE_"`
-	EditFile        ToolConfig         `json:"edit_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_EDIT_FILE_"`
-	FindSkills      ToolConfig         `json:"find_skills"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_FIND_SKILLS_"`
-	I2C             ToolConfig         `json:"i2c"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_I2C_"`
-	InstallSkill    ToolConfig         `json:"install_skill"     yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_INSTALL_SKILL_"`
-	ListDir         ToolConfig         `json:"list_dir"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_LIST_DIR_"`
-	Message         ToolConfig         `json:"message"           yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_MESSAGE_"`
-	ReadFile        ReadFileToolConfig `json:"read_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_READ_FILE_"`
-	Serial          ToolConfig         `json:"serial"            yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SERIAL_"`
-	SendFile        ToolConfig         `json:"send_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SEND_FILE_"`
-	SendTTS         ToolConfig         `json:"send_tts"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SEND_TTS_"`
-	Spawn           ToolConfig         `json:"spawn"             yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPAWN_"`
-	SpawnStatus     ToolConfig         `json:"spawn_status"      yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPAWN_STATUS_"`
-	SPI             ToolConfig         `json:"spi"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPI_"`
-	Subagent        ToolConfig         `json:"subagent"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SUBAGENT_"`
-	WebFetch        ToolConfig         `json:"web_fetch"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_WEB_FETCH_"`
-	WriteFile       ToolConfig         `json:"write_file"        yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_WRITE_FILE_"`
+	FilterMinLength int                    `json:"filter_min_length" yaml:"-"                env:"PICOCLAW_TOOLS_FILTER_MIN_LENGTH"`
+	Web             WebToolsConfig         `json:"web"               yaml:"web,omitempty"`
+	Cron            CronToolsConfig        `json:"cron"              yaml:"-"`
+	Triggers        TriggersToolsConfig    `json:"triggers"          yaml:"-"`
+	Exec            ExecConfig             `json:"exec"              yaml:"-"`
+	Skills          SkillsToolsConfig      `json:"skills"            yaml:"skills,omitempty"`
+	MediaCleanup    MediaCleanupConfig     `json:"media_cleanup"     yaml:"-"`
+	MediaStore      MediaStoreConfig       `json:"media_store"       yaml:"-"`
+	MCP             MCPConfig              `json:"mcp"               yaml:"-"`
+	AppendFile      ToolConfig             `json:"append_file"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_APPEND_FILE_"`
+	EditFile        ToolConfig             `json:"edit_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_EDIT_FILE_"`
+	FindSkills      ToolConfig             `json:"find_skills"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_FIND_SKILLS_"`
+	I2C             ToolConfig             `json:"i2c"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_I2C_"`
+	InstallSkill    ToolConfig             `json:"install_skill"     yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_INSTALL_SKILL_"`
+	ListDir         ToolConfig             `json:"list_dir"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_LIST_DIR_"`
+	Message         ToolConfig             `json:"message"           yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_MESSAGE_"`
+	ReadFile        ReadFileToolConfig     `json:"read_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_READ_FILE_"`
+	ReadDocument    ReadDocumentToolConfig `json:"read_document"     yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_READ_DOCUMENT_"`
+	Serial          ToolConfig             `json:"serial"            yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SERIAL_"`
+	SendFile        ToolConfig             `json:"send_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SEND_FILE_"`
+	SendTTS         ToolConfig             `json:"send_tts"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SEND_TTS_"`
+	Spawn           ToolConfig             `json:"spawn"             yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPAWN_"`
+	SpawnStatus     ToolConfig             `json:"spawn_status"      yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPAWN_STATUS_"`
+	SPI             ToolConfig             `json:"spi"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPI_"`
+	Subagent        ToolConfig             `json:"subagent"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SUBAGENT_"`
+	WebFetch        ToolConfig             `json:"web_fetch"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_WEB_FETCH_"`
+	WriteFile       ToolConfig             `json:"write_file"        yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_WRITE_FILE_"`
 }
 
 // IsFilterSensitiveDataEnabled returns true if sensitive data filtering is enabled
@@ -977,7 +1212,15 @@ func (c *MCPConfig) GetMaxInlineTextChars() int {
 	return DefaultMCPMaxInlineTextChars
 }
 
+// LoadConfig loads the config at path with the profile and environment
+// overlay selected by PICOCLAW_PROFILE and PICOCLAW_ENV.
 func LoadConfig(path string) (*Config, error) {
+	return LoadConfigWithOptions(path, DefaultLoadOptions())
+}
+
+// LoadConfigWithOptions loads the config at path, merging its includes, the
+// selected profile and the environment overlay before decoding.
+func LoadConfigWithOptions(path string, opts LoadOptions) (*Config, error) {
 	updateResolver(filepath.Dir(path))
 
 	data, err := os.ReadFile(path)
@@ -992,6 +1235,12 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
+	data, layers, err := composeConfigLayers(path, data, opts)
+	if err != nil {
+		logger.ErrorCF("config", formatDiagnosticLogMessage("Failed to compose config", err), map[string]any{"path": path})
+		return nil, err
+	}
+
 	// First, try to detect config version by reading the version field
 	var versionInfo struct {
 		Version int `json:"version"`
@@ -1001,6 +1250,14 @@ func LoadConfig(path string) (*Config, error) {
 		logger.ErrorCF("config", formatDiagnosticLogMessage("Malformed config file", e), map[string]any{"path": path})
 		return nil, e
 	}
+	// Migrations rewrite the config file in place, which would flatten the
+	// layers, so layered configs must already be on the current schema.
+	if layers != nil && versionInfo.Version != CurrentVersion {
+		return nil, fmt.Errorf(
+			"layered config must use version %d, got %d: migrate the base file without include/profiles first",
+			CurrentVersion, versionInfo.Version,
+		)
+	}
 	if len(data) <= 10 {
 		logger.Warn(fmt.Sprintf("content is [%s]", string(data)))
 		return DefaultConfig(), nil
@@ -1229,6 +1486,7 @@ func LoadConfig(path string) (*Config, error) {
 		cfg.Agents.Defaults.Workspace = filepath.Join(homePath, pkg.WorkspaceName)
 	}
 
+	cfg.layers = layers
 	return cfg, nil
 }
 
@@ -1351,6 +1609,9 @@ func toNameIndex(list []*ModelConfig) []string {
 }
 
 func SaveConfig(path string, cfg *Config) error {
+	if len(cfg.layers) > 0 {
+		return ErrLayeredConfig
+	}
 	if cfg.Version < CurrentVersion {
 		cfg.Version = CurrentVersion
 	}
@@ -1481,6 +1742,7 @@ func expandMultiKeyModels(models []*ModelConfig) []*ModelConfig {
 				ThinkingLevel:  m.ThinkingLevel,
 				ExtraBody:      m.ExtraBody,
 				CustomHeaders:  m.CustomHeaders,
+				Ollama:         m.Ollama,
 				UserAgent:      m.UserAgent,
 				isVirtual:      true,
 			}
@@ -1504,6 +1766,7 @@ func expandMultiKeyModels(models []*ModelConfig) []*ModelConfig {
 			ThinkingLevel:  m.ThinkingLevel,
 			ExtraBody:      m.ExtraBody,
 			CustomHeaders:  m.CustomHeaders,
+			Ollama:         m.Ollama,
 			UserAgent:      m.UserAgent,
 			APIKeys:        SimpleSecureStrings(keys[0]),
 		}
@@ -1527,6 +1790,8 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.Web.Enabled
 	case "cron":
 		return t.Cron.Enabled
+	case "triggers":
+		return t.Triggers.Enabled
 	case "exec":
 		return t.Exec.Enabled
 	case "skills":
@@ -1549,6 +1814,8 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.Message.Enabled
 	case "read_file":
 		return t.ReadFile.Enabled
+	case "read_document":
+		return t.ReadDocument.Enabled
 	case "serial":
 		return t.Serial.Enabled
 	case "spawn":
@@ -33,6 +33,11 @@ const (
 	ChannelWhatsApp       = "whatsapp"
 	ChannelWhatsAppNative = "whatsapp_native"
 	ChannelTeamsWebHook   = "teams_webhook"
+	ChannelWebhook        = "webhook"
+	ChannelMattermost     = "mattermost"
+	ChannelRocketChat     = "rocketchat"
+	ChannelSignal         = "signal"
+	ChannelXMPP           = "xmpp"
 )
 
 func initChannel() {
@@ -640,6 +645,11 @@ var channelSettingsFactory = map[string]any{
 	ChannelWhatsApp:       (WhatsAppSettings{}),
 	ChannelWhatsAppNative: (WhatsAppSettings{}),
 	ChannelTeamsWebHook:   (TeamsWebhookSettings{}),
+	ChannelWebhook:        (WebhookSettings{}),
+	ChannelMattermost:     (MattermostSettings{}),
+	ChannelRocketChat:     (RocketChatSettings{}),
+	ChannelSignal:         (SignalSettings{}),
+	ChannelXMPP:           (XMPPSettings{}),
 }
 
 // newChannelSettings creates a fresh zero-value pointer for the given channel type.
@@ -2402,3 +2402,33 @@ func testChannelsConfigWithTokens() ChannelsConfig {
 	}
 	return channels
 }
+
+func TestSaveConfig_KeepsEmptyRolePolicyLists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	cfg := DefaultConfig()
+	cfg.Access = AccessConfig{
+		Enabled: true,
+		Roles: map[string]RolePolicy{
+			"guest": {Tools: []string{}, Commands: []string{"help"}},
+		},
+	}
+	if err := SaveConfig(path, cfg); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	guest := loaded.Access.Roles["guest"]
+	if guest.Tools == nil || len(guest.Tools) != 0 {
+		t.Fatalf("guest tools = %#v, want an explicit empty list", guest.Tools)
+	}
+	if guest.Agents != nil {
+		t.Fatalf("guest agents = %#v, want nil (built-in default)", guest.Agents)
+	}
+	if len(guest.Commands) != 1 || guest.Commands[0] != "help" {
+		t.Fatalf("guest commands = %#v", guest.Commands)
+	}
+}
@@ -304,6 +304,10 @@ func DefaultConfig() *Config {
 				MaxAge:   30,
 				Interval: 5,
 			},
+			MediaStore: MediaStoreConfig{
+				RetentionDays: 30,
+				URLTTLSecs:    3600,
+			},
 			Web: WebToolsConfig{
 				ToolConfig: ToolConfig{
 					Enabled: true,
@@ -357,6 +361,11 @@ func DefaultConfig() *Config {
 				ExecTimeoutMinutes: 5,
 				AllowCommand:       true,
 			},
+			Triggers: TriggersToolsConfig{
+				ToolConfig: ToolConfig{
+					Enabled: true,
+				},
+			},
 			Exec: ExecConfig{
 				ToolConfig: ToolConfig{
 					Enabled: true,
@@ -435,6 +444,12 @@ func DefaultConfig() *Config {
 				Mode:            ReadFileModeBytes,
 				MaxReadFileSize: 64 * 1024, // 64KB
 			},
+			ReadDocument: ReadDocumentToolConfig{
+				Enabled:           true,
+				MaxChars:          20000,
+				AttachmentSummary: true,
+				SummaryChars:      600,
+			},
 			Serial: ToolConfig{
 				Enabled: false, // Hardware tool - requires host serial ports
 			},
@@ -28,6 +28,16 @@ const (
 	// Default: $PICOCLAW_HOME/config.json
 	EnvConfig = "PICOCLAW_CONFIG"
 
+	// EnvProfile selects a named profile from the config's "profiles"
+	// object to layer over the base config. Set by the --profile flag.
+	// Default: none
+	EnvProfile = "PICOCLAW_PROFILE"
+
+	// EnvEnvironment selects the per-environment overlay file
+	// (config.<env>.json next to the config file) layered last.
+	// Default: none
+	EnvEnvironment = "PICOCLAW_ENV"
+
 	// EnvBuiltinSkills overrides the directory from which built-in
 	// skills are loaded.
 	// Default: <cwd>/skills
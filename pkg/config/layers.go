@@ -0,0 +1,288 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// includeKey lists files merged underneath the file that names them.
+	includeKey = "include"
+	// profilesKey maps profile names to partial configs layered over the base.
+	profilesKey = "profiles"
+
+	maxIncludeDepth = 8
+)
+
+// Config layer kinds, in the order they are applied.
+const (
+	LayerInclude = "include"
+	LayerBase    = "base"
+	LayerProfile = "profile"
+	LayerOverlay = "overlay"
+)
+
+// ErrLayeredConfig is returned by SaveConfig for a config composed from
+// includes, profiles or overlays: writing it back would flatten every layer
+// into the base file.
+var ErrLayeredConfig = errors.New("config is composed from several layers; edit the source files instead")
+
+// LoadOptions selects what is layered over the base config file.
+type LoadOptions struct {
+	// Profile names an entry of the top-level "profiles" object.
+	Profile string
+	// Environment selects the overlay file config.<environment>.json next to
+	// the base file.
+	Environment string
+}
+
+// DefaultLoadOptions returns the options selected by PICOCLAW_PROFILE and
+// PICOCLAW_ENV.
+func DefaultLoadOptions() LoadOptions {
+	return LoadOptions{
+		Profile:     strings.TrimSpace(os.Getenv(EnvProfile)),
+		Environment: strings.TrimSpace(os.Getenv(EnvEnvironment)),
+	}
+}
+
+// ConfigLayer is one source that contributed to the effective config.
+type ConfigLayer struct {
+	Kind string `json:"kind"`
+	// Path is the file the layer was read from. Profiles defined inline in
+	// another file report that file.
+	Path string `json:"path"`
+	// Name is the profile name for profile layers.
+	Name string `json:"name,omitempty"`
+}
+
+// Layers returns the sources the config was composed from, in merge order,
+// or nil for a plain single-file config.
+func (c *Config) Layers() []ConfigLayer {
+	return c.layers
+}
+
+// OverlayPath returns the per-environment overlay path for a config file,
+// e.g. config.json + "prod" -> config.prod.json.
+func OverlayPath(configPath, environment string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + "." + environment + ext
+}
+
+// ConfigProfiles returns the profile names defined by the config at path,
+// including profiles pulled in through includes.
+func ConfigProfiles(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseLayer(path, data)
+	if err != nil {
+		return nil, err
+	}
+	merged, _, err := expandIncludes(path, root, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := profilesOf(path, merged)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// composeConfigLayers merges the includes, selected profile and environment
+// overlay of the config at path into a single document. A config that uses
+// none of them is returned unchanged with nil layers, so diagnostics keep
+// pointing at the user's own lines.
+func composeConfigLayers(path string, data []byte, opts LoadOptions) ([]byte, []ConfigLayer, error) {
+	root, err := parseLayer(path, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, hasInclude := root[includeKey]
+	_, hasProfiles := root[profilesKey]
+	if !hasInclude && !hasProfiles && opts.Profile == "" && opts.Environment == "" {
+		return data, nil, nil
+	}
+
+	merged, layers, err := expandIncludes(path, root, nil, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profiles, err := profilesOf(path, merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	delete(merged, profilesKey)
+
+	if opts.Profile != "" {
+		profile, ok := profiles[opts.Profile]
+		if !ok {
+			names := make([]string, 0, len(profiles))
+			for name := range profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if len(names) == 0 {
+				return nil, nil, fmt.Errorf("profile %q not found: %s defines no profiles", opts.Profile, path)
+			}
+			return nil, nil, fmt.Errorf(
+				"profile %q not found (available: %s)", opts.Profile, strings.Join(names, ", "),
+			)
+		}
+		// Includes inside a profile resolve relative to the base file.
+		expanded, included, err := expandIncludes(path, profile, []string{path}, 1)
+		if err != nil {
+			return nil, nil, fmt.Errorf("profile %q: %w", opts.Profile, err)
+		}
+		layers = append(layers, included[:len(included)-1]...)
+		layers = append(layers, ConfigLayer{Kind: LayerProfile, Path: path, Name: opts.Profile})
+		merged = mergeMap(merged, expanded)
+	}
+
+	if opts.Environment != "" {
+		overlayPath := OverlayPath(path, opts.Environment)
+		overlayData, err := os.ReadFile(overlayPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("environment %q overlay: %w", opts.Environment, err)
+		}
+		overlay, err := parseLayer(overlayPath, overlayData)
+		if err != nil {
+			return nil, nil, err
+		}
+		expanded, included, err := expandIncludes(overlayPath, overlay, []string{path}, 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := expanded[profilesKey]; ok {
+			return nil, nil, fmt.Errorf("%s: profiles can only be defined by the base config", overlayPath)
+		}
+		included[len(included)-1].Kind = LayerOverlay
+		layers = append(layers, included...)
+		merged = mergeMap(merged, expanded)
+	}
+
+	composed, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return composed, layers, nil
+}
+
+// expandIncludes merges the files listed in doc's "include" key underneath
+// doc. Later includes override earlier ones and doc overrides them all.
+// The returned layers end with the layer for path itself.
+func expandIncludes(path string, doc map[string]any, stack []string, depth int) (map[string]any, []ConfigLayer, error) {
+	if depth > maxIncludeDepth {
+		return nil, nil, fmt.Errorf("%s: includes nested deeper than %d levels", path, maxIncludeDepth)
+	}
+	stack = append(stack, path)
+
+	includes, err := includesOf(path, doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	delete(doc, includeKey)
+
+	merged := map[string]any{}
+	var layers []ConfigLayer
+	for _, inc := range includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(path), inc)
+		}
+		for _, seen := range stack {
+			if seen == inc {
+				return nil, nil, fmt.Errorf("%s: include cycle through %s", path, inc)
+			}
+		}
+		data, err := os.ReadFile(inc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: include: %w", path, err)
+		}
+		child, err := parseLayer(inc, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		expanded, childLayers, err := expandIncludes(inc, child, stack, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		childLayers[len(childLayers)-1].Kind = LayerInclude
+		layers = append(layers, childLayers...)
+		merged = mergeMap(merged, expanded)
+	}
+
+	layers = append(layers, ConfigLayer{Kind: LayerBase, Path: path})
+	return mergeMap(merged, doc), layers, nil
+}
+
+func includesOf(path string, doc map[string]any) ([]string, error) {
+	switch v := doc[includeKey].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		includes := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok || s == "" {
+				return nil, fmt.Errorf("%s: include entries must be file paths", path)
+			}
+			includes = append(includes, s)
+		}
+		return includes, nil
+	default:
+		return nil, fmt.Errorf("%s: include must be a file path or a list of file paths", path)
+	}
+}
+
+func profilesOf(path string, doc map[string]any) (map[string]map[string]any, error) {
+	raw, ok := doc[profilesKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: profiles must be an object of profile name to config", path)
+	}
+	profiles := make(map[string]map[string]any, len(obj))
+	for name, v := range obj {
+		profile, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: profile %q must be an object", path, name)
+		}
+		if _, nested := profile[profilesKey]; nested {
+			return nil, fmt.Errorf("%s: profile %q cannot define profiles", path, name)
+		}
+		profiles[name] = profile
+	}
+	return profiles, nil
+}
+
+func parseLayer(path string, data []byte) (map[string]any, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, wrapJSONError(data, err, filepath.Base(path))
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	return doc, nil
+}
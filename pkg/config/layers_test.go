@@ -0,0 +1,157 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeLayerFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigWithOptions_Layers(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, dir, "common/gateway.json", `{"gateway": {"port": 18000, "log_level": "warn"}}`)
+	writeLayerFile(t, dir, "boards/edge.json", `{"agents": {"defaults": {"max_tokens": 1024}}}`)
+	path := writeLayerFile(t, dir, "config.json", `{
+  "version": 3,
+  "include": "common/gateway.json",
+  "gateway": {"port": 18100},
+  "agents": {"defaults": {"model_name": "base-model", "max_tokens": 4096}},
+  "profiles": {
+    "edge": {
+      "include": ["boards/edge.json"],
+      "agents": {"defaults": {"model_name": "edge-model"}}
+    }
+  }
+}`)
+	writeLayerFile(t, dir, "config.prod.json", `{"gateway": {"log_level": "error"}}`)
+
+	base, err := LoadConfigWithOptions(path, LoadOptions{})
+	if err != nil {
+		t.Fatalf("load base: %v", err)
+	}
+	if base.Gateway.Port != 18100 || base.Gateway.LogLevel != "warn" {
+		t.Fatalf("base gateway = %d/%q, want 18100/warn", base.Gateway.Port, base.Gateway.LogLevel)
+	}
+	if got := base.Agents.Defaults.GetModelName(); got != "base-model" {
+		t.Fatalf("base model = %q", got)
+	}
+
+	edge, err := LoadConfigWithOptions(path, LoadOptions{Profile: "edge", Environment: "prod"})
+	if err != nil {
+		t.Fatalf("load edge/prod: %v", err)
+	}
+	if got := edge.Agents.Defaults.GetModelName(); got != "edge-model" {
+		t.Fatalf("edge model = %q", got)
+	}
+	if edge.Agents.Defaults.MaxTokens != 1024 {
+		t.Fatalf("edge max_tokens = %d, want 1024 from the profile include", edge.Agents.Defaults.MaxTokens)
+	}
+	if edge.Gateway.LogLevel != "error" || edge.Gateway.Port != 18100 {
+		t.Fatalf("edge gateway = %d/%q, want 18100/error", edge.Gateway.Port, edge.Gateway.LogLevel)
+	}
+
+	var kinds []string
+	for _, l := range edge.Layers() {
+		kinds = append(kinds, l.Kind)
+	}
+	if got := strings.Join(kinds, ","); got != "include,base,include,profile,overlay" {
+		t.Fatalf("layers = %s", got)
+	}
+
+	if err := SaveConfig(path, edge); !errors.Is(err, ErrLayeredConfig) {
+		t.Fatalf("SaveConfig on layered config: err = %v, want ErrLayeredConfig", err)
+	}
+
+	changes, err := DiffConfigs(base, edge)
+	if err != nil {
+		t.Fatalf("DiffConfigs: %v", err)
+	}
+	changed := map[string]bool{}
+	for _, c := range changes {
+		changed[c.Path] = true
+	}
+	for _, want := range []string{"agents.defaults.model_name", "agents.defaults.max_tokens", "gateway.log_level"} {
+		if !changed[want] {
+			t.Errorf("diff is missing %s: %+v", want, changes)
+		}
+	}
+	if changed["gateway.port"] {
+		t.Errorf("gateway.port did not change but was reported")
+	}
+
+	profiles, err := ConfigProfiles(path)
+	if err != nil || len(profiles) != 1 || profiles[0] != "edge" {
+		t.Fatalf("ConfigProfiles = %v, %v", profiles, err)
+	}
+}
+
+func TestLoadConfigWithOptions_LayerErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, dir, "a.json", `{"include": "b.json"}`)
+	writeLayerFile(t, dir, "b.json", `{"include": "a.json"}`)
+	path := writeLayerFile(t, dir, "config.json", `{"version": 3, "include": "a.json", "profiles": {"edge": {}}}`)
+
+	if _, err := LoadConfigWithOptions(path, LoadOptions{}); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("include cycle: err = %v", err)
+	}
+
+	writeLayerFile(t, dir, "b.json", `{}`)
+	if _, err := LoadConfigWithOptions(path, LoadOptions{Profile: "cloud"}); err == nil ||
+		!strings.Contains(err.Error(), "available: edge") {
+		t.Fatalf("unknown profile: err = %v", err)
+	}
+	if _, err := LoadConfigWithOptions(path, LoadOptions{Environment: "staging"}); err == nil {
+		t.Fatal("missing environment overlay should fail")
+	}
+
+	legacy := writeLayerFile(t, dir, "legacy.json", `{"version": 2, "include": "b.json"}`)
+	if _, err := LoadConfigWithOptions(legacy, LoadOptions{}); err == nil {
+		t.Fatal("layered config on an old schema version should fail")
+	}
+}
+
+func TestLoadConfig_PlainConfigHasNoLayers(t *testing.T) {
+	path := writeLayerFile(t, t.TempDir(), "config.json", `{"version": 3}`)
+	cfg, err := LoadConfigWithOptions(path, LoadOptions{})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Layers() != nil {
+		t.Fatalf("Layers() = %+v, want nil", cfg.Layers())
+	}
+}
+
+func TestRenderConfig_MasksSecrets(t *testing.T) {
+	path := writeLayerFile(t, t.TempDir(), "config.json", `{
+  "version": 3,
+  "model_list": [{"model_name": "m", "model": "openai/gpt-4o", "api_keys": ["sk-super-secret-value"]}],
+  "agents": {"defaults": {"model_name": "m", "workspace": "/srv/sk-super-secret-value"}}
+}`)
+	cfg, err := LoadConfigWithOptions(path, LoadOptions{})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	out, err := RenderConfig(cfg)
+	if err != nil {
+		t.Fatalf("RenderConfig: %v", err)
+	}
+	if strings.Contains(string(out), "sk-super-secret-value") {
+		t.Fatalf("rendered config leaks a secret:\n%s", out)
+	}
+	if !strings.Contains(string(out), "[FILTERED]") {
+		t.Fatalf("secret reused in a plain field should be filtered:\n%s", out)
+	}
+}
@@ -0,0 +1,111 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// RenderConfig returns the effective config as indented JSON. Secret fields
+// are left out exactly as SaveConfig leaves them out of config.json, and any
+// secret value that still shows up elsewhere is replaced with [FILTERED].
+func RenderConfig(cfg *Config) ([]byte, error) {
+	out := *cfg
+	out.ModelList = make([]*ModelConfig, 0, len(cfg.ModelList))
+	for _, m := range cfg.ModelList {
+		if !m.isVirtual {
+			out.ModelList = append(out.ModelList, m)
+		}
+	}
+
+	data, err := json.MarshalIndent(&out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(cfg.SensitiveDataReplacer().Replace(string(data))), nil
+}
+
+// ConfigChange is one leaf value that differs between two configs. Old or
+// New is nil when the path is absent on that side.
+type ConfigChange struct {
+	Path string
+	Old  any
+	New  any
+}
+
+// DiffConfigs compares the rendered form of two configs and returns the
+// changed leaves sorted by path.
+func DiffConfigs(from, to *Config) ([]ConfigChange, error) {
+	fromLeaves, err := renderedLeaves(from)
+	if err != nil {
+		return nil, err
+	}
+	toLeaves, err := renderedLeaves(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ConfigChange
+	for path, oldVal := range fromLeaves {
+		newVal, ok := toLeaves[path]
+		if !ok {
+			changes = append(changes, ConfigChange{Path: path, Old: oldVal})
+			continue
+		}
+		if !reflect.DeepEqual(oldVal, newVal) {
+			changes = append(changes, ConfigChange{Path: path, Old: oldVal, New: newVal})
+		}
+	}
+	for path, newVal := range toLeaves {
+		if _, ok := fromLeaves[path]; !ok {
+			changes = append(changes, ConfigChange{Path: path, New: newVal})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func renderedLeaves(cfg *Config) (map[string]any, error) {
+	data, err := RenderConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	leaves := make(map[string]any)
+	flattenJSON(doc, "", leaves)
+	return leaves, nil
+}
+
+// flattenJSON records every scalar (and every empty object or array) in v
+// under its dotted path, using the same path syntax as config diagnostics.
+func flattenJSON(v any, path string, leaves map[string]any) {
+	switch val := v.(type) {
+	case map[string]any:
+		if len(val) == 0 {
+			leaves[path] = val
+			return
+		}
+		for k, child := range val {
+			flattenJSON(child, appendJSONPath(path, k), leaves)
+		}
+	case []any:
+		if len(val) == 0 {
+			leaves[path] = val
+			return
+		}
+		for i, child := range val {
+			flattenJSON(child, fmt.Sprintf("%s[%d]", path, i), leaves)
+		}
+	default:
+		leaves[path] = val
+	}
+}
@@ -270,3 +270,25 @@ skills:
 		assert.Equal(t, "abc", envCfg.Tools.Web.Brave.APIKeys[1].raw)
 	})
 }
+
+func TestSecurityConfig_SwarmPeerTokensMergeByName(t *testing.T) {
+	dir := t.TempDir()
+	secPath := filepath.Join(dir, ".security.yml")
+	data := "swarm:\n  token: inbound-secret\n  peers:\n    lab:\n      token: lab-secret\n"
+	require.NoError(t, os.WriteFile(secPath, []byte(data), 0o600))
+
+	cfg := &Config{Channels: make(ChannelsConfig)}
+	cfg.Swarm.Peers = SwarmPeers{
+		{Name: "lab", URL: "http://lab:18790"},
+		{Name: "desk", URL: "http://desk:18790"},
+	}
+	require.NoError(t, loadSecurityConfig(cfg, secPath))
+
+	assert.Equal(t, "inbound-secret", cfg.Swarm.Token.String())
+	lab, ok := cfg.Swarm.Peers.Get("LAB")
+	require.True(t, ok)
+	assert.Equal(t, "http://lab:18790", lab.URL)
+	assert.Equal(t, "lab-secret", lab.Token.String())
+	desk, _ := cfg.Swarm.Peers.Get("desk")
+	assert.Empty(t, desk.Token.String())
+}
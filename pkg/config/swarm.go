@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// SwarmConfig lets PicoClaw instances delegate spawn tasks to each other.
+//
+// When enabled, the gateway serves the peer API under /swarm/ and accepts
+// requests bearing Token. Peers lists the remote instances this one may
+// delegate to; the spawn tool reaches them through its "peer" argument.
+type SwarmConfig struct {
+	Enabled bool         `json:"enabled"                   yaml:"-"               env:"PICOCLAW_SWARM_ENABLED"`
+	Name    string       `json:"name,omitempty"            yaml:"-"               env:"PICOCLAW_SWARM_NAME"`
+	Token   SecureString `json:"token,omitzero"            yaml:"token,omitempty" env:"PICOCLAW_SWARM_TOKEN"`
+	Timeout int          `json:"timeout_seconds,omitempty" yaml:"-"               env:"PICOCLAW_SWARM_TIMEOUT_SECONDS"` // per delegated task, 0 = 10 minutes
+	// AllowAgents lists the local agents remote peers may run (glob patterns).
+	// Empty allows none; "*" allows all.
+	AllowAgents []string   `json:"allow_agents,omitempty" yaml:"-"`
+	Peers       SwarmPeers `json:"peers,omitempty"        yaml:"peers,omitempty"`
+}
+
+// SwarmPeer is a remote PicoClaw instance reachable over its gateway.
+type SwarmPeer struct {
+	Name  string       `json:"name"           yaml:"-"`
+	URL   string       `json:"url"            yaml:"-"`
+	Token SecureString `json:"token,omitzero" yaml:"token,omitempty"`
+}
+
+// SwarmPeers keeps peer tokens in security.yml keyed by peer name, the same
+// way SecureModelList keeps API keys out of config.json.
+type SwarmPeers []*SwarmPeer
+
+// Get returns the peer with the given name (case-insensitive).
+func (p SwarmPeers) Get(name string) (*SwarmPeer, bool) {
+	for _, peer := range p {
+		if peer != nil && strings.EqualFold(peer.Name, name) {
+			return peer, true
+		}
+	}
+	return nil, false
+}
+
+func (p *SwarmPeers) UnmarshalYAML(value *yaml.Node) error {
+	mm := make(map[string]*SwarmPeer)
+	if err := value.Decode(&mm); err != nil {
+		logger.Errorf("Decode error: %v", err)
+		return err
+	}
+	for _, peer := range *p {
+		if peer == nil {
+			continue
+		}
+		if sec := mm[peer.Name]; sec != nil {
+			peer.Token = sec.Token
+		}
+	}
+	return nil
+}
+
+func (p SwarmPeers) MarshalYAML() (any, error) {
+	type onlySecureData struct {
+		Token SecureString `yaml:"token,omitempty"`
+	}
+	mm := make(map[string]onlySecureData)
+	for _, peer := range p {
+		if peer == nil || peer.Name == "" {
+			continue
+		}
+		mm[peer.Name] = onlySecureData{Token: peer.Token}
+	}
+	return mm, nil
+}
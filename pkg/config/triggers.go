@@ -0,0 +1,33 @@
+package config
+
+// TriggersToolsConfig configures event-driven triggers and the agent-facing
+// triggers tool.
+//
+// Triggers listed here are always active while the gateway runs; the tool and
+// the `picoclaw triggers` CLI manage additional triggers kept in
+// <workspace>/triggers/triggers.json. Enabled only controls the tool.
+type TriggersToolsConfig struct {
+	ToolConfig         `                envPrefix:"PICOCLAW_TOOLS_TRIGGERS_"`
+	AllowPrivateHosts  bool            `                                     json:"allow_private_hosts"            env:"PICOCLAW_TOOLS_TRIGGERS_ALLOW_PRIVATE_HOSTS"`
+	MinIntervalSeconds int             `                                     json:"min_interval_seconds,omitempty" env:"PICOCLAW_TOOLS_TRIGGERS_MIN_INTERVAL_SECONDS"` // 0 = 60
+	Triggers           []TriggerConfig `                                     json:"triggers,omitempty"`
+}
+
+// TriggerConfig defines one trigger in config.json.
+type TriggerConfig struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"` // file, feed or http
+	Disabled bool   `json:"disabled,omitempty"`
+	Path     string `json:"path,omitempty"`
+	URL      string `json:"url,omitempty"`
+	JSONPath string `json:"json_path,omitempty"`
+	// IntervalSeconds is the poll interval of feed and http triggers (0 = 300).
+	IntervalSeconds int `json:"interval_seconds,omitempty"`
+	// Prompt is a Go text/template rendered with the event; empty uses a
+	// per-kind default.
+	Prompt string `json:"prompt,omitempty"`
+	// Channel and ChatID select where the turn is delivered. Empty uses the
+	// last active chat.
+	Channel string `json:"channel,omitempty"`
+	ChatID  string `json:"chat_id,omitempty"`
+}
@@ -0,0 +1,149 @@
+package cron
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+const (
+	// DefaultHistoryLimit is the number of runs kept per job when no limit is configured.
+	DefaultHistoryLimit = 20
+
+	// maxOutputExcerpt bounds the handler output stored with each run record.
+	maxOutputExcerpt = 500
+)
+
+// Run statuses recorded in job history and CronJobState.LastStatus.
+const (
+	RunStatusOK      = "ok"
+	RunStatusError   = "error"
+	RunStatusTimeout = "timeout"
+	RunStatusSkipped = "skipped"
+)
+
+// Run triggers describe why a run started.
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+	TriggerCatchUp  = "catch_up"
+	TriggerQueued   = "queued"
+)
+
+// CronRun is a single execution attempt of a job.
+type CronRun struct {
+	JobID       string `json:"jobId"`
+	Trigger     string `json:"trigger"`
+	Attempt     int    `json:"attempt"`
+	StartedAtMS int64  `json:"startedAtMs"`
+	DurationMS  int64  `json:"durationMs"`
+	Status      string `json:"status"`
+	Output      string `json:"output,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// historyPath returns the JSONL file holding the run history of a job.
+// History lives next to the job store so the CLI and the gateway share it.
+func (cs *CronService) historyPath(jobID string) string {
+	return filepath.Join(filepath.Dir(cs.storePath), "runs", jobID+".jsonl")
+}
+
+// appendHistory records a run and trims the job's history to the configured limit.
+func (cs *CronService) appendHistory(run CronRun) error {
+	cs.historyMu.Lock()
+	defer cs.historyMu.Unlock()
+
+	limit := cs.historyLimit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+
+	path := cs.historyPath(run.JobID)
+	runs, err := readHistory(path)
+	if err != nil {
+		return err
+	}
+	runs = append(runs, run)
+	if len(runs) > limit {
+		runs = runs[len(runs)-limit:]
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range runs {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return fileutil.WriteFileAtomic(path, buf.Bytes(), 0o600)
+}
+
+// History returns the recorded runs of a job, newest first. A limit of 0
+// returns everything that is kept on disk.
+func (cs *CronService) History(jobID string, limit int) ([]CronRun, error) {
+	cs.historyMu.Lock()
+	runs, err := readHistory(cs.historyPath(jobID))
+	cs.historyMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+func (cs *CronService) removeHistory(jobID string) {
+	cs.historyMu.Lock()
+	defer cs.historyMu.Unlock()
+	if err := os.Remove(cs.historyPath(jobID)); err != nil && !os.IsNotExist(err) {
+		log.Printf("[cron] failed to remove history for job %s: %v", jobID, err)
+	}
+}
+
+func readHistory(path string) ([]CronRun, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []CronRun
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var run CronRun
+		if err := json.Unmarshal(line, &run); err != nil {
+			// Skip a torn line rather than losing the whole history.
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, scanner.Err()
+}
+
+func outputExcerpt(output string) string {
+	output = strings.TrimSpace(output)
+	runes := []rune(output)
+	if len(runes) <= maxOutputExcerpt {
+		return output
+	}
+	return string(runes[:maxOutputExcerpt]) + "…"
+}
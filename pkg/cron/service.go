@@ -1,9 +1,11 @@
 package cron
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -32,22 +34,95 @@ type CronPayload struct {
 }
 
 type CronJobState struct {
-	NextRunAtMS *int64 `json:"nextRunAtMs,omitempty"`
-	LastRunAtMS *int64 `json:"lastRunAtMs,omitempty"`
-	LastStatus  string `json:"lastStatus,omitempty"`
-	LastError   string `json:"lastError,omitempty"`
+	NextRunAtMS      *int64 `json:"nextRunAtMs,omitempty"`
+	LastRunAtMS      *int64 `json:"lastRunAtMs,omitempty"`
+	LastStatus       string `json:"lastStatus,omitempty"`
+	LastError        string `json:"lastError,omitempty"`
+	LastDurationMS   int64  `json:"lastDurationMs,omitempty"`
+	RunRequestedAtMS *int64 `json:"runRequestedAtMs,omitempty"`
+}
+
+// Concurrency policies decide what happens when a job fires while a previous
+// run of the same job is still in progress.
+const (
+	ConcurrencySkip  = "skip"
+	ConcurrencyQueue = "queue"
+	ConcurrencyAllow = "allow"
+)
+
+// Catch-up policies decide what happens to runs missed while the service was down.
+const (
+	CatchUpSkip = "skip"
+	CatchUpOnce = "once"
+)
+
+// maxRetryBackoff caps the exponential backoff between retry attempts.
+const maxRetryBackoff = time.Hour
+
+// storePollInterval bounds how long the run loop sleeps before checking the
+// store file for changes made by another process (CLI, web launcher).
+const storePollInterval = 5 * time.Second
+
+// CronRunPolicy controls retries, timeouts, overlap and catch-up of a job.
+// Zero fields fall back to the service defaults.
+type CronRunPolicy struct {
+	MaxRetries     int    `json:"maxRetries,omitempty"`
+	RetryBackoffMS int64  `json:"retryBackoffMs,omitempty"`
+	TimeoutMS      int64  `json:"timeoutMs,omitempty"`
+	Concurrency    string `json:"concurrency,omitempty"`
+	CatchUp        string `json:"catchUp,omitempty"`
+}
+
+// ValidatePolicy checks the enumerated fields of a run policy.
+func ValidatePolicy(p CronRunPolicy) error {
+	switch p.Concurrency {
+	case "", ConcurrencySkip, ConcurrencyQueue, ConcurrencyAllow:
+	default:
+		return fmt.Errorf("invalid concurrency policy %q (want skip, queue or allow)", p.Concurrency)
+	}
+	switch p.CatchUp {
+	case "", CatchUpSkip, CatchUpOnce:
+	default:
+		return fmt.Errorf("invalid catch-up policy %q (want skip or once)", p.CatchUp)
+	}
+	if p.MaxRetries < 0 || p.RetryBackoffMS < 0 || p.TimeoutMS < 0 {
+		return fmt.Errorf("retries, backoff and timeout must not be negative")
+	}
+	return nil
+}
+
+// ValidateSchedule checks that a schedule has the fields its kind needs.
+func ValidateSchedule(s CronSchedule) error {
+	switch s.Kind {
+	case "at":
+		if s.AtMS == nil || *s.AtMS <= 0 {
+			return fmt.Errorf("\"at\" schedule needs a run time")
+		}
+	case "every":
+		if s.EveryMS == nil || *s.EveryMS <= 0 {
+			return fmt.Errorf("\"every\" schedule needs a positive interval")
+		}
+	case "cron":
+		if !gronx.IsValid(s.Expr) {
+			return fmt.Errorf("invalid cron expression %q", s.Expr)
+		}
+	default:
+		return fmt.Errorf("invalid schedule kind %q (want at, every or cron)", s.Kind)
+	}
+	return nil
 }
 
 type CronJob struct {
-	ID             string       `json:"id"`
-	Name           string       `json:"name"`
-	Enabled        bool         `json:"enabled"`
-	Schedule       CronSchedule `json:"schedule"`
-	Payload        CronPayload  `json:"payload"`
-	State          CronJobState `json:"state"`
-	CreatedAtMS    int64        `json:"createdAtMs"`
-	UpdatedAtMS    int64        `json:"updatedAtMs"`
-	DeleteAfterRun bool         `json:"deleteAfterRun"`
+	ID             string        `json:"id"`
+	Name           string        `json:"name"`
+	Enabled        bool          `json:"enabled"`
+	Schedule       CronSchedule  `json:"schedule"`
+	Payload        CronPayload   `json:"payload"`
+	Policy         CronRunPolicy `json:"policy,omitzero"`
+	State          CronJobState  `json:"state"`
+	CreatedAtMS    int64         `json:"createdAtMs"`
+	UpdatedAtMS    int64         `json:"updatedAtMs"`
+	DeleteAfterRun bool          `json:"deleteAfterRun"`
 }
 
 type CronStore struct {
@@ -55,17 +130,31 @@ type CronStore struct {
 	Jobs    []CronJob `json:"jobs"`
 }
 
-type JobHandler func(job *CronJob) (string, error)
+// JobHandler executes a job. The context is cancelled when the job's timeout
+// expires or the service stops; the returned string is kept as the run's output.
+type JobHandler func(ctx context.Context, job *CronJob) (string, error)
 
 type CronService struct {
-	storePath string
-	store     *CronStore
-	onJob     JobHandler
-	mu        sync.RWMutex
-	running   bool
-	stopChan  chan struct{}
-	wakeChan  chan struct{}
-	gronx     *gronx.Gronx
+	storePath    string
+	store        *CronStore
+	storeModTime time.Time
+	onJob        JobHandler
+	mu           sync.RWMutex
+	running      bool
+	stopChan     chan struct{}
+	wakeChan     chan struct{}
+	gronx        *gronx.Gronx
+
+	defaults     CronRunPolicy
+	historyLimit int
+	historyMu    sync.Mutex
+
+	runCtx    context.Context
+	runCancel context.CancelFunc
+	active    map[string]int  // in-flight runs per job
+	queued    map[string]int  // runs waiting for an in-flight run (concurrency "queue")
+	catchUp   map[string]bool // jobs due because of a missed run
+	runs      sync.WaitGroup
 }
 
 func NewCronService(storePath string, onJob JobHandler) *CronService {
@@ -74,6 +163,14 @@ func NewCronService(storePath string, onJob JobHandler) *CronService {
 		onJob:     onJob,
 		gronx:     gronx.New(),
 		wakeChan:  make(chan struct{}),
+		defaults: CronRunPolicy{
+			Concurrency: ConcurrencySkip,
+			CatchUp:     CatchUpSkip,
+		},
+		historyLimit: DefaultHistoryLimit,
+		active:       make(map[string]int),
+		queued:       make(map[string]int),
+		catchUp:      make(map[string]bool),
 	}
 	// Initialize and load store on creation
 	cs.loadStore()
@@ -101,6 +198,7 @@ func (cs *CronService) Start() error {
 	if cs.wakeChan == nil {
 		cs.wakeChan = make(chan struct{})
 	}
+	cs.runCtx, cs.runCancel = context.WithCancel(context.Background())
 	cs.running = true
 	go cs.runLoop(cs.stopChan)
 
@@ -120,6 +218,16 @@ func (cs *CronService) Stop() {
 		close(cs.stopChan)
 		cs.stopChan = nil
 	}
+	// In-flight runs see their context cancelled; they still record their
+	// outcome but are not waited for here.
+	if cs.runCancel != nil {
+		cs.runCancel()
+	}
+}
+
+// Wait blocks until all in-flight job runs have finished.
+func (cs *CronService) Wait() {
+	cs.runs.Wait()
 }
 
 func (cs *CronService) runLoop(stopChan chan struct{}) {
@@ -130,17 +238,18 @@ func (cs *CronService) runLoop(stopChan chan struct{}) {
 	defer timer.Stop()
 
 	for {
-		// every loop, recalculate the next wake time
-		cs.mu.RLock()
+		// every loop, pick up external edits and recalculate the next wake time
+		cs.mu.Lock()
+		cs.reloadIfChangedUnsafe()
 		nextWake := cs.getNextWakeMS()
-		cs.mu.RUnlock()
+		cs.mu.Unlock()
 
 		var delay time.Duration
 		now := time.Now().UnixMilli()
 
 		if nextWake == nil {
-			// no jobs, sleep for a long time (or until a new job is added)
-			delay = time.Hour
+			// no jobs, sleep until the next store poll (or until a new job is added)
+			delay = storePollInterval
 		} else {
 			diff := *nextWake - now
 			if diff <= 0 {
@@ -149,6 +258,9 @@ func (cs *CronService) runLoop(stopChan chan struct{}) {
 				delay = time.Duration(diff) * time.Millisecond
 			}
 		}
+		if delay > storePollInterval {
+			delay = storePollInterval
+		}
 
 		timer.Reset(delay)
 
@@ -171,73 +283,191 @@ func (cs *CronService) runLoop(stopChan chan struct{}) {
 
 func (cs *CronService) checkJobs() {
 	cs.mu.Lock()
+	defer cs.mu.Unlock()
 
 	if !cs.running {
-		cs.mu.Unlock()
 		return
 	}
 
 	now := time.Now().UnixMilli()
-	var dueJobIDs []string
+	type dueJob struct {
+		id      string
+		trigger string
+	}
+	var due []dueJob
 
-	// Collect jobs that are due (we need to copy them to execute outside lock)
 	for i := range cs.store.Jobs {
 		job := &cs.store.Jobs[i]
-		if job.Enabled && job.State.NextRunAtMS != nil && *job.State.NextRunAtMS <= now {
-			dueJobIDs = append(dueJobIDs, job.ID)
+		switch {
+		case job.State.RunRequestedAtMS != nil:
+			// Manual runs fire even for disabled jobs and leave the schedule alone.
+			job.State.RunRequestedAtMS = nil
+			due = append(due, dueJob{job.ID, TriggerManual})
+		case job.Enabled && job.State.NextRunAtMS != nil && *job.State.NextRunAtMS <= now:
+			trigger := TriggerSchedule
+			if cs.catchUp[job.ID] {
+				trigger = TriggerCatchUp
+				delete(cs.catchUp, job.ID)
+			}
+			// Advance recurring schedules at dispatch time so a slow run does
+			// not delay the next one; "at" jobs are settled when the run ends.
+			if job.Schedule.Kind == "at" {
+				job.State.NextRunAtMS = nil
+			} else {
+				job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, now)
+			}
+			due = append(due, dueJob{job.ID, trigger})
 		}
 	}
 
-	// Reset next run for due jobs before unlocking to avoid duplicate execution.
-	dueMap := make(map[string]bool, len(dueJobIDs))
-	for _, jobID := range dueJobIDs {
-		dueMap[jobID] = true
-	}
-	for i := range cs.store.Jobs {
-		if dueMap[cs.store.Jobs[i].ID] {
-			cs.store.Jobs[i].State.NextRunAtMS = nil
-		}
+	if len(due) == 0 {
+		return
 	}
 
 	if err := cs.saveStoreUnsafe(); err != nil {
 		log.Printf("[cron] failed to save store: %v", err)
 	}
 
-	cs.mu.Unlock()
+	for _, d := range due {
+		cs.dispatchUnsafe(d.id, d.trigger)
+	}
+}
 
-	// Execute jobs outside lock.
-	for _, jobID := range dueJobIDs {
-		cs.executeJobByID(jobID)
+// dispatchUnsafe starts a run of jobID or applies the job's concurrency
+// policy when a previous run is still in progress. Callers hold cs.mu.
+func (cs *CronService) dispatchUnsafe(jobID, trigger string) {
+	job := cs.findJobUnsafe(jobID)
+	if job == nil {
+		return
 	}
+
+	if cs.active[jobID] > 0 {
+		switch cs.effectivePolicy(job).Concurrency {
+		case ConcurrencyQueue:
+			cs.queued[jobID]++
+			log.Printf("[cron] job '%s' is still running, queued another run", job.Name)
+			return
+		case ConcurrencyAllow:
+		default:
+			log.Printf("[cron] job '%s' is still running, skipping this run", job.Name)
+			run := CronRun{
+				JobID:       jobID,
+				Trigger:     trigger,
+				StartedAtMS: time.Now().UnixMilli(),
+				Status:      RunStatusSkipped,
+				Error:       "previous run still in progress",
+			}
+			go func() {
+				if err := cs.appendHistory(run); err != nil {
+					log.Printf("[cron] failed to record history for job %s: %v", jobID, err)
+				}
+			}()
+			return
+		}
+	}
+
+	cs.active[jobID]++
+	cs.runs.Add(1)
+	go cs.runJob(jobID, trigger)
 }
 
-func (cs *CronService) executeJobByID(jobID string) {
+// runJob executes a job with retries, then drains any runs queued behind it.
+func (cs *CronService) runJob(jobID, trigger string) {
+	defer cs.runs.Done()
+
+	for {
+		cs.executeJobByID(jobID, trigger)
+
+		cs.mu.Lock()
+		if cs.queued[jobID] > 0 && cs.findJobUnsafe(jobID) != nil {
+			cs.queued[jobID]--
+			cs.mu.Unlock()
+			trigger = TriggerQueued
+			continue
+		}
+		delete(cs.queued, jobID)
+		if cs.active[jobID]--; cs.active[jobID] <= 0 {
+			delete(cs.active, jobID)
+		}
+		cs.mu.Unlock()
+		return
+	}
+}
+
+func (cs *CronService) executeJobByID(jobID, trigger string) {
 	startTime := time.Now().UnixMilli()
 
 	cs.mu.RLock()
 	var callbackJob *CronJob
-	for i := range cs.store.Jobs {
-		job := &cs.store.Jobs[i]
-		if job.ID == jobID {
-			jobCopy := *job
-			callbackJob = &jobCopy
-			break
-		}
+	if job := cs.findJobUnsafe(jobID); job != nil {
+		jobCopy := *job
+		callbackJob = &jobCopy
 	}
+	policy := cs.defaults
+	if callbackJob != nil {
+		policy = cs.effectivePolicy(callbackJob)
+	}
+	handler := cs.onJob
+	ctx := cs.runCtx
 	cs.mu.RUnlock()
 
 	if callbackJob == nil {
 		log.Printf("[cron] job %s not found, skipping", jobID)
 		return
 	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
 	// Log job execution start
-	log.Printf("[cron] ▶ executing job '%s' (id: %s, schedule: %s, channel: %s)",
-		callbackJob.Name, jobID, callbackJob.Schedule.Kind, callbackJob.Payload.Channel)
+	log.Printf("[cron] ▶ executing job '%s' (id: %s, schedule: %s, channel: %s, trigger: %s)",
+		callbackJob.Name, jobID, callbackJob.Schedule.Kind, callbackJob.Payload.Channel, trigger)
 
 	var err error
-	if cs.onJob != nil {
-		_, err = cs.onJob(callbackJob)
+	var status string
+	for attempt := 1; ; attempt++ {
+		attemptStart := time.Now()
+		var output string
+		output, err = cs.invoke(ctx, handler, callbackJob, policy)
+
+		status = RunStatusOK
+		if err != nil {
+			status = RunStatusError
+			if errors.Is(err, context.DeadlineExceeded) {
+				status = RunStatusTimeout
+			}
+		}
+		run := CronRun{
+			JobID:       jobID,
+			Trigger:     trigger,
+			Attempt:     attempt,
+			StartedAtMS: attemptStart.UnixMilli(),
+			DurationMS:  time.Since(attemptStart).Milliseconds(),
+			Status:      status,
+			Output:      outputExcerpt(output),
+		}
+		if err != nil {
+			run.Error = err.Error()
+		}
+		if herr := cs.appendHistory(run); herr != nil {
+			log.Printf("[cron] failed to record history for job %s: %v", jobID, herr)
+		}
+
+		if err == nil || attempt > policy.MaxRetries || ctx.Err() != nil {
+			break
+		}
+
+		backoff := retryBackoff(policy.RetryBackoffMS, attempt)
+		log.Printf("[cron] job '%s' attempt %d failed: %v; retrying in %s", callbackJob.Name, attempt, err, backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			break
+		}
 	}
 
 	execDuration := time.Now().UnixMilli() - startTime
@@ -246,33 +476,27 @@ func (cs *CronService) executeJobByID(jobID string) {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
-	var job *CronJob
-	for i := range cs.store.Jobs {
-		if cs.store.Jobs[i].ID == jobID {
-			job = &cs.store.Jobs[i]
-			break
-		}
-	}
+	job := cs.findJobUnsafe(jobID)
 	if job == nil {
 		log.Printf("[cron] job %s disappeared before state update", jobID)
 		return
 	}
 
 	job.State.LastRunAtMS = &startTime
+	job.State.LastDurationMS = execDuration
+	job.State.LastStatus = status
 	job.UpdatedAtMS = time.Now().UnixMilli()
 
 	if err != nil {
-		job.State.LastStatus = "error"
 		job.State.LastError = err.Error()
 		log.Printf("[cron] ✗ job '%s' failed after %dms: %v", job.Name, execDuration, err)
 	} else {
-		job.State.LastStatus = "ok"
 		job.State.LastError = ""
 	}
 
-	// Compute next run time
+	// Compute next run time. Manual runs of recurring jobs keep their schedule.
 	var nextRunStr string
-	if job.Schedule.Kind == "at" {
+	if job.Schedule.Kind == "at" && trigger != TriggerManual {
 		if job.DeleteAfterRun {
 			cs.removeJobUnsafe(job.ID)
 			nextRunStr = "(deleted)"
@@ -281,14 +505,10 @@ func (cs *CronService) executeJobByID(jobID string) {
 			job.State.NextRunAtMS = nil
 			nextRunStr = "(disabled)"
 		}
+	} else if job.State.NextRunAtMS != nil {
+		nextRunStr = time.UnixMilli(*job.State.NextRunAtMS).Format("2006-01-02 15:04:05")
 	} else {
-		nextRun := cs.computeNextRun(&job.Schedule, time.Now().UnixMilli())
-		job.State.NextRunAtMS = nextRun
-		if nextRun != nil {
-			nextRunStr = time.UnixMilli(*nextRun).Format("2006-01-02 15:04:05")
-		} else {
-			nextRunStr = "(none)"
-		}
+		nextRunStr = "(none)"
 	}
 
 	if err == nil {
@@ -300,6 +520,94 @@ func (cs *CronService) executeJobByID(jobID string) {
 	}
 }
 
+// invoke calls the handler under the job's timeout. A handler that ignores
+// its context is abandoned once the timeout fires so the run can be recorded.
+func (cs *CronService) invoke(
+	ctx context.Context,
+	handler JobHandler,
+	job *CronJob,
+	policy CronRunPolicy,
+) (string, error) {
+	if handler == nil {
+		return "", nil
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if policy.TimeoutMS > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(policy.TimeoutMS)*time.Millisecond)
+	}
+	defer cancel()
+
+	type result struct {
+		output string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		output, err := handler(ctx, job)
+		done <- result{output, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err == nil && ctx.Err() != nil {
+			return r.output, fmt.Errorf("job interrupted: %w", ctx.Err())
+		}
+		return r.output, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("timed out after %s: %w",
+				time.Duration(policy.TimeoutMS)*time.Millisecond, ctx.Err())
+		}
+		return "", fmt.Errorf("job interrupted: %w", ctx.Err())
+	}
+}
+
+// retryBackoff doubles the base delay for every failed attempt.
+func retryBackoff(baseMS int64, attempt int) time.Duration {
+	if baseMS <= 0 {
+		return 0
+	}
+	backoff := time.Duration(baseMS) * time.Millisecond
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= maxRetryBackoff {
+			return maxRetryBackoff
+		}
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+// effectivePolicy merges a job's policy over the service defaults.
+func (cs *CronService) effectivePolicy(job *CronJob) CronRunPolicy {
+	p := cs.defaults
+	if job.Policy.MaxRetries > 0 {
+		p.MaxRetries = job.Policy.MaxRetries
+	}
+	if job.Policy.RetryBackoffMS > 0 {
+		p.RetryBackoffMS = job.Policy.RetryBackoffMS
+	}
+	if job.Policy.TimeoutMS > 0 {
+		p.TimeoutMS = job.Policy.TimeoutMS
+	}
+	if job.Policy.Concurrency != "" {
+		p.Concurrency = job.Policy.Concurrency
+	}
+	if job.Policy.CatchUp != "" {
+		p.CatchUp = job.Policy.CatchUp
+	}
+	return p
+}
+
+func (cs *CronService) findJobUnsafe(jobID string) *CronJob {
+	for i := range cs.store.Jobs {
+		if cs.store.Jobs[i].ID == jobID {
+			return &cs.store.Jobs[i]
+		}
+	}
+	return nil
+}
+
 func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int64 {
 	switch schedule.Kind {
 	case "at":
@@ -347,15 +655,52 @@ func (cs *CronService) recomputeNextRuns() {
 	now := time.Now().UnixMilli()
 	for i := range cs.store.Jobs {
 		job := &cs.store.Jobs[i]
-		if job.Enabled {
-			job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, now)
+		if !job.Enabled {
+			continue
 		}
+		// A persisted next run in the past was missed while the service was down.
+		missed := job.State.NextRunAtMS != nil && *job.State.NextRunAtMS <= now
+		if missed && cs.effectivePolicy(job).CatchUp == CatchUpOnce {
+			dueAt := now
+			job.State.NextRunAtMS = &dueAt
+			cs.catchUp[job.ID] = true
+			continue
+		}
+		job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, now)
 	}
 }
 
+// reloadIfChangedUnsafe reloads the store when another process (the CLI or
+// the web launcher) rewrote it since the last load or save.
+func (cs *CronService) reloadIfChangedUnsafe() {
+	info, err := os.Stat(cs.storePath)
+	if err != nil || info.ModTime().Equal(cs.storeModTime) {
+		return
+	}
+
+	data, err := os.ReadFile(cs.storePath)
+	if err != nil {
+		log.Printf("[cron] failed to reload store: %v", err)
+		return
+	}
+	store := &CronStore{}
+	if err := json.Unmarshal(data, store); err != nil {
+		log.Printf("[cron] ignoring unreadable store update: %v", err)
+		cs.storeModTime = info.ModTime()
+		return
+	}
+	cs.store = store
+	cs.storeModTime = info.ModTime()
+}
+
 func (cs *CronService) getNextWakeMS() *int64 {
 	var nextWake *int64
 	for _, job := range cs.store.Jobs {
+		if job.State.RunRequestedAtMS != nil {
+			if nextWake == nil || *job.State.RunRequestedAtMS < *nextWake {
+				nextWake = job.State.RunRequestedAtMS
+			}
+		}
 		if job.Enabled && job.State.NextRunAtMS != nil {
 			if nextWake == nil || *job.State.NextRunAtMS < *nextWake {
 				nextWake = job.State.NextRunAtMS
@@ -377,6 +722,29 @@ func (cs *CronService) SetOnJob(handler JobHandler) {
 	cs.onJob = handler
 }
 
+// SetDefaultPolicy sets the policy applied to jobs that leave a field unset.
+func (cs *CronService) SetDefaultPolicy(policy CronRunPolicy) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if policy.Concurrency == "" {
+		policy.Concurrency = ConcurrencySkip
+	}
+	if policy.CatchUp == "" {
+		policy.CatchUp = CatchUpSkip
+	}
+	cs.defaults = policy
+}
+
+// SetHistoryLimit sets how many runs are kept per job. Values <= 0 restore the default.
+func (cs *CronService) SetHistoryLimit(limit int) {
+	cs.historyMu.Lock()
+	defer cs.historyMu.Unlock()
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	cs.historyLimit = limit
+}
+
 func (cs *CronService) loadStore() error {
 	cs.store = &CronStore{
 		Version: 1,
@@ -391,6 +759,9 @@ func (cs *CronService) loadStore() error {
 		return err
 	}
 
+	if info, err := os.Stat(cs.storePath); err == nil {
+		cs.storeModTime = info.ModTime()
+	}
 	return json.Unmarshal(data, cs.store)
 }
 
@@ -401,7 +772,13 @@ func (cs *CronService) saveStoreUnsafe() error {
 	}
 
 	// Use unified atomic write utility with explicit sync for flash storage reliability.
-	return fileutil.WriteFileAtomic(cs.storePath, data, 0o600)
+	if err := fileutil.WriteFileAtomic(cs.storePath, data, 0o600); err != nil {
+		return err
+	}
+	if info, err := os.Stat(cs.storePath); err == nil {
+		cs.storeModTime = info.ModTime()
+	}
+	return nil
 }
 
 func (cs *CronService) AddJob(
@@ -464,11 +841,83 @@ func (cs *CronService) UpdateJob(job *CronJob) error {
 	return fmt.Errorf("job not found")
 }
 
+// EditJob applies edit to a stored job and recomputes its next run, so
+// schedule changes take effect immediately.
+func (cs *CronService) EditJob(jobID string, edit func(job *CronJob)) (*CronJob, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	job := cs.findJobUnsafe(jobID)
+	if job == nil {
+		return nil, fmt.Errorf("job not found")
+	}
+	edit(job)
+
+	now := time.Now().UnixMilli()
+	job.ID = jobID
+	job.UpdatedAtMS = now
+	job.DeleteAfterRun = job.Schedule.Kind == "at"
+	if job.Enabled {
+		job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, now)
+	} else {
+		job.State.NextRunAtMS = nil
+	}
+
+	if err := cs.saveStoreUnsafe(); err != nil {
+		return nil, err
+	}
+	cs.notify()
+
+	edited := *job
+	return &edited, nil
+}
+
 func (cs *CronService) RemoveJob(jobID string) bool {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
-	return cs.removeJobUnsafe(jobID)
+	removed := cs.removeJobUnsafe(jobID)
+	if removed {
+		cs.removeHistory(jobID)
+	}
+	return removed
+}
+
+// RequestRun marks a job to run as soon as possible, regardless of its
+// schedule or enabled state. The running gateway picks the request up from
+// the store, so this also works from the CLI and the web launcher.
+func (cs *CronService) RequestRun(jobID string) *CronJob {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	job := cs.findJobUnsafe(jobID)
+	if job == nil {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	job.State.RunRequestedAtMS = &now
+	if err := cs.saveStoreUnsafe(); err != nil {
+		log.Printf("[cron] failed to save store after run request: %v", err)
+	}
+
+	cs.notify()
+
+	jobCopy := *job
+	return &jobCopy
+}
+
+// GetJob returns a copy of the job with the given ID, or nil.
+func (cs *CronService) GetJob(jobID string) *CronJob {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	job := cs.findJobUnsafe(jobID)
+	if job == nil {
+		return nil
+	}
+	jobCopy := *job
+	return &jobCopy
 }
 
 func (cs *CronService) removeJobUnsafe(jobID string) bool {
@@ -1,6 +1,7 @@
 package cron
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -40,14 +41,15 @@ func int64Ptr(v int64) *int64 {
 	return &v
 }
 
-func setupService(handler JobHandler) (*CronService, string) {
-	tmpFile := fmt.Sprintf("test_cron_%d.json", time.Now().UnixNano())
+func setupService(t *testing.T, handler JobHandler) (*CronService, string) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), fmt.Sprintf("test_cron_%d.json", time.Now().UnixNano()))
 	cs := NewCronService(tmpFile, handler)
 	return cs, tmpFile
 }
 
 func TestCronService_CRUD(t *testing.T) {
-	cs, path := setupService(nil)
+	cs, path := setupService(t, nil)
 	defer os.Remove(path)
 
 	// Test AddJob
@@ -84,7 +86,7 @@ func TestCronService_CRUD(t *testing.T) {
 
 // 2. Test Cron Expression Calculation Logic
 func TestCronService_ComputeNextRun(t *testing.T) {
-	cs, path := setupService(nil)
+	cs, path := setupService(t, nil)
 	defer os.Remove(path)
 
 	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC).UnixMilli()
@@ -116,14 +118,14 @@ func TestCronService_ExecutionFlow(t *testing.T) {
 	var mu sync.Mutex
 	executedJobs := make(map[string]bool)
 
-	handler := func(job *CronJob) (string, error) {
+	handler := func(_ context.Context, job *CronJob) (string, error) {
 		mu.Lock()
 		executedJobs[job.ID] = true
 		mu.Unlock()
 		return "ok", nil
 	}
 
-	cs, path := setupService(handler)
+	cs, path := setupService(t, handler)
 	defer os.Remove(path)
 
 	// Start the service
@@ -195,7 +197,7 @@ func TestCronService_PersistenceIntegrity(t *testing.T) {
 }
 
 func TestCronService_ConcurrentAccess(t *testing.T) {
-	cs, path := setupService(nil)
+	cs, path := setupService(t, nil)
 	defer os.Remove(path)
 
 	cs.Start()
@@ -235,3 +237,250 @@ func TestCronService_ConcurrentAccess(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestCronService_RetriesRecordHistory(t *testing.T) {
+	var calls int
+	handler := func(_ context.Context, _ *CronJob) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", fmt.Errorf("boom %d", calls)
+		}
+		return "done", nil
+	}
+
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), handler)
+	job, err := cs.AddJob("retry", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "m", "", "")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	job.Policy = CronRunPolicy{MaxRetries: 2, RetryBackoffMS: 1}
+	if err := cs.UpdateJob(job); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+
+	cs.executeJobByID(job.ID, TriggerManual)
+
+	if calls != 3 {
+		t.Fatalf("handler called %d times, want 3", calls)
+	}
+	runs, err := cs.History(job.ID, 0)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("history has %d runs, want 3", len(runs))
+	}
+	if runs[0].Status != RunStatusOK || runs[0].Attempt != 3 || runs[0].Output != "done" {
+		t.Errorf("newest run = %+v, want ok attempt 3 with output", runs[0])
+	}
+	if runs[2].Status != RunStatusError || runs[2].Error != "boom 1" {
+		t.Errorf("oldest run = %+v, want first failure", runs[2])
+	}
+
+	got := cs.GetJob(job.ID)
+	if got.State.LastStatus != RunStatusOK || got.State.LastError != "" {
+		t.Errorf("state = %+v, want ok", got.State)
+	}
+}
+
+func TestCronService_TimeoutAbandonsHandler(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	handler := func(_ context.Context, _ *CronJob) (string, error) {
+		<-release
+		return "", nil
+	}
+
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), handler)
+	job, _ := cs.AddJob("slow", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "m", "", "")
+	job.Policy = CronRunPolicy{TimeoutMS: 20}
+	cs.UpdateJob(job)
+
+	cs.executeJobByID(job.ID, TriggerManual)
+
+	got := cs.GetJob(job.ID)
+	if got.State.LastStatus != RunStatusTimeout {
+		t.Fatalf("LastStatus = %q, want %q", got.State.LastStatus, RunStatusTimeout)
+	}
+}
+
+func TestCronService_HistoryLimit(t *testing.T) {
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+	cs.SetHistoryLimit(3)
+	for i := range 5 {
+		if err := cs.appendHistory(CronRun{JobID: "j", Attempt: i + 1, Status: RunStatusOK}); err != nil {
+			t.Fatalf("appendHistory failed: %v", err)
+		}
+	}
+
+	runs, err := cs.History("j", 0)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(runs) != 3 || runs[0].Attempt != 5 || runs[2].Attempt != 3 {
+		t.Fatalf("runs = %+v, want attempts 5,4,3", runs)
+	}
+
+	if runs, _ := cs.History("j", 1); len(runs) != 1 {
+		t.Fatalf("History(limit=1) returned %d runs", len(runs))
+	}
+}
+
+func TestCronService_ConcurrencyPolicies(t *testing.T) {
+	for _, tt := range []struct {
+		policy    string
+		wantCalls int
+	}{
+		{ConcurrencySkip, 1},
+		{ConcurrencyQueue, 2},
+		{ConcurrencyAllow, 2},
+	} {
+		t.Run(tt.policy, func(t *testing.T) {
+			var mu sync.Mutex
+			calls := 0
+			started := make(chan struct{}, 4)
+			release := make(chan struct{})
+			handler := func(_ context.Context, _ *CronJob) (string, error) {
+				mu.Lock()
+				calls++
+				mu.Unlock()
+				started <- struct{}{}
+				<-release
+				return "", nil
+			}
+
+			cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), handler)
+			job, _ := cs.AddJob("overlap", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "m", "", "")
+			job.Policy = CronRunPolicy{Concurrency: tt.policy}
+			cs.UpdateJob(job)
+
+			cs.mu.Lock()
+			cs.dispatchUnsafe(job.ID, TriggerManual)
+			cs.mu.Unlock()
+			<-started
+
+			cs.mu.Lock()
+			cs.dispatchUnsafe(job.ID, TriggerManual)
+			cs.mu.Unlock()
+
+			close(release)
+			cs.Wait()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCronService_CatchUpOnce(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "jobs.json")
+	cs := NewCronService(storePath, nil)
+	missed, _ := cs.AddJob("missed", CronSchedule{Kind: "every", EveryMS: int64Ptr(3600000)}, "m", "", "")
+	skipped, _ := cs.AddJob("skipped", CronSchedule{Kind: "every", EveryMS: int64Ptr(3600000)}, "m", "", "")
+
+	past := time.Now().Add(-time.Minute).UnixMilli()
+	missed.Policy.CatchUp = CatchUpOnce
+	missed.State.NextRunAtMS = &past
+	cs.UpdateJob(missed)
+	skipped.State.NextRunAtMS = &past
+	cs.UpdateJob(skipped)
+
+	restarted := NewCronService(storePath, nil)
+	restarted.recomputeNextRuns()
+
+	now := time.Now().UnixMilli()
+	if got := restarted.GetJob(missed.ID).State.NextRunAtMS; got == nil || *got > now {
+		t.Errorf("catch-up job next run = %v, want due now", got)
+	}
+	if !restarted.catchUp[missed.ID] {
+		t.Error("catch-up job was not marked for a catch_up trigger")
+	}
+	if got := restarted.GetJob(skipped.ID).State.NextRunAtMS; got == nil || *got <= now {
+		t.Errorf("skip job next run = %v, want rescheduled in the future", got)
+	}
+}
+
+func TestCronService_RequestRun(t *testing.T) {
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+	job, _ := cs.AddJob("manual", CronSchedule{Kind: "every", EveryMS: int64Ptr(3600000)}, "m", "", "")
+	cs.EnableJob(job.ID, false)
+
+	if cs.RequestRun("missing") != nil {
+		t.Fatal("RequestRun should return nil for unknown job")
+	}
+	if cs.RequestRun(job.ID) == nil {
+		t.Fatal("RequestRun returned nil")
+	}
+
+	cs.running = true
+	cs.checkJobs()
+	cs.Wait()
+
+	got := cs.GetJob(job.ID)
+	if got.State.RunRequestedAtMS != nil {
+		t.Error("run request was not consumed")
+	}
+	if got.State.LastStatus != RunStatusOK {
+		t.Errorf("LastStatus = %q, want ok", got.State.LastStatus)
+	}
+	if got.Enabled {
+		t.Error("manual run must not enable the job")
+	}
+}
+
+func TestCronService_EditJobRecomputesNextRun(t *testing.T) {
+	cs, _ := setupService(t, nil)
+
+	at := time.Now().Add(time.Hour).UnixMilli()
+	job, err := cs.AddJob("Task", CronSchedule{Kind: "at", AtMS: &at}, "msg", "", "")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+
+	every := int64(60_000)
+	before := time.Now().UnixMilli()
+	edited, err := cs.EditJob(job.ID, func(j *CronJob) {
+		j.Schedule = CronSchedule{Kind: "every", EveryMS: &every}
+		j.Payload.Message = "new msg"
+	})
+	if err != nil {
+		t.Fatalf("EditJob failed: %v", err)
+	}
+	if edited.DeleteAfterRun {
+		t.Error("recurring job should not be deleted after run")
+	}
+	if next := edited.State.NextRunAtMS; next == nil || *next < before+every {
+		t.Errorf("NextRunAtMS = %v, want about now + 1m", next)
+	}
+	if got := cs.GetJob(job.ID); got.Payload.Message != "new msg" {
+		t.Errorf("stored message = %q", got.Payload.Message)
+	}
+
+	if _, err := cs.EditJob("missing", func(*CronJob) {}); err == nil {
+		t.Error("EditJob should fail for unknown job")
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	at := int64(1)
+	zero := int64(0)
+	tests := []struct {
+		schedule CronSchedule
+		valid    bool
+	}{
+		{CronSchedule{Kind: "at", AtMS: &at}, true},
+		{CronSchedule{Kind: "at"}, false},
+		{CronSchedule{Kind: "every", EveryMS: &zero}, false},
+		{CronSchedule{Kind: "cron", Expr: "*/5 * * * *"}, true},
+		{CronSchedule{Kind: "cron", Expr: "every day"}, false},
+		{CronSchedule{Kind: "weekly"}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateSchedule(tt.schedule); (err == nil) != tt.valid {
+			t.Errorf("ValidateSchedule(%+v) = %v, want valid=%v", tt.schedule, err, tt.valid)
+		}
+	}
+}
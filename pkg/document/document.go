@@ -0,0 +1,319 @@
+// Package document extracts text from office documents, PDFs and e-books
+// without external tools.
+//
+// Supported formats are PDF, DOCX, XLSX, PPTX, ODT and EPUB. Extraction keeps
+// the structure a reader would navigate by: PDF pages, DOCX pages (from the
+// page breaks Word records), XLSX sheets, PPTX slides and EPUB chapters.
+// Tables are rendered as Markdown-style rows.
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies a supported document format.
+type Format string
+
+const (
+	FormatPDF  Format = "pdf"
+	FormatDOCX Format = "docx"
+	FormatXLSX Format = "xlsx"
+	FormatPPTX Format = "pptx"
+	FormatODT  Format = "odt"
+	FormatEPUB Format = "epub"
+)
+
+// Section kinds, i.e. what a Section stands for in its format.
+const (
+	KindPage    = "page"
+	KindSheet   = "sheet"
+	KindSlide   = "slide"
+	KindChapter = "chapter"
+	KindSection = "section"
+)
+
+const (
+	// maxFileSize bounds the documents that are read at all.
+	maxFileSize = 64 << 20
+	// maxPartSize bounds one decompressed archive member or PDF stream, so a
+	// zip bomb cannot exhaust memory.
+	maxPartSize = 64 << 20
+)
+
+// ErrUnsupported is returned for files that are not a supported document.
+var ErrUnsupported = errors.New("unsupported document format")
+
+// Section is one navigable unit of a document.
+type Section struct {
+	Kind  string
+	Index int    // 1-based position among the document's sections
+	Title string // sheet name, chapter title; may be empty
+	Text  string
+}
+
+// Document is the extracted text of a file.
+type Document struct {
+	Format   Format
+	Title    string
+	Sections []Section
+}
+
+var extensionFormats = map[string]Format{
+	".pdf":  FormatPDF,
+	".docx": FormatDOCX,
+	".xlsx": FormatXLSX,
+	".pptx": FormatPPTX,
+	".odt":  FormatODT,
+	".epub": FormatEPUB,
+}
+
+var mimeFormats = map[string]Format{
+	"application/pdf": FormatPDF,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   FormatDOCX,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         FormatXLSX,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": FormatPPTX,
+	"application/vnd.oasis.opendocument.text":                                   FormatODT,
+	"application/epub+zip": FormatEPUB,
+}
+
+// DetectFormat returns the format of a file from its name or content type,
+// or "" when neither identifies a supported document.
+func DetectFormat(filename, contentType string) Format {
+	if f, ok := extensionFormats[strings.ToLower(filepath.Ext(filename))]; ok {
+		return f
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mimeFormats[mediaType]
+	}
+	return ""
+}
+
+// Supported reports whether a file can be extracted.
+func Supported(filename, contentType string) bool {
+	return DetectFormat(filename, contentType) != ""
+}
+
+// ExtractFile extracts the document at path. contentType may be empty.
+func ExtractFile(path, contentType string) (*Document, error) {
+	format := DetectFormat(path, contentType)
+	data, err := readLimited(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = sniffFormat(data)
+	}
+	if format == "" {
+		return nil, ErrUnsupported
+	}
+	return Extract(data, format)
+}
+
+// Extract extracts a document held in memory.
+func Extract(data []byte, format Format) (*Document, error) {
+	var (
+		doc *Document
+		err error
+	)
+	switch format {
+	case FormatPDF:
+		doc, err = extractPDF(data)
+	case FormatDOCX, FormatXLSX, FormatPPTX, FormatODT, FormatEPUB:
+		var zr *zip.Reader
+		zr, err = zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("open %s archive: %w", format, err)
+		}
+		switch format {
+		case FormatDOCX:
+			doc, err = extractDOCX(zr)
+		case FormatXLSX:
+			doc, err = extractXLSX(zr)
+		case FormatPPTX:
+			doc, err = extractPPTX(zr)
+		case FormatODT:
+			doc, err = extractODT(zr)
+		case FormatEPUB:
+			doc, err = extractEPUB(zr)
+		}
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc.Format = format
+	for i := range doc.Sections {
+		doc.Sections[i].Index = i + 1
+		doc.Sections[i].Text = tidyText(doc.Sections[i].Text)
+	}
+	return doc, nil
+}
+
+func readLimited(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && info.Size() > maxFileSize {
+		return nil, fmt.Errorf("document too large: %d bytes (max %d)", info.Size(), maxFileSize)
+	}
+	return io.ReadAll(io.LimitReader(f, maxFileSize))
+}
+
+// sniffFormat recognizes PDFs and the zip-based formats by content.
+func sniffFormat(data []byte) Format {
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		return FormatPDF
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ""
+	}
+	if mt, err := readZipFile(zr, "mimetype"); err == nil {
+		switch strings.TrimSpace(string(mt)) {
+		case "application/epub+zip":
+			return FormatEPUB
+		case "application/vnd.oasis.opendocument.text":
+			return FormatODT
+		}
+	}
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "word/document.xml":
+			return FormatDOCX
+		case f.Name == "xl/workbook.xml":
+			return FormatXLSX
+		case f.Name == "ppt/presentation.xml":
+			return FormatPPTX
+		}
+	}
+	return ""
+}
+
+// KindLabel returns the plural noun for n sections of kind, e.g. "3 pages".
+func KindLabel(kind string, n int) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", kind)
+	}
+	return fmt.Sprintf("%d %ss", n, kind)
+}
+
+// Describe returns a one-line description such as "PDF, 12 pages".
+func (d *Document) Describe() string {
+	desc := strings.ToUpper(string(d.Format))
+	if len(d.Sections) > 0 {
+		desc += ", " + KindLabel(d.Sections[0].Kind, len(d.Sections))
+	}
+	if d.Title != "" {
+		desc += fmt.Sprintf(", title %q", d.Title)
+	}
+	return desc
+}
+
+// Summary returns the description followed by the start of the text, at most
+// maxChars characters of it.
+func (d *Document) Summary(maxChars int) string {
+	var text strings.Builder
+	for _, s := range d.Sections {
+		if s.Text == "" {
+			continue
+		}
+		if text.Len() > 0 {
+			text.WriteString("\n")
+		}
+		text.WriteString(s.Text)
+		if text.Len() >= maxChars {
+			break
+		}
+	}
+	preview, truncated := truncateRunes(text.String(), maxChars)
+	if preview == "" {
+		return d.Describe() + " (no extractable text)"
+	}
+	if truncated {
+		preview += "…"
+	}
+	return d.Describe() + "\n" + preview
+}
+
+// Render returns the text of the selected sections under a heading each.
+// sel selects by section index; name, when set, selects sheets or chapters
+// by title instead. The output is cut at maxChars (0 = unlimited).
+func (d *Document) Render(sel Range, name string, maxChars int) (text string, shown int, truncated bool) {
+	var b strings.Builder
+	for _, s := range d.Sections {
+		if name != "" {
+			if !strings.EqualFold(strings.TrimSpace(s.Title), strings.TrimSpace(name)) {
+				continue
+			}
+		} else if !sel.Contains(s.Index) {
+			continue
+		}
+		shown++
+		heading := fmt.Sprintf("--- %s %d", titleCase(s.Kind), s.Index)
+		if s.Title != "" {
+			heading += ": " + s.Title
+		}
+		b.WriteString(heading + " ---\n")
+		if s.Text == "" {
+			b.WriteString("(no extractable text)\n")
+		} else {
+			b.WriteString(s.Text)
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	text = strings.TrimRight(b.String(), "\n")
+	if maxChars > 0 {
+		text, truncated = truncateRunes(text, maxChars)
+	}
+	return text, shown, truncated
+}
+
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func truncateRunes(s string, maxChars int) (string, bool) {
+	if maxChars <= 0 {
+		return s, false
+	}
+	runes := []rune(s)
+	if len(runes) <= maxChars {
+		return s, false
+	}
+	return string(runes[:maxChars]), true
+}
+
+// tidyText trims trailing spaces and collapses runs of blank lines.
+func tidyText(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t ")
+		if line == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
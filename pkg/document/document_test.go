@@ -0,0 +1,356 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// buildPDF assembles a minimal PDF whose pages draw the given content
+// streams with a Helvetica font. Streams are Flate-compressed so the decoder
+// is exercised too.
+func buildPDF(t *testing.T, title string, pages ...string) []byte {
+	t.Helper()
+	var objs []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	objs = append(objs,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /Resources << /Font << /F1 3 0 R >> >> >>",
+			strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+	for i, content := range pages {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write([]byte(content))
+		zw.Close()
+		objs = append(objs,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R >>", 5+2*i),
+			fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.String()),
+		)
+	}
+	infoNum := len(objs) + 1
+	objs = append(objs, fmt.Sprintf("<< /Title (%s) >>", title))
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	for i, obj := range objs {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Root 1 0 R /Info %d 0 R /Size %d >>\n%%%%EOF\n", infoNum, len(objs)+1)
+	return b.Bytes()
+}
+
+func TestExtractPDF(t *testing.T) {
+	data := buildPDF(t, "Quarterly Report",
+		"BT /F1 12 Tf 72 720 Td (Hello) Tj ( world) Tj 0 -14 Td [(Second) -300 (line)] TJ ET",
+		"BT /F1 12 Tf 1 0 0 1 72 700 Tm (Page two) Tj ET",
+	)
+	doc, err := Extract(data, FormatPDF)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if doc.Title != "Quarterly Report" {
+		t.Errorf("Title = %q", doc.Title)
+	}
+	if len(doc.Sections) != 2 {
+		t.Fatalf("got %d sections, want 2", len(doc.Sections))
+	}
+	if got, want := doc.Sections[0].Text, "Hello world\nSecond line"; got != want {
+		t.Errorf("page 1 = %q, want %q", got, want)
+	}
+	if got := doc.Sections[1].Text; got != "Page two" {
+		t.Errorf("page 2 = %q", got)
+	}
+	if doc.Sections[1].Kind != KindPage || doc.Sections[1].Index != 2 {
+		t.Errorf("section 2 = %+v", doc.Sections[1])
+	}
+}
+
+func TestExtractPDFToUnicode(t *testing.T) {
+	cmap := "/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n" +
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange\n" +
+		"2 beginbfchar <0001> <0048> <0002> <0069> endbfchar\n" +
+		"1 beginbfrange <0010> <0012> <00E9> endbfrange\n" +
+		"endcmap CMapName currentdict /CMap defineresource pop end end"
+	content := "BT /F1 10 Tf 10 10 Td <00010002> Tj <0010001100120010> Tj ET"
+	pdf := "%PDF-1.7\n" +
+		"1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n" +
+		"2 0 obj << /Type /Pages /Kids [3 0 R] /Count 1 >> endobj\n" +
+		"3 0 obj << /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >> endobj\n" +
+		fmt.Sprintf("4 0 obj << /Length %d >> stream\n%s\nendstream endobj\n", len(content), content) +
+		"5 0 obj << /Type /Font /Subtype /Type0 /Encoding /Identity-H /ToUnicode 6 0 R >> endobj\n" +
+		fmt.Sprintf("6 0 obj << /Length %d >> stream\n%s\nendstream endobj\n", len(cmap), cmap) +
+		"trailer << /Root 1 0 R >>\n%%EOF\n"
+
+	doc, err := Extract([]byte(pdf), FormatPDF)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if got, want := doc.Sections[0].Text, "Hiéêëé"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+}
+
+func TestExtractPDFMalformed(t *testing.T) {
+	for _, data := range []string{"%PDF-1.4\n", "%PDF-1.4\n1 0 obj << /Type /Catalog /Pages 9 0 R >> endobj\n"} {
+		if _, err := Extract([]byte(data), FormatPDF); err == nil {
+			t.Errorf("Extract(%q) succeeded, want error", data)
+		}
+	}
+}
+
+func TestExtractDOCX(t *testing.T) {
+	const body = `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:tabs><w:tab w:val="left" w:pos="720"/></w:tabs></w:pPr><w:r><w:t>Intro</w:t><w:tab/><w:t xml:space="preserve">text</w:t></w:r></w:p>
+<w:tbl>
+<w:tr><w:tc><w:p><w:r><w:t>Name</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Qty</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>Apples</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>3</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>
+<w:p><w:r><w:br w:type="page"/></w:r></w:p>
+<w:p><w:r><w:lastRenderedPageBreak/><w:t>Second page</w:t></w:r></w:p>
+</w:body></w:document>`
+	data := buildZip(t, map[string]string{
+		"word/document.xml": body,
+		"docProps/core.xml": `<cp:coreProperties xmlns:cp="x" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Memo</dc:title></cp:coreProperties>`,
+	})
+	doc, err := Extract(data, FormatDOCX)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if doc.Title != "Memo" {
+		t.Errorf("Title = %q", doc.Title)
+	}
+	if len(doc.Sections) != 2 {
+		t.Fatalf("got %d sections, want 2: %+v", len(doc.Sections), doc.Sections)
+	}
+	want := "Intro\ttext\n| Name | Qty |\n| --- | --- |\n| Apples | 3 |"
+	if got := doc.Sections[0].Text; got != want {
+		t.Errorf("page 1 = %q, want %q", got, want)
+	}
+	if got := doc.Sections[1].Text; got != "Second page" {
+		t.Errorf("page 2 = %q", got)
+	}
+}
+
+func TestExtractXLSX(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>
+<sheet name="Sales" sheetId="1" r:id="rId1"/><sheet name="Empty" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships>
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst><si><t>Region</t></si><si><r><t>To</t></r><r><t>tal</t></r></si><si><t>North</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+<row r="1"><c r="B1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="2"><c r="B2" t="s"><v>2</v></c><c r="C2"><v>42.5</v></c><c r="D2" t="inlineStr"><is><t>note</t></is></c></row>
+<row r="3"><c r="C3" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData/></worksheet>`,
+	})
+	doc, err := Extract(data, FormatXLSX)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if len(doc.Sections) != 2 {
+		t.Fatalf("got %d sections, want 2", len(doc.Sections))
+	}
+	s := doc.Sections[0]
+	if s.Kind != KindSheet || s.Title != "Sales" {
+		t.Errorf("section = %+v", s)
+	}
+	want := "| Region | Total |  |\n| --- | --- | --- |\n| North | 42.5 | note |\n|  | TRUE |  |"
+	if s.Text != want {
+		t.Errorf("sheet text = %q, want %q", s.Text, want)
+	}
+	if doc.Sections[1].Text != "" {
+		t.Errorf("empty sheet text = %q", doc.Sections[1].Text)
+	}
+}
+
+func TestExtractPPTX(t *testing.T) {
+	slide := func(title, body string) string {
+		return `<p:sld xmlns:p="p" xmlns:a="a"><p:cSld><p:spTree>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="title"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>` + title + `</a:t></a:r></a:p></p:txBody></p:sp>
+<p:sp><p:txBody><a:p><a:r><a:t>` + body + `</a:t></a:r></a:p></p:txBody></p:sp>
+</p:spTree></p:cSld></p:sld>`
+	}
+	data := buildZip(t, map[string]string{
+		"ppt/presentation.xml": `<p:presentation xmlns:p="p" xmlns:r="r"><p:sldIdLst>
+<p:sldId id="257" r:id="rId3"/><p:sldId id="256" r:id="rId2"/></p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": `<Relationships>
+<Relationship Id="rId2" Target="slides/slide1.xml"/><Relationship Id="rId3" Target="slides/slide2.xml"/></Relationships>`,
+		"ppt/slides/slide1.xml": slide("Later", "second"),
+		"ppt/slides/slide2.xml": slide("Agenda", "first"),
+	})
+	doc, err := Extract(data, FormatPPTX)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if len(doc.Sections) != 2 {
+		t.Fatalf("got %d sections, want 2", len(doc.Sections))
+	}
+	if s := doc.Sections[0]; s.Title != "Agenda" || s.Text != "Agenda\nfirst" {
+		t.Errorf("slide 1 = %+v", s)
+	}
+	if s := doc.Sections[1]; s.Title != "Later" || s.Kind != KindSlide {
+		t.Errorf("slide 2 = %+v", s)
+	}
+}
+
+func TestExtractODT(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"mimetype": "application/vnd.oasis.opendocument.text",
+		"content.xml": `<office:document-content xmlns:office="o" xmlns:text="t"><office:body><office:text>
+<text:h>Heading</text:h><text:p>a<text:s text:c="3"/>b<text:span> c</text:span></text:p>
+<text:soft-page-break/><text:p>next</text:p></office:text></office:body></office:document-content>`,
+		"meta.xml": `<office:document-meta xmlns:office="o" xmlns:dc="dc"><office:meta><dc:title>Notes</dc:title></office:meta></office:document-meta>`,
+	})
+	if got := sniffFormat(data); got != FormatODT {
+		t.Errorf("sniffFormat = %q, want odt", got)
+	}
+	doc, err := Extract(data, FormatODT)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if doc.Title != "Notes" || len(doc.Sections) != 2 {
+		t.Fatalf("doc = %+v", doc)
+	}
+	if got, want := doc.Sections[0].Text, "Heading\na   b c"; got != want {
+		t.Errorf("page 1 = %q, want %q", got, want)
+	}
+}
+
+func TestExtractEPUB(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<package><metadata><dc:title xmlns:dc="dc">The Book</dc:title></metadata>
+<manifest><item id="cover" href="cover.xhtml"/><item id="c1" href="text/ch%201.xhtml"/><item id="c2" href="text/ch2.xhtml"/></manifest>
+<spine><itemref idref="cover"/><itemref idref="c1"/><itemref idref="c2"/></spine></package>`,
+		"OEBPS/cover.xhtml":       `<html><body><img src="cover.jpg"/></body></html>`,
+		"OEBPS/text/ch 1.xhtml":   `<html><head><title>x</title><style>p{}</style></head><body><h1>Chapter <em>One</em></h1><p>It began.<br/>Then &amp; more.</p></body></html>`,
+		"OEBPS/text/ch2.xhtml":    `<html><body><h2>Two</h2><table><tr><th>k</th><th>v</th></tr><tr><td>a</td><td><p>1</p></td></tr></table></body></html>`,
+		"OEBPS/text/unused.xhtml": `<html><body><p>not in spine</p></body></html>`,
+	})
+	doc, err := Extract(data, FormatEPUB)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if doc.Title != "The Book" || len(doc.Sections) != 2 {
+		t.Fatalf("doc = %+v", doc)
+	}
+	if s := doc.Sections[0]; s.Title != "Chapter One" || s.Text != "Chapter One\nIt began.\nThen & more." {
+		t.Errorf("chapter 1 = %+v", s)
+	}
+	if got, want := doc.Sections[1].Text, "Two\n| k | v |\n| --- | --- |\n| a | 1 |"; got != want {
+		t.Errorf("chapter 2 = %q, want %q", got, want)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name, contentType string
+		want              Format
+	}{
+		{"report.PDF", "", FormatPDF},
+		{"file", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", FormatXLSX},
+		{"book.epub", "application/octet-stream", FormatEPUB},
+		{"photo.jpg", "image/jpeg", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.name, tt.contentType); got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %q, want %q", tt.name, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestExtractFileSniffsContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.bin")
+	if err := os.WriteFile(path, buildPDF(t, "T", "BT /F1 12 Tf (x) Tj ET"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ExtractFile(path, "")
+	if err != nil {
+		t.Fatalf("ExtractFile: %v", err)
+	}
+	if doc.Format != FormatPDF {
+		t.Errorf("Format = %q", doc.Format)
+	}
+
+	if err := os.WriteFile(path, []byte("plain text"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExtractFile(path, ""); err != ErrUnsupported {
+		t.Errorf("err = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	r, err := ParseRange("1, 3-4, 9-")
+	if err != nil {
+		t.Fatalf("ParseRange: %v", err)
+	}
+	for i, want := range map[int]bool{1: true, 2: false, 3: true, 4: true, 5: false, 9: true, 100: true} {
+		if got := r.Contains(i); got != want {
+			t.Errorf("Contains(%d) = %v, want %v", i, got, want)
+		}
+	}
+	if all, _ := ParseRange(""); !all.All() {
+		t.Error("empty range should select all")
+	}
+	for _, bad := range []string{"0", "x", "5-2", "1-a"} {
+		if _, err := ParseRange(bad); err == nil {
+			t.Errorf("ParseRange(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestRenderAndSummary(t *testing.T) {
+	doc := &Document{Format: FormatXLSX, Sections: []Section{
+		{Kind: KindSheet, Index: 1, Title: "Q1", Text: "alpha"},
+		{Kind: KindSheet, Index: 2, Title: "Q2", Text: "beta"},
+		{Kind: KindSheet, Index: 3, Title: "Q3"},
+	}}
+	sel, _ := ParseRange("2-")
+	text, shown, truncated := doc.Render(sel, "", 0)
+	if shown != 2 || truncated {
+		t.Errorf("shown = %d, truncated = %v", shown, truncated)
+	}
+	if want := "--- Sheet 2: Q2 ---\nbeta\n\n--- Sheet 3: Q3 ---\n(no extractable text)"; text != want {
+		t.Errorf("Render = %q, want %q", text, want)
+	}
+	if text, shown, _ := doc.Render(Range{}, "q1", 0); shown != 1 || !strings.Contains(text, "alpha") {
+		t.Errorf("Render by name = %q (%d)", text, shown)
+	}
+	if got, want := doc.Summary(7), "XLSX, 3 sheets\nalpha\nb…"; got != want {
+		t.Errorf("Summary = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package document
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+)
+
+var htmlLayout = &textLayout{
+	paragraphs: set(
+		"p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "li", "dt", "dd",
+		"blockquote", "pre", "figcaption", "caption",
+	),
+	breaks: set("br"),
+	skip:   set("head", "script", "style", "nav"),
+	tables: set("table"),
+	rows:   set("tr"),
+	cells:  set("td", "th"),
+}
+
+func extractEPUB(zr *zip.Reader) (*Document, error) {
+	container, err := readZipFile(zr, "META-INF/container.xml")
+	if err != nil {
+		return nil, err
+	}
+	var opfPath string
+	dec := newXMLDecoder(container)
+	for opfPath == "" {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "rootfile" {
+			opfPath = attr(se, "full-path")
+		}
+	}
+	if opfPath == "" {
+		return nil, errors.New("epub: no package document in container.xml")
+	}
+	opf, err := readZipFile(zr, opfPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := path.Dir(opfPath)
+	manifest := make(map[string]string)
+	var spine []string
+	dec = newXMLDecoder(opf)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch se.Name.Local {
+		case "item":
+			href := attr(se, "href")
+			if unescaped, err := url.PathUnescape(href); err == nil {
+				href = unescaped
+			}
+			manifest[attr(se, "id")] = path.Join(dir, href)
+		case "itemref":
+			if attr(se, "linear") != "no" {
+				spine = append(spine, attr(se, "idref"))
+			}
+		}
+	}
+
+	doc := &Document{Title: firstElementText(opf, "title")}
+	for _, id := range spine {
+		href, ok := manifest[id]
+		if !ok {
+			continue
+		}
+		data, err := readZipFile(zr, href)
+		if err != nil {
+			continue
+		}
+		text := strings.Join(walkText(data, htmlLayout), "\n")
+		if strings.TrimSpace(text) == "" {
+			// Cover pages and other image-only spine items.
+			continue
+		}
+		doc.Sections = append(doc.Sections, Section{
+			Kind:  KindChapter,
+			Title: firstElementText(data, "h1", "h2", "h3"),
+			Text:  text,
+		})
+	}
+	return doc, nil
+}
@@ -0,0 +1,35 @@
+package document
+
+import (
+	"archive/zip"
+	"encoding/xml"
+)
+
+func extractODT(zr *zip.Reader) (*Document, error) {
+	data, err := readZipFile(zr, "content.xml")
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{}
+	if meta, err := readZipFile(zr, "meta.xml"); err == nil {
+		doc.Title = firstElementText(meta, "title")
+	}
+	for _, page := range walkText(data, odtLayout) {
+		doc.Sections = append(doc.Sections, Section{Kind: KindPage, Text: page})
+	}
+	return doc, nil
+}
+
+var odtLayout = &textLayout{
+	paragraphs: set("p", "h"),
+	tabs:       set("tab"),
+	breaks:     set("line-break"),
+	skip:       set("note-citation", "tracked-changes", "annotation"),
+	spaces:     "s",
+	tables:     set("table"),
+	rows:       set("table-row"),
+	cells:      set("table-cell"),
+	pageBreak: func(se xml.StartElement) bool {
+		return se.Name.Local == "soft-page-break"
+	},
+}
@@ -0,0 +1,354 @@
+package document
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func set(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
+
+var docxLayout = &textLayout{
+	paragraphs: set("p"),
+	text:       set("t"),
+	tabs:       set("tab"),
+	breaks:     set("br", "cr"),
+	// pPr holds tab stop definitions that look like <w:tab/> runs;
+	// instrText and delText are field codes and tracked deletions.
+	skip:   set("pPr", "instrText", "delText"),
+	tables: set("tbl"),
+	rows:   set("tr"),
+	cells:  set("tc"),
+	pageBreak: func(se xml.StartElement) bool {
+		switch se.Name.Local {
+		case "lastRenderedPageBreak":
+			return true
+		case "br":
+			return attr(se, "type") == "page"
+		}
+		return false
+	},
+}
+
+func extractDOCX(zr *zip.Reader) (*Document, error) {
+	data, err := readZipFile(zr, "word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{Title: coreTitle(zr)}
+	for _, page := range walkText(data, docxLayout) {
+		doc.Sections = append(doc.Sections, Section{Kind: KindPage, Text: page})
+	}
+	return doc, nil
+}
+
+var pptxLayout = &textLayout{
+	paragraphs: set("p"),
+	text:       set("t"),
+	breaks:     set("br"),
+	tables:     set("tbl"),
+	rows:       set("tr"),
+	cells:      set("tc"),
+}
+
+func extractPPTX(zr *zip.Reader) (*Document, error) {
+	const presentation = "ppt/presentation.xml"
+	data, err := readZipFile(zr, presentation)
+	if err != nil {
+		return nil, err
+	}
+	rels := relationships(zr, presentation)
+	var slides []string
+	dec := newXMLDecoder(data)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "sldId" {
+			if target, ok := rels[relID(se)]; ok {
+				slides = append(slides, target)
+			}
+		}
+	}
+
+	doc := &Document{Title: coreTitle(zr)}
+	for _, slide := range slides {
+		data, err := readZipFile(zr, slide)
+		if err != nil {
+			continue
+		}
+		doc.Sections = append(doc.Sections, Section{
+			Kind:  KindSlide,
+			Title: slideTitle(data),
+			Text:  strings.Join(walkText(data, pptxLayout), "\n"),
+		})
+	}
+	return doc, nil
+}
+
+// slideTitle returns the text of the slide's title placeholder.
+func slideTitle(data []byte) string {
+	dec := newXMLDecoder(data)
+	var (
+		title   strings.Builder
+		inShape bool
+		isTitle bool
+		inText  bool
+	)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "sp":
+				inShape, isTitle = true, false
+			case "ph":
+				typ := attr(t, "type")
+				isTitle = inShape && (typ == "title" || typ == "ctrTitle")
+			case "t":
+				inText = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "sp":
+				if isTitle && title.Len() > 0 {
+					return strings.Join(strings.Fields(title.String()), " ")
+				}
+				inShape = false
+			case "t":
+				inText = false
+			case "p":
+				if isTitle {
+					title.WriteString(" ")
+				}
+			}
+		case xml.CharData:
+			if inText && isTitle {
+				title.Write(t)
+			}
+		}
+	}
+	return ""
+}
+
+func extractXLSX(zr *zip.Reader) (*Document, error) {
+	const workbook = "xl/workbook.xml"
+	data, err := readZipFile(zr, workbook)
+	if err != nil {
+		return nil, err
+	}
+	rels := relationships(zr, workbook)
+	var strs []string
+	if ss, err := readZipFile(zr, "xl/sharedStrings.xml"); err == nil {
+		strs = sharedStrings(ss)
+	}
+
+	doc := &Document{Title: coreTitle(zr)}
+	dec := newXMLDecoder(data)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "sheet" {
+			continue
+		}
+		target, ok := rels[relID(se)]
+		if !ok {
+			continue
+		}
+		sheet, err := readZipFile(zr, target)
+		if err != nil {
+			continue
+		}
+		doc.Sections = append(doc.Sections, Section{
+			Kind:  KindSheet,
+			Title: attr(se, "name"),
+			Text:  sheetText(sheet, strs),
+		})
+	}
+	return doc, nil
+}
+
+// sharedStrings reads the workbook's string table. Rich-text entries are
+// concatenated runs.
+func sharedStrings(data []byte) []string {
+	var (
+		strs   []string
+		cur    strings.Builder
+		inText bool
+		inPhon bool
+	)
+	dec := newXMLDecoder(data)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				cur.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				inPhon = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				strs = append(strs, cur.String())
+			case "t":
+				inText = false
+			case "rPh":
+				inPhon = false
+			}
+		case xml.CharData:
+			if inText && !inPhon {
+				cur.Write(t)
+			}
+		}
+	}
+	return strs
+}
+
+// sheetText renders a worksheet as table rows. Cells are placed by their
+// reference so that gaps in sparse rows keep columns aligned.
+func sheetText(data []byte, strs []string) string {
+	type cell struct {
+		col  int
+		text string
+	}
+	var (
+		rows    [][]cell
+		row     []cell
+		typ     string
+		ref     string
+		val     strings.Builder
+		inValue bool
+		maxCol  int
+	)
+	dec := newXMLDecoder(data)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				row = nil
+			case "c":
+				typ, ref = attr(t, "t"), attr(t, "r")
+				val.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				text := cellValue(typ, val.String(), strs)
+				if text == "" {
+					continue
+				}
+				col := len(row)
+				if c, ok := columnIndex(ref); ok {
+					col = c
+				}
+				row = append(row, cell{col: col, text: text})
+				maxCol = max(maxCol, col+1)
+			case "row":
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				val.Write(t)
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	// Drop leading columns that are empty in every row.
+	minCol := maxCol
+	for _, r := range rows {
+		for _, c := range r {
+			minCol = min(minCol, c.col)
+		}
+	}
+	width := min(maxCol-minCol, 256)
+
+	var b strings.Builder
+	cells := make([]string, width)
+	for i, r := range rows {
+		sort.Slice(r, func(x, y int) bool { return r[x].col < r[y].col })
+		for j := range cells {
+			cells[j] = ""
+		}
+		for _, c := range r {
+			if idx := c.col - minCol; idx < width {
+				cells[idx] = c.text
+			}
+		}
+		b.WriteString(tableRow(cells))
+		if i == 0 {
+			b.WriteString(tableSeparator(width))
+		}
+	}
+	return b.String()
+}
+
+func cellValue(typ, raw string, strs []string) string {
+	switch typ {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || i < 0 || i >= len(strs) {
+			return ""
+		}
+		return strs[i]
+	case "b":
+		if strings.TrimSpace(raw) == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	return strings.TrimSpace(raw)
+}
+
+// columnIndex converts the column letters of a cell reference like "AB12"
+// to a 0-based index.
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 || n > 3 {
+		return 0, false
+	}
+	return col - 1, true
+}
@@ -0,0 +1,632 @@
+package document
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxPDFPages bounds how many pages are interpreted.
+const maxPDFPages = 2000
+
+func extractPDF(data []byte) (doc *Document, err error) {
+	// The parser indexes into untrusted bytes throughout; a malformed file
+	// must fail the extraction, not the process.
+	defer func() {
+		if r := recover(); r != nil {
+			doc, err = nil, fmt.Errorf("pdf: malformed document: %v", r)
+		}
+	}()
+
+	f, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+	doc = &Document{}
+	if info := f.dict(f.trailer["Info"]); info != nil {
+		if title, ok := f.resolve(info["Title"]).(pdfString); ok {
+			doc.Title = strings.TrimSpace(decodeTextString(title))
+		}
+	}
+	for _, page := range f.pages() {
+		doc.Sections = append(doc.Sections, Section{Kind: KindPage, Text: f.pageText(page)})
+	}
+	if len(doc.Sections) == 0 {
+		return nil, fmt.Errorf("pdf: no pages found")
+	}
+	return doc, nil
+}
+
+// pdfPage is a page dictionary with its inherited resources.
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+func (f *pdfFile) pages() []pdfPage {
+	root := f.dict(f.trailer["Root"])
+	if root == nil {
+		// No usable trailer: fall back to any catalog in the file.
+		for _, obj := range f.objects {
+			if d, ok := obj.(pdfDict); ok && d["Type"] == pdfName("Catalog") {
+				root = d
+				break
+			}
+		}
+	}
+	if root == nil {
+		return nil
+	}
+	var pages []pdfPage
+	visited := make(map[pdfRef]bool)
+	var walk func(node any, resources pdfDict, depth int)
+	walk = func(node any, resources pdfDict, depth int) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref] {
+				return
+			}
+			visited[ref] = true
+		}
+		d := f.dict(node)
+		if d == nil || depth > 64 || len(pages) >= maxPDFPages {
+			return
+		}
+		if r := f.dict(d["Resources"]); r != nil {
+			resources = r
+		}
+		kids, isTree := f.resolve(d["Kids"]).(pdfArray)
+		if !isTree {
+			pages = append(pages, pdfPage{dict: d, resources: resources})
+			return
+		}
+		for _, kid := range kids {
+			walk(kid, resources, depth+1)
+		}
+	}
+	walk(root["Pages"], nil, 0)
+	return pages
+}
+
+func (f *pdfFile) pageText(p pdfPage) string {
+	var content []byte
+	switch c := f.resolve(p.dict["Contents"]).(type) {
+	case *pdfStream:
+		content, _ = f.decodeStream(c)
+	case pdfArray:
+		for _, part := range c {
+			if s := f.stream(part); s != nil {
+				if data, err := f.decodeStream(s); err == nil {
+					content = append(content, data...)
+					content = append(content, '\n')
+				}
+			}
+		}
+	}
+	t := &pdfText{file: f}
+	t.run(content, p.resources, 0)
+	return t.out.String()
+}
+
+// pdfText interprets content stream text operators. It does not lay the
+// page out; it only tracks enough of the text position to tell line breaks
+// and word gaps from glyph kerning.
+type pdfText struct {
+	file *pdfFile
+	out  strings.Builder
+
+	font     *pdfFont
+	fontSize float64
+	leading  float64
+	// Origin and scale of the text line matrix, the advance since the
+	// line start in text space, and where the last text ended in user space.
+	lineX, lineY   float64
+	scaleX, scaleY float64
+	advance        float64
+	endX, endY     float64
+}
+
+const maxFormDepth = 8
+
+func (t *pdfText) run(content []byte, resources pdfDict, depth int) {
+	lx := &pdfLexer{data: content}
+	var operands []any
+	for {
+		v, err := lx.parseObject()
+		if err != nil {
+			return
+		}
+		op, ok := v.(pdfOp)
+		if !ok {
+			operands = append(operands, v)
+			if len(operands) > 64 {
+				operands = operands[1:]
+			}
+			continue
+		}
+		t.apply(string(op), operands, resources, depth)
+		if op == "ID" {
+			skipInlineImage(lx)
+		}
+		operands = operands[:0]
+	}
+}
+
+// skipInlineImage moves past inline image data, which is binary and ends
+// at the first "EI" delimited by whitespace.
+func skipInlineImage(lx *pdfLexer) {
+	for i := lx.pos + 1; i+1 < len(lx.data); i++ {
+		if lx.data[i] == 'E' && lx.data[i+1] == 'I' && isPDFSpace(lx.data[i-1]) &&
+			(i+2 == len(lx.data) || isPDFSpace(lx.data[i+2])) {
+			lx.pos = i + 2
+			return
+		}
+	}
+	lx.pos = len(lx.data)
+}
+
+func number(operands []any, i int) float64 {
+	if i < 0 || i >= len(operands) {
+		return 0
+	}
+	n, _ := operands[i].(float64)
+	return n
+}
+
+func (t *pdfText) apply(op string, operands []any, resources pdfDict, depth int) {
+	last := len(operands) - 1
+	switch op {
+	case "BT":
+		// The text matrix restarts at identity; endX/endY keep the position
+		// of the previous text object for comparison.
+		t.lineX, t.lineY, t.scaleX, t.scaleY, t.advance = 0, 0, 1, 1, 0
+	case "Tf":
+		if last >= 1 {
+			name, _ := operands[last-1].(pdfName)
+			t.font = t.file.font(resources, name)
+			t.fontSize = number(operands, last)
+		}
+	case "TL":
+		t.leading = number(operands, last)
+	case "Td", "TD":
+		tx, ty := number(operands, last-1), number(operands, last)
+		if op == "TD" {
+			t.leading = -ty
+		}
+		t.moveTo(t.lineX+tx*t.scaleX, t.lineY+ty*t.scaleY, t.scaleX, t.scaleY)
+	case "Tm":
+		if last >= 5 {
+			t.moveTo(number(operands, last-1), number(operands, last),
+				math.Abs(number(operands, last-5)), math.Abs(number(operands, last-2)))
+		}
+	case "T*":
+		t.nextLine()
+	case "'", "\"":
+		t.nextLine()
+		if last >= 0 {
+			t.show(operands[last])
+		}
+	case "Tj":
+		if last >= 0 {
+			t.show(operands[last])
+		}
+	case "TJ":
+		if last < 0 {
+			return
+		}
+		arr, _ := operands[last].(pdfArray)
+		for _, item := range arr {
+			switch v := item.(type) {
+			case pdfString:
+				t.show(v)
+			case float64:
+				// Offsets are in thousandths of the font size; a large
+				// negative one is how many producers encode a word gap.
+				t.advance -= v / 1000 * t.fontSize
+				t.endX = t.lineX + t.advance*t.scaleX
+				if v < -200 {
+					t.space()
+				}
+			}
+		}
+	case "Do":
+		if last >= 0 && depth < maxFormDepth {
+			name, _ := operands[last].(pdfName)
+			t.form(resources, name, depth)
+		}
+	}
+}
+
+// moveTo starts a new text line at (x, y) in user space, emitting a line
+// break or a space if that is how far it is from where the last text ended.
+func (t *pdfText) moveTo(x, y, scaleX, scaleY float64) {
+	if scaleX == 0 {
+		scaleX = 1
+	}
+	if scaleY == 0 {
+		scaleY = 1
+	}
+	size := t.size()
+	switch {
+	case math.Abs(y-t.endY) > size*scaleY/2:
+		t.newline()
+	case x-t.endX > size*scaleX/5:
+		t.space()
+	}
+	t.lineX, t.lineY, t.scaleX, t.scaleY = x, y, scaleX, scaleY
+	t.advance = 0
+	t.endX, t.endY = x, y
+}
+
+func (t *pdfText) nextLine() {
+	t.newline()
+	t.lineY -= t.leading * t.scaleY
+	t.advance = 0
+	t.endX, t.endY = t.lineX, t.lineY
+}
+
+func (t *pdfText) size() float64 {
+	if t.fontSize <= 0 {
+		return 1
+	}
+	return t.fontSize
+}
+
+func (t *pdfText) show(v any) {
+	s, ok := v.(pdfString)
+	if !ok {
+		return
+	}
+	text, width := t.font.decode([]byte(s))
+	t.out.WriteString(text)
+	t.advance += width / 1000 * t.fontSize
+	t.endX = t.lineX + t.advance*t.scaleX
+}
+
+func (t *pdfText) newline() {
+	if t.out.Len() == 0 {
+		return
+	}
+	if s := t.out.String(); s[len(s)-1] != '\n' {
+		t.out.WriteByte('\n')
+	}
+}
+
+func (t *pdfText) space() {
+	if t.out.Len() == 0 {
+		return
+	}
+	s := t.out.String()
+	if r, _ := utf8.DecodeLastRuneInString(s); r != ' ' && r != '\n' {
+		t.out.WriteByte(' ')
+	}
+}
+
+// form runs the content of a form XObject, which may hold text of its own.
+func (t *pdfText) form(resources pdfDict, name pdfName, depth int) {
+	s := t.file.stream(t.file.dict(resources["XObject"])[name])
+	if s == nil || s.dict["Subtype"] != pdfName("Form") {
+		return
+	}
+	data, err := t.file.decodeStream(s)
+	if err != nil {
+		return
+	}
+	if r := t.file.dict(s.dict["Resources"]); r != nil {
+		resources = r
+	}
+	t.run(data, resources, depth+1)
+}
+
+// pdfFont maps the character codes of a font to text and glyph widths.
+type pdfFont struct {
+	codeLen   int // bytes per code: 1 for simple fonts, usually 2 for Type0
+	toUnicode map[uint32]string
+	encoding  *[256]rune
+	widths    map[uint32]float64
+	missing   float64
+}
+
+func (f *pdfFile) font(resources pdfDict, name pdfName) *pdfFont {
+	ref := f.dict(resources["Font"])[name]
+	if ref == nil {
+		return nil
+	}
+	key, isRef := ref.(pdfRef)
+	if !isRef {
+		return f.loadFont(f.dict(ref))
+	}
+	if font, ok := f.fonts[key]; ok {
+		return font
+	}
+	font := f.loadFont(f.dict(ref))
+	f.fonts[key] = font
+	return font
+}
+
+func (f *pdfFile) loadFont(d pdfDict) *pdfFont {
+	font := &pdfFont{codeLen: 1, missing: 500}
+	if d == nil {
+		return font
+	}
+	if s := f.stream(d["ToUnicode"]); s != nil {
+		if data, err := f.decodeStream(s); err == nil {
+			font.toUnicode, font.codeLen = parseCMap(data)
+		}
+	}
+
+	if d["Subtype"] == pdfName("Type0") {
+		font.codeLen = 2
+		font.missing = 1000
+		if desc, ok := f.resolve(d["DescendantFonts"]).(pdfArray); ok && len(desc) > 0 {
+			cid := f.dict(desc[0])
+			if dw, ok := f.resolve(cid["DW"]).(float64); ok {
+				font.missing = dw
+			}
+			font.widths = f.cidWidths(cid["W"])
+		}
+		return font
+	}
+
+	font.encoding = f.simpleEncoding(d["Encoding"])
+	if widths, ok := f.resolve(d["Widths"]).(pdfArray); ok {
+		first, _ := f.resolve(d["FirstChar"]).(float64)
+		font.widths = make(map[uint32]float64, len(widths))
+		for i, w := range widths {
+			if n, ok := f.resolve(w).(float64); ok {
+				font.widths[uint32(int(first)+i)] = n
+			}
+		}
+	}
+	if desc := f.dict(d["FontDescriptor"]); desc != nil {
+		if mw, ok := f.resolve(desc["MissingWidth"]).(float64); ok && mw > 0 {
+			font.missing = mw
+		}
+	}
+	return font
+}
+
+// cidWidths reads a CIDFont /W array: "c [w1 w2 ...]" and "cfirst clast w".
+func (f *pdfFile) cidWidths(v any) map[uint32]float64 {
+	arr, ok := f.resolve(v).(pdfArray)
+	if !ok {
+		return nil
+	}
+	widths := make(map[uint32]float64)
+	for i := 0; i < len(arr); {
+		first, ok := f.resolve(arr[i]).(float64)
+		if !ok || i+1 >= len(arr) {
+			break
+		}
+		if list, ok := f.resolve(arr[i+1]).(pdfArray); ok {
+			for j, w := range list {
+				if n, ok := f.resolve(w).(float64); ok {
+					widths[uint32(first)+uint32(j)] = n
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(arr) {
+			break
+		}
+		last, _ := f.resolve(arr[i+1]).(float64)
+		w, _ := f.resolve(arr[i+2]).(float64)
+		for c := first; c <= last && c-first < 65536; c++ {
+			widths[uint32(c)] = w
+		}
+		i += 3
+	}
+	return widths
+}
+
+// decode returns the text for a shown string and its width in thousandths
+// of the font size. A nil font decodes bytes as WinAnsi.
+func (font *pdfFont) decode(b []byte) (string, float64) {
+	if font == nil {
+		font = &pdfFont{codeLen: 1, missing: 500}
+	}
+	var (
+		sb    strings.Builder
+		width float64
+	)
+	step := max(font.codeLen, 1)
+	for i := 0; i+step <= len(b); i += step {
+		var code uint32
+		for _, c := range b[i : i+step] {
+			code = code<<8 | uint32(c)
+		}
+		if w, ok := font.widths[code]; ok {
+			width += w
+		} else {
+			width += font.missing
+		}
+		if s, ok := font.toUnicode[code]; ok {
+			sb.WriteString(s)
+			continue
+		}
+		if step == 1 {
+			enc := font.encoding
+			if enc == nil {
+				enc = &winAnsiEncoding
+			}
+			if r := enc[code]; r != 0 {
+				sb.WriteRune(r)
+			}
+		}
+		// Two-byte codes without a ToUnicode map are glyph IDs; there is no
+		// text to recover from them.
+	}
+	return sb.String(), width
+}
+
+// parseCMap reads the bfchar and bfrange mappings of a ToUnicode CMap.
+func parseCMap(data []byte) (map[uint32]string, int) {
+	m := make(map[uint32]string)
+	codeLen := 0
+	lx := &pdfLexer{data: data}
+	var operands []any
+	for {
+		v, err := lx.parseObject()
+		if err != nil {
+			break
+		}
+		op, ok := v.(pdfOp)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+		switch op {
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if s, ok := operands[0].(pdfString); ok && len(s) > 0 {
+					codeLen = len(s)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					m[codeValue(src)] = decodeUTF16BE([]byte(dst))
+				}
+				if codeLen == 0 {
+					codeLen = len(src)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 {
+					continue
+				}
+				if codeLen == 0 {
+					codeLen = len(lo)
+				}
+				addBFRange(m, codeValue(lo), codeValue(hi), operands[i+2])
+			}
+		}
+		operands = operands[:0]
+	}
+	if codeLen == 0 || codeLen > 4 {
+		codeLen = 1
+	}
+	return m, codeLen
+}
+
+func addBFRange(m map[uint32]string, lo, hi uint32, dst any) {
+	if hi < lo || hi-lo > 0xFFFF {
+		return
+	}
+	switch d := dst.(type) {
+	case pdfString:
+		units := []byte(d)
+		if len(units) < 2 {
+			return
+		}
+		for c := lo; c <= hi; c++ {
+			out := append([]byte(nil), units...)
+			n := len(out)
+			last := uint32(out[n-2])<<8 | uint32(out[n-1])
+			last += c - lo
+			out[n-2], out[n-1] = byte(last>>8), byte(last)
+			m[c] = decodeUTF16BE(out)
+		}
+	case pdfArray:
+		for i, item := range d {
+			if s, ok := item.(pdfString); ok && lo+uint32(i) <= hi {
+				m[lo+uint32(i)] = decodeUTF16BE([]byte(s))
+			}
+		}
+	}
+}
+
+func codeValue(s pdfString) uint32 {
+	var v uint32
+	for i := 0; i < len(s) && i < 4; i++ {
+		v = v<<8 | uint32(s[i])
+	}
+	return v
+}
+
+// simpleEncoding returns the byte-to-rune table of a simple font: WinAnsi
+// (the common case) adjusted by any /Differences.
+func (f *pdfFile) simpleEncoding(v any) *[256]rune {
+	enc := winAnsiEncoding
+	d := f.dict(v)
+	if d == nil {
+		return &enc
+	}
+	diffs, _ := f.resolve(d["Differences"]).(pdfArray)
+	code := 0
+	for _, item := range diffs {
+		switch x := f.resolve(item).(type) {
+		case float64:
+			code = int(x)
+		case pdfName:
+			if code >= 0 && code < 256 {
+				if r := glyphRune(string(x)); r != 0 {
+					enc[code] = r
+				}
+			}
+			code++
+		}
+	}
+	return &enc
+}
+
+var winAnsiEncoding = func() [256]rune {
+	var t [256]rune
+	for i := 32; i < 256; i++ {
+		t[i] = rune(i)
+	}
+	t['\t'], t['\n'], t['\r'] = '\t', '\n', '\r'
+	high := []rune{
+		'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+		0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+	}
+	copy(t[0x80:], high)
+	t[0x7F] = 0
+	return t
+}()
+
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$',
+	"percent": '%', "ampersand": '&', "quotesingle": '\'', "parenleft": '(',
+	"parenright": ')', "asterisk": '*', "plus": '+', "comma": ',', "hyphen": '-',
+	"period": '.', "slash": '/', "zero": '0', "one": '1', "two": '2', "three": '3',
+	"four": '4', "five": '5', "six": '6', "seven": '7', "eight": '8', "nine": '9',
+	"colon": ':', "semicolon": ';', "less": '<', "equal": '=', "greater": '>',
+	"question": '?', "at": '@', "bracketleft": '[', "backslash": '\\',
+	"bracketright": ']', "underscore": '_', "braceleft": '{', "bar": '|',
+	"braceright": '}', "quoteleft": '‘', "quoteright": '’', "quotedblleft": '“',
+	"quotedblright": '”', "bullet": '•', "endash": '–', "emdash": '—',
+	"ellipsis": '…', "fi": 'ﬁ', "fl": 'ﬂ', "ff": 'ﬀ', "ffi": 'ﬃ', "ffl": 'ﬄ',
+	"minus": '−', "degree": '°', "copyright": '©', "registered": '®',
+	"trademark": '™', "section": '§', "paragraph": '¶', "dagger": '†',
+	"daggerdbl": '‡', "Euro": '€', "sterling": '£', "yen": '¥', "cent": '¢',
+}
+
+// glyphRune maps a glyph name to its character: the common named glyphs,
+// single letters, and the uniXXXX / uXXXX conventions.
+func glyphRune(name string) rune {
+	if r, ok := glyphNames[name]; ok {
+		return r
+	}
+	if len(name) == 1 {
+		return rune(name[0])
+	}
+	for _, prefix := range []string{"uni", "u"} {
+		if hexPart, ok := strings.CutPrefix(name, prefix); ok && len(hexPart) >= 4 && len(hexPart) <= 6 {
+			if v, err := strconv.ParseUint(hexPart[:4], 16, 32); err == nil && prefix == "uni" {
+				return rune(v)
+			}
+			if v, err := strconv.ParseUint(hexPart, 16, 32); err == nil {
+				return rune(v)
+			}
+		}
+	}
+	return 0
+}
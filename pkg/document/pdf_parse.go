@@ -0,0 +1,556 @@
+package document
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"unicode/utf16"
+)
+
+// PDF object model. Numbers are float64, names pdfName, strings pdfString.
+type (
+	pdfName   string
+	pdfString string
+	pdfArray  []any
+	pdfDict   map[pdfName]any
+	pdfRef    struct{ num, gen int }
+	pdfOp     string // a bare keyword: a content stream operator or obj/endobj
+)
+
+type pdfStream struct {
+	dict pdfDict
+	data []byte // raw, still encoded
+}
+
+// pdfFile indexes the objects of a PDF. Rather than trusting the cross
+// reference table, which is often broken in files passed around by chat
+// apps, it scans the body for "N G obj" headers; for objects defined more
+// than once the last definition wins, as with incremental updates.
+type pdfFile struct {
+	objects map[int]any
+	trailer pdfDict
+	fonts   map[pdfRef]*pdfFont
+}
+
+var (
+	objHeaderRe = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	trailerRe   = regexp.MustCompile(`trailer\s*<<`)
+)
+
+func parsePDF(data []byte) (*pdfFile, error) {
+	f := &pdfFile{objects: make(map[int]any), trailer: pdfDict{}, fonts: make(map[pdfRef]*pdfFont)}
+
+	var objStreams []*pdfStream
+	for _, m := range objHeaderRe.FindAllSubmatchIndex(data, -1) {
+		num, err := strconv.Atoi(string(data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		lx := &pdfLexer{data: data, pos: m[1]}
+		obj, err := lx.parseObject()
+		if err != nil {
+			continue
+		}
+		if d, ok := obj.(pdfDict); ok {
+			if s := lx.readStream(d); s != nil {
+				obj = s
+				switch s.dict["Type"] {
+				case pdfName("ObjStm"):
+					objStreams = append(objStreams, s)
+				case pdfName("XRef"):
+					f.mergeTrailer(s.dict)
+				}
+			}
+		}
+		f.objects[num] = obj
+	}
+	for _, s := range objStreams {
+		f.loadObjectStream(s)
+	}
+	for _, m := range trailerRe.FindAllIndex(data, -1) {
+		lx := &pdfLexer{data: data, pos: m[0] + len("trailer")}
+		if d, ok := lx.mustObject().(pdfDict); ok {
+			f.mergeTrailer(d)
+		}
+	}
+	if len(f.objects) == 0 {
+		return nil, errors.New("pdf: no objects found")
+	}
+	if _, ok := f.trailer["Encrypt"]; ok {
+		return nil, errors.New("pdf: encrypted documents are not supported")
+	}
+	return f, nil
+}
+
+func (f *pdfFile) mergeTrailer(d pdfDict) {
+	for _, key := range []pdfName{"Root", "Info", "Encrypt"} {
+		if v, ok := d[key]; ok {
+			f.trailer[key] = v
+		}
+	}
+}
+
+// loadObjectStream adds the objects compressed into an object stream. Objects
+// already defined in the file body are kept, since an update that rewrites an
+// object normally writes it uncompressed.
+func (f *pdfFile) loadObjectStream(s *pdfStream) {
+	data, err := f.decodeStream(s)
+	if err != nil {
+		return
+	}
+	n, _ := f.resolve(s.dict["N"]).(float64)
+	first, _ := f.resolve(s.dict["First"]).(float64)
+	if n <= 0 || first <= 0 || int(first) > len(data) {
+		return
+	}
+	header := &pdfLexer{data: data[:int(first)]}
+	for i := 0; i < int(n); i++ {
+		num, ok1 := header.mustObject().(float64)
+		off, ok2 := header.mustObject().(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+		pos := int(first) + int(off)
+		if pos < 0 || pos >= len(data) {
+			continue
+		}
+		if _, exists := f.objects[int(num)]; exists {
+			continue
+		}
+		lx := &pdfLexer{data: data, pos: pos}
+		if obj, err := lx.parseObject(); err == nil {
+			f.objects[int(num)] = obj
+		}
+	}
+}
+
+// resolve follows indirect references.
+func (f *pdfFile) resolve(v any) any {
+	for range 32 {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = f.objects[ref.num]
+	}
+	return nil
+}
+
+func (f *pdfFile) dict(v any) pdfDict {
+	switch d := f.resolve(v).(type) {
+	case pdfDict:
+		return d
+	case *pdfStream:
+		return d.dict
+	}
+	return nil
+}
+
+func (f *pdfFile) stream(v any) *pdfStream {
+	s, _ := f.resolve(v).(*pdfStream)
+	return s
+}
+
+// decodeStream applies the stream's filters. Image filters are not
+// supported; they never carry text.
+func (f *pdfFile) decodeStream(s *pdfStream) ([]byte, error) {
+	var filters []any
+	switch v := f.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []any{v}
+	case pdfArray:
+		filters = v
+	}
+	data := s.data
+	for _, filter := range filters {
+		name, _ := f.resolve(filter).(pdfName)
+		var err error
+		switch name {
+		case "FlateDecode", "Fl":
+			data, err = inflate(data)
+		case "ASCIIHexDecode", "AHx":
+			data, err = decodeASCIIHex(data)
+		case "ASCII85Decode", "A85":
+			data, err = decodeASCII85(data)
+		default:
+			return nil, fmt.Errorf("pdf: unsupported filter %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func inflate(data []byte) ([]byte, error) {
+	var r io.Reader
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err == nil {
+		defer zr.Close()
+		r = zr
+	} else {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	out, err := io.ReadAll(io.LimitReader(r, maxPartSize))
+	// Truncated streams are common; keep what could be inflated.
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("pdf: inflate: %w", err)
+	}
+	return out, nil
+}
+
+func decodeASCIIHex(data []byte) ([]byte, error) {
+	if i := bytes.IndexByte(data, '>'); i >= 0 {
+		data = data[:i]
+	}
+	clean := make([]byte, 0, len(data)+1)
+	for _, c := range data {
+		if !isPDFSpace(c) {
+			clean = append(clean, c)
+		}
+	}
+	if len(clean)%2 == 1 {
+		clean = append(clean, '0')
+	}
+	out := make([]byte, len(clean)/2)
+	_, err := hex.Decode(out, clean)
+	return out, err
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	out := make([]byte, len(data)*4/5+4)
+	n, _, err := ascii85.Decode(out, data, true)
+	return out[:n], err
+}
+
+// decodeTextString decodes a PDF text string (UTF-16BE with a byte order
+// mark, or PDFDocEncoding, which is close enough to Latin-1 for titles).
+func decodeTextString(s pdfString) string {
+	b := []byte(s)
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		return decodeUTF16BE(b[2:])
+	}
+	if len(b) >= 3 && b[0] == 0xEF && b[1] == 0xBB && b[2] == 0xBF {
+		return string(b[3:])
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func decodeUTF16BE(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+// pdfLexer tokenizes PDF object syntax and content streams.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+var errPDFEOF = errors.New("pdf: unexpected end of data")
+
+func isPDFSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isPDFDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (lx *pdfLexer) skipSpace() {
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		switch {
+		case isPDFSpace(c):
+			lx.pos++
+		case c == '%':
+			for lx.pos < len(lx.data) && lx.data[lx.pos] != '\n' && lx.data[lx.pos] != '\r' {
+				lx.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// mustObject parses the next object, returning nil on any error.
+func (lx *pdfLexer) mustObject() any {
+	v, err := lx.parseObject()
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// parseObject parses the next object. Bare keywords come back as pdfOp and
+// the closing delimiters "]" and ">>" as pdfOp too, so that callers parsing
+// containers can detect the end.
+func (lx *pdfLexer) parseObject() (any, error) {
+	lx.skipSpace()
+	if lx.pos >= len(lx.data) {
+		return nil, errPDFEOF
+	}
+	c := lx.data[lx.pos]
+	switch {
+	case c == '/':
+		return lx.readName(), nil
+	case c == '(':
+		return lx.readLiteralString(), nil
+	case c == '<' && lx.pos+1 < len(lx.data) && lx.data[lx.pos+1] == '<':
+		lx.pos += 2
+		return lx.readDict()
+	case c == '<':
+		return lx.readHexString(), nil
+	case c == '>' && lx.pos+1 < len(lx.data) && lx.data[lx.pos+1] == '>':
+		lx.pos += 2
+		return pdfOp(">>"), nil
+	case c == '[':
+		lx.pos++
+		return lx.readArray()
+	case c == ']':
+		lx.pos++
+		return pdfOp("]"), nil
+	case c == '{' || c == '}' || c == ')' || c == '>':
+		lx.pos++
+		return pdfOp(string(c)), nil
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return lx.readNumberOrRef(), nil
+	}
+	start := lx.pos
+	for lx.pos < len(lx.data) && !isPDFSpace(lx.data[lx.pos]) && !isPDFDelim(lx.data[lx.pos]) {
+		lx.pos++
+	}
+	switch kw := string(lx.data[start:lx.pos]); kw {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return pdfOp(kw), nil
+	}
+}
+
+func (lx *pdfLexer) readNumber() (float64, bool) {
+	start := lx.pos
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		if c != '+' && c != '-' && c != '.' && (c < '0' || c > '9') {
+			break
+		}
+		lx.pos++
+	}
+	n, err := strconv.ParseFloat(string(lx.data[start:lx.pos]), 64)
+	return n, err == nil
+}
+
+// readNumberOrRef reads a number, or an indirect reference "num gen R".
+func (lx *pdfLexer) readNumberOrRef() any {
+	n, ok := lx.readNumber()
+	if !ok {
+		return float64(0)
+	}
+	save := lx.pos
+	lx.skipSpace()
+	if lx.pos < len(lx.data) && lx.data[lx.pos] >= '0' && lx.data[lx.pos] <= '9' {
+		gen, ok := lx.readNumber()
+		lx.skipSpace()
+		if ok && lx.pos < len(lx.data) && lx.data[lx.pos] == 'R' &&
+			(lx.pos+1 == len(lx.data) || isPDFSpace(lx.data[lx.pos+1]) || isPDFDelim(lx.data[lx.pos+1])) {
+			lx.pos++
+			return pdfRef{num: int(n), gen: int(gen)}
+		}
+	}
+	lx.pos = save
+	return n
+}
+
+func (lx *pdfLexer) readName() pdfName {
+	lx.pos++ // '/'
+	var b []byte
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		if isPDFSpace(c) || isPDFDelim(c) {
+			break
+		}
+		if c == '#' && lx.pos+2 < len(lx.data) {
+			if v, err := strconv.ParseUint(string(lx.data[lx.pos+1:lx.pos+3]), 16, 8); err == nil {
+				b = append(b, byte(v))
+				lx.pos += 3
+				continue
+			}
+		}
+		b = append(b, c)
+		lx.pos++
+	}
+	return pdfName(b)
+}
+
+func (lx *pdfLexer) readLiteralString() pdfString {
+	lx.pos++ // '('
+	var b []byte
+	depth := 1
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		lx.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return pdfString(b)
+			}
+		case '\\':
+			if lx.pos >= len(lx.data) {
+				return pdfString(b)
+			}
+			e := lx.data[lx.pos]
+			lx.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if lx.pos < len(lx.data) && lx.data[lx.pos] == '\n' {
+					lx.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && lx.pos < len(lx.data); i++ {
+						d := lx.data[lx.pos]
+						if d < '0' || d > '7' {
+							break
+						}
+						v = v*8 + int(d-'0')
+						lx.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return pdfString(b)
+}
+
+func (lx *pdfLexer) readHexString() pdfString {
+	lx.pos++ // '<'
+	end := bytes.IndexByte(lx.data[lx.pos:], '>')
+	if end < 0 {
+		end = len(lx.data) - lx.pos
+	}
+	raw := lx.data[lx.pos : lx.pos+end]
+	lx.pos += end + 1
+	b, _ := decodeASCIIHex(raw)
+	return pdfString(b)
+}
+
+func (lx *pdfLexer) readArray() (pdfArray, error) {
+	var arr pdfArray
+	for {
+		v, err := lx.parseObject()
+		if err != nil {
+			return arr, err
+		}
+		if op, ok := v.(pdfOp); ok && op == "]" {
+			return arr, nil
+		}
+		arr = append(arr, v)
+	}
+}
+
+func (lx *pdfLexer) readDict() (pdfDict, error) {
+	d := pdfDict{}
+	for {
+		k, err := lx.parseObject()
+		if err != nil {
+			return d, err
+		}
+		if op, ok := k.(pdfOp); ok && op == ">>" {
+			return d, nil
+		}
+		key, ok := k.(pdfName)
+		if !ok {
+			continue
+		}
+		v, err := lx.parseObject()
+		if err != nil {
+			return d, err
+		}
+		if op, ok := v.(pdfOp); ok && op == ">>" {
+			return d, nil
+		}
+		d[key] = v
+	}
+}
+
+// readStream reads the stream data following a dictionary, if any.
+func (lx *pdfLexer) readStream(d pdfDict) *pdfStream {
+	lx.skipSpace()
+	if !bytes.HasPrefix(lx.data[lx.pos:], []byte("stream")) {
+		return nil
+	}
+	start := lx.pos + len("stream")
+	if start < len(lx.data) && lx.data[start] == '\r' {
+		start++
+	}
+	if start < len(lx.data) && lx.data[start] == '\n' {
+		start++
+	}
+	// Trust a direct /Length when endstream follows it; otherwise search.
+	if n, ok := d["Length"].(float64); ok && n >= 0 && start+int(n) <= len(lx.data) {
+		end := start + int(n)
+		rest := bytes.TrimLeft(lx.data[end:min(end+16, len(lx.data))], "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			lx.pos = end
+			return &pdfStream{dict: d, data: lx.data[start:end]}
+		}
+	}
+	end := bytes.Index(lx.data[start:], []byte("endstream"))
+	if end < 0 {
+		end = len(lx.data) - start
+	}
+	data := bytes.TrimRight(lx.data[start:start+end], "\r\n")
+	lx.pos = start + end
+	return &pdfStream{dict: d, data: data}
+}
@@ -0,0 +1,81 @@
+package document
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Range selects sections by their 1-based index. The zero Range selects
+// everything.
+type Range struct {
+	spans []span
+}
+
+type span struct {
+	from, to int // to == 0 means open-ended
+}
+
+// ParseRange parses a selection such as "3", "2-5", "7-" or "1,3,8-10".
+// An empty string selects all sections.
+func ParseRange(s string) (Range, error) {
+	var r Range
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return r, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isSpan := strings.Cut(part, "-")
+		from, err := parseIndex(lo)
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid range %q: %w", part, err)
+		}
+		sp := span{from: from, to: from}
+		if isSpan {
+			sp.to = 0
+			if strings.TrimSpace(hi) != "" {
+				if sp.to, err = parseIndex(hi); err != nil {
+					return Range{}, fmt.Errorf("invalid range %q: %w", part, err)
+				}
+				if sp.to < sp.from {
+					return Range{}, fmt.Errorf("invalid range %q: end before start", part)
+				}
+			}
+		}
+		r.spans = append(r.spans, sp)
+	}
+	return r, nil
+}
+
+func parseIndex(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", strings.TrimSpace(s))
+	}
+	if n < 1 {
+		return 0, fmt.Errorf("indexes start at 1")
+	}
+	return n, nil
+}
+
+// All reports whether the range selects every section.
+func (r Range) All() bool {
+	return len(r.spans) == 0
+}
+
+// Contains reports whether the 1-based index i is selected.
+func (r Range) Contains(i int) bool {
+	if r.All() {
+		return true
+	}
+	for _, sp := range r.spans {
+		if i >= sp.from && (sp.to == 0 || i <= sp.to) {
+			return true
+		}
+	}
+	return false
+}
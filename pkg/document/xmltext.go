@@ -0,0 +1,342 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// readZipFile returns the decompressed content of the named archive member.
+func readZipFile(zr *zip.Reader, name string) ([]byte, error) {
+	name = strings.TrimPrefix(name, "/")
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(io.LimitReader(rc, maxPartSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxPartSize {
+			return nil, fmt.Errorf("%s: decompressed size exceeds %d bytes", name, maxPartSize)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("%s: not found in archive", name)
+}
+
+// newXMLDecoder returns a lenient decoder: office files in the wild carry
+// undeclared entities and odd charset labels that are not worth failing on.
+func newXMLDecoder(data []byte) *xml.Decoder {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	return dec
+}
+
+func attr(se xml.StartElement, local string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// relID returns the r:id attribute that links an element to another part.
+// It has to be told apart from plain id attributes, such as on p:sldId.
+func relID(se xml.StartElement) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == "id" && a.Name.Space != "" {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// relationships maps the relationship IDs of an OOXML part to archive paths.
+func relationships(zr *zip.Reader, part string) map[string]string {
+	dir, file := path.Split(part)
+	data, err := readZipFile(zr, dir+"_rels/"+file+".rels")
+	if err != nil {
+		return nil
+	}
+	rels := make(map[string]string)
+	dec := newXMLDecoder(data)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "Relationship" || attr(se, "TargetMode") == "External" {
+			continue
+		}
+		target := attr(se, "Target")
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(dir, target)
+		}
+		rels[attr(se, "Id")] = target
+	}
+	return rels
+}
+
+// coreTitle reads dc:title from an OOXML docProps/core.xml.
+func coreTitle(zr *zip.Reader) string {
+	data, err := readZipFile(zr, "docProps/core.xml")
+	if err != nil {
+		return ""
+	}
+	return firstElementText(data, "title")
+}
+
+// firstElementText returns the text of the first element with one of the
+// given local names, including the text of nested elements.
+func firstElementText(data []byte, locals ...string) string {
+	dec := newXMLDecoder(data)
+	var (
+		b     strings.Builder
+		depth int
+	)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth > 0 {
+				depth++
+			} else if slices.Contains(locals, t.Name.Local) {
+				depth = 1
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				continue
+			}
+			if depth--; depth == 0 {
+				if text := strings.Join(strings.Fields(b.String()), " "); text != "" {
+					return text
+				}
+				b.Reset()
+			}
+		case xml.CharData:
+			if depth > 0 {
+				b.Write(t)
+			}
+		}
+	}
+}
+
+// textLayout names the elements that carry structure in an XML text format.
+// Elements are matched by local name, so the same layout covers every
+// namespace prefix a producer might choose.
+type textLayout struct {
+	paragraphs map[string]bool
+	// text lists the elements whose character data is document text. When
+	// nil, all character data inside a paragraph or table counts.
+	text      map[string]bool
+	tabs      map[string]bool
+	breaks    map[string]bool
+	skip      map[string]bool // subtrees without document text
+	tables    map[string]bool
+	rows      map[string]bool
+	cells     map[string]bool
+	spaces    string // ODF <text:s text:c="n"/>
+	pageBreak func(xml.StartElement) bool
+}
+
+// walkText extracts the text of an XML part. Page breaks recognized by
+// layout.pageBreak split the result into several pages.
+func walkText(data []byte, layout *textLayout) []string {
+	w := &textWalker{layout: layout}
+	dec := newXMLDecoder(data)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			w.start(t)
+		case xml.EndElement:
+			w.end(t.Name.Local)
+		case xml.CharData:
+			w.chars(t)
+		}
+	}
+	w.flushParagraph()
+	w.pages = append(w.pages, w.page.String())
+	return w.pages
+}
+
+type textWalker struct {
+	layout     *textLayout
+	pages      []string
+	page       strings.Builder
+	para       strings.Builder
+	cell       strings.Builder
+	cells      []string
+	rows       int
+	paraDepth  int
+	textDepth  int
+	skipDepth  int
+	tableDepth int
+}
+
+func (w *textWalker) start(se xml.StartElement) {
+	if w.skipDepth > 0 {
+		w.skipDepth++
+		return
+	}
+	l := w.layout
+	name := se.Name.Local
+	if l.pageBreak != nil && l.pageBreak(se) {
+		w.newPage()
+		return
+	}
+	switch {
+	case l.skip[name]:
+		w.skipDepth = 1
+	case l.paragraphs[name]:
+		w.paraDepth++
+	case l.text[name]:
+		w.textDepth++
+	case l.tabs[name]:
+		w.para.WriteString("\t")
+	case l.breaks[name]:
+		w.para.WriteString("\n")
+	case name == l.spaces:
+		n, err := strconv.Atoi(attr(se, "c"))
+		if err != nil || n < 1 {
+			n = 1
+		}
+		w.para.WriteString(strings.Repeat(" ", min(n, 64)))
+	case l.tables[name]:
+		w.tableDepth++
+		if w.tableDepth == 1 {
+			w.flushParagraph()
+			w.rows = 0
+		}
+	case l.rows[name] && w.tableDepth == 1:
+		w.cells = w.cells[:0]
+	case l.cells[name] && w.tableDepth == 1:
+		w.cell.Reset()
+	}
+}
+
+func (w *textWalker) end(name string) {
+	if w.skipDepth > 0 {
+		w.skipDepth--
+		return
+	}
+	l := w.layout
+	switch {
+	case l.paragraphs[name]:
+		w.paraDepth--
+		w.flushParagraph()
+	case l.text[name]:
+		w.textDepth--
+	case l.cells[name] && w.tableDepth == 1:
+		w.flushParagraph()
+		w.cells = append(w.cells, w.cell.String())
+	case l.rows[name] && w.tableDepth == 1:
+		w.writeRow()
+	case l.tables[name]:
+		w.tableDepth--
+		if w.tableDepth == 0 {
+			w.page.WriteString("\n")
+		}
+	}
+}
+
+func (w *textWalker) chars(data []byte) {
+	if w.skipDepth > 0 {
+		return
+	}
+	if w.layout.text != nil {
+		if w.textDepth > 0 {
+			w.para.Write(data)
+		}
+		return
+	}
+	if w.paraDepth > 0 || w.tableDepth > 0 {
+		w.para.Write(data)
+	}
+}
+
+func (w *textWalker) flushParagraph() {
+	text := strings.TrimSpace(w.para.String())
+	w.para.Reset()
+	if w.tableDepth > 0 {
+		if text != "" {
+			if w.cell.Len() > 0 {
+				w.cell.WriteString(" ")
+			}
+			w.cell.WriteString(text)
+		}
+		return
+	}
+	if text != "" {
+		w.page.WriteString(text)
+		w.page.WriteString("\n")
+	}
+}
+
+func (w *textWalker) writeRow() {
+	if len(w.cells) == 0 {
+		return
+	}
+	w.page.WriteString(tableRow(w.cells))
+	if w.rows == 0 {
+		w.page.WriteString(tableSeparator(len(w.cells)))
+	}
+	w.rows++
+}
+
+// newPage starts a new page unless the current one is still empty, so that
+// the several break markers Word writes around one break yield one page.
+func (w *textWalker) newPage() {
+	if w.tableDepth > 0 {
+		return
+	}
+	w.flushParagraph()
+	if strings.TrimSpace(w.page.String()) == "" {
+		return
+	}
+	w.pages = append(w.pages, w.page.String())
+	w.page.Reset()
+}
+
+// tableRow renders cells as a Markdown table row.
+func tableRow(cells []string) string {
+	var b strings.Builder
+	b.WriteString("|")
+	for _, c := range cells {
+		c = strings.Join(strings.Fields(c), " ")
+		b.WriteString(" ")
+		b.WriteString(strings.ReplaceAll(c, "|", `\|`))
+		b.WriteString(" |")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func tableSeparator(n int) string {
+	return "|" + strings.Repeat(" --- |", n) + "\n"
+}
@@ -9,11 +9,14 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/sipeed/picoclaw/pkg/agent"
 	"github.com/sipeed/picoclaw/pkg/audio/asr"
 	"github.com/sipeed/picoclaw/pkg/audio/tts"
@@ -25,17 +28,22 @@ import (
 	_ "github.com/sipeed/picoclaw/pkg/channels/irc"
 	_ "github.com/sipeed/picoclaw/pkg/channels/line"
 	_ "github.com/sipeed/picoclaw/pkg/channels/maixcam"
+	_ "github.com/sipeed/picoclaw/pkg/channels/mattermost"
 	_ "github.com/sipeed/picoclaw/pkg/channels/onebot"
 	_ "github.com/sipeed/picoclaw/pkg/channels/pico"
 	_ "github.com/sipeed/picoclaw/pkg/channels/qq"
+	_ "github.com/sipeed/picoclaw/pkg/channels/rocketchat"
+	_ "github.com/sipeed/picoclaw/pkg/channels/signal"
 	_ "github.com/sipeed/picoclaw/pkg/channels/slack"
 	_ "github.com/sipeed/picoclaw/pkg/channels/teams_webhook"
 	_ "github.com/sipeed/picoclaw/pkg/channels/telegram"
 	_ "github.com/sipeed/picoclaw/pkg/channels/vk"
+	_ "github.com/sipeed/picoclaw/pkg/channels/webhook"
 	_ "github.com/sipeed/picoclaw/pkg/channels/wecom"
 	_ "github.com/sipeed/picoclaw/pkg/channels/weixin"
 	_ "github.com/sipeed/picoclaw/pkg/channels/whatsapp"
 	_ "github.com/sipeed/picoclaw/pkg/channels/whatsapp_native"
+	_ "github.com/sipeed/picoclaw/pkg/channels/xmpp"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/cron"
 	"github.com/sipeed/picoclaw/pkg/devices"
@@ -47,7 +55,9 @@ import (
 	"github.com/sipeed/picoclaw/pkg/pid"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/swarm"
 	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/triggers"
 )
 
 const (
@@ -62,6 +72,7 @@ const (
 
 type services struct {
 	CronService      *cron.CronService
+	TriggerService   *triggers.Service
 	HeartbeatService *heartbeat.HeartbeatService
 	MediaStore       media.MediaStore
 	ChannelManager   *channels.Manager
@@ -322,6 +333,10 @@ func createStartupProvider(
 	cfg *config.Config,
 	allowEmptyStartup bool,
 ) (providers.LLMProvider, string, error) {
+	// Expand "provider/*" model_list templates before resolving the default
+	// model so discovered local models can be selected by name.
+	providers.DiscoverLocalModels(context.Background(), cfg)
+
 	modelName := cfg.Agents.Defaults.GetModelName()
 	if modelName == "" && allowEmptyStartup {
 		reason := "no default model configured; gateway started in limited mode"
@@ -362,6 +377,12 @@ func setupAndStartServices(
 	}
 	fmt.Println("✓ Cron service started")
 
+	runningServices.TriggerService = setupTriggers(agentLoop, cfg)
+	if err = runningServices.TriggerService.Start(); err != nil {
+		return nil, fmt.Errorf("error starting trigger service: %w", err)
+	}
+	fmt.Println("✓ Trigger service started")
+
 	runningServices.HeartbeatService = heartbeat.NewHeartbeatService(
 		cfg.WorkspacePath(),
 		cfg.Heartbeat.Interval,
@@ -374,20 +395,12 @@ func setupAndStartServices(
 	}
 	fmt.Println("✓ Heartbeat service started")
 
-	runningServices.MediaStore = media.NewFileMediaStoreWithCleanup(media.MediaCleanerConfig{
-		Enabled:  cfg.Tools.MediaCleanup.Enabled,
-		MaxAge:   time.Duration(cfg.Tools.MediaCleanup.MaxAge) * time.Minute,
-		Interval: time.Duration(cfg.Tools.MediaCleanup.Interval) * time.Minute,
-	})
-	if fms, ok := runningServices.MediaStore.(*media.FileMediaStore); ok {
-		fms.Start()
-	}
+	runningServices.MediaStore = newMediaStore(cfg, nil)
+	startMediaStore(runningServices.MediaStore)
 
 	runningServices.ChannelManager, err = channels.NewManager(cfg, msgBus, runningServices.MediaStore)
 	if err != nil {
-		if fms, ok := runningServices.MediaStore.(*media.FileMediaStore); ok {
-			fms.Stop()
-		}
+		stopMediaStore(runningServices.MediaStore)
 		return nil, fmt.Errorf("error creating channel manager: %w", err)
 	}
 
@@ -424,9 +437,16 @@ func setupAndStartServices(
 		runningServices.HealthServer,
 	)
 
+	setupSwarmServer(runningServices.ChannelManager, agentLoop)
+	setupMediaServer(runningServices.ChannelManager, runningServices.MediaStore)
+	runningServices.ChannelManager.RegisterHTTPHandler(agent.EventStreamPath, agentLoop.EventStreamHandler(authToken))
+	runningServices.ChannelManager.RegisterHTTPHandler(agent.RuntimeAdminPath, agentLoop.RuntimeAdminHandler(authToken))
+
 	if err = runningServices.ChannelManager.StartAll(context.Background()); err != nil {
 		return nil, fmt.Errorf("error starting channels: %w", err)
 	}
+	// /ready is what the launcher probes after a restart or update.
+	runningServices.HealthServer.SetReady(true)
 
 	logChannelVoiceCapabilities(runningServices.ChannelManager, transcriber != nil, ttsAvailable)
 
@@ -443,6 +463,7 @@ func setupAndStartServices(
 		"✓ Health endpoints available at http://%s/health, /ready and /reload (POST)\n",
 		healthAddr,
 	)
+	fmt.Printf("✓ Agent event stream available at http://%s%s\n", healthAddr, agent.EventStreamPath)
 
 	stateManager := state.NewManager(cfg.WorkspacePath())
 	runningServices.DeviceService = devices.NewService(devices.Config{
@@ -463,6 +484,9 @@ func stopAndCleanupServices(runningServices *services, shutdownTimeout time.Dura
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer shutdownCancel()
 
+	if !isReload && runningServices.HealthServer != nil {
+		runningServices.HealthServer.SetReady(false)
+	}
 	// reload should not stop channel manager
 	if !isReload && runningServices.ChannelManager != nil {
 		runningServices.ChannelManager.StopAll(shutdownCtx)
@@ -479,10 +503,11 @@ func stopAndCleanupServices(runningServices *services, shutdownTimeout time.Dura
 	if runningServices.CronService != nil {
 		runningServices.CronService.Stop()
 	}
+	if runningServices.TriggerService != nil {
+		runningServices.TriggerService.Stop()
+	}
 	if runningServices.MediaStore != nil {
-		if fms, ok := runningServices.MediaStore.(*media.FileMediaStore); ok {
-			fms.Stop()
-		}
+		stopMediaStore(runningServices.MediaStore)
 	}
 }
 
@@ -598,6 +623,12 @@ func restartServices(
 	}
 	fmt.Println("  ✓ Cron service restarted")
 
+	runningServices.TriggerService = setupTriggers(al, cfg)
+	if err = runningServices.TriggerService.Start(); err != nil {
+		return fmt.Errorf("error restarting trigger service: %w", err)
+	}
+	fmt.Println("  ✓ Trigger service restarted")
+
 	runningServices.HeartbeatService = heartbeat.NewHeartbeatService(
 		cfg.WorkspacePath(),
 		cfg.Heartbeat.Interval,
@@ -610,14 +641,8 @@ func restartServices(
 	}
 	fmt.Println("  ✓ Heartbeat service restarted")
 
-	runningServices.MediaStore = media.NewFileMediaStoreWithCleanup(media.MediaCleanerConfig{
-		Enabled:  cfg.Tools.MediaCleanup.Enabled,
-		MaxAge:   time.Duration(cfg.Tools.MediaCleanup.MaxAge) * time.Minute,
-		Interval: time.Duration(cfg.Tools.MediaCleanup.Interval) * time.Minute,
-	})
-	if fms, ok := runningServices.MediaStore.(*media.FileMediaStore); ok {
-		fms.Start()
-	}
+	runningServices.MediaStore = newMediaStore(cfg, runningServices.MediaStore)
+	startMediaStore(runningServices.MediaStore)
 	al.SetMediaStore(runningServices.MediaStore)
 
 	al.SetChannelManager(runningServices.ChannelManager)
@@ -627,6 +652,9 @@ func restartServices(
 	}
 	fmt.Println("  ✓ Channels restarted.")
 
+	setupSwarmServer(runningServices.ChannelManager, al)
+	setupMediaServer(runningServices.ChannelManager, runningServices.MediaStore)
+
 	enabledChannels := runningServices.ChannelManager.GetEnabledChannels()
 	if len(enabledChannels) > 0 {
 		fmt.Printf("  ✓ Channels enabled: %s\n", enabledChannels)
@@ -761,6 +789,19 @@ func setupCronTool(
 	cronStorePath := filepath.Join(workspace, "cron", "jobs.json")
 
 	cronService := cron.NewCronService(cronStorePath, nil)
+	cronCfg := cfg.Tools.Cron
+	policy := cron.CronRunPolicy{
+		MaxRetries:     cronCfg.MaxRetries,
+		RetryBackoffMS: int64(cronCfg.RetryBackoffSeconds) * 1000,
+		TimeoutMS:      int64(cronCfg.JobTimeoutMinutes) * 60 * 1000,
+		Concurrency:    cronCfg.Concurrency,
+		CatchUp:        cronCfg.CatchUp,
+	}
+	if err := cron.ValidatePolicy(policy); err != nil {
+		return nil, fmt.Errorf("invalid tools.cron policy: %w", err)
+	}
+	cronService.SetDefaultPolicy(policy)
+	cronService.SetHistoryLimit(cronCfg.HistoryLimit)
 
 	var cronTool *tools.CronTool
 	if cfg.Tools.IsToolEnabled("cron") {
@@ -774,15 +815,139 @@ func setupCronTool(
 	}
 
 	if cronTool != nil {
-		cronService.SetOnJob(func(job *cron.CronJob) (string, error) {
-			result := cronTool.ExecuteJob(context.Background(), job)
-			return result, nil
-		})
+		cronService.SetOnJob(cronTool.RunJob)
 	}
 
 	return cronService, nil
 }
 
+// setupTriggers builds the trigger service from config, registers the
+// triggers tool and routes fired triggers into agent turns.
+func setupTriggers(agentLoop *agent.AgentLoop, cfg *config.Config) *triggers.Service {
+	workspace := cfg.WorkspacePath()
+	triggerCfg := cfg.Tools.Triggers
+
+	service := triggers.NewService(triggers.StorePath(workspace), triggers.Options{
+		Workspace:          workspace,
+		AllowPrivateHosts:  triggerCfg.AllowPrivateHosts,
+		MinIntervalSeconds: triggerCfg.MinIntervalSeconds,
+	})
+
+	service.SetConfigured(triggers.FromConfig(triggerCfg.Triggers))
+
+	stateManager := state.NewManager(workspace)
+	service.SetHandler(func(ctx context.Context, t *triggers.Trigger, prompt string) error {
+		channel, chatID := t.Channel, t.ChatID
+		if channel == "" || chatID == "" {
+			channel, chatID = "cli", "direct"
+			if last := stateManager.GetLastChannel(); last != "" {
+				if platform, id, ok := strings.Cut(last, ":"); ok && platform != "" && id != "" {
+					channel, chatID = platform, id
+				}
+			}
+		}
+
+		sessionKey := fmt.Sprintf("agent:trigger-%s-%s", t.ID, uuid.New().String())
+		response, err := agentLoop.ProcessDirectWithChannel(ctx, prompt, sessionKey, channel, chatID)
+		if err != nil {
+			return err
+		}
+		if response != "" {
+			agentLoop.PublishResponseIfNeeded(ctx, channel, chatID, sessionKey, response)
+		}
+		return nil
+	})
+
+	if cfg.Tools.IsToolEnabled("triggers") {
+		agentLoop.RegisterTool(tools.NewTriggersTool(service, workspace, cfg.Agents.Defaults.RestrictToWorkspace))
+	}
+
+	return service
+}
+
+// setupSwarmServer mounts the swarm peer API on the shared HTTP server, or
+// removes it when swarm mode is off.
+func setupSwarmServer(cm *channels.Manager, al *agent.AgentLoop) {
+	server := al.SwarmServer()
+	if server == nil {
+		cm.UnregisterHTTPHandler(swarm.PathPrefix)
+		return
+	}
+	cm.RegisterHTTPHandler(swarm.PathPrefix, server)
+	fmt.Printf("✓ Swarm peer API available at %s\n", swarm.PathPrefix)
+}
+
+// newMediaStore builds the media store selected by cfg.Tools.MediaStore.
+// The persistent store defaults to $PICOCLAW_HOME/media, outside the
+// workspace, so the agent's file tools cannot read its URL signing key.
+// On reload, a persistent store already open on the same directory is
+// reconfigured and reused instead of opening its index twice. If the
+// persistent store cannot be opened, the in-memory store is used.
+func newMediaStore(cfg *config.Config, current media.MediaStore) media.MediaStore {
+	storeCfg := cfg.Tools.MediaStore
+	if storeCfg.Persistent {
+		dir := storeCfg.Dir
+		if dir == "" {
+			dir = filepath.Join(config.GetHome(), "media")
+		}
+		diskCfg := media.DiskStoreConfig{
+			Dir:        dir,
+			ScopeQuota: int64(storeCfg.ScopeQuotaMB) << 20,
+			MaxTotal:   int64(storeCfg.MaxTotalMB) << 20,
+			MaxAge:     time.Duration(storeCfg.RetentionDays) * 24 * time.Hour,
+			PublicURL:  storeCfg.PublicURL,
+			URLTTL:     time.Duration(storeCfg.URLTTLSecs) * time.Second,
+		}
+		if ds, ok := current.(*media.DiskMediaStore); ok && ds.Dir() == dir {
+			ds.Configure(diskCfg)
+			return ds
+		}
+		ds, err := media.NewDiskMediaStore(diskCfg)
+		if err == nil {
+			return ds
+		}
+		logger.ErrorCF("media", "Failed to open persistent media store, falling back to memory", map[string]any{
+			"dir":   dir,
+			"error": err.Error(),
+		})
+	}
+	return media.NewFileMediaStoreWithCleanup(media.MediaCleanerConfig{
+		Enabled:  cfg.Tools.MediaCleanup.Enabled,
+		MaxAge:   time.Duration(cfg.Tools.MediaCleanup.MaxAge) * time.Minute,
+		Interval: time.Duration(cfg.Tools.MediaCleanup.Interval) * time.Minute,
+	})
+}
+
+// mediaStoreLifecycle is implemented by stores with a background cleanup loop.
+type mediaStoreLifecycle interface {
+	Start()
+	Stop()
+}
+
+func startMediaStore(store media.MediaStore) {
+	if lc, ok := store.(mediaStoreLifecycle); ok {
+		lc.Start()
+	}
+}
+
+func stopMediaStore(store media.MediaStore) {
+	if lc, ok := store.(mediaStoreLifecycle); ok {
+		lc.Stop()
+	}
+}
+
+// setupMediaServer mounts the signed media URL endpoint when the store can
+// serve it.
+func setupMediaServer(cm *channels.Manager, store media.MediaStore) {
+	ds, ok := store.(*media.DiskMediaStore)
+	if !ok || !ds.SignsURLs() {
+		cm.UnregisterHTTPHandler(media.URLPathPrefix)
+		return
+	}
+	cm.RegisterHTTPHandler(media.URLPathPrefix, ds)
+	fmt.Printf("✓ Signed media URLs available at %s\n", media.URLPathPrefix)
+}
+
 func createHeartbeatHandler(agentLoop *agent.AgentLoop) func(prompt, channel, chatID string) *tools.ToolResult {
 	return func(prompt, channel, chatID string) *tools.ToolResult {
 		if channel == "" || chatID == "" {
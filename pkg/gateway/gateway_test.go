@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/media"
 )
 
 func TestRun_StartupFailuresReturnErrorAndEmitStructuredLog(t *testing.T) {
@@ -106,3 +107,23 @@ func TestGatewayRunStartupFailureHelper(t *testing.T) {
 	fmt.Fprintln(os.Stdout, err.Error())
 	os.Exit(0)
 }
+
+func TestNewMediaStore_DefaultDirOutsideWorkspace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(config.EnvHome, home)
+
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = filepath.Join(t.TempDir(), "workspace")
+	cfg.Tools.MediaStore.Persistent = true
+
+	ds, ok := newMediaStore(cfg, nil).(*media.DiskMediaStore)
+	if !ok {
+		t.Fatal("expected a persistent disk media store")
+	}
+	if want := filepath.Join(home, "media"); ds.Dir() != want {
+		t.Fatalf("media dir = %q, want %q", ds.Dir(), want)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.WorkspacePath(), "media")); !os.IsNotExist(err) {
+		t.Fatalf("workspace media dir should not exist, stat err = %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// probeRequestTimeout bounds a single /ready request made by WaitReady.
+const probeRequestTimeout = 2 * time.Second
+
+// Probe fetches url (a /health or /ready endpoint) and decodes the status
+// response.
+func Probe(ctx context.Context, url string) (*StatusResponse, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	var status StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, resp.StatusCode, err
+	}
+	return &status, resp.StatusCode, nil
+}
+
+// WaitReady polls baseURL+"/ready" every interval until the server reports
+// ready or ctx is done. When pid is positive the answer must come from that
+// process, so an old instance that is still shutting down is not mistaken
+// for its replacement. The returned error describes the last observed state.
+func WaitReady(ctx context.Context, baseURL string, pid int, interval time.Duration) error {
+	url := strings.TrimRight(baseURL, "/") + "/ready"
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := "no response"
+	for {
+		status, code, err := Probe(ctx, url)
+		switch {
+		case err != nil:
+			// Keep the previous state when the probe was cut off by ctx.
+			if ctx.Err() == nil || last == "no response" {
+				last = err.Error()
+			}
+		case pid > 0 && status.PID != pid:
+			last = fmt.Sprintf("answered by PID %d, waiting for PID %d", status.PID, pid)
+		case code == http.StatusOK:
+			return nil
+		default:
+			last = describeNotReady(status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not ready: %s", last)
+		case <-ticker.C:
+		}
+	}
+}
+
+func describeNotReady(status *StatusResponse) string {
+	var failed []string
+	for name, check := range status.Checks {
+		if check.Status == "fail" {
+			if check.Message != "" {
+				name += " (" + check.Message + ")"
+			}
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) == 0 {
+		return status.Status
+	}
+	sort.Strings(failed)
+	return status.Status + ": " + strings.Join(failed, ", ")
+}
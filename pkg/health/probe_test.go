@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitReady_BecomesReady(t *testing.T) {
+	s := NewServer("127.0.0.1", 0, "")
+	mux := http.NewServeMux()
+	s.RegisterOnMux(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		s.SetReady(true)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := WaitReady(ctx, ts.URL, os.Getpid(), 10*time.Millisecond); err != nil {
+		t.Fatalf("WaitReady() error = %v", err)
+	}
+}
+
+func TestWaitReady_ReportsFailingCheck(t *testing.T) {
+	s := NewServer("127.0.0.1", 0, "")
+	s.SetReady(true)
+	s.RegisterCheck("channels", func() (bool, string) { return false, "telegram: unauthorized" })
+	mux := http.NewServeMux()
+	s.RegisterOnMux(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := WaitReady(ctx, ts.URL, 0, 10*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "telegram: unauthorized") {
+		t.Fatalf("WaitReady() error = %v, want failing check in message", err)
+	}
+}
+
+func TestWaitReady_IgnoresOtherProcess(t *testing.T) {
+	s := NewServer("127.0.0.1", 0, "")
+	s.SetReady(true)
+	mux := http.NewServeMux()
+	s.RegisterOnMux(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := WaitReady(ctx, ts.URL, os.Getpid()+1, 10*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "waiting for PID") {
+		t.Fatalf("WaitReady() error = %v, want PID mismatch", err)
+	}
+}
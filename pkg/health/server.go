@@ -192,6 +192,7 @@ func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		json.NewEncoder(w).Encode(StatusResponse{
 			Status: "not ready",
+			PID:    os.Getpid(),
 			Checks: checks,
 		})
 		return
@@ -202,6 +203,7 @@ func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusServiceUnavailable)
 			json.NewEncoder(w).Encode(StatusResponse{
 				Status: "not ready",
+				PID:    os.Getpid(),
 				Checks: checks,
 			})
 			return
@@ -213,6 +215,7 @@ func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(StatusResponse{
 		Status: "ready",
 		Uptime: uptime.String(),
+		PID:    os.Getpid(),
 		Checks: checks,
 	})
 }
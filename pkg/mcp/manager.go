@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -128,17 +129,27 @@ type ServerConnection struct {
 // Manager manages multiple MCP server connections
 type Manager struct {
 	servers map[string]*ServerConnection
+	errs    map[string]string // last connection error per server
 	mu      sync.RWMutex
 	closed  atomic.Bool    // changed from bool to atomic.Bool to avoid TOCTOU race
 	wg      sync.WaitGroup // tracks in-flight CallTool calls
 }
 
+// ServerStatus describes the live state of one MCP server.
+type ServerStatus struct {
+	Name      string `json:"name"`
+	Connected bool   `json:"connected"`
+	ToolCount int    `json:"tool_count"`
+	Error     string `json:"error,omitempty"`
+}
+
 var connectServerFunc = connectServer
 
 // NewManager creates a new MCP manager
 func NewManager() *Manager {
 	return &Manager{
 		servers: make(map[string]*ServerConnection),
+		errs:    make(map[string]string),
 	}
 }
 
@@ -265,22 +276,84 @@ func (m *Manager) ConnectServer(
 	cfg config.MCPServerConfig,
 ) error {
 	conn, err := connectServerFunc(ctx, name, cfg)
-	if err != nil {
-		return err
-	}
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if err != nil {
+		m.errs[name] = err.Error()
+		return err
+	}
 	if m.closed.Load() {
 		_ = conn.Session.Close()
 		return fmt.Errorf("manager is closed")
 	}
 
 	m.servers[name] = conn
+	delete(m.errs, name)
 	return nil
 }
 
+// RestartServer opens a fresh connection to a server and swaps it in place
+// of the current one, if any. On failure the current connection is kept.
+func (m *Manager) RestartServer(
+	ctx context.Context,
+	name string,
+	cfg config.MCPServerConfig,
+) (*ServerConnection, error) {
+	if m.closed.Load() {
+		return nil, fmt.Errorf("manager is closed")
+	}
+
+	conn, err := connectServerFunc(ctx, name, cfg)
+
+	m.mu.Lock()
+	if err != nil {
+		m.errs[name] = err.Error()
+		m.mu.Unlock()
+		return nil, err
+	}
+	if m.closed.Load() {
+		m.mu.Unlock()
+		_ = conn.Session.Close()
+		return nil, fmt.Errorf("manager is closed")
+	}
+	old := m.servers[name]
+	m.servers[name] = conn
+	delete(m.errs, name)
+	m.mu.Unlock()
+
+	if old != nil {
+		_ = old.Session.Close()
+	}
+	logger.InfoCF("mcp", "Restarted MCP server",
+		map[string]any{
+			"server":    name,
+			"toolCount": len(conn.Tools),
+		})
+	return conn, nil
+}
+
+// Status reports every server that is connected or failed to connect,
+// sorted by name.
+func (m *Manager) Status() []ServerStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]ServerStatus, 0, len(m.servers)+len(m.errs))
+	for name, conn := range m.servers {
+		result = append(result, ServerStatus{Name: name, Connected: true, ToolCount: len(conn.Tools)})
+	}
+	for name, msg := range m.errs {
+		if _, ok := m.servers[name]; ok {
+			continue
+		}
+		result = append(result, ServerStatus{Name: name, Error: msg})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
 func connectServer(
 	ctx context.Context,
 	name string,
@@ -392,6 +392,54 @@ func TestCallTool_ReconnectsWhenHTTPServerLosesSession(t *testing.T) {
 	}
 }
 
+func TestRestartServer_SwapsConnectionAndReportsStatus(t *testing.T) {
+	originalConnectServerFunc := connectServerFunc
+	t.Cleanup(func() {
+		connectServerFunc = originalConnectServerFunc
+	})
+
+	oldConn, _, err := newScriptedServerConnection("session-1", nil, nil)
+	if err != nil {
+		t.Fatalf("newScriptedServerConnection(old) error = %v", err)
+	}
+	freshConn, _, err := newScriptedServerConnection("session-2", nil, nil)
+	if err != nil {
+		t.Fatalf("newScriptedServerConnection(fresh) error = %v", err)
+	}
+
+	connectServerFunc = func(ctx context.Context, name string, cfg config.MCPServerConfig) (*ServerConnection, error) {
+		if name == "broken" {
+			return nil, fmt.Errorf("command not found")
+		}
+		return freshConn, nil
+	}
+
+	mgr := NewManager()
+	mgr.servers["flaky"] = oldConn
+	if err := mgr.ConnectServer(context.Background(), "broken", config.MCPServerConfig{}); err == nil {
+		t.Fatal("expected ConnectServer(broken) to fail")
+	}
+
+	if _, err := mgr.RestartServer(context.Background(), "flaky", oldConn.Config); err != nil {
+		t.Fatalf("RestartServer() error = %v", err)
+	}
+	conn, ok := mgr.GetServer("flaky")
+	if !ok || conn.Session.ID() != "session-2" {
+		t.Fatalf("expected restarted connection, got %#v", conn)
+	}
+
+	status := mgr.Status()
+	if len(status) != 2 {
+		t.Fatalf("Status() = %#v, want 2 entries", status)
+	}
+	if status[0].Name != "broken" || status[0].Connected || status[0].Error != "command not found" {
+		t.Fatalf("status[0] = %#v", status[0])
+	}
+	if status[1].Name != "flaky" || !status[1].Connected || status[1].ToolCount != 1 {
+		t.Fatalf("status[1] = %#v", status[1])
+	}
+}
+
 func TestClose_IdempotentOnEmptyManager(t *testing.T) {
 	mgr := NewManager()
 
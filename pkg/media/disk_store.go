@@ -0,0 +1,508 @@
+package media
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	diskIndexFile    = "index.json"
+	diskBlobDir      = "blobs"
+	diskIndexVersion = 1
+)
+
+// ErrQuotaExceeded is returned by DiskMediaStore.Store when accepting a file
+// would push a scope or the whole store over its configured limit.
+var ErrQuotaExceeded = errors.New("media store: quota exceeded")
+
+// DiskStoreConfig configures a DiskMediaStore.
+type DiskStoreConfig struct {
+	// Dir holds the index and the content-addressed blobs.
+	Dir string
+	// ScopeQuota caps the bytes referenced by one scope. 0 disables it.
+	ScopeQuota int64
+	// MaxTotal caps the bytes of all blobs on disk. 0 disables it.
+	MaxTotal int64
+	// MaxAge expires refs older than this. 0 keeps refs until released.
+	MaxAge time.Duration
+	// Interval is how often expired refs are swept when MaxAge is set.
+	Interval time.Duration
+	// PublicURL is the externally reachable gateway base URL used by
+	// SignedURL. Empty disables signed URLs.
+	PublicURL string
+	// URLTTL is how long a signed URL stays valid. Defaults to one hour.
+	URLTTL time.Duration
+}
+
+// diskEntry is one ref as recorded in the on-disk index.
+type diskEntry struct {
+	Hash        string    `json:"hash"`
+	Ext         string    `json:"ext,omitempty"`
+	Size        int64     `json:"size"`
+	Scope       string    `json:"scope"`
+	Filename    string    `json:"filename,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Source      string    `json:"source,omitempty"`
+	StoredAt    time.Time `json:"stored_at"`
+}
+
+type diskIndex struct {
+	Version int                   `json:"version"`
+	Refs    map[string]*diskEntry `json:"refs"`
+}
+
+// DiskMediaStore is a persistent MediaStore. Stored files are copied into
+// Dir under their SHA-256 digest, so identical content is kept once no matter
+// how often it is received, and refs recorded in session history keep
+// resolving after a restart.
+//
+// Files stored with CleanupPolicyDeleteOnCleanup are removed from their
+// original location once copied; CleanupPolicyForgetOnly files are left in
+// place. A blob is deleted when its last ref is released or expires.
+type DiskMediaStore struct {
+	cfg DiskStoreConfig
+
+	mu         sync.RWMutex
+	refs       map[string]*diskEntry
+	blobRefs   map[string]int // hash -> number of refs
+	blobSize   map[string]int64
+	scopeBytes map[string]int64
+
+	signKey []byte
+
+	lifeMu  sync.Mutex
+	stop    chan struct{}
+	nowFunc func() time.Time // for testing
+}
+
+// NewDiskMediaStore opens (or creates) a store rooted at cfg.Dir and loads
+// its index.
+func NewDiskMediaStore(cfg DiskStoreConfig) (*DiskMediaStore, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("media store: directory is required")
+	}
+	if err := os.MkdirAll(filepath.Join(cfg.Dir, diskBlobDir), 0o700); err != nil {
+		return nil, fmt.Errorf("media store: %w", err)
+	}
+	key, err := loadSigningKey(filepath.Join(cfg.Dir, signingKeyFile))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &DiskMediaStore{
+		cfg:        cfg,
+		refs:       make(map[string]*diskEntry),
+		blobRefs:   make(map[string]int),
+		blobSize:   make(map[string]int64),
+		scopeBytes: make(map[string]int64),
+		signKey:    key,
+		nowFunc:    time.Now,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Dir returns the directory the store lives in.
+func (s *DiskMediaStore) Dir() string {
+	return s.cfg.Dir
+}
+
+// Configure applies new limits, retention and URL settings to a running
+// store, as on config reload. The directory cannot change.
+func (s *DiskMediaStore) Configure(cfg DiskStoreConfig) {
+	cfg.Dir = s.cfg.Dir
+	s.mu.Lock()
+	s.cfg = cfg
+	s.mu.Unlock()
+}
+
+func (s *DiskMediaStore) load() error {
+	data, err := os.ReadFile(filepath.Join(s.cfg.Dir, diskIndexFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("media store: read index: %w", err)
+	}
+	var idx diskIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return fmt.Errorf("media store: parse index: %w", err)
+	}
+
+	dropped := 0
+	for ref, e := range idx.Refs {
+		if e == nil || !validHash(e.Hash) {
+			dropped++
+			continue
+		}
+		if _, err := os.Stat(s.blobPath(e.Hash, e.Ext)); err != nil {
+			// The blob was removed behind our back; the ref cannot resolve.
+			dropped++
+			continue
+		}
+		s.addLocked(ref, e)
+	}
+	if dropped > 0 {
+		logger.WarnCF("media", "Dropped index entries without a blob", map[string]any{
+			"dir":   s.cfg.Dir,
+			"count": dropped,
+		})
+		return s.saveLocked()
+	}
+	return nil
+}
+
+// Store copies localPath into the store and returns a ref for it. Storing
+// the same content with the same filename under the same scope returns the
+// existing ref.
+func (s *DiskMediaStore) Store(localPath string, meta MediaMeta, scope string) (string, error) {
+	meta.CleanupPolicy = normalizeCleanupPolicy(meta.CleanupPolicy)
+
+	hash, size, tmpPath, err := s.ingest(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpPath)
+
+	ext := strings.ToLower(filepath.Ext(meta.Filename))
+	if ext == "" {
+		ext = strings.ToLower(filepath.Ext(localPath))
+	}
+	if !validExt(ext) {
+		ext = ""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ref, e := range s.refs {
+		if e.Scope == scope && e.Hash == hash && e.Filename == meta.Filename {
+			s.consumeSource(localPath, meta.CleanupPolicy)
+			return ref, nil
+		}
+	}
+
+	if s.cfg.ScopeQuota > 0 && s.scopeBytes[scope]+size > s.cfg.ScopeQuota {
+		return "", fmt.Errorf("%w: scope %q would use %d of %d bytes",
+			ErrQuotaExceeded, scope, s.scopeBytes[scope]+size, s.cfg.ScopeQuota)
+	}
+
+	if s.blobRefs[hash] == 0 {
+		if s.cfg.MaxTotal > 0 {
+			if total := s.totalBytesLocked() + size; total > s.cfg.MaxTotal {
+				return "", fmt.Errorf("%w: store would use %d of %d bytes", ErrQuotaExceeded, total, s.cfg.MaxTotal)
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(s.blobPath(hash, ext)), 0o700); err != nil {
+			return "", fmt.Errorf("media store: %w", err)
+		}
+		if err := os.Rename(tmpPath, s.blobPath(hash, ext)); err != nil {
+			return "", fmt.Errorf("media store: %w", err)
+		}
+	} else {
+		// Keep the extension of the blob already on disk.
+		ext = s.existingExtLocked(hash)
+	}
+
+	ref := "media://" + uuid.New().String()
+	s.addLocked(ref, &diskEntry{
+		Hash:        hash,
+		Ext:         ext,
+		Size:        size,
+		Scope:       scope,
+		Filename:    meta.Filename,
+		ContentType: meta.ContentType,
+		Source:      meta.Source,
+		StoredAt:    s.nowFunc().UTC(),
+	})
+	if err := s.saveLocked(); err != nil {
+		if path, orphan := s.removeLocked(ref); orphan {
+			removeBlobs([]string{path}, "store")
+		}
+		return "", err
+	}
+	s.consumeSource(localPath, meta.CleanupPolicy)
+	return ref, nil
+}
+
+// ingest copies localPath to a temporary file inside the store while hashing
+// it, so the final rename into the blob tree is atomic.
+func (s *DiskMediaStore) ingest(localPath string) (hash string, size int64, tmpPath string, err error) {
+	src, err := os.Open(localPath)
+	if err != nil {
+		return "", 0, "", fmt.Errorf("media store: %s: %w", localPath, err)
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(s.cfg.Dir, ".ingest-*")
+	if err != nil {
+		return "", 0, "", fmt.Errorf("media store: %w", err)
+	}
+	h := sha256.New()
+	size, err = io.Copy(io.MultiWriter(tmp, h), src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", 0, "", fmt.Errorf("media store: copy %s: %w", localPath, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, tmp.Name(), nil
+}
+
+// consumeSource removes the original file once the store owns a copy of it.
+func (s *DiskMediaStore) consumeSource(localPath string, policy CleanupPolicy) {
+	if policy != CleanupPolicyDeleteOnCleanup {
+		return
+	}
+	if err := os.Remove(localPath); err != nil && !os.IsNotExist(err) {
+		logger.WarnCF("media", "store: failed to remove ingested file", map[string]any{
+			"path":  localPath,
+			"error": err.Error(),
+		})
+	}
+}
+
+// Resolve returns the blob path for the given ref.
+func (s *DiskMediaStore) Resolve(ref string) (string, error) {
+	path, _, err := s.ResolveWithMeta(ref)
+	return path, err
+}
+
+// ResolveWithMeta returns the blob path and metadata for the given ref.
+func (s *DiskMediaStore) ResolveWithMeta(ref string) (string, MediaMeta, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.refs[ref]
+	if !ok {
+		return "", MediaMeta{}, fmt.Errorf("media store: unknown ref: %s", ref)
+	}
+	return s.blobPath(e.Hash, e.Ext), MediaMeta{
+		Filename:      e.Filename,
+		ContentType:   e.ContentType,
+		Source:        e.Source,
+		CleanupPolicy: CleanupPolicyDeleteOnCleanup,
+	}, nil
+}
+
+// ReleaseAll drops every ref under scope and deletes blobs no longer
+// referenced by any other scope.
+func (s *DiskMediaStore) ReleaseAll(scope string) error {
+	s.mu.Lock()
+	var orphans []string
+	for ref, e := range s.refs {
+		if e.Scope == scope {
+			if path, orphan := s.removeLocked(ref); orphan {
+				orphans = append(orphans, path)
+			}
+		}
+	}
+	err := s.saveLocked()
+	s.mu.Unlock()
+
+	removeBlobs(orphans, "release")
+	return err
+}
+
+// CleanExpired removes refs older than MaxAge and returns how many were
+// dropped.
+func (s *DiskMediaStore) CleanExpired() int {
+	s.mu.Lock()
+	if s.cfg.MaxAge <= 0 {
+		s.mu.Unlock()
+		return 0
+	}
+	cutoff := s.nowFunc().Add(-s.cfg.MaxAge)
+	var orphans []string
+	expired := 0
+	for ref, e := range s.refs {
+		if e.StoredAt.Before(cutoff) {
+			if path, orphan := s.removeLocked(ref); orphan {
+				orphans = append(orphans, path)
+			}
+			expired++
+		}
+	}
+	if expired > 0 {
+		if err := s.saveLocked(); err != nil {
+			logger.WarnCF("media", "cleanup: failed to save index", map[string]any{"error": err.Error()})
+		}
+	}
+	s.mu.Unlock()
+
+	removeBlobs(orphans, "cleanup")
+	return expired
+}
+
+// Usage returns the bytes referenced by scope and the bytes of all blobs.
+func (s *DiskMediaStore) Usage(scope string) (scopeBytes, totalBytes int64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.scopeBytes[scope], s.totalBytesLocked()
+}
+
+// Start begins the background expiry sweep when MaxAge is set. A stopped
+// store may be started again.
+func (s *DiskMediaStore) Start() {
+	s.mu.RLock()
+	maxAge, interval := s.cfg.MaxAge, s.cfg.Interval
+	s.mu.RUnlock()
+	if maxAge <= 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	s.lifeMu.Lock()
+	defer s.lifeMu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	s.stop = stop
+
+	logger.InfoCF("media", "persistent store cleanup enabled", map[string]any{
+		"dir":      s.cfg.Dir,
+		"interval": interval.String(),
+		"max_age":  maxAge.String(),
+	})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if n := s.CleanExpired(); n > 0 {
+					logger.InfoCF("media", "cleanup: removed expired entries", map[string]any{
+						"count": n,
+					})
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background sweep. Safe to call multiple times.
+func (s *DiskMediaStore) Stop() {
+	s.lifeMu.Lock()
+	defer s.lifeMu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+func (s *DiskMediaStore) addLocked(ref string, e *diskEntry) {
+	s.refs[ref] = e
+	s.blobRefs[e.Hash]++
+	s.blobSize[e.Hash] = e.Size
+	s.scopeBytes[e.Scope] += e.Size
+}
+
+// removeLocked drops ref and reports the blob path when no ref uses it any
+// more.
+func (s *DiskMediaStore) removeLocked(ref string) (string, bool) {
+	e, ok := s.refs[ref]
+	if !ok {
+		return "", false
+	}
+	delete(s.refs, ref)
+
+	s.scopeBytes[e.Scope] -= e.Size
+	if s.scopeBytes[e.Scope] <= 0 {
+		delete(s.scopeBytes, e.Scope)
+	}
+	s.blobRefs[e.Hash]--
+	if s.blobRefs[e.Hash] > 0 {
+		return "", false
+	}
+	delete(s.blobRefs, e.Hash)
+	delete(s.blobSize, e.Hash)
+	return s.blobPath(e.Hash, e.Ext), true
+}
+
+func (s *DiskMediaStore) existingExtLocked(hash string) string {
+	for _, e := range s.refs {
+		if e.Hash == hash {
+			return e.Ext
+		}
+	}
+	return ""
+}
+
+func (s *DiskMediaStore) totalBytesLocked() int64 {
+	var total int64
+	for _, size := range s.blobSize {
+		total += size
+	}
+	return total
+}
+
+func (s *DiskMediaStore) saveLocked() error {
+	data, err := json.MarshalIndent(diskIndex{Version: diskIndexVersion, Refs: s.refs}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("media store: encode index: %w", err)
+	}
+	if err := fileutil.WriteFileAtomic(filepath.Join(s.cfg.Dir, diskIndexFile), data, 0o600); err != nil {
+		return fmt.Errorf("media store: write index: %w", err)
+	}
+	return nil
+}
+
+// blobPath shards blobs by the first two hex digits of their digest.
+func (s *DiskMediaStore) blobPath(hash, ext string) string {
+	return filepath.Join(s.cfg.Dir, diskBlobDir, hash[:2], hash+ext)
+}
+
+func removeBlobs(paths []string, op string) {
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			logger.WarnCF("media", op+": failed to remove file", map[string]any{
+				"path":  p,
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+// validExt keeps blob names free of path separators and oddities copied
+// from untrusted filenames.
+func validExt(ext string) bool {
+	if len(ext) < 2 || len(ext) > 10 || ext[0] != '.' {
+		return false
+	}
+	for _, r := range ext[1:] {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
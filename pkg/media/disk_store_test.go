@@ -0,0 +1,223 @@
+package media
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestDiskStore(t *testing.T, cfg DiskStoreConfig) *DiskMediaStore {
+	t.Helper()
+	if cfg.Dir == "" {
+		cfg.Dir = filepath.Join(t.TempDir(), "media")
+	}
+	s, err := NewDiskMediaStore(cfg)
+	if err != nil {
+		t.Fatalf("NewDiskMediaStore failed: %v", err)
+	}
+	return s
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiskStore_StoreTakesOwnershipAndSurvivesRestart(t *testing.T) {
+	src := t.TempDir()
+	dir := filepath.Join(t.TempDir(), "media")
+	store := newTestDiskStore(t, DiskStoreConfig{Dir: dir})
+
+	path := writeFile(t, src, "photo.jpg", "jpeg bytes")
+	ref, err := store.Store(path, MediaMeta{Filename: "photo.jpg", ContentType: "image/jpeg", Source: "telegram"}, "tg:1:10")
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if !strings.HasPrefix(ref, "media://") {
+		t.Fatalf("unexpected ref %q", ref)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("delete_on_cleanup source should be removed after ingest, stat err = %v", err)
+	}
+
+	reopened := newTestDiskStore(t, DiskStoreConfig{Dir: dir})
+	blob, meta, err := reopened.ResolveWithMeta(ref)
+	if err != nil {
+		t.Fatalf("ref should resolve after reopen: %v", err)
+	}
+	if meta.Filename != "photo.jpg" || meta.ContentType != "image/jpeg" || meta.Source != "telegram" {
+		t.Errorf("unexpected meta: %+v", meta)
+	}
+	if !strings.HasPrefix(blob, dir) || filepath.Ext(blob) != ".jpg" {
+		t.Errorf("blob path %q should live in the store with the original extension", blob)
+	}
+	data, _ := os.ReadFile(blob)
+	if string(data) != "jpeg bytes" {
+		t.Errorf("blob content = %q", data)
+	}
+}
+
+func TestDiskStore_ForgetOnlyKeepsSource(t *testing.T) {
+	src := t.TempDir()
+	store := newTestDiskStore(t, DiskStoreConfig{})
+
+	path := writeFile(t, src, "report.pdf", "pdf")
+	if _, err := store.Store(path, MediaMeta{Filename: "report.pdf", CleanupPolicy: CleanupPolicyForgetOnly}, "s"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("forget_only source must stay in place: %v", err)
+	}
+}
+
+func TestDiskStore_DeduplicatesContent(t *testing.T) {
+	src := t.TempDir()
+	store := newTestDiskStore(t, DiskStoreConfig{})
+
+	ref1, _ := store.Store(writeFile(t, src, "a.png", "same"), MediaMeta{Filename: "a.png"}, "chat:1")
+	ref2, _ := store.Store(writeFile(t, src, "b.png", "same"), MediaMeta{Filename: "b.png"}, "chat:2")
+	ref3, _ := store.Store(writeFile(t, src, "a2.png", "same"), MediaMeta{Filename: "a.png"}, "chat:1")
+
+	if ref1 == ref2 {
+		t.Error("different scopes should get different refs")
+	}
+	if ref1 != ref3 {
+		t.Error("same content, name and scope should reuse the ref")
+	}
+	p1, _ := store.Resolve(ref1)
+	p2, _ := store.Resolve(ref2)
+	if p1 != p2 {
+		t.Errorf("identical content should share one blob: %q vs %q", p1, p2)
+	}
+	if _, total := store.Usage(""); total != int64(len("same")) {
+		t.Errorf("total usage = %d, want one copy", total)
+	}
+
+	// Releasing one scope keeps the shared blob for the other.
+	if err := store.ReleaseAll("chat:1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(p2); err != nil {
+		t.Fatalf("blob still referenced by chat:2 was removed: %v", err)
+	}
+	store.ReleaseAll("chat:2")
+	if _, err := os.Stat(p2); !os.IsNotExist(err) {
+		t.Errorf("unreferenced blob should be deleted, stat err = %v", err)
+	}
+}
+
+func TestDiskStore_Quotas(t *testing.T) {
+	src := t.TempDir()
+	store := newTestDiskStore(t, DiskStoreConfig{ScopeQuota: 10, MaxTotal: 15})
+
+	if _, err := store.Store(writeFile(t, src, "1", "12345678"), MediaMeta{}, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := store.Store(writeFile(t, src, "2", "abcd"), MediaMeta{}, "s1")
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected scope quota error, got %v", err)
+	}
+	if _, err := store.Store(writeFile(t, src, "3", "abcd"), MediaMeta{}, "s2"); err != nil {
+		t.Fatalf("other scope should still fit: %v", err)
+	}
+	_, err = store.Store(writeFile(t, src, "4", "wxyz"), MediaMeta{}, "s3")
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected total quota error, got %v", err)
+	}
+	// Deduplicated content costs no extra disk space.
+	if _, err := store.Store(writeFile(t, src, "5", "abcd"), MediaMeta{}, "s3"); err != nil {
+		t.Fatalf("duplicate content should not count against the total: %v", err)
+	}
+}
+
+func TestDiskStore_CleanExpired(t *testing.T) {
+	src := t.TempDir()
+	store := newTestDiskStore(t, DiskStoreConfig{MaxAge: time.Hour})
+	now := time.Now()
+	store.nowFunc = func() time.Time { return now }
+
+	old, _ := store.Store(writeFile(t, src, "old.txt", "old"), MediaMeta{}, "s")
+	store.nowFunc = func() time.Time { return now.Add(50 * time.Minute) }
+	fresh, _ := store.Store(writeFile(t, src, "new.txt", "new"), MediaMeta{}, "s")
+
+	store.nowFunc = func() time.Time { return now.Add(90 * time.Minute) }
+	if n := store.CleanExpired(); n != 1 {
+		t.Fatalf("CleanExpired = %d, want 1", n)
+	}
+	if _, err := store.Resolve(old); err == nil {
+		t.Error("expired ref should be gone")
+	}
+	if _, err := store.Resolve(fresh); err != nil {
+		t.Errorf("fresh ref should remain: %v", err)
+	}
+}
+
+func TestDiskStore_LoadDropsMissingBlobs(t *testing.T) {
+	src := t.TempDir()
+	dir := filepath.Join(t.TempDir(), "media")
+	store := newTestDiskStore(t, DiskStoreConfig{Dir: dir})
+	ref, _ := store.Store(writeFile(t, src, "x.bin", "x"), MediaMeta{}, "s")
+	blob, _ := store.Resolve(ref)
+	os.Remove(blob)
+
+	reopened := newTestDiskStore(t, DiskStoreConfig{Dir: dir})
+	if _, err := reopened.Resolve(ref); err == nil {
+		t.Error("ref without blob should not resolve")
+	}
+}
+
+func TestDiskStore_SignedURL(t *testing.T) {
+	src := t.TempDir()
+	store := newTestDiskStore(t, DiskStoreConfig{URLTTL: time.Minute})
+	ref, _ := store.Store(writeFile(t, src, "cat.png", "meow"), MediaMeta{Filename: "cat.png", ContentType: "image/png"}, "s")
+
+	if _, err := store.SignedURL(ref); !errors.Is(err, ErrNoPublicURL) {
+		t.Fatalf("expected ErrNoPublicURL, got %v", err)
+	}
+	store.Configure(DiskStoreConfig{PublicURL: "https://bot.example.com/", URLTTL: time.Minute})
+
+	signed, err := store.SignedURL(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(signed, "https://bot.example.com/media/") || !strings.Contains(signed, "/cat.png?") {
+		t.Fatalf("unexpected URL %q", signed)
+	}
+	u, _ := url.Parse(signed)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		store.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	rec := get(u.RequestURI())
+	body, _ := io.ReadAll(rec.Result().Body)
+	if rec.Code != http.StatusOK || string(body) != "meow" {
+		t.Fatalf("signed URL: status %d body %q", rec.Code, body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	tampered := strings.Replace(u.RequestURI(), "sig=", "sig=00", 1)
+	if rec := get(tampered); rec.Code != http.StatusNotFound {
+		t.Errorf("tampered signature: status %d, want 404", rec.Code)
+	}
+
+	store.nowFunc = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if rec := get(u.RequestURI()); rec.Code != http.StatusNotFound {
+		t.Errorf("expired URL: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,155 @@
+package media
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// URLPathPrefix is where the gateway serves signed media URLs.
+const URLPathPrefix = "/media/"
+
+const (
+	signingKeyFile    = "url.key"
+	defaultSignedTTL  = time.Hour
+	signingKeyLength  = 32
+	mediaRefURIPrefix = "media://"
+)
+
+// ErrNoPublicURL is returned by SignedURL when the store has no public base
+// URL configured.
+var ErrNoPublicURL = errors.New("media store: public URL not configured")
+
+// URLSigner is implemented by stores that can hand out time-limited public
+// URLs for their media. Channels whose APIs fetch media by URL (rather than
+// accepting uploads) use it when the store supports it.
+type URLSigner interface {
+	SignedURL(ref string) (string, error)
+}
+
+// SignsURLs reports whether a public base URL is configured.
+func (s *DiskMediaStore) SignsURLs() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg.PublicURL != ""
+}
+
+// SignedURL returns a URL under PublicURL that serves ref until URLTTL
+// elapses.
+func (s *DiskMediaStore) SignedURL(ref string) (string, error) {
+	s.mu.RLock()
+	base, ttl := s.cfg.PublicURL, s.cfg.URLTTL
+	e, ok := s.refs[ref]
+	s.mu.RUnlock()
+
+	if base == "" {
+		return "", ErrNoPublicURL
+	}
+	if !ok {
+		return "", fmt.Errorf("media store: unknown ref: %s", ref)
+	}
+	if ttl <= 0 {
+		ttl = defaultSignedTTL
+	}
+
+	id := strings.TrimPrefix(ref, mediaRefURIPrefix)
+	exp := strconv.FormatInt(s.nowFunc().Add(ttl).Unix(), 10)
+	name := e.Filename
+	if name == "" {
+		name = "file" + e.Ext
+	}
+
+	q := url.Values{}
+	q.Set("exp", exp)
+	q.Set("sig", s.sign(id, exp))
+	return strings.TrimRight(base, "/") + URLPathPrefix + id + "/" + url.PathEscape(path.Base(name)) + "?" + q.Encode(), nil
+}
+
+// ServeHTTP serves media for URLs produced by SignedURL. Requests with a
+// missing, wrong or expired signature get 404 so refs cannot be probed.
+func (s *DiskMediaStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, URLPathPrefix)
+	id, _, _ := strings.Cut(rest, "/")
+	exp := r.URL.Query().Get("exp")
+	sig := r.URL.Query().Get("sig")
+
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if id == "" || err != nil || s.nowFunc().Unix() > expUnix ||
+		!hmac.Equal([]byte(sig), []byte(s.sign(id, exp))) {
+		http.NotFound(w, r)
+		return
+	}
+
+	localPath, meta, err := s.ResolveWithMeta(mediaRefURIPrefix + id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	if meta.ContentType != "" {
+		w.Header().Set("Content-Type", meta.ContentType)
+	}
+	if meta.Filename != "" {
+		w.Header().Set("Content-Disposition",
+			mime.FormatMediaType("inline", map[string]string{"filename": meta.Filename}))
+	}
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(max(expUnix-s.nowFunc().Unix(), 0), 10))
+	http.ServeContent(w, r, meta.Filename, info.ModTime(), f)
+}
+
+func (s *DiskMediaStore) sign(id, exp string) string {
+	mac := hmac.New(sha256.New, s.signKey)
+	mac.Write([]byte(id))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(exp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// loadSigningKey reads the URL signing key, creating it on first use. The
+// key lives next to the index so signed URLs stay valid across restarts.
+func loadSigningKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err == nil && len(key) >= signingKeyLength {
+		return key, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("media store: read signing key: %w", err)
+	}
+
+	key = make([]byte, signingKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("media store: generate signing key: %w", err)
+	}
+	if err := os.WriteFile(path, key, 0o600); err != nil {
+		return nil, fmt.Errorf("media store: write signing key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,110 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers/ollama"
+)
+
+// discoveryTimeout bounds each server query so an unreachable local
+// endpoint cannot stall gateway startup.
+const discoveryTimeout = 5 * time.Second
+
+// DiscoverLocalModels expands discovery templates in cfg.ModelList into
+// virtual entries, one per model installed on the server. A template is a
+// model_list entry whose model ID is "*", e.g.
+//
+//	{"model_name": "ollama", "model": "ollama/*"}
+//
+// Each discovered model is registered as "<template model_name>/<model>"
+// (e.g. "ollama/llama3.1:8b") and inherits the template's api_base, proxy and
+// provider options. Names that already exist in model_list are left alone so
+// hand-written entries always win. Discovery failures are logged and skipped.
+func DiscoverLocalModels(ctx context.Context, cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+
+	existing := make(map[string]struct{}, len(cfg.ModelList))
+	for _, mc := range cfg.ModelList {
+		existing[mc.ModelName] = struct{}{}
+	}
+
+	var discovered []*config.ModelConfig
+	for _, tmpl := range cfg.ModelList {
+		if tmpl.IsVirtual() || !tmpl.IsDiscoveryTemplate() {
+			continue
+		}
+		protocol, _ := ExtractProtocol(tmpl)
+		var names []string
+		switch protocol {
+		case "ollama", "ollama-native":
+			names = discoverOllamaModels(ctx, tmpl, protocol)
+		default:
+			logger.WarnCF("providers", "Model discovery is not supported for this provider",
+				map[string]any{"model_name": tmpl.ModelName, "provider": protocol})
+			continue
+		}
+
+		for _, name := range names {
+			modelName := tmpl.ModelName + "/" + name
+			if _, ok := existing[modelName]; ok {
+				continue
+			}
+			existing[modelName] = struct{}{}
+
+			model := name
+			if strings.TrimSpace(tmpl.Provider) == "" {
+				model = protocol + "/" + name
+			}
+			discovered = append(discovered, tmpl.DiscoveredModel(modelName, model))
+		}
+	}
+
+	if len(discovered) > 0 {
+		cfg.ModelList = append(cfg.ModelList, discovered...)
+		logger.InfoCF("providers", "Discovered local models",
+			map[string]any{"count": len(discovered)})
+	}
+}
+
+func discoverOllamaModels(ctx context.Context, tmpl *config.ModelConfig, protocol string) []string {
+	// The native client strips the /v1 suffix of the OpenAI-compatible
+	// shim, so one listing works for both protocols.
+	apiBase := tmpl.APIBase
+	if apiBase == "" {
+		apiBase = getDefaultAPIBase(protocol)
+	}
+	client := ollama.NewProvider(apiBase, tmpl.Proxy, ollama.WithCustomHeaders(ollamaHeaders(tmpl)))
+
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	models, err := client.ListModels(ctx)
+	if err != nil {
+		logger.WarnCF("providers", "Ollama model discovery failed",
+			map[string]any{"model_name": tmpl.ModelName, "api_base": client.APIBase(), "error": err.Error()})
+		return nil
+	}
+
+	names := make([]string, 0, len(models))
+	for _, m := range models {
+		name := strings.TrimSpace(m.Name)
+		if name == "" {
+			name = strings.TrimSpace(m.Model)
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
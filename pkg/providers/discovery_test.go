@@ -0,0 +1,77 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestDiscoverLocalModels_ExpandsOllamaTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"models":[{"name":"llama3.1:8b"},{"name":"qwen3:4b"}]}`)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		ModelList: []*config.ModelConfig{
+			{
+				ModelName: "ollama",
+				Model:     "ollama-native/*",
+				APIBase:   server.URL + "/v1",
+				Ollama:    &config.OllamaModelConfig{KeepAlive: "5m"},
+			},
+			// A hand-written entry with the same name must not be shadowed.
+			{ModelName: "ollama/qwen3:4b", Model: "ollama/qwen3:4b", APIBase: "http://other:11434"},
+		},
+	}
+
+	DiscoverLocalModels(context.Background(), cfg)
+
+	if len(cfg.ModelList) != 3 {
+		t.Fatalf("len(ModelList) = %d, want 3", len(cfg.ModelList))
+	}
+	got, err := cfg.GetModelConfig("ollama/llama3.1:8b")
+	if err != nil {
+		t.Fatalf("GetModelConfig() error = %v", err)
+	}
+	if !got.IsVirtual() {
+		t.Error("discovered model should be virtual")
+	}
+	if got.Model != "ollama-native/llama3.1:8b" || got.APIBase != server.URL+"/v1" {
+		t.Errorf("discovered model = %+v", got)
+	}
+	if got.Ollama == nil || got.Ollama.KeepAlive != "5m" {
+		t.Errorf("discovered model should inherit ollama options, got %+v", got.Ollama)
+	}
+	kept, _ := cfg.GetModelConfig("ollama/qwen3:4b")
+	if kept.APIBase != "http://other:11434" {
+		t.Errorf("explicit entry was replaced: %+v", kept)
+	}
+}
+
+func TestDiscoverLocalModels_UnreachableServer(t *testing.T) {
+	cfg := &config.Config{
+		ModelList: []*config.ModelConfig{
+			{ModelName: "ollama", Provider: "ollama", Model: "*", APIBase: "http://127.0.0.1:1"},
+		},
+	}
+
+	DiscoverLocalModels(context.Background(), cfg)
+
+	if len(cfg.ModelList) != 1 {
+		t.Fatalf("len(ModelList) = %d, want template only", len(cfg.ModelList))
+	}
+}
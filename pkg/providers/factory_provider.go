@@ -15,6 +15,7 @@ import (
 	anthropicmessages "github.com/sipeed/picoclaw/pkg/providers/anthropic_messages"
 	"github.com/sipeed/picoclaw/pkg/providers/azure"
 	"github.com/sipeed/picoclaw/pkg/providers/bedrock"
+	"github.com/sipeed/picoclaw/pkg/providers/ollama"
 )
 
 type protocolMeta struct {
@@ -34,6 +35,7 @@ var protocolMetaByName = map[string]protocolMeta{
 	"gemini":                   {defaultAPIBase: "https://generativelanguage.googleapis.com/v1beta"},
 	"nvidia":                   {defaultAPIBase: "https://integrate.api.nvidia.com/v1"},
 	"ollama":                   {defaultAPIBase: "http://localhost:11434/v1", emptyAPIKeyAllowed: true},
+	"ollama-native":            {defaultAPIBase: "http://localhost:11434", emptyAPIKeyAllowed: true},
 	"moonshot":                 {defaultAPIBase: "https://api.moonshot.cn/v1"},
 	"shengsuanyun":             {defaultAPIBase: "https://router.shengsuanyun.com/api/v1"},
 	"deepseek":                 {defaultAPIBase: "https://api.deepseek.com/v1"},
@@ -246,6 +248,28 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		}
 		return provider, modelID, nil
 
+	case "ollama-native":
+		// Native Ollama API (/api/chat). api_base may include the /v1 suffix
+		// of the OpenAI-compatible shim; the provider strips it.
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		opts := []ollama.Option{
+			ollama.WithUserAgent(userAgent),
+			ollama.WithRequestTimeout(time.Duration(cfg.RequestTimeout) * time.Second),
+			ollama.WithExtraBody(cfg.ExtraBody),
+			ollama.WithCustomHeaders(ollamaHeaders(cfg)),
+		}
+		if oc := cfg.Ollama; oc != nil {
+			opts = append(opts,
+				ollama.WithNumCtx(oc.NumCtx),
+				ollama.WithKeepAlive(oc.KeepAlive),
+				ollama.WithAutoPull(oc.AutoPull),
+			)
+		}
+		return ollama.NewProvider(apiBase, cfg.Proxy, opts...), modelID, nil
+
 	case "litellm", "lmstudio", "openrouter", "groq", "zhipu", "nvidia", "venice",
 		"ollama", "moonshot", "shengsuanyun", "deepseek", "cerebras",
 		"vivgrid", "volcengine", "vllm", "qwen", "qwen-portal", "qwen-intl", "qwen-international", "dashscope-intl",
@@ -418,6 +442,26 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 	}
 }
 
+// CanDetectContextWindow reports whether providers created for protocol
+// implement ContextWindowProvider. Callers use it to avoid constructing
+// providers (some of which dial out on creation) just to probe for support.
+func CanDetectContextWindow(protocol string) bool {
+	return NormalizeProvider(protocol) == "ollama-native"
+}
+
+// ollamaHeaders adds a bearer token when an api_key is configured, which is
+// how reverse proxies in front of Ollama are usually secured.
+func ollamaHeaders(cfg *config.ModelConfig) map[string]string {
+	if cfg.APIKey() == "" {
+		return cfg.CustomHeaders
+	}
+	headers := map[string]string{"Authorization": "Bearer " + cfg.APIKey()}
+	for k, v := range cfg.CustomHeaders {
+		headers[k] = v
+	}
+	return headers
+}
+
 func isEmptyAPIKeyAllowed(protocol string) bool {
 	meta, ok := protocolMetaByName[protocol]
 	return ok && meta.emptyAPIKeyAllowed
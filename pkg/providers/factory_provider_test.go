@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers/ollama"
 )
 
 func TestExtractProtocol(t *testing.T) {
@@ -345,6 +346,35 @@ func TestCreateProviderFromConfig_LocalProviders(t *testing.T) {
 	}
 }
 
+func TestCreateProviderFromConfig_OllamaNative(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName: "test-ollama",
+		Model:     "ollama-native/llama3.1:8b",
+		Ollama:    &config.OllamaModelConfig{NumCtx: 8192, KeepAlive: "10m"},
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if modelID != "llama3.1:8b" {
+		t.Errorf("modelID = %q, want %q", modelID, "llama3.1:8b")
+	}
+	op, ok := provider.(*ollama.Provider)
+	if !ok {
+		t.Fatalf("expected *ollama.Provider, got %T", provider)
+	}
+	if got := op.APIBase(); got != "http://localhost:11434" {
+		t.Errorf("APIBase() = %q, want %q", got, "http://localhost:11434")
+	}
+	if _, ok := provider.(ContextWindowProvider); !ok {
+		t.Error("ollama provider should implement ContextWindowProvider")
+	}
+	if _, ok := provider.(StreamingProvider); !ok {
+		t.Error("ollama provider should implement StreamingProvider")
+	}
+}
+
 func TestCreateProviderFromConfig_LongCat(t *testing.T) {
 	cfg := &config.ModelConfig{
 		ModelName: "test-longcat",
@@ -0,0 +1,203 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers/common"
+)
+
+// LocalModel describes a model installed on the Ollama server.
+type LocalModel struct {
+	Name       string `json:"name"`
+	Model      string `json:"model"`
+	Size       int64  `json:"size"`
+	ModifiedAt string `json:"modified_at"`
+	Details    struct {
+		Family            string `json:"family"`
+		ParameterSize     string `json:"parameter_size"`
+		QuantizationLevel string `json:"quantization_level"`
+	} `json:"details"`
+}
+
+// ModelDetails is the subset of /api/show used by PicoClaw.
+type ModelDetails struct {
+	// ContextLength is the architectural maximum reported in model_info.
+	ContextLength int
+	// NumCtx is the num_ctx parameter baked into the Modelfile, if any.
+	NumCtx int
+	// Capabilities lists server-reported capabilities such as "tools" or
+	// "vision" (Ollama 0.6+).
+	Capabilities []string
+}
+
+// ListModels returns the models installed on the server (/api/tags).
+func (p *Provider) ListModels(ctx context.Context) ([]LocalModel, error) {
+	req, err := p.newRequest(ctx, http.MethodGet, "/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, common.HandleErrorResponse(resp, p.apiBase)
+	}
+
+	var out struct {
+		Models []LocalModel `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	return out.Models, nil
+}
+
+// Show returns model metadata from /api/show.
+func (p *Provider) Show(ctx context.Context, model string) (*ModelDetails, error) {
+	payload, err := json.Marshal(map[string]any{"model": model})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := p.newRequest(ctx, http.MethodPost, "/api/show", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, common.HandleErrorResponse(resp, p.apiBase)
+	}
+
+	var out struct {
+		Parameters   string         `json:"parameters"`
+		ModelInfo    map[string]any `json:"model_info"`
+		Capabilities []string       `json:"capabilities"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	details := &ModelDetails{
+		NumCtx:       parseNumCtxParameter(out.Parameters),
+		Capabilities: out.Capabilities,
+	}
+	// model_info keys are architecture-prefixed, e.g. "llama.context_length".
+	for key, value := range out.ModelInfo {
+		if !strings.HasSuffix(key, ".context_length") {
+			continue
+		}
+		if n, ok := common.AsInt(value); ok && n > details.ContextLength {
+			details.ContextLength = n
+		}
+	}
+	return details, nil
+}
+
+// ContextWindow reports the effective context window for model. A num_ctx
+// configured on the provider wins, because that is what every request sends;
+// otherwise the Modelfile num_ctx is used, then the architectural maximum.
+func (p *Provider) ContextWindow(ctx context.Context, model string) (int, error) {
+	if p.numCtx > 0 {
+		return p.numCtx, nil
+	}
+	details, err := p.Show(ctx, model)
+	if err != nil {
+		return 0, err
+	}
+	if details.NumCtx > 0 {
+		return details.NumCtx, nil
+	}
+	if details.ContextLength > 0 {
+		return details.ContextLength, nil
+	}
+	return 0, fmt.Errorf("ollama did not report a context length for %q", model)
+}
+
+// Pull downloads model onto the server and blocks until it completes.
+func (p *Provider) Pull(ctx context.Context, model string) error {
+	payload, err := json.Marshal(map[string]any{"model": model, "stream": false})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := p.newRequest(ctx, http.MethodPost, "/api/pull", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	// Pulls routinely exceed the chat timeout; the caller's ctx bounds them.
+	client := &http.Client{Transport: p.httpClient.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return common.HandleErrorResponse(resp, p.apiBase)
+	}
+
+	var out struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	if out.Error != "" {
+		return fmt.Errorf("ollama error: %s", out.Error)
+	}
+	if out.Status != "" && out.Status != "success" {
+		return fmt.Errorf("unexpected pull status %q", out.Status)
+	}
+	return nil
+}
+
+// ModelMatches reports whether an installed model name satisfies a requested
+// model, treating a missing tag as ":latest".
+func ModelMatches(candidate, want string) bool {
+	candidateBase, candidateTag := splitModel(candidate)
+	wantBase, wantTag := splitModel(want)
+	if candidateBase == "" || wantBase == "" {
+		return false
+	}
+	return strings.EqualFold(candidateBase, wantBase) && strings.EqualFold(candidateTag, wantTag)
+}
+
+func splitModel(raw string) (base, tag string) {
+	base, tag, _ = strings.Cut(strings.TrimSpace(raw), ":")
+	base = strings.TrimSpace(base)
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		tag = "latest"
+	}
+	return base, tag
+}
+
+// parseNumCtxParameter extracts num_ctx from the Modelfile parameter block
+// returned by /api/show ("num_ctx                        8192\n...").
+func parseNumCtxParameter(parameters string) int {
+	for line := range strings.SplitSeq(parameters, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "num_ctx" {
+			continue
+		}
+		if n, err := strconv.Atoi(fields[1]); err == nil {
+			return n
+		}
+	}
+	return 0
+}
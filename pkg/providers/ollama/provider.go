@@ -0,0 +1,574 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package ollama implements the native Ollama HTTP API (/api/chat, /api/show,
+// /api/tags, /api/pull). Unlike the OpenAI-compatible /v1 shim it preserves
+// Ollama-specific request options such as num_ctx and keep_alive and exposes
+// model management endpoints used for discovery and context-size detection.
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/common"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	ToolCall       = protocoltypes.ToolCall
+	FunctionCall   = protocoltypes.FunctionCall
+	LLMResponse    = protocoltypes.LLMResponse
+	UsageInfo      = protocoltypes.UsageInfo
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+)
+
+// DefaultAPIBase is the default local Ollama endpoint.
+const DefaultAPIBase = "http://localhost:11434"
+
+// pullTimeout bounds automatic model pulls, which can take a long time for
+// multi-gigabyte models on slow links.
+const pullTimeout = 30 * time.Minute
+
+// Provider talks to an Ollama server using its native API.
+type Provider struct {
+	apiBase       string
+	httpClient    *http.Client
+	userAgent     string
+	customHeaders map[string]string
+	extraBody     map[string]any
+
+	numCtx    int
+	keepAlive string
+	autoPull  bool
+
+	// pullMu serializes automatic pulls so concurrent turns do not download
+	// the same model twice.
+	pullMu sync.Mutex
+}
+
+type Option func(*Provider)
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(p *Provider) {
+		p.userAgent = userAgent
+	}
+}
+
+// WithRequestTimeout overrides the non-streaming request timeout.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		if timeout > 0 {
+			p.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithCustomHeaders injects additional headers into every request.
+func WithCustomHeaders(customHeaders map[string]string) Option {
+	return func(p *Provider) {
+		p.customHeaders = customHeaders
+	}
+}
+
+// WithExtraBody merges additional top-level fields into /api/chat requests.
+func WithExtraBody(extraBody map[string]any) Option {
+	return func(p *Provider) {
+		p.extraBody = extraBody
+	}
+}
+
+// WithNumCtx sets options.num_ctx for every chat request. Zero keeps the
+// server or Modelfile default.
+func WithNumCtx(numCtx int) Option {
+	return func(p *Provider) {
+		if numCtx > 0 {
+			p.numCtx = numCtx
+		}
+	}
+}
+
+// WithKeepAlive sets how long Ollama keeps the model loaded after a request
+// (e.g. "10m", "-1" to keep forever, "0" to unload immediately).
+func WithKeepAlive(keepAlive string) Option {
+	return func(p *Provider) {
+		p.keepAlive = strings.TrimSpace(keepAlive)
+	}
+}
+
+// WithAutoPull enables pulling a missing model on first use.
+func WithAutoPull(autoPull bool) Option {
+	return func(p *Provider) {
+		p.autoPull = autoPull
+	}
+}
+
+// NewProvider creates a native Ollama provider. apiBase may be given with or
+// without the OpenAI-compatible "/v1" suffix; it is normalized to the server
+// root so existing configs keep working.
+func NewProvider(apiBase, proxy string, opts ...Option) *Provider {
+	p := &Provider{
+		apiBase:    NormalizeAPIBase(apiBase),
+		httpClient: common.NewHTTPClient(proxy),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+	return p
+}
+
+// NormalizeAPIBase strips OpenAI-compatible and API path suffixes from an
+// Ollama endpoint so native paths can be joined onto it.
+func NormalizeAPIBase(apiBase string) string {
+	base := strings.TrimRight(strings.TrimSpace(apiBase), "/")
+	if base == "" {
+		return DefaultAPIBase
+	}
+	for _, suffix := range []string{"/v1", "/api"} {
+		base = strings.TrimSuffix(base, suffix)
+	}
+	return strings.TrimRight(base, "/")
+}
+
+// APIBase returns the normalized server root used by this provider.
+func (p *Provider) APIBase() string {
+	return p.apiBase
+}
+
+// GetDefaultModel returns an empty string; Ollama has no implicit model.
+func (p *Provider) GetDefaultModel() string {
+	return ""
+}
+
+// Chat sends a non-streaming /api/chat request.
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	body := p.buildRequestBody(messages, tools, model, options, false)
+
+	resp, err := p.postChat(ctx, p.httpClient, model, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var chunk chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chunk); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	if chunk.Error != "" {
+		return nil, fmt.Errorf("ollama error: %s", chunk.Error)
+	}
+
+	acc := newStreamAccumulator()
+	acc.add(chunk)
+	return acc.response(), nil
+}
+
+// ChatStream sends a streaming /api/chat request. Ollama streams
+// newline-delimited JSON objects; onChunk receives the accumulated text.
+func (p *Provider) ChatStream(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onChunk func(accumulated string),
+) (*LLMResponse, error) {
+	body := p.buildRequestBody(messages, tools, model, options, true)
+
+	// Streaming responses can outlive the client timeout; rely on ctx instead.
+	streamClient := &http.Client{Transport: p.httpClient.Transport}
+	resp, err := p.postChat(ctx, streamClient, model, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return parseStream(ctx, resp.Body, onChunk)
+}
+
+// postChat posts a chat request and, when auto-pull is enabled, pulls a
+// missing model and retries once.
+func (p *Provider) postChat(
+	ctx context.Context,
+	client *http.Client,
+	model string,
+	body map[string]any,
+) (*http.Response, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := p.newRequest(ctx, http.MethodPost, "/api/chat", bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+
+		if resp.StatusCode == http.StatusNotFound && p.autoPull && attempt == 0 {
+			errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			resp.Body.Close()
+			if !isModelNotFound(errBody) {
+				return nil, fmt.Errorf(
+					"API request failed:\n  Status: %d\n  Body:   %s",
+					resp.StatusCode,
+					common.ResponsePreview(errBody, 128),
+				)
+			}
+			if err := p.pullOnce(ctx, model); err != nil {
+				return nil, fmt.Errorf("auto-pull of ollama model %q failed: %w", model, err)
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		return nil, common.HandleErrorResponse(resp, p.apiBase)
+	}
+}
+
+func (p *Provider) pullOnce(ctx context.Context, model string) error {
+	p.pullMu.Lock()
+	defer p.pullMu.Unlock()
+
+	// Another turn may have finished pulling while we waited.
+	if models, err := p.ListModels(ctx); err == nil {
+		for _, m := range models {
+			if ModelMatches(m.Name, model) {
+				return nil
+			}
+		}
+	}
+
+	pullCtx, cancel := context.WithTimeout(ctx, pullTimeout)
+	defer cancel()
+	return p.Pull(pullCtx, model)
+}
+
+func isModelNotFound(body []byte) bool {
+	lower := strings.ToLower(string(body))
+	return strings.Contains(lower, "not found") || strings.Contains(lower, "pull")
+}
+
+func (p *Provider) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.apiBase+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.userAgent != "" {
+		req.Header.Set("User-Agent", p.userAgent)
+	}
+	for k, v := range p.customHeaders {
+		if strings.TrimSpace(k) == "" {
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+// buildRequestBody converts internal messages and options into an /api/chat
+// request body.
+func (p *Provider) buildRequestBody(
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	stream bool,
+) map[string]any {
+	body := map[string]any{
+		"model":    model,
+		"messages": serializeMessages(messages),
+		"stream":   stream,
+	}
+	if len(tools) > 0 {
+		body["tools"] = serializeTools(tools)
+	}
+
+	modelOptions := map[string]any{}
+	if p.numCtx > 0 {
+		modelOptions["num_ctx"] = p.numCtx
+	}
+	if maxTokens, ok := common.AsInt(options["max_tokens"]); ok && maxTokens > 0 {
+		modelOptions["num_predict"] = maxTokens
+	}
+	if temperature, ok := common.AsFloat(options["temperature"]); ok {
+		modelOptions["temperature"] = temperature
+	}
+	if len(modelOptions) > 0 {
+		body["options"] = modelOptions
+	}
+	if p.keepAlive != "" {
+		body["keep_alive"] = p.keepAlive
+	}
+
+	// Extra body fields take precedence, but an "options" object is merged
+	// into the computed options instead of replacing them.
+	for k, v := range p.extraBody {
+		if k == "options" {
+			if extra, ok := v.(map[string]any); ok {
+				merged := map[string]any{}
+				maps.Copy(merged, modelOptions)
+				maps.Copy(merged, extra)
+				body["options"] = merged
+				continue
+			}
+		}
+		body[k] = v
+	}
+
+	return body
+}
+
+type wireMessage struct {
+	Role      string         `json:"role"`
+	Content   string         `json:"content"`
+	Thinking  string         `json:"thinking,omitempty"`
+	Images    []string       `json:"images,omitempty"`
+	ToolCalls []wireToolCall `json:"tool_calls,omitempty"`
+	ToolName  string         `json:"tool_name,omitempty"`
+}
+
+type wireToolCall struct {
+	ID       string           `json:"id,omitempty"`
+	Function wireFunctionCall `json:"function"`
+}
+
+type wireFunctionCall struct {
+	Index     *int           `json:"index,omitempty"`
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+func serializeMessages(messages []Message) []wireMessage {
+	// Ollama identifies tool results by tool name rather than call ID, so
+	// remember which name each call ID belonged to.
+	toolNames := map[string]string{}
+
+	out := make([]wireMessage, 0, len(messages))
+	for _, m := range messages {
+		wm := wireMessage{
+			Role:     m.Role,
+			Content:  m.Content,
+			Thinking: m.ReasoningContent,
+		}
+		for _, mediaURL := range m.Media {
+			if data, ok := imageDataFromURL(mediaURL); ok {
+				wm.Images = append(wm.Images, data)
+			}
+		}
+		for _, tc := range m.ToolCalls {
+			name, args := toolCallNameAndArgs(tc)
+			if name == "" {
+				continue
+			}
+			if tc.ID != "" {
+				toolNames[tc.ID] = name
+			}
+			wm.ToolCalls = append(wm.ToolCalls, wireToolCall{
+				ID:       tc.ID,
+				Function: wireFunctionCall{Name: name, Arguments: args},
+			})
+		}
+		if m.ToolCallID != "" {
+			wm.Role = "tool"
+			wm.ToolName = toolNames[m.ToolCallID]
+		}
+		out = append(out, wm)
+	}
+	return out
+}
+
+func toolCallNameAndArgs(tc ToolCall) (string, map[string]any) {
+	name := tc.Name
+	args := tc.Arguments
+	if tc.Function != nil {
+		if name == "" {
+			name = tc.Function.Name
+		}
+		if args == nil && tc.Function.Arguments != "" {
+			_ = json.Unmarshal([]byte(tc.Function.Arguments), &args)
+		}
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+	return name, args
+}
+
+// imageDataFromURL returns the raw base64 payload of a data:image/... URL,
+// which is the format Ollama expects in the images array.
+func imageDataFromURL(mediaURL string) (string, bool) {
+	if !strings.HasPrefix(mediaURL, "data:image/") {
+		return "", false
+	}
+	_, data, ok := strings.Cut(mediaURL, ";base64,")
+	if !ok || data == "" {
+		return "", false
+	}
+	return data, true
+}
+
+func serializeTools(tools []ToolDefinition) []any {
+	out := make([]any, 0, len(tools))
+	for _, tool := range tools {
+		params := tool.Function.Parameters
+		if params == nil {
+			params = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		out = append(out, map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name":        tool.Function.Name,
+				"description": tool.Function.Description,
+				"parameters":  params,
+			},
+		})
+	}
+	return out
+}
+
+type chatResponse struct {
+	Model   string `json:"model"`
+	Message struct {
+		Role      string         `json:"role"`
+		Content   string         `json:"content"`
+		Thinking  string         `json:"thinking"`
+		ToolCalls []wireToolCall `json:"tool_calls"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error"`
+}
+
+type streamAccumulator struct {
+	content   strings.Builder
+	thinking  strings.Builder
+	toolCalls []ToolCall
+	reason    string
+	usage     *UsageInfo
+}
+
+func newStreamAccumulator() *streamAccumulator {
+	return &streamAccumulator{}
+}
+
+func (a *streamAccumulator) add(chunk chatResponse) {
+	a.content.WriteString(chunk.Message.Content)
+	a.thinking.WriteString(chunk.Message.Thinking)
+	for _, tc := range chunk.Message.ToolCalls {
+		args := tc.Function.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		id := tc.ID
+		if id == "" {
+			id = fmt.Sprintf("call_%d", len(a.toolCalls)+1)
+		}
+		argsJSON, _ := json.Marshal(args)
+		a.toolCalls = append(a.toolCalls, ToolCall{
+			ID:        id,
+			Type:      "function",
+			Name:      tc.Function.Name,
+			Arguments: args,
+			Function: &FunctionCall{
+				Name:      tc.Function.Name,
+				Arguments: string(argsJSON),
+			},
+		})
+	}
+	if chunk.Done {
+		a.reason = chunk.DoneReason
+		a.usage = &UsageInfo{
+			PromptTokens:     chunk.PromptEvalCount,
+			CompletionTokens: chunk.EvalCount,
+			TotalTokens:      chunk.PromptEvalCount + chunk.EvalCount,
+		}
+	}
+}
+
+func (a *streamAccumulator) response() *LLMResponse {
+	finishReason := "stop"
+	switch {
+	case len(a.toolCalls) > 0:
+		finishReason = "tool_calls"
+	case a.reason == "length":
+		finishReason = "length"
+	}
+	return &LLMResponse{
+		Content:          a.content.String(),
+		ReasoningContent: a.thinking.String(),
+		ToolCalls:        a.toolCalls,
+		FinishReason:     finishReason,
+		Usage:            a.usage,
+	}
+}
+
+func parseStream(ctx context.Context, reader io.Reader, onChunk func(accumulated string)) (*LLMResponse, error) {
+	acc := newStreamAccumulator()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk chatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			continue // skip malformed chunks
+		}
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("ollama error: %s", chunk.Error)
+		}
+
+		acc.add(chunk)
+		if chunk.Message.Content != "" && onChunk != nil {
+			onChunk(acc.content.String())
+		}
+		if chunk.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("streaming read error: %w", err)
+	}
+
+	return acc.response(), nil
+}
@@ -0,0 +1,258 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+func TestNormalizeAPIBase(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", DefaultAPIBase},
+		{"http://localhost:11434/v1", "http://localhost:11434"},
+		{"http://localhost:11434/v1/", "http://localhost:11434"},
+		{"http://box:11434/api", "http://box:11434"},
+		{"http://box:11434", "http://box:11434"},
+		{"https://proxy.example.com/ollama/v1", "https://proxy.example.com/ollama"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeAPIBase(tt.in); got != tt.want {
+			t.Errorf("NormalizeAPIBase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestChat_BuildsNativeRequest(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %q, want /api/chat", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		fmt.Fprint(w, `{"message":{"role":"assistant","content":"hi"},"done":true,"done_reason":"stop",`+
+			`"prompt_eval_count":12,"eval_count":3}`)
+	}))
+	defer server.Close()
+
+	p := NewProvider(server.URL+"/v1", "", WithNumCtx(8192), WithKeepAlive("10m"))
+	messages := []protocoltypes.Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "look", Media: []string{"data:image/png;base64,QUJD"}},
+		{
+			Role: "assistant",
+			ToolCalls: []protocoltypes.ToolCall{
+				{ID: "call_1", Name: "read_file", Arguments: map[string]any{"path": "a.txt"}},
+			},
+		},
+		{Role: "tool", ToolCallID: "call_1", Content: "file body"},
+	}
+	tools := []protocoltypes.ToolDefinition{{
+		Type: "function",
+		Function: protocoltypes.ToolFunctionDefinition{
+			Name:       "read_file",
+			Parameters: map[string]any{"type": "object"},
+		},
+	}}
+
+	resp, err := p.Chat(context.Background(), messages, tools, "llama3.2", map[string]any{
+		"max_tokens":  256,
+		"temperature": 0.2,
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "hi" || resp.FinishReason != "stop" {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 15 {
+		t.Fatalf("usage = %+v, want total 15", resp.Usage)
+	}
+
+	if got["stream"] != false {
+		t.Errorf("stream = %v, want false", got["stream"])
+	}
+	if got["keep_alive"] != "10m" {
+		t.Errorf("keep_alive = %v, want 10m", got["keep_alive"])
+	}
+	opts, _ := got["options"].(map[string]any)
+	if opts["num_ctx"] != float64(8192) || opts["num_predict"] != float64(256) || opts["temperature"] != 0.2 {
+		t.Errorf("options = %v", opts)
+	}
+	msgs, _ := got["messages"].([]any)
+	if len(msgs) != 4 {
+		t.Fatalf("messages len = %d, want 4", len(msgs))
+	}
+	user := msgs[1].(map[string]any)
+	if images, _ := user["images"].([]any); len(images) != 1 || images[0] != "QUJD" {
+		t.Errorf("images = %v, want [QUJD]", user["images"])
+	}
+	assistant := msgs[2].(map[string]any)
+	calls, _ := assistant["tool_calls"].([]any)
+	if len(calls) != 1 {
+		t.Fatalf("tool_calls = %v", assistant["tool_calls"])
+	}
+	fn := calls[0].(map[string]any)["function"].(map[string]any)
+	if fn["name"] != "read_file" || fn["arguments"].(map[string]any)["path"] != "a.txt" {
+		t.Errorf("function = %v", fn)
+	}
+	tool := msgs[3].(map[string]any)
+	if tool["role"] != "tool" || tool["tool_name"] != "read_file" {
+		t.Errorf("tool message = %v", tool)
+	}
+}
+
+func TestChatStream_AccumulatesContentAndToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["stream"] != true {
+			t.Errorf("stream = %v, want true", req["stream"])
+		}
+		lines := []string{
+			`{"message":{"role":"assistant","content":"Hel"},"done":false}`,
+			`{"message":{"role":"assistant","content":"lo"},"done":false}`,
+			`{"message":{"role":"assistant","content":"","tool_calls":[` +
+				`{"function":{"name":"exec","arguments":{"cmd":"ls"}}}]},"done":false}`,
+			`{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop",` +
+				`"prompt_eval_count":5,"eval_count":7}`,
+		}
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider(server.URL, "")
+	var chunks []string
+	resp, err := p.ChatStream(context.Background(), []protocoltypes.Message{{Role: "user", Content: "hi"}},
+		nil, "qwen3", nil, func(acc string) { chunks = append(chunks, acc) })
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if resp.Content != "Hello" {
+		t.Errorf("Content = %q, want Hello", resp.Content)
+	}
+	if strings.Join(chunks, "|") != "Hel|Hello" {
+		t.Errorf("chunks = %v", chunks)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "exec" || resp.ToolCalls[0].ID == "" {
+		t.Fatalf("ToolCalls = %+v", resp.ToolCalls)
+	}
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", resp.FinishReason)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 12 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
+
+func TestChat_AutoPullsMissingModel(t *testing.T) {
+	var pulled atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/chat":
+			if !pulled.Load() {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"error":"model \"phi4\" not found, try pulling it first"}`)
+				return
+			}
+			fmt.Fprint(w, `{"message":{"role":"assistant","content":"ok"},"done":true}`)
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[]}`)
+		case "/api/pull":
+			var req map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req["model"] != "phi4" {
+				t.Errorf("pull model = %v, want phi4", req["model"])
+			}
+			pulled.Store(true)
+			fmt.Fprint(w, `{"status":"success"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider(server.URL, "", WithAutoPull(true))
+	resp, err := p.Chat(context.Background(), []protocoltypes.Message{{Role: "user", Content: "hi"}}, nil, "phi4", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "ok" || !pulled.Load() {
+		t.Fatalf("resp = %+v, pulled = %v", resp, pulled.Load())
+	}
+}
+
+func TestChat_MissingModelWithoutAutoPull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/pull" {
+			t.Error("unexpected pull without auto_pull")
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"model not found"}`)
+	}))
+	defer server.Close()
+
+	p := NewProvider(server.URL, "")
+	_, err := p.Chat(context.Background(), []protocoltypes.Message{{Role: "user", Content: "hi"}}, nil, "phi4", nil)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("Chat() error = %v, want 404 error", err)
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req["model"] {
+		case "tuned":
+			fmt.Fprint(w, `{"parameters":"stop \"<|im_end|>\"\nnum_ctx 16384","model_info":{"qwen2.context_length":32768}}`)
+		case "plain":
+			fmt.Fprint(w, `{"model_info":{"llama.context_length":131072}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"not found"}`)
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider(server.URL, "")
+	if got, err := p.ContextWindow(context.Background(), "tuned"); err != nil || got != 16384 {
+		t.Errorf("ContextWindow(tuned) = %d, %v; want 16384", got, err)
+	}
+	if got, err := p.ContextWindow(context.Background(), "plain"); err != nil || got != 131072 {
+		t.Errorf("ContextWindow(plain) = %d, %v; want 131072", got, err)
+	}
+	if _, err := p.ContextWindow(context.Background(), "missing"); err == nil {
+		t.Error("ContextWindow(missing) error = nil, want error")
+	}
+
+	configured := NewProvider(server.URL, "", WithNumCtx(4096))
+	if got, err := configured.ContextWindow(context.Background(), "plain"); err != nil || got != 4096 {
+		t.Errorf("ContextWindow with num_ctx = %d, %v; want 4096", got, err)
+	}
+}
+
+func TestModelMatches(t *testing.T) {
+	if !ModelMatches("llama3:latest", "llama3") {
+		t.Error("llama3:latest should match llama3")
+	}
+	if ModelMatches("llama3:8b", "llama3") {
+		t.Error("llama3:8b should not match llama3 (implicit latest)")
+	}
+	if !ModelMatches("Qwen3:4B", "qwen3:4b") {
+		t.Error("match should be case-insensitive")
+	}
+}
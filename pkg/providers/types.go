@@ -68,6 +68,13 @@ type NativeSearchCapable interface {
 	SupportsNativeSearch() bool
 }
 
+// ContextWindowProvider is an optional interface for providers that can
+// report a model's context window at runtime (e.g. Ollama via /api/show).
+// The agent uses it when agents.defaults.context_window is not configured.
+type ContextWindowProvider interface {
+	ContextWindow(ctx context.Context, model string) (int, error)
+}
+
 // FailoverReason classifies why an LLM request failed for fallback decisions.
 type FailoverReason string
 
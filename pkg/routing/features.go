@@ -125,3 +125,29 @@ func hasAttachments(msg string) bool {
 
 	return false
 }
+
+// currentMessageHasMedia reports whether the most recent user message in
+// history carries media refs. Channels attach images and audio through
+// Message.Media rather than the text, which hasAttachments cannot see.
+func currentMessageHasMedia(history []providers.Message) bool {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			return len(history[i].Media) > 0
+		}
+	}
+	return false
+}
+
+// estimatePromptTokens approximates the size of the whole prompt, used to
+// skip tiers whose context window is too small.
+func estimatePromptTokens(msg string, history []providers.Message) int {
+	total := 0
+	for _, m := range history {
+		total += estimateTokens(m.Content)
+	}
+	// The agent passes the built prompt, which already ends with msg.
+	if n := len(history); n == 0 || history[n-1].Content != msg {
+		total += estimateTokens(msg)
+	}
+	return total
+}
@@ -0,0 +1,171 @@
+package routing
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	defaultJudgeTimeout   = 5 * time.Second
+	defaultJudgeCacheSize = 256
+	defaultJudgeCacheTTL  = time.Hour
+
+	// judgeMaxRunes caps how much of the message and the preceding assistant
+	// reply the judge sees; complexity is evident well before that.
+	judgeMaxRunes = 2000
+	judgeMaxReply = 500
+)
+
+const judgePrompt = `You grade how demanding a user's request is for an AI assistant.
+Reply with a single integer from 0 to 10 and nothing else.
+0-2: greetings, small talk, simple facts, short confirmations.
+3-5: everyday questions, short writing or summaries, simple lookups.
+6-8: multi-step reasoning, non-trivial code, analysis, planning.
+9-10: hard problems, large code changes, long documents, research.`
+
+var judgeNumber = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// JudgeConfig configures a Judge.
+type JudgeConfig struct {
+	// Model is the model ID passed to the provider.
+	Model string
+	// Timeout bounds a single grading call. Defaults to 5s.
+	Timeout time.Duration
+	// CacheSize is the number of grades kept in memory. Defaults to 256;
+	// a negative value disables the cache.
+	CacheSize int
+	// CacheTTL is how long a cached grade stays valid. Defaults to 1h.
+	CacheTTL time.Duration
+}
+
+// Judge grades message complexity by asking a small model. Grades are
+// cached by message content so repeated prompts cost nothing.
+// It is safe for concurrent use.
+type Judge struct {
+	provider providers.LLMProvider
+	model    string
+	timeout  time.Duration
+	cache    *lruCache[[sha256.Size]byte, float64]
+}
+
+// NewJudge creates a Judge that queries provider with cfg.Model.
+func NewJudge(provider providers.LLMProvider, cfg JudgeConfig) *Judge {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultJudgeTimeout
+	}
+	if cfg.CacheSize == 0 {
+		cfg.CacheSize = defaultJudgeCacheSize
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultJudgeCacheTTL
+	}
+	j := &Judge{provider: provider, model: cfg.Model, timeout: cfg.Timeout}
+	if cfg.CacheSize > 0 {
+		j.cache = newLRUCache[[sha256.Size]byte, float64](cfg.CacheSize, cfg.CacheTTL)
+	}
+	return j
+}
+
+// Grade returns the complexity score in [0, 1] for msg. The previous
+// assistant reply is included so that short follow-ups such as "yes, do it"
+// inherit the weight of what they refer to. cached reports whether the
+// score came from the cache.
+func (j *Judge) Grade(ctx context.Context, msg string, history []providers.Message) (score float64, cached bool, err error) {
+	msg = truncateRunes(strings.TrimSpace(msg), judgeMaxRunes)
+	prev := truncateRunes(previousAssistantReply(history, msg), judgeMaxReply)
+
+	key := cacheKey(prev, msg)
+	if j.cache != nil {
+		if s, ok := j.cache.get(key); ok {
+			return s, true, nil
+		}
+	}
+
+	var prompt strings.Builder
+	if prev != "" {
+		prompt.WriteString("Previous assistant reply:\n")
+		prompt.WriteString(prev)
+		prompt.WriteString("\n\n")
+	}
+	prompt.WriteString("User request:\n")
+	prompt.WriteString(msg)
+
+	ctx, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+	resp, err := j.provider.Chat(ctx, []providers.Message{
+		{Role: "system", Content: judgePrompt},
+		{Role: "user", Content: prompt.String()},
+	}, nil, j.model, map[string]any{
+		"max_tokens":  16,
+		"temperature": 0.0,
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("routing judge: %w", err)
+	}
+	if resp == nil {
+		return 0, false, errors.New("routing judge: empty response")
+	}
+
+	score, err = parseGrade(resp.Content)
+	if err != nil {
+		return 0, false, err
+	}
+	if j.cache != nil {
+		j.cache.put(key, score)
+	}
+	return score, false, nil
+}
+
+// parseGrade extracts the first number from the judge's reply and maps the
+// 0–10 scale onto [0, 1].
+func parseGrade(reply string) (float64, error) {
+	m := judgeNumber.FindString(reply)
+	if m == "" {
+		return 0, fmt.Errorf("routing judge: no grade in reply %q", truncateRunes(reply, 80))
+	}
+	n, err := strconv.ParseFloat(m, 64)
+	if err != nil {
+		return 0, fmt.Errorf("routing judge: %w", err)
+	}
+	return min(max(n, 0), 10) / 10, nil
+}
+
+// previousAssistantReply returns the last assistant text before the current
+// user message. The agent passes the built prompt, which ends with msg.
+func previousAssistantReply(history []providers.Message, msg string) string {
+	end := len(history)
+	if end > 0 && history[end-1].Role == "user" && strings.TrimSpace(history[end-1].Content) == msg {
+		end--
+	}
+	for i := end - 1; i >= 0; i-- {
+		switch history[i].Role {
+		case "assistant":
+			if history[i].Content != "" {
+				return strings.TrimSpace(history[i].Content)
+			}
+		case "user":
+			return ""
+		}
+	}
+	return ""
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+func cacheKey(prev, msg string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(prev + "\x00" + msg))
+}
@@ -0,0 +1,147 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type judgeProvider struct {
+	reply string
+	err   error
+	calls int
+	last  []providers.Message
+}
+
+func (p *judgeProvider) Chat(
+	_ context.Context,
+	messages []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	p.calls++
+	p.last = messages
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &providers.LLMResponse{Content: p.reply}, nil
+}
+
+func (p *judgeProvider) GetDefaultModel() string { return "judge" }
+
+func TestParseGrade(t *testing.T) {
+	cases := []struct {
+		reply string
+		want  float64
+	}{
+		{"7", 0.7},
+		{" 3\n", 0.3},
+		{"Score: 10", 1.0},
+		{"42", 1.0},
+		{"2.5", 0.25},
+	}
+	for _, tc := range cases {
+		got, err := parseGrade(tc.reply)
+		if err != nil {
+			t.Errorf("reply %q: unexpected error %v", tc.reply, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("reply %q: got %f, want %f", tc.reply, got, tc.want)
+		}
+	}
+	if _, err := parseGrade("hard"); err == nil {
+		t.Error("reply without a number: expected error")
+	}
+}
+
+func TestJudge_CachesGrades(t *testing.T) {
+	p := &judgeProvider{reply: "8"}
+	j := NewJudge(p, JudgeConfig{Model: "mini"})
+
+	score, cached, err := j.Grade(context.Background(), "refactor this module", nil)
+	if err != nil || cached || score != 0.8 {
+		t.Fatalf("first grade: got (%f, %v, %v), want (0.8, false, nil)", score, cached, err)
+	}
+	score, cached, err = j.Grade(context.Background(), "refactor this module", nil)
+	if err != nil || !cached || score != 0.8 {
+		t.Fatalf("second grade: got (%f, %v, %v), want (0.8, true, nil)", score, cached, err)
+	}
+	if p.calls != 1 {
+		t.Errorf("provider calls: got %d, want 1", p.calls)
+	}
+}
+
+func TestJudge_IncludesPreviousReply(t *testing.T) {
+	p := &judgeProvider{reply: "6"}
+	j := NewJudge(p, JudgeConfig{Model: "mini", CacheSize: -1})
+	history := []providers.Message{
+		{Role: "user", Content: "plan a migration"},
+		{Role: "assistant", Content: "Here is a five-step plan."},
+		{Role: "user", Content: "yes, do it"},
+	}
+	if _, _, err := j.Grade(context.Background(), "yes, do it", history); err != nil {
+		t.Fatal(err)
+	}
+	want := "Previous assistant reply:\nHere is a five-step plan.\n\nUser request:\nyes, do it"
+	if got := p.last[1].Content; got != want {
+		t.Errorf("judge prompt: got %q, want %q", got, want)
+	}
+}
+
+func TestLRUCache_EvictsAndExpires(t *testing.T) {
+	c := newLRUCache[[32]byte, float64](2, time.Minute)
+	now := time.Unix(1000, 0)
+	c.now = func() time.Time { return now }
+
+	a, b, d := cacheKey("", "a"), cacheKey("", "b"), cacheKey("", "d")
+	c.put(a, 0.1)
+	c.put(b, 0.2)
+	c.get(a) // a becomes most recently used
+	c.put(d, 0.3)
+	if _, ok := c.get(b); ok {
+		t.Error("least recently used entry should be evicted")
+	}
+	if _, ok := c.get(a); !ok {
+		t.Error("recently used entry should be kept")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.get(d); ok {
+		t.Error("expired entry should be dropped")
+	}
+}
+
+func TestRouter_Select_UsesJudge(t *testing.T) {
+	p := &judgeProvider{reply: "9"}
+	r := newWithClassifier(RouterConfig{
+		Tiers: threeTiers(),
+		Judge: NewJudge(p, JudgeConfig{Model: "mini"}),
+	}, &fixedScoreClassifier{score: 0.0})
+
+	d := r.Select(context.Background(), "hi", nil)
+	if d.Tier != "frontier" || d.Source != SourceJudge {
+		t.Errorf("judge: got tier %q via %q, want frontier via judge", d.Tier, d.Source)
+	}
+	d = r.Select(context.Background(), "hi", nil)
+	if d.Source != SourceJudgeCache {
+		t.Errorf("repeat: source got %q, want %q", d.Source, SourceJudgeCache)
+	}
+}
+
+func TestRouter_Select_JudgeFailureFallsBackToRules(t *testing.T) {
+	p := &judgeProvider{err: errors.New("boom")}
+	r := newWithClassifier(RouterConfig{
+		Tiers: threeTiers(),
+		Judge: NewJudge(p, JudgeConfig{Model: "mini"}),
+	}, &fixedScoreClassifier{score: 0.1})
+
+	d := r.Select(context.Background(), "hi", nil)
+	if d.Tier != "local" || d.Source != SourceRules || d.JudgeErr == nil {
+		t.Errorf("fallback: got tier %q via %q (err %v), want local via rules with error", d.Tier, d.Source, d.JudgeErr)
+	}
+}
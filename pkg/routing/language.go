@@ -0,0 +1,102 @@
+package routing
+
+import (
+	"strings"
+	"unicode"
+)
+
+// scriptLanguages maps scripts used by essentially one language to its
+// ISO 639-1 code. Han is handled separately because kana marks Japanese.
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	code  string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// latinStopwords holds frequent function words used to tell Latin-script
+// languages apart. Words shared by several languages are left out.
+var latinStopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "you", "what", "how", "this", "with", "please", "can", "my"},
+	"es": {"el", "los", "las", "es", "por", "para", "que", "qué", "cómo", "con", "una", "mi"},
+	"fr": {"le", "les", "est", "et", "pour", "que", "vous", "avec", "une", "mon", "je", "pas"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ich", "mit", "ein", "eine", "wie", "bitte"},
+	"pt": {"o", "os", "as", "é", "não", "para", "com", "uma", "você", "meu", "como", "obrigado"},
+	"it": {"il", "gli", "è", "non", "per", "che", "con", "una", "sono", "come", "grazie", "mio"},
+}
+
+// detectLanguage guesses the language of text from its script and, for Latin
+// text, from common function words. It returns an ISO 639-1 code or "" when
+// the text gives no clear signal. Like the routing features, it avoids any
+// external model so dispatch stays instant.
+func detectLanguage(text string) string {
+	var han, kana, latin int
+	scripts := make(map[string]int)
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		default:
+			for _, s := range scriptLanguages {
+				if unicode.Is(s.table, r) {
+					scripts[s.code]++
+					break
+				}
+			}
+		}
+	}
+
+	best, bestCount := "", 0
+	if kana > 0 {
+		best, bestCount = "ja", kana+han
+	} else if han > 0 {
+		best, bestCount = "zh", han
+	}
+	for code, n := range scripts {
+		if n > bestCount {
+			best, bestCount = code, n
+		}
+	}
+	// CJK runes carry roughly a word each, so compare them with Latin words
+	// rather than letters.
+	if best != "" && bestCount*4 >= latin {
+		return best
+	}
+	if latin == 0 {
+		return ""
+	}
+	return detectLatinLanguage(text)
+}
+
+func detectLatinLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	counts := make(map[string]int)
+	for _, w := range words {
+		for code, stop := range latinStopwords {
+			for _, s := range stop {
+				if w == s {
+					counts[code]++
+				}
+			}
+		}
+	}
+	best, bestCount := "", 0
+	for code, n := range counts {
+		if n > bestCount || n == bestCount && code < best {
+			best, bestCount = code, n
+		}
+	}
+	return best
+}
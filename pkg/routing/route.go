@@ -1,11 +1,15 @@
 package routing
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
 // SessionPolicy describes how a routed message should be mapped to a session.
@@ -23,9 +27,25 @@ type ResolvedRoute struct {
 	MatchedBy     string
 }
 
+// DispatchMessage carries the message facts that content-aware selectors and
+// triage look at. The zero value only satisfies context selectors.
+type DispatchMessage struct {
+	Text string
+	// Attachments lists the kinds of attached media: image, audio, video or file.
+	Attachments []string
+	// Time is when the message arrived; zero means now.
+	Time time.Time
+}
+
 // RouteResolver determines which agent handles a message.
 type RouteResolver struct {
-	cfg *config.Config
+	cfg    *config.Config
+	triage *Triage
+
+	// patterns and locations cache compiled regex selectors and time zones;
+	// invalid entries are stored as nil so they are reported only once.
+	patterns  sync.Map
+	locations sync.Map
 }
 
 // NewRouteResolver creates a new route resolver.
@@ -33,14 +53,39 @@ func NewRouteResolver(cfg *config.Config) *RouteResolver {
 	return &RouteResolver{cfg: cfg}
 }
 
+// SetTriage enables model-based agent selection for messages that no
+// dispatch rule matches.
+func (r *RouteResolver) SetTriage(t *Triage) {
+	r.triage = t
+}
+
 // ResolveRoute determines which agent handles the message from a normalized
 // inbound context and returns the session policy that should be used to
 // allocate session state.
 func (r *RouteResolver) ResolveRoute(inbound bus.InboundContext) ResolvedRoute {
+	return r.ResolveMessageRoute(context.Background(), inbound, DispatchMessage{})
+}
+
+// ResolveMessageRoute is ResolveRoute with the message content available, so
+// content selectors can match and triage can run when no rule does.
+func (r *RouteResolver) ResolveMessageRoute(
+	ctx context.Context,
+	inbound bus.InboundContext,
+	msg DispatchMessage,
+) ResolvedRoute {
 	channel := strings.ToLower(strings.TrimSpace(inbound.Channel))
 	accountID := NormalizeAccountID(inbound.Account)
 	identityLinks := cloneIdentityLinks(r.cfg.Session.IdentityLinks)
 	view := buildDispatchView(inbound, identityLinks)
+	view.Text = strings.TrimSpace(msg.Text)
+	view.Attachments = msg.Attachments
+	view.Time = msg.Time
+	if view.Time.IsZero() {
+		view.Time = time.Now()
+	}
+	if r.cfg.Agents.Dispatch != nil {
+		view.Roles = senderRoles(r.cfg.Agents.Dispatch.Roles, inbound.Channel, inbound.SenderID, view.Sender)
+	}
 
 	if rule := r.matchDispatchRule(view); rule != nil {
 		return ResolvedRoute{
@@ -52,6 +97,16 @@ func (r *RouteResolver) ResolveRoute(inbound bus.InboundContext) ResolvedRoute {
 		}
 	}
 
+	if agentID := r.triageAgent(ctx, inbound, view); agentID != "" {
+		return ResolvedRoute{
+			AgentID:       agentID,
+			Channel:       channel,
+			AccountID:     accountID,
+			SessionPolicy: r.sessionPolicy(nil),
+			MatchedBy:     "dispatch.triage",
+		}
+	}
+
 	return ResolvedRoute{
 		AgentID:       r.pickAgentID(r.resolveDefaultAgentID()),
 		Channel:       channel,
@@ -61,6 +116,25 @@ func (r *RouteResolver) ResolveRoute(inbound bus.InboundContext) ResolvedRoute {
 	}
 }
 
+// triageAgent asks the triage model to pick an agent. It returns "" when
+// triage is off, has nothing to choose from or declines to pick.
+func (r *RouteResolver) triageAgent(ctx context.Context, inbound bus.InboundContext, view dispatchView) string {
+	if r.triage == nil || view.Text == "" || len(r.cfg.Agents.List) < 2 {
+		return ""
+	}
+	scope := strings.Join([]string{view.Channel, view.Account, view.Chat, view.Sender, inbound.MessageID}, "|")
+	agentID, err := r.triage.PickAgent(ctx, scope, view.Text, r.cfg.Agents.List)
+	if err != nil {
+		logger.WarnCF("routing", "Dispatch triage failed; using default agent",
+			map[string]any{"error": err.Error()})
+		return ""
+	}
+	if agentID == "" {
+		return ""
+	}
+	return r.pickAgentID(agentID)
+}
+
 func (r *RouteResolver) pickAgentID(agentID string) string {
 	trimmed := strings.TrimSpace(agentID)
 	if trimmed == "" {
@@ -156,6 +230,14 @@ type dispatchView struct {
 	Topic     string
 	Sender    string
 	Mentioned bool
+
+	Text        string
+	Attachments []string
+	Time        time.Time
+	Roles       map[string]bool
+
+	language     string
+	languageDone bool
 }
 
 func (r *RouteResolver) matchDispatchRule(view dispatchView) *config.DispatchRule {
@@ -168,14 +250,14 @@ func (r *RouteResolver) matchDispatchRule(view dispatchView) *config.DispatchRul
 		if !selectorHasAnyConstraint(rule.When) {
 			continue
 		}
-		if ruleMatchesView(*rule, view) {
+		if r.ruleMatchesView(*rule, &view) {
 			return rule
 		}
 	}
 	return nil
 }
 
-func ruleMatchesView(rule config.DispatchRule, view dispatchView) bool {
+func (r *RouteResolver) ruleMatchesView(rule config.DispatchRule, view *dispatchView) bool {
 	when := normalizeDispatchSelector(rule.When)
 	if when.Channel != "" && when.Channel != view.Channel {
 		return false
@@ -198,7 +280,7 @@ func ruleMatchesView(rule config.DispatchRule, view dispatchView) bool {
 	if when.Mentioned != nil && *when.Mentioned != view.Mentioned {
 		return false
 	}
-	return true
+	return r.contentMatches(when, view)
 }
 
 func matchedByForRule(rule *config.DispatchRule) string {
@@ -251,6 +333,11 @@ func normalizeDispatchSelector(selector config.DispatchSelector) config.Dispatch
 	selector.Chat = strings.ToLower(strings.TrimSpace(selector.Chat))
 	selector.Topic = strings.ToLower(strings.TrimSpace(selector.Topic))
 	selector.Sender = strings.ToLower(strings.TrimSpace(selector.Sender))
+	selector.Prefix = strings.ToLower(strings.TrimSpace(selector.Prefix))
+	selector.Regex = strings.TrimSpace(selector.Regex)
+	selector.Language = strings.ToLower(strings.TrimSpace(selector.Language))
+	selector.Attachment = strings.ToLower(strings.TrimSpace(selector.Attachment))
+	selector.SenderRole = strings.ToLower(strings.TrimSpace(selector.SenderRole))
 	return selector
 }
 
@@ -261,7 +348,13 @@ func selectorHasAnyConstraint(selector config.DispatchSelector) bool {
 		strings.TrimSpace(selector.Chat) != "" ||
 		strings.TrimSpace(selector.Topic) != "" ||
 		strings.TrimSpace(selector.Sender) != "" ||
-		selector.Mentioned != nil
+		selector.Mentioned != nil ||
+		strings.TrimSpace(selector.Prefix) != "" ||
+		strings.TrimSpace(selector.Regex) != "" ||
+		strings.TrimSpace(selector.Language) != "" ||
+		strings.TrimSpace(selector.Attachment) != "" ||
+		strings.TrimSpace(selector.SenderRole) != "" ||
+		selector.Time != nil
 }
 
 func canonicalDispatchSenderID(channel, rawID string, identityLinks map[string][]string) string {
@@ -0,0 +1,187 @@
+package routing
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// contentMatches checks the content, time and role selectors of a rule. The
+// selector must already be normalized.
+func (r *RouteResolver) contentMatches(when config.DispatchSelector, view *dispatchView) bool {
+	if when.Prefix != "" && !strings.HasPrefix(strings.ToLower(view.Text), when.Prefix) {
+		return false
+	}
+	if when.Regex != "" {
+		re := r.compilePattern(when.Regex)
+		if re == nil || !re.MatchString(view.Text) {
+			return false
+		}
+	}
+	if when.Language != "" && when.Language != view.detectedLanguage() {
+		return false
+	}
+	if when.Attachment != "" && !hasAttachmentKind(view.Attachments, when.Attachment) {
+		return false
+	}
+	if when.SenderRole != "" && !view.Roles[when.SenderRole] {
+		return false
+	}
+	if when.Time != nil && !r.timeWindowMatches(when.Time, view.Time) {
+		return false
+	}
+	return true
+}
+
+func (v *dispatchView) detectedLanguage() string {
+	if !v.languageDone {
+		v.language = detectLanguage(v.Text)
+		v.languageDone = true
+	}
+	return v.language
+}
+
+func hasAttachmentKind(kinds []string, want string) bool {
+	if want == "any" {
+		return len(kinds) > 0
+	}
+	return slices.Contains(kinds, want)
+}
+
+// compilePattern returns the compiled regex selector, or nil when it does
+// not compile. Failures are logged once.
+func (r *RouteResolver) compilePattern(pattern string) *regexp.Regexp {
+	if v, ok := r.patterns.Load(pattern); ok {
+		return v.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		logger.WarnCF("routing", "Invalid dispatch regex; rule will not match",
+			map[string]any{"regex": pattern, "error": err.Error()})
+		re = nil
+	}
+	r.patterns.Store(pattern, re)
+	return re
+}
+
+func (r *RouteResolver) location(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+	if v, ok := r.locations.Load(name); ok {
+		return v.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		logger.WarnCF("routing", "Invalid dispatch time zone; rule will not match",
+			map[string]any{"timezone": name, "error": err.Error()})
+		loc = nil
+	}
+	r.locations.Store(name, loc)
+	return loc
+}
+
+// timeWindowMatches reports whether t falls inside the window. A window whose
+// end is earlier than its start runs past midnight; the hours after midnight
+// belong to the day the window started on.
+func (r *RouteResolver) timeWindowMatches(w *config.DispatchTimeWindow, t time.Time) bool {
+	loc := r.location(strings.TrimSpace(w.Timezone))
+	if loc == nil {
+		return false
+	}
+	start, ok := parseClock(w.Start, 0)
+	if !ok {
+		return false
+	}
+	end, ok := parseClock(w.End, 24*60)
+	if !ok {
+		return false
+	}
+
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	switch {
+	case start <= end:
+		if minute < start || minute >= end {
+			return false
+		}
+	case minute >= start:
+	case minute < end:
+		day = (day + 6) % 7
+	default:
+		return false
+	}
+	return dayAllowed(w.Days, day)
+}
+
+// parseClock parses "HH:MM" into minutes since midnight. Empty input yields
+// def; "24:00" is accepted as the end of the day.
+func parseClock(s string, def int) (int, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return def, true
+	}
+	hh, mm, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, false
+	}
+	h, err1 := strconv.Atoi(hh)
+	m, err2 := strconv.Atoi(mm)
+	if err1 != nil || err2 != nil || h < 0 || m < 0 || m > 59 || h > 24 || h == 24 && m != 0 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+func dayAllowed(days []string, day time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	want := strings.ToLower(day.String()[:3])
+	for _, d := range days {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if len(d) >= 3 && d[:3] == want {
+			return true
+		}
+	}
+	return false
+}
+
+// senderRoles returns the roles whose member list contains the sender. A
+// member may be the raw platform ID, "channel:id" or an identity_links name.
+func senderRoles(roles map[string][]string, channel, senderID, canonical string) map[string]bool {
+	if len(roles) == 0 {
+		return nil
+	}
+	raw := strings.ToLower(strings.TrimSpace(senderID))
+	if raw == "" {
+		return nil
+	}
+	candidates := map[string]bool{raw: true}
+	if canonical != "" {
+		candidates[canonical] = true
+	}
+	if channel = strings.ToLower(strings.TrimSpace(channel)); channel != "" {
+		candidates[channel+":"+raw] = true
+	}
+	if _, id, ok := strings.Cut(raw, ":"); ok && id != "" {
+		candidates[id] = true
+	}
+
+	matched := make(map[string]bool)
+	for role, members := range roles {
+		for _, m := range members {
+			if candidates[strings.ToLower(strings.TrimSpace(m))] {
+				matched[strings.ToLower(strings.TrimSpace(role))] = true
+				break
+			}
+		}
+	}
+	return matched
+}
@@ -0,0 +1,170 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func contentTestResolver(rules ...config.DispatchRule) *RouteResolver {
+	cfg := testConfig([]config.AgentConfig{
+		{ID: "main", Default: true, Description: "General assistant"},
+		{ID: "support", Description: "Billing and account problems"},
+		{ID: "ops", Description: "Server incidents and deployments"},
+	})
+	cfg.Agents.Dispatch = &config.DispatchConfig{
+		Rules: rules,
+		Roles: map[string][]string{"Oncall": {"telegram:42", "alice"}},
+	}
+	return NewRouteResolver(cfg)
+}
+
+var telegramDM = bus.InboundContext{Channel: "telegram", ChatID: "42", ChatType: "direct", SenderID: "42"}
+
+func TestResolveMessageRoute_ContentSelectors(t *testing.T) {
+	cases := []struct {
+		name string
+		when config.DispatchSelector
+		msg  DispatchMessage
+		want string
+	}{
+		{"prefix", config.DispatchSelector{Prefix: "!Ops"}, DispatchMessage{Text: "!ops restart db"}, "ops"},
+		{"prefix miss", config.DispatchSelector{Prefix: "!ops"}, DispatchMessage{Text: "hello"}, "main"},
+		{"regex", config.DispatchSelector{Regex: `(?i)\binvoice\b`}, DispatchMessage{Text: "My Invoice is wrong"}, "ops"},
+		{"invalid regex", config.DispatchSelector{Regex: `(`}, DispatchMessage{Text: "("}, "main"},
+		{"language", config.DispatchSelector{Language: "zh"}, DispatchMessage{Text: "服务器宕机了"}, "ops"},
+		{"language miss", config.DispatchSelector{Language: "zh"}, DispatchMessage{Text: "the server is down"}, "main"},
+		{"attachment kind", config.DispatchSelector{Attachment: "image"}, DispatchMessage{Attachments: []string{"image"}}, "ops"},
+		{"attachment any", config.DispatchSelector{Attachment: "any"}, DispatchMessage{Attachments: []string{"file"}}, "ops"},
+		{"attachment miss", config.DispatchSelector{Attachment: "audio"}, DispatchMessage{Attachments: []string{"image"}}, "main"},
+		{"sender role", config.DispatchSelector{SenderRole: "oncall"}, DispatchMessage{Text: "hi"}, "ops"},
+		{"combined with channel", config.DispatchSelector{Channel: "slack", Prefix: "!ops"}, DispatchMessage{Text: "!ops"}, "main"},
+	}
+	for _, tc := range cases {
+		r := contentTestResolver(config.DispatchRule{Name: "ops", Agent: "ops", When: tc.when})
+		route := r.ResolveMessageRoute(context.Background(), telegramDM, tc.msg)
+		if route.AgentID != tc.want {
+			t.Errorf("%s: AgentID = %q, want %q", tc.name, route.AgentID, tc.want)
+		}
+	}
+}
+
+func TestResolveMessageRoute_SenderRoleNotMatched(t *testing.T) {
+	r := contentTestResolver(config.DispatchRule{Agent: "ops", When: config.DispatchSelector{SenderRole: "oncall"}})
+	route := r.ResolveMessageRoute(context.Background(), bus.InboundContext{
+		Channel:  "telegram",
+		ChatID:   "7",
+		SenderID: "7",
+	}, DispatchMessage{Text: "hi"})
+	if route.AgentID != "main" {
+		t.Errorf("AgentID = %q, want main", route.AgentID)
+	}
+}
+
+func TestTimeWindowMatches(t *testing.T) {
+	r := NewRouteResolver(testConfig(nil))
+	utc := func(day, hour, minute int) time.Time {
+		// 2026-01-05 is a Monday.
+		return time.Date(2026, 1, 5+day, hour, minute, 0, 0, time.UTC)
+	}
+	business := &config.DispatchTimeWindow{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:30", Timezone: "UTC"}
+	overnight := &config.DispatchTimeWindow{Days: []string{"friday"}, Start: "22:00", End: "02:00", Timezone: "UTC"}
+	cases := []struct {
+		name string
+		w    *config.DispatchTimeWindow
+		t    time.Time
+		want bool
+	}{
+		{"business open", business, utc(0, 9, 0), true},
+		{"business before", business, utc(0, 8, 59), false},
+		{"business end exclusive", business, utc(0, 17, 30), false},
+		{"business weekend", business, utc(5, 12, 0), false},
+		{"overnight evening", overnight, utc(4, 23, 0), true},
+		{"overnight after midnight", overnight, utc(5, 1, 0), true},
+		{"overnight wrong day", overnight, utc(4, 1, 0), false},
+		{"overnight gap", overnight, utc(4, 12, 0), false},
+		{"zone offset", &config.DispatchTimeWindow{Start: "09:00", End: "10:00", Timezone: "Asia/Shanghai"}, utc(0, 1, 30), true},
+		{"invalid zone", &config.DispatchTimeWindow{Timezone: "Mars/Olympus"}, utc(0, 1, 30), false},
+		{"invalid clock", &config.DispatchTimeWindow{Start: "9am"}, utc(0, 10, 0), false},
+	}
+	for _, tc := range cases {
+		if got := r.timeWindowMatches(tc.w, tc.t); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	cases := map[string]string{
+		"What is the weather like today?":              "en",
+		"¿Cómo está el clima para mañana?":             "es",
+		"Pourquoi est-ce que le serveur ne répond pas": "fr",
+		"Ich habe eine Frage und bitte um Hilfe":       "de",
+		"今天天气怎么样":                                      "zh",
+		"今日はいい天気ですね":                                   "ja",
+		"오늘 날씨 어때요":                                    "ko",
+		"Сервер не отвечает":                           "ru",
+		"12345":                                        "",
+	}
+	for text, want := range cases {
+		if got := detectLanguage(text); got != want {
+			t.Errorf("detectLanguage(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestResolveMessageRoute_Triage(t *testing.T) {
+	p := &judgeProvider{reply: "`ops`"}
+	r := contentTestResolver()
+	r.SetTriage(NewTriage(p, TriageConfig{Model: "mini"}))
+
+	for range 2 {
+		route := r.ResolveMessageRoute(context.Background(), telegramDM, DispatchMessage{Text: "prod is down"})
+		if route.AgentID != "ops" || route.MatchedBy != "dispatch.triage" {
+			t.Fatalf("route = %s via %s, want ops via dispatch.triage", route.AgentID, route.MatchedBy)
+		}
+	}
+	if p.calls != 1 {
+		t.Errorf("triage calls = %d, want 1 (second resolution is cached)", p.calls)
+	}
+	if got := p.last[0].Content; !containsAll(got, "- support: Billing and account problems", "- ops: Server incidents") {
+		t.Errorf("triage prompt missing agent descriptions: %q", got)
+	}
+
+	// Rules take precedence over triage.
+	r = contentTestResolver(config.DispatchRule{Name: "bills", Agent: "support", When: config.DispatchSelector{Prefix: "bill"}})
+	r.SetTriage(NewTriage(p, TriageConfig{Model: "mini"}))
+	route := r.ResolveMessageRoute(context.Background(), telegramDM, DispatchMessage{Text: "billing question"})
+	if route.MatchedBy != "dispatch.rule:bills" {
+		t.Errorf("MatchedBy = %q, want dispatch.rule:bills", route.MatchedBy)
+	}
+}
+
+func TestResolveMessageRoute_TriageDeclinesOrFails(t *testing.T) {
+	for _, p := range []*judgeProvider{{reply: "none"}, {reply: "marketing"}, {err: errors.New("down")}} {
+		r := contentTestResolver()
+		r.SetTriage(NewTriage(p, TriageConfig{Model: "mini"}))
+		route := r.ResolveMessageRoute(context.Background(), telegramDM, DispatchMessage{Text: "hello"})
+		if route.AgentID != "main" || route.MatchedBy != "default" {
+			t.Errorf("reply %q / err %v: route = %s via %s, want main via default", p.reply, p.err, route.AgentID, route.MatchedBy)
+		}
+		r.ResolveMessageRoute(context.Background(), telegramDM, DispatchMessage{Text: "hello"})
+		if p.calls != 1 {
+			t.Errorf("reply %q / err %v: triage calls = %d, want 1 (outcome is cached)", p.reply, p.err, p.calls)
+		}
+	}
+}
+
+func containsAll(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if !strings.Contains(s, sub) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,11 @@
 package routing
 
 import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
@@ -9,66 +14,230 @@ import (
 // or an attachment) before the heavy model is chosen.
 const defaultThreshold = 0.35
 
+// Score sources reported in Decision.Source.
+const (
+	SourceRules      = "rules"
+	SourceJudge      = "judge"
+	SourceJudgeCache = "judge-cache"
+)
+
 // RouterConfig holds the validated model routing settings.
 // It mirrors config.RoutingConfig but lives in pkg/routing to keep the
 // dependency graph simple: pkg/agent resolves config → routing, not the reverse.
 type RouterConfig struct {
 	// LightModel is the model_name (from model_list) used for simple tasks.
+	// Only used when Tiers is empty.
 	LightModel string
 
 	// Threshold is the complexity score cutoff in [0, 1].
 	// score >= Threshold → primary (heavy) model.
 	// score <  Threshold → light model.
+	// Only used when Tiers is empty.
 	Threshold float64
+
+	// Tiers replaces the light/primary pair with an ordered ladder of models.
+	// When empty, LightModel and Threshold form a two-tier ladder.
+	Tiers []Tier
+
+	// Judge, when set, grades message complexity with a small model instead
+	// of the structural RuleClassifier. Rules remain the fallback.
+	Judge *Judge
+}
+
+// Tier is one rung of the routing ladder.
+type Tier struct {
+	// Name identifies the tier in logs and /context (e.g. "local", "frontier").
+	Name string
+
+	// Model is the model_name (from model_list) serving this tier.
+	// Empty means the agent's primary model.
+	Model string
+
+	// MaxScore is the exclusive upper score bound for this tier: a turn
+	// scoring below it may use the tier. The last tier accepts any score.
+	MaxScore float64
+
+	// Vision reports whether the model accepts image and audio inputs.
+	Vision bool
+
+	// Tools reports whether the model handles tool calling reliably. Tiers
+	// without it are skipped while a tool workflow is in progress.
+	Tools bool
+
+	// ContextWindow is the model's context size in tokens. Zero means
+	// unlimited; otherwise turns whose prompt exceeds it skip the tier.
+	ContextWindow int
+}
+
+// Decision describes the routing outcome for one turn.
+type Decision struct {
+	// Tier is the name of the selected tier.
+	Tier string
+	// Model is the tier's model_name; empty means the primary model.
+	Model string
+	// Score is the complexity score the decision was based on.
+	Score float64
+	// Source tells where the score came from (SourceRules, SourceJudge or
+	// SourceJudgeCache).
+	Source string
+	// Skipped lists cheaper tiers whose score range matched but which lacked
+	// a capability the turn needs, formatted as "name: reason".
+	Skipped []string
+	// JudgeErr is set when the judge failed and rules were used instead.
+	JudgeErr error
+}
+
+// Primary reports whether the decision keeps the agent's primary model.
+func (d Decision) Primary() bool {
+	return d.Model == ""
 }
 
 // Router selects the appropriate model tier for each incoming message.
 // It is safe for concurrent use from multiple goroutines.
 type Router struct {
 	cfg        RouterConfig
+	tiers      []Tier
 	classifier Classifier
+	judge      *Judge
 }
 
 // New creates a Router with the given config and the default RuleClassifier.
 // If cfg.Threshold is zero or negative, defaultThreshold (0.35) is used.
 func New(cfg RouterConfig) *Router {
+	return newWithClassifier(cfg, &RuleClassifier{})
+}
+
+// newWithClassifier creates a Router with a custom Classifier.
+// Intended for unit tests that need to inject a deterministic scorer.
+func newWithClassifier(cfg RouterConfig, c Classifier) *Router {
 	if cfg.Threshold <= 0 {
 		cfg.Threshold = defaultThreshold
 	}
 	return &Router{
 		cfg:        cfg,
-		classifier: &RuleClassifier{},
+		tiers:      normalizeTiers(cfg),
+		classifier: c,
+		judge:      cfg.Judge,
 	}
 }
 
-// newWithClassifier creates a Router with a custom Classifier.
-// Intended for unit tests that need to inject a deterministic scorer.
-func newWithClassifier(cfg RouterConfig, c Classifier) *Router {
-	if cfg.Threshold <= 0 {
-		cfg.Threshold = defaultThreshold
+// normalizeTiers builds the tier ladder, ordered by ascending MaxScore.
+// Tiers without a usable bound (<= 0) are treated as catch-alls and sorted
+// last. The legacy light model becomes a tier without vision so that media
+// turns keep going to the primary model.
+func normalizeTiers(cfg RouterConfig) []Tier {
+	if len(cfg.Tiers) == 0 {
+		return []Tier{
+			{Name: "light", Model: cfg.LightModel, MaxScore: cfg.Threshold, Tools: true},
+			{Name: "primary"},
+		}
+	}
+
+	tiers := make([]Tier, len(cfg.Tiers))
+	copy(tiers, cfg.Tiers)
+	for i := range tiers {
+		if tiers[i].MaxScore <= 0 {
+			tiers[i].MaxScore = math.Inf(1)
+		}
+		if tiers[i].Name == "" {
+			tiers[i].Name = tiers[i].Model
+		}
+		if tiers[i].Name == "" {
+			tiers[i].Name = "primary"
+		}
 	}
-	return &Router{cfg: cfg, classifier: c}
+	sort.SliceStable(tiers, func(i, j int) bool {
+		return tiers[i].MaxScore < tiers[j].MaxScore
+	})
+	return tiers
+}
+
+// Select scores the turn and walks the tiers from cheapest to most capable,
+// returning the first one whose score range and capabilities fit. The last
+// tier is the catch-all and is never skipped.
+func (r *Router) Select(ctx context.Context, msg string, history []providers.Message) Decision {
+	features := ExtractFeatures(msg, history)
+	needsVision := features.HasAttachments || currentMessageHasMedia(history)
+	needsTools := features.RecentToolCalls > 0
+	promptTokens := estimatePromptTokens(msg, history)
+
+	// Media is handled through tier capabilities, so it must not also pin
+	// the score to 1.0 and bypass every cheaper vision-capable tier.
+	scored := features
+	scored.HasAttachments = false
+
+	d := Decision{Source: SourceRules}
+	if r.judge != nil && strings.TrimSpace(msg) != "" {
+		score, cached, err := r.judge.Grade(ctx, msg, history)
+		if err == nil {
+			d.Score = score
+			d.Source = SourceJudge
+			if cached {
+				d.Source = SourceJudgeCache
+			}
+		} else {
+			d.JudgeErr = err
+		}
+	}
+	if d.Source == SourceRules {
+		d.Score = r.classifier.Score(scored)
+	}
+
+	for i, t := range r.tiers {
+		last := i == len(r.tiers)-1
+		if !last {
+			if d.Score >= t.MaxScore {
+				continue
+			}
+			if reason := missingCapability(t, needsVision, needsTools, promptTokens); reason != "" {
+				d.Skipped = append(d.Skipped, t.Name+": "+reason)
+				continue
+			}
+		}
+		d.Tier = t.Name
+		d.Model = t.Model
+		break
+	}
+	return d
+}
+
+func missingCapability(t Tier, needsVision, needsTools bool, promptTokens int) string {
+	switch {
+	case needsVision && !t.Vision:
+		return "no vision"
+	case needsTools && !t.Tools:
+		return "no tools"
+	case t.ContextWindow > 0 && promptTokens > t.ContextWindow:
+		return "context too small"
+	}
+	return ""
 }
 
 // SelectModel returns the model to use for this conversation turn along with
 // the computed complexity score (for logging and debugging).
 //
-//   - If score < cfg.Threshold: returns (cfg.LightModel, true, score)
-//   - Otherwise:               returns (primaryModel, false, score)
+//   - If a cheaper tier is selected: returns (tier model, true, score)
+//   - Otherwise:                    returns (primaryModel, false, score)
 //
 // The caller is responsible for resolving the returned model name into
-// provider candidates (see AgentInstance.LightCandidates).
+// provider candidates (see AgentInstance.RouteTargets).
 func (r *Router) SelectModel(
 	msg string,
 	history []providers.Message,
 	primaryModel string,
 ) (model string, usedLight bool, score float64) {
-	features := ExtractFeatures(msg, history)
-	score = r.classifier.Score(features)
-	if score < r.cfg.Threshold {
-		return r.cfg.LightModel, true, score
+	d := r.Select(context.Background(), msg, history)
+	if d.Primary() {
+		return primaryModel, false, d.Score
 	}
-	return primaryModel, false, score
+	return d.Model, true, d.Score
+}
+
+// Tiers returns the normalized tier ladder, cheapest first.
+func (r *Router) Tiers() []Tier {
+	tiers := make([]Tier, len(r.tiers))
+	copy(tiers, r.tiers)
+	return tiers
 }
 
 // LightModel returns the configured light model name.
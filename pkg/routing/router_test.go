@@ -1,6 +1,7 @@
 package routing
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -412,3 +413,90 @@ func TestRouter_SelectModel_ReturnsScore(t *testing.T) {
 		t.Errorf("score: got %f, want 0.42", score)
 	}
 }
+
+// ── Tiers ────────────────────────────────────────────────────────────────────
+
+func threeTiers() []Tier {
+	return []Tier{
+		{Name: "frontier"},
+		{Name: "local", Model: "qwen", MaxScore: 0.3, Tools: true, ContextWindow: 100},
+		{Name: "cheap", Model: "flash", MaxScore: 0.7, Vision: true, Tools: true},
+	}
+}
+
+func TestRouter_Tiers_SortedWithCatchAllLast(t *testing.T) {
+	r := New(RouterConfig{Tiers: threeTiers()})
+	var names []string
+	for _, tier := range r.Tiers() {
+		names = append(names, tier.Name)
+	}
+	if got := strings.Join(names, ","); got != "local,cheap,frontier" {
+		t.Errorf("tier order: got %s, want local,cheap,frontier", got)
+	}
+}
+
+func TestRouter_Select_ScoreBands(t *testing.T) {
+	cases := []struct {
+		score float64
+		tier  string
+	}{
+		{0.1, "local"},
+		{0.3, "cheap"},
+		{0.69, "cheap"},
+		{0.7, "frontier"},
+		{1.0, "frontier"},
+	}
+	for _, tc := range cases {
+		r := newWithClassifier(RouterConfig{Tiers: threeTiers()}, &fixedScoreClassifier{score: tc.score})
+		d := r.Select(context.Background(), "hello", nil)
+		if d.Tier != tc.tier {
+			t.Errorf("score %.2f: got tier %q, want %q", tc.score, d.Tier, tc.tier)
+		}
+		if d.Source != SourceRules {
+			t.Errorf("score %.2f: source got %q, want %q", tc.score, d.Source, SourceRules)
+		}
+	}
+}
+
+func TestRouter_Select_MediaSkipsTiersWithoutVision(t *testing.T) {
+	r := newWithClassifier(RouterConfig{Tiers: threeTiers()}, &fixedScoreClassifier{score: 0.1})
+	history := []providers.Message{{Role: "user", Content: "what is this", Media: []string{"media://1"}}}
+	d := r.Select(context.Background(), "what is this", history)
+	if d.Tier != "cheap" {
+		t.Errorf("media: got tier %q, want cheap", d.Tier)
+	}
+	if len(d.Skipped) != 1 || d.Skipped[0] != "local: no vision" {
+		t.Errorf("skipped: got %v, want [local: no vision]", d.Skipped)
+	}
+}
+
+func TestRouter_Select_ContextWindowTooSmall(t *testing.T) {
+	r := newWithClassifier(RouterConfig{Tiers: threeTiers()}, &fixedScoreClassifier{score: 0.1})
+	history := []providers.Message{{Role: "user", Content: strings.Repeat("word ", 200)}}
+	d := r.Select(context.Background(), "short", history)
+	if d.Tier != "cheap" {
+		t.Errorf("long prompt: got tier %q, want cheap", d.Tier)
+	}
+}
+
+func TestRouter_Select_ToolWorkflowNeedsTools(t *testing.T) {
+	tiers := []Tier{
+		{Name: "local", Model: "qwen", MaxScore: 0.5},
+		{Name: "primary"},
+	}
+	r := newWithClassifier(RouterConfig{Tiers: tiers}, &fixedScoreClassifier{score: 0.1})
+	history := []providers.Message{{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "1"}}}}
+	d := r.Select(context.Background(), "go on", history)
+	if !d.Primary() || d.Tier != "primary" {
+		t.Errorf("tool workflow: got tier %q (model %q), want primary", d.Tier, d.Model)
+	}
+}
+
+func TestRouter_LegacyConfig_MediaUsesPrimary(t *testing.T) {
+	r := New(RouterConfig{LightModel: "light", Threshold: 0.35})
+	history := []providers.Message{{Role: "user", Content: "look", Media: []string{"media://1"}}}
+	_, usedLight, _ := r.SelectModel("look", history, "heavy")
+	if usedLight {
+		t.Error("media ref: expected primary model to be selected")
+	}
+}
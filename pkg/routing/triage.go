@@ -0,0 +1,127 @@
+package routing
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	defaultTriageTimeout   = 10 * time.Second
+	defaultTriageCacheTTL  = 10 * time.Minute
+	defaultTriageCacheSize = 512
+)
+
+// TriageConfig configures a Triage.
+type TriageConfig struct {
+	// Model is the model ID passed to the provider.
+	Model string
+	// Timeout bounds a single triage call. Defaults to 10s.
+	Timeout time.Duration
+	// CacheTTL is how long a pick, or a failed call, is reused for the same
+	// message. Defaults to 10m.
+	CacheTTL time.Duration
+}
+
+// Triage picks the agent for a message by asking a lightweight model to
+// compare it with each agent's description. It is safe for concurrent use.
+type Triage struct {
+	provider providers.LLMProvider
+	model    string
+	timeout  time.Duration
+	cache    *lruCache[[sha256.Size]byte, string]
+}
+
+// NewTriage creates a Triage that queries provider with cfg.Model.
+func NewTriage(provider providers.LLMProvider, cfg TriageConfig) *Triage {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTriageTimeout
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultTriageCacheTTL
+	}
+	return &Triage{
+		provider: provider,
+		model:    cfg.Model,
+		timeout:  cfg.Timeout,
+		cache:    newLRUCache[[sha256.Size]byte, string](defaultTriageCacheSize, cfg.CacheTTL),
+	}
+}
+
+// PickAgent returns the ID of the agent best suited to text, or "" when the
+// model finds no clear fit. scope identifies the message (chat, sender,
+// message ID) so identical text from different chats is triaged separately.
+// A failed call is cached as "no pick", so the message is not triaged again
+// and every later resolution agrees on the default agent.
+func (t *Triage) PickAgent(ctx context.Context, scope, text string, agents []config.AgentConfig) (string, error) {
+	text = truncateRunes(text, judgeMaxRunes)
+	key := sha256.Sum256([]byte(scope + "\x00" + text))
+	if id, ok := t.cache.get(key); ok {
+		return id, nil
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("You route chat messages to the agent best suited to handle them.\n")
+	prompt.WriteString("Agents:\n")
+	for _, a := range agents {
+		id := NormalizeAgentID(a.ID)
+		desc := strings.TrimSpace(a.Description)
+		if desc == "" {
+			desc = strings.TrimSpace(a.Name)
+		}
+		if desc == "" {
+			desc = "(no description)"
+		}
+		fmt.Fprintf(&prompt, "- %s: %s\n", id, desc)
+	}
+	prompt.WriteString("Reply with the agent id only, or \"none\" if no agent clearly fits.")
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	resp, err := t.provider.Chat(ctx, []providers.Message{
+		{Role: "system", Content: prompt.String()},
+		{Role: "user", Content: text},
+	}, nil, t.model, map[string]any{
+		"max_tokens":  32,
+		"temperature": 0.0,
+	})
+	if err != nil {
+		t.cache.put(key, "")
+		return "", fmt.Errorf("dispatch triage: %w", err)
+	}
+	if resp == nil {
+		t.cache.put(key, "")
+		return "", errors.New("dispatch triage: empty response")
+	}
+
+	id := parseTriageReply(resp.Content, agents)
+	t.cache.put(key, id)
+	return id, nil
+}
+
+// parseTriageReply finds the agent ID in the model's reply. Models sometimes
+// wrap the answer in quotes or a sentence, so every word is checked.
+func parseTriageReply(reply string, agents []config.AgentConfig) string {
+	known := make(map[string]bool, len(agents))
+	for _, a := range agents {
+		known[NormalizeAgentID(a.ID)] = true
+	}
+	fields := strings.FieldsFunc(strings.ToLower(reply), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	})
+	for _, f := range fields {
+		if f == "none" {
+			return ""
+		}
+		if id := NormalizeAgentID(f); known[id] {
+			return id
+		}
+	}
+	return ""
+}
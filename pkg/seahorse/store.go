@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 )
@@ -13,6 +14,34 @@ type Store struct {
 	db *sql.DB
 }
 
+// OpenStore opens an existing seahorse database for read-only queries. Unlike
+// NewEngine it never creates the file or runs migrations, so it is safe to use
+// from another process while the gateway owns the database.
+func OpenStore(dbPath string) (*Store, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA busy_timeout = 5000;"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("set busy_timeout: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA query_only = ON;"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("set query_only: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close releases the underlying database handle.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
 // CreateSummaryInput holds parameters for creating a summary.
 type CreateSummaryInput struct {
 	ConversationID       int64
@@ -80,6 +109,25 @@ func (s *Store) GetConversationBySessionKey(ctx context.Context, sessionKey stri
 	return &conv, nil
 }
 
+// GetConversation retrieves a conversation by ID.
+func (s *Store) GetConversation(ctx context.Context, convID int64) (*Conversation, error) {
+	var conv Conversation
+	var createdAt, updatedAt string
+	err := s.db.QueryRowContext(ctx,
+		"SELECT conversation_id, session_key, created_at, updated_at FROM conversations WHERE conversation_id = ?",
+		convID,
+	).Scan(&conv.ConversationID, &conv.SessionKey, &createdAt, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get conversation: %w", err)
+	}
+	conv.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
+	conv.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
+	return &conv, nil
+}
+
 // GetSessionStatus returns status for a specific session.
 func (s *Store) GetSessionStatus(ctx context.Context, sessionKey string) (*SessionStatus, error) {
 	conv, err := s.GetConversationBySessionKey(ctx, sessionKey)
@@ -2,7 +2,10 @@ package seahorse
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -79,6 +82,75 @@ func TestStoreGetConversationBySessionKey(t *testing.T) {
 	}
 }
 
+func TestStoreGetConversation(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	conv, err := s.GetConversation(ctx, 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conv != nil {
+		t.Error("expected nil for nonexistent conversation")
+	}
+
+	created, err := s.GetOrCreateConversation(ctx, "agent:test")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	found, err := s.GetConversation(ctx, created.ConversationID)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if found == nil || found.SessionKey != "agent:test" {
+		t.Errorf("found %+v, want session key agent:test", found)
+	}
+}
+
+func TestOpenStore(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "seahorse.db")
+
+	if _, err := OpenStore(dbPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("OpenStore(missing) error = %v, want os.ErrNotExist", err)
+	}
+	if _, err := os.Stat(dbPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("OpenStore created %s", dbPath)
+	}
+
+	eng, err := NewEngine(Config{DBPath: dbPath}, nil)
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	conv, err := eng.store.GetOrCreateConversation(ctx, "agent:test")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := eng.store.AddMessage(ctx, conv.ConversationID, "user", "deploy the staging cluster", 5); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Close engine: %v", err)
+	}
+
+	s, err := OpenStore(dbPath)
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	defer s.Close()
+
+	results, err := s.SearchMessages(ctx, SearchInput{Pattern: "staging", AllConversations: true})
+	if err != nil {
+		t.Fatalf("SearchMessages: %v", err)
+	}
+	if len(results) != 1 || results[0].ConversationID != conv.ConversationID {
+		t.Fatalf("results = %+v, want one match in conversation %d", results, conv.ConversationID)
+	}
+	if _, err := s.GetOrCreateConversation(ctx, "agent:other"); err == nil {
+		t.Fatal("expected write to fail on a read-only store")
+	}
+}
+
 // --- Conversation Clear ---
 
 func TestStoreClearConversation(t *testing.T) {
@@ -55,6 +55,14 @@ func buildSessionScope(input AllocationInput) SessionScope {
 		scope.Channel = "unknown"
 	}
 
+	// Direct chats from identities linked with /link share one scope across
+	// channels and accounts, keyed by the link group instead of the chat.
+	linkedDirect := linkedDirectIdentity(input)
+	if linkedDirect != "" {
+		scope.Channel = LinkedChannel
+		scope.Account = routing.NormalizeAccountID("")
+	}
+
 	dimensions := make([]string, 0, len(input.SessionPolicy.Dimensions))
 	values := make(map[string]string, len(input.SessionPolicy.Dimensions))
 
@@ -70,6 +78,11 @@ func buildSessionScope(input AllocationInput) SessionScope {
 				values["space"] = fmt.Sprintf("%s:%s", spaceType, strings.ToLower(spaceID))
 			}
 		case "chat":
+			if linkedDirect != "" {
+				dimensions = append(dimensions, "chat")
+				values["chat"] = "direct:" + linkedDirect
+				continue
+			}
 			chatID := strings.TrimSpace(inbound.ChatID)
 			if chatID == "" {
 				continue
@@ -112,6 +125,28 @@ func buildSessionScope(input AllocationInput) SessionScope {
 	return scope
 }
 
+// linkedDirectIdentity returns the link group of the sender of a direct chat,
+// or "" when the chat is not direct, the sender is not linked, or the policy
+// has no chat or sender dimension to key the shared scope by.
+func linkedDirectIdentity(input AllocationInput) string {
+	inbound := input.Context
+	// An unset chat type counts as direct, as in the chat dimension.
+	if chatType := strings.TrimSpace(inbound.ChatType); chatType != "" && !strings.EqualFold(chatType, "direct") {
+		return ""
+	}
+	keyed := false
+	for _, dimension := range input.SessionPolicy.Dimensions {
+		if dimension == "chat" || dimension == "sender" {
+			keyed = true
+		}
+	}
+	if !keyed {
+		return ""
+	}
+	canonical, _ := linkedIdentity(inbound.Channel, inbound.SenderID)
+	return canonical
+}
+
 func buildLegacySessionAliases(input AllocationInput) []string {
 	aliases := []string{strings.ToLower(BuildLegacyMainAlias(input.AgentID))}
 	inbound := input.Context
@@ -123,7 +123,8 @@ func BuildLegacyPeerAlias(agentID, channel, peerKind, peerID string) string {
 }
 
 // CanonicalSessionIdentityID collapses an identity using identity_links when
-// possible, then returns a normalized lowercase identifier.
+// possible, then links made with /link, and returns a normalized lowercase
+// identifier.
 func CanonicalSessionIdentityID(channel, rawID string, identityLinks map[string][]string) string {
 	normalizedID := strings.TrimSpace(rawID)
 	if normalizedID == "" {
@@ -131,6 +132,8 @@ func CanonicalSessionIdentityID(channel, rawID string, identityLinks map[string]
 	}
 	if linked := resolveLinkedPeerID(identityLinks, channel, normalizedID); linked != "" {
 		normalizedID = linked
+	} else if linked, ok := linkedIdentity(channel, normalizedID); ok {
+		normalizedID = linked
 	}
 	return strings.ToLower(normalizedID)
 }
@@ -0,0 +1,357 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// LinkCodeTTL is how long a /link code stays valid.
+const LinkCodeTTL = 10 * time.Minute
+
+const (
+	// linkCodeBytes of randomness give 8 base32 characters.
+	linkCodeBytes = 5
+	// maxLinkAttempts is how many wrong codes one identity may send within
+	// LinkCodeTTL before Confirm stops taking codes from it.
+	maxLinkAttempts = 5
+)
+
+// LinkedChannel replaces the channel in the scope of direct chats from
+// linked identities, so the same person shares one conversation across
+// platforms.
+const LinkedChannel = "linked"
+
+var (
+	ErrLinkCodeInvalid = errors.New("link code is invalid or expired")
+	ErrLinkSelf        = errors.New("send the code from your other account, not the one that requested it")
+	ErrLinkAttempts    = errors.New("too many wrong link codes; try again later")
+	ErrLinkNotDirect   = errors.New("use /link in a direct chat")
+)
+
+// IdentityLink is one identity joined to a link group.
+type IdentityLink struct {
+	Identity  string    `json:"identity"`  // "platform:id"
+	Canonical string    `json:"canonical"` // shared identity of the group
+	LinkedAt  time.Time `json:"linked_at"`
+}
+
+type pendingLink struct {
+	identity string
+	expires  time.Time
+}
+
+// linkAttempts counts the wrong codes an identity sent since first.
+type linkAttempts struct {
+	failures int
+	first    time.Time
+}
+
+// LinkStore keeps identity links created at runtime with /link. Links are
+// saved to a JSON file so the web launcher can list and remove them; edits
+// made by another process are picked up on the next lookup.
+// It is safe for concurrent use.
+type LinkStore struct {
+	path string
+	now  func() time.Time
+
+	mu       sync.Mutex
+	links    map[string]IdentityLink
+	modTime  time.Time
+	pending  map[string]pendingLink  // code -> requester
+	attempts map[string]linkAttempts // confirming identity -> wrong codes
+}
+
+// LinkStorePath returns where the link store lives under the PicoClaw home
+// directory. It is kept out of agent workspaces so agents cannot rewrite
+// links with their file tools.
+func LinkStorePath(home string) string {
+	return filepath.Join(home, "state", "identity_links.json")
+}
+
+// NewLinkStore opens the link file at path. A missing file is an empty store.
+func NewLinkStore(path string) *LinkStore {
+	s := &LinkStore{
+		path:     path,
+		now:      time.Now,
+		links:    make(map[string]IdentityLink),
+		pending:  make(map[string]pendingLink),
+		attempts: make(map[string]linkAttempts),
+	}
+	s.mu.Lock()
+	s.reloadLocked()
+	s.mu.Unlock()
+	return s
+}
+
+var defaultLinkStore atomic.Pointer[LinkStore]
+
+// SetLinkStore installs the store consulted by CanonicalSessionIdentityID.
+// A nil store turns runtime links off.
+func SetLinkStore(s *LinkStore) {
+	defaultLinkStore.Store(s)
+}
+
+// linkedIdentity returns the link-group identity of the sender, if the
+// sender was linked with /link.
+func linkedIdentity(channel, rawID string) (string, bool) {
+	s := defaultLinkStore.Load()
+	if s == nil {
+		return "", false
+	}
+	return s.Resolve(LinkIdentity(channel, rawID))
+}
+
+// LinkIdentity builds the "platform:id" form used by the link store. rawID
+// may already carry the platform prefix.
+func LinkIdentity(channel, rawID string) string {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	rawID = strings.ToLower(strings.TrimSpace(rawID))
+	if rawID == "" {
+		return ""
+	}
+	if channel == "" || strings.HasPrefix(rawID, channel+":") {
+		return rawID
+	}
+	return channel + ":" + rawID
+}
+
+// Resolve returns the canonical identity of the group identity belongs to.
+func (s *LinkStore) Resolve(identity string) (string, bool) {
+	identity = strings.ToLower(strings.TrimSpace(identity))
+	if identity == "" {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloadLocked()
+	link, ok := s.links[identity]
+	return link.Canonical, ok
+}
+
+// List returns all links sorted by group, then identity.
+func (s *LinkStore) List() []IdentityLink {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloadLocked()
+	out := make([]IdentityLink, 0, len(s.links))
+	for _, l := range s.links {
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Canonical != out[j].Canonical {
+			return out[i].Canonical < out[j].Canonical
+		}
+		return out[i].Identity < out[j].Identity
+	})
+	return out
+}
+
+// IssueCode creates a one-time code for identity. Confirming it from another
+// identity links the two. A new code replaces any earlier one.
+func (s *LinkStore) IssueCode(identity string) (string, error) {
+	identity = strings.ToLower(strings.TrimSpace(identity))
+	if identity == "" {
+		return "", errors.New("sender identity is unknown")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for code, p := range s.pending {
+		if p.identity == identity || now.After(p.expires) {
+			delete(s.pending, code)
+		}
+	}
+	buf := make([]byte, linkCodeBytes)
+	for {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("generate link code: %w", err)
+		}
+		code := base32.StdEncoding.EncodeToString(buf)
+		if _, taken := s.pending[code]; !taken {
+			s.pending[code] = pendingLink{identity: identity, expires: now.Add(LinkCodeTTL)}
+			return code, nil
+		}
+	}
+}
+
+// Confirm redeems code for identity and links it with the identity that
+// requested the code. It returns the group's canonical identity. An identity
+// that was the canonical of another group brings that group along.
+// After maxLinkAttempts wrong codes, an identity is refused until
+// LinkCodeTTL has passed since its first wrong code.
+func (s *LinkStore) Confirm(code, identity string) (string, error) {
+	identity = strings.ToLower(strings.TrimSpace(identity))
+	code = strings.ToUpper(strings.TrimSpace(code))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloadLocked()
+
+	now := s.now()
+	for id, a := range s.attempts {
+		if now.Sub(a.first) > LinkCodeTTL {
+			delete(s.attempts, id)
+		}
+	}
+	if s.attempts[identity].failures >= maxLinkAttempts {
+		return "", ErrLinkAttempts
+	}
+
+	p, ok := s.pending[code]
+	if !ok || now.After(p.expires) {
+		delete(s.pending, code)
+		a := s.attempts[identity]
+		if a.failures == 0 {
+			a.first = now
+		}
+		a.failures++
+		s.attempts[identity] = a
+		return "", ErrLinkCodeInvalid
+	}
+	if p.identity == identity {
+		return "", ErrLinkSelf
+	}
+
+	canonical := p.identity
+	if l, ok := s.links[p.identity]; ok {
+		canonical = l.Canonical
+	}
+	prev := make(map[string]IdentityLink, len(s.links))
+	for k, v := range s.links {
+		prev[k] = v
+	}
+
+	linkedAt := now.UTC()
+	oldGroup := identity
+	if l, ok := s.links[identity]; ok {
+		oldGroup = l.Canonical
+	}
+	for id, l := range s.links {
+		if l.Canonical == oldGroup && oldGroup != canonical {
+			l.Canonical = canonical
+			s.links[id] = l
+		}
+	}
+	if _, ok := s.links[p.identity]; !ok {
+		s.links[p.identity] = IdentityLink{Identity: p.identity, Canonical: canonical, LinkedAt: linkedAt}
+	}
+	s.links[identity] = IdentityLink{Identity: identity, Canonical: canonical, LinkedAt: linkedAt}
+
+	if err := s.saveLocked(); err != nil {
+		s.links = prev
+		return "", err
+	}
+	delete(s.pending, code)
+	delete(s.attempts, identity)
+	return canonical, nil
+}
+
+// Unlink removes identity from its group. A group left with a single member
+// is dissolved. It reports whether identity was linked.
+func (s *LinkStore) Unlink(identity string) (bool, error) {
+	identity = strings.ToLower(strings.TrimSpace(identity))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloadLocked()
+
+	link, ok := s.links[identity]
+	if !ok {
+		return false, nil
+	}
+	prev := make(map[string]IdentityLink, len(s.links))
+	for k, v := range s.links {
+		prev[k] = v
+	}
+	delete(s.links, identity)
+	var rest []string
+	for id, l := range s.links {
+		if l.Canonical == link.Canonical {
+			rest = append(rest, id)
+		}
+	}
+	if len(rest) == 1 {
+		delete(s.links, rest[0])
+	}
+	if err := s.saveLocked(); err != nil {
+		s.links = prev
+		return false, err
+	}
+	return true, nil
+}
+
+type linkFile struct {
+	Links []IdentityLink `json:"links"`
+}
+
+// reloadLocked rereads the file when another process changed it.
+func (s *LinkStore) reloadLocked() {
+	if s.path == "" {
+		return
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !s.modTime.IsZero() {
+			s.links = make(map[string]IdentityLink)
+			s.modTime = time.Time{}
+		}
+		return
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	var f linkFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return
+	}
+	links := make(map[string]IdentityLink, len(f.Links))
+	for _, l := range f.Links {
+		l.Identity = strings.ToLower(strings.TrimSpace(l.Identity))
+		l.Canonical = strings.ToLower(strings.TrimSpace(l.Canonical))
+		if l.Identity != "" && l.Canonical != "" {
+			links[l.Identity] = l
+		}
+	}
+	s.links = links
+	s.modTime = info.ModTime()
+}
+
+func (s *LinkStore) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	f := linkFile{Links: make([]IdentityLink, 0, len(s.links))}
+	for _, l := range s.links {
+		f.Links = append(f.Links, l)
+	}
+	sort.Slice(f.Links, func(i, j int) bool { return f.Links[i].Identity < f.Links[j].Identity })
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("save identity links: %w", err)
+	}
+	if err := fileutil.WriteFileAtomic(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("save identity links: %w", err)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+func TestLinkStore_IssueConfirmUnlink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "identity_links.json")
+	s := NewLinkStore(path)
+
+	code, err := s.IssueCode("telegram:123")
+	if err != nil || len(code) != 8 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567") != "" {
+		t.Fatalf("IssueCode = %q, %v", code, err)
+	}
+	if _, err := s.Confirm(code, "telegram:123"); !errors.Is(err, ErrLinkSelf) {
+		t.Fatalf("self confirm err = %v, want ErrLinkSelf", err)
+	}
+	canonical, err := s.Confirm(code, "Discord:456")
+	if err != nil || canonical != "telegram:123" {
+		t.Fatalf("Confirm = %q, %v", canonical, err)
+	}
+	if _, err := s.Confirm(code, "slack:u1"); !errors.Is(err, ErrLinkCodeInvalid) {
+		t.Fatalf("reused code err = %v, want ErrLinkCodeInvalid", err)
+	}
+
+	// A third account joins through a code issued by a linked member.
+	code, _ = s.IssueCode("discord:456")
+	if canonical, err := s.Confirm(code, "slack:u1"); err != nil || canonical != "telegram:123" {
+		t.Fatalf("second Confirm = %q, %v", canonical, err)
+	}
+
+	reopened := NewLinkStore(path)
+	if got, ok := reopened.Resolve("slack:u1"); !ok || got != "telegram:123" {
+		t.Fatalf("persisted Resolve = %q, %v", got, ok)
+	}
+	if n := len(reopened.List()); n != 3 {
+		t.Fatalf("List() has %d links, want 3", n)
+	}
+
+	if removed, err := reopened.Unlink("slack:u1"); !removed || err != nil {
+		t.Fatalf("Unlink = %v, %v", removed, err)
+	}
+	if removed, _ := reopened.Unlink("discord:456"); !removed {
+		t.Fatal("Unlink(discord) should succeed")
+	}
+	if got := reopened.List(); len(got) != 0 {
+		t.Fatalf("a group of one should be dissolved, got %+v", got)
+	}
+
+	// The first store sees the other process's edits.
+	if _, ok := s.Resolve("telegram:123"); ok {
+		t.Fatal("stale link after external unlink")
+	}
+}
+
+func TestLinkStore_CodeExpires(t *testing.T) {
+	s := NewLinkStore("")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	code, _ := s.IssueCode("telegram:1")
+	now = now.Add(LinkCodeTTL + time.Second)
+	if _, err := s.Confirm(code, "discord:2"); !errors.Is(err, ErrLinkCodeInvalid) {
+		t.Fatalf("expired code err = %v, want ErrLinkCodeInvalid", err)
+	}
+}
+
+func TestLinkStore_LimitsWrongCodes(t *testing.T) {
+	s := NewLinkStore("")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	code, _ := s.IssueCode("telegram:1")
+	for i := 0; i < maxLinkAttempts; i++ {
+		if _, err := s.Confirm("AAAAAAAA", "discord:2"); !errors.Is(err, ErrLinkCodeInvalid) {
+			t.Fatalf("wrong code %d err = %v, want ErrLinkCodeInvalid", i, err)
+		}
+	}
+	if _, err := s.Confirm(code, "discord:2"); !errors.Is(err, ErrLinkAttempts) {
+		t.Fatalf("right code after too many wrong ones err = %v, want ErrLinkAttempts", err)
+	}
+
+	// Other identities are unaffected, and codes are case-insensitive.
+	if _, err := s.Confirm(strings.ToLower(code), "slack:3"); err != nil {
+		t.Fatalf("Confirm from another identity err = %v", err)
+	}
+
+	now = now.Add(LinkCodeTTL + time.Second)
+	code, _ = s.IssueCode("telegram:1")
+	if _, err := s.Confirm(code, "discord:2"); err != nil {
+		t.Fatalf("Confirm after the lockout expired err = %v", err)
+	}
+}
+
+func TestLinkStore_MergesGroups(t *testing.T) {
+	s := NewLinkStore("")
+	code, _ := s.IssueCode("telegram:1")
+	s.Confirm(code, "discord:1")
+	code, _ = s.IssueCode("slack:1")
+	s.Confirm(code, "matrix:1")
+
+	// telegram:1's group absorbs slack:1's group.
+	code, _ = s.IssueCode("discord:1")
+	if _, err := s.Confirm(code, "slack:1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"telegram:1", "discord:1", "slack:1", "matrix:1"} {
+		if got, _ := s.Resolve(id); got != "telegram:1" {
+			t.Errorf("Resolve(%s) = %q, want telegram:1", id, got)
+		}
+	}
+}
+
+func TestAllocateRouteSession_LinkedDirectChatsShareScope(t *testing.T) {
+	s := NewLinkStore("")
+	SetLinkStore(s)
+	t.Cleanup(func() { SetLinkStore(nil) })
+	code, _ := s.IssueCode("telegram:123")
+	if _, err := s.Confirm(code, "discord:456"); err != nil {
+		t.Fatal(err)
+	}
+
+	allocate := func(ctx bus.InboundContext, dims ...string) Allocation {
+		return AllocateRouteSession(AllocationInput{
+			AgentID:       "main",
+			Context:       ctx,
+			SessionPolicy: routing.SessionPolicy{Dimensions: dims},
+		})
+	}
+	tg := allocate(bus.InboundContext{Channel: "telegram", ChatID: "123", ChatType: "direct", SenderID: "123"}, "chat")
+	dc := allocate(bus.InboundContext{Channel: "discord", Account: "bot2", ChatID: "dm-9", ChatType: "direct", SenderID: "456"}, "chat")
+	if tg.SessionKey != dc.SessionKey {
+		t.Fatalf("linked DMs use different sessions: %+v vs %+v", tg.Scope, dc.Scope)
+	}
+	if tg.Scope.Channel != LinkedChannel || tg.Scope.Values["chat"] != "direct:telegram:123" {
+		t.Fatalf("linked scope = %+v", tg.Scope)
+	}
+
+	group := allocate(bus.InboundContext{Channel: "discord", ChatID: "g1", ChatType: "group", SenderID: "456"}, "chat", "sender")
+	if group.Scope.Channel != "discord" || group.Scope.Values["sender"] != "telegram:123" {
+		t.Fatalf("group scope = %+v, want discord channel with linked sender", group.Scope)
+	}
+
+	other := allocate(bus.InboundContext{Channel: "discord", ChatID: "dm-7", ChatType: "direct", SenderID: "789"}, "chat")
+	if other.Scope.Channel != "discord" {
+		t.Fatalf("unlinked DM scope = %+v", other.Scope)
+	}
+}
+
+func TestLinkStore_MissingFileAfterDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+	s := NewLinkStore(path)
+	code, _ := s.IssueCode("telegram:1")
+	s.Confirm(code, "discord:1")
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Resolve("telegram:1"); ok {
+		t.Fatal("links should be cleared when the file is removed")
+	}
+}
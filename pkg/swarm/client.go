@@ -0,0 +1,135 @@
+package swarm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrUnauthorized is returned when a peer rejects our token.
+var ErrUnauthorized = errors.New("peer rejected token")
+
+// DefaultTaskTimeout bounds a delegated task when no timeout is configured.
+const DefaultTaskTimeout = 10 * time.Minute
+
+// maxEventLine bounds one NDJSON line of the task stream; final answers can
+// be long.
+const maxEventLine = 4 << 20
+
+// Client talks to one remote peer.
+type Client struct {
+	name    string
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for the peer at baseURL (the peer's gateway,
+// e.g. "http://10.0.0.7:18790").
+func NewClient(name, baseURL, token string) *Client {
+	return &Client{
+		name:    name,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		// No client timeout: task streams last as long as the task; callers
+		// bound them with ctx.
+		http: &http.Client{},
+	}
+}
+
+// Name returns the configured peer name.
+func (c *Client) Name() string { return c.name }
+
+// Info fetches the peer's advertisement.
+func (c *Client) Info(ctx context.Context) (*PeerInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+InfoPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var info PeerInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("peer %s: decode info: %w", c.name, err)
+	}
+	return &info, nil
+}
+
+// Delegate runs req on the peer and returns its final answer. onEvent, if
+// non-nil, receives every event of the stream, including the terminal one.
+func (c *Client) Delegate(ctx context.Context, task TaskRequest, onEvent func(Event)) (string, error) {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+TasksPath, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventLine)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var evt Event
+		if err := json.Unmarshal(line, &evt); err != nil {
+			return "", fmt.Errorf("peer %s: decode event: %w", c.name, err)
+		}
+		if onEvent != nil {
+			onEvent(evt)
+		}
+		switch evt.Type {
+		case EventResult:
+			return evt.Message, nil
+		case EventError:
+			return "", fmt.Errorf("peer %s: %s", c.name, evt.Message)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("peer %s: read stream: %w", c.name, err)
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	return "", fmt.Errorf("peer %s: stream ended without a result", c.name)
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("peer %s: %w", c.name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("peer %s: %w", c.name, ErrUnauthorized)
+		}
+		return nil, fmt.Errorf("peer %s: %s: %s", c.name, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
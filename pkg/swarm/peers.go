@@ -0,0 +1,49 @@
+package swarm
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// Peers is the set of remote instances this one may delegate to.
+type Peers struct {
+	clients map[string]*Client
+}
+
+// NewPeers builds clients for the configured peers. Peers without a name or
+// URL are skipped.
+func NewPeers(peers config.SwarmPeers) *Peers {
+	p := &Peers{clients: make(map[string]*Client, len(peers))}
+	for _, peer := range peers {
+		if peer == nil || strings.TrimSpace(peer.Name) == "" || strings.TrimSpace(peer.URL) == "" {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(peer.Name))
+		p.clients[name] = NewClient(peer.Name, peer.URL, peer.Token.String())
+	}
+	return p
+}
+
+// Get returns the client of the named peer (case-insensitive).
+func (p *Peers) Get(name string) (*Client, bool) {
+	if p == nil {
+		return nil, false
+	}
+	c, ok := p.clients[strings.ToLower(strings.TrimSpace(name))]
+	return c, ok
+}
+
+// Names lists the configured peer names in sorted order.
+func (p *Peers) Names() []string {
+	if p == nil {
+		return nil
+	}
+	names := make([]string, 0, len(p.clients))
+	for _, c := range p.clients {
+		names = append(names, c.name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,60 @@
+// Package swarm lets PicoClaw instances delegate spawn tasks to each other.
+//
+// Every instance with swarm enabled serves a small HTTP API on its gateway:
+//
+//	GET  /swarm/info   advertise the instance, its agents and their tools
+//	POST /swarm/tasks  run a task and stream its progress as NDJSON events
+//
+// Both endpoints require "Authorization: Bearer <token>". The task stream
+// ends with exactly one "result" or "error" event.
+package swarm
+
+const (
+	// PathPrefix is the subtree the server is mounted on.
+	PathPrefix = "/swarm/"
+	// InfoPath serves the peer advertisement.
+	InfoPath = "/swarm/info"
+	// TasksPath accepts delegated tasks.
+	TasksPath = "/swarm/tasks"
+)
+
+// Event types streamed back while a delegated task runs.
+const (
+	EventAccepted = "accepted" // task admitted, carries the remote task ID
+	EventProgress = "progress" // a tool started or finished on the remote side
+	EventResult   = "result"   // final answer; terminal
+	EventError    = "error"    // task failed; terminal
+)
+
+// AgentInfo advertises one agent of a peer.
+type AgentInfo struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name,omitempty"`
+	Model string   `json:"model,omitempty"`
+	Tools []string `json:"tools,omitempty"`
+}
+
+// PeerInfo is the body of GET /swarm/info.
+type PeerInfo struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Agents  []AgentInfo `json:"agents"`
+}
+
+// TaskRequest is the body of POST /swarm/tasks.
+type TaskRequest struct {
+	Task    string `json:"task"`
+	Label   string `json:"label,omitempty"`
+	AgentID string `json:"agent_id,omitempty"` // empty = the peer's default agent
+	Origin  string `json:"origin,omitempty"`   // name of the delegating instance
+}
+
+// Event is one line of the task stream.
+type Event struct {
+	Type    string `json:"type"`
+	TaskID  string `json:"task_id,omitempty"`
+	AgentID string `json:"agent_id,omitempty"`
+	Tool    string `json:"tool,omitempty"`
+	Message string `json:"message,omitempty"`
+	IsError bool   `json:"is_error,omitempty"`
+}
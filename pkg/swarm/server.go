@@ -0,0 +1,192 @@
+package swarm
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// maxTaskBody bounds the size of a delegated task request.
+const maxTaskBody = 1 << 20
+
+// Executor runs delegated tasks on the local agents.
+type Executor interface {
+	// Agents lists the agents remote peers may target.
+	Agents() []AgentInfo
+	// RunTask runs req to completion and returns the final answer. progress
+	// may be called from any goroutine until RunTask returns.
+	RunTask(ctx context.Context, taskID string, req TaskRequest, progress func(Event)) (string, error)
+}
+
+// Server serves the peer API. It is an http.Handler meant to be mounted on
+// PathPrefix of the gateway's shared HTTP server.
+type Server struct {
+	name    string
+	version string
+	token   string
+	exec    Executor
+	timeout time.Duration
+	nextID  atomic.Uint64
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithVersion sets the version advertised in PeerInfo.
+func WithVersion(version string) ServerOption {
+	return func(s *Server) { s.version = version }
+}
+
+// WithTaskTimeout bounds how long a delegated task may run.
+func WithTaskTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		if timeout > 0 {
+			s.timeout = timeout
+		}
+	}
+}
+
+// NewServer creates a peer API server. Requests are rejected unless they
+// carry token, so an empty token disables the API entirely.
+func NewServer(name, token string, exec Executor, opts ...ServerOption) *Server {
+	s := &Server{
+		name:    name,
+		token:   token,
+		exec:    exec,
+		timeout: DefaultTaskTimeout,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case InfoPath:
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(PeerInfo{
+			Name:    s.name,
+			Version: s.version,
+			Agents:  s.exec.Agents(),
+		})
+	case TasksPath:
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleTask(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return false
+	}
+	given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) == 1
+}
+
+func (s *Server) handleTask(w http.ResponseWriter, r *http.Request) {
+	var req TaskRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTaskBody)).Decode(&req); err != nil {
+		http.Error(w, "invalid task request", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Task) == "" {
+		http.Error(w, "task is required", http.StatusBadRequest)
+		return
+	}
+
+	taskID := fmt.Sprintf("%s-task-%d", s.name, s.nextID.Add(1))
+	logger.InfoCF("swarm", "Accepted delegated task", map[string]any{
+		"task_id":  taskID,
+		"agent_id": req.AgentID,
+		"origin":   req.Origin,
+		"label":    req.Label,
+	})
+
+	// The gateway's shared server has a short write timeout; a task stream
+	// lives as long as the task does.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	// Events may arrive from the executor's goroutines; the channel
+	// serializes them onto the response.
+	events := make(chan Event, 32)
+	writeDone := make(chan struct{})
+	go func() {
+		defer close(writeDone)
+		enc := json.NewEncoder(w)
+		for evt := range events {
+			if err := enc.Encode(evt); err != nil {
+				continue
+			}
+			_ = rc.Flush()
+		}
+	}()
+
+	events <- Event{Type: EventAccepted, TaskID: taskID, AgentID: req.AgentID}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		closed bool
+	)
+	progress := func(evt Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		evt.Type = EventProgress
+		evt.TaskID = taskID
+		select {
+		case events <- evt:
+		default: // drop progress rather than stall the task
+		}
+	}
+
+	result, err := s.exec.RunTask(ctx, taskID, req, progress)
+	mu.Lock()
+	closed = true
+	mu.Unlock()
+
+	final := Event{Type: EventResult, TaskID: taskID, AgentID: req.AgentID, Message: result}
+	if err != nil {
+		final = Event{Type: EventError, TaskID: taskID, AgentID: req.AgentID, Message: err.Error(), IsError: true}
+	}
+	events <- final
+	close(events)
+	<-writeDone
+
+	logger.InfoCF("swarm", "Delegated task finished", map[string]any{
+		"task_id": taskID,
+		"type":    final.Type,
+	})
+}
@@ -0,0 +1,114 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+type fakeExecutor struct {
+	err error
+}
+
+func (f *fakeExecutor) Agents() []AgentInfo {
+	return []AgentInfo{{ID: "main", Model: "test-model", Tools: []string{"read_file"}}}
+}
+
+func (f *fakeExecutor) RunTask(
+	_ context.Context,
+	_ string,
+	req TaskRequest,
+	progress func(Event),
+) (string, error) {
+	progress(Event{AgentID: "main", Tool: "read_file", Message: "started"})
+	if f.err != nil {
+		return "", f.err
+	}
+	return "done: " + req.Task, nil
+}
+
+func startTestServer(t *testing.T, exec Executor) *Client {
+	t.Helper()
+	srv := httptest.NewServer(NewServer("remote", "secret", exec))
+	t.Cleanup(srv.Close)
+	return NewClient("remote", srv.URL, "secret")
+}
+
+func TestClient_InfoAdvertisesAgents(t *testing.T) {
+	client := startTestServer(t, &fakeExecutor{})
+
+	info, err := client.Info(context.Background())
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if info.Name != "remote" || len(info.Agents) != 1 || info.Agents[0].Tools[0] != "read_file" {
+		t.Fatalf("info = %+v", info)
+	}
+}
+
+func TestClient_DelegateStreamsProgressAndResult(t *testing.T) {
+	client := startTestServer(t, &fakeExecutor{})
+
+	var events []Event
+	result, err := client.Delegate(context.Background(), TaskRequest{Task: "sum"}, func(evt Event) {
+		events = append(events, evt)
+	})
+	if err != nil {
+		t.Fatalf("Delegate() error = %v", err)
+	}
+	if result != "done: sum" {
+		t.Fatalf("result = %q", result)
+	}
+	if len(events) != 3 ||
+		events[0].Type != EventAccepted ||
+		events[1].Type != EventProgress || events[1].Tool != "read_file" ||
+		events[2].Type != EventResult {
+		t.Fatalf("events = %+v", events)
+	}
+	if events[0].TaskID == "" || events[1].TaskID != events[0].TaskID {
+		t.Fatalf("task IDs not propagated: %+v", events)
+	}
+}
+
+func TestClient_DelegateReturnsRemoteError(t *testing.T) {
+	client := startTestServer(t, &fakeExecutor{err: errors.New("boom")})
+
+	if _, err := client.Delegate(context.Background(), TaskRequest{Task: "x"}, nil); err == nil {
+		t.Fatal("expected remote error")
+	}
+}
+
+func TestServer_RejectsBadToken(t *testing.T) {
+	srv := httptest.NewServer(NewServer("remote", "secret", &fakeExecutor{}))
+	defer srv.Close()
+
+	_, err := NewClient("remote", srv.URL, "wrong").Info(context.Background())
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Info() error = %v, want ErrUnauthorized", err)
+	}
+
+	// An empty server token disables the API rather than accepting anyone.
+	open := httptest.NewServer(NewServer("remote", "", &fakeExecutor{}))
+	defer open.Close()
+	if _, err := NewClient("remote", open.URL, "").Info(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Info() with empty token error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestNewPeers_SkipsIncompleteEntries(t *testing.T) {
+	peers := NewPeers(config.SwarmPeers{
+		{Name: "Lab", URL: "http://lab:18790"},
+		{Name: "", URL: "http://nameless"},
+		{Name: "nourl"},
+	})
+
+	if got := peers.Names(); len(got) != 1 || got[0] != "Lab" {
+		t.Fatalf("Names() = %v", got)
+	}
+	if _, ok := peers.Get("lab"); !ok {
+		t.Fatal("Get should be case-insensitive")
+	}
+}
@@ -294,6 +294,18 @@ func (t *CronTool) enableJob(args map[string]any, enable bool) *ToolResult {
 
 // ExecuteJob executes a cron job through the agent
 func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
+	_, err := t.RunJob(ctx, job)
+	// Command failures are reported to the channel, not to the scheduler.
+	if err != nil && job.Payload.Command == "" {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return "ok"
+}
+
+// RunJob executes a cron job and returns its output for the run history.
+// Command jobs publish their output to the target channel and report a
+// failed command as an error; agent jobs return the agent's response.
+func (t *CronTool) RunJob(ctx context.Context, job *cron.CronJob) (string, error) {
 	// Get channel/chatID from job payload
 	channel := job.Payload.Channel
 	chatID := job.Payload.To
@@ -316,7 +328,7 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 				Context: bus.NewOutboundContext(channel, chatID, ""),
 				Content: output,
 			})
-			return "ok"
+			return output, fmt.Errorf("command execution is disabled")
 		}
 
 		args := map[string]any{
@@ -327,8 +339,10 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 
 		result := t.execTool.Execute(ctx, args)
 		var output string
+		var runErr error
 		if result.IsError {
 			output = fmt.Sprintf("Error executing scheduled command: %s", result.ForLLM)
+			runErr = fmt.Errorf("command failed: %s", utils.Truncate(result.ForLLM, 200))
 		} else {
 			output = fmt.Sprintf("Scheduled command '%s' executed:\n%s", job.Payload.Command, result.ForLLM)
 		}
@@ -339,7 +353,7 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 			Context: bus.NewOutboundContext(channel, chatID, ""),
 			Content: output,
 		})
-		return "ok"
+		return result.ForLLM, runErr
 	}
 
 	sessionKey := fmt.Sprintf("agent:cron-%s-%s", job.ID, uuid.New().String())
@@ -353,11 +367,11 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 		chatID,
 	)
 	if err != nil {
-		return fmt.Sprintf("Error: %v", err)
+		return "", err
 	}
 
 	if response != "" {
 		t.executor.PublishResponseIfNeeded(ctx, channel, chatID, sessionKey, response)
 	}
-	return "ok"
+	return response, nil
 }
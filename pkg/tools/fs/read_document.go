@@ -0,0 +1,173 @@
+package fstools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/document"
+)
+
+// DefaultReadDocumentMaxChars bounds the text returned by one read_document
+// call when no limit is configured.
+const DefaultReadDocumentMaxChars = 20000
+
+// ReadDocumentTool extracts readable text from office documents, PDFs and
+// e-books. Unlike read_file it understands the container format, so the
+// agent sees paragraphs, tables and page/sheet/slide boundaries instead of
+// compressed bytes.
+type ReadDocumentTool struct {
+	workspace  string
+	restrict   bool
+	maxChars   int
+	allowPaths []*regexp.Regexp
+}
+
+func NewReadDocumentTool(
+	workspace string,
+	restrict bool,
+	maxChars int,
+	allowPaths ...[]*regexp.Regexp,
+) *ReadDocumentTool {
+	if maxChars <= 0 {
+		maxChars = DefaultReadDocumentMaxChars
+	}
+	var patterns []*regexp.Regexp
+	if len(allowPaths) > 0 {
+		patterns = allowPaths[0]
+	}
+	return &ReadDocumentTool{
+		workspace:  workspace,
+		restrict:   restrict,
+		maxChars:   maxChars,
+		allowPaths: patterns,
+	}
+}
+
+func (t *ReadDocumentTool) Name() string { return "read_document" }
+
+func (t *ReadDocumentTool) Description() string {
+	return "Extract the text of a document: PDF, DOCX, XLSX, PPTX, ODT or EPUB. " +
+		"Tables are returned as Markdown rows. Use `pages` to read part of a long document " +
+		"(pages, sheets, slides or chapters depending on the format) and `sheet` to pick a spreadsheet sheet by name."
+}
+
+func (t *ReadDocumentTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path to the document. Relative paths are resolved from workspace.",
+			},
+			"pages": map[string]any{
+				"type":        "string",
+				"description": "Sections to read, 1-based: \"3\", \"2-5\", \"10-\" or \"1,4,7-9\". Defaults to all.",
+			},
+			"sheet": map[string]any{
+				"type":        "string",
+				"description": "Sheet or chapter title to read instead of a page range.",
+			},
+			"max_chars": map[string]any{
+				"type":        "integer",
+				"description": "Maximum number of characters to return.",
+				"default":     t.maxChars,
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+
+func (t *ReadDocumentTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	path, _ := args["path"].(string)
+	if strings.TrimSpace(path) == "" {
+		return ErrorResult("path is required")
+	}
+
+	var pages string
+	switch v := args["pages"].(type) {
+	case string:
+		pages = v
+	case float64:
+		pages = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	sel, err := document.ParseRange(pages)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	sheet, _ := args["sheet"].(string)
+
+	maxChars, err := getInt64Arg(args, "max_chars", int64(t.maxChars))
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if maxChars <= 0 || maxChars > int64(t.maxChars) {
+		maxChars = int64(t.maxChars)
+	}
+
+	resolved, err := validatePathWithAllowPaths(path, t.workspace, t.restrict, t.allowPaths)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid path: %v", err))
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("file not found: %v", err))
+	}
+	if info.IsDir() {
+		return ErrorResult("path is a directory, expected a document")
+	}
+
+	doc, err := document.ExtractFile(resolved, "")
+	if errors.Is(err, document.ErrUnsupported) {
+		return ErrorResult("unsupported document format; read_document handles PDF, DOCX, XLSX, PPTX, ODT and EPUB")
+	}
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to extract document: %v", err))
+	}
+
+	text, shown, truncated := doc.Render(sel, sheet, int(maxChars))
+	if shown == 0 {
+		if sheet != "" {
+			return ErrorResult(fmt.Sprintf("no section titled %q; document has %s", sheet, sectionTitles(doc)))
+		}
+		return ErrorResult(fmt.Sprintf("no sections in range %q; document has %s",
+			pages, document.KindLabel(sectionKind(doc), len(doc.Sections))))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", filepath.Base(resolved), doc.Describe())
+	if shown < len(doc.Sections) {
+		fmt.Fprintf(&b, "Showing %d of %d.\n", shown, len(doc.Sections))
+	}
+	b.WriteString("\n")
+	b.WriteString(text)
+	if truncated {
+		fmt.Fprintf(&b, "\n\n[truncated at %d characters; request fewer pages to read the rest]", maxChars)
+	}
+	return NewToolResult(b.String())
+}
+
+func sectionKind(doc *document.Document) string {
+	if len(doc.Sections) == 0 {
+		return document.KindSection
+	}
+	return doc.Sections[0].Kind
+}
+
+func sectionTitles(doc *document.Document) string {
+	var titles []string
+	for _, s := range doc.Sections {
+		if s.Title != "" {
+			titles = append(titles, strconv.Quote(s.Title))
+		}
+	}
+	if len(titles) == 0 {
+		return "no titled sections"
+	}
+	return strings.Join(titles, ", ")
+}
@@ -0,0 +1,131 @@
+package fstools
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeWorkbook writes a two-sheet XLSX file and returns its path.
+func writeWorkbook(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "budget.xlsx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="r"><sheets>` +
+			`<sheet name="Income" r:id="rId1"/><sheet name="Costs" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships>` +
+			`<Relationship Id="rId1" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Target="worksheets/sheet2.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row><c r="A1" t="inlineStr"><is><t>salary</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData><row><c r="A1" t="inlineStr"><is><t>rent</t></is></c></row></sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadDocument_PathRequired(t *testing.T) {
+	tool := NewReadDocumentTool(t.TempDir(), false, 0)
+	result := tool.Execute(context.Background(), map[string]any{})
+	if !result.IsError {
+		t.Fatal("expected error for missing path")
+	}
+}
+
+func TestReadDocument_AllSheets(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkbook(t, dir)
+	tool := NewReadDocumentTool(dir, true, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{"path": "budget.xlsx"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	for _, want := range []string{"XLSX, 2 sheets", "--- Sheet 1: Income ---", "salary", "--- Sheet 2: Costs ---", "rent"} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("output missing %q:\n%s", want, result.ForLLM)
+		}
+	}
+}
+
+func TestReadDocument_SelectBySheetAndRange(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkbook(t, dir)
+	tool := NewReadDocumentTool(dir, true, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{"path": "budget.xlsx", "sheet": "costs"})
+	if result.IsError || strings.Contains(result.ForLLM, "salary") || !strings.Contains(result.ForLLM, "rent") {
+		t.Fatalf("sheet selection failed: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "Showing 1 of 2.") {
+		t.Errorf("expected partial-view note: %s", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"path": "budget.xlsx", "pages": float64(1)})
+	if result.IsError || !strings.Contains(result.ForLLM, "salary") || strings.Contains(result.ForLLM, "rent") {
+		t.Fatalf("range selection failed: %s", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"path": "budget.xlsx", "pages": "5-"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "2 sheets") {
+		t.Fatalf("expected out-of-range error, got: %s", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"path": "budget.xlsx", "sheet": "Taxes"})
+	if !result.IsError || !strings.Contains(result.ForLLM, `"Income", "Costs"`) {
+		t.Fatalf("expected unknown-sheet error listing sheets, got: %s", result.ForLLM)
+	}
+}
+
+func TestReadDocument_Truncates(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkbook(t, dir)
+	tool := NewReadDocumentTool(dir, true, 30)
+
+	result := tool.Execute(context.Background(), map[string]any{"path": "budget.xlsx", "max_chars": 1000})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "[truncated at 30 characters") {
+		t.Errorf("expected truncation capped by tool limit: %s", result.ForLLM)
+	}
+}
+
+func TestReadDocument_Unsupported(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello"), 0o644)
+	tool := NewReadDocumentTool(dir, true, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{"path": "notes.txt"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "unsupported document format") {
+		t.Fatalf("expected unsupported error, got: %s", result.ForLLM)
+	}
+}
+
+func TestReadDocument_RestrictedOutsideWorkspace(t *testing.T) {
+	outside := t.TempDir()
+	path := writeWorkbook(t, outside)
+	tool := NewReadDocumentTool(t.TempDir(), true, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{"path": path})
+	if !result.IsError || !strings.Contains(result.ForLLM, "access denied") {
+		t.Fatalf("expected access denied, got: %s", result.ForLLM)
+	}
+}
@@ -16,9 +16,13 @@ type (
 	AppendFileTool    = fstools.AppendFileTool
 	LoadImageTool     = fstools.LoadImageTool
 	SendFileTool      = fstools.SendFileTool
+	ReadDocumentTool  = fstools.ReadDocumentTool
 )
 
-const MaxReadFileSize = fstools.MaxReadFileSize
+const (
+	MaxReadFileSize             = fstools.MaxReadFileSize
+	DefaultReadDocumentMaxChars = fstools.DefaultReadDocumentMaxChars
+)
 
 func NewReadFileTool(
 	workspace string,
@@ -98,3 +102,12 @@ func NewSendFileTool(
 ) *SendFileTool {
 	return fstools.NewSendFileTool(workspace, restrict, maxFileSize, store, allowPaths...)
 }
+
+func NewReadDocumentTool(
+	workspace string,
+	restrict bool,
+	maxChars int,
+	allowPaths ...[]*regexp.Regexp,
+) *ReadDocumentTool {
+	return fstools.NewReadDocumentTool(workspace, restrict, maxChars, allowPaths...)
+}
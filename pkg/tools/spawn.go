@@ -3,11 +3,13 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 )
 
 type SpawnTool struct {
 	spawner        SubTurnSpawner
+	manager        *SubagentManager
 	defaultModel   string
 	maxTokens      int
 	temperature    float64
@@ -22,6 +24,7 @@ func NewSpawnTool(manager *SubagentManager) *SpawnTool {
 		return &SpawnTool{}
 	}
 	return &SpawnTool{
+		manager:      manager,
 		defaultModel: manager.defaultModel,
 		maxTokens:    manager.maxTokens,
 		temperature:  manager.temperature,
@@ -42,26 +45,42 @@ func (t *SpawnTool) Description() string {
 }
 
 func (t *SpawnTool) Parameters() map[string]any {
-	return map[string]any{
-		"type": "object",
-		"properties": map[string]any{
-			"task": map[string]any{
-				"type":        "string",
-				"description": "The task for subagent to complete",
-			},
-			"label": map[string]any{
-				"type":        "string",
-				"description": "Optional short label for the task (for display)",
-			},
-			"agent_id": map[string]any{
-				"type":        "string",
-				"description": "Optional target agent ID to delegate the task to",
-			},
+	properties := map[string]any{
+		"task": map[string]any{
+			"type":        "string",
+			"description": "The task for subagent to complete",
+		},
+		"label": map[string]any{
+			"type":        "string",
+			"description": "Optional short label for the task (for display)",
+		},
+		"agent_id": map[string]any{
+			"type":        "string",
+			"description": "Optional target agent ID to delegate the task to",
 		},
-		"required": []string{"task"},
+	}
+	if peers := t.remotePeers(); len(peers) > 0 {
+		properties["peer"] = map[string]any{
+			"type": "string",
+			"description": "Optional remote PicoClaw peer to run the task on; agent_id then names " +
+				"an agent of that peer. Available peers: " + strings.Join(peers, ", "),
+			"enum": peers,
+		}
+	}
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{"task"},
 	}
 }
 
+func (t *SpawnTool) remotePeers() []string {
+	if t.manager == nil {
+		return nil
+	}
+	return t.manager.RemotePeers()
+}
+
 func (t *SpawnTool) SetAllowlistChecker(check func(targetAgentID string) bool) {
 	t.allowlistCheck = check
 }
@@ -92,6 +111,11 @@ func (t *SpawnTool) execute(
 
 	label, _ := args["label"].(string)
 	agentID, _ := args["agent_id"].(string)
+	peer, _ := args["peer"].(string)
+
+	if peer = strings.TrimSpace(peer); peer != "" {
+		return t.executeRemote(ctx, peer, task, label, agentID, cb)
+	}
 
 	// Check allowlist if targeting a specific agent
 	if agentID != "" && t.allowlistCheck != nil {
@@ -150,3 +174,27 @@ Task: %s`,
 	// Fallback: spawner not configured
 	return ErrorResult("Subagent manager not configured")
 }
+
+// executeRemote delegates the task to a remote peer. The peer enforces its
+// own agent allowlist, so the local one does not apply.
+func (t *SpawnTool) executeRemote(
+	ctx context.Context,
+	peer, task, label, agentID string,
+	cb AsyncCallback,
+) *ToolResult {
+	if t.manager == nil {
+		return ErrorResult("Subagent manager not configured")
+	}
+	peers := t.manager.RemotePeers()
+	idx := slices.IndexFunc(peers, func(name string) bool { return strings.EqualFold(name, peer) })
+	if idx < 0 {
+		return ErrorResult(fmt.Sprintf("unknown peer '%s'", peer))
+	}
+	peer = peers[idx]
+
+	msg, err := t.manager.SpawnRemote(ctx, peer, task, label, agentID, ToolChannel(ctx), ToolChatID(ctx), cb)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Spawn failed: %v", err)).WithError(err)
+	}
+	return AsyncResult(msg)
+}
@@ -155,6 +155,9 @@ func spawnStatusFormatTask(task *SubagentTask) string {
 	if task.AgentID != "" {
 		header += fmt.Sprintf("  agent=%s", task.AgentID)
 	}
+	if task.Peer != "" {
+		header += fmt.Sprintf("  peer=%s", task.Peer)
+	}
 	if task.Created > 0 {
 		created := time.UnixMilli(task.Created).UTC().Format("2006-01-02 15:04:05 UTC")
 		header += fmt.Sprintf("  created=%s", created)
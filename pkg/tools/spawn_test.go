@@ -96,3 +96,56 @@ func TestSpawnTool_Execute_NilManager(t *testing.T) {
 		t.Errorf("Error message should mention manager not configured, got: %s", result.ForLLM)
 	}
 }
+
+func TestSpawnTool_Execute_RemotePeer(t *testing.T) {
+	manager := NewSubagentManager(&MockLLMProvider{}, "test-model", "/tmp/test")
+	delegated := make(chan string, 1)
+	manager.SetRemoteDelegator([]string{"lab"}, func(
+		_ context.Context,
+		peer, task, _, agentID string,
+	) (*ToolResult, error) {
+		delegated <- peer + "/" + agentID + ": " + task
+		return &ToolResult{ForLLM: "remote done", ForUser: "remote done"}, nil
+	})
+	tool := NewSpawnTool(manager)
+
+	props := tool.Parameters()["properties"].(map[string]any)
+	if _, ok := props["peer"]; !ok {
+		t.Fatal("expected peer parameter when remote delegation is configured")
+	}
+
+	done := make(chan *ToolResult, 1)
+	result := tool.ExecuteAsync(context.Background(), map[string]any{
+		"task":     "index the repo",
+		"peer":     "LAB",
+		"agent_id": "coder",
+	}, func(_ context.Context, r *ToolResult) { done <- r })
+	if result.IsError || !result.Async {
+		t.Fatalf("result = %+v, want async acknowledgment", result)
+	}
+
+	if got := <-delegated; got != "lab/coder: index the repo" {
+		t.Fatalf("delegated = %q", got)
+	}
+	if r := <-done; r.ForLLM != "remote done" {
+		t.Fatalf("callback result = %+v", r)
+	}
+
+	tasks := manager.ListTaskCopies()
+	if len(tasks) != 1 || tasks[0].Peer != "lab" || tasks[0].Status != "completed" {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+}
+
+func TestSpawnTool_Execute_UnknownPeer(t *testing.T) {
+	manager := NewSubagentManager(&MockLLMProvider{}, "test-model", "/tmp/test")
+	tool := NewSpawnTool(manager)
+
+	if _, ok := tool.Parameters()["properties"].(map[string]any)["peer"]; ok {
+		t.Fatal("peer parameter should be hidden without remote delegation")
+	}
+	result := tool.Execute(context.Background(), map[string]any{"task": "x", "peer": "lab"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "unknown peer") {
+		t.Fatalf("result = %+v, want unknown peer error", result)
+	}
+}
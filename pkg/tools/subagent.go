@@ -37,6 +37,7 @@ type SubagentTask struct {
 	Task          string
 	Label         string
 	AgentID       string
+	Peer          string // remote PicoClaw peer running the task; empty = local
 	OriginChannel string
 	OriginChatID  string
 	Status        string
@@ -53,6 +54,13 @@ type SpawnSubTurnFunc func(
 	hasMaxTokens, hasTemperature bool,
 ) (*ToolResult, error)
 
+// RemoteDelegateFunc runs a task on a remote PicoClaw peer and returns its
+// result. agentID names an agent of the peer, not a local one.
+type RemoteDelegateFunc func(
+	ctx context.Context,
+	peer, task, label, agentID string,
+) (*ToolResult, error)
+
 type SubagentManager struct {
 	tasks          map[string]*SubagentTask
 	mu             sync.RWMutex
@@ -67,6 +75,8 @@ type SubagentManager struct {
 	hasTemperature bool
 	nextID         int
 	spawner        SpawnSubTurnFunc
+	remote         RemoteDelegateFunc
+	remotePeers    []string
 
 	// mediaResolver resolves media:// refs in tool-loop messages before
 	// each LLM call in the legacy RunToolLoop fallback path.
@@ -96,6 +106,25 @@ func (sm *SubagentManager) SetSpawner(spawner SpawnSubTurnFunc) {
 	sm.spawner = spawner
 }
 
+// SetRemoteDelegator enables delegating tasks to the named remote peers.
+func (sm *SubagentManager) SetRemoteDelegator(peers []string, delegate RemoteDelegateFunc) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.remote = delegate
+	sm.remotePeers = append([]string(nil), peers...)
+}
+
+// RemotePeers lists the peers tasks can be delegated to, or nil when remote
+// delegation is not configured.
+func (sm *SubagentManager) RemotePeers() []string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	if sm.remote == nil {
+		return nil
+	}
+	return append([]string(nil), sm.remotePeers...)
+}
+
 // SetMediaResolver injects a message preprocessor that resolves media:// refs
 // into LLM-ready content before each tool-loop iteration.
 // This is only used by the legacy RunToolLoop fallback path.
@@ -164,6 +193,45 @@ func (sm *SubagentManager) Spawn(
 	return fmt.Sprintf("Spawned subagent for task: %s", task), nil
 }
 
+// SpawnRemote records a task and delegates it to a remote peer in the
+// background. The task shows up in ListTasks like a local one, and callback
+// receives the peer's result.
+func (sm *SubagentManager) SpawnRemote(
+	ctx context.Context,
+	peer, task, label, agentID, originChannel, originChatID string,
+	callback AsyncCallback,
+) (string, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.remote == nil {
+		return "", fmt.Errorf("remote delegation is not configured")
+	}
+
+	taskID := fmt.Sprintf("subagent-%d", sm.nextID)
+	sm.nextID++
+
+	subagentTask := &SubagentTask{
+		ID:            taskID,
+		Task:          task,
+		Label:         label,
+		AgentID:       agentID,
+		Peer:          peer,
+		OriginChannel: originChannel,
+		OriginChatID:  originChatID,
+		Status:        "running",
+		Created:       time.Now().UnixMilli(),
+	}
+	sm.tasks[taskID] = subagentTask
+
+	go sm.runTask(ctx, subagentTask, callback)
+
+	if label != "" {
+		return fmt.Sprintf("Delegated subagent '%s' to peer %s for task: %s", label, peer, task), nil
+	}
+	return fmt.Sprintf("Delegated subagent to peer %s for task: %s", peer, task), nil
+}
+
 func (sm *SubagentManager) runTask(
 	ctx context.Context,
 	task *SubagentTask,
@@ -188,6 +256,7 @@ func (sm *SubagentManager) runTask(
 
 	sm.mu.RLock()
 	spawner := sm.spawner
+	remote := sm.remote
 	tools := sm.tools
 	maxIter := sm.maxIterations
 	maxTokens := sm.maxTokens
@@ -200,7 +269,13 @@ func (sm *SubagentManager) runTask(
 	var result *ToolResult
 	var err error
 
-	if spawner != nil {
+	if task.Peer != "" {
+		if remote == nil {
+			err = fmt.Errorf("remote delegation is not configured")
+		} else {
+			result, err = remote(ctx, task.Peer, task.Task, task.Label, task.AgentID)
+		}
+	} else if spawner != nil {
 		result, err = spawner(
 			ctx,
 			task.Task,
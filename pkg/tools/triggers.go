@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/triggers"
+)
+
+// TriggersTool lets the agent react to events other than chat messages:
+// file changes, new feed entries and changed JSON values.
+type TriggersTool struct {
+	service   *triggers.Service
+	workspace string
+	restrict  bool
+}
+
+// NewTriggersTool creates a TriggersTool backed by service. When restrict is
+// set, watched paths must stay inside the workspace.
+func NewTriggersTool(service *triggers.Service, workspace string, restrict bool) *TriggersTool {
+	return &TriggersTool{service: service, workspace: workspace, restrict: restrict}
+}
+
+func (t *TriggersTool) Name() string {
+	return "triggers"
+}
+
+func (t *TriggersTool) Description() string {
+	return "Run an agent turn when something happens instead of on a schedule. Kinds: 'file' watches a workspace file or directory, 'feed' polls an RSS/Atom feed for new entries, 'http' polls a JSON URL and fires when the value (optionally at 'json_path') changes. The 'prompt' is a Go template; useful fields are {{.Summary}}, {{.Paths}}, {{range .Items}}{{.Title}} {{.Link}}{{end}}, {{.Value}} and {{.Previous}}. Results are delivered to the current chat."
+}
+
+func (t *TriggersTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"add", "list", "remove", "enable", "disable"},
+				"description": "Action to perform.",
+			},
+			"kind": map[string]any{
+				"type":        "string",
+				"enum":        []string{triggers.KindFile, triggers.KindFeed, triggers.KindHTTP},
+				"description": "Trigger kind (for add).",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Unique trigger name (for add).",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File or directory to watch, relative to the workspace (kind=file).",
+			},
+			"url": map[string]any{
+				"type":        "string",
+				"description": "Feed or JSON URL to poll (kind=feed or http).",
+			},
+			"json_path": map[string]any{
+				"type":        "string",
+				"description": "Optional dot path of the watched value, e.g. 'data.0.status' (kind=http).",
+			},
+			"interval_seconds": map[string]any{
+				"type":        "integer",
+				"description": "Poll interval in seconds (kind=feed or http, default 300).",
+			},
+			"prompt": map[string]any{
+				"type":        "string",
+				"description": "Optional prompt template for the agent turn.",
+			},
+			"trigger_id": map[string]any{
+				"type":        "string",
+				"description": "Trigger ID (for remove/enable/disable).",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *TriggersTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	switch action {
+	case "add":
+		return t.add(ctx, args)
+	case "list":
+		return t.list()
+	case "remove":
+		id, _ := args["trigger_id"].(string)
+		if id == "" {
+			return ErrorResult("trigger_id is required for remove")
+		}
+		if err := t.service.Remove(id); err != nil {
+			return ErrorResult(err.Error())
+		}
+		return SilentResult(fmt.Sprintf("Trigger removed: %s", id))
+	case "enable", "disable":
+		id, _ := args["trigger_id"].(string)
+		if id == "" {
+			return ErrorResult("trigger_id is required for enable/disable")
+		}
+		trigger, err := t.service.SetEnabled(id, action == "enable")
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		return SilentResult(fmt.Sprintf("Trigger '%s' %sd", trigger.Name, action))
+	case "":
+		return ErrorResult("action is required")
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+func (t *TriggersTool) add(ctx context.Context, args map[string]any) *ToolResult {
+	channel := ToolChannel(ctx)
+	chatID := ToolChatID(ctx)
+	if channel == "" || chatID == "" {
+		return ErrorResult("no session context (channel/chat_id not set). Use this tool in an active conversation.")
+	}
+
+	def := triggers.Trigger{
+		Kind:     stringArg(args, "kind"),
+		Name:     stringArg(args, "name"),
+		Path:     stringArg(args, "path"),
+		URL:      stringArg(args, "url"),
+		JSONPath: stringArg(args, "json_path"),
+		Prompt:   stringArg(args, "prompt"),
+		Channel:  channel,
+		ChatID:   chatID,
+	}
+	if interval, ok := args["interval_seconds"].(float64); ok && interval > 0 {
+		def.IntervalSeconds = int(interval)
+	}
+
+	if def.Kind == triggers.KindFile && t.restrict {
+		if err := t.checkWorkspacePath(def.Path); err != nil {
+			return ErrorResult(err.Error())
+		}
+	}
+
+	trigger, err := t.service.Add(def)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error adding trigger: %v", err))
+	}
+	return SilentResult(fmt.Sprintf("Trigger added: %s (id: %s, %s)", trigger.Name, trigger.ID, trigger.Describe()))
+}
+
+func (t *TriggersTool) list() *ToolResult {
+	all := t.service.List()
+	if len(all) == 0 {
+		return SilentResult("No triggers")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Triggers:\n")
+	for _, trigger := range all {
+		status := "enabled"
+		if !trigger.Enabled {
+			status = "disabled"
+		}
+		if trigger.Source == triggers.SourceConfig {
+			status += ", from config"
+		}
+		fmt.Fprintf(&sb, "- %s (id: %s, %s, %s)\n", trigger.Name, trigger.ID, trigger.Describe(), status)
+	}
+	return SilentResult(sb.String())
+}
+
+func (t *TriggersTool) checkWorkspacePath(path string) error {
+	if path == "" {
+		return nil
+	}
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(t.workspace, abs)
+	}
+	rel, err := filepath.Rel(t.workspace, filepath.Clean(abs))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("path %q is outside the workspace", path)
+	}
+	return nil
+}
+
+func stringArg(args map[string]any, key string) string {
+	v, _ := args[key].(string)
+	return strings.TrimSpace(v)
+}
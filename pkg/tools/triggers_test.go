@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/triggers"
+)
+
+func newTestTriggersTool(t *testing.T) *TriggersTool {
+	t.Helper()
+	workspace := t.TempDir()
+	service := triggers.NewService(filepath.Join(workspace, "triggers", "triggers.json"), triggers.Options{
+		Workspace: workspace,
+	})
+	return NewTriggersTool(service, workspace, true)
+}
+
+func TestTriggersTool_AddListRemove(t *testing.T) {
+	tool := newTestTriggersTool(t)
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+
+	result := tool.Execute(ctx, map[string]any{
+		"action": "add",
+		"kind":   "feed",
+		"name":   "blog",
+		"url":    "https://example.com/feed.xml",
+	})
+	if result.IsError {
+		t.Fatalf("add failed: %s", result.ForLLM)
+	}
+
+	all := tool.service.List()
+	if len(all) != 1 {
+		t.Fatalf("triggers = %d, want 1", len(all))
+	}
+	if all[0].Channel != "telegram" || all[0].ChatID != "chat-1" {
+		t.Fatalf("delivery = %s/%s, want telegram/chat-1", all[0].Channel, all[0].ChatID)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "list"})
+	if !strings.Contains(result.ForLLM, "blog") {
+		t.Fatalf("list output missing trigger: %s", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "remove", "trigger_id": all[0].ID})
+	if result.IsError {
+		t.Fatalf("remove failed: %s", result.ForLLM)
+	}
+	if len(tool.service.List()) != 0 {
+		t.Fatal("trigger was not removed")
+	}
+}
+
+func TestTriggersTool_RejectsPathOutsideWorkspace(t *testing.T) {
+	tool := newTestTriggersTool(t)
+	ctx := WithToolContext(context.Background(), "cli", "direct")
+
+	result := tool.Execute(ctx, map[string]any{
+		"action": "add",
+		"kind":   "file",
+		"name":   "etc",
+		"path":   "../../etc",
+	})
+	if !result.IsError {
+		t.Fatalf("expected error for path outside workspace, got %s", result.ForLLM)
+	}
+}
+
+func TestTriggersTool_RequiresSessionContext(t *testing.T) {
+	tool := newTestTriggersTool(t)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"action": "add",
+		"kind":   "file",
+		"name":   "inbox",
+		"path":   "inbox",
+	})
+	if !result.IsError {
+		t.Fatal("expected error without channel context")
+	}
+}
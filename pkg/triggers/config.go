@@ -0,0 +1,32 @@
+package triggers
+
+import (
+	"path/filepath"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// StorePath returns the trigger store of a workspace.
+func StorePath(workspace string) string {
+	return filepath.Join(workspace, "triggers", "triggers.json")
+}
+
+// FromConfig converts the triggers defined in config.json.
+func FromConfig(defs []config.TriggerConfig) []Trigger {
+	out := make([]Trigger, 0, len(defs))
+	for _, tc := range defs {
+		out = append(out, Trigger{
+			Name:            tc.Name,
+			Kind:            tc.Kind,
+			Enabled:         !tc.Disabled,
+			Path:            tc.Path,
+			URL:             tc.URL,
+			JSONPath:        tc.JSONPath,
+			IntervalSeconds: tc.IntervalSeconds,
+			Prompt:          tc.Prompt,
+			Channel:         tc.Channel,
+			ChatID:          tc.ChatID,
+		})
+	}
+	return out
+}
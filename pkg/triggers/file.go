@@ -0,0 +1,228 @@
+package triggers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// fileDebounce coalesces a burst of changes into one agent turn.
+	fileDebounce = 2 * time.Second
+	// filePollInterval is used by the polling watcher on platforms without inotify.
+	filePollInterval = 2 * time.Second
+)
+
+// File change operations.
+const (
+	OpCreate = "create"
+	OpWrite  = "write"
+	OpRemove = "remove"
+	OpRename = "rename"
+)
+
+type fileChange struct {
+	Path string
+	Op   string
+}
+
+// fileWatcher reports changes to a file, or to the direct entries of a directory.
+type fileWatcher interface {
+	Events() <-chan fileChange
+	Close() error
+}
+
+// runFileWatcher fires the trigger after a quiet period following changes.
+// Changes made while the trigger's own turn runs are ignored, so an agent
+// that writes into the watched path does not trigger itself.
+func (s *Service) runFileWatcher(ctx context.Context, t Trigger) {
+	path := s.resolvePath(t.Path)
+
+	var w fileWatcher
+	for {
+		var err error
+		w, err = newFileWatcher(path)
+		if err == nil {
+			break
+		}
+		s.updateState(t.ID, func(st *TriggerState) { st.LastError = err.Error() })
+		logger.WarnCF("triggers", "Cannot watch path, retrying", map[string]any{"trigger": t.Name, "error": err.Error()})
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+	defer w.Close()
+
+	pending := make(map[string]string)
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+	var quietUntil time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ch, ok := <-w.Events():
+			if !ok {
+				return
+			}
+			if time.Now().Before(quietUntil) {
+				continue
+			}
+			pending[ch.Path] = mergeOp(pending[ch.Path], ch.Op)
+			timer.Reset(fileDebounce)
+		case <-timer.C:
+			if len(pending) == 0 {
+				continue
+			}
+			ev := fileEvent(path, pending)
+			pending = make(map[string]string)
+			s.fire(ctx, t, ev)
+			quietUntil = time.Now().Add(fileDebounce)
+		}
+	}
+}
+
+func mergeOp(prev, next string) string {
+	// A file created and then written within one burst is still new.
+	if prev == OpCreate && next == OpWrite {
+		return prev
+	}
+	return next
+}
+
+func fileEvent(root string, pending map[string]string) Event {
+	paths := make([]string, 0, len(pending))
+	for p := range pending {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	described := make([]string, 0, len(paths))
+	for _, p := range paths {
+		described = append(described, fmt.Sprintf("%s (%s)", p, pending[p]))
+	}
+
+	summary := fmt.Sprintf("%d change(s) in %s", len(paths), root)
+	if len(paths) == 1 {
+		summary = fmt.Sprintf("%s was %s", paths[0], opVerb(pending[paths[0]]))
+	}
+	return Event{Summary: summary, Path: root, Paths: described}
+}
+
+func opVerb(op string) string {
+	switch op {
+	case OpCreate:
+		return "created"
+	case OpRemove:
+		return "removed"
+	case OpRename:
+		return "renamed"
+	default:
+		return "modified"
+	}
+}
+
+// pollWatcher compares directory snapshots on an interval. It backs file
+// triggers on platforms without inotify.
+type pollWatcher struct {
+	path   string
+	events chan fileChange
+	done   chan struct{}
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func newPollWatcher(path string, interval time.Duration) (*pollWatcher, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	w := &pollWatcher{
+		path:   path,
+		events: make(chan fileChange, 64),
+		done:   make(chan struct{}),
+	}
+	go w.loop(interval)
+	return w, nil
+}
+
+func (w *pollWatcher) Events() <-chan fileChange { return w.events }
+
+func (w *pollWatcher) Close() error {
+	close(w.done)
+	return nil
+}
+
+func (w *pollWatcher) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := snapshot(w.path)
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		cur := snapshot(w.path)
+		for p, stamp := range cur {
+			old, existed := prev[p]
+			switch {
+			case !existed:
+				w.emit(fileChange{Path: p, Op: OpCreate})
+			case old != stamp:
+				w.emit(fileChange{Path: p, Op: OpWrite})
+			}
+		}
+		for p := range prev {
+			if _, ok := cur[p]; !ok {
+				w.emit(fileChange{Path: p, Op: OpRemove})
+			}
+		}
+		prev = cur
+	}
+}
+
+func (w *pollWatcher) emit(ch fileChange) {
+	select {
+	case w.events <- ch:
+	default:
+	}
+}
+
+func snapshot(path string) map[string]fileStamp {
+	out := make(map[string]fileStamp)
+	info, err := os.Stat(path)
+	if err != nil {
+		return out
+	}
+	if !info.IsDir() {
+		out[path] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		return out
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return out
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if fi, err := e.Info(); err == nil {
+			out[filepath.Join(path, e.Name())] = fileStamp{size: fi.Size(), modTime: fi.ModTime()}
+		}
+	}
+	return out
+}
@@ -0,0 +1,372 @@
+package triggers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	maxPollBodyBytes = 2 << 20
+	maxSeenItems     = 500
+	maxItemsPerFire  = 10
+	maxValueExcerpt  = 2000
+	pollTimeout      = 30 * time.Second
+)
+
+var errPrivateHost = errors.New("destination is a private or local address (set tools.triggers.allow_private_hosts to allow)")
+
+// runPoller checks a feed or http trigger immediately and then on its interval.
+func (s *Service) runPoller(ctx context.Context, t Trigger) {
+	ticker := time.NewTicker(t.interval(s.minInterval()))
+	defer ticker.Stop()
+
+	for {
+		s.poll(ctx, t)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) poll(ctx context.Context, t Trigger) {
+	var ev *Event
+	var err error
+	switch t.Kind {
+	case KindFeed:
+		ev, err = s.checkFeed(ctx, t)
+	case KindHTTP:
+		ev, err = s.checkHTTP(ctx, t)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		logger.WarnCF("triggers", "Trigger check failed", map[string]any{"trigger": t.Name, "error": err.Error()})
+		s.updateState(t.ID, func(st *TriggerState) {
+			st.LastCheckAtMS = time.Now().UnixMilli()
+			st.LastError = err.Error()
+		})
+		return
+	}
+	if ev != nil {
+		s.fire(ctx, t, *ev)
+	}
+}
+
+// checkFeed records the entries of a feed and returns an event for entries
+// not seen before. The first check only records a baseline.
+func (s *Service) checkFeed(ctx context.Context, t Trigger) (*Event, error) {
+	body, err := s.fetch(ctx, t.URL, "application/rss+xml, application/atom+xml, application/xml, text/xml")
+	if err != nil {
+		return nil, err
+	}
+	items, err := parseFeed(body)
+	if err != nil {
+		return nil, err
+	}
+
+	prev := s.state(t.ID)
+	baseline := prev.LastCheckAtMS == 0 && len(prev.Seen) == 0
+	seen := make(map[string]bool, len(prev.Seen))
+	for _, id := range prev.Seen {
+		seen[id] = true
+	}
+
+	var fresh []FeedItem
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+		if !seen[item.ID] {
+			fresh = append(fresh, item)
+		}
+	}
+
+	s.updateState(t.ID, func(st *TriggerState) {
+		st.LastCheckAtMS = time.Now().UnixMilli()
+		st.LastError = ""
+		// Keep the current feed first so entries that scroll off age out last.
+		merged := append([]string{}, ids...)
+		for _, id := range st.Seen {
+			if !containsString(ids, id) {
+				merged = append(merged, id)
+			}
+		}
+		if len(merged) > maxSeenItems {
+			merged = merged[:maxSeenItems]
+		}
+		st.Seen = merged
+	})
+
+	if baseline || len(fresh) == 0 {
+		return nil, nil
+	}
+
+	summary := fmt.Sprintf("%d new entries in %s", len(fresh), t.URL)
+	if len(fresh) == 1 {
+		summary = fmt.Sprintf("new entry in %s: %s", t.URL, fresh[0].Title)
+	}
+	if len(fresh) > maxItemsPerFire {
+		fresh = fresh[:maxItemsPerFire]
+	}
+	return &Event{Summary: summary, URL: t.URL, Items: fresh}, nil
+}
+
+// checkHTTP fetches a JSON endpoint and returns an event when the watched
+// value changed. The first check only records a baseline.
+func (s *Service) checkHTTP(ctx context.Context, t Trigger) (*Event, error) {
+	body, err := s.fetch(ctx, t.URL, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	value, err := extractJSON(body, t.JSONPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(value))
+	fingerprint := hex.EncodeToString(sum[:])
+	excerpt := excerpt(value, maxValueExcerpt)
+
+	prev := s.state(t.ID)
+	s.updateState(t.ID, func(st *TriggerState) {
+		st.LastCheckAtMS = time.Now().UnixMilli()
+		st.LastError = ""
+		st.Fingerprint = fingerprint
+		st.LastValue = excerpt
+	})
+
+	if prev.Fingerprint == "" || prev.Fingerprint == fingerprint {
+		return nil, nil
+	}
+
+	target := t.URL
+	if t.JSONPath != "" {
+		target = t.JSONPath + " in " + t.URL
+	}
+	return &Event{
+		Summary:  "value of " + target + " changed",
+		URL:      t.URL,
+		Value:    excerpt,
+		Previous: prev.LastValue,
+	}, nil
+}
+
+func (s *Service) fetch(ctx context.Context, url, accept string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, pollTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", "picoclaw-triggers")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPollBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxPollBodyBytes {
+		return nil, fmt.Errorf("GET %s: response larger than %d bytes", url, maxPollBodyBytes)
+	}
+	return body, nil
+}
+
+type feedDoc struct {
+	XMLName xml.Name
+	// RSS 2.0 nests items in <channel>; RSS 1.0 (RDF) keeps them at the root.
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []rssItem   `xml:"item"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate"`
+}
+
+type atomEntry struct {
+	ID      string `xml:"id"`
+	Title   string `xml:"title"`
+	Summary string `xml:"summary"`
+	Content string `xml:"content"`
+	Updated string `xml:"updated"`
+	Links   []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+}
+
+// parseFeed reads RSS 1.0/2.0 and Atom documents.
+func parseFeed(body []byte) ([]FeedItem, error) {
+	var doc feedDoc
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("not a valid feed: %w", err)
+	}
+
+	var items []FeedItem
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		for _, it := range append(doc.Channel.Items, doc.Items...) {
+			id := firstNonEmpty(it.GUID, it.Link, it.Title)
+			items = append(items, FeedItem{
+				ID:        id,
+				Title:     strings.TrimSpace(it.Title),
+				Link:      strings.TrimSpace(it.Link),
+				Summary:   excerpt(it.Description, 500),
+				Published: it.PubDate,
+			})
+		}
+	case "feed":
+		for _, e := range doc.Entries {
+			link := ""
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			items = append(items, FeedItem{
+				ID:        firstNonEmpty(e.ID, link, e.Title),
+				Title:     strings.TrimSpace(e.Title),
+				Link:      link,
+				Summary:   excerpt(firstNonEmpty(e.Summary, e.Content), 500),
+				Published: e.Updated,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported feed format <%s>", doc.XMLName.Local)
+	}
+	return items, nil
+}
+
+// extractJSON returns the value at a dot-separated path. Objects and arrays
+// are re-encoded so key order does not cause spurious changes.
+func extractJSON(body []byte, path string) (string, error) {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		if path == "" {
+			return strings.TrimSpace(string(body)), nil
+		}
+		return "", fmt.Errorf("response is not JSON: %w", err)
+	}
+
+	if path != "" {
+		for _, seg := range strings.Split(path, ".") {
+			switch cur := v.(type) {
+			case map[string]any:
+				next, ok := cur[seg]
+				if !ok {
+					return "", fmt.Errorf("json path %q: key %q not found", path, seg)
+				}
+				v = next
+			case []any:
+				idx, err := strconv.Atoi(seg)
+				if err != nil || idx < 0 || idx >= len(cur) {
+					return "", fmt.Errorf("json path %q: invalid index %q", path, seg)
+				}
+				v = cur[idx]
+			default:
+				return "", fmt.Errorf("json path %q: cannot descend into %q", path, seg)
+			}
+		}
+	}
+
+	if str, ok := v.(string); ok {
+		return str, nil
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// newHTTPClient returns the poller client. Unless private hosts are allowed,
+// connections to loopback, private and link-local addresses are refused at
+// dial time, after DNS resolution.
+func newHTTPClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip != nil && isRestrictedIP(ip) {
+				return errPrivateHost
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout: pollTimeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
+
+func isRestrictedIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func excerpt(s string, max int) string {
+	s = strings.TrimSpace(s)
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "…"
+}
@@ -0,0 +1,491 @@
+package triggers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// fireTimeout bounds a single agent turn started by a trigger.
+	fireTimeout = 10 * time.Minute
+	// storePollInterval bounds how long it takes the gateway to notice
+	// triggers added or changed by another process (CLI, web launcher).
+	storePollInterval = 5 * time.Second
+	// retryInterval is how long a runner waits after a setup failure, e.g. a
+	// watched path that does not exist yet.
+	retryInterval = time.Minute
+)
+
+// Handler runs the agent turn for a fired trigger.
+type Handler func(ctx context.Context, t *Trigger, prompt string) error
+
+// Options configures a Service.
+type Options struct {
+	// Workspace resolves relative file trigger paths.
+	Workspace string
+	// AllowPrivateHosts lets feed and http triggers reach loopback and
+	// private network addresses.
+	AllowPrivateHosts bool
+	// MinIntervalSeconds overrides MinIntervalSeconds when > 0.
+	MinIntervalSeconds int
+	// HTTPClient replaces the default client used by pollers.
+	HTTPClient *http.Client
+}
+
+type store struct {
+	Version  int                      `json:"version"`
+	Triggers []Trigger                `json:"triggers"`
+	State    map[string]*TriggerState `json:"state,omitempty"`
+}
+
+// Service owns the trigger store and runs one goroutine per enabled trigger.
+type Service struct {
+	storePath    string
+	storeModTime time.Time
+	opts         Options
+	client       *http.Client
+
+	mu         sync.Mutex
+	store      *store
+	configured []Trigger
+	handler    Handler
+	running    bool
+	ctx        context.Context
+	cancel     context.CancelFunc
+	runners    map[string]*runner
+}
+
+type runner struct {
+	def    Trigger
+	cancel context.CancelFunc
+}
+
+// NewService creates a service backed by the store at storePath.
+func NewService(storePath string, opts Options) *Service {
+	client := opts.HTTPClient
+	if client == nil {
+		client = newHTTPClient(opts.AllowPrivateHosts)
+	}
+	s := &Service{
+		storePath: storePath,
+		opts:      opts,
+		client:    client,
+		runners:   make(map[string]*runner),
+	}
+	if err := s.loadLocked(); err != nil {
+		logger.WarnCF("triggers", "Failed to load trigger store", map[string]any{"error": err.Error()})
+	}
+	return s
+}
+
+// ConfigID returns the ID of a trigger defined in config.json.
+func ConfigID(name string) string {
+	return "config:" + name
+}
+
+// SetConfigured replaces the triggers defined in config.json.
+func (s *Service) SetConfigured(defs []Trigger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.configured = make([]Trigger, 0, len(defs))
+	for _, t := range defs {
+		t.ID = ConfigID(t.Name)
+		t.Source = SourceConfig
+		if err := Validate(&t); err != nil {
+			logger.WarnCF("triggers", "Ignoring invalid configured trigger", map[string]any{"error": err.Error()})
+			continue
+		}
+		s.configured = append(s.configured, t)
+	}
+	if s.running {
+		s.syncRunnersLocked()
+	}
+}
+
+// SetHandler sets the function that runs fired triggers.
+func (s *Service) SetHandler(h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = h
+}
+
+// Start launches the runners of all enabled triggers.
+func (s *Service) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+	if err := s.loadLocked(); err != nil {
+		return fmt.Errorf("failed to load trigger store: %w", err)
+	}
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.running = true
+	s.syncRunnersLocked()
+	go s.watchStore(s.ctx)
+
+	return nil
+}
+
+// Stop cancels all runners.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return
+	}
+	s.running = false
+	s.cancel()
+	for id, r := range s.runners {
+		r.cancel()
+		delete(s.runners, id)
+	}
+}
+
+// List returns configured triggers followed by stored ones.
+func (s *Service) List() []Trigger {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.allLocked()
+}
+
+// Get returns a trigger and its state.
+func (s *Service) Get(id string) (*Trigger, TriggerState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.findLocked(id)
+	if t == nil {
+		return nil, TriggerState{}, false
+	}
+	tCopy := *t
+	var st TriggerState
+	if cur := s.store.State[id]; cur != nil {
+		st = *cur
+	}
+	return &tCopy, st, true
+}
+
+// Add validates and stores a new trigger.
+func (s *Service) Add(t Trigger) (*Trigger, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reloadIfChangedLocked()
+
+	t.ID = generateID()
+	t.Source = ""
+	t.Enabled = true
+	t.CreatedAtMS = time.Now().UnixMilli()
+	if err := Validate(&t); err != nil {
+		return nil, err
+	}
+	for _, existing := range s.allLocked() {
+		if strings.EqualFold(existing.Name, t.Name) {
+			return nil, fmt.Errorf("a trigger named %q already exists", t.Name)
+		}
+	}
+
+	s.store.Triggers = append(s.store.Triggers, t)
+	if err := s.saveLocked(); err != nil {
+		return nil, err
+	}
+	if s.running {
+		s.syncRunnersLocked()
+	}
+	return &t, nil
+}
+
+// Remove deletes a stored trigger. Configured triggers cannot be removed.
+func (s *Service) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reloadIfChangedLocked()
+
+	if t := s.findLocked(id); t != nil && t.Source == SourceConfig {
+		return fmt.Errorf("trigger %q is defined in config.json", t.Name)
+	}
+
+	kept := s.store.Triggers[:0]
+	found := false
+	for _, t := range s.store.Triggers {
+		if t.ID == id {
+			found = true
+			continue
+		}
+		kept = append(kept, t)
+	}
+	if !found {
+		return fmt.Errorf("trigger %s not found", id)
+	}
+	s.store.Triggers = kept
+	delete(s.store.State, id)
+
+	if err := s.saveLocked(); err != nil {
+		return err
+	}
+	if s.running {
+		s.syncRunnersLocked()
+	}
+	return nil
+}
+
+// SetEnabled enables or disables a stored trigger.
+func (s *Service) SetEnabled(id string, enabled bool) (*Trigger, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reloadIfChangedLocked()
+
+	for i := range s.store.Triggers {
+		t := &s.store.Triggers[i]
+		if t.ID != id {
+			continue
+		}
+		t.Enabled = enabled
+		if err := s.saveLocked(); err != nil {
+			return nil, err
+		}
+		if s.running {
+			s.syncRunnersLocked()
+		}
+		tCopy := *t
+		return &tCopy, nil
+	}
+	if t := s.findLocked(id); t != nil {
+		return nil, fmt.Errorf("trigger %q is defined in config.json", t.Name)
+	}
+	return nil, fmt.Errorf("trigger %s not found", id)
+}
+
+func (s *Service) allLocked() []Trigger {
+	all := make([]Trigger, 0, len(s.configured)+len(s.store.Triggers))
+	all = append(all, s.configured...)
+	all = append(all, s.store.Triggers...)
+	return all
+}
+
+func (s *Service) findLocked(id string) *Trigger {
+	for i := range s.configured {
+		if s.configured[i].ID == id {
+			return &s.configured[i]
+		}
+	}
+	for i := range s.store.Triggers {
+		if s.store.Triggers[i].ID == id {
+			return &s.store.Triggers[i]
+		}
+	}
+	return nil
+}
+
+// syncRunnersLocked starts, stops and restarts runners so that exactly the
+// enabled triggers run with their current definition.
+func (s *Service) syncRunnersLocked() {
+	wanted := make(map[string]Trigger)
+	for _, t := range s.allLocked() {
+		if t.Enabled {
+			wanted[t.ID] = t
+		}
+	}
+
+	for id, r := range s.runners {
+		if def, ok := wanted[id]; !ok || def != r.def {
+			r.cancel()
+			delete(s.runners, id)
+		}
+	}
+	for id, def := range wanted {
+		if _, ok := s.runners[id]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(s.ctx)
+		s.runners[id] = &runner{def: def, cancel: cancel}
+		go s.run(ctx, def)
+	}
+}
+
+func (s *Service) run(ctx context.Context, t Trigger) {
+	logger.InfoCF("triggers", "Trigger started", map[string]any{"trigger": t.Name, "kind": t.Kind})
+	switch t.Kind {
+	case KindFile:
+		s.runFileWatcher(ctx, t)
+	case KindFeed, KindHTTP:
+		s.runPoller(ctx, t)
+	}
+}
+
+// watchStore reloads the store when another process changes it.
+func (s *Service) watchStore(ctx context.Context) {
+	ticker := time.NewTicker(storePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			if s.reloadIfChangedLocked() && s.running {
+				s.syncRunnersLocked()
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// fire renders the prompt for ev and hands it to the handler.
+func (s *Service) fire(ctx context.Context, t Trigger, ev Event) {
+	ev.Trigger = t.Name
+	ev.Kind = t.Kind
+	ev.Time = time.Now().Format("2006-01-02 15:04:05")
+
+	s.mu.Lock()
+	handler := s.handler
+	s.mu.Unlock()
+
+	prompt, err := RenderPrompt(&t, ev)
+	if err == nil && handler != nil {
+		fireCtx, cancel := context.WithTimeout(ctx, fireTimeout)
+		err = handler(fireCtx, &t, prompt)
+		cancel()
+	}
+
+	logger.InfoCF("triggers", "Trigger fired", map[string]any{"trigger": t.Name, "summary": ev.Summary})
+	s.updateState(t.ID, func(st *TriggerState) {
+		st.LastFiredAtMS = time.Now().UnixMilli()
+		st.FireCount++
+		st.LastError = ""
+		if err != nil {
+			st.LastError = err.Error()
+		}
+	})
+	if err != nil {
+		logger.WarnCF("triggers", "Trigger turn failed", map[string]any{"trigger": t.Name, "error": err.Error()})
+	}
+}
+
+// state returns a copy of the state of a trigger.
+func (s *Service) state(id string) TriggerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st := s.store.State[id]; st != nil {
+		return *st
+	}
+	return TriggerState{}
+}
+
+// updateState applies fn to the state of a trigger and persists it.
+func (s *Service) updateState(id string, fn func(st *TriggerState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reloadIfChangedLocked()
+	if s.store.State == nil {
+		s.store.State = make(map[string]*TriggerState)
+	}
+	st := s.store.State[id]
+	if st == nil {
+		st = &TriggerState{}
+		s.store.State[id] = st
+	}
+	fn(st)
+	if err := s.saveLocked(); err != nil {
+		logger.WarnCF("triggers", "Failed to save trigger store", map[string]any{"error": err.Error()})
+	}
+}
+
+func (s *Service) resolvePath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) && s.opts.Workspace != "" {
+		path = filepath.Join(s.opts.Workspace, path)
+	}
+	return filepath.Clean(path)
+}
+
+func (s *Service) minInterval() int {
+	if s.opts.MinIntervalSeconds > 0 {
+		return s.opts.MinIntervalSeconds
+	}
+	return MinIntervalSeconds
+}
+
+func (s *Service) loadLocked() error {
+	s.store = &store{Version: 1}
+
+	data, err := os.ReadFile(s.storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info, err := os.Stat(s.storePath); err == nil {
+		s.storeModTime = info.ModTime()
+	}
+	return json.Unmarshal(data, s.store)
+}
+
+// reloadIfChangedLocked re-reads the store if another process rewrote it and
+// reports whether it did.
+func (s *Service) reloadIfChangedLocked() bool {
+	info, err := os.Stat(s.storePath)
+	if err != nil || info.ModTime().Equal(s.storeModTime) {
+		return false
+	}
+	data, err := os.ReadFile(s.storePath)
+	if err != nil {
+		return false
+	}
+	st := &store{}
+	if err := json.Unmarshal(data, st); err != nil {
+		logger.WarnCF("triggers", "Ignoring unreadable trigger store", map[string]any{"error": err.Error()})
+		s.storeModTime = info.ModTime()
+		return false
+	}
+	s.store = st
+	s.storeModTime = info.ModTime()
+	return true
+}
+
+func (s *Service) saveLocked() error {
+	data, err := json.MarshalIndent(s.store, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(s.storePath, data, 0o600); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.storePath); err == nil {
+		s.storeModTime = info.ModTime()
+	}
+	return nil
+}
+
+func generateID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
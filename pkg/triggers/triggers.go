@@ -0,0 +1,188 @@
+// Package triggers fires agent turns in response to outside events: file
+// changes in watched paths, new entries in RSS/Atom feeds and changed values
+// in polled JSON endpoints.
+package triggers
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Trigger kinds.
+const (
+	KindFile = "file"
+	KindFeed = "feed"
+	KindHTTP = "http"
+)
+
+// SourceConfig marks triggers defined in config.json. They are read-only for
+// the tool and the CLI and are not persisted in the trigger store.
+const SourceConfig = "config"
+
+const (
+	// DefaultIntervalSeconds is the poll interval of feed and http triggers
+	// that do not set one.
+	DefaultIntervalSeconds = 300
+	// MinIntervalSeconds is the shortest poll interval accepted by default.
+	MinIntervalSeconds = 60
+)
+
+// Trigger describes one event source and the agent turn it fires.
+type Trigger struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Enabled bool   `json:"enabled"`
+	Source  string `json:"source,omitempty"`
+
+	// Path is the file or directory watched by file triggers. Relative paths
+	// are resolved against the workspace.
+	Path string `json:"path,omitempty"`
+	// URL is polled by feed and http triggers.
+	URL string `json:"url,omitempty"`
+	// JSONPath selects the watched value of an http trigger, e.g.
+	// "data.items.0.status". Empty watches the whole body.
+	JSONPath        string `json:"jsonPath,omitempty"`
+	IntervalSeconds int    `json:"intervalSeconds,omitempty"`
+
+	// Prompt is a text/template rendered with the Event that fired.
+	Prompt  string `json:"prompt,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	ChatID  string `json:"chatId,omitempty"`
+
+	CreatedAtMS int64 `json:"createdAtMs,omitempty"`
+}
+
+// TriggerState is the change-detection and bookkeeping state of a trigger.
+type TriggerState struct {
+	LastCheckAtMS int64    `json:"lastCheckAtMs,omitempty"`
+	LastFiredAtMS int64    `json:"lastFiredAtMs,omitempty"`
+	LastError     string   `json:"lastError,omitempty"`
+	FireCount     int      `json:"fireCount,omitempty"`
+	Fingerprint   string   `json:"fingerprint,omitempty"` // http: hash of the watched value
+	LastValue     string   `json:"lastValue,omitempty"`   // http: excerpt of the watched value
+	Seen          []string `json:"seen,omitempty"`        // feed: IDs of known entries
+}
+
+// FeedItem is a new entry found in a feed.
+type FeedItem struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string
+	Published string
+}
+
+// Event is what a trigger observed. It is the data of the prompt template.
+type Event struct {
+	Trigger string
+	Kind    string
+	Time    string
+	Summary string
+
+	// file
+	Path  string
+	Paths []string
+
+	// feed
+	Items []FeedItem
+
+	// http
+	URL      string
+	Value    string
+	Previous string
+}
+
+var defaultPrompts = map[string]string{
+	KindFile: `Trigger "{{.Trigger}}" fired: {{.Summary}}
+Changed paths:
+{{range .Paths}}- {{.}}
+{{end}}`,
+	KindFeed: `Trigger "{{.Trigger}}" fired: {{.Summary}}
+{{range .Items}}- {{.Title}}{{if .Link}} ({{.Link}}){{end}}
+{{end}}`,
+	KindHTTP: `Trigger "{{.Trigger}}" fired: {{.Summary}}
+Previous value: {{.Previous}}
+Current value: {{.Value}}`,
+}
+
+// Validate checks a trigger definition.
+func Validate(t *Trigger) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("trigger name is required")
+	}
+	switch t.Kind {
+	case KindFile:
+		if strings.TrimSpace(t.Path) == "" {
+			return fmt.Errorf("file trigger %q requires a path", t.Name)
+		}
+	case KindFeed, KindHTTP:
+		u, err := url.Parse(t.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s trigger %q requires an http(s) url", t.Kind, t.Name)
+		}
+		if t.IntervalSeconds < 0 {
+			return fmt.Errorf("trigger %q has a negative interval", t.Name)
+		}
+	default:
+		return fmt.Errorf("unknown trigger kind %q (want file, feed or http)", t.Kind)
+	}
+	if _, err := parsePrompt(t); err != nil {
+		return fmt.Errorf("trigger %q has an invalid prompt template: %w", t.Name, err)
+	}
+	return nil
+}
+
+func parsePrompt(t *Trigger) (*template.Template, error) {
+	text := t.Prompt
+	if strings.TrimSpace(text) == "" {
+		text = defaultPrompts[t.Kind]
+	}
+	return template.New(t.Name).Option("missingkey=zero").Parse(text)
+}
+
+// RenderPrompt renders the trigger's prompt template for an event.
+func RenderPrompt(t *Trigger, ev Event) (string, error) {
+	tmpl, err := parsePrompt(t)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ev); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func (t *Trigger) interval(minSeconds int) time.Duration {
+	seconds := t.IntervalSeconds
+	if seconds <= 0 {
+		seconds = DefaultIntervalSeconds
+	}
+	if seconds < minSeconds {
+		seconds = minSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Describe returns a one-line summary of what the trigger watches.
+func (t *Trigger) Describe() string {
+	switch t.Kind {
+	case KindFile:
+		return "watch " + t.Path
+	case KindFeed:
+		return fmt.Sprintf("feed %s every %s", t.URL, t.interval(0))
+	case KindHTTP:
+		target := t.URL
+		if t.JSONPath != "" {
+			target += " @ " + t.JSONPath
+		}
+		return fmt.Sprintf("poll %s every %s", target, t.interval(0))
+	default:
+		return t.Kind
+	}
+}
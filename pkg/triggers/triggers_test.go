@@ -0,0 +1,255 @@
+package triggers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	dir := t.TempDir()
+	return NewService(filepath.Join(dir, "triggers", "triggers.json"), Options{
+		Workspace:         dir,
+		AllowPrivateHosts: true,
+	})
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger Trigger
+		wantErr bool
+	}{
+		{"file", Trigger{Name: "a", Kind: KindFile, Path: "inbox"}, false},
+		{"feed", Trigger{Name: "a", Kind: KindFeed, URL: "https://example.com/feed"}, false},
+		{"missing name", Trigger{Kind: KindFile, Path: "inbox"}, true},
+		{"file without path", Trigger{Name: "a", Kind: KindFile}, true},
+		{"bad scheme", Trigger{Name: "a", Kind: KindHTTP, URL: "file:///etc/passwd"}, true},
+		{"negative interval", Trigger{Name: "a", Kind: KindHTTP, URL: "https://x.io", IntervalSeconds: -1}, true},
+		{"unknown kind", Trigger{Name: "a", Kind: "cron"}, true},
+		{"bad template", Trigger{Name: "a", Kind: KindFile, Path: "x", Prompt: "{{.Summary"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.trigger)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRenderPrompt(t *testing.T) {
+	tr := &Trigger{Name: "blog", Kind: KindFeed}
+	out, err := RenderPrompt(tr, Event{
+		Trigger: "blog",
+		Summary: "1 new entry",
+		Items:   []FeedItem{{Title: "Hello", Link: "https://example.com/hello"}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, `Trigger "blog" fired: 1 new entry`)
+	assert.Contains(t, out, "- Hello (https://example.com/hello)")
+
+	tr.Prompt = "Summarize {{range .Items}}{{.Link}}{{end}}"
+	out, err = RenderPrompt(tr, Event{Items: []FeedItem{{Link: "https://x.io/1"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize https://x.io/1", out)
+}
+
+func TestParseFeed(t *testing.T) {
+	rss := `<?xml version="1.0"?><rss version="2.0"><channel>
+<item><title>One</title><link>https://x.io/1</link><guid>g1</guid></item>
+<item><title>Two</title><link>https://x.io/2</link></item>
+</channel></rss>`
+	items, err := parseFeed([]byte(rss))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "g1", items[0].ID)
+	assert.Equal(t, "https://x.io/2", items[1].ID)
+
+	atom := `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom">
+<entry><id>urn:1</id><title>A</title><link rel="self" href="https://x.io/self"/><link href="https://x.io/a"/></entry>
+</feed>`
+	items, err = parseFeed([]byte(atom))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "urn:1", items[0].ID)
+	assert.Equal(t, "https://x.io/a", items[0].Link)
+
+	_, err = parseFeed([]byte(`<html></html>`))
+	assert.Error(t, err)
+}
+
+func TestExtractJSON(t *testing.T) {
+	body := []byte(`{"data":[{"status":"ok","n":2}],"meta":{"b":1,"a":2}}`)
+
+	v, err := extractJSON(body, "data.0.status")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", v)
+
+	v, err = extractJSON(body, "meta")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":2,"b":1}`, v)
+
+	_, err = extractJSON(body, "data.5")
+	assert.Error(t, err)
+	_, err = extractJSON(body, "missing")
+	assert.Error(t, err)
+}
+
+func TestCheckFeed_BaselineThenNewEntries(t *testing.T) {
+	var mu sync.Mutex
+	feed := `<rss><channel><item><guid>1</guid><title>One</title></item></channel></rss>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write([]byte(feed))
+	}))
+	defer srv.Close()
+
+	s := newTestService(t)
+	tr := Trigger{ID: "t1", Name: "feed", Kind: KindFeed, URL: srv.URL}
+
+	ev, err := s.checkFeed(context.Background(), tr)
+	require.NoError(t, err)
+	assert.Nil(t, ev, "first check only records a baseline")
+
+	mu.Lock()
+	feed = `<rss><channel><item><guid>2</guid><title>Two</title></item><item><guid>1</guid><title>One</title></item></channel></rss>`
+	mu.Unlock()
+
+	ev, err = s.checkFeed(context.Background(), tr)
+	require.NoError(t, err)
+	require.NotNil(t, ev)
+	require.Len(t, ev.Items, 1)
+	assert.Equal(t, "Two", ev.Items[0].Title)
+
+	ev, err = s.checkFeed(context.Background(), tr)
+	require.NoError(t, err)
+	assert.Nil(t, ev)
+}
+
+func TestCheckHTTP_FiresOnChange(t *testing.T) {
+	var mu sync.Mutex
+	body := `{"build":{"state":"running"},"ts":1}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	s := newTestService(t)
+	tr := Trigger{ID: "t1", Name: "ci", Kind: KindHTTP, URL: srv.URL, JSONPath: "build.state"}
+
+	ev, err := s.checkHTTP(context.Background(), tr)
+	require.NoError(t, err)
+	assert.Nil(t, ev)
+
+	// Changes outside the watched path are ignored.
+	mu.Lock()
+	body = `{"build":{"state":"running"},"ts":2}`
+	mu.Unlock()
+	ev, err = s.checkHTTP(context.Background(), tr)
+	require.NoError(t, err)
+	assert.Nil(t, ev)
+
+	mu.Lock()
+	body = `{"build":{"state":"passed"},"ts":3}`
+	mu.Unlock()
+	ev, err = s.checkHTTP(context.Background(), tr)
+	require.NoError(t, err)
+	require.NotNil(t, ev)
+	assert.Equal(t, "running", ev.Previous)
+	assert.Equal(t, "passed", ev.Value)
+}
+
+func TestHTTPClient_BlocksPrivateHosts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	s := NewService(filepath.Join(t.TempDir(), "triggers.json"), Options{})
+	_, err := s.fetch(context.Background(), srv.URL, "application/json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "private")
+}
+
+func TestService_AddRemoveAndConfigTriggers(t *testing.T) {
+	s := newTestService(t)
+	s.SetConfigured([]Trigger{{Name: "inbox", Kind: KindFile, Path: "inbox", Enabled: true}})
+
+	added, err := s.Add(Trigger{Name: "blog", Kind: KindFeed, URL: "https://example.com/feed"})
+	require.NoError(t, err)
+	assert.True(t, added.Enabled)
+	assert.NotEmpty(t, added.ID)
+
+	_, err = s.Add(Trigger{Name: "inbox", Kind: KindFile, Path: "other"})
+	assert.Error(t, err, "names must be unique across config and store")
+
+	assert.Len(t, s.List(), 2)
+	assert.Error(t, s.Remove(ConfigID("inbox")))
+	_, err = s.SetEnabled(ConfigID("inbox"), false)
+	assert.Error(t, err)
+
+	// A second service sees the persisted trigger but not the config one.
+	other := NewService(s.storePath, Options{Workspace: s.opts.Workspace})
+	require.Len(t, other.List(), 1)
+	assert.Equal(t, "blog", other.List()[0].Name)
+
+	require.NoError(t, s.Remove(added.ID))
+	assert.Len(t, s.List(), 1)
+}
+
+func TestFileTrigger_FiresOnceAfterDebounce(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for the debounce period")
+	}
+
+	s := newTestService(t)
+	inbox := filepath.Join(s.opts.Workspace, "inbox")
+	require.NoError(t, os.MkdirAll(inbox, 0o755))
+
+	prompts := make(chan string, 4)
+	s.SetHandler(func(_ context.Context, _ *Trigger, prompt string) error {
+		prompts <- prompt
+		return nil
+	})
+	_, err := s.Add(Trigger{Name: "inbox", Kind: KindFile, Path: "inbox"})
+	require.NoError(t, err)
+	require.NoError(t, s.Start())
+	defer s.Stop()
+
+	// Give the watcher time to take its initial snapshot.
+	time.Sleep(filePollInterval + 500*time.Millisecond)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(inbox, name), []byte("x"), 0o644))
+	}
+
+	select {
+	case prompt := <-prompts:
+		assert.Contains(t, prompt, "a.txt")
+		assert.Contains(t, prompt, "b.txt")
+	case <-time.After(15 * time.Second):
+		t.Fatal("trigger did not fire")
+	}
+
+	select {
+	case prompt := <-prompts:
+		t.Fatalf("unexpected second fire: %s", strings.TrimSpace(prompt))
+	case <-time.After(fileDebounce + time.Second):
+	}
+}
@@ -0,0 +1,132 @@
+//go:build linux
+
+package triggers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_MODIFY | unix.IN_DELETE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO
+
+// inotifyWatcher watches a directory, or the parent directory of a file so
+// that editors replacing the file through a rename are still seen.
+type inotifyWatcher struct {
+	fd     int
+	dir    string
+	only   string // base name to report when watching a single file
+	events chan fileChange
+	done   chan struct{}
+}
+
+func newFileWatcher(path string) (fileWatcher, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, only := path, ""
+	if !info.IsDir() {
+		dir, only = filepath.Dir(path), filepath.Base(path)
+	}
+
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		// inotify can be unavailable (exhausted instances, some containers).
+		return newPollWatcher(path, filePollInterval)
+	}
+	if _, err := unix.InotifyAddWatch(fd, dir, inotifyMask); err != nil {
+		unix.Close(fd)
+		return newPollWatcher(path, filePollInterval)
+	}
+
+	w := &inotifyWatcher{
+		fd:     fd,
+		dir:    dir,
+		only:   only,
+		events: make(chan fileChange, 64),
+		done:   make(chan struct{}),
+	}
+	go w.loop()
+	return w, nil
+}
+
+func (w *inotifyWatcher) Events() <-chan fileChange { return w.events }
+
+func (w *inotifyWatcher) Close() error {
+	close(w.done)
+	return nil
+}
+
+func (w *inotifyWatcher) loop() {
+	defer unix.Close(w.fd)
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+	for {
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+
+		// Poll with a timeout so Close is noticed without a pending read.
+		n, err := unix.Poll(fds, 500)
+		if err != nil && err != unix.EINTR {
+			return
+		}
+		if n <= 0 {
+			continue
+		}
+
+		n, err = unix.Read(w.fd, buf)
+		if err != nil || n < unix.SizeofInotifyEvent {
+			continue
+		}
+		w.parse(buf[:n])
+	}
+}
+
+func (w *inotifyWatcher) parse(buf []byte) {
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(buf); {
+		raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		nameStart := offset + unix.SizeofInotifyEvent
+		nameEnd := nameStart + int(raw.Len)
+		if nameEnd > len(buf) {
+			return
+		}
+		name := strings.TrimRight(string(buf[nameStart:nameEnd]), "\x00")
+		offset = nameEnd
+
+		if name == "" || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if w.only != "" && name != w.only {
+			continue
+		}
+
+		var op string
+		switch {
+		case raw.Mask&unix.IN_CREATE != 0, raw.Mask&unix.IN_MOVED_TO != 0:
+			op = OpCreate
+		case raw.Mask&unix.IN_DELETE != 0:
+			op = OpRemove
+		case raw.Mask&unix.IN_MOVED_FROM != 0:
+			op = OpRename
+		case raw.Mask&(unix.IN_CLOSE_WRITE|unix.IN_MODIFY) != 0:
+			op = OpWrite
+		default:
+			continue
+		}
+
+		select {
+		case w.events <- fileChange{Path: filepath.Join(w.dir, name), Op: op}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package triggers
+
+func newFileWatcher(path string) (fileWatcher, error) {
+	return newPollWatcher(path, filePollInterval)
+}
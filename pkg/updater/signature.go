@@ -0,0 +1,236 @@
+package updater
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ReleasePublicKey is the minisign public key release archives are signed
+// with: either the full contents of a minisign .pub file or just its base64
+// line. Official builds embed it with
+//
+//	-ldflags "-X github.com/sipeed/picoclaw/pkg/updater.ReleasePublicKey=<key>"
+//
+// The goreleaser release build fails when the key is not provided. When it
+// is empty (local and development builds) updates are verified by SHA-256
+// checksum only.
+var ReleasePublicKey = ""
+
+// signatureSuffix is the file name suffix minisign uses for detached
+// signatures. The signature of an asset is published next to it.
+const signatureSuffix = ".minisig"
+
+var (
+	// ErrSignatureMissing is returned when a public key is embedded but the
+	// release does not publish a signature for the selected asset.
+	ErrSignatureMissing = errors.New("release asset has no minisign signature")
+	// ErrSignatureInvalid is returned when a signature does not match the
+	// asset or was made with a different key.
+	ErrSignatureInvalid = errors.New("release signature verification failed")
+)
+
+var (
+	minisignAlgLegacy    = [2]byte{'E', 'd'} // signature over the raw message
+	minisignAlgPrehashed = [2]byte{'E', 'D'} // signature over BLAKE2b-512(message)
+)
+
+// PublicKey is a parsed minisign Ed25519 public key.
+type PublicKey struct {
+	KeyID [8]byte
+	Key   ed25519.PublicKey
+}
+
+// Signature is a parsed minisign detached signature.
+type Signature struct {
+	Algorithm       [2]byte
+	KeyID           [8]byte
+	Signature       []byte
+	TrustedComment  string
+	GlobalSignature []byte
+}
+
+// SignatureVerificationEnabled reports whether this binary embeds a release
+// public key and therefore rejects unsigned updates.
+func SignatureVerificationEnabled() bool {
+	return strings.TrimSpace(ReleasePublicKey) != ""
+}
+
+// ParsePublicKey parses a minisign public key. It accepts the whole .pub
+// file (with its untrusted comment line) or only the base64 key line.
+func ParsePublicKey(s string) (PublicKey, error) {
+	var line string
+	for _, l := range strings.Split(strings.TrimSpace(s), "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "untrusted comment:") {
+			continue
+		}
+		line = l
+		break
+	}
+	raw, err := base64.StdEncoding.DecodeString(line)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("decode public key: %w", err)
+	}
+	if len(raw) != 2+8+ed25519.PublicKeySize || !bytes.Equal(raw[:2], minisignAlgLegacy[:]) {
+		return PublicKey{}, errors.New("unsupported public key format")
+	}
+	var pk PublicKey
+	copy(pk.KeyID[:], raw[2:10])
+	pk.Key = ed25519.PublicKey(append([]byte(nil), raw[10:]...))
+	return pk, nil
+}
+
+// ParseSignature parses the four-line minisign signature format.
+func ParseSignature(data []byte) (Signature, error) {
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		lines = append(lines, strings.TrimRight(sc.Text(), "\r"))
+	}
+	if err := sc.Err(); err != nil {
+		return Signature{}, err
+	}
+	if len(lines) < 4 ||
+		!strings.HasPrefix(lines[0], "untrusted comment:") ||
+		!strings.HasPrefix(lines[2], "trusted comment: ") {
+		return Signature{}, errors.New("malformed minisign signature")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil {
+		return Signature{}, fmt.Errorf("decode signature: %w", err)
+	}
+	if len(raw) != 2+8+ed25519.SignatureSize {
+		return Signature{}, errors.New("malformed minisign signature")
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil {
+		return Signature{}, fmt.Errorf("decode global signature: %w", err)
+	}
+	if len(global) != ed25519.SignatureSize {
+		return Signature{}, errors.New("malformed minisign global signature")
+	}
+
+	var sig Signature
+	copy(sig.Algorithm[:], raw[:2])
+	copy(sig.KeyID[:], raw[2:10])
+	sig.Signature = raw[10:]
+	sig.TrustedComment = strings.TrimPrefix(lines[2], "trusted comment: ")
+	sig.GlobalSignature = global
+	if sig.Algorithm != minisignAlgLegacy && sig.Algorithm != minisignAlgPrehashed {
+		return Signature{}, fmt.Errorf("unsupported signature algorithm %q", sig.Algorithm[:])
+	}
+	return sig, nil
+}
+
+// SignedFileName returns the file name minisign records in the trusted
+// comment ("timestamp:<unix>\tfile:<name>\thashed"), or "" when the comment
+// has none.
+func (sig Signature) SignedFileName() string {
+	for _, field := range strings.Split(sig.TrustedComment, "\t") {
+		if name, ok := strings.CutPrefix(field, "file:"); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// VerifyFile checks sig against the contents of the file at path.
+func (pk PublicKey) VerifyFile(path string, sig Signature) error {
+	if sig.KeyID != pk.KeyID {
+		return fmt.Errorf("%w: signed with key %X, expected %X", ErrSignatureInvalid, sig.KeyID, pk.KeyID)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var message []byte
+	if sig.Algorithm == minisignAlgPrehashed {
+		h, _ := blake2b.New512(nil)
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		message = h.Sum(nil)
+	} else {
+		if message, err = io.ReadAll(f); err != nil {
+			return err
+		}
+	}
+	if !ed25519.Verify(pk.Key, message, sig.Signature) {
+		return ErrSignatureInvalid
+	}
+
+	// The global signature binds the trusted comment to the signature.
+	global := append(append([]byte(nil), sig.Signature...), sig.TrustedComment...)
+	if !ed25519.Verify(pk.Key, global, sig.GlobalSignature) {
+		return fmt.Errorf("%w: trusted comment does not match", ErrSignatureInvalid)
+	}
+	return nil
+}
+
+// verifyReleaseSignature downloads the detached signature published for
+// assetURL and checks the archive at archivePath against ReleasePublicKey.
+// It is a no-op when no public key is embedded.
+func verifyReleaseSignature(archivePath, assetURL string) error {
+	if !SignatureVerificationEnabled() {
+		return nil
+	}
+	pk, err := ParsePublicKey(ReleasePublicKey)
+	if err != nil {
+		return fmt.Errorf("embedded release public key: %w", err)
+	}
+
+	resp, err := getWithRetry(assetURL + signatureSuffix)
+	if err != nil {
+		return fmt.Errorf("download signature: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrSignatureMissing, assetURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download signature: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return fmt.Errorf("download signature: %w", err)
+	}
+
+	sig, err := ParseSignature(data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSignatureInvalid, err)
+	}
+	if err := pk.VerifyFile(archivePath, sig); err != nil {
+		return err
+	}
+	// The trusted comment is covered by the global signature, so its file
+	// name proves the signature was made for this asset and not for another
+	// signed file served in its place.
+	if name, want := sig.SignedFileName(), assetFileName(assetURL); name != want {
+		return fmt.Errorf("%w: signature is for %q, not %q", ErrSignatureInvalid, name, want)
+	}
+	return nil
+}
+
+// assetFileName returns the last path element of a download URL.
+func assetFileName(assetURL string) string {
+	if u, err := url.Parse(assetURL); err == nil {
+		return path.Base(u.Path)
+	}
+	return path.Base(assetURL)
+}
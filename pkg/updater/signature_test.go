@@ -0,0 +1,205 @@
+package updater
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+type testSigner struct {
+	keyID [8]byte
+	priv  ed25519.PrivateKey
+	pub   ed25519.PublicKey
+}
+
+func newTestSigner(t *testing.T, keyID byte) *testSigner {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	s := &testSigner{priv: priv, pub: pub}
+	for i := range s.keyID {
+		s.keyID[i] = keyID
+	}
+	return s
+}
+
+// publicKey returns the key in minisign .pub file format.
+func (s *testSigner) publicKey() string {
+	raw := append(append([]byte("Ed"), s.keyID[:]...), s.pub...)
+	return "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(raw) + "\n"
+}
+
+// sign produces a minisign signature; prehashed selects the "ED" algorithm.
+func (s *testSigner) sign(message []byte, prehashed bool, comment string) []byte {
+	alg := "Ed"
+	if prehashed {
+		alg = "ED"
+		sum := blake2b.Sum512(message)
+		message = sum[:]
+	}
+	sig := ed25519.Sign(s.priv, message)
+	global := ed25519.Sign(s.priv, append(append([]byte(nil), sig...), comment...))
+	raw := append(append([]byte(alg), s.keyID[:]...), sig...)
+	return []byte(fmt.Sprintf(
+		"untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(raw),
+		comment,
+		base64.StdEncoding.EncodeToString(global),
+	))
+}
+
+func withReleasePublicKey(t *testing.T, key string) {
+	t.Helper()
+	orig := ReleasePublicKey
+	ReleasePublicKey = key
+	t.Cleanup(func() { ReleasePublicKey = orig })
+}
+
+func TestPublicKeyVerifyFile(t *testing.T) {
+	signer := newTestSigner(t, 0x42)
+	pk, err := ParsePublicKey(signer.publicKey())
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "asset.tar.gz")
+	payload := []byte("release archive payload")
+	if err := os.WriteFile(path, payload, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, prehashed := range []bool{true, false} {
+		sig, err := ParseSignature(signer.sign(payload, prehashed, "timestamp:1 file:asset.tar.gz"))
+		if err != nil {
+			t.Fatalf("ParseSignature(prehashed=%v): %v", prehashed, err)
+		}
+		if err := pk.VerifyFile(path, sig); err != nil {
+			t.Fatalf("VerifyFile(prehashed=%v): %v", prehashed, err)
+		}
+	}
+
+	// Tampered trusted comment.
+	sig, _ := ParseSignature(signer.sign(payload, true, "original"))
+	sig.TrustedComment = "forged"
+	if err := pk.VerifyFile(path, sig); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("forged comment: err = %v, want ErrSignatureInvalid", err)
+	}
+
+	// Tampered payload.
+	if err := os.WriteFile(path, []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sig, _ = ParseSignature(signer.sign(payload, true, "c"))
+	if err := pk.VerifyFile(path, sig); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("tampered payload: err = %v, want ErrSignatureInvalid", err)
+	}
+
+	// Signed by another key.
+	other := newTestSigner(t, 0x07)
+	sig, _ = ParseSignature(other.sign([]byte("tampered"), true, "c"))
+	if err := pk.VerifyFile(path, sig); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("other key: err = %v, want ErrSignatureInvalid", err)
+	}
+}
+
+func TestParsePublicKey_BareLine(t *testing.T) {
+	signer := newTestSigner(t, 1)
+	lines := strings.Split(strings.TrimSpace(signer.publicKey()), "\n")
+	pk, err := ParsePublicKey(lines[1])
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	if pk.KeyID != signer.keyID {
+		t.Fatalf("KeyID = %X, want %X", pk.KeyID, signer.keyID)
+	}
+	if _, err := ParsePublicKey("not base64!"); err == nil {
+		t.Fatal("expected error for malformed key")
+	}
+}
+
+func TestDownloadAndExtractRelease_VerifiesSignature(t *testing.T) {
+	signer := newTestSigner(t, 0x42)
+	withReleasePublicKey(t, signer.publicKey())
+
+	tarGzContent := buildTestTarGz(t, map[string]string{"picoclaw": "signed binary"})
+	sum := sha256.Sum256(tarGzContent)
+	checksum := hex.EncodeToString(sum[:])
+	goodSig := signer.sign(tarGzContent, true, "timestamp:1700000000\tfile:picoclaw_Linux_x86_64.tar.gz\thashed")
+	badSig := newTestSigner(t, 0x42).sign(tarGzContent, true, "forged")
+	otherAssetSig := signer.sign(tarGzContent, true, "timestamp:1700000000\tfile:picoclaw_Darwin_arm64.tar.gz\thashed")
+
+	var signature []byte
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case testReleaseAPIPath:
+			writeReleasePayload(w, testReleasePayload{
+				TagName: "v0.3.0",
+				Assets: []testReleaseAsset{
+					{
+						Name:               "picoclaw_Linux_x86_64.tar.gz.minisig",
+						BrowserDownloadURL: server.URL + "/assets/picoclaw_Linux_x86_64.tar.gz.minisig",
+					},
+					{
+						Name:               "picoclaw_Linux_x86_64.tar.gz",
+						BrowserDownloadURL: server.URL + "/assets/picoclaw_Linux_x86_64.tar.gz",
+						Digest:             "sha256:" + checksum,
+					},
+				},
+			})
+		case "/assets/picoclaw_Linux_x86_64.tar.gz":
+			_, _ = w.Write(tarGzContent)
+		case "/assets/picoclaw_Linux_x86_64.tar.gz.minisig":
+			if signature == nil {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(signature)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	withTestHTTPClient(t, server.Client())
+
+	apiURL := server.URL + testReleaseAPIPath
+
+	signature = nil
+	if _, err := DownloadAndExtractRelease(apiURL, "linux", "amd64"); !errors.Is(err, ErrSignatureMissing) {
+		t.Fatalf("unsigned release: err = %v, want ErrSignatureMissing", err)
+	}
+
+	signature = badSig
+	if _, err := DownloadAndExtractRelease(apiURL, "linux", "amd64"); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("bad signature: err = %v, want ErrSignatureInvalid", err)
+	}
+
+	signature = otherAssetSig
+	if _, err := DownloadAndExtractRelease(apiURL, "linux", "amd64"); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("signature for another asset: err = %v, want ErrSignatureInvalid", err)
+	}
+
+	signature = goodSig
+	dir, err := DownloadAndExtractRelease(apiURL, "linux", "amd64")
+	if err != nil {
+		t.Fatalf("signed release: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if bs, err := os.ReadFile(filepath.Join(dir, "picoclaw")); err != nil || string(bs) != "signed binary" {
+		t.Fatalf("extracted = %q, %v", bs, err)
+	}
+}
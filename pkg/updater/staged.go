@@ -0,0 +1,142 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/minio/selfupdate"
+)
+
+// StagedUpdate is a downloaded and verified release binary that has not yet
+// replaced the installed one. The caller applies it, checks that the new
+// version works (for the gateway: restart it and wait for /ready) and rolls
+// back if it does not.
+type StagedUpdate struct {
+	// Target is the installed binary the update replaces.
+	Target string
+	// Backup is where Apply keeps the previous binary for Rollback.
+	Backup string
+
+	dir    string // extraction directory holding the staged binary
+	binary string // staged binary inside dir
+}
+
+// BackupPath returns where the binary at target is kept while an update
+// is applied.
+func BackupPath(target string) string {
+	return target + ".old"
+}
+
+// StageUpdate downloads the release, verifies its checksum and signature and
+// extracts programName, leaving target untouched. An empty target means the
+// running executable; empty platform/arch mean the runtime values. Call
+// Close when done to remove the staging directory.
+func StageUpdate(releaseURL, platform, arch, programName, target string) (*StagedUpdate, error) {
+	if platform == "" {
+		platform = runtime.GOOS
+	}
+	if arch == "" {
+		arch = runtime.GOARCH
+	}
+	target, err := resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := DownloadAndExtractRelease(releaseURL, platform, arch)
+	if err != nil {
+		return nil, err
+	}
+	binPath, err := findBinaryInDir(dir, programName)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	// ensure executable bit on non-windows
+	if runtime.GOOS != "windows" {
+		_ = os.Chmod(binPath, 0o755)
+	}
+
+	return &StagedUpdate{
+		Target: target,
+		Backup: BackupPath(target),
+		dir:    dir,
+		binary: binPath,
+	}, nil
+}
+
+// Apply replaces Target with the staged binary and keeps the previous one
+// at Backup.
+func (s *StagedUpdate) Apply() error {
+	f, err := os.Open(s.binary)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := selfupdate.Apply(f, selfupdate.Options{TargetPath: s.Target, OldSavePath: s.Backup}); err != nil {
+		if rerr := selfupdate.RollbackError(err); rerr != nil {
+			return fmt.Errorf("apply update: %w (restoring previous binary also failed: %v)", err, rerr)
+		}
+		return fmt.Errorf("apply update: %w", err)
+	}
+	return nil
+}
+
+// Rollback restores the binary that Apply replaced.
+func (s *StagedUpdate) Rollback() error {
+	return RollbackBinary(s.Target)
+}
+
+// Close removes the staging directory. The backup is kept so the previous
+// version can still be restored later with RollbackBinary.
+func (s *StagedUpdate) Close() error {
+	if s.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(s.dir)
+}
+
+// RollbackBinary restores target from the backup left by the last update.
+// An empty target means the running executable.
+func RollbackBinary(target string) error {
+	target, err := resolveTarget(target)
+	if err != nil {
+		return err
+	}
+	backup := BackupPath(target)
+	f, err := os.Open(backup)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no previous version to roll back to (%s not found)", backup)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = selfupdate.Apply(f, selfupdate.Options{TargetPath: target})
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("roll back to %s: %w", backup, err)
+	}
+	// The backup is now the installed binary; drop it so a second rollback
+	// does not look like it restored something different.
+	_ = os.Remove(backup)
+	return nil
+}
+
+func resolveTarget(target string) (string, error) {
+	if target == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return "", fmt.Errorf("locate running executable: %w", err)
+		}
+		target = exe
+	}
+	if resolved, err := filepath.EvalSymlinks(target); err == nil {
+		target = resolved
+	}
+	return filepath.Abs(target)
+}
@@ -0,0 +1,85 @@
+package updater
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestStagedUpdate_ApplyAndRollback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("selfupdate hides rather than removes replaced binaries on windows")
+	}
+
+	tarGzContent := buildTestTarGz(t, map[string]string{"picoclaw": "new version"})
+	sum := sha256.Sum256(tarGzContent)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case testReleaseAPIPath:
+			writeReleasePayload(w, testReleasePayload{
+				TagName: "v0.3.0",
+				Assets: []testReleaseAsset{{
+					Name:               "picoclaw_Linux_x86_64.tar.gz",
+					BrowserDownloadURL: server.URL + "/assets/picoclaw_Linux_x86_64.tar.gz",
+					Digest:             "sha256:" + hex.EncodeToString(sum[:]),
+				}},
+			})
+		case "/assets/picoclaw_Linux_x86_64.tar.gz":
+			_, _ = w.Write(tarGzContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	withTestHTTPClient(t, server.Client())
+	withReleasePublicKey(t, "")
+
+	target := filepath.Join(t.TempDir(), "picoclaw")
+	if err := os.WriteFile(target, []byte("old version"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	staged, err := StageUpdate(server.URL+testReleaseAPIPath, "linux", "amd64", "picoclaw", target)
+	if err != nil {
+		t.Fatalf("StageUpdate: %v", err)
+	}
+	defer staged.Close()
+
+	// Staging alone must not touch the installed binary.
+	assertFileContent(t, target, "old version")
+
+	if err := staged.Apply(); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	assertFileContent(t, target, "new version")
+	assertFileContent(t, staged.Backup, "old version")
+
+	if err := staged.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	assertFileContent(t, target, "old version")
+	if _, err := os.Stat(staged.Backup); !os.IsNotExist(err) {
+		t.Fatalf("backup should be consumed by rollback, stat err = %v", err)
+	}
+	if err := RollbackBinary(target); err == nil {
+		t.Fatal("second rollback should report there is nothing to restore")
+	}
+}
+
+func assertFileContent(t *testing.T, path, want string) {
+	t.Helper()
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s): %v", path, err)
+	}
+	if string(bs) != want {
+		t.Fatalf("%s = %q, want %q", path, bs, want)
+	}
+}
@@ -19,7 +19,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/minio/selfupdate"
 	"github.com/spf13/cobra"
 
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -112,6 +111,13 @@ func DownloadAndExtractRelease(releaseURL, platform, arch string) (string, error
 		}
 	}
 
+	// The checksum comes from the same release as the asset; the publisher
+	// signature is what proves the release itself is genuine.
+	if err := verifyReleaseSignature(tmpPath, assetURL); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", err
+	}
+
 	// Extract
 	destDir, err := os.MkdirTemp("", "picoclaw-extract-*")
 	if err != nil {
@@ -132,50 +138,18 @@ func DownloadAndExtractRelease(releaseURL, platform, arch string) (string, error
 
 // UpdateSelfFromRelease downloads the release matching the given parameters,
 // extracts it and applies the binary named programName to update the
-// currently running executable using minio/selfupdate.
+// currently running executable using minio/selfupdate. The previous binary
+// is kept at BackupPath(executable) so RollbackBinary can restore it.
 // If releaseURL is empty, the latest release is used. If platform or arch
 // is empty, runtime values are used.
 func UpdateSelfFromRelease(releaseURL, platform, arch, programName string) error {
-	if platform == "" {
-		platform = runtime.GOOS
-	}
-	if arch == "" {
-		arch = runtime.GOARCH
-	}
-
-	dir, err := DownloadAndExtractRelease(releaseURL, platform, arch)
-	if err != nil {
-		return err
-	}
-	defer os.RemoveAll(dir)
-
-	binPath, err := findBinaryInDir(dir, programName)
-	if err != nil {
-		return err
-	}
-
-	// ensure executable bit on non-windows
-	if runtime.GOOS != "windows" {
-		_ = os.Chmod(binPath, 0o755)
-	}
-
-	f, err := os.Open(binPath)
+	staged, err := StageUpdate(releaseURL, platform, arch, programName, "")
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-
-	// Backup current executable so we can roll back if needed.
-	var opts selfupdate.Options
-	if exePath, err := os.Executable(); err == nil {
-		opts.OldSavePath = exePath + ".old"
-	}
-
-	if err := selfupdate.Apply(f, opts); err != nil {
-		return fmt.Errorf("apply update: %w", err)
-	}
+	defer staged.Close()
 
-	return nil
+	return staged.Apply()
 }
 
 // UpdateSelf updates the running executable by fetching the latest release
@@ -261,6 +235,9 @@ func findAssetInfo(releaseURL, platform, arch string) (string, string, error) {
 	var platformIdx []int
 	for i, a := range data.Assets {
 		n := strings.ToLower(a.Name)
+		if strings.HasSuffix(n, signatureSuffix) {
+			continue
+		}
 		if platform == "" || strings.Contains(n, platformLower) {
 			platformIdx = append(platformIdx, i)
 		}
@@ -703,15 +680,35 @@ func NewUpdateCommand(binaryName string) *cobra.Command {
 				arch = runtime.GOARCH
 			}
 			fmt.Printf("Current version: %s\n", config.FormatVersion())
+			if !SignatureVerificationEnabled() {
+				fmt.Println("Warning: this build has no release signing key; only the checksum is verified.")
+			}
 			if err := UpdateSelfFromRelease(urlStr, platform, arch, binaryName); err != nil {
 				return err
 			}
 			fmt.Println("Update applied; restart to use the new version.")
+			fmt.Printf("If the new version misbehaves, run '%s update rollback'.\n", binaryName)
 			return nil
 		},
 	}
 	cmd.Flags().StringVarP(&urlStr, "url", "u", "", "Direct URL to download release asset or release page")
 	cmd.Flags().StringVar(&platform, "platform", "", "Target platform (default: runtime.GOOS)")
 	cmd.Flags().StringVar(&arch, "arch", "", "Target arch (default: runtime.GOARCH)")
+	cmd.AddCommand(newRollbackCommand())
 	return cmd
 }
+
+func newRollbackCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rollback",
+		Short: "Restore the binary replaced by the last update",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RollbackBinary(""); err != nil {
+				return err
+			}
+			fmt.Println("Previous version restored; restart to use it.")
+			return nil
+		},
+	}
+}
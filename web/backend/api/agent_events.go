@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httputil"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// gatewayEventStreamPath mirrors agent.EventStreamPath; the launcher does not
+// link the agent package.
+const gatewayEventStreamPath = "/events/agent"
+
+// registerAgentEventRoutes binds the live agent event stream.
+func (h *Handler) registerAgentEventRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/agent/events", h.handleAgentEvents)
+}
+
+// handleAgentEvents proxies the gateway's agent event stream (Server-Sent
+// Events). Dashboard auth applies to this route; the gateway token from the
+// pid file is injected only on the upstream request. The session, agent and
+// kind query parameters are passed through as filters.
+//
+//	GET /api/agent/events
+func (h *Handler) handleAgentEvents(w http.ResponseWriter, r *http.Request) {
+	token := h.gatewayToken()
+	if token == "" {
+		http.Error(w, "Gateway not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(h.gatewayProxyURL())
+			pr.Out.URL.Path = gatewayEventStreamPath
+			pr.Out.URL.RawPath = ""
+			pr.Out.Header.Set("Authorization", "Bearer "+token)
+		},
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if r.Context().Err() != nil {
+				return
+			}
+			logger.Errorf("Failed to proxy agent event stream: %v", err)
+			http.Error(w, "Gateway unavailable: "+err.Error(), http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
@@ -2,13 +2,13 @@ package api
 
 import (
 	"context"
-	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 
+	"github.com/sipeed/picoclaw/web/backend/dashboardauth"
 	"github.com/sipeed/picoclaw/web/backend/middleware"
 )
 
@@ -25,7 +25,11 @@ type LauncherAuthRouteOpts struct {
 	SessionCookie string
 	SecureCookie  func(*http.Request) bool
 	// PasswordStore enables password login. It must be non-nil for auth to work.
+	// When it also implements AccountStore and Sessions is set, login switches
+	// to per-user accounts with roles, TOTP and API tokens.
 	PasswordStore PasswordStore
+	// Sessions holds per-user login sessions for multi-user accounts.
+	Sessions *middleware.DashboardSessions
 	// StoreError holds the error returned when opening the password store. When
 	// non-nil and PasswordStore is nil, auth endpoints fail closed with a
 	// recovery message.
@@ -33,10 +37,16 @@ type LauncherAuthRouteOpts struct {
 }
 
 type launcherAuthLoginBody struct {
+	// Username selects the account; empty means the default admin so the
+	// single-password login keeps working.
+	Username string `json:"username,omitempty"`
 	Password string `json:"password"`
+	TOTPCode string `json:"totp_code,omitempty"`
 }
 
 type launcherAuthSetupBody struct {
+	// Username names the first admin account during initial setup.
+	Username string `json:"username,omitempty"`
 	Password string `json:"password"`
 	Confirm  string `json:"confirm"`
 }
@@ -44,6 +54,12 @@ type launcherAuthSetupBody struct {
 type launcherAuthStatusResponse struct {
 	Authenticated bool `json:"authenticated"`
 	Initialized   bool `json:"initialized"`
+	// MultiUser reports whether per-user accounts are available.
+	MultiUser bool   `json:"multi_user"`
+	Username  string `json:"username,omitempty"`
+	Role      string `json:"role,omitempty"`
+	// TOTPEnabled reports whether the signed-in account has a second factor.
+	TOTPEnabled bool `json:"totp_enabled,omitempty"`
 }
 
 // RegisterLauncherAuthRoutes registers /api/auth/login|logout|status|setup.
@@ -59,10 +75,15 @@ func RegisterLauncherAuthRoutes(mux *http.ServeMux, opts LauncherAuthRouteOpts)
 		storeErr:      opts.StoreError,
 		loginLimit:    newLoginRateLimiter(),
 	}
+	if accounts, ok := opts.PasswordStore.(AccountStore); ok && opts.Sessions != nil {
+		h.accounts = accounts
+		h.sessions = opts.Sessions
+	}
 	mux.HandleFunc("POST /api/auth/login", h.handleLogin)
 	mux.HandleFunc("POST /api/auth/logout", h.handleLogout)
 	mux.HandleFunc("GET /api/auth/status", h.handleStatus)
 	mux.HandleFunc("POST /api/auth/setup", h.handleSetup)
+	h.registerAccountRoutes(mux)
 }
 
 type launcherAuthHandlers struct {
@@ -71,6 +92,18 @@ type launcherAuthHandlers struct {
 	store         PasswordStore
 	storeErr      error // set when the store failed to open; drives recovery messages
 	loginLimit    *loginRateLimiter
+	// accounts and sessions are set when multi-user accounts are enabled.
+	accounts AccountStore
+	sessions *middleware.DashboardSessions
+}
+
+// principal returns the signed-in caller, preferring the principal attached by
+// LauncherDashboardAuth and falling back to the session cookie.
+func (h *launcherAuthHandlers) principal(r *http.Request) (middleware.DashboardPrincipal, bool) {
+	if p, ok := middleware.DashboardPrincipalFromContext(r.Context()); ok {
+		return p, true
+	}
+	return middleware.LauncherDashboardCookiePrincipal(r, h.sessionCookie, h.sessions)
 }
 
 // isStoreInitialized safely queries the store.
@@ -117,6 +150,11 @@ func (h *launcherAuthHandlers) handleLogin(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if h.accounts != nil {
+		h.loginAccount(w, r, body, in)
+		return
+	}
+
 	ok, err := h.store.VerifyPassword(r.Context(), in)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -159,6 +197,9 @@ func (h *launcherAuthHandlers) handleLogout(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if c, err := r.Cookie(middleware.LauncherDashboardCookieName); err == nil {
+		h.sessions.Revoke(c.Value)
+	}
 	middleware.ClearLauncherDashboardSessionCookie(w, r, h.secureCookie)
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte(`{"status":"ok"}`))
@@ -166,10 +207,7 @@ func (h *launcherAuthHandlers) handleLogout(w http.ResponseWriter, r *http.Reque
 
 func (h *launcherAuthHandlers) handleStatus(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	authed := false
-	if c, err := r.Cookie(middleware.LauncherDashboardCookieName); err == nil {
-		authed = subtle.ConstantTimeCompare([]byte(c.Value), []byte(h.sessionCookie)) == 1
-	}
+	principal, authed := h.principal(r)
 	initialized, initErr := h.isStoreInitialized(r.Context())
 	if initErr != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -179,6 +217,16 @@ func (h *launcherAuthHandlers) handleStatus(w http.ResponseWriter, r *http.Reque
 	resp := launcherAuthStatusResponse{
 		Authenticated: authed,
 		Initialized:   initialized,
+		MultiUser:     h.accounts != nil,
+	}
+	if authed {
+		resp.Username = principal.Username
+		resp.Role = string(principal.Role)
+		if h.accounts != nil && principal.Via != middleware.DashboardViaToken {
+			if user, err := h.accounts.GetUser(r.Context(), principal.Username); err == nil {
+				resp.TOTPEnabled = user.TOTPEnabled
+			}
+		}
 	}
 	enc, err := json.Marshal(resp)
 	if err != nil {
@@ -193,8 +241,10 @@ func (h *launcherAuthHandlers) handleStatus(w http.ResponseWriter, r *http.Reque
 //
 // Rules:
 //   - If the store has no password yet, anyone who can reach the setup endpoint
-//     may initialize the password.
-//   - If a password is already set, the caller must hold a valid session cookie.
+//     may initialize the password. With multi-user accounts this creates the
+//     first admin account.
+//   - If a password is already set, the caller must hold a valid session cookie
+//     and changes their own password.
 func (h *launcherAuthHandlers) handleSetup(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -216,11 +266,8 @@ func (h *launcherAuthHandlers) handleSetup(w http.ResponseWriter, r *http.Reques
 	}
 
 	// If already initialized, require an active session (change-password flow).
+	principal, authed := h.principal(r)
 	if initialized {
-		authed := false
-		if c, err := r.Cookie(middleware.LauncherDashboardCookieName); err == nil {
-			authed = subtle.ConstantTimeCompare([]byte(c.Value), []byte(h.sessionCookie)) == 1
-		}
 		if !authed {
 			w.WriteHeader(http.StatusUnauthorized)
 			_, _ = w.Write([]byte(`{"error":"must be authenticated to change password"}`))
@@ -252,9 +299,21 @@ func (h *launcherAuthHandlers) handleSetup(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if err := h.store.SetPassword(r.Context(), pw); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		writeErrorf(w, "failed to save password: %v", err)
+	var err error
+	switch {
+	case h.accounts == nil:
+		err = h.store.SetPassword(r.Context(), pw)
+	case initialized:
+		err = h.accounts.SetUserPassword(r.Context(), principal.Username, pw)
+	default:
+		username := strings.TrimSpace(body.Username)
+		if username == "" {
+			username = dashboardauth.DefaultAdminUsername
+		}
+		_, err = h.accounts.CreateUser(r.Context(), username, pw, dashboardauth.RoleAdmin)
+	}
+	if err != nil {
+		writeAccountError(w, err, "failed to save password")
 		return
 	}
 
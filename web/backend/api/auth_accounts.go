@@ -0,0 +1,509 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/web/backend/dashboardauth"
+	"github.com/sipeed/picoclaw/web/backend/middleware"
+)
+
+// AccountStore extends PasswordStore with multi-user dashboard accounts, TOTP,
+// API tokens and the audit log. Implemented by dashboardauth.Store.
+type AccountStore interface {
+	PasswordStore
+	CreateUser(ctx context.Context, username, plain string, role dashboardauth.Role) (dashboardauth.User, error)
+	GetUser(ctx context.Context, username string) (dashboardauth.User, error)
+	ListUsers(ctx context.Context) ([]dashboardauth.User, error)
+	UpdateUserRole(ctx context.Context, username string, role dashboardauth.Role) error
+	SetUserPassword(ctx context.Context, username, plain string) error
+	DeleteUser(ctx context.Context, username string) error
+	AuthenticateUser(ctx context.Context, username, plain string) (dashboardauth.User, error)
+	BeginTOTPEnrollment(ctx context.Context, username string) (string, error)
+	ConfirmTOTPEnrollment(ctx context.Context, username, code string) error
+	DisableTOTP(ctx context.Context, username string) error
+	VerifyTOTP(ctx context.Context, username, code string) (bool, error)
+	CreateAPIToken(ctx context.Context, spec dashboardauth.APITokenSpec) (string, dashboardauth.APIToken, error)
+	ListAPITokens(ctx context.Context, username string) ([]dashboardauth.APIToken, error)
+	RevokeAPIToken(ctx context.Context, id int64, username string) error
+	ListAudit(ctx context.Context, q dashboardauth.AuditQuery) ([]dashboardauth.AuditEntry, error)
+}
+
+const minDashboardPasswordLen = 8
+
+type accountUserBody struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+type accountUserUpdateBody struct {
+	Role     string `json:"role,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+type accountTOTPBody struct {
+	Code string `json:"code"`
+}
+
+type accountTOTPEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type accountTokenBody struct {
+	Name          string   `json:"name"`
+	Role          string   `json:"role,omitempty"`
+	Scopes        []string `json:"scopes,omitempty"`
+	AllowedCIDRs  []string `json:"allowed_cidrs,omitempty"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"`
+}
+
+type accountTokenCreateResponse struct {
+	// Token is the plaintext bearer token; it is only returned once.
+	Token    string                 `json:"token"`
+	APIToken dashboardauth.APIToken `json:"api_token"`
+}
+
+func (h *launcherAuthHandlers) registerAccountRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/auth/users", h.handleListUsers)
+	mux.HandleFunc("POST /api/auth/users", h.handleCreateUser)
+	mux.HandleFunc("PUT /api/auth/users/{username}", h.handleUpdateUser)
+	mux.HandleFunc("DELETE /api/auth/users/{username}", h.handleDeleteUser)
+	mux.HandleFunc("DELETE /api/auth/users/{username}/totp", h.handleResetUserTOTP)
+	mux.HandleFunc("POST /api/auth/totp/enroll", h.handleEnrollTOTP)
+	mux.HandleFunc("POST /api/auth/totp/confirm", h.handleConfirmTOTP)
+	mux.HandleFunc("POST /api/auth/totp/disable", h.handleDisableTOTP)
+	mux.HandleFunc("GET /api/auth/tokens", h.handleListTokens)
+	mux.HandleFunc("POST /api/auth/tokens", h.handleCreateToken)
+	mux.HandleFunc("DELETE /api/auth/tokens/{id}", h.handleRevokeToken)
+	mux.HandleFunc("GET /api/auth/audit", h.handleListAudit)
+}
+
+// loginAccount completes a password login against per-user accounts.
+func (h *launcherAuthHandlers) loginAccount(
+	w http.ResponseWriter,
+	r *http.Request,
+	body launcherAuthLoginBody,
+	password string,
+) {
+	username := strings.TrimSpace(body.Username)
+	if username == "" {
+		username = dashboardauth.DefaultAdminUsername
+	}
+	user, err := h.accounts.AuthenticateUser(r.Context(), username, password)
+	if errors.Is(err, dashboardauth.ErrInvalidCredentials) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid username or password"}`))
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		writeErrorf(w, "password verification failed: %v", err)
+		return
+	}
+	if user.TOTPEnabled {
+		code := strings.TrimSpace(body.TOTPCode)
+		if code == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"TOTP code required","totp_required":true}`))
+			return
+		}
+		ok, verr := h.accounts.VerifyTOTP(r.Context(), user.Username, code)
+		if verr != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			writeErrorf(w, "TOTP verification failed: %v", verr)
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid TOTP code","totp_required":true}`))
+			return
+		}
+	}
+
+	value, err := h.sessions.Issue(middleware.DashboardPrincipal{
+		Username: user.Username,
+		Role:     user.Role,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		writeErrorf(w, "failed to create session: %v", err)
+		return
+	}
+	middleware.SetLauncherDashboardSessionCookie(w, r, value, h.secureCookie)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`{"status":"ok"}`))
+}
+
+// accountPrincipal resolves the caller for account endpoints and checks that
+// multi-user accounts are enabled and the caller holds the required role.
+// API tokens never reach these endpoints (see middleware.DashboardRoleAccess).
+func (h *launcherAuthHandlers) accountPrincipal(
+	w http.ResponseWriter,
+	r *http.Request,
+	required dashboardauth.Role,
+) (middleware.DashboardPrincipal, bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if h.accounts == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		_, _ = w.Write([]byte(`{"error":"multi-user accounts are unavailable on this platform"}`))
+		return middleware.DashboardPrincipal{}, false
+	}
+	p, ok := h.principal(r)
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"unauthorized"}`))
+		return middleware.DashboardPrincipal{}, false
+	}
+	if p.Via == middleware.DashboardViaToken {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"API tokens cannot manage dashboard accounts"}`))
+		return middleware.DashboardPrincipal{}, false
+	}
+	if !p.Role.Allows(required) {
+		w.WriteHeader(http.StatusForbidden)
+		writeErrorf(w, "%s role required", required)
+		return middleware.DashboardPrincipal{}, false
+	}
+	return p, true
+}
+
+func (h *launcherAuthHandlers) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.accountPrincipal(w, r, dashboardauth.RoleAdmin); !ok {
+		return
+	}
+	users, err := h.accounts.ListUsers(r.Context())
+	if err != nil {
+		writeAccountError(w, err, "failed to list users")
+		return
+	}
+	if users == nil {
+		users = []dashboardauth.User{}
+	}
+	writeAccountJSON(w, http.StatusOK, map[string]any{"users": users})
+}
+
+func (h *launcherAuthHandlers) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.accountPrincipal(w, r, dashboardauth.RoleAdmin); !ok {
+		return
+	}
+	var body accountUserBody
+	if !decodeAccountBody(w, r, &body) {
+		return
+	}
+	role, err := dashboardauth.ParseRole(body.Role)
+	if err != nil {
+		writeAccountError(w, err, "")
+		return
+	}
+	if !validDashboardPassword(w, body.Password) {
+		return
+	}
+	user, err := h.accounts.CreateUser(r.Context(), strings.TrimSpace(body.Username), strings.TrimSpace(body.Password), role)
+	if err != nil {
+		writeAccountError(w, err, "failed to create user")
+		return
+	}
+	writeAccountJSON(w, http.StatusCreated, user)
+}
+
+func (h *launcherAuthHandlers) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.accountPrincipal(w, r, dashboardauth.RoleAdmin); !ok {
+		return
+	}
+	var body accountUserUpdateBody
+	if !decodeAccountBody(w, r, &body) {
+		return
+	}
+	user, err := h.accounts.GetUser(r.Context(), r.PathValue("username"))
+	if err != nil {
+		writeAccountError(w, err, "failed to load user")
+		return
+	}
+	if body.Role != "" {
+		role, perr := dashboardauth.ParseRole(body.Role)
+		if perr != nil {
+			writeAccountError(w, perr, "")
+			return
+		}
+		if err = h.accounts.UpdateUserRole(r.Context(), user.Username, role); err != nil {
+			writeAccountError(w, err, "failed to update role")
+			return
+		}
+	}
+	if body.Password != "" {
+		if !validDashboardPassword(w, body.Password) {
+			return
+		}
+		if err = h.accounts.SetUserPassword(r.Context(), user.Username, strings.TrimSpace(body.Password)); err != nil {
+			writeAccountError(w, err, "failed to set password")
+			return
+		}
+	}
+	// Sessions carry the role they were issued with; force a fresh login.
+	h.sessions.RevokeUser(user.Username)
+	if user, err = h.accounts.GetUser(r.Context(), user.Username); err != nil {
+		writeAccountError(w, err, "failed to load user")
+		return
+	}
+	writeAccountJSON(w, http.StatusOK, user)
+}
+
+func (h *launcherAuthHandlers) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.accountPrincipal(w, r, dashboardauth.RoleAdmin); !ok {
+		return
+	}
+	user, err := h.accounts.GetUser(r.Context(), r.PathValue("username"))
+	if err != nil {
+		writeAccountError(w, err, "failed to load user")
+		return
+	}
+	if err = h.accounts.DeleteUser(r.Context(), user.Username); err != nil {
+		writeAccountError(w, err, "failed to delete user")
+		return
+	}
+	h.sessions.RevokeUser(user.Username)
+	writeAccountJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleResetUserTOTP lets an admin remove a user's second factor, e.g. after
+// a lost phone.
+func (h *launcherAuthHandlers) handleResetUserTOTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.accountPrincipal(w, r, dashboardauth.RoleAdmin); !ok {
+		return
+	}
+	if err := h.accounts.DisableTOTP(r.Context(), r.PathValue("username")); err != nil {
+		writeAccountError(w, err, "failed to reset TOTP")
+		return
+	}
+	writeAccountJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *launcherAuthHandlers) handleEnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.accountPrincipal(w, r, dashboardauth.RoleViewer)
+	if !ok {
+		return
+	}
+	user, err := h.accounts.GetUser(r.Context(), p.Username)
+	if err != nil {
+		writeAccountError(w, err, "failed to load user")
+		return
+	}
+	if user.TOTPEnabled {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":"TOTP is already enabled; disable it first"}`))
+		return
+	}
+	secret, err := h.accounts.BeginTOTPEnrollment(r.Context(), user.Username)
+	if err != nil {
+		writeAccountError(w, err, "failed to start TOTP enrollment")
+		return
+	}
+	writeAccountJSON(w, http.StatusOK, accountTOTPEnrollResponse{
+		Secret: secret,
+		URI:    dashboardauth.TOTPURI(user.Username, secret),
+	})
+}
+
+func (h *launcherAuthHandlers) handleConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.accountPrincipal(w, r, dashboardauth.RoleViewer)
+	if !ok {
+		return
+	}
+	var body accountTOTPBody
+	if !decodeAccountBody(w, r, &body) {
+		return
+	}
+	if err := h.accounts.ConfirmTOTPEnrollment(r.Context(), p.Username, body.Code); err != nil {
+		writeAccountError(w, err, "failed to enable TOTP")
+		return
+	}
+	writeAccountJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *launcherAuthHandlers) handleDisableTOTP(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.accountPrincipal(w, r, dashboardauth.RoleViewer)
+	if !ok {
+		return
+	}
+	var body accountTOTPBody
+	if !decodeAccountBody(w, r, &body) {
+		return
+	}
+	valid, err := h.accounts.VerifyTOTP(r.Context(), p.Username, body.Code)
+	if err != nil {
+		writeAccountError(w, err, "TOTP verification failed")
+		return
+	}
+	if !valid {
+		writeAccountError(w, dashboardauth.ErrInvalidTOTPCode, "")
+		return
+	}
+	if err = h.accounts.DisableTOTP(r.Context(), p.Username); err != nil {
+		writeAccountError(w, err, "failed to disable TOTP")
+		return
+	}
+	writeAccountJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleListTokens returns the caller's tokens, or every token for admins.
+func (h *launcherAuthHandlers) handleListTokens(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.accountPrincipal(w, r, dashboardauth.RoleViewer)
+	if !ok {
+		return
+	}
+	owner := p.Username
+	if p.Role == dashboardauth.RoleAdmin {
+		owner = ""
+	}
+	tokens, err := h.accounts.ListAPITokens(r.Context(), owner)
+	if err != nil {
+		writeAccountError(w, err, "failed to list API tokens")
+		return
+	}
+	if tokens == nil {
+		tokens = []dashboardauth.APIToken{}
+	}
+	writeAccountJSON(w, http.StatusOK, map[string]any{"tokens": tokens})
+}
+
+func (h *launcherAuthHandlers) handleCreateToken(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.accountPrincipal(w, r, dashboardauth.RoleViewer)
+	if !ok {
+		return
+	}
+	var body accountTokenBody
+	if !decodeAccountBody(w, r, &body) {
+		return
+	}
+	spec := dashboardauth.APITokenSpec{
+		Username:     p.Username,
+		Name:         body.Name,
+		Scopes:       body.Scopes,
+		AllowedCIDRs: body.AllowedCIDRs,
+	}
+	if body.Role != "" {
+		role, err := dashboardauth.ParseRole(body.Role)
+		if err != nil {
+			writeAccountError(w, err, "")
+			return
+		}
+		spec.Role = role
+	}
+	if body.ExpiresInDays < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"expires_in_days must not be negative"}`))
+		return
+	}
+	if body.ExpiresInDays > 0 {
+		spec.ExpiresAt = time.Now().AddDate(0, 0, body.ExpiresInDays)
+	}
+	plain, tok, err := h.accounts.CreateAPIToken(r.Context(), spec)
+	if err != nil {
+		writeAccountError(w, err, "failed to create API token")
+		return
+	}
+	writeAccountJSON(w, http.StatusCreated, accountTokenCreateResponse{Token: plain, APIToken: tok})
+}
+
+func (h *launcherAuthHandlers) handleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.accountPrincipal(w, r, dashboardauth.RoleViewer)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid token id"}`))
+		return
+	}
+	owner := p.Username
+	if p.Role == dashboardauth.RoleAdmin {
+		owner = ""
+	}
+	if err = h.accounts.RevokeAPIToken(r.Context(), id, owner); err != nil {
+		writeAccountError(w, err, "failed to revoke API token")
+		return
+	}
+	writeAccountJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *launcherAuthHandlers) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.accountPrincipal(w, r, dashboardauth.RoleAdmin); !ok {
+		return
+	}
+	q := dashboardauth.AuditQuery{Username: strings.TrimSpace(r.URL.Query().Get("user"))}
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid limit"}`))
+			return
+		}
+		q.Limit = limit
+	}
+	entries, err := h.accounts.ListAudit(r.Context(), q)
+	if err != nil {
+		writeAccountError(w, err, "failed to list audit log")
+		return
+	}
+	if entries == nil {
+		entries = []dashboardauth.AuditEntry{}
+	}
+	writeAccountJSON(w, http.StatusOK, map[string]any{"entries": entries})
+}
+
+func decodeAccountBody(w http.ResponseWriter, r *http.Request, out any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(out); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid JSON"}`))
+		return false
+	}
+	return true
+}
+
+func validDashboardPassword(w http.ResponseWriter, password string) bool {
+	if len([]rune(strings.TrimSpace(password))) < minDashboardPasswordLen {
+		w.WriteHeader(http.StatusBadRequest)
+		writeErrorf(w, "password must be at least %d characters", minDashboardPasswordLen)
+		return false
+	}
+	return true
+}
+
+func writeAccountJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeAccountError maps dashboardauth errors to HTTP statuses. prefix
+// describes the failed operation for unexpected (5xx) errors.
+func writeAccountError(w http.ResponseWriter, err error, prefix string) {
+	var inputErr *dashboardauth.InputError
+	status := http.StatusInternalServerError
+	switch {
+	case errors.As(err, &inputErr),
+		errors.Is(err, dashboardauth.ErrInvalidTOTPCode),
+		errors.Is(err, dashboardauth.ErrTOTPNotPending):
+		status = http.StatusBadRequest
+	case errors.Is(err, dashboardauth.ErrUserNotFound),
+		errors.Is(err, dashboardauth.ErrAPITokenNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, dashboardauth.ErrUserExists),
+		errors.Is(err, dashboardauth.ErrLastAdmin):
+		status = http.StatusConflict
+	}
+	w.WriteHeader(status)
+	if status == http.StatusInternalServerError && prefix != "" {
+		writeErrorf(w, "%s: %v", prefix, err)
+		return
+	}
+	writeErrorf(w, "%v", err)
+}
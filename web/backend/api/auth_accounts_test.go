@@ -0,0 +1,232 @@
+//go:build !mipsle && !netbsd && !(freebsd && arm)
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/web/backend/dashboardauth"
+	"github.com/sipeed/picoclaw/web/backend/middleware"
+)
+
+type accountTestEnv struct {
+	t        *testing.T
+	store    *dashboardauth.Store
+	sessions *middleware.DashboardSessions
+	mux      *http.ServeMux
+}
+
+func newAccountTestEnv(t *testing.T) *accountTestEnv {
+	t.Helper()
+	store, err := dashboardauth.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("dashboardauth.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	env := &accountTestEnv{
+		t:        t,
+		store:    store,
+		sessions: middleware.NewDashboardSessions(time.Hour),
+		mux:      http.NewServeMux(),
+	}
+	RegisterLauncherAuthRoutes(env.mux, LauncherAuthRouteOpts{
+		SessionCookie: "owner-cookie",
+		PasswordStore: store,
+		Sessions:      env.sessions,
+	})
+	return env
+}
+
+func (e *accountTestEnv) do(method, path, cookie, body string) *httptest.ResponseRecorder {
+	e.t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "127.0.0.1:1234"
+	if cookie != "" {
+		req.AddCookie(&http.Cookie{Name: middleware.LauncherDashboardCookieName, Value: cookie})
+	}
+	rec := httptest.NewRecorder()
+	e.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func (e *accountTestEnv) login(username, password, code string) *httptest.ResponseRecorder {
+	e.t.Helper()
+	body, _ := json.Marshal(launcherAuthLoginBody{Username: username, Password: password, TOTPCode: code})
+	return e.do(http.MethodPost, "/api/auth/login", "", string(body))
+}
+
+func sessionCookieFrom(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == middleware.LauncherDashboardCookieName {
+			return c.Value
+		}
+	}
+	t.Fatalf("no session cookie in response: %s", rec.Body.String())
+	return ""
+}
+
+func TestLauncherAuthAccounts_SetupLoginAndRoles(t *testing.T) {
+	env := newAccountTestEnv(t)
+
+	rec := env.do(http.MethodPost, "/api/auth/setup", "",
+		`{"username":"root","password":"root-password","confirm":"root-password"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("setup: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = env.login("root", "root-password", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("root login: %d %s", rec.Code, rec.Body.String())
+	}
+	rootCookie := sessionCookieFrom(t, rec)
+
+	rec = env.do(http.MethodGet, "/api/auth/status", rootCookie, "")
+	var status launcherAuthStatusResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &status)
+	if !status.Authenticated || !status.MultiUser || status.Username != "root" || status.Role != "admin" {
+		t.Fatalf("status = %+v", status)
+	}
+
+	rec = env.do(http.MethodPost, "/api/auth/users", rootCookie,
+		`{"username":"val","password":"viewer-password","role":"viewer"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create user: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = env.do(http.MethodPost, "/api/auth/users", rootCookie,
+		`{"username":"val","password":"viewer-password","role":"viewer"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("duplicate user: %d, want 409", rec.Code)
+	}
+
+	rec = env.login("val", "wrong-password", "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bad login: %d, want 401", rec.Code)
+	}
+	valCookie := sessionCookieFrom(t, env.login("val", "viewer-password", ""))
+
+	rec = env.do(http.MethodGet, "/api/auth/users", valCookie, "")
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("viewer listing users: %d, want 403", rec.Code)
+	}
+
+	// Changing val's role revokes their sessions.
+	rec = env.do(http.MethodPut, "/api/auth/users/val", rootCookie, `{"role":"operator"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update role: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec = env.do(http.MethodPost, "/api/auth/totp/enroll", valCookie, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("revoked session: %d, want 401", rec.Code)
+	}
+
+	rec = env.do(http.MethodDelete, "/api/auth/users/root", rootCookie, "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("delete last admin: %d, want 409", rec.Code)
+	}
+
+	// Audit is written by middleware; the endpoint reads it back.
+	if err := env.store.RecordAudit(context.Background(), dashboardauth.AuditEntry{
+		Username: "root", Via: "session", Method: "PUT", Path: "/api/config", Status: 200,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rec = env.do(http.MethodGet, "/api/auth/audit?user=root", rootCookie, "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"/api/config"`) {
+		t.Fatalf("audit: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLauncherAuthAccounts_TOTPLogin(t *testing.T) {
+	env := newAccountTestEnv(t)
+	if _, err := env.store.CreateUser(context.Background(), "alice", "alice-password", dashboardauth.RoleOperator); err != nil {
+		t.Fatal(err)
+	}
+	cookie := sessionCookieFrom(t, env.login("alice", "alice-password", ""))
+
+	rec := env.do(http.MethodPost, "/api/auth/totp/enroll", cookie, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enroll: %d %s", rec.Code, rec.Body.String())
+	}
+	var enroll accountTOTPEnrollResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &enroll)
+	if enroll.Secret == "" || !strings.HasPrefix(enroll.URI, "otpauth://totp/") {
+		t.Fatalf("enroll = %+v", enroll)
+	}
+	code, _ := dashboardauth.TOTPCode(enroll.Secret, time.Now())
+	rec = env.do(http.MethodPost, "/api/auth/totp/confirm", cookie, `{"code":"`+code+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("confirm: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = env.login("alice", "alice-password", "")
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), `"totp_required":true`) {
+		t.Fatalf("login without code: %d %s", rec.Code, rec.Body.String())
+	}
+	code, _ = dashboardauth.TOTPCode(enroll.Secret, time.Now())
+	if rec = env.login("alice", "alice-password", code); rec.Code != http.StatusOK {
+		t.Fatalf("login with code: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLauncherAuthAccounts_APITokens(t *testing.T) {
+	env := newAccountTestEnv(t)
+	ctx := context.Background()
+	if _, err := env.store.CreateUser(ctx, "ops", "ops-password", dashboardauth.RoleOperator); err != nil {
+		t.Fatal(err)
+	}
+	cookie := sessionCookieFrom(t, env.login("ops", "ops-password", ""))
+
+	rec := env.do(http.MethodPost, "/api/auth/tokens", cookie, `{"name":"ci","role":"admin"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("token above own role: %d, want 400", rec.Code)
+	}
+	rec = env.do(http.MethodPost, "/api/auth/tokens", cookie,
+		`{"name":"ci","scopes":["gateway"],"expires_in_days":30}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create token: %d %s", rec.Code, rec.Body.String())
+	}
+	var created accountTokenCreateResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	if !strings.HasPrefix(created.Token, dashboardauth.APITokenPrefix) || created.APIToken.ExpiresAt == nil {
+		t.Fatalf("created = %+v", created)
+	}
+	if _, err := env.store.AuthenticateAPIToken(ctx, created.Token); err != nil {
+		t.Fatalf("AuthenticateAPIToken() error = %v", err)
+	}
+
+	rec = env.do(http.MethodGet, "/api/auth/tokens", cookie, "")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), created.Token) {
+		t.Fatalf("list tokens must not reveal plaintext: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = env.do(http.MethodDelete, "/api/auth/tokens/"+strconv.FormatInt(created.APIToken.ID, 10), cookie, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("revoke: %d %s", rec.Code, rec.Body.String())
+	}
+	if _, err := env.store.AuthenticateAPIToken(ctx, created.Token); err == nil {
+		t.Fatal("revoked token should not authenticate")
+	}
+}
+
+func TestLauncherAuthAccounts_LegacyPasswordLogin(t *testing.T) {
+	env := newAccountTestEnv(t)
+	if err := env.store.SetPassword(context.Background(), "legacy-password"); err != nil {
+		t.Fatal(err)
+	}
+	// Old clients send only a password; it maps to the default admin.
+	rec := env.do(http.MethodPost, "/api/auth/login", "", `{"password":"legacy-password"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("legacy login: %d %s", rec.Code, rec.Body.String())
+	}
+	p, ok := env.sessions.Lookup(sessionCookieFrom(t, rec))
+	if !ok || p.Username != dashboardauth.DefaultAdminUsername || p.Role != dashboardauth.RoleAdmin {
+		t.Fatalf("principal = %+v ok=%v", p, ok)
+	}
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
+
+type cronJobsResponse struct {
+	Jobs []cron.CronJob `json:"jobs"`
+}
+
+type cronHistoryResponse struct {
+	JobID string         `json:"job_id"`
+	Runs  []cron.CronRun `json:"runs"`
+}
+
+// cronJobRequest is the body for creating or replacing a job. Jobs created
+// from the dashboard are always agent turns; shell command payloads stay
+// CLI-only.
+type cronJobRequest struct {
+	Name     string             `json:"name"`
+	Schedule cron.CronSchedule  `json:"schedule"`
+	Message  string             `json:"message"`
+	Channel  string             `json:"channel"`
+	To       string             `json:"to"`
+	Policy   cron.CronRunPolicy `json:"policy"`
+}
+
+func (req *cronJobRequest) validate() error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		return fmt.Errorf("message is required")
+	}
+	if err := cron.ValidateSchedule(req.Schedule); err != nil {
+		return err
+	}
+	return cron.ValidatePolicy(req.Policy)
+}
+
+type cronJobStateRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// registerCronRoutes binds scheduled job endpoints to the ServeMux.
+// The launcher edits the shared workspace store directly; a running gateway
+// picks the changes up on its next store poll.
+func (h *Handler) registerCronRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/cron/jobs", h.handleListCronJobs)
+	mux.HandleFunc("POST /api/cron/jobs", h.handleCreateCronJob)
+	mux.HandleFunc("GET /api/cron/jobs/{id}", h.handleGetCronJob)
+	mux.HandleFunc("PUT /api/cron/jobs/{id}", h.handleUpdateCronJob)
+	mux.HandleFunc("GET /api/cron/jobs/{id}/history", h.handleGetCronHistory)
+	mux.HandleFunc("POST /api/cron/jobs/{id}/run", h.handleRunCronJob)
+	mux.HandleFunc("PUT /api/cron/jobs/{id}/state", h.handleUpdateCronJobState)
+	mux.HandleFunc("DELETE /api/cron/jobs/{id}", h.handleDeleteCronJob)
+}
+
+func (h *Handler) cronService() (*cron.CronService, error) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	cs := cron.NewCronService(filepath.Join(cfg.WorkspacePath(), "cron", "jobs.json"), nil)
+	cs.SetHistoryLimit(cfg.Tools.Cron.HistoryLimit)
+	return cs, nil
+}
+
+func (h *Handler) handleListCronJobs(w http.ResponseWriter, r *http.Request) {
+	cs, err := h.cronService()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jobs := cs.ListJobs(true)
+	if jobs == nil {
+		jobs = []cron.CronJob{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cronJobsResponse{Jobs: jobs})
+}
+
+func (h *Handler) handleGetCronJob(w http.ResponseWriter, r *http.Request) {
+	cs, err := h.cronService()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	job := cs.GetJob(r.PathValue("id"))
+	if job == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+func (h *Handler) handleCreateCronJob(w http.ResponseWriter, r *http.Request) {
+	var req cronJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cs, err := h.cronService()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	job, err := cs.AddJob(req.Name, req.Schedule, req.Message, req.Channel, req.To)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to add job: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if req.Policy != (cron.CronRunPolicy{}) {
+		job.Policy = req.Policy
+		if err := cs.UpdateJob(job); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to save policy: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(job)
+}
+
+func (h *Handler) handleUpdateCronJob(w http.ResponseWriter, r *http.Request) {
+	var req cronJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cs, err := h.cronService()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id := r.PathValue("id")
+	if cs.GetJob(id) == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	job, err := cs.EditJob(id, func(job *cron.CronJob) {
+		job.Name = req.Name
+		job.Schedule = req.Schedule
+		job.Payload.Message = req.Message
+		job.Payload.Channel = req.Channel
+		job.Payload.To = req.To
+		job.Policy = req.Policy
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update job: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+func (h *Handler) handleGetCronHistory(w http.ResponseWriter, r *http.Request) {
+	cs, err := h.cronService()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	jobID := r.PathValue("id")
+	runs, err := cs.History(jobID, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read history: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if runs == nil {
+		if cs.GetJob(jobID) == nil {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		runs = []cron.CronRun{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cronHistoryResponse{JobID: jobID, Runs: runs})
+}
+
+func (h *Handler) handleRunCronJob(w http.ResponseWriter, r *http.Request) {
+	cs, err := h.cronService()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if cs.RequestRun(r.PathValue("id")) == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "queued"})
+}
+
+func (h *Handler) handleUpdateCronJobState(w http.ResponseWriter, r *http.Request) {
+	cs, err := h.cronService()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var req cronJobStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	job := cs.EnableJob(r.PathValue("id"), req.Enabled)
+	if job == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+func (h *Handler) handleDeleteCronJob(w http.ResponseWriter, r *http.Request) {
+	cs, err := h.cronService()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !cs.RemoveJob(r.PathValue("id")) {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
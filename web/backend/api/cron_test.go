@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
+
+func TestCronJobRoutes(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	every := int64(60000)
+	cs := cron.NewCronService(filepath.Join(cfg.WorkspacePath(), "cron", "jobs.json"), nil)
+	job, err := cs.AddJob("ping", cron.CronSchedule{Kind: "every", EveryMS: &every}, "hello", "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob() error = %v", err)
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/cron/jobs", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var list cronJobsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(list.Jobs) != 1 || list.Jobs[0].ID != job.ID {
+		t.Fatalf("jobs = %#v, want the added job", list.Jobs)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/cron/jobs/"+job.ID+"/run", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("run status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if err := cs.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cs.GetJob(job.ID); got == nil || got.State.RunRequestedAtMS == nil {
+		t.Fatalf("run request was not persisted: %#v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/cron/jobs/"+job.ID+"/history", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("history status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var history cronHistoryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if history.Runs == nil || len(history.Runs) != 0 {
+		t.Fatalf("runs = %#v, want empty list", history.Runs)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(
+		http.MethodPut,
+		"/api/cron/jobs/"+job.ID+"/state",
+		bytes.NewBufferString(`{"enabled":false}`),
+	))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("state status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/cron/jobs/"+job.ID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/cron/jobs/"+job.ID+"/history", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("history after delete status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestCronJobCreateAndUpdate(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(
+		http.MethodPost,
+		"/api/cron/jobs",
+		bytes.NewBufferString(`{"name":"bad","message":"hi","schedule":{"kind":"cron","expr":"often"}}`),
+	))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid schedule status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(
+		http.MethodPost,
+		"/api/cron/jobs",
+		bytes.NewBufferString(`{
+			"name": "digest",
+			"message": "summarize the news",
+			"schedule": {"kind": "cron", "expr": "0 9 * * *"},
+			"policy": {"maxRetries": 2, "concurrency": "skip"}
+		}`),
+	))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var created cron.CronJob
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if created.ID == "" || created.Policy.MaxRetries != 2 || created.State.NextRunAtMS == nil {
+		t.Fatalf("created job = %#v", created)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(
+		http.MethodPut,
+		"/api/cron/jobs/"+created.ID,
+		bytes.NewBufferString(`{"name":"digest","message":"summarize","schedule":{"kind":"every","everyMs":3600000}}`),
+	))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var updated cron.CronJob
+	if err := json.Unmarshal(rec.Body.Bytes(), &updated); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if updated.Schedule.Kind != "every" || updated.Payload.Message != "summarize" ||
+		updated.Policy != (cron.CronRunPolicy{}) {
+		t.Fatalf("updated job = %#v", updated)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(
+		http.MethodPut,
+		"/api/cron/jobs/missing",
+		bytes.NewBufferString(`{"name":"x","message":"y","schedule":{"kind":"every","everyMs":1000}}`),
+	))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("update missing status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
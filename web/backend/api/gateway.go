@@ -116,6 +116,12 @@ var gatewayProcessMatcher = isLikelyGatewayProcess
 // getGatewayHealth checks the gateway health endpoint and returns the status response.
 // Returns (*health.StatusResponse, statusCode, error). If error is not nil, the other values are not valid.
 func (h *Handler) getGatewayHealth(cfg *config.Config, timeout time.Duration) (*health.StatusResponse, int, error) {
+	return getGatewayHealthByURL(h.gatewayProbeBaseURL(cfg)+"/health", timeout)
+}
+
+// gatewayProbeBaseURL returns the http://host:port base of the gateway's
+// health endpoints.
+func (h *Handler) gatewayProbeBaseURL(cfg *config.Config) string {
 	// Prefer port/host from pidData when available.
 	var port int
 	var host string
@@ -135,9 +141,7 @@ func (h *Handler) getGatewayHealth(cfg *config.Config, timeout time.Duration) (*
 		host = gatewayProbeHost(h.effectiveGatewayBindHost(cfg))
 	}
 
-	url := "http://" + net.JoinHostPort(host, strconv.Itoa(port)) + "/health"
-
-	return getGatewayHealthByURL(url, timeout)
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port))
 }
 
 func getGatewayHealthByURL(url string, timeout time.Duration) (*health.StatusResponse, int, error) {
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/mcp"
+)
+
+// gatewayRuntimePath mirrors agent.RuntimeAdminPath; the launcher does not
+// link the agent package.
+const gatewayRuntimePath = "/runtime/"
+
+const gatewayRuntimeTimeout = 5 * time.Second
+
+var errGatewayUnavailable = errors.New("gateway not available")
+
+// gatewayRuntimeStatus mirrors agent.RuntimeStatus.
+type gatewayRuntimeStatus struct {
+	MCP   gatewayMCPStatus   `json:"mcp"`
+	Hooks gatewayHooksStatus `json:"hooks"`
+}
+
+type gatewayMCPStatus struct {
+	Running   bool               `json:"running"`
+	InitError string             `json:"init_error,omitempty"`
+	Servers   []mcp.ServerStatus `json:"servers"`
+}
+
+type gatewayHooksStatus struct {
+	Mounted   []string `json:"mounted"`
+	Builtins  []string `json:"builtins"`
+	InitError string   `json:"init_error,omitempty"`
+}
+
+// gatewayToken returns the bearer token of the running gateway, or "" when
+// no gateway is available.
+func (h *Handler) gatewayToken() string {
+	if !h.gatewayAvailableForProxy() {
+		return ""
+	}
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	if gateway.pidData == nil {
+		return ""
+	}
+	return gateway.pidData.Token
+}
+
+// gatewayRuntimeCall sends an authenticated request to the gateway's runtime
+// admin endpoints, encoding body as JSON when it is not nil, and decodes the
+// JSON response into out. Non-2xx responses are returned as errors carrying
+// the gateway's message.
+func (h *Handler) gatewayRuntimeCall(ctx context.Context, method, path string, body, out any) error {
+	token := h.gatewayToken()
+	if token == "" {
+		return errGatewayUnavailable
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	target := h.gatewayProxyURL().JoinPath(gatewayRuntimePath, path)
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errGatewayUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("gateway returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// gatewayRuntimeStatus fetches live MCP and hook state. It returns nil when
+// the gateway is not running or does not answer.
+func (h *Handler) gatewayRuntimeStatus(ctx context.Context) *gatewayRuntimeStatus {
+	ctx, cancel := context.WithTimeout(ctx, gatewayRuntimeTimeout)
+	defer cancel()
+
+	var status gatewayRuntimeStatus
+	if err := h.gatewayRuntimeCall(ctx, http.MethodGet, "status", nil, &status); err != nil {
+		return nil
+	}
+	return &status
+}
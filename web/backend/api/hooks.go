@@ -0,0 +1,245 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// processHookIntercepts mirrors the intercept names the agent accepts for
+// process hooks.
+var processHookIntercepts = []string{"before_llm", "after_llm", "before_tool", "after_tool", "approve_tool"}
+
+type builtinHookItem struct {
+	Name string `json:"name"`
+	config.BuiltinHookConfig
+	Mounted bool `json:"mounted"`
+}
+
+type processHookItem struct {
+	Name string `json:"name"`
+	config.ProcessHookConfig
+	Mounted bool `json:"mounted"`
+}
+
+type hooksResponse struct {
+	Enabled        bool                      `json:"enabled"`
+	Defaults       config.HookDefaultsConfig `json:"defaults"`
+	GatewayRunning bool                      `json:"gateway_running"`
+	InitError      string                    `json:"init_error,omitempty"`
+	// AvailableBuiltins lists the builtin hooks the running gateway can mount.
+	AvailableBuiltins []string          `json:"available_builtins"`
+	Builtins          []builtinHookItem `json:"builtins"`
+	Processes         []processHookItem `json:"processes"`
+}
+
+type hooksSettingsRequest struct {
+	Enabled  bool                      `json:"enabled"`
+	Defaults config.HookDefaultsConfig `json:"defaults"`
+}
+
+// registerHookRoutes binds hook configuration endpoints to the ServeMux.
+// Changes are written to config and mounted when the gateway reloads.
+func (h *Handler) registerHookRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/hooks", h.handleListHooks)
+	mux.HandleFunc("PUT /api/hooks", h.handleUpdateHookSettings)
+	mux.HandleFunc("PUT /api/hooks/builtins/{name}", h.handleSaveBuiltinHook)
+	mux.HandleFunc("DELETE /api/hooks/builtins/{name}", h.handleDeleteBuiltinHook)
+	mux.HandleFunc("PUT /api/hooks/processes/{name}", h.handleSaveProcessHook)
+	mux.HandleFunc("DELETE /api/hooks/processes/{name}", h.handleDeleteProcessHook)
+}
+
+func (h *Handler) handleListHooks(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var live *gatewayHooksStatus
+	if status := h.gatewayRuntimeStatus(r.Context()); status != nil {
+		live = &status.Hooks
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildHooksResponse(cfg, live))
+}
+
+func buildHooksResponse(cfg *config.Config, live *gatewayHooksStatus) hooksResponse {
+	resp := hooksResponse{
+		Enabled:           cfg.Hooks.Enabled,
+		Defaults:          cfg.Hooks.Defaults,
+		GatewayRunning:    live != nil,
+		AvailableBuiltins: []string{},
+		Builtins:          make([]builtinHookItem, 0, len(cfg.Hooks.Builtins)),
+		Processes:         make([]processHookItem, 0, len(cfg.Hooks.Processes)),
+	}
+	var mounted []string
+	if live != nil {
+		resp.InitError = live.InitError
+		mounted = live.Mounted
+		if live.Builtins != nil {
+			resp.AvailableBuiltins = live.Builtins
+		}
+	}
+
+	for name, spec := range cfg.Hooks.Builtins {
+		resp.Builtins = append(resp.Builtins, builtinHookItem{
+			Name:              name,
+			BuiltinHookConfig: spec,
+			Mounted:           slices.Contains(mounted, name),
+		})
+	}
+	for name, spec := range cfg.Hooks.Processes {
+		resp.Processes = append(resp.Processes, processHookItem{
+			Name:              name,
+			ProcessHookConfig: spec,
+			Mounted:           slices.Contains(mounted, name),
+		})
+	}
+	sort.Slice(resp.Builtins, func(i, j int) bool { return resp.Builtins[i].Name < resp.Builtins[j].Name })
+	sort.Slice(resp.Processes, func(i, j int) bool { return resp.Processes[i].Name < resp.Processes[j].Name })
+	return resp
+}
+
+func (h *Handler) handleUpdateHookSettings(w http.ResponseWriter, r *http.Request) {
+	var req hooksSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	d := req.Defaults
+	if d.ObserverTimeoutMS < 0 || d.InterceptorTimeoutMS < 0 || d.ApprovalTimeoutMS < 0 {
+		http.Error(w, "timeouts must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	h.updateHooksConfig(w, func(hooks *config.HooksConfig) error {
+		hooks.Enabled = req.Enabled
+		hooks.Defaults = req.Defaults
+		return nil
+	})
+}
+
+func (h *Handler) handleSaveBuiltinHook(w http.ResponseWriter, r *http.Request) {
+	var spec config.BuiltinHookConfig
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(r.PathValue("name"))
+	if name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	if len(spec.Config) > 0 && !json.Valid(spec.Config) {
+		http.Error(w, "config must be valid JSON", http.StatusBadRequest)
+		return
+	}
+
+	h.updateHooksConfig(w, func(hooks *config.HooksConfig) error {
+		if hooks.Builtins == nil {
+			hooks.Builtins = make(map[string]config.BuiltinHookConfig)
+		}
+		hooks.Builtins[name] = spec
+		return nil
+	})
+}
+
+func (h *Handler) handleDeleteBuiltinHook(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	h.updateHooksConfig(w, func(hooks *config.HooksConfig) error {
+		if _, ok := hooks.Builtins[name]; !ok {
+			return errHookNotFound
+		}
+		delete(hooks.Builtins, name)
+		return nil
+	})
+}
+
+func (h *Handler) handleSaveProcessHook(w http.ResponseWriter, r *http.Request) {
+	var spec config.ProcessHookConfig
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(r.PathValue("name"))
+	if name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	if err := validateProcessHook(spec); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.updateHooksConfig(w, func(hooks *config.HooksConfig) error {
+		if hooks.Processes == nil {
+			hooks.Processes = make(map[string]config.ProcessHookConfig)
+		}
+		hooks.Processes[name] = spec
+		return nil
+	})
+}
+
+func (h *Handler) handleDeleteProcessHook(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	h.updateHooksConfig(w, func(hooks *config.HooksConfig) error {
+		if _, ok := hooks.Processes[name]; !ok {
+			return errHookNotFound
+		}
+		delete(hooks.Processes, name)
+		return nil
+	})
+}
+
+// validateProcessHook checks the fields the launcher can verify without the
+// agent package. Observed event kinds are checked when the gateway mounts
+// the hook and reported as its init error.
+func validateProcessHook(spec config.ProcessHookConfig) error {
+	if spec.Transport != "" && spec.Transport != "stdio" {
+		return fmt.Errorf("unsupported transport %q", spec.Transport)
+	}
+	if len(spec.Command) == 0 || strings.TrimSpace(spec.Command[0]) == "" {
+		return fmt.Errorf("command is required")
+	}
+	for _, intercept := range spec.Intercept {
+		if intercept != "" && !slices.Contains(processHookIntercepts, intercept) {
+			return fmt.Errorf("unsupported intercept %q", intercept)
+		}
+	}
+	return nil
+}
+
+var errHookNotFound = errors.New("hook not found")
+
+// updateHooksConfig loads the config, applies edit to its hooks section,
+// and saves it.
+func (h *Handler) updateHooksConfig(w http.ResponseWriter, edit func(hooks *config.HooksConfig) error) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := edit(&cfg.Hooks); err != nil {
+		if errors.Is(err, errHookNotFound) {
+			http.Error(w, "Hook not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := config.SaveConfig(h.configPath, cfg); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestHookRoutes(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+	origPidData := gateway.pidData
+	gateway.pidData = nil
+	t.Cleanup(func() { gateway.pidData = origPidData })
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return rec
+	}
+
+	if rec := do(http.MethodPut, "/api/hooks", `{"enabled":true,"defaults":{"observer_timeout_ms":500}}`); rec.Code != http.StatusOK {
+		t.Fatalf("settings status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	rec := do(http.MethodPut, "/api/hooks/processes/audit", `{
+		"enabled": true,
+		"priority": 10,
+		"command": ["python3", "audit.py"],
+		"observe": ["tool_exec_end"],
+		"intercept": ["approve_tool"]
+	}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("save process status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodPut, "/api/hooks/processes/bad", `{"enabled":true,"command":["x"],"intercept":["rewrite"]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid intercept status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec = do(http.MethodPut, "/api/hooks/builtins/redact", `{"enabled":false,"config":{"patterns":["sk-"]}}`); rec.Code != http.StatusOK {
+		t.Fatalf("save builtin status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if !cfg.Hooks.Enabled || cfg.Hooks.Defaults.ObserverTimeoutMS != 500 {
+		t.Fatalf("hook settings = %#v", cfg.Hooks)
+	}
+	if p := cfg.Hooks.Processes["audit"]; p.Priority != 10 || len(p.Command) != 2 {
+		t.Fatalf("process hook = %#v", p)
+	}
+
+	rec = do(http.MethodGet, "/api/hooks", "")
+	var list hooksResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(list.Processes) != 1 || list.Processes[0].Name != "audit" || list.Processes[0].Mounted {
+		t.Fatalf("processes = %#v", list.Processes)
+	}
+	if len(list.Builtins) != 1 || string(list.Builtins[0].Config) != `{"patterns":["sk-"]}` {
+		t.Fatalf("builtins = %#v", list.Builtins)
+	}
+
+	if rec = do(http.MethodDelete, "/api/hooks/builtins/redact", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodDelete, "/api/hooks/builtins/redact", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("second delete status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+type identityLinksResponse struct {
+	Links []session.IdentityLink `json:"links"`
+}
+
+// registerIdentityLinkRoutes binds the cross-channel identity link endpoints.
+// Links are created from chat with /link; the launcher lists them and can
+// remove them. A running gateway sees removals on its next lookup.
+func (h *Handler) registerIdentityLinkRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/identity-links", h.handleListIdentityLinks)
+	mux.HandleFunc("DELETE /api/identity-links/{identity}", h.handleDeleteIdentityLink)
+}
+
+func (h *Handler) linkStore() *session.LinkStore {
+	return session.NewLinkStore(session.LinkStorePath(config.GetHome()))
+}
+
+func (h *Handler) handleListIdentityLinks(w http.ResponseWriter, r *http.Request) {
+	store := h.linkStore()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(identityLinksResponse{Links: store.List()})
+}
+
+func (h *Handler) handleDeleteIdentityLink(w http.ResponseWriter, r *http.Request) {
+	store := h.linkStore()
+
+	removed, err := store.Unlink(r.PathValue("identity"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to remove link: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.Error(w, "Link not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
@@ -0,0 +1,460 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/mcp"
+)
+
+const mcpProbeTimeout = 10 * time.Second
+
+// MCP server status values reported by the list endpoint.
+const (
+	mcpStatusDisabled  = "disabled"  // disabled in config
+	mcpStatusConnected = "connected" // connected in the running gateway
+	mcpStatusFailed    = "failed"    // the gateway failed to connect
+	mcpStatusPending   = "pending"   // not loaded by the gateway yet; restart needed
+	mcpStatusUnknown   = "unknown"   // gateway not running
+)
+
+type mcpServerItem struct {
+	Name      string            `json:"name"`
+	Enabled   bool              `json:"enabled"`
+	Deferred  *bool             `json:"deferred,omitempty"`
+	Type      string            `json:"type"`
+	Command   string            `json:"command,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	EnvFile   string            `json:"env_file,omitempty"`
+	URL       string            `json:"url,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Status    string            `json:"status"`
+	ToolCount int               `json:"tool_count"`
+	Error     string            `json:"error,omitempty"`
+}
+
+type mcpServersResponse struct {
+	Enabled          bool            `json:"enabled"`
+	DiscoveryEnabled bool            `json:"discovery_enabled"`
+	GatewayRunning   bool            `json:"gateway_running"`
+	InitError        string          `json:"init_error,omitempty"`
+	Servers          []mcpServerItem `json:"servers"`
+}
+
+// mcpServerRequest is the body for adding or replacing a server. On update,
+// nil Env and Headers keep the stored values and empty objects clear them.
+type mcpServerRequest struct {
+	Name     string            `json:"name"`
+	Enabled  *bool             `json:"enabled"`
+	Deferred *bool             `json:"deferred"`
+	Type     string            `json:"type"`
+	Command  string            `json:"command"`
+	Args     []string          `json:"args"`
+	Env      map[string]string `json:"env"`
+	EnvFile  string            `json:"env_file"`
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers"`
+}
+
+type mcpServerStateRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type mcpProbeResponse struct {
+	Name      string   `json:"name"`
+	ToolCount int      `json:"tool_count"`
+	Tools     []string `json:"tools"`
+}
+
+// registerMCPRoutes binds MCP server management endpoints to the ServeMux.
+// Config edits take effect when the gateway reloads; restart reconnects one
+// server in the running gateway.
+func (h *Handler) registerMCPRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/mcp/servers", h.handleListMCPServers)
+	mux.HandleFunc("POST /api/mcp/servers", h.handleCreateMCPServer)
+	mux.HandleFunc("PUT /api/mcp/servers/{name}", h.handleUpdateMCPServer)
+	mux.HandleFunc("DELETE /api/mcp/servers/{name}", h.handleDeleteMCPServer)
+	mux.HandleFunc("PUT /api/mcp/servers/{name}/state", h.handleUpdateMCPServerState)
+	mux.HandleFunc("POST /api/mcp/servers/{name}/test", h.handleTestMCPServer)
+	mux.HandleFunc("POST /api/mcp/servers/{name}/restart", h.handleRestartMCPServer)
+}
+
+func (h *Handler) handleListMCPServers(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var live *gatewayMCPStatus
+	if status := h.gatewayRuntimeStatus(r.Context()); status != nil {
+		live = &status.MCP
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildMCPServersResponse(cfg, live))
+}
+
+func buildMCPServersResponse(cfg *config.Config, live *gatewayMCPStatus) mcpServersResponse {
+	resp := mcpServersResponse{
+		Enabled:          cfg.Tools.MCP.Enabled,
+		DiscoveryEnabled: cfg.Tools.MCP.Discovery.Enabled,
+		GatewayRunning:   live != nil,
+		Servers:          make([]mcpServerItem, 0, len(cfg.Tools.MCP.Servers)),
+	}
+	liveByName := make(map[string]mcp.ServerStatus)
+	if live != nil {
+		resp.InitError = live.InitError
+		for _, s := range live.Servers {
+			liveByName[s.Name] = s
+		}
+	}
+
+	for name, server := range cfg.Tools.MCP.Servers {
+		item := mcpServerItem{
+			Name:     name,
+			Enabled:  server.Enabled,
+			Deferred: server.Deferred,
+			Type:     mcpTransportType(server),
+			Command:  server.Command,
+			Args:     server.Args,
+			Env:      maskMCPValues(server.Env),
+			EnvFile:  server.EnvFile,
+			URL:      server.URL,
+			Headers:  maskMCPValues(server.Headers),
+		}
+		s, loaded := liveByName[name]
+		switch {
+		case !server.Enabled || !cfg.Tools.MCP.Enabled:
+			item.Status = mcpStatusDisabled
+		case live == nil:
+			item.Status = mcpStatusUnknown
+		case loaded && s.Connected:
+			item.Status = mcpStatusConnected
+			item.ToolCount = s.ToolCount
+		case loaded:
+			item.Status = mcpStatusFailed
+			item.Error = s.Error
+		default:
+			item.Status = mcpStatusPending
+		}
+		resp.Servers = append(resp.Servers, item)
+	}
+	sort.Slice(resp.Servers, func(i, j int) bool {
+		return resp.Servers[i].Name < resp.Servers[j].Name
+	})
+	return resp
+}
+
+// maskMCPValues masks env and header values, which often hold credentials,
+// for the list response. Keys stay visible so the form can show what is set.
+func maskMCPValues(values map[string]string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	masked := make(map[string]string, len(values))
+	for k, v := range values {
+		masked[k] = maskAPIKey(v)
+	}
+	return masked
+}
+
+// unmaskMCPValues restores the stored value of every entry the client sent
+// back still masked, so saving the form does not overwrite secrets.
+func unmaskMCPValues(values, stored map[string]string) {
+	for k, v := range values {
+		if old, ok := stored[k]; ok && v != old && v == maskAPIKey(old) {
+			values[k] = old
+		}
+	}
+}
+
+func mcpTransportType(server config.MCPServerConfig) string {
+	switch server.Type {
+	case "stdio", "http", "sse":
+		return server.Type
+	}
+	if server.URL != "" {
+		return "sse"
+	}
+	return "stdio"
+}
+
+// toConfig validates the request and builds the server config. existing is
+// the stored config on update, nil on create.
+func (req *mcpServerRequest) toConfig(existing *config.MCPServerConfig) (config.MCPServerConfig, error) {
+	server := config.MCPServerConfig{
+		Enabled:  true,
+		Deferred: req.Deferred,
+		Type:     strings.ToLower(strings.TrimSpace(req.Type)),
+		Command:  strings.TrimSpace(req.Command),
+		Args:     req.Args,
+		Env:      req.Env,
+		EnvFile:  strings.TrimSpace(req.EnvFile),
+		URL:      strings.TrimSpace(req.URL),
+		Headers:  req.Headers,
+	}
+	if existing != nil {
+		server.Enabled = existing.Enabled
+		if server.Env == nil {
+			server.Env = existing.Env
+		} else {
+			unmaskMCPValues(server.Env, existing.Env)
+		}
+		if server.Headers == nil {
+			server.Headers = existing.Headers
+		} else {
+			unmaskMCPValues(server.Headers, existing.Headers)
+		}
+	}
+	if req.Enabled != nil {
+		server.Enabled = *req.Enabled
+	}
+	if len(server.Env) == 0 {
+		server.Env = nil
+	}
+	if len(server.Headers) == 0 {
+		server.Headers = nil
+	}
+
+	if server.Type == "" {
+		server.Type = mcpTransportType(server)
+	}
+	switch server.Type {
+	case "stdio":
+		if server.Command == "" {
+			return server, fmt.Errorf("command is required for stdio transport")
+		}
+		if server.URL != "" || server.Headers != nil {
+			return server, fmt.Errorf("url and headers can only be used with http or sse transport")
+		}
+	case "http", "sse":
+		parsed, err := url.ParseRequestURI(server.URL)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return server, fmt.Errorf("invalid MCP URL %q", server.URL)
+		}
+		if server.Command != "" || len(server.Args) > 0 || server.Env != nil || server.EnvFile != "" {
+			return server, fmt.Errorf("command, args and env can only be used with stdio transport")
+		}
+	default:
+		return server, fmt.Errorf("unsupported transport %q (want stdio, http or sse)", req.Type)
+	}
+	return server, nil
+}
+
+func validMCPServerName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "/ \t\r\n")
+}
+
+func (h *Handler) handleCreateMCPServer(w http.ResponseWriter, r *http.Request) {
+	var req mcpServerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if !validMCPServerName(name) {
+		http.Error(w, "name is required and must not contain spaces or slashes", http.StatusBadRequest)
+		return
+	}
+	server, err := req.toConfig(nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if _, exists := cfg.Tools.MCP.Servers[name]; exists {
+		http.Error(w, fmt.Sprintf("MCP server %q already exists", name), http.StatusConflict)
+		return
+	}
+	if cfg.Tools.MCP.Servers == nil {
+		cfg.Tools.MCP.Servers = make(map[string]config.MCPServerConfig)
+	}
+	cfg.Tools.MCP.Enabled = true
+	cfg.Tools.MCP.Servers[name] = server
+
+	if err := config.SaveConfig(h.configPath, cfg); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (h *Handler) handleUpdateMCPServer(w http.ResponseWriter, r *http.Request) {
+	var req mcpServerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	name := r.PathValue("name")
+	existing, ok := cfg.Tools.MCP.Servers[name]
+	if !ok {
+		http.Error(w, "MCP server not found", http.StatusNotFound)
+		return
+	}
+	server, err := req.toConfig(&existing)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cfg.Tools.MCP.Servers[name] = server
+
+	if err := config.SaveConfig(h.configPath, cfg); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (h *Handler) handleDeleteMCPServer(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	name := r.PathValue("name")
+	if _, ok := cfg.Tools.MCP.Servers[name]; !ok {
+		http.Error(w, "MCP server not found", http.StatusNotFound)
+		return
+	}
+	delete(cfg.Tools.MCP.Servers, name)
+
+	if err := config.SaveConfig(h.configPath, cfg); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (h *Handler) handleUpdateMCPServerState(w http.ResponseWriter, r *http.Request) {
+	var req mcpServerStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	name := r.PathValue("name")
+	server, ok := cfg.Tools.MCP.Servers[name]
+	if !ok {
+		http.Error(w, "MCP server not found", http.StatusNotFound)
+		return
+	}
+	server.Enabled = req.Enabled
+	cfg.Tools.MCP.Servers[name] = server
+	if req.Enabled {
+		cfg.Tools.MCP.Enabled = true
+	}
+
+	if err := config.SaveConfig(h.configPath, cfg); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleTestMCPServer connects to the saved server config from the launcher
+// process, lists its tools and disconnects. The gateway is not involved.
+//
+//	POST /api/mcp/servers/{name}/test
+func (h *Handler) handleTestMCPServer(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	name := r.PathValue("name")
+	server, ok := cfg.Tools.MCP.Servers[name]
+	if !ok {
+		http.Error(w, "MCP server not found", http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), mcpProbeTimeout)
+	defer cancel()
+
+	mgr := mcp.NewManager()
+	defer func() { _ = mgr.Close() }()
+	server.Enabled = true
+	mcpCfg := config.MCPConfig{
+		ToolConfig: config.ToolConfig{Enabled: true},
+		Servers:    map[string]config.MCPServerConfig{name: server},
+	}
+	if err := mgr.LoadFromMCPConfig(ctx, mcpCfg, cfg.WorkspacePath()); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to reach MCP server: %v", err), http.StatusBadGateway)
+		return
+	}
+	conn, ok := mgr.GetServer(name)
+	if !ok {
+		http.Error(w, "Failed to reach MCP server", http.StatusBadGateway)
+		return
+	}
+
+	resp := mcpProbeResponse{Name: name, ToolCount: len(conn.Tools), Tools: make([]string, 0, len(conn.Tools))}
+	for _, tool := range conn.Tools {
+		resp.Tools = append(resp.Tools, tool.Name)
+	}
+	sort.Strings(resp.Tools)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleRestartMCPServer asks the running gateway to reconnect one server
+// and re-register its tools, then returns the refreshed server list.
+//
+//	POST /api/mcp/servers/{name}/restart
+func (h *Handler) handleRestartMCPServer(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var live gatewayMCPStatus
+	path := "mcp/" + url.PathEscape(r.PathValue("name")) + "/restart"
+	if err := h.gatewayRuntimeCall(r.Context(), http.MethodPost, path, nil, &live); err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, errGatewayUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, fmt.Sprintf("Failed to restart MCP server: %v", err), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildMCPServersResponse(cfg, &live))
+}
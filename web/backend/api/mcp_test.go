@@ -0,0 +1,261 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/mcp"
+	ppid "github.com/sipeed/picoclaw/pkg/pid"
+)
+
+func TestMCPServerRoutes(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+	origPidData := gateway.pidData
+	gateway.pidData = nil
+	t.Cleanup(func() { gateway.pidData = origPidData })
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/mcp/servers", `{"name":"fs","command":"npx","args":["-y","server-fs"],"env":{"TOKEN":"abc"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodPost, "/api/mcp/servers", `{"name":"fs","command":"npx"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("duplicate create status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec = do(http.MethodPost, "/api/mcp/servers", `{"name":"remote","type":"http"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("create without url status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = do(http.MethodPut, "/api/mcp/servers/fs", `{"command":"uvx","args":["server-fs"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	server := cfg.Tools.MCP.Servers["fs"]
+	if !cfg.Tools.MCP.Enabled || !server.Enabled || server.Command != "uvx" || server.Env["TOKEN"] != "abc" {
+		t.Fatalf("saved server = %#v, want command updated and env kept", server)
+	}
+
+	rec = do(http.MethodGet, "/api/mcp/servers", "")
+	var list mcpServersResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if list.GatewayRunning || len(list.Servers) != 1 || list.Servers[0].Status != mcpStatusUnknown {
+		t.Fatalf("list = %#v, want one server with unknown status", list)
+	}
+
+	if rec = do(http.MethodPut, "/api/mcp/servers/fs/state", `{"enabled":false}`); rec.Code != http.StatusOK {
+		t.Fatalf("state status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodGet, "/api/mcp/servers", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if list.Servers[0].Status != mcpStatusDisabled {
+		t.Fatalf("status after disable = %q, want %q", list.Servers[0].Status, mcpStatusDisabled)
+	}
+
+	if rec = do(http.MethodPost, "/api/mcp/servers/fs/restart", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("restart without gateway status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	rec = do(http.MethodPost, "/api/mcp/servers", `{"name":"broken","command":"`+filepath.Join(t.TempDir(), "missing")+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create broken status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodPost, "/api/mcp/servers/broken/test", ""); rec.Code != http.StatusBadGateway {
+		t.Fatalf("test broken status = %d, want %d", rec.Code, http.StatusBadGateway)
+	}
+
+	if rec = do(http.MethodDelete, "/api/mcp/servers/fs", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodDelete, "/api/mcp/servers/fs", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("second delete status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestMCPServerList_MasksSecrets(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+	origPidData := gateway.pidData
+	gateway.pidData = nil
+	t.Cleanup(func() { gateway.pidData = origPidData })
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/mcp/servers",
+		`{"name":"remote","type":"http","url":"https://mcp.example.com","headers":{"Authorization":"Bearer sk-live-0123456789"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = do(http.MethodGet, "/api/mcp/servers", "")
+	if bytes.Contains(rec.Body.Bytes(), []byte("sk-live-0123456789")) {
+		t.Fatalf("list leaks the header value: %s", rec.Body.String())
+	}
+	var list mcpServersResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	masked := list.Servers[0].Headers["Authorization"]
+	if masked == "" {
+		t.Fatalf("list headers = %#v, want the key with a masked value", list.Servers[0].Headers)
+	}
+
+	// Saving the form as listed keeps the stored secret.
+	body, _ := json.Marshal(map[string]any{
+		"type":    "http",
+		"url":     "https://mcp.example.com",
+		"headers": map[string]string{"Authorization": masked, "X-Team": "core"},
+	})
+	if rec = do(http.MethodPut, "/api/mcp/servers/remote", string(body)); rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	headers := cfg.Tools.MCP.Servers["remote"].Headers
+	if headers["Authorization"] != "Bearer sk-live-0123456789" || headers["X-Team"] != "core" {
+		t.Fatalf("saved headers = %#v, want the secret kept and the new header added", headers)
+	}
+}
+
+func TestMCPServerStatusFromGateway(t *testing.T) {
+	origMatcher := gatewayProcessMatcher
+	gatewayProcessMatcher = func(int) (bool, bool) { return true, true }
+	t.Cleanup(func() { gatewayProcessMatcher = origMatcher })
+
+	t.Setenv("PICOCLAW_HOME", t.TempDir())
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	restarted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mcpStatus := gatewayMCPStatus{Running: true}
+		mcpStatus.Servers = append(mcpStatus.Servers,
+			mcpServerStatus("fs", true, 3, ""),
+			mcpServerStatus("git", false, 0, "command not found"),
+		)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /runtime/status":
+			json.NewEncoder(w).Encode(gatewayRuntimeStatus{MCP: mcpStatus})
+		case "POST /runtime/mcp/git/restart":
+			restarted = true
+			mcpStatus.Servers[1] = mcpServerStatus("git", true, 1, "")
+			json.NewEncoder(w).Encode(mcpStatus)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Gateway.Host = "127.0.0.1"
+	cfg.Gateway.Port = mustGatewayTestPort(t, server.URL)
+	cfg.Tools.MCP.Enabled = true
+	cfg.Tools.MCP.Servers = map[string]config.MCPServerConfig{
+		"fs":  {Enabled: true, Command: "npx"},
+		"git": {Enabled: true, Command: "uvx"},
+		"new": {Enabled: true, Type: "http", URL: "https://example.com/mcp"},
+		"off": {Enabled: false, Command: "npx"},
+	}
+	if err := config.SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+	cmd := startGatewayLikeProcess(t)
+	t.Cleanup(func() {
+		if cmd.Process != nil {
+			_ = cmd.Process.Kill()
+		}
+		_ = cmd.Wait()
+	})
+	writeTestPidFile(t, ppid.PidFileData{
+		PID:   cmd.Process.Pid,
+		Token: "test-token",
+		Host:  cfg.Gateway.Host,
+		Port:  cfg.Gateway.Port,
+	})
+	origPidData := gateway.pidData
+	t.Cleanup(func() {
+		ppid.RemovePidFile(globalConfigDir())
+		gateway.pidData = origPidData
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/mcp/servers", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var list mcpServersResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := map[string]string{
+		"fs":  mcpStatusConnected,
+		"git": mcpStatusFailed,
+		"new": mcpStatusPending,
+		"off": mcpStatusDisabled,
+	}
+	if !list.GatewayRunning || len(list.Servers) != len(want) {
+		t.Fatalf("list = %#v", list)
+	}
+	for _, item := range list.Servers {
+		if item.Status != want[item.Name] {
+			t.Errorf("%s status = %q, want %q", item.Name, item.Status, want[item.Name])
+		}
+	}
+	if list.Servers[0].ToolCount != 3 || list.Servers[1].Error != "command not found" {
+		t.Fatalf("servers = %#v", list.Servers)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/mcp/servers/git/restart", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restart status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !restarted || list.Servers[1].Status != mcpStatusConnected {
+		t.Fatalf("after restart = %#v", list.Servers)
+	}
+}
+
+func mcpServerStatus(name string, connected bool, tools int, errMsg string) mcp.ServerStatus {
+	return mcp.ServerStatus{Name: name, Connected: connected, ToolCount: tools, Error: errMsg}
+}
@@ -174,7 +174,10 @@ func runLocalModelProbe(m *config.ModelConfig) bool {
 	apiBase := modelProbeAPIBase(m)
 	protocol, modelID := splitModel(m)
 	switch protocol {
-	case "ollama":
+	case "ollama", "ollama-native":
+		if m.IsDiscoveryTemplate() {
+			return probeTCPServiceFunc(apiBase)
+		}
 		return probeOllamaModelFunc(apiBase, modelID)
 	case "vllm", "lmstudio":
 		return probeOpenAICompatibleModelFunc(apiBase, modelID, m.APIKey())
@@ -35,14 +35,15 @@ type modelResponse struct {
 	Proxy      string `json:"proxy,omitempty"`
 	AuthMethod string `json:"auth_method,omitempty"`
 	// Advanced fields
-	ConnectMode    string            `json:"connect_mode,omitempty"`
-	Workspace      string            `json:"workspace,omitempty"`
-	RPM            int               `json:"rpm,omitempty"`
-	MaxTokensField string            `json:"max_tokens_field,omitempty"`
-	RequestTimeout int               `json:"request_timeout,omitempty"`
-	ThinkingLevel  string            `json:"thinking_level,omitempty"`
-	ExtraBody      map[string]any    `json:"extra_body,omitempty"`
-	CustomHeaders  map[string]string `json:"custom_headers,omitempty"`
+	ConnectMode    string                    `json:"connect_mode,omitempty"`
+	Workspace      string                    `json:"workspace,omitempty"`
+	RPM            int                       `json:"rpm,omitempty"`
+	MaxTokensField string                    `json:"max_tokens_field,omitempty"`
+	RequestTimeout int                       `json:"request_timeout,omitempty"`
+	ThinkingLevel  string                    `json:"thinking_level,omitempty"`
+	ExtraBody      map[string]any            `json:"extra_body,omitempty"`
+	CustomHeaders  map[string]string         `json:"custom_headers,omitempty"`
+	Ollama         *config.OllamaModelConfig `json:"ollama,omitempty"`
 	// Meta
 	Enabled   bool   `json:"enabled"`
 	Available bool   `json:"available"`
@@ -94,6 +95,7 @@ func (h *Handler) handleListModels(w http.ResponseWriter, r *http.Request) {
 			ThinkingLevel:  m.ThinkingLevel,
 			ExtraBody:      m.ExtraBody,
 			CustomHeaders:  m.CustomHeaders,
+			Ollama:         m.Ollama,
 			Enabled:        m.Enabled,
 			Available:      modelStatuses[i].Available,
 			Status:         modelStatuses[i].Status,
@@ -76,6 +76,7 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 
 	// Session history
 	h.registerSessionRoutes(mux)
+	h.registerSessionBrowserRoutes(mux)
 
 	// OAuth login and credential management
 	h.registerOAuthRoutes(mux)
@@ -90,6 +91,19 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	h.registerSkillRoutes(mux)
 	h.registerToolRoutes(mux)
 
+	// Scheduled jobs and their run history
+	h.registerCronRoutes(mux)
+
+	// MCP servers and agent hooks, with live status from the gateway
+	h.registerMCPRoutes(mux)
+	h.registerHookRoutes(mux)
+
+	// Cross-channel identity links created with /link
+	h.registerIdentityLinkRoutes(mux)
+
+	// Live agent event stream proxied from the gateway
+	h.registerAgentEventRoutes(mux)
+
 	// OS startup / launch-at-login
 	h.registerStartupRoutes(mux)
 
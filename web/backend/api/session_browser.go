@@ -0,0 +1,570 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+// registerSessionBrowserRoutes binds the cross-channel session browser. Unlike
+// /api/sessions, which only lists Pico chats, it covers every channel and
+// agent and addresses sessions by their full session key.
+func (h *Handler) registerSessionBrowserRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/sessions/browse", h.handleBrowseSessions)
+	mux.HandleFunc("GET /api/sessions/browse/{key}", h.handleGetBrowsedSession)
+	mux.HandleFunc("POST /api/sessions/browse/{key}/continue", h.handleContinueSession)
+}
+
+const (
+	// maxBrowseSearchMatches caps the snippets returned per session.
+	maxBrowseSearchMatches = 3
+	// maxBrowseSearchResults caps the FTS5 rows fetched per seahorse database.
+	maxBrowseSearchResults = 500
+	browseSnippetRadius    = 60
+
+	searchBackendSeahorse = "seahorse"
+	searchBackendScan     = "scan"
+
+	sessionContinueTimeout = 5 * time.Minute
+)
+
+// browseSessionSource is one sessions directory and the agent that owns it.
+type browseSessionSource struct {
+	AgentID string
+	Dir     string
+}
+
+// browsedSession is a session loaded from disk together with its location.
+type browsedSession struct {
+	Key     string
+	Source  browseSessionSource
+	Scope   *session.SessionScope
+	Session sessionFile
+}
+
+// browseSessionItem is one row returned by GET /api/sessions/browse.
+type browseSessionItem struct {
+	Key          string                `json:"key"`
+	PicoID       string                `json:"pico_id,omitempty"`
+	AgentID      string                `json:"agent_id"`
+	Channel      string                `json:"channel"`
+	Account      string                `json:"account,omitempty"`
+	ChatID       string                `json:"chat_id,omitempty"`
+	Sender       string                `json:"sender,omitempty"`
+	Scope        *session.SessionScope `json:"scope,omitempty"`
+	Title        string                `json:"title"`
+	Preview      string                `json:"preview"`
+	MessageCount int                   `json:"message_count"`
+	Created      string                `json:"created"`
+	Updated      string                `json:"updated"`
+	Continuable  bool                  `json:"continuable"`
+	Matches      []browseSearchMatch   `json:"matches,omitempty"`
+
+	updated time.Time
+}
+
+// browseSearchMatch is one full-text hit inside a session.
+type browseSearchMatch struct {
+	Role    string `json:"role,omitempty"`
+	Snippet string `json:"snippet"`
+	Created string `json:"created,omitempty"`
+}
+
+type browseSessionsResponse struct {
+	Sessions      []browseSessionItem `json:"sessions"`
+	Total         int                 `json:"total"`
+	SearchBackend string              `json:"search_backend,omitempty"`
+	Channels      []string            `json:"channels"`
+	Agents        []string            `json:"agents"`
+}
+
+// browseSessionFilter holds the query parameters of the session browser.
+type browseSessionFilter struct {
+	Query   string
+	Channel string
+	Agent   string
+	Sender  string
+	Since   *time.Time
+	Until   *time.Time
+}
+
+func parseBrowseSessionFilter(r *http.Request) (browseSessionFilter, error) {
+	q := r.URL.Query()
+	filter := browseSessionFilter{
+		Query:   strings.TrimSpace(q.Get("q")),
+		Channel: strings.TrimSpace(q.Get("channel")),
+		Agent:   strings.TrimSpace(q.Get("agent")),
+		Sender:  strings.TrimSpace(q.Get("sender")),
+	}
+	var err error
+	if filter.Since, err = parseBrowseTime(q.Get("since"), false); err != nil {
+		return filter, fmt.Errorf("invalid since: %w", err)
+	}
+	if filter.Until, err = parseBrowseTime(q.Get("until"), true); err != nil {
+		return filter, fmt.Errorf("invalid until: %w", err)
+	}
+	return filter, nil
+}
+
+// parseBrowseTime accepts RFC 3339 timestamps and plain dates. A plain date
+// used as an upper bound covers the whole day.
+func parseBrowseTime(raw string, endOfDay bool) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, raw, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// matches reports whether item passes every filter except the text query.
+func (f browseSessionFilter) matches(item browseSessionItem) bool {
+	if f.Channel != "" && !strings.EqualFold(item.Channel, f.Channel) {
+		return false
+	}
+	if f.Agent != "" && !strings.EqualFold(item.AgentID, f.Agent) {
+		return false
+	}
+	if f.Sender != "" {
+		needle := strings.ToLower(f.Sender)
+		if !strings.Contains(strings.ToLower(item.Sender), needle) &&
+			!strings.Contains(strings.ToLower(item.ChatID), needle) &&
+			!strings.Contains(strings.ToLower(item.Key), needle) {
+			return false
+		}
+	}
+	if f.Since != nil && item.updated.Before(*f.Since) {
+		return false
+	}
+	if f.Until != nil && !item.updated.Before(*f.Until) {
+		return false
+	}
+	return true
+}
+
+// browseSessionSources returns the sessions directory of the default
+// workspace and of every configured agent with a workspace of its own,
+// mirroring agent.resolveAgentWorkspace.
+func browseSessionSources(cfg *config.Config) []browseSessionSource {
+	defaults := cfg.Agents.Defaults
+	sources := []browseSessionSource{{
+		AgentID: routing.DefaultAgentID,
+		Dir:     resolveSessionsDir(defaults.Workspace),
+	}}
+	seen := map[string]struct{}{filepath.Clean(sources[0].Dir): {}}
+
+	for _, agentCfg := range cfg.Agents.List {
+		agentID := routing.NormalizeAgentID(agentCfg.ID)
+		workspace := strings.TrimSpace(agentCfg.Workspace)
+		if workspace == "" {
+			if agentCfg.Default || agentID == routing.DefaultAgentID {
+				sources[0].AgentID = agentID
+				continue
+			}
+			workspace = filepath.Join(defaults.Workspace, "..", "workspace-"+agentID)
+		}
+		dir := resolveSessionsDir(workspace)
+		if _, ok := seen[filepath.Clean(dir)]; ok {
+			continue
+		}
+		seen[filepath.Clean(dir)] = struct{}{}
+		sources = append(sources, browseSessionSource{AgentID: agentID, Dir: dir})
+	}
+	return sources
+}
+
+// loadBrowsedSessions reads every non-empty session in dir: JSONL sessions
+// with metadata, JSONL files whose key can be recovered from the file name,
+// and legacy JSON files.
+func (h *Handler) loadBrowsedSessions(source browseSessionSource) []browsedSession {
+	entries, err := os.ReadDir(source.Dir)
+	if err != nil {
+		return nil
+	}
+
+	sessions := make([]browsedSession, 0)
+	seen := make(map[string]struct{})
+	metaBackedBases := make(map[string]struct{})
+	add := func(sess sessionFile, scope *session.SessionScope) {
+		if sess.Key == "" || isEmptySession(sess) {
+			return
+		}
+		if _, ok := seen[sess.Key]; ok {
+			return
+		}
+		seen[sess.Key] = struct{}{}
+		sessions = append(sessions, browsedSession{Key: sess.Key, Source: source, Scope: scope, Session: sess})
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".meta.json") {
+			continue
+		}
+		meta, err := h.readSessionMeta(filepath.Join(source.Dir, name), "")
+		if err != nil || meta.Key == "" {
+			continue
+		}
+		metaBackedBases[strings.TrimSuffix(name, ".meta.json")] = struct{}{}
+		sess, err := h.readJSONLSession(source.Dir, meta.Key)
+		if err != nil {
+			continue
+		}
+		var scope *session.SessionScope
+		if len(meta.Scope) > 0 {
+			var parsed session.SessionScope
+			if json.Unmarshal(meta.Scope, &parsed) == nil {
+				scope = &parsed
+			}
+		}
+		add(sess, scope)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(name, ".jsonl"):
+			if _, ok := metaBackedBases[strings.TrimSuffix(name, ".jsonl")]; ok {
+				continue
+			}
+			ref, ok := jsonlSessionRefFromFilename(name)
+			if !ok {
+				continue
+			}
+			if sess, err := h.readJSONLSession(source.Dir, ref.Key); err == nil {
+				add(sess, nil)
+			}
+		case filepath.Ext(name) == ".json" && !strings.HasSuffix(name, ".meta.json"):
+			if sess, err := h.readLegacySession(filepath.Join(source.Dir, name)); err == nil {
+				add(sess, nil)
+			}
+		}
+	}
+	return sessions
+}
+
+// findBrowsedSession looks up one session by key across all sources.
+func (h *Handler) findBrowsedSession(cfg *config.Config, key string) (browsedSession, bool) {
+	for _, source := range browseSessionSources(cfg) {
+		for _, sess := range h.loadBrowsedSessions(source) {
+			if sess.Key == key {
+				return sess, true
+			}
+		}
+	}
+	return browsedSession{}, false
+}
+
+// sessionScopeChatID recovers the platform chat ID from a scope chat value
+// such as "group:-100123/42", dropping the chat type and topic suffix.
+func sessionScopeChatID(value string) string {
+	_, chatID, ok := strings.Cut(value, ":")
+	if !ok {
+		return ""
+	}
+	if idx := strings.Index(chatID, "/"); idx >= 0 {
+		chatID = chatID[:idx]
+	}
+	return chatID
+}
+
+func buildBrowseSessionItem(sess browsedSession, toolFeedbackMaxArgsLength int) browseSessionItem {
+	summary := buildSessionListItem(sess.Key, sess.Session, toolFeedbackMaxArgsLength)
+	item := browseSessionItem{
+		Key:          sess.Key,
+		AgentID:      sess.Source.AgentID,
+		Scope:        sess.Scope,
+		Title:        summary.Title,
+		Preview:      summary.Preview,
+		MessageCount: summary.MessageCount,
+		Created:      summary.Created,
+		Updated:      summary.Updated,
+		Continuable:  session.IsExplicitSessionKey(sess.Key),
+		updated:      sess.Session.Updated,
+	}
+
+	if parsed := session.ParseLegacyAgentSessionKey(sess.Key); parsed != nil {
+		item.AgentID = parsed.AgentID
+		item.Channel, _, _ = strings.Cut(parsed.Rest, ":")
+	}
+	if scope := sess.Scope; scope != nil {
+		if scope.AgentID != "" {
+			item.AgentID = scope.AgentID
+		}
+		item.Channel = scope.Channel
+		item.Account = scope.Account
+		item.ChatID = sessionScopeChatID(scope.Values["chat"])
+		item.Sender = scope.Values["sender"]
+	}
+	if id, ok := extractLegacyPicoSessionID(sess.Key); ok {
+		item.PicoID = id
+		item.Channel = "pico"
+	} else if sess.Scope != nil {
+		item.PicoID, _ = extractPicoSessionIDFromScope(*sess.Scope)
+	}
+	return item
+}
+
+// sessionSearchMatches scans the visible transcript for query, which is the
+// fallback when seahorse is not the context manager.
+func sessionSearchMatches(sess sessionFile, query string, toolFeedbackMaxArgsLength int) []browseSearchMatch {
+	needle := strings.ToLower(query)
+	matches := make([]browseSearchMatch, 0)
+	for _, msg := range visibleSessionMessages(sess.Messages, toolFeedbackMaxArgsLength) {
+		lower := strings.ToLower(msg.Content)
+		idx := strings.Index(lower, needle)
+		if idx < 0 {
+			continue
+		}
+		matches = append(matches, browseSearchMatch{
+			Role:    msg.Role,
+			Snippet: searchSnippet(msg.Content, idx, idx+len(needle)),
+		})
+		if len(matches) == maxBrowseSearchMatches {
+			break
+		}
+	}
+	return matches
+}
+
+// searchSnippet returns the text around the match at content[start:end],
+// cut on rune boundaries with whitespace collapsed.
+func searchSnippet(content string, start, end int) string {
+	start, end = min(start, len(content)), min(end, len(content))
+	runes := []rune(content)
+	matchStart := utf8.RuneCountInString(content[:start])
+	matchEnd := matchStart + utf8.RuneCountInString(content[start:end])
+	from := max(matchStart-browseSnippetRadius, 0)
+	to := min(matchEnd+browseSnippetRadius, len(runes))
+
+	snippet := strings.Join(strings.Fields(string(runes[from:to])), " ")
+	if from > 0 {
+		snippet = "..." + snippet
+	}
+	if to < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// handleBrowseSessions lists sessions from every channel and agent, newest
+// first. With q set, only sessions whose history matches are returned, each
+// with up to three snippets. Search uses the seahorse FTS5 index when
+// seahorse is the context manager and scans the JSONL history otherwise.
+//
+//	GET /api/sessions/browse?q=&channel=&agent=&sender=&since=&until=&offset=&limit=
+func (h *Handler) handleBrowseSessions(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseBrowseSessionFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, "failed to load config", http.StatusInternalServerError)
+		return
+	}
+	toolFeedbackMaxArgsLength := cfg.Agents.Defaults.GetToolFeedbackMaxArgsLength()
+	useSeahorse := strings.EqualFold(strings.TrimSpace(cfg.Agents.Defaults.ContextManager), "seahorse")
+
+	resp := browseSessionsResponse{Sessions: []browseSessionItem{}}
+	if filter.Query != "" {
+		resp.SearchBackend = searchBackendScan
+	}
+	channels := make(map[string]struct{})
+	agents := make(map[string]struct{})
+
+	for _, source := range browseSessionSources(cfg) {
+		sessions := h.loadBrowsedSessions(source)
+
+		var indexed map[string][]browseSearchMatch
+		if filter.Query != "" && useSeahorse {
+			indexed, err = searchSeahorseSessions(
+				r.Context(),
+				filepath.Join(source.Dir, "seahorse.db"),
+				filter,
+			)
+			if err == nil {
+				resp.SearchBackend = searchBackendSeahorse
+			}
+		}
+
+		for _, sess := range sessions {
+			item := buildBrowseSessionItem(sess, toolFeedbackMaxArgsLength)
+			channels[item.Channel] = struct{}{}
+			agents[item.AgentID] = struct{}{}
+			if !filter.matches(item) {
+				continue
+			}
+			if filter.Query != "" {
+				if indexed != nil {
+					item.Matches = indexed[item.Key]
+				} else {
+					item.Matches = sessionSearchMatches(sess.Session, filter.Query, toolFeedbackMaxArgsLength)
+				}
+				if len(item.Matches) == 0 {
+					continue
+				}
+			}
+			resp.Sessions = append(resp.Sessions, item)
+		}
+	}
+	resp.Channels = sortedKeys(channels)
+	resp.Agents = sortedKeys(agents)
+
+	sort.SliceStable(resp.Sessions, func(i, j int) bool {
+		return resp.Sessions[i].updated.After(resp.Sessions[j].updated)
+	})
+
+	offset, limit := 0, 20
+	if val, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && val >= 0 {
+		offset = val
+	}
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 {
+		limit = val
+	}
+	resp.Total = len(resp.Sessions)
+	if offset >= resp.Total {
+		resp.Sessions = []browseSessionItem{}
+	} else {
+		resp.Sessions = resp.Sessions[offset:min(offset+limit, resp.Total)]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleGetBrowsedSession returns the scope and full history of one session.
+//
+//	GET /api/sessions/browse/{key}
+func (h *Handler) handleGetBrowsedSession(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, "failed to load config", http.StatusInternalServerError)
+		return
+	}
+	sess, ok := h.findBrowsedSession(cfg, key)
+	if !ok {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	toolFeedbackMaxArgsLength := cfg.Agents.Defaults.GetToolFeedbackMaxArgsLength()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"session":  buildBrowseSessionItem(sess, toolFeedbackMaxArgsLength),
+		"messages": detailSessionMessages(sess.Session.Messages, toolFeedbackMaxArgsLength),
+		"summary":  sess.Session.Summary,
+	})
+}
+
+// gatewayContinueSessionRequest mirrors agent.ContinueSessionRequest.
+type gatewayContinueSessionRequest struct {
+	SessionKey string `json:"session_key"`
+	Channel    string `json:"channel"`
+	ChatID     string `json:"chat_id"`
+	Content    string `json:"content"`
+	Deliver    bool   `json:"deliver,omitempty"`
+}
+
+// handleContinueSession sends a message into an existing channel session
+// through the running gateway and returns the agent reply. With deliver set,
+// the reply is also posted to the original chat.
+//
+//	POST /api/sessions/browse/{key}/continue
+func (h *Handler) handleContinueSession(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Content string `json:"content"`
+		Deliver bool   `json:"deliver"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(body.Content) == "" {
+		http.Error(w, "content is required", http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, "failed to load config", http.StatusInternalServerError)
+		return
+	}
+	sess, ok := h.findBrowsedSession(cfg, r.PathValue("key"))
+	if !ok {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	item := buildBrowseSessionItem(sess, cfg.Agents.Defaults.GetToolFeedbackMaxArgsLength())
+	if !item.Continuable {
+		http.Error(w, "session cannot be continued", http.StatusConflict)
+		return
+	}
+	if body.Deliver && (item.ChatID == "" || item.Channel == session.LinkedChannel) {
+		http.Error(w, "session has no chat to deliver to", http.StatusConflict)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), sessionContinueTimeout)
+	defer cancel()
+
+	var reply struct {
+		Response string `json:"response"`
+	}
+	err = h.gatewayRuntimeCall(ctx, http.MethodPost, "sessions/continue", gatewayContinueSessionRequest{
+		SessionKey: item.Key,
+		Channel:    item.Channel,
+		ChatID:     item.ChatID,
+		Content:    body.Content,
+		Deliver:    body.Deliver,
+	}, &reply)
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, errGatewayUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, fmt.Sprintf("Failed to continue session: %v", err), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reply)
+}
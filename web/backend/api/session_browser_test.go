@@ -0,0 +1,313 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/memory"
+	ppid "github.com/sipeed/picoclaw/pkg/pid"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+func writeBrowseTestSession(
+	t *testing.T,
+	store *memory.JSONLStore,
+	key string,
+	scope *session.SessionScope,
+	messages ...string,
+) {
+	t.Helper()
+
+	for i, content := range messages {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		if err := store.AddFullMessage(nil, key, providers.Message{Role: role, Content: content}); err != nil {
+			t.Fatalf("AddFullMessage(%s) error = %v", key, err)
+		}
+	}
+	if scope == nil {
+		return
+	}
+	data, err := json.Marshal(scope)
+	if err != nil {
+		t.Fatalf("Marshal(scope) error = %v", err)
+	}
+	if err := store.UpsertSessionMeta(nil, key, data, nil); err != nil {
+		t.Fatalf("UpsertSessionMeta(%s) error = %v", key, err)
+	}
+}
+
+func browseTestScope(channel, chat, sender string) *session.SessionScope {
+	return &session.SessionScope{
+		Version:    session.ScopeVersionV1,
+		AgentID:    "main",
+		Channel:    channel,
+		Account:    "default",
+		Dimensions: []string{"chat", "sender"},
+		Values:     map[string]string{"chat": chat, "sender": sender},
+	}
+}
+
+func browseSessions(t *testing.T, mux *http.ServeMux, query string) browseSessionsResponse {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions/browse?"+query, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("browse %q status = %d, body=%s", query, rec.Code, rec.Body.String())
+	}
+	var resp browseSessionsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return resp
+}
+
+func TestHandleBrowseSessions(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	store, err := memory.NewJSONLStore(sessionsTestDir(t, configPath))
+	if err != nil {
+		t.Fatalf("NewJSONLStore() error = %v", err)
+	}
+	writeBrowseTestSession(t, store, "sk_v1_telegram", browseTestScope("telegram", "direct:42", "telegram:42"),
+		"Deploy the staging cluster tonight", "Staging deploy scheduled.")
+	writeBrowseTestSession(t, store, "sk_v1_discord", browseTestScope("discord", "group:998/7", "discord:alice"),
+		"What is the weather in Lisbon?", "Sunny and warm.")
+	writeBrowseTestSession(t, store, "sk_v1_pico", browseTestScope("pico", "direct:pico:chat-1", "pico:chat-1"),
+		"hello from the browser")
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	all := browseSessions(t, mux, "")
+	if all.Total != 3 || len(all.Sessions) != 3 {
+		t.Fatalf("browse all = %+v, want 3 sessions", all)
+	}
+	if got := all.Channels; len(got) != 3 || got[0] != "discord" || got[2] != "telegram" {
+		t.Fatalf("channels = %v", got)
+	}
+	byKey := make(map[string]browseSessionItem)
+	for _, item := range all.Sessions {
+		byKey[item.Key] = item
+	}
+	if item := byKey["sk_v1_discord"]; item.ChatID != "998" || item.Sender != "discord:alice" || !item.Continuable {
+		t.Fatalf("discord item = %+v", item)
+	}
+	if item := byKey["sk_v1_pico"]; item.PicoID != "chat-1" {
+		t.Fatalf("pico item = %+v, want pico_id chat-1", item)
+	}
+
+	if got := browseSessions(t, mux, "channel=TELEGRAM"); got.Total != 1 || got.Sessions[0].Key != "sk_v1_telegram" {
+		t.Fatalf("channel filter = %+v", got)
+	}
+	if got := browseSessions(t, mux, "sender=alice"); got.Total != 1 || got.Sessions[0].Key != "sk_v1_discord" {
+		t.Fatalf("sender filter = %+v", got)
+	}
+	if got := browseSessions(t, mux, "until=2000-01-01"); got.Total != 0 {
+		t.Fatalf("until filter = %+v, want no sessions", got)
+	}
+	if got := browseSessions(t, mux, "limit=2&offset=2"); got.Total != 3 || len(got.Sessions) != 1 {
+		t.Fatalf("pagination = %+v", got)
+	}
+
+	found := browseSessions(t, mux, "q=staging")
+	if found.SearchBackend != searchBackendScan || found.Total != 1 {
+		t.Fatalf("search = %+v, want one scan match", found)
+	}
+	if matches := found.Sessions[0].Matches; len(matches) != 2 || matches[0].Snippet != "Deploy the staging cluster tonight" {
+		t.Fatalf("matches = %+v", matches)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions/browse?since=yesterday", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid since status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions/browse/sk_v1_discord", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("detail status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var detail struct {
+		Session  browseSessionItem    `json:"session"`
+		Messages []sessionChatMessage `json:"messages"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("Unmarshal(detail) error = %v", err)
+	}
+	if detail.Session.Channel != "discord" || len(detail.Messages) != 2 {
+		t.Fatalf("detail = %+v", detail)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions/browse/sk_v1_missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing detail status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleBrowseSessions_AgentWorkspaces(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	researchWorkspace := t.TempDir()
+	cfg.Agents.List = []config.AgentConfig{
+		{ID: "main", Default: true},
+		{ID: "research", Workspace: researchWorkspace},
+	}
+	if err := config.SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	mainStore, err := memory.NewJSONLStore(sessionsTestDir(t, configPath))
+	if err != nil {
+		t.Fatalf("NewJSONLStore(main) error = %v", err)
+	}
+	writeBrowseTestSession(t, mainStore, "agent:main:telegram:direct:42", nil, "main agent question")
+	researchStore, err := memory.NewJSONLStore(resolveSessionsDir(researchWorkspace))
+	if err != nil {
+		t.Fatalf("NewJSONLStore(research) error = %v", err)
+	}
+	writeBrowseTestSession(t, researchStore, "sk_v1_research", nil, "research agent question")
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	resp := browseSessions(t, mux, "")
+	if resp.Total != 2 || len(resp.Agents) != 2 {
+		t.Fatalf("browse = %+v, want sessions from both agents", resp)
+	}
+	got := browseSessions(t, mux, "agent=research")
+	if got.Total != 1 || got.Sessions[0].Key != "sk_v1_research" {
+		t.Fatalf("agent filter = %+v", got)
+	}
+	got = browseSessions(t, mux, "agent=main")
+	if got.Total != 1 || got.Sessions[0].Channel != "telegram" {
+		t.Fatalf("legacy key item = %+v, want channel parsed from the key", got)
+	}
+}
+
+func TestHandleContinueSession(t *testing.T) {
+	origMatcher := gatewayProcessMatcher
+	gatewayProcessMatcher = func(int) (bool, bool) { return true, true }
+	t.Cleanup(func() { gatewayProcessMatcher = origMatcher })
+
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	var forwarded gatewayContinueSessionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method+" "+r.URL.Path != "POST /runtime/sessions/continue" ||
+			r.Header.Get("Authorization") != "Bearer test-token" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&forwarded)
+		json.NewEncoder(w).Encode(map[string]string{"response": "On it."})
+	}))
+	defer server.Close()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.Gateway.Host = "127.0.0.1"
+	cfg.Gateway.Port = mustGatewayTestPort(t, server.URL)
+	if err := config.SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	store, err := memory.NewJSONLStore(sessionsTestDir(t, configPath))
+	if err != nil {
+		t.Fatalf("NewJSONLStore() error = %v", err)
+	}
+	writeBrowseTestSession(t, store, "sk_v1_discord", browseTestScope("discord", "group:998/7", "discord:alice"),
+		"Remind me about the release")
+	writeBrowseTestSession(t, store, "sk_v1_linked", browseTestScope(session.LinkedChannel, "direct:bob", "bob"),
+		"Linked conversation")
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	continueSession := func(key, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		path := "/api/sessions/browse/" + url.PathEscape(key) + "/continue"
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body)))
+		return rec
+	}
+
+	if rec := continueSession("sk_v1_discord", `{"content":"any news?"}`); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("continue without gateway = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	cmd := startGatewayLikeProcess(t)
+	t.Cleanup(func() {
+		if cmd.Process != nil {
+			_ = cmd.Process.Kill()
+		}
+		_ = cmd.Wait()
+	})
+	writeTestPidFile(t, ppid.PidFileData{
+		PID:   cmd.Process.Pid,
+		Token: "test-token",
+		Host:  cfg.Gateway.Host,
+		Port:  cfg.Gateway.Port,
+	})
+	origPidData := gateway.pidData
+	t.Cleanup(func() {
+		ppid.RemovePidFile(globalConfigDir())
+		gateway.pidData = origPidData
+	})
+
+	for _, tc := range []struct {
+		key, body string
+		want      int
+	}{
+		{"sk_v1_discord", `{"content":"  "}`, http.StatusBadRequest},
+		{"sk_v1_missing", `{"content":"hi"}`, http.StatusNotFound},
+		{"sk_v1_linked", `{"content":"hi","deliver":true}`, http.StatusConflict},
+	} {
+		if rec := continueSession(tc.key, tc.body); rec.Code != tc.want {
+			t.Fatalf("continue %s %s = %d, want %d", tc.key, tc.body, rec.Code, tc.want)
+		}
+	}
+
+	rec := continueSession("sk_v1_discord", `{"content":"any news?","deliver":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("continue status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var reply struct {
+		Response string `json:"response"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil || reply.Response != "On it." {
+		t.Fatalf("reply = %+v, err = %v", reply, err)
+	}
+	want := gatewayContinueSessionRequest{
+		SessionKey: "sk_v1_discord",
+		Channel:    "discord",
+		ChatID:     "998",
+		Content:    "any news?",
+		Deliver:    true,
+	}
+	if forwarded != want {
+		t.Fatalf("forwarded = %+v, want %+v", forwarded, want)
+	}
+}
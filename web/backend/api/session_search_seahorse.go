@@ -0,0 +1,61 @@
+//go:build !mipsle && !netbsd && !(freebsd && arm)
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/seahorse"
+)
+
+// searchSeahorseSessions runs query against the seahorse FTS5 index at
+// dbPath and groups the hits by session key. It fails when the database does
+// not exist, in which case callers fall back to scanning JSONL history.
+func searchSeahorseSessions(
+	ctx context.Context,
+	dbPath string,
+	filter browseSessionFilter,
+) (map[string][]browseSearchMatch, error) {
+	store, err := seahorse.OpenStore(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	results, err := store.SearchMessages(ctx, seahorse.SearchInput{
+		Pattern:          filter.Query,
+		Since:            filter.Since,
+		Before:           filter.Until,
+		Limit:            maxBrowseSearchResults,
+		AllConversations: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessionKeys := make(map[int64]string)
+	matches := make(map[string][]browseSearchMatch)
+	for _, result := range results {
+		key, ok := sessionKeys[result.ConversationID]
+		if !ok {
+			conv, err := store.GetConversation(ctx, result.ConversationID)
+			if err != nil {
+				return nil, err
+			}
+			if conv != nil {
+				key = conv.SessionKey
+			}
+			sessionKeys[result.ConversationID] = key
+		}
+		if key == "" || len(matches[key]) == maxBrowseSearchMatches {
+			continue
+		}
+		matches[key] = append(matches[key], browseSearchMatch{
+			Role:    result.Role,
+			Snippet: result.Snippet,
+			Created: result.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return matches, nil
+}
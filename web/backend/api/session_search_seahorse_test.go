@@ -0,0 +1,66 @@
+//go:build !mipsle && !netbsd && !(freebsd && arm)
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/memory"
+	"github.com/sipeed/picoclaw/pkg/seahorse"
+)
+
+func TestHandleBrowseSessions_SeahorseSearch(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.Agents.Defaults.ContextManager = "seahorse"
+	if err := config.SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	dir := sessionsTestDir(t, configPath)
+	store, err := memory.NewJSONLStore(dir)
+	if err != nil {
+		t.Fatalf("NewJSONLStore() error = %v", err)
+	}
+	writeBrowseTestSession(t, store, "sk_v1_indexed", browseTestScope("telegram", "direct:42", "telegram:42"),
+		"Deploy the staging cluster tonight")
+	writeBrowseTestSession(t, store, "sk_v1_unindexed", browseTestScope("slack", "group:c1", "slack:u1"),
+		"staging is down again")
+
+	engine, err := seahorse.NewEngine(seahorse.Config{DBPath: filepath.Join(dir, "seahorse.db")}, nil)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	if _, err := engine.Ingest(context.Background(), "sk_v1_indexed", []seahorse.Message{
+		{Role: "user", Content: "Deploy the staging cluster tonight", TokenCount: 6},
+	}); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if err := engine.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	resp := browseSessions(t, mux, "q=staging")
+	if resp.SearchBackend != searchBackendSeahorse {
+		t.Fatalf("search_backend = %q, want %q", resp.SearchBackend, searchBackendSeahorse)
+	}
+	if resp.Total != 1 || resp.Sessions[0].Key != "sk_v1_indexed" {
+		t.Fatalf("search = %+v, want only the indexed session", resp)
+	}
+	if matches := resp.Sessions[0].Matches; len(matches) != 1 || matches[0].Role != "user" || matches[0].Snippet == "" {
+		t.Fatalf("matches = %+v", matches)
+	}
+}
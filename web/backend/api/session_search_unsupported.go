@@ -0,0 +1,18 @@
+//go:build mipsle || netbsd || (freebsd && arm)
+
+package api
+
+import (
+	"context"
+	"errors"
+)
+
+// searchSeahorseSessions is unavailable where the seahorse SQLite driver does
+// not build; session search always scans JSONL history there.
+func searchSeahorseSessions(
+	context.Context,
+	string,
+	browseSessionFilter,
+) (map[string][]browseSearchMatch, error) {
+	return nil, errors.New("seahorse is not supported on this platform")
+}
@@ -111,6 +111,12 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "automation",
 		ConfigKey:   "cron",
 	},
+	{
+		Name:        "triggers",
+		Description: "Run the agent when watched files change, feeds publish entries, or polled JSON values change.",
+		Category:    "automation",
+		ConfigKey:   "triggers",
+	},
 	{
 		Name:        "web_search",
 		Description: "Search the web using the configured providers.",
@@ -349,6 +355,8 @@ func applyToolState(cfg *config.Config, toolName string, enabled bool) error {
 		cfg.Tools.Exec.Enabled = enabled
 	case "cron":
 		cfg.Tools.Cron.Enabled = enabled
+	case "triggers":
+		cfg.Tools.Triggers.Enabled = enabled
 	case "web_search":
 		cfg.Tools.Web.Enabled = enabled
 	case "web_fetch":
@@ -1,12 +1,24 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"os/exec"
+	"time"
 
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/health"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/updater"
+	"github.com/sipeed/picoclaw/web/backend/utils"
 )
 
+// gatewayUpdateReadyTimeout bounds how long a freshly updated gateway may
+// take to report ready before the update is rolled back.
+var gatewayUpdateReadyTimeout = 60 * time.Second
+
 // registerUpdateRoutes registers the self-update endpoint.
 func (h *Handler) registerUpdateRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/update", h.handleUpdate)
@@ -15,6 +27,10 @@ func (h *Handler) registerUpdateRoutes(mux *http.ServeMux) {
 type updateRequest struct {
 	URL    string `json:"url,omitempty"`
 	Binary string `json:"binary,omitempty"`
+	// Target is "launcher" (default) to replace this process's binary, or
+	// "gateway" to replace the picoclaw binary, restart the gateway and roll
+	// back if it does not become ready.
+	Target string `json:"target,omitempty"`
 }
 
 type updateResponse struct {
@@ -37,6 +53,17 @@ func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch req.Target {
+	case "", "launcher":
+	case "gateway":
+		h.handleGatewayUpdate(w, r, req.URL)
+		return
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(updateResponse{Status: "error", Message: "target must be launcher or gateway"})
+		return
+	}
+
 	binary := req.Binary
 	if binary == "" {
 		binary = "picoclaw-launcher"
@@ -50,3 +77,101 @@ func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
 
 	_ = json.NewEncoder(w).Encode(updateResponse{Status: "ok", Message: "update applied; restart to use new version"})
 }
+
+// handleGatewayUpdate stages the release's picoclaw binary, swaps it in and,
+// when the gateway is running, restarts it and waits for /ready. A gateway
+// that fails to come up is rolled back to the previous binary.
+func (h *Handler) handleGatewayUpdate(w http.ResponseWriter, r *http.Request, releaseURL string) {
+	target, err := exec.LookPath(utils.FindPicoclawBinary())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(updateResponse{Status: "error", Message: fmt.Sprintf("locate gateway binary: %v", err)})
+		return
+	}
+
+	staged, err := updater.StageUpdate(releaseURL, "", "", "picoclaw", target)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(updateResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	defer staged.Close()
+
+	gateway.mu.Lock()
+	running := isCmdProcessAliveLocked(gateway.cmd)
+	gateway.mu.Unlock()
+
+	steps := gatewayUpdateSteps{apply: staged.Apply, rollback: staged.Rollback}
+	if running {
+		steps.restart = h.RestartGateway
+		steps.waitReady = func(pid int) error {
+			// Do not let a disconnecting browser turn into a rollback.
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), gatewayUpdateReadyTimeout)
+			defer cancel()
+			cfg, _ := config.LoadConfig(h.configPath)
+			return health.WaitReady(ctx, h.gatewayProbeBaseURL(cfg), pid, 500*time.Millisecond)
+		}
+	}
+
+	rolledBack, err := runGatewayUpdate(steps)
+	switch {
+	case rolledBack:
+		logger.ErrorC("update", fmt.Sprintf("Gateway update rolled back: %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(updateResponse{
+			Status:  "rolled_back",
+			Message: fmt.Sprintf("new gateway did not become ready (%v); previous version restored", err),
+		})
+	case err != nil:
+		logger.ErrorC("update", fmt.Sprintf("Gateway update failed: %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(updateResponse{Status: "error", Message: err.Error()})
+	case running:
+		logger.InfoC("update", "Gateway updated and ready")
+		_ = json.NewEncoder(w).Encode(updateResponse{Status: "ok", Message: "gateway updated and ready"})
+	default:
+		_ = json.NewEncoder(w).Encode(updateResponse{
+			Status:  "ok",
+			Message: "update applied; the gateway will use it on next start",
+		})
+	}
+}
+
+type gatewayUpdateSteps struct {
+	apply    func() error
+	rollback func() error
+	// restart and waitReady are nil when the gateway is not running, in
+	// which case the new binary is simply left in place for the next start.
+	restart   func() (int, error)
+	waitReady func(pid int) error
+}
+
+// runGatewayUpdate applies a staged gateway binary and verifies it. If the
+// restarted gateway fails to start or become ready the previous binary is
+// restored and the gateway restarted on it; rolledBack reports that case and
+// err then carries the reason.
+func runGatewayUpdate(s gatewayUpdateSteps) (rolledBack bool, err error) {
+	if err := s.apply(); err != nil {
+		return false, err
+	}
+	if s.restart == nil {
+		return false, nil
+	}
+
+	pid, err := s.restart()
+	if err == nil {
+		err = s.waitReady(pid)
+	}
+	if err == nil {
+		return false, nil
+	}
+
+	failure := err
+	if rbErr := s.rollback(); rbErr != nil {
+		return false, fmt.Errorf("%v; rollback failed: %w", failure, rbErr)
+	}
+	if _, rsErr := s.restart(); rsErr != nil {
+		return true, fmt.Errorf("%v; previous version restored but restart failed: %v", failure, rsErr)
+	}
+	return true, failure
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRunGatewayUpdate(t *testing.T) {
+	var calls []string
+	steps := func(readyErr error) gatewayUpdateSteps {
+		calls = nil
+		pid := 100
+		return gatewayUpdateSteps{
+			apply:    func() error { calls = append(calls, "apply"); return nil },
+			rollback: func() error { calls = append(calls, "rollback"); return nil },
+			restart: func() (int, error) {
+				calls = append(calls, "restart")
+				pid++
+				return pid, nil
+			},
+			waitReady: func(int) error { calls = append(calls, "wait"); return readyErr },
+		}
+	}
+
+	rolledBack, err := runGatewayUpdate(steps(nil))
+	if err != nil || rolledBack {
+		t.Fatalf("healthy update: rolledBack=%v err=%v", rolledBack, err)
+	}
+	if want := []string{"apply", "restart", "wait"}; !slices.Equal(calls, want) {
+		t.Fatalf("healthy update calls = %v, want %v", calls, want)
+	}
+
+	notReady := errors.New("not ready: channels failed")
+	rolledBack, err = runGatewayUpdate(steps(notReady))
+	if !rolledBack || !errors.Is(err, notReady) {
+		t.Fatalf("failed update: rolledBack=%v err=%v", rolledBack, err)
+	}
+	if want := []string{"apply", "restart", "wait", "rollback", "restart"}; !slices.Equal(calls, want) {
+		t.Fatalf("failed update calls = %v, want %v", calls, want)
+	}
+
+	// A stopped gateway only gets the new binary.
+	s := steps(nil)
+	s.restart, s.waitReady = nil, nil
+	rolledBack, err = runGatewayUpdate(s)
+	if err != nil || rolledBack {
+		t.Fatalf("stopped gateway: rolledBack=%v err=%v", rolledBack, err)
+	}
+	if want := []string{"apply"}; !slices.Equal(calls, want) {
+		t.Fatalf("stopped gateway calls = %v, want %v", calls, want)
+	}
+
+	// A failed rollback is not reported as rolled back.
+	s = steps(notReady)
+	s.rollback = func() error { return errors.New("no backup") }
+	rolledBack, err = runGatewayUpdate(s)
+	if rolledBack || err == nil {
+		t.Fatalf("failed rollback: rolledBack=%v err=%v", rolledBack, err)
+	}
+}
@@ -0,0 +1,200 @@
+package dashboardauth
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Role is a dashboard account role. Roles are ordered: admin implies operator
+// and operator implies viewer.
+type Role string
+
+const (
+	// RoleViewer may read dashboard state but not change it.
+	RoleViewer Role = "viewer"
+	// RoleOperator may additionally run the gateway, chat, trigger cron jobs
+	// and continue sessions.
+	RoleOperator Role = "operator"
+	// RoleAdmin may additionally change configuration and manage accounts.
+	RoleAdmin Role = "admin"
+)
+
+// DefaultAdminUsername is the account created from the legacy single
+// dashboard password and by first-time setup when no username is given.
+const DefaultAdminUsername = "admin"
+
+// APITokenPrefix prefixes every plaintext API token so leaked tokens are easy
+// to recognize in logs and secret scanners.
+const APITokenPrefix = "pct_"
+
+var (
+	// ErrUserNotFound reports that no account has the requested username.
+	ErrUserNotFound = errors.New("dashboard user not found")
+	// ErrUserExists reports that the username is already taken.
+	ErrUserExists = errors.New("dashboard user already exists")
+	// ErrInvalidCredentials reports an unknown username or a wrong password.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrLastAdmin reports that the change would leave no admin account.
+	ErrLastAdmin = errors.New("at least one admin account is required")
+	// ErrInvalidAPIToken reports a missing, revoked or expired API token.
+	ErrInvalidAPIToken = errors.New("invalid API token")
+	// ErrAPITokenNotFound reports that no token matches the requested id.
+	ErrAPITokenNotFound = errors.New("API token not found")
+	// ErrTOTPNotPending reports that TOTP confirmation was attempted without
+	// a preceding enrollment.
+	ErrTOTPNotPending = errors.New("no pending TOTP enrollment")
+	// ErrInvalidTOTPCode reports a wrong or expired TOTP code.
+	ErrInvalidTOTPCode = errors.New("invalid TOTP code")
+)
+
+// InputError reports a rejected username, role, password, scope or similar
+// caller-supplied field.
+type InputError struct {
+	msg string
+}
+
+func (e *InputError) Error() string { return e.msg }
+
+func inputErrorf(format string, args ...any) error {
+	return &InputError{msg: fmt.Sprintf(format, args...)}
+}
+
+// User is a dashboard account without its credentials.
+type User struct {
+	ID          int64     `json:"id"`
+	Username    string    `json:"username"`
+	Role        Role      `json:"role"`
+	TOTPEnabled bool      `json:"totp_enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// APITokenSpec describes a token to create.
+type APITokenSpec struct {
+	Username string
+	Name     string
+	// Role caps what the token may do; it is further capped by the owner's
+	// role at request time.
+	Role Role
+	// Scopes limits the token to /api/<scope>/... resources. Empty or "*"
+	// means all resources.
+	Scopes []string
+	// AllowedCIDRs pins the token to client networks. Empty means any
+	// network allowed by the launcher allowlist.
+	AllowedCIDRs []string
+	// ExpiresAt is the zero time for tokens that never expire.
+	ExpiresAt time.Time
+}
+
+// APIToken is a stored API token. The plaintext is only returned once, by
+// CreateAPIToken.
+type APIToken struct {
+	ID           int64      `json:"id"`
+	Username     string     `json:"username"`
+	Name         string     `json:"name"`
+	Role         Role       `json:"role"`
+	Scopes       []string   `json:"scopes,omitempty"`
+	AllowedCIDRs []string   `json:"allowed_cidrs,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
+
+// AuditEntry is one recorded dashboard change.
+type AuditEntry struct {
+	ID       int64     `json:"id"`
+	Time     time.Time `json:"time"`
+	Username string    `json:"username"`
+	// Via is how the caller authenticated: "session", "launcher" or "token".
+	Via    string `json:"via"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+}
+
+// AuditQuery filters ListAudit results.
+type AuditQuery struct {
+	Username string
+	Limit    int
+}
+
+// ParseRole validates a role name.
+func ParseRole(s string) (Role, error) {
+	switch r := Role(strings.ToLower(strings.TrimSpace(s))); r {
+	case RoleViewer, RoleOperator, RoleAdmin:
+		return r, nil
+	default:
+		return "", inputErrorf("unknown role %q (want viewer, operator or admin)", s)
+	}
+}
+
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Allows reports whether r grants at least the required role.
+func (r Role) Allows(required Role) bool {
+	return r.rank() > 0 && r.rank() >= required.rank()
+}
+
+// MinRole returns the less privileged of a and b.
+func MinRole(a, b Role) Role {
+	if a.rank() <= b.rank() {
+		return a
+	}
+	return b
+}
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@-]{0,63}$`)
+
+// ValidateUsername checks that a username is 1-64 characters of letters,
+// digits, '.', '_', '@' or '-', starting with a letter or digit.
+func ValidateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return inputErrorf("invalid username %q", username)
+	}
+	return nil
+}
+
+var scopePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// NormalizeScopes lowercases, deduplicates and validates token scopes. A "*"
+// entry collapses the list to nil (all resources).
+func NormalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, raw := range scopes {
+		scope := strings.ToLower(strings.TrimSpace(raw))
+		if scope == "" {
+			continue
+		}
+		if scope == "*" {
+			return nil, nil
+		}
+		if !scopePattern.MatchString(scope) {
+			return nil, inputErrorf("invalid scope %q", raw)
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		out = append(out, scope)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	sort.Strings(out)
+	return out, nil
+}
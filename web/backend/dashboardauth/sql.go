@@ -7,18 +7,130 @@ const (
 	sqliteDriver = "sqlite"
 	// bcryptCost is deliberately high enough to slow brute-force attempts.
 	bcryptCost = 12
+	// auditRetention caps the audit log; older rows are pruned on insert.
+	auditRetention = 10000
 
+	// dashboard_credentials is the legacy single-password table. Open copies
+	// its row into dashboard_users and leaves it for older launchers.
 	sqlCreateTable = `
 		CREATE TABLE IF NOT EXISTS dashboard_credentials (
 			id          INTEGER PRIMARY KEY CHECK (id = 1),
 			bcrypt_hash TEXT    NOT NULL
 		)`
 
-	sqlCountCredentials = `SELECT COUNT(*) FROM dashboard_credentials WHERE id = 1`
+	sqlCreateUsers = `
+		CREATE TABLE IF NOT EXISTS dashboard_users (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			username     TEXT    NOT NULL UNIQUE COLLATE NOCASE,
+			bcrypt_hash  TEXT    NOT NULL,
+			role         TEXT    NOT NULL,
+			totp_secret  TEXT    NOT NULL DEFAULT '',
+			totp_pending TEXT    NOT NULL DEFAULT '',
+			created_at   INTEGER NOT NULL
+		)`
+
+	sqlCreateAPITokens = `
+		CREATE TABLE IF NOT EXISTS dashboard_api_tokens (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id       INTEGER NOT NULL,
+			name          TEXT    NOT NULL,
+			token_hash    TEXT    NOT NULL UNIQUE,
+			role          TEXT    NOT NULL,
+			scopes        TEXT    NOT NULL DEFAULT '',
+			allowed_cidrs TEXT    NOT NULL DEFAULT '',
+			created_at    INTEGER NOT NULL,
+			last_used_at  INTEGER NOT NULL DEFAULT 0,
+			expires_at    INTEGER NOT NULL DEFAULT 0
+		)`
+
+	sqlCreateAuditLog = `
+		CREATE TABLE IF NOT EXISTS dashboard_audit_log (
+			id       INTEGER PRIMARY KEY AUTOINCREMENT,
+			at       INTEGER NOT NULL,
+			username TEXT    NOT NULL,
+			via      TEXT    NOT NULL,
+			method   TEXT    NOT NULL,
+			path     TEXT    NOT NULL,
+			status   INTEGER NOT NULL
+		)`
+
+	sqlMigrateLegacyCredentials = `
+		INSERT INTO dashboard_users (username, bcrypt_hash, role, created_at)
+		SELECT ?, bcrypt_hash, ?, ? FROM dashboard_credentials
+		WHERE id = 1 AND NOT EXISTS (SELECT 1 FROM dashboard_users)`
+
+	sqlCountUsers = `SELECT COUNT(*) FROM dashboard_users`
+
+	sqlCountAdmins = `SELECT COUNT(*) FROM dashboard_users WHERE role = 'admin'`
+
+	sqlUserColumns = `id, username, role, totp_secret <> '', created_at`
+
+	sqlSelectUser = `SELECT ` + sqlUserColumns + ` FROM dashboard_users WHERE username = ?`
+
+	sqlListUsers = `SELECT ` + sqlUserColumns + ` FROM dashboard_users ORDER BY username COLLATE NOCASE`
+
+	sqlSelectUserAuth = `SELECT ` + sqlUserColumns + `, bcrypt_hash FROM dashboard_users WHERE username = ?`
+
+	sqlInsertUser = `
+		INSERT INTO dashboard_users (username, bcrypt_hash, role, created_at)
+		VALUES (?, ?, ?, ?)`
+
+	sqlUpdateUserHash = `UPDATE dashboard_users SET bcrypt_hash = ? WHERE username = ?`
+
+	sqlUpdateUserRole = `UPDATE dashboard_users SET role = ? WHERE username = ?`
+
+	sqlDeleteUser = `DELETE FROM dashboard_users WHERE username = ?`
+
+	sqlSelectTOTP = `SELECT totp_secret, totp_pending FROM dashboard_users WHERE username = ?`
+
+	sqlUpdateTOTPPending = `UPDATE dashboard_users SET totp_pending = ? WHERE username = ?`
+
+	sqlEnableTOTP = `UPDATE dashboard_users SET totp_secret = totp_pending, totp_pending = '' WHERE username = ?`
+
+	sqlDisableTOTP = `UPDATE dashboard_users SET totp_secret = '', totp_pending = '' WHERE username = ?`
+
+	sqlInsertAPIToken = `
+		INSERT INTO dashboard_api_tokens
+			(user_id, name, token_hash, role, scopes, allowed_cidrs, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	sqlAPITokenColumns = `
+		t.id, u.username, t.name, t.role, t.scopes, t.allowed_cidrs,
+		t.created_at, t.last_used_at, t.expires_at`
+
+	sqlListAPITokens = `
+		SELECT ` + sqlAPITokenColumns + `
+		FROM dashboard_api_tokens t JOIN dashboard_users u ON u.id = t.user_id
+		WHERE ? = '' OR u.username = ?
+		ORDER BY t.id`
+
+	sqlSelectAPITokenByHash = `
+		SELECT ` + sqlAPITokenColumns + `, u.role
+		FROM dashboard_api_tokens t JOIN dashboard_users u ON u.id = t.user_id
+		WHERE t.token_hash = ?`
+
+	sqlTouchAPIToken = `UPDATE dashboard_api_tokens SET last_used_at = ? WHERE id = ?`
+
+	sqlDeleteAPIToken = `
+		DELETE FROM dashboard_api_tokens
+		WHERE id = ? AND (? = '' OR user_id = (SELECT id FROM dashboard_users WHERE username = ?))`
+
+	sqlDeleteUserAPITokens = `
+		DELETE FROM dashboard_api_tokens
+		WHERE user_id = (SELECT id FROM dashboard_users WHERE username = ?)`
+
+	sqlInsertAudit = `
+		INSERT INTO dashboard_audit_log (at, username, via, method, path, status)
+		VALUES (?, ?, ?, ?, ?, ?)`
 
-	sqlUpsertHash = `
-		INSERT INTO dashboard_credentials (id, bcrypt_hash) VALUES (1, ?)
-		ON CONFLICT(id) DO UPDATE SET bcrypt_hash = excluded.bcrypt_hash`
+	sqlPruneAudit = `
+		DELETE FROM dashboard_audit_log
+		WHERE id <= (SELECT MAX(id) FROM dashboard_audit_log) - ?`
 
-	sqlSelectHash = `SELECT bcrypt_hash FROM dashboard_credentials WHERE id = 1`
+	sqlListAudit = `
+		SELECT id, at, username, via, method, path, status
+		FROM dashboard_audit_log
+		WHERE ? = '' OR username = ?
+		ORDER BY id DESC
+		LIMIT ?`
 )
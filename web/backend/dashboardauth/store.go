@@ -1,25 +1,37 @@
 //go:build !mipsle && !netbsd && !(freebsd && arm)
 
-// Package dashboardauth provides a bcrypt-backed SQLite store for the
-// launcher dashboard password. The database contains a single row (id=1)
-// with the bcrypt hash; no plaintext is ever persisted.
+// Package dashboardauth provides a SQLite store for launcher dashboard
+// accounts: bcrypt password hashes with a role per user, optional TOTP
+// secrets, hashed API tokens and an audit log of dashboard changes. No
+// plaintext password or token is ever persisted.
 package dashboardauth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	_ "modernc.org/sqlite" // register "sqlite" driver
 )
 
-// Store holds a handle to the SQLite database that stores the bcrypt hash.
+const apiTokenBytes = 32
+
+// Store holds a handle to the SQLite database that stores dashboard accounts.
 type Store struct {
 	db   *sql.DB
 	path string // absolute path to the SQLite file
+	now  func() time.Time
 }
 
 // New opens (or creates) the database inside dir, using the package's
@@ -35,16 +47,36 @@ func New(dir string) (*Store, error) {
 }
 
 // Open opens (or creates) the SQLite database at path and migrates the schema.
+// A legacy single dashboard password becomes the DefaultAdminUsername account.
 func Open(path string) (*Store, error) {
 	db, err := sql.Open(sqliteDriver, path)
 	if err != nil {
 		return nil, err
 	}
-	if _, err = db.Exec(sqlCreateTable); err != nil {
+	// A single connection serializes writers so concurrent logins, token
+	// lookups and audit inserts never hit SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+	s := &Store{db: db, path: path, now: time.Now}
+	if err = s.migrate(); err != nil {
 		_ = db.Close()
 		return nil, err
 	}
-	return &Store{db: db, path: path}, nil
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	for _, stmt := range []string{sqlCreateTable, sqlCreateUsers, sqlCreateAPITokens, sqlCreateAuditLog} {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	// The legacy row is left in place so an older launcher opening the same
+	// database still accepts the password it knew. The copy only happens
+	// while dashboard_users is empty, so it runs once.
+	if _, err := s.db.Exec(sqlMigrateLegacyCredentials, DefaultAdminUsername, RoleAdmin, s.now().Unix()); err != nil {
+		return fmt.Errorf("migrate legacy dashboard password: %w", err)
+	}
+	return nil
 }
 
 // Close releases the database handle.
@@ -53,44 +85,501 @@ func (s *Store) Close() error { return s.db.Close() }
 // DBPath returns the absolute path to the SQLite database file.
 func (s *Store) DBPath() string { return s.path }
 
-// IsInitialized reports whether a password hash has been stored.
+// IsInitialized reports whether at least one account exists.
 func (s *Store) IsInitialized(ctx context.Context) (bool, error) {
 	var n int
-	err := s.db.QueryRowContext(ctx, sqlCountCredentials).Scan(&n)
+	err := s.db.QueryRowContext(ctx, sqlCountUsers).Scan(&n)
 	if err != nil {
 		return false, err
 	}
 	return n > 0, nil
 }
 
-// SetPassword hashes plain with bcrypt (cost 12) and stores (or replaces) it.
-// The plaintext is never written to disk.
+// SetPassword sets the password of the DefaultAdminUsername account, creating
+// it as an admin when it does not exist yet. The plaintext is never written
+// to disk.
 func (s *Store) SetPassword(ctx context.Context, plain string) error {
-	if len([]rune(plain)) == 0 {
-		return errors.New("password must not be empty")
+	err := s.SetUserPassword(ctx, DefaultAdminUsername, plain)
+	if errors.Is(err, ErrUserNotFound) {
+		_, err = s.CreateUser(ctx, DefaultAdminUsername, plain, RoleAdmin)
 	}
-	hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcryptCost)
+	return err
+}
+
+// VerifyPassword returns true iff plain matches the DefaultAdminUsername
+// account's password. Returns (false, nil) when no such account exists.
+func (s *Store) VerifyPassword(ctx context.Context, plain string) (bool, error) {
+	_, err := s.AuthenticateUser(ctx, DefaultAdminUsername, plain)
+	if errors.Is(err, ErrInvalidCredentials) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CreateUser adds an account with a bcrypt-hashed password.
+func (s *Store) CreateUser(ctx context.Context, username, plain string, role Role) (User, error) {
+	if err := ValidateUsername(username); err != nil {
+		return User{}, err
+	}
+	if _, err := ParseRole(string(role)); err != nil {
+		return User{}, err
+	}
+	hash, err := hashPassword(plain)
+	if err != nil {
+		return User{}, err
+	}
+	if _, err = s.GetUser(ctx, username); err == nil {
+		return User{}, ErrUserExists
+	} else if !errors.Is(err, ErrUserNotFound) {
+		return User{}, err
+	}
+	if _, err = s.db.ExecContext(ctx, sqlInsertUser, username, hash, role, s.now().Unix()); err != nil {
+		return User{}, err
+	}
+	return s.GetUser(ctx, username)
+}
+
+// GetUser returns the account for username (case-insensitive).
+func (s *Store) GetUser(ctx context.Context, username string) (User, error) {
+	u, err := scanUser(s.db.QueryRowContext(ctx, sqlSelectUser, username))
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, ErrUserNotFound
+	}
+	return u, err
+}
+
+// ListUsers returns all accounts ordered by username.
+func (s *Store) ListUsers(ctx context.Context) ([]User, error) {
+	rows, err := s.db.QueryContext(ctx, sqlListUsers)
 	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// UpdateUserRole changes an account's role. Demoting the last admin fails
+// with ErrLastAdmin.
+func (s *Store) UpdateUserRole(ctx context.Context, username string, role Role) error {
+	if _, err := ParseRole(string(role)); err != nil {
 		return err
 	}
-	_, err = s.db.ExecContext(ctx, sqlUpsertHash, string(hash))
-	return err
+	return s.withAdminGuard(ctx, username, role != RoleAdmin, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, sqlUpdateUserRole, role, username)
+		return err
+	})
 }
 
-// VerifyPassword returns true iff plain matches the stored bcrypt hash.
-// Returns (false, nil) when no password has been set yet.
-func (s *Store) VerifyPassword(ctx context.Context, plain string) (bool, error) {
-	var hash string
-	err := s.db.QueryRowContext(ctx, sqlSelectHash).Scan(&hash)
+// DeleteUser removes an account and its API tokens. Deleting the last admin
+// fails with ErrLastAdmin.
+func (s *Store) DeleteUser(ctx context.Context, username string) error {
+	return s.withAdminGuard(ctx, username, true, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, sqlDeleteUserAPITokens, username); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, sqlDeleteUser, username)
+		return err
+	})
+}
+
+// withAdminGuard runs fn in a transaction after checking that username exists
+// and, when removesAdmin is set, that it is not the only admin left.
+func (s *Store) withAdminGuard(
+	ctx context.Context,
+	username string,
+	removesAdmin bool,
+	fn func(*sql.Tx) error,
+) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	u, err := scanUser(tx.QueryRowContext(ctx, sqlSelectUser, username))
 	if errors.Is(err, sql.ErrNoRows) {
-		return false, nil
+		return ErrUserNotFound
 	}
 	if err != nil {
-		return false, err
+		return err
+	}
+	if removesAdmin && u.Role == RoleAdmin {
+		var admins int
+		if err = tx.QueryRowContext(ctx, sqlCountAdmins).Scan(&admins); err != nil {
+			return err
+		}
+		if admins <= 1 {
+			return ErrLastAdmin
+		}
+	}
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetUserPassword replaces an account's password.
+func (s *Store) SetUserPassword(ctx context.Context, username, plain string) error {
+	hash, err := hashPassword(plain)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx, sqlUpdateUserHash, hash, username)
+	if err != nil {
+		return err
 	}
+	return requireAffected(res, ErrUserNotFound)
+}
+
+// AuthenticateUser checks username and password. Unknown users and wrong
+// passwords both return ErrInvalidCredentials.
+func (s *Store) AuthenticateUser(ctx context.Context, username, plain string) (User, error) {
+	var (
+		u    User
+		hash string
+	)
+	row := s.db.QueryRowContext(ctx, sqlSelectUserAuth, username)
+	var created int64
+	err := row.Scan(&u.ID, &u.Username, &u.Role, &u.TOTPEnabled, &created, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Spend the same bcrypt time as for a real account so response
+		// times do not reveal which usernames exist.
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(plain))
+		return User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return User{}, err
+	}
+	u.CreatedAt = time.Unix(created, 0)
 	err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain))
 	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-		return false, nil
+		return User{}, ErrInvalidCredentials
 	}
-	return err == nil, err
+	if err != nil {
+		return User{}, err
+	}
+	return u, nil
+}
+
+// BeginTOTPEnrollment generates a new pending TOTP secret for username. The
+// secret only takes effect after ConfirmTOTPEnrollment.
+func (s *Store) BeginTOTPEnrollment(ctx context.Context, username string) (string, error) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+	res, err := s.db.ExecContext(ctx, sqlUpdateTOTPPending, secret, username)
+	if err != nil {
+		return "", err
+	}
+	if err = requireAffected(res, ErrUserNotFound); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// ConfirmTOTPEnrollment enables the pending TOTP secret once code proves the
+// authenticator app was set up correctly.
+func (s *Store) ConfirmTOTPEnrollment(ctx context.Context, username, code string) error {
+	_, pending, err := s.totpSecrets(ctx, username)
+	if err != nil {
+		return err
+	}
+	if pending == "" {
+		return ErrTOTPNotPending
+	}
+	if !VerifyTOTPCode(pending, code, s.now()) {
+		return ErrInvalidTOTPCode
+	}
+	_, err = s.db.ExecContext(ctx, sqlEnableTOTP, username)
+	return err
+}
+
+// DisableTOTP removes the TOTP second factor from username.
+func (s *Store) DisableTOTP(ctx context.Context, username string) error {
+	res, err := s.db.ExecContext(ctx, sqlDisableTOTP, username)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, ErrUserNotFound)
+}
+
+// VerifyTOTP checks code against username's enabled TOTP secret. It returns
+// true when TOTP is not enabled for the account.
+func (s *Store) VerifyTOTP(ctx context.Context, username, code string) (bool, error) {
+	secret, _, err := s.totpSecrets(ctx, username)
+	if err != nil {
+		return false, err
+	}
+	if secret == "" {
+		return true, nil
+	}
+	return VerifyTOTPCode(secret, code, s.now()), nil
+}
+
+func (s *Store) totpSecrets(ctx context.Context, username string) (secret, pending string, err error) {
+	err = s.db.QueryRowContext(ctx, sqlSelectTOTP, username).Scan(&secret, &pending)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", ErrUserNotFound
+	}
+	return secret, pending, err
+}
+
+// CreateAPIToken stores a new token for spec.Username and returns its
+// plaintext, which cannot be recovered later. The token role may not exceed
+// the owner's role.
+func (s *Store) CreateAPIToken(ctx context.Context, spec APITokenSpec) (string, APIToken, error) {
+	owner, err := s.GetUser(ctx, spec.Username)
+	if err != nil {
+		return "", APIToken{}, err
+	}
+	if spec.Role == "" {
+		spec.Role = owner.Role
+	}
+	if _, err = ParseRole(string(spec.Role)); err != nil {
+		return "", APIToken{}, err
+	}
+	if !owner.Role.Allows(spec.Role) {
+		return "", APIToken{}, inputErrorf("token role %q exceeds %s's role %q", spec.Role, owner.Username, owner.Role)
+	}
+	name := strings.TrimSpace(spec.Name)
+	if name == "" {
+		return "", APIToken{}, inputErrorf("token name must not be empty")
+	}
+	scopes, err := NormalizeScopes(spec.Scopes)
+	if err != nil {
+		return "", APIToken{}, err
+	}
+	cidrs := make([]string, 0, len(spec.AllowedCIDRs))
+	for _, raw := range spec.AllowedCIDRs {
+		cidr := strings.TrimSpace(raw)
+		if cidr == "" {
+			continue
+		}
+		if _, _, err = net.ParseCIDR(cidr); err != nil {
+			return "", APIToken{}, inputErrorf("invalid CIDR %q", cidr)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	var expires int64
+	if !spec.ExpiresAt.IsZero() {
+		expires = spec.ExpiresAt.Unix()
+	}
+
+	buf := make([]byte, apiTokenBytes)
+	if _, err = rand.Read(buf); err != nil {
+		return "", APIToken{}, err
+	}
+	plain := APITokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	res, err := s.db.ExecContext(ctx, sqlInsertAPIToken,
+		owner.ID, name, hashAPIToken(plain), spec.Role,
+		strings.Join(scopes, ","), strings.Join(cidrs, ","),
+		s.now().Unix(), expires)
+	if err != nil {
+		return "", APIToken{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return "", APIToken{}, err
+	}
+	tokens, err := s.ListAPITokens(ctx, owner.Username)
+	if err != nil {
+		return "", APIToken{}, err
+	}
+	for _, tok := range tokens {
+		if tok.ID == id {
+			return plain, tok, nil
+		}
+	}
+	return "", APIToken{}, ErrAPITokenNotFound
+}
+
+// ListAPITokens returns the tokens owned by username, or all tokens when
+// username is empty.
+func (s *Store) ListAPITokens(ctx context.Context, username string) ([]APIToken, error) {
+	rows, err := s.db.QueryContext(ctx, sqlListAPITokens, username, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tokens []APIToken
+	for rows.Next() {
+		tok, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeAPIToken deletes token id. When username is non-empty the token must
+// belong to that user.
+func (s *Store) RevokeAPIToken(ctx context.Context, id int64, username string) error {
+	res, err := s.db.ExecContext(ctx, sqlDeleteAPIToken, id, username, username)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, ErrAPITokenNotFound)
+}
+
+// AuthenticateAPIToken resolves a plaintext bearer token. The returned
+// token's Role is capped by the owner's current role so demoting a user also
+// demotes their tokens.
+func (s *Store) AuthenticateAPIToken(ctx context.Context, plain string) (APIToken, error) {
+	if !strings.HasPrefix(plain, APITokenPrefix) {
+		return APIToken{}, ErrInvalidAPIToken
+	}
+	var ownerRole Role
+	tok, err := scanAPIToken(s.db.QueryRowContext(ctx, sqlSelectAPITokenByHash, hashAPIToken(plain)), &ownerRole)
+	if errors.Is(err, sql.ErrNoRows) {
+		return APIToken{}, ErrInvalidAPIToken
+	}
+	if err != nil {
+		return APIToken{}, err
+	}
+	now := s.now()
+	if tok.ExpiresAt != nil && !now.Before(*tok.ExpiresAt) {
+		return APIToken{}, ErrInvalidAPIToken
+	}
+	tok.Role = MinRole(tok.Role, ownerRole)
+	// Last-use tracking is informational; a failed update must not reject
+	// an otherwise valid token.
+	if tok.LastUsedAt == nil || now.Sub(*tok.LastUsedAt) >= time.Minute {
+		_, _ = s.db.ExecContext(ctx, sqlTouchAPIToken, now.Unix(), tok.ID)
+	}
+	return tok, nil
+}
+
+// RecordAudit appends entry to the audit log, pruning the oldest rows beyond
+// the retention limit.
+func (s *Store) RecordAudit(ctx context.Context, entry AuditEntry) error {
+	at := entry.Time
+	if at.IsZero() {
+		at = s.now()
+	}
+	if _, err := s.db.ExecContext(ctx, sqlInsertAudit,
+		at.Unix(), entry.Username, entry.Via, entry.Method, entry.Path, entry.Status); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, sqlPruneAudit, auditRetention)
+	return err
+}
+
+// ListAudit returns the newest audit entries first.
+func (s *Store) ListAudit(ctx context.Context, q AuditQuery) ([]AuditEntry, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > auditRetention {
+		limit = 100
+	}
+	rows, err := s.db.QueryContext(ctx, sqlListAudit, q.Username, q.Username, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []AuditEntry
+	for rows.Next() {
+		var (
+			e  AuditEntry
+			at int64
+		)
+		if err := rows.Scan(&e.ID, &at, &e.Username, &e.Via, &e.Method, &e.Path, &e.Status); err != nil {
+			return nil, err
+		}
+		e.Time = time.Unix(at, 0)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner) (User, error) {
+	var (
+		u       User
+		created int64
+	)
+	if err := row.Scan(&u.ID, &u.Username, &u.Role, &u.TOTPEnabled, &created); err != nil {
+		return User{}, err
+	}
+	u.CreatedAt = time.Unix(created, 0)
+	return u, nil
+}
+
+func scanAPIToken(row rowScanner, extra ...any) (APIToken, error) {
+	var (
+		tok                       APIToken
+		scopes, cidrs             string
+		created, lastUsed, expiry int64
+	)
+	dest := []any{
+		&tok.ID, &tok.Username, &tok.Name, &tok.Role, &scopes, &cidrs,
+		&created, &lastUsed, &expiry,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return APIToken{}, err
+	}
+	if scopes != "" {
+		tok.Scopes = strings.Split(scopes, ",")
+	}
+	if cidrs != "" {
+		tok.AllowedCIDRs = strings.Split(cidrs, ",")
+	}
+	tok.CreatedAt = time.Unix(created, 0)
+	if lastUsed > 0 {
+		t := time.Unix(lastUsed, 0)
+		tok.LastUsedAt = &t
+	}
+	if expiry > 0 {
+		t := time.Unix(expiry, 0)
+		tok.ExpiresAt = &t
+	}
+	return tok, nil
+}
+
+// dummyPasswordHash is compared against for unknown usernames. Hashing a
+// short fixed password at bcryptCost cannot fail.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("picoclaw-dashboard-dummy"), bcryptCost)
+	return hash
+})
+
+func hashPassword(plain string) (string, error) {
+	if len([]rune(plain)) == 0 {
+		return "", inputErrorf("password must not be empty")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// hashAPIToken uses SHA-256 rather than bcrypt: tokens carry 256 bits of
+// entropy, and lookups happen on every API request.
+func hashAPIToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func requireAffected(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
 }
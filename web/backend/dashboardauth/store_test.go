@@ -0,0 +1,269 @@
+//go:build !mipsle && !netbsd && !(freebsd && arm)
+
+package dashboardauth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), DBFilename))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestOpenMigratesLegacyPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DBFilename)
+	db, err := sql.Open(sqliteDriver, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte("legacy-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec(sqlCreateTable); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec(`INSERT INTO dashboard_credentials (id, bcrypt_hash) VALUES (1, ?)`, string(hash)); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	ctx := context.Background()
+	u, err := s.AuthenticateUser(ctx, DefaultAdminUsername, "legacy-password")
+	if err != nil {
+		t.Fatalf("AuthenticateUser() error = %v", err)
+	}
+	if u.Role != RoleAdmin {
+		t.Fatalf("migrated role = %q, want admin", u.Role)
+	}
+	ok, err := s.VerifyPassword(ctx, "legacy-password")
+	if err != nil || !ok {
+		t.Fatalf("VerifyPassword() = %v, %v", ok, err)
+	}
+
+	// The legacy row stays for older launchers, and reopening does not
+	// migrate it again.
+	var legacy int
+	if err = s.db.QueryRow(`SELECT COUNT(*) FROM dashboard_credentials`).Scan(&legacy); err != nil || legacy != 1 {
+		t.Fatalf("legacy rows = %d, %v; want 1", legacy, err)
+	}
+	_ = s.Close()
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	users, err := s.ListUsers(ctx)
+	if err != nil || len(users) != 1 {
+		t.Fatalf("ListUsers() after reopen = %+v, %v; want one account", users, err)
+	}
+}
+
+func TestStoreUsersAndLastAdminGuard(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	if ok, _ := s.IsInitialized(ctx); ok {
+		t.Fatal("new store should not be initialized")
+	}
+	if err := s.SetPassword(ctx, "admin-password"); err != nil {
+		t.Fatalf("SetPassword() error = %v", err)
+	}
+	if ok, _ := s.IsInitialized(ctx); !ok {
+		t.Fatal("store should be initialized after SetPassword")
+	}
+	if _, err := s.CreateUser(ctx, "alice", "alice-password", RoleViewer); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if _, err := s.CreateUser(ctx, "Alice", "other", RoleViewer); !errors.Is(err, ErrUserExists) {
+		t.Fatalf("duplicate CreateUser() error = %v, want ErrUserExists", err)
+	}
+	if _, err := s.AuthenticateUser(ctx, "alice", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("AuthenticateUser(wrong) error = %v", err)
+	}
+	if _, err := s.AuthenticateUser(ctx, "nobody", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("AuthenticateUser(unknown) error = %v", err)
+	}
+	// Unknown users are checked against a hash of the same cost as real ones.
+	if cost, err := bcrypt.Cost(dummyPasswordHash()); err != nil || cost != bcryptCost {
+		t.Fatalf("dummy hash cost = %d, %v; want %d", cost, err, bcryptCost)
+	}
+
+	if err := s.UpdateUserRole(ctx, DefaultAdminUsername, RoleViewer); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("demote last admin error = %v, want ErrLastAdmin", err)
+	}
+	if err := s.UpdateUserRole(ctx, "alice", RoleAdmin); err != nil {
+		t.Fatalf("UpdateUserRole() error = %v", err)
+	}
+	if err := s.DeleteUser(ctx, DefaultAdminUsername); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if err := s.DeleteUser(ctx, "alice"); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("delete last admin error = %v, want ErrLastAdmin", err)
+	}
+
+	users, err := s.ListUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Username != "alice" || users[0].Role != RoleAdmin {
+		t.Fatalf("users = %+v", users)
+	}
+}
+
+func TestStoreTOTPEnrollment(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	s.now = func() time.Time { return now }
+
+	if _, err := s.CreateUser(ctx, "bob", "bob-password", RoleOperator); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ConfirmTOTPEnrollment(ctx, "bob", "000000"); !errors.Is(err, ErrTOTPNotPending) {
+		t.Fatalf("confirm without enrollment error = %v", err)
+	}
+	secret, err := s.BeginTOTPEnrollment(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Pending secrets are not enforced yet.
+	if ok, _ := s.VerifyTOTP(ctx, "bob", ""); !ok {
+		t.Fatal("VerifyTOTP should pass before enrollment is confirmed")
+	}
+	code, _ := TOTPCode(secret, now)
+	if err = s.ConfirmTOTPEnrollment(ctx, "bob", code); err != nil {
+		t.Fatalf("ConfirmTOTPEnrollment() error = %v", err)
+	}
+	u, _ := s.GetUser(ctx, "bob")
+	if !u.TOTPEnabled {
+		t.Fatal("TOTP should be enabled")
+	}
+	if ok, _ := s.VerifyTOTP(ctx, "bob", "000000"); ok && code != "000000" {
+		t.Fatal("wrong code should fail")
+	}
+	if ok, _ := s.VerifyTOTP(ctx, "bob", code); !ok {
+		t.Fatal("current code should pass")
+	}
+	if err = s.DisableTOTP(ctx, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	u, _ = s.GetUser(ctx, "bob")
+	if u.TOTPEnabled {
+		t.Fatal("TOTP should be disabled")
+	}
+}
+
+func TestStoreAPITokens(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	if _, err := s.CreateUser(ctx, "ops", "ops-password", RoleOperator); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.CreateAPIToken(ctx, APITokenSpec{Username: "ops", Name: "ci", Role: RoleAdmin}); err == nil {
+		t.Fatal("token role above owner role should be rejected")
+	}
+
+	plain, tok, err := s.CreateAPIToken(ctx, APITokenSpec{
+		Username:     "ops",
+		Name:         "ci",
+		Scopes:       []string{"Gateway", "cron", "gateway"},
+		AllowedCIDRs: []string{"10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatalf("CreateAPIToken() error = %v", err)
+	}
+	if tok.Role != RoleOperator || len(tok.Scopes) != 2 || tok.Scopes[0] != "cron" {
+		t.Fatalf("token = %+v", tok)
+	}
+
+	got, err := s.AuthenticateAPIToken(ctx, plain)
+	if err != nil {
+		t.Fatalf("AuthenticateAPIToken() error = %v", err)
+	}
+	if got.ID != tok.ID || got.Username != "ops" || len(got.AllowedCIDRs) != 1 {
+		t.Fatalf("authenticated token = %+v", got)
+	}
+	if _, err = s.AuthenticateAPIToken(ctx, plain+"x"); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("tampered token error = %v", err)
+	}
+
+	// Demoting the owner caps the token.
+	if _, err = s.CreateUser(ctx, "root", "root-password", RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.UpdateUserRole(ctx, "ops", RoleViewer); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.AuthenticateAPIToken(ctx, plain)
+	if got.Role != RoleViewer {
+		t.Fatalf("token role after demotion = %q, want viewer", got.Role)
+	}
+
+	if err = s.RevokeAPIToken(ctx, tok.ID, "root"); !errors.Is(err, ErrAPITokenNotFound) {
+		t.Fatalf("revoke other user's token error = %v", err)
+	}
+	if err = s.RevokeAPIToken(ctx, tok.ID, "ops"); err != nil {
+		t.Fatalf("RevokeAPIToken() error = %v", err)
+	}
+	if _, err = s.AuthenticateAPIToken(ctx, plain); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("revoked token error = %v", err)
+	}
+
+	expired, _, err := s.CreateAPIToken(ctx, APITokenSpec{
+		Username:  "root",
+		Name:      "old",
+		ExpiresAt: time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.AuthenticateAPIToken(ctx, expired); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("expired token error = %v", err)
+	}
+}
+
+func TestStoreAudit(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	for _, user := range []string{"alice", "bob", "alice"} {
+		if err := s.RecordAudit(ctx, AuditEntry{
+			Username: user,
+			Via:      "session",
+			Method:   "PUT",
+			Path:     "/api/config",
+			Status:   200,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	all, err := s.ListAudit(ctx, AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].ID < all[2].ID {
+		t.Fatalf("audit = %+v", all)
+	}
+	alice, _ := s.ListAudit(ctx, AuditQuery{Username: "alice", Limit: 1})
+	if len(alice) != 1 || alice[0].Username != "alice" {
+		t.Fatalf("alice audit = %+v", alice)
+	}
+}
@@ -55,6 +55,91 @@ func (s *Store) VerifyPassword(context.Context, string) (bool, error) {
 	return false, unsupportedPlatformError()
 }
 
+// CreateUser reports that the store is unavailable on this platform.
+func (s *Store) CreateUser(context.Context, string, string, Role) (User, error) {
+	return User{}, unsupportedPlatformError()
+}
+
+// GetUser reports that the store is unavailable on this platform.
+func (s *Store) GetUser(context.Context, string) (User, error) {
+	return User{}, unsupportedPlatformError()
+}
+
+// ListUsers reports that the store is unavailable on this platform.
+func (s *Store) ListUsers(context.Context) ([]User, error) {
+	return nil, unsupportedPlatformError()
+}
+
+// UpdateUserRole reports that the store is unavailable on this platform.
+func (s *Store) UpdateUserRole(context.Context, string, Role) error {
+	return unsupportedPlatformError()
+}
+
+// DeleteUser reports that the store is unavailable on this platform.
+func (s *Store) DeleteUser(context.Context, string) error {
+	return unsupportedPlatformError()
+}
+
+// SetUserPassword reports that the store is unavailable on this platform.
+func (s *Store) SetUserPassword(context.Context, string, string) error {
+	return unsupportedPlatformError()
+}
+
+// AuthenticateUser reports that the store is unavailable on this platform.
+func (s *Store) AuthenticateUser(context.Context, string, string) (User, error) {
+	return User{}, unsupportedPlatformError()
+}
+
+// BeginTOTPEnrollment reports that the store is unavailable on this platform.
+func (s *Store) BeginTOTPEnrollment(context.Context, string) (string, error) {
+	return "", unsupportedPlatformError()
+}
+
+// ConfirmTOTPEnrollment reports that the store is unavailable on this platform.
+func (s *Store) ConfirmTOTPEnrollment(context.Context, string, string) error {
+	return unsupportedPlatformError()
+}
+
+// DisableTOTP reports that the store is unavailable on this platform.
+func (s *Store) DisableTOTP(context.Context, string) error {
+	return unsupportedPlatformError()
+}
+
+// VerifyTOTP reports that the store is unavailable on this platform.
+func (s *Store) VerifyTOTP(context.Context, string, string) (bool, error) {
+	return false, unsupportedPlatformError()
+}
+
+// CreateAPIToken reports that the store is unavailable on this platform.
+func (s *Store) CreateAPIToken(context.Context, APITokenSpec) (string, APIToken, error) {
+	return "", APIToken{}, unsupportedPlatformError()
+}
+
+// ListAPITokens reports that the store is unavailable on this platform.
+func (s *Store) ListAPITokens(context.Context, string) ([]APIToken, error) {
+	return nil, unsupportedPlatformError()
+}
+
+// RevokeAPIToken reports that the store is unavailable on this platform.
+func (s *Store) RevokeAPIToken(context.Context, int64, string) error {
+	return unsupportedPlatformError()
+}
+
+// AuthenticateAPIToken reports that the store is unavailable on this platform.
+func (s *Store) AuthenticateAPIToken(context.Context, string) (APIToken, error) {
+	return APIToken{}, unsupportedPlatformError()
+}
+
+// RecordAudit reports that the store is unavailable on this platform.
+func (s *Store) RecordAudit(context.Context, AuditEntry) error {
+	return unsupportedPlatformError()
+}
+
+// ListAudit reports that the store is unavailable on this platform.
+func (s *Store) ListAudit(context.Context, AuditQuery) ([]AuditEntry, error) {
+	return nil, unsupportedPlatformError()
+}
+
 func unsupportedPlatformError() error {
 	return fmt.Errorf("%w (%s/%s)", ErrUnsupportedPlatform, runtime.GOOS, runtime.GOARCH)
 }
@@ -0,0 +1,104 @@
+package dashboardauth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters follow the RFC 6238 defaults understood by every
+// authenticator app: HMAC-SHA1, 6 digits, 30 second steps.
+const (
+	totpSecretBytes = 20
+	totpDigits      = 6
+	totpStep        = 30 * time.Second
+	// totpSkewSteps accepts codes from one step before or after now to
+	// tolerate clock drift between the launcher and the phone.
+	totpSkewSteps = 1
+	// TOTPIssuer is the issuer label shown by authenticator apps.
+	TOTPIssuer = "PicoClaw"
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a random base32 TOTP secret.
+func GenerateTOTPSecret() (string, error) {
+	buf := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// TOTPURI returns the otpauth:// URI used to enroll secret in an
+// authenticator app, usually rendered as a QR code.
+func TOTPURI(account, secret string) string {
+	label := url.PathEscape(TOTPIssuer + ":" + account)
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", TOTPIssuer)
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(int(totpStep/time.Second)))
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// TOTPCode returns the code for secret at time t.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return totpCodeAt(key, uint64(t.Unix())/uint64(totpStep/time.Second)), nil
+}
+
+// VerifyTOTPCode reports whether code is valid for secret at now, allowing
+// one step of clock skew in either direction.
+func VerifyTOTPCode(secret, code string, now time.Time) bool {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return false
+	}
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return false
+	}
+	counter := uint64(now.Unix()) / uint64(totpStep/time.Second)
+	for skew := -totpSkewSteps; skew <= totpSkewSteps; skew++ {
+		want := totpCodeAt(key, counter+uint64(int64(skew)))
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	normalized := strings.ToUpper(strings.TrimRight(strings.ReplaceAll(secret, " ", ""), "="))
+	key, err := totpEncoding.DecodeString(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	return key, nil
+}
+
+func totpCodeAt(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for range totpDigits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
@@ -0,0 +1,62 @@
+package dashboardauth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// RFC 6238 Appendix B SHA-1 vectors, truncated to 6 digits.
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).
+		EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPCode(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("TOTPCode(%d) error = %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %q, want %q", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTOTPCodeAllowsOneStepSkew(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	prev, _ := TOTPCode(secret, now.Add(-totpStep))
+	stale, _ := TOTPCode(secret, now.Add(-3*totpStep))
+
+	if !VerifyTOTPCode(secret, prev, now) {
+		t.Fatal("code from previous step should be accepted")
+	}
+	if VerifyTOTPCode(secret, stale, now) && stale != prev {
+		t.Fatal("code from three steps ago should be rejected")
+	}
+	if VerifyTOTPCode(secret, "12345", now) {
+		t.Fatal("short code should be rejected")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("alice", "ABCDEF")
+	if !strings.HasPrefix(uri, "otpauth://totp/PicoClaw:alice?") {
+		t.Fatalf("uri = %q", uri)
+	}
+	if !strings.Contains(uri, "secret=ABCDEF") || !strings.Contains(uri, "issuer=PicoClaw") {
+		t.Fatalf("uri = %q", uri)
+	}
+}
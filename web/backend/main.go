@@ -512,9 +512,19 @@ func main() {
 
 	// Open the bcrypt password store (creates the DB file on first run).
 	authStore, authStoreErr := dashboardauth.New(picoHome)
-	var passwordStore api.PasswordStore
+	var (
+		passwordStore     api.PasswordStore
+		dashboardSessions *middleware.DashboardSessions
+		dashboardTokens   middleware.DashboardTokenAuthenticator
+		dashboardAudit    middleware.DashboardAuditRecorder
+	)
 	if authStoreErr == nil {
 		passwordStore = authStore
+		// The SQLite store also backs per-user accounts, API tokens and the
+		// audit log; the launcher-config fallback stays single-password.
+		dashboardSessions = middleware.NewDashboardSessions(0)
+		dashboardTokens = authStore
+		dashboardAudit = authStore
 		defer authStore.Close()
 	} else if errors.Is(authStoreErr, dashboardauth.ErrUnsupportedPlatform) {
 		logger.InfoC(
@@ -575,6 +585,7 @@ func main() {
 	api.RegisterLauncherAuthRoutes(mux, api.LauncherAuthRouteOpts{
 		SessionCookie: dashboardSessionCookie,
 		PasswordStore: passwordStore,
+		Sessions:      dashboardSessions,
 		StoreError:    authStoreErr,
 	})
 
@@ -591,13 +602,18 @@ func main() {
 	// Frontend Embedded Assets
 	registerEmbedRoutes(mux)
 
-	accessControlledMux, err := middleware.IPAllowlist(launcherCfg.AllowedCIDRs, mux)
+	accessControlledMux, err := middleware.IPAllowlist(
+		launcherCfg.AllowedCIDRs,
+		middleware.DashboardRoleAccess(middleware.DashboardAudit(dashboardAudit, mux)),
+	)
 	if err != nil {
 		logger.Fatalf("Invalid allowed CIDR configuration: %v", err)
 	}
 
 	dashAuth := middleware.LauncherDashboardAuth(middleware.LauncherDashboardAuthConfig{
 		ExpectedCookie: dashboardSessionCookie,
+		Sessions:       dashboardSessions,
+		Tokens:         dashboardTokens,
 		LocalAutoLogin: localAutoLogin,
 	}, accessControlledMux)
 
@@ -1,10 +1,14 @@
 package middleware
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
+	"slices"
 	"strings"
+
+	"github.com/sipeed/picoclaw/web/backend/dashboardauth"
 )
 
 // IPAllowlist restricts access to requests from configured CIDR ranges.
@@ -15,13 +19,9 @@ func IPAllowlist(allowedCIDRs []string, next http.Handler) (http.Handler, error)
 		return next, nil
 	}
 
-	nets := make([]*net.IPNet, 0, len(allowedCIDRs))
-	for _, cidr := range allowedCIDRs {
-		_, ipNet, err := net.ParseCIDR(cidr)
-		if err != nil {
-			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
-		}
-		nets = append(nets, ipNet)
+	nets, err := parseCIDRs(allowedCIDRs)
+	if err != nil {
+		return nil, err
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -30,19 +30,139 @@ func IPAllowlist(allowedCIDRs []string, next http.Handler) (http.Handler, error)
 			rejectByPolicy(w, r)
 			return
 		}
-		if ip.IsLoopback() {
+		if ip.IsLoopback() || ipInNets(ip, nets) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rejectByPolicy(w, r)
+	}), nil
+}
+
+// DashboardRoleAccess enforces the role, scopes and network pinning of the
+// DashboardPrincipal attached by LauncherDashboardAuth. Requests without a
+// principal (public paths) pass through unchanged.
+func DashboardRoleAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := DashboardPrincipalFromContext(r.Context())
+		if !ok {
 			next.ServeHTTP(w, r)
 			return
 		}
-		for _, ipNet := range nets {
-			if ipNet.Contains(ip) {
-				next.ServeHTTP(w, r)
+		p := canonicalAuthPath(r.URL.Path)
+		if principal.Via == DashboardViaToken {
+			if msg := checkDashboardToken(r, p, principal); msg != "" {
+				rejectByRole(w, r, msg)
 				return
 			}
 		}
+		required := RequiredDashboardRole(r.Method, p)
+		if !principal.Role.Allows(required) {
+			rejectByRole(w, r, fmt.Sprintf("%s role required", required))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
 
-		rejectByPolicy(w, r)
-	}), nil
+// dashboardAdminResources are /api/<resource> prefixes whose mutations change
+// launcher, gateway or channel configuration.
+var dashboardAdminResources = map[string]bool{
+	"channels":       true,
+	"config":         true,
+	"hooks":          true,
+	"identity-links": true,
+	"mcp":            true,
+	"models":         true,
+	"oauth":          true,
+	"pico":           true,
+	"skills":         true,
+	"system":         true,
+	"tools":          true,
+	"update":         true,
+	"wecom":          true,
+	"weixin":         true,
+}
+
+// RequiredDashboardRole returns the minimum role for a request:
+//   - account management and the audit log require admin;
+//   - reads require viewer, as do a caller's own TOTP and API token settings;
+//   - configuration changes require admin;
+//   - other changes (gateway control, cron runs, chat) require operator.
+func RequiredDashboardRole(method, canonicalPath string) dashboardauth.Role {
+	resource := dashboardAPIResource(canonicalPath)
+	if resource == "auth" {
+		rest := strings.TrimPrefix(canonicalPath, "/api/auth")
+		if strings.HasPrefix(rest, "/users") || strings.HasPrefix(rest, "/audit") {
+			return dashboardauth.RoleAdmin
+		}
+		return dashboardauth.RoleViewer
+	}
+	if canonicalPath == "/pico/ws" {
+		return dashboardauth.RoleOperator
+	}
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		return dashboardauth.RoleViewer
+	}
+	if dashboardAdminResources[resource] {
+		return dashboardauth.RoleAdmin
+	}
+	return dashboardauth.RoleOperator
+}
+
+// checkDashboardToken returns a rejection message when an API token may not
+// be used for the request.
+func checkDashboardToken(r *http.Request, canonicalPath string, principal DashboardPrincipal) string {
+	resource := dashboardAPIResource(canonicalPath)
+	if resource == "auth" {
+		return "API tokens cannot manage dashboard accounts"
+	}
+	if len(principal.Scopes) > 0 && !slices.Contains(principal.Scopes, resource) {
+		return fmt.Sprintf("API token is not scoped for %q", resource)
+	}
+	if len(principal.AllowedCIDRs) > 0 {
+		nets, err := parseCIDRs(principal.AllowedCIDRs)
+		if err != nil {
+			return "API token has an invalid network restriction"
+		}
+		ip := clientIPFromRemoteAddr(r.RemoteAddr)
+		if ip == nil || !ipInNets(ip, nets) {
+			return "API token is not allowed from this network"
+		}
+	}
+	return ""
+}
+
+// dashboardAPIResource returns the first path segment after /api/, or "" for
+// non-API paths.
+func dashboardAPIResource(canonicalPath string) string {
+	rest, ok := strings.CutPrefix(canonicalPath, "/api/")
+	if !ok {
+		return ""
+	}
+	resource, _, _ := strings.Cut(rest, "/")
+	return resource
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+func ipInNets(ip net.IP, nets []*net.IPNet) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
 
 func clientIPFromRemoteAddr(remoteAddr string) net.IP {
@@ -62,3 +182,14 @@ func rejectByPolicy(w http.ResponseWriter, r *http.Request) {
 	}
 	http.Error(w, "Forbidden", http.StatusForbidden)
 }
+
+func rejectByRole(w http.ResponseWriter, r *http.Request, msg string) {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		body, _ := json.Marshal(map[string]string{"error": msg})
+		_, _ = w.Write(body)
+		return
+	}
+	http.Error(w, "Forbidden", http.StatusForbidden)
+}
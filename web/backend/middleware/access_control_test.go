@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/sipeed/picoclaw/web/backend/dashboardauth"
 )
 
 func TestIPAllowlist_EmptyCIDRsAllowsAll(t *testing.T) {
@@ -84,3 +86,69 @@ func TestIPAllowlist_InvalidCIDR(t *testing.T) {
 		t.Fatal("IPAllowlist() expected error for invalid CIDR")
 	}
 }
+
+func TestRequiredDashboardRole(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         dashboardauth.Role
+	}{
+		{http.MethodGet, "/api/config", dashboardauth.RoleViewer},
+		{http.MethodPut, "/api/config", dashboardauth.RoleAdmin},
+		{http.MethodPost, "/api/models/default", dashboardauth.RoleAdmin},
+		{http.MethodPost, "/api/gateway/restart", dashboardauth.RoleOperator},
+		{http.MethodPost, "/api/cron/jobs/x/run", dashboardauth.RoleOperator},
+		{http.MethodGet, "/pico/ws", dashboardauth.RoleOperator},
+		{http.MethodGet, "/api/auth/users", dashboardauth.RoleAdmin},
+		{http.MethodGet, "/api/auth/audit", dashboardauth.RoleAdmin},
+		{http.MethodPost, "/api/auth/tokens", dashboardauth.RoleViewer},
+		{http.MethodPost, "/api/auth/totp/enroll", dashboardauth.RoleViewer},
+	}
+	for _, tt := range tests {
+		if got := RequiredDashboardRole(tt.method, tt.path); got != tt.want {
+			t.Errorf("RequiredDashboardRole(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDashboardRoleAccess(t *testing.T) {
+	h := DashboardRoleAccess(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(p DashboardPrincipal, method, path, remote string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remote
+		req = req.WithContext(WithDashboardPrincipal(req.Context(), p))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	viewer := DashboardPrincipal{Username: "v", Role: dashboardauth.RoleViewer, Via: DashboardViaSession}
+	if code := serve(viewer, http.MethodGet, "/api/config", "10.0.0.1:1"); code != http.StatusOK {
+		t.Fatalf("viewer GET: %d", code)
+	}
+	if code := serve(viewer, http.MethodPut, "/api/config", "10.0.0.1:1"); code != http.StatusForbidden {
+		t.Fatalf("viewer PUT: %d, want 403", code)
+	}
+
+	token := DashboardPrincipal{
+		Username:     "ci",
+		Role:         dashboardauth.RoleOperator,
+		Via:          DashboardViaToken,
+		Scopes:       []string{"gateway"},
+		AllowedCIDRs: []string{"192.168.1.0/24"},
+	}
+	if code := serve(token, http.MethodPost, "/api/gateway/restart", "192.168.1.5:1"); code != http.StatusOK {
+		t.Fatalf("scoped token in scope: %d", code)
+	}
+	if code := serve(token, http.MethodPost, "/api/cron/jobs/a/run", "192.168.1.5:1"); code != http.StatusForbidden {
+		t.Fatalf("scoped token out of scope: %d, want 403", code)
+	}
+	if code := serve(token, http.MethodPost, "/api/gateway/restart", "10.0.0.1:1"); code != http.StatusForbidden {
+		t.Fatalf("token outside pinned network: %d, want 403", code)
+	}
+	token.Scopes = nil
+	if code := serve(token, http.MethodGet, "/api/auth/tokens", "192.168.1.5:1"); code != http.StatusForbidden {
+		t.Fatalf("token on /api/auth: %d, want 403", code)
+	}
+}
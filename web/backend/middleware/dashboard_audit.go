@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/web/backend/dashboardauth"
+)
+
+// DashboardAuditRecorder persists audit entries. Implemented by
+// dashboardauth.Store.
+type DashboardAuditRecorder interface {
+	RecordAudit(ctx context.Context, entry dashboardauth.AuditEntry) error
+}
+
+// DashboardAudit records every authenticated /api/* request that may change
+// state (anything but GET, HEAD and OPTIONS) together with the caller and the
+// response status. Request bodies are never recorded.
+func DashboardAudit(recorder DashboardAuditRecorder, next http.Handler) http.Handler {
+	if recorder == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := DashboardPrincipalFromContext(r.Context())
+		if !ok || !isAuditedDashboardRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		entry := dashboardauth.AuditEntry{
+			Time:     time.Now(),
+			Username: principal.Username,
+			Via:      principal.Via,
+			Method:   r.Method,
+			Path:     r.URL.Path,
+			Status:   rec.statusCode,
+		}
+		// The client may already be gone; the record must still be written.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 5*time.Second)
+		defer cancel()
+		if err := recorder.RecordAudit(ctx, entry); err != nil {
+			logger.WarnC("web", fmt.Sprintf("Failed to record dashboard audit entry: %v", err))
+		}
+	})
+}
+
+func isAuditedDashboardRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	p := canonicalAuthPath(r.URL.Path)
+	// Logins are rate-limited, not audited; the resulting session's changes are.
+	return strings.HasPrefix(p, "/api/") && p != "/api/auth/login"
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/web/backend/dashboardauth"
+)
+
+type memoryAuditRecorder struct {
+	entries []dashboardauth.AuditEntry
+}
+
+func (m *memoryAuditRecorder) RecordAudit(_ context.Context, e dashboardauth.AuditEntry) error {
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func TestDashboardAuditRecordsAuthenticatedChanges(t *testing.T) {
+	recorder := &memoryAuditRecorder{}
+	h := DashboardAudit(recorder, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	alice := DashboardPrincipal{Username: "alice", Role: dashboardauth.RoleAdmin, Via: DashboardViaSession}
+
+	for _, tc := range []struct {
+		method, path string
+		principal    bool
+	}{
+		{http.MethodPut, "/api/config", true},
+		{http.MethodGet, "/api/config", true},
+		{http.MethodPost, "/api/auth/login", true},
+		{http.MethodPost, "/api/gateway/start", false},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.principal {
+			req = req.WithContext(WithDashboardPrincipal(req.Context(), alice))
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if len(recorder.entries) != 1 {
+		t.Fatalf("entries = %+v, want only the PUT", recorder.entries)
+	}
+	e := recorder.entries[0]
+	if e.Username != "alice" || e.Via != DashboardViaSession || e.Method != http.MethodPut ||
+		e.Path != "/api/config" || e.Status != http.StatusNoContent {
+		t.Fatalf("entry = %+v", e)
+	}
+}
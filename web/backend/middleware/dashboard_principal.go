@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/web/backend/dashboardauth"
+)
+
+// How a dashboard principal authenticated.
+const (
+	// DashboardViaLauncher is the per-process cookie used by the single
+	// password login and the launcher-opened browser.
+	DashboardViaLauncher = "launcher"
+	// DashboardViaSession is a per-user login session.
+	DashboardViaSession = "session"
+	// DashboardViaToken is a long-lived API bearer token.
+	DashboardViaToken = "token"
+)
+
+// DashboardPrincipal is the authenticated caller of a dashboard request.
+type DashboardPrincipal struct {
+	Username string
+	Role     dashboardauth.Role
+	Via      string
+	// TokenID, Scopes and AllowedCIDRs are only set for API tokens.
+	TokenID      int64
+	Scopes       []string
+	AllowedCIDRs []string
+}
+
+type dashboardPrincipalKey struct{}
+
+// WithDashboardPrincipal returns a copy of ctx carrying p.
+func WithDashboardPrincipal(ctx context.Context, p DashboardPrincipal) context.Context {
+	return context.WithValue(ctx, dashboardPrincipalKey{}, p)
+}
+
+// DashboardPrincipalFromContext returns the principal attached by
+// LauncherDashboardAuth, if any.
+func DashboardPrincipalFromContext(ctx context.Context) (DashboardPrincipal, bool) {
+	p, ok := ctx.Value(dashboardPrincipalKey{}).(DashboardPrincipal)
+	return p, ok
+}
+
+// launcherOwnerPrincipal is the identity behind the per-process cookie. It is
+// an admin because whoever holds it either knows the single dashboard
+// password or was handed the cookie by the launcher that they started.
+func launcherOwnerPrincipal() DashboardPrincipal {
+	return DashboardPrincipal{
+		Username: dashboardauth.DefaultAdminUsername,
+		Role:     dashboardauth.RoleAdmin,
+		Via:      DashboardViaLauncher,
+	}
+}
+
+// DashboardTokenAuthenticator resolves API bearer tokens. Implemented by
+// dashboardauth.Store.
+type DashboardTokenAuthenticator interface {
+	AuthenticateAPIToken(ctx context.Context, token string) (dashboardauth.APIToken, error)
+}
+
+// DashboardSessions keeps per-user login sessions in memory. Like the
+// per-process cookie, sessions do not survive a launcher restart.
+type DashboardSessions struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]dashboardSession
+	now      func() time.Time
+}
+
+type dashboardSession struct {
+	principal DashboardPrincipal
+	expires   time.Time
+}
+
+// NewDashboardSessions creates an empty session table. A non-positive ttl
+// uses the dashboard cookie lifetime.
+func NewDashboardSessions(ttl time.Duration) *DashboardSessions {
+	if ttl <= 0 {
+		ttl = launcherDashboardSessionMaxAgeSec * time.Second
+	}
+	return &DashboardSessions{
+		ttl:      ttl,
+		sessions: make(map[string]dashboardSession),
+		now:      time.Now,
+	}
+}
+
+// Issue creates a session for p and returns the cookie value.
+func (s *DashboardSessions) Issue(p DashboardPrincipal) (string, error) {
+	value, err := randomURLToken(launcherSessionCookieBytes)
+	if err != nil {
+		return "", err
+	}
+	p.Via = DashboardViaSession
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for k, sess := range s.sessions {
+		if !now.Before(sess.expires) {
+			delete(s.sessions, k)
+		}
+	}
+	s.sessions[value] = dashboardSession{principal: p, expires: now.Add(s.ttl)}
+	return value, nil
+}
+
+// Lookup returns the principal for a session cookie value.
+func (s *DashboardSessions) Lookup(value string) (DashboardPrincipal, bool) {
+	if s == nil || value == "" {
+		return DashboardPrincipal{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[value]
+	if !ok {
+		return DashboardPrincipal{}, false
+	}
+	if !s.now().Before(sess.expires) {
+		delete(s.sessions, value)
+		return DashboardPrincipal{}, false
+	}
+	return sess.principal, true
+}
+
+// Revoke ends the session identified by value.
+func (s *DashboardSessions) Revoke(value string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, value)
+}
+
+// RevokeUser ends every session of username, e.g. after a role change,
+// password reset or account deletion.
+func (s *DashboardSessions) RevokeUser(username string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, sess := range s.sessions {
+		if strings.EqualFold(sess.principal.Username, username) {
+			delete(s.sessions, k)
+		}
+	}
+}
@@ -45,7 +45,15 @@ func randomURLToken(n int) (string, error) {
 
 // LauncherDashboardAuthConfig holds runtime material for dashboard access checks.
 type LauncherDashboardAuthConfig struct {
+	// ExpectedCookie is the per-process session cookie. It authenticates as
+	// the launcher owner (an admin).
 	ExpectedCookie string
+	// Sessions holds per-user login sessions when multi-user accounts are
+	// enabled.
+	Sessions *DashboardSessions
+	// Tokens resolves "Authorization: Bearer" API tokens on /api/* paths.
+	// When nil, bearer tokens are not accepted.
+	Tokens DashboardTokenAuthenticator
 	// LocalAutoLogin enables one-shot startup auto-login.
 	LocalAutoLogin *LauncherDashboardLocalAutoLogin
 	// SecureCookie sets the session cookie's Secure flag. If nil, DefaultLauncherDashboardSecureCookie is used.
@@ -129,8 +137,10 @@ func ClearLauncherDashboardSessionCookie(w http.ResponseWriter, r *http.Request,
 	})
 }
 
-// LauncherDashboardAuth requires a valid session cookie before calling next.
-// Public paths are login/setup pages and /api/auth/* handlers.
+// LauncherDashboardAuth requires a valid session cookie (or, for /api/*, an
+// API bearer token) before calling next. The resolved DashboardPrincipal is
+// attached to the request context. Public paths are login/setup pages and
+// /api/auth/* handlers.
 func LauncherDashboardAuth(cfg LauncherDashboardAuthConfig, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		p := canonicalAuthPath(r.URL.Path)
@@ -139,17 +149,76 @@ func LauncherDashboardAuth(cfg LauncherDashboardAuthConfig, next http.Handler) h
 			return
 		}
 		if isPublicLauncherDashboardPath(r.Method, p) {
+			// Public auth endpoints still see who is signed in, e.g. for the
+			// change-password flow and its audit record.
+			if principal, ok := LauncherDashboardCookiePrincipal(r, cfg.ExpectedCookie, cfg.Sessions); ok {
+				r = r.WithContext(WithDashboardPrincipal(r.Context(), principal))
+			}
 			next.ServeHTTP(w, r)
 			return
 		}
-		if validLauncherDashboardAuth(r, cfg) {
-			next.ServeHTTP(w, r)
+		if principal, ok := resolveLauncherDashboardPrincipal(r, p, cfg); ok {
+			next.ServeHTTP(w, r.WithContext(WithDashboardPrincipal(r.Context(), principal)))
 			return
 		}
 		rejectLauncherDashboardAuth(w, r, p)
 	})
 }
 
+// LauncherDashboardCookiePrincipal resolves the dashboard session cookie on r
+// against the per-process cookie and the per-user session table.
+func LauncherDashboardCookiePrincipal(
+	r *http.Request,
+	expectedCookie string,
+	sessions *DashboardSessions,
+) (DashboardPrincipal, bool) {
+	c, err := r.Cookie(LauncherDashboardCookieName)
+	if err != nil || c.Value == "" {
+		return DashboardPrincipal{}, false
+	}
+	if expectedCookie != "" && subtle.ConstantTimeCompare([]byte(c.Value), []byte(expectedCookie)) == 1 {
+		return launcherOwnerPrincipal(), true
+	}
+	return sessions.Lookup(c.Value)
+}
+
+func resolveLauncherDashboardPrincipal(
+	r *http.Request,
+	canonicalPath string,
+	cfg LauncherDashboardAuthConfig,
+) (DashboardPrincipal, bool) {
+	if token, ok := bearerToken(r); ok {
+		// Bearer tokens are for automation against the JSON API only; they
+		// never unlock pages or the Pico WebSocket.
+		if cfg.Tokens == nil || !strings.HasPrefix(canonicalPath, "/api/") {
+			return DashboardPrincipal{}, false
+		}
+		tok, err := cfg.Tokens.AuthenticateAPIToken(r.Context(), token)
+		if err != nil {
+			return DashboardPrincipal{}, false
+		}
+		return DashboardPrincipal{
+			Username:     tok.Username,
+			Role:         tok.Role,
+			Via:          DashboardViaToken,
+			TokenID:      tok.ID,
+			Scopes:       tok.Scopes,
+			AllowedCIDRs: tok.AllowedCIDRs,
+		}, true
+	}
+	return LauncherDashboardCookiePrincipal(r, cfg.ExpectedCookie, cfg.Sessions)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(h, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
 // canonicalAuthPath matches path cleaning used for routing decisions so
 // prefixes like /assets/../ cannot bypass auth (CVE-class traversal).
 
@@ -288,12 +357,8 @@ func isPublicLauncherDashboardStatic(method, p string) bool {
 }
 
 func validLauncherDashboardAuth(r *http.Request, cfg LauncherDashboardAuthConfig) bool {
-	if c, err := r.Cookie(LauncherDashboardCookieName); err == nil {
-		if subtle.ConstantTimeCompare([]byte(c.Value), []byte(cfg.ExpectedCookie)) == 1 {
-			return true
-		}
-	}
-	return false
+	_, ok := LauncherDashboardCookiePrincipal(r, cfg.ExpectedCookie, cfg.Sessions)
+	return ok
 }
 
 func rejectLauncherDashboardAuth(w http.ResponseWriter, r *http.Request, canonicalPath string) {
@@ -1,10 +1,13 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/sipeed/picoclaw/web/backend/dashboardauth"
 )
 
 func TestNewLauncherDashboardSessionCookie(t *testing.T) {
@@ -263,3 +266,100 @@ func TestLauncherDashboardAuth_WebSocketUnauthorizedDoesNotRedirect(t *testing.T
 		t.Fatalf("Location = %q, want empty", got)
 	}
 }
+
+type fakeDashboardTokens map[string]dashboardauth.APIToken
+
+func (f fakeDashboardTokens) AuthenticateAPIToken(_ context.Context, token string) (dashboardauth.APIToken, error) {
+	tok, ok := f[token]
+	if !ok {
+		return dashboardauth.APIToken{}, dashboardauth.ErrInvalidAPIToken
+	}
+	return tok, nil
+}
+
+func TestLauncherDashboardAuth_UserSessionsAndTokens(t *testing.T) {
+	sessions := NewDashboardSessions(time.Hour)
+	sessionVal, err := sessions.Issue(DashboardPrincipal{Username: "alice", Role: dashboardauth.RoleViewer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := LauncherDashboardAuthConfig{
+		ExpectedCookie: "owner-cookie",
+		Sessions:       sessions,
+		Tokens: fakeDashboardTokens{
+			"pct_ok": {ID: 7, Username: "ci", Role: dashboardauth.RoleOperator, Scopes: []string{"gateway"}},
+		},
+	}
+	var got DashboardPrincipal
+	h := LauncherDashboardAuth(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = DashboardPrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+	req.AddCookie(&http.Cookie{Name: LauncherDashboardCookieName, Value: sessionVal})
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || got.Username != "alice" || got.Via != DashboardViaSession {
+		t.Fatalf("session auth: status = %d principal = %+v", rec.Code, got)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: LauncherDashboardCookieName, Value: "owner-cookie"})
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || got.Via != DashboardViaLauncher || got.Role != dashboardauth.RoleAdmin {
+		t.Fatalf("owner cookie: status = %d principal = %+v", rec.Code, got)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/gateway/restart", nil)
+	req.Header.Set("Authorization", "Bearer pct_ok")
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || got.TokenID != 7 || got.Via != DashboardViaToken {
+		t.Fatalf("token auth: status = %d principal = %+v", rec.Code, got)
+	}
+
+	// Tokens only authenticate JSON API calls.
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/pico/ws", nil)
+	req.Header.Set("Authorization", "Bearer pct_ok")
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("token on /pico/ws: status = %d, want 401", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/config", nil)
+	req.Header.Set("Authorization", "Bearer pct_unknown")
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown token: status = %d, want 401", rec.Code)
+	}
+
+	sessions.RevokeUser("ALICE")
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/config", nil)
+	req.AddCookie(&http.Cookie{Name: LauncherDashboardCookieName, Value: sessionVal})
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("revoked session: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestDashboardSessionsExpire(t *testing.T) {
+	sessions := NewDashboardSessions(time.Minute)
+	now := time.Unix(1_700_000_000, 0)
+	sessions.now = func() time.Time { return now }
+	value, err := sessions.Issue(DashboardPrincipal{Username: "bob", Role: dashboardauth.RoleOperator})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sessions.Lookup(value); !ok {
+		t.Fatal("fresh session should resolve")
+	}
+	now = now.Add(2 * time.Minute)
+	if _, ok := sessions.Lookup(value); ok {
+		t.Fatal("expired session should not resolve")
+	}
+}
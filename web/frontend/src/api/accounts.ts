@@ -0,0 +1,148 @@
+import { launcherFetch } from "@/api/http"
+import type { DashboardRole } from "@/api/launcher-auth"
+
+export interface DashboardUser {
+  id: number
+  username: string
+  role: DashboardRole
+  totp_enabled: boolean
+  created_at: string
+}
+
+export interface APIToken {
+  id: number
+  username: string
+  name: string
+  role: DashboardRole
+  scopes?: string[]
+  allowed_cidrs?: string[]
+  created_at: string
+  last_used_at?: string
+  expires_at?: string
+}
+
+export interface APITokenInput {
+  name: string
+  role?: DashboardRole
+  scopes?: string[]
+  allowed_cidrs?: string[]
+  expires_in_days?: number
+}
+
+export interface APITokenCreated {
+  /** Plaintext bearer token; only returned once. */
+  token: string
+  api_token: APIToken
+}
+
+export interface AuditEntry {
+  id: number
+  time: string
+  username: string
+  via: "session" | "launcher" | "token"
+  method: string
+  path: string
+  status: number
+}
+
+export interface TOTPEnrollment {
+  secret: string
+  uri: string
+}
+
+interface ActionResponse {
+  status: string
+}
+
+async function request<T>(path: string, options?: RequestInit): Promise<T> {
+  const res = await launcherFetch(path, options)
+  if (!res.ok) {
+    const text = (await res.text()).trim()
+    let message = text
+    try {
+      const j = JSON.parse(text) as { error?: string }
+      if (j.error) message = j.error
+    } catch {
+      /* plain-text error */
+    }
+    throw new Error(message || `API error: ${res.status} ${res.statusText}`)
+  }
+  return res.json() as Promise<T>
+}
+
+function jsonBody(method: string, body?: unknown): RequestInit {
+  return {
+    method,
+    headers: { "Content-Type": "application/json" },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  }
+}
+
+export async function getUsers(): Promise<{ users: DashboardUser[] }> {
+  return request("/api/auth/users")
+}
+
+export async function createUser(input: {
+  username: string
+  password: string
+  role: DashboardRole
+}): Promise<DashboardUser> {
+  return request("/api/auth/users", jsonBody("POST", input))
+}
+
+export async function updateUser(
+  username: string,
+  input: { role?: DashboardRole; password?: string },
+): Promise<DashboardUser> {
+  return request(
+    `/api/auth/users/${encodeURIComponent(username)}`,
+    jsonBody("PUT", input),
+  )
+}
+
+export async function deleteUser(username: string): Promise<ActionResponse> {
+  return request(`/api/auth/users/${encodeURIComponent(username)}`, {
+    method: "DELETE",
+  })
+}
+
+export async function resetUserTOTP(username: string): Promise<ActionResponse> {
+  return request(`/api/auth/users/${encodeURIComponent(username)}/totp`, {
+    method: "DELETE",
+  })
+}
+
+export async function enrollTOTP(): Promise<TOTPEnrollment> {
+  return request("/api/auth/totp/enroll", jsonBody("POST"))
+}
+
+export async function confirmTOTP(code: string): Promise<ActionResponse> {
+  return request("/api/auth/totp/confirm", jsonBody("POST", { code }))
+}
+
+export async function disableTOTP(code: string): Promise<ActionResponse> {
+  return request("/api/auth/totp/disable", jsonBody("POST", { code }))
+}
+
+export async function getAPITokens(): Promise<{ tokens: APIToken[] }> {
+  return request("/api/auth/tokens")
+}
+
+export async function createAPIToken(
+  input: APITokenInput,
+): Promise<APITokenCreated> {
+  return request("/api/auth/tokens", jsonBody("POST", input))
+}
+
+export async function revokeAPIToken(id: number): Promise<ActionResponse> {
+  return request(`/api/auth/tokens/${id}`, { method: "DELETE" })
+}
+
+export async function getAuditLog(
+  user = "",
+  limit = 100,
+): Promise<{ entries: AuditEntry[] }> {
+  const params = new URLSearchParams({ limit: String(limit) })
+  if (user) params.set("user", user)
+  return request(`/api/auth/audit?${params.toString()}`)
+}
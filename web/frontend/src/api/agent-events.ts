@@ -0,0 +1,37 @@
+export interface AgentEvent {
+  kind: string
+  time: string
+  agent_id?: string
+  turn_id?: string
+  parent_turn_id?: string
+  session_key?: string
+  iteration: number
+  source?: string
+  trace?: string
+  channel?: string
+  chat_id?: string
+  data?: Record<string, unknown>
+}
+
+export interface AgentEventFilter {
+  session?: string
+  agent?: string
+  kinds?: string[]
+}
+
+// agentEventsURL returns the Server-Sent Events endpoint for the live agent
+// event stream. Dashboard cookies authenticate the EventSource request.
+export function agentEventsURL(filter: AgentEventFilter): string {
+  const params = new URLSearchParams()
+  if (filter.session?.trim()) {
+    params.set("session", filter.session.trim())
+  }
+  if (filter.agent?.trim()) {
+    params.set("agent", filter.agent.trim())
+  }
+  if (filter.kinds && filter.kinds.length > 0) {
+    params.set("kind", filter.kinds.join(","))
+  }
+  const query = params.toString()
+  return query ? `/api/agent/events?${query}` : "/api/agent/events"
+}
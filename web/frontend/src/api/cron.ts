@@ -0,0 +1,142 @@
+import { launcherFetch } from "@/api/http"
+
+export interface CronSchedule {
+  kind: "at" | "every" | "cron"
+  atMs?: number
+  everyMs?: number
+  expr?: string
+  tz?: string
+}
+
+export interface CronRunPolicy {
+  maxRetries?: number
+  retryBackoffMs?: number
+  timeoutMs?: number
+  concurrency?: "" | "skip" | "queue" | "allow"
+  catchUp?: "" | "skip" | "once"
+}
+
+export interface CronJob {
+  id: string
+  name: string
+  enabled: boolean
+  schedule: CronSchedule
+  payload: {
+    kind: string
+    message: string
+    command?: string
+    channel?: string
+    to?: string
+  }
+  policy?: CronRunPolicy
+  state: {
+    nextRunAtMs?: number
+    lastRunAtMs?: number
+    lastStatus?: string
+    lastError?: string
+    lastDurationMs?: number
+    runRequestedAtMs?: number
+  }
+  createdAtMs: number
+  updatedAtMs: number
+  deleteAfterRun: boolean
+}
+
+export interface CronRun {
+  jobId: string
+  trigger: string
+  attempt: number
+  status: string
+  startedAtMs: number
+  durationMs: number
+  error?: string
+  output?: string
+}
+
+export interface CronJobInput {
+  name: string
+  schedule: CronSchedule
+  message: string
+  channel?: string
+  to?: string
+  policy?: CronRunPolicy
+}
+
+interface CronJobsResponse {
+  jobs: CronJob[]
+}
+
+interface CronHistoryResponse {
+  job_id: string
+  runs: CronRun[]
+}
+
+interface CronActionResponse {
+  status: string
+}
+
+async function request<T>(path: string, options?: RequestInit): Promise<T> {
+  const res = await launcherFetch(path, options)
+  if (!res.ok) {
+    const text = (await res.text()).trim()
+    throw new Error(text || `API error: ${res.status} ${res.statusText}`)
+  }
+  return res.json() as Promise<T>
+}
+
+export async function getCronJobs(): Promise<CronJobsResponse> {
+  return request<CronJobsResponse>("/api/cron/jobs")
+}
+
+export async function createCronJob(input: CronJobInput): Promise<CronJob> {
+  return request<CronJob>("/api/cron/jobs", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(input),
+  })
+}
+
+export async function updateCronJob(
+  id: string,
+  input: CronJobInput,
+): Promise<CronJob> {
+  return request<CronJob>(`/api/cron/jobs/${encodeURIComponent(id)}`, {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(input),
+  })
+}
+
+export async function setCronJobEnabled(
+  id: string,
+  enabled: boolean,
+): Promise<CronJob> {
+  return request<CronJob>(`/api/cron/jobs/${encodeURIComponent(id)}/state`, {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ enabled }),
+  })
+}
+
+export async function runCronJob(id: string): Promise<CronActionResponse> {
+  return request<CronActionResponse>(
+    `/api/cron/jobs/${encodeURIComponent(id)}/run`,
+    { method: "POST" },
+  )
+}
+
+export async function deleteCronJob(id: string): Promise<CronActionResponse> {
+  return request<CronActionResponse>(
+    `/api/cron/jobs/${encodeURIComponent(id)}`,
+    { method: "DELETE" },
+  )
+}
+
+export async function getCronHistory(
+  id: string,
+  limit = 20,
+): Promise<CronHistoryResponse> {
+  return request<CronHistoryResponse>(
+    `/api/cron/jobs/${encodeURIComponent(id)}/history?limit=${limit}`,
+  )
+}
@@ -0,0 +1,119 @@
+import { launcherFetch } from "@/api/http"
+
+export interface HookDefaults {
+  observer_timeout_ms?: number
+  interceptor_timeout_ms?: number
+  approval_timeout_ms?: number
+}
+
+export interface BuiltinHookConfig {
+  enabled: boolean
+  priority?: number
+  config?: unknown
+}
+
+export interface ProcessHookConfig {
+  enabled: boolean
+  priority?: number
+  transport?: string
+  command?: string[]
+  dir?: string
+  env?: Record<string, string>
+  observe?: string[]
+  intercept?: string[]
+}
+
+export type BuiltinHook = BuiltinHookConfig & { name: string; mounted: boolean }
+export type ProcessHook = ProcessHookConfig & { name: string; mounted: boolean }
+
+export interface HooksResponse {
+  enabled: boolean
+  defaults: HookDefaults
+  gateway_running: boolean
+  init_error?: string
+  available_builtins: string[]
+  builtins: BuiltinHook[]
+  processes: ProcessHook[]
+}
+
+interface HookActionResponse {
+  status: string
+}
+
+export const PROCESS_HOOK_INTERCEPTS = [
+  "before_llm",
+  "after_llm",
+  "before_tool",
+  "after_tool",
+  "approve_tool",
+] as const
+
+async function request<T>(path: string, options?: RequestInit): Promise<T> {
+  const res = await launcherFetch(path, options)
+  if (!res.ok) {
+    const text = (await res.text()).trim()
+    throw new Error(text || `API error: ${res.status} ${res.statusText}`)
+  }
+  return res.json() as Promise<T>
+}
+
+export async function getHooks(): Promise<HooksResponse> {
+  return request<HooksResponse>("/api/hooks")
+}
+
+export async function updateHookSettings(
+  enabled: boolean,
+  defaults: HookDefaults,
+): Promise<HookActionResponse> {
+  return request<HookActionResponse>("/api/hooks", {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ enabled, defaults }),
+  })
+}
+
+export async function saveBuiltinHook(
+  name: string,
+  spec: BuiltinHookConfig,
+): Promise<HookActionResponse> {
+  return request<HookActionResponse>(
+    `/api/hooks/builtins/${encodeURIComponent(name)}`,
+    {
+      method: "PUT",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(spec),
+    },
+  )
+}
+
+export async function deleteBuiltinHook(
+  name: string,
+): Promise<HookActionResponse> {
+  return request<HookActionResponse>(
+    `/api/hooks/builtins/${encodeURIComponent(name)}`,
+    { method: "DELETE" },
+  )
+}
+
+export async function saveProcessHook(
+  name: string,
+  spec: ProcessHookConfig,
+): Promise<HookActionResponse> {
+  return request<HookActionResponse>(
+    `/api/hooks/processes/${encodeURIComponent(name)}`,
+    {
+      method: "PUT",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(spec),
+    },
+  )
+}
+
+export async function deleteProcessHook(
+  name: string,
+): Promise<HookActionResponse> {
+  return request<HookActionResponse>(
+    `/api/hooks/processes/${encodeURIComponent(name)}`,
+    { method: "DELETE" },
+  )
+}
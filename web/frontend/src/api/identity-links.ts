@@ -0,0 +1,37 @@
+import { launcherFetch } from "@/api/http"
+
+export interface IdentityLink {
+  identity: string
+  canonical: string
+  linked_at: string
+}
+
+interface IdentityLinksResponse {
+  links: IdentityLink[]
+}
+
+interface IdentityLinkActionResponse {
+  status: string
+}
+
+async function request<T>(path: string, options?: RequestInit): Promise<T> {
+  const res = await launcherFetch(path, options)
+  if (!res.ok) {
+    const text = (await res.text()).trim()
+    throw new Error(text || `API error: ${res.status} ${res.statusText}`)
+  }
+  return res.json() as Promise<T>
+}
+
+export async function getIdentityLinks(): Promise<IdentityLinksResponse> {
+  return request<IdentityLinksResponse>("/api/identity-links")
+}
+
+export async function deleteIdentityLink(
+  identity: string,
+): Promise<IdentityLinkActionResponse> {
+  return request<IdentityLinkActionResponse>(
+    `/api/identity-links/${encodeURIComponent(identity)}`,
+    { method: "DELETE" },
+  )
+}
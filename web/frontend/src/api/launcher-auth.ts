@@ -4,30 +4,53 @@
  */
 export type LoginResult =
   | { ok: true }
-  | { ok: false; status: number; error: string }
+  | { ok: false; status: number; error: string; totpRequired: boolean }
+
+export interface LoginCredentials {
+  /** Empty means the default admin account. */
+  username?: string
+  password: string
+  totpCode?: string
+}
 
 export async function postLauncherDashboardLogin(
-  password: string,
+  credentials: LoginCredentials,
 ): Promise<LoginResult> {
   const res = await fetch("/api/auth/login", {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     credentials: "same-origin",
-    body: JSON.stringify({ password: password.trim() }),
+    body: JSON.stringify({
+      username: credentials.username?.trim() || undefined,
+      password: credentials.password.trim(),
+      totp_code: credentials.totpCode?.trim() || undefined,
+    }),
   })
   if (res.ok) return { ok: true }
 
-  return {
-    ok: false,
-    status: res.status,
-    error: await readLauncherAuthError(res),
+  let error = `Request failed with status ${res.status}`
+  let totpRequired = false
+  try {
+    const j = (await res.json()) as { error?: string; totp_required?: boolean }
+    if (j.error) error = j.error
+    totpRequired = j.totp_required === true
+  } catch {
+    /* ignore */
   }
+  return { ok: false, status: res.status, error, totpRequired }
 }
 
+export type DashboardRole = "viewer" | "operator" | "admin"
+
 export type LauncherAuthStatus = {
   authenticated: boolean
   /** true when a bcrypt password has been stored in the DB */
   initialized: boolean
+  /** true when per-user accounts, TOTP and API tokens are available */
+  multi_user: boolean
+  username?: string
+  role?: DashboardRole
+  totp_enabled?: boolean
 }
 
 export async function getLauncherAuthStatus(): Promise<LauncherAuthStatus> {
@@ -0,0 +1,125 @@
+import { launcherFetch } from "@/api/http"
+
+export type MCPServerStatus =
+  | "connected"
+  | "failed"
+  | "pending"
+  | "disabled"
+  | "unknown"
+
+export interface MCPServer {
+  name: string
+  enabled: boolean
+  deferred?: boolean
+  type: "stdio" | "http" | "sse"
+  command?: string
+  args?: string[]
+  env?: Record<string, string>
+  env_file?: string
+  url?: string
+  headers?: Record<string, string>
+  status: MCPServerStatus
+  tool_count: number
+  error?: string
+}
+
+export interface MCPServersResponse {
+  enabled: boolean
+  discovery_enabled: boolean
+  gateway_running: boolean
+  init_error?: string
+  servers: MCPServer[]
+}
+
+export interface MCPServerInput {
+  name?: string
+  enabled?: boolean
+  deferred?: boolean
+  type: "stdio" | "http" | "sse"
+  command?: string
+  args?: string[]
+  env?: Record<string, string>
+  env_file?: string
+  url?: string
+  headers?: Record<string, string>
+}
+
+export interface MCPProbeResponse {
+  name: string
+  tool_count: number
+  tools: string[]
+}
+
+interface MCPActionResponse {
+  status: string
+}
+
+async function request<T>(path: string, options?: RequestInit): Promise<T> {
+  const res = await launcherFetch(path, options)
+  if (!res.ok) {
+    const text = (await res.text()).trim()
+    throw new Error(text || `API error: ${res.status} ${res.statusText}`)
+  }
+  return res.json() as Promise<T>
+}
+
+function serverPath(name: string, action?: string): string {
+  const base = `/api/mcp/servers/${encodeURIComponent(name)}`
+  return action ? `${base}/${action}` : base
+}
+
+export async function getMCPServers(): Promise<MCPServersResponse> {
+  return request<MCPServersResponse>("/api/mcp/servers")
+}
+
+export async function createMCPServer(
+  input: MCPServerInput,
+): Promise<MCPActionResponse> {
+  return request<MCPActionResponse>("/api/mcp/servers", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(input),
+  })
+}
+
+export async function updateMCPServer(
+  name: string,
+  input: MCPServerInput,
+): Promise<MCPActionResponse> {
+  return request<MCPActionResponse>(serverPath(name), {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(input),
+  })
+}
+
+export async function setMCPServerEnabled(
+  name: string,
+  enabled: boolean,
+): Promise<MCPActionResponse> {
+  return request<MCPActionResponse>(serverPath(name, "state"), {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ enabled }),
+  })
+}
+
+export async function deleteMCPServer(
+  name: string,
+): Promise<MCPActionResponse> {
+  return request<MCPActionResponse>(serverPath(name), { method: "DELETE" })
+}
+
+export async function testMCPServer(name: string): Promise<MCPProbeResponse> {
+  return request<MCPProbeResponse>(serverPath(name, "test"), {
+    method: "POST",
+  })
+}
+
+export async function restartMCPServer(
+  name: string,
+): Promise<MCPServersResponse> {
+  return request<MCPServersResponse>(serverPath(name, "restart"), {
+    method: "POST",
+  })
+}
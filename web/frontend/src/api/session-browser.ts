@@ -0,0 +1,106 @@
+import { launcherFetch } from "@/api/http"
+import type { SessionDetail } from "@/api/sessions"
+
+export interface SessionScope {
+  version: number
+  agent_id: string
+  channel: string
+  account: string
+  dimensions: string[] | null
+  values: Record<string, string> | null
+}
+
+export interface SessionSearchMatch {
+  role?: string
+  snippet: string
+  created?: string
+}
+
+export interface BrowsedSession {
+  key: string
+  pico_id?: string
+  agent_id: string
+  channel: string
+  account?: string
+  chat_id?: string
+  sender?: string
+  scope?: SessionScope
+  title: string
+  preview: string
+  message_count: number
+  created: string
+  updated: string
+  continuable: boolean
+  matches?: SessionSearchMatch[]
+}
+
+export interface BrowseSessionsResponse {
+  sessions: BrowsedSession[]
+  total: number
+  search_backend?: "seahorse" | "scan"
+  channels: string[]
+  agents: string[]
+}
+
+export interface BrowseSessionsParams {
+  q?: string
+  channel?: string
+  agent?: string
+  sender?: string
+  since?: string
+  until?: string
+  offset?: number
+  limit?: number
+}
+
+export interface BrowsedSessionDetail {
+  session: BrowsedSession
+  messages: SessionDetail["messages"]
+  summary: string
+}
+
+async function request<T>(path: string, options?: RequestInit): Promise<T> {
+  const res = await launcherFetch(path, options)
+  if (!res.ok) {
+    const text = (await res.text()).trim()
+    throw new Error(text || `API error: ${res.status} ${res.statusText}`)
+  }
+  return res.json() as Promise<T>
+}
+
+export async function browseSessions(
+  params: BrowseSessionsParams,
+): Promise<BrowseSessionsResponse> {
+  const query = new URLSearchParams()
+  for (const [key, value] of Object.entries(params)) {
+    if (value !== undefined && value !== "") {
+      query.set(key, String(value))
+    }
+  }
+  return request<BrowseSessionsResponse>(
+    `/api/sessions/browse?${query.toString()}`,
+  )
+}
+
+export async function getBrowsedSession(
+  key: string,
+): Promise<BrowsedSessionDetail> {
+  return request<BrowsedSessionDetail>(
+    `/api/sessions/browse/${encodeURIComponent(key)}`,
+  )
+}
+
+export async function continueSession(
+  key: string,
+  content: string,
+  deliver: boolean,
+): Promise<{ response: string }> {
+  return request<{ response: string }>(
+    `/api/sessions/browse/${encodeURIComponent(key)}/continue`,
+    {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ content, deliver }),
+    },
+  )
+}
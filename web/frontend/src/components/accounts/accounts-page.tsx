@@ -0,0 +1,122 @@
+import { useQuery } from "@tanstack/react-query"
+import dayjs from "dayjs"
+import { useTranslation } from "react-i18next"
+
+import { getAuditLog } from "@/api/accounts"
+import { getLauncherAuthStatus } from "@/api/launcher-auth"
+import { APITokensCard } from "@/components/accounts/api-tokens-card"
+import { TOTPCard } from "@/components/accounts/totp-card"
+import { UsersCard } from "@/components/accounts/users-card"
+import { PageHeader } from "@/components/page-header"
+import { Badge } from "@/components/ui/badge"
+import { Card, CardContent } from "@/components/ui/card"
+import { Skeleton } from "@/components/ui/skeleton"
+
+export function AccountsPage() {
+  const { t } = useTranslation()
+
+  const statusQuery = useQuery({
+    queryKey: ["launcher-auth-status"],
+    queryFn: getLauncherAuthStatus,
+  })
+  const status = statusQuery.data
+  const isAdmin = status?.role === "admin"
+
+  return (
+    <div className="flex h-full flex-col">
+      <PageHeader title={t("navigation.accounts")} />
+
+      <div className="flex-1 overflow-auto p-4 sm:p-8">
+        <div className="mx-auto max-w-3xl space-y-6">
+          {statusQuery.isLoading ? (
+            <Skeleton className="h-32 w-full rounded-xl" />
+          ) : !status?.multi_user ? (
+            <Card>
+              <CardContent className="text-muted-foreground py-10 text-center text-sm">
+                {t("pages.accounts.unavailable")}
+              </CardContent>
+            </Card>
+          ) : (
+            <>
+              <p className="text-muted-foreground text-sm">
+                {t("pages.accounts.signed_in_as", {
+                  username: status.username,
+                  role: t(`pages.accounts.roles.${status.role}`),
+                })}
+              </p>
+              <TOTPCard enabled={status.totp_enabled === true} />
+              <APITokensCard
+                role={status.role ?? "viewer"}
+                showOwner={isAdmin}
+              />
+              {isAdmin && (
+                <>
+                  <UsersCard currentUser={status.username ?? ""} />
+                  <AuditCard />
+                </>
+              )}
+            </>
+          )}
+        </div>
+      </div>
+    </div>
+  )
+}
+
+function AuditCard() {
+  const { t } = useTranslation()
+
+  const auditQuery = useQuery({
+    queryKey: ["dashboard-audit"],
+    queryFn: () => getAuditLog(),
+    refetchInterval: 30_000,
+  })
+  const entries = auditQuery.data?.entries ?? []
+
+  return (
+    <Card>
+      <CardContent className="space-y-3">
+        <div className="font-medium">{t("pages.accounts.audit.title")}</div>
+        <p className="text-muted-foreground text-xs">
+          {t("pages.accounts.audit.description")}
+        </p>
+        {auditQuery.isLoading ? (
+          <Skeleton className="h-20 w-full" />
+        ) : auditQuery.isError ? (
+          <p className="text-destructive text-sm">
+            {t("pages.accounts.audit.load_error")}
+          </p>
+        ) : entries.length === 0 ? (
+          <p className="text-muted-foreground text-sm">
+            {t("pages.accounts.audit.empty")}
+          </p>
+        ) : (
+          <div className="divide-border/60 divide-y text-sm">
+            {entries.map((entry) => (
+              <div
+                key={entry.id}
+                className="flex items-center justify-between gap-4 py-2"
+              >
+                <div className="min-w-0">
+                  <div className="truncate font-mono text-xs">
+                    {entry.method} {entry.path}
+                  </div>
+                  <div className="text-muted-foreground text-xs">
+                    {entry.username} ·{" "}
+                    {t(`pages.accounts.audit.via.${entry.via}`)} ·{" "}
+                    {dayjs(entry.time).format("YYYY-MM-DD HH:mm:ss")}
+                  </div>
+                </div>
+                <Badge
+                  variant={entry.status >= 400 ? "destructive" : "secondary"}
+                >
+                  {entry.status}
+                </Badge>
+              </div>
+            ))}
+          </div>
+        )}
+      </CardContent>
+    </Card>
+  )
+}
@@ -0,0 +1,318 @@
+import { IconKey, IconLoader2, IconPlus, IconTrash } from "@tabler/icons-react"
+import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
+import dayjs from "dayjs"
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { toast } from "sonner"
+
+import {
+  type APIToken,
+  createAPIToken,
+  getAPITokens,
+  revokeAPIToken,
+} from "@/api/accounts"
+import type { DashboardRole } from "@/api/launcher-auth"
+import { DeleteConfirmDialog } from "@/components/agent/delete-confirm-dialog"
+import { Field } from "@/components/shared-form"
+import { Badge } from "@/components/ui/badge"
+import { Button } from "@/components/ui/button"
+import { Card, CardContent } from "@/components/ui/card"
+import { Input } from "@/components/ui/input"
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "@/components/ui/select"
+import { Skeleton } from "@/components/ui/skeleton"
+
+const ROLES: DashboardRole[] = ["viewer", "operator", "admin"]
+
+interface APITokensCardProps {
+  /** Role of the signed-in user; tokens cannot exceed it. */
+  role: DashboardRole
+  /** Admins see every user's tokens, so show the owner on each row. */
+  showOwner: boolean
+}
+
+interface TokenForm {
+  name: string
+  role: DashboardRole
+  scopes: string
+  cidrs: string
+  expiresInDays: string
+}
+
+const emptyForm = (role: DashboardRole): TokenForm => ({
+  name: "",
+  role,
+  scopes: "",
+  cidrs: "",
+  expiresInDays: "90",
+})
+
+function splitList(value: string): string[] | undefined {
+  const items = value
+    .split(/[\s,]+/)
+    .map((item) => item.trim())
+    .filter(Boolean)
+  return items.length > 0 ? items : undefined
+}
+
+export function APITokensCard({ role, showOwner }: APITokensCardProps) {
+  const { t } = useTranslation()
+  const queryClient = useQueryClient()
+  const [creating, setCreating] = useState(false)
+  const [form, setForm] = useState<TokenForm>(() => emptyForm(role))
+  const [plaintext, setPlaintext] = useState<string | null>(null)
+  const [pendingRevoke, setPendingRevoke] = useState<APIToken | null>(null)
+
+  const tokensQuery = useQuery({
+    queryKey: ["dashboard-api-tokens"],
+    queryFn: getAPITokens,
+  })
+  const tokens = tokensQuery.data?.tokens ?? []
+  const assignableRoles = ROLES.slice(0, ROLES.indexOf(role) + 1)
+
+  const invalidate = () =>
+    queryClient.invalidateQueries({ queryKey: ["dashboard-api-tokens"] })
+
+  const createMutation = useMutation({
+    mutationFn: () =>
+      createAPIToken({
+        name: form.name.trim(),
+        role: form.role,
+        scopes: splitList(form.scopes),
+        allowed_cidrs: splitList(form.cidrs),
+        expires_in_days: Number(form.expiresInDays) || undefined,
+      }),
+    onSuccess: (created) => {
+      setPlaintext(created.token)
+      setCreating(false)
+      setForm(emptyForm(role))
+      void invalidate()
+    },
+    onError: (error) => {
+      toast.error(
+        error instanceof Error
+          ? error.message
+          : t("pages.accounts.tokens.create_error"),
+      )
+    },
+  })
+
+  const revokeMutation = useMutation({
+    mutationFn: (token: APIToken) => revokeAPIToken(token.id),
+    onSuccess: () => {
+      toast.success(t("pages.accounts.tokens.revoke_success"))
+      setPendingRevoke(null)
+      void invalidate()
+    },
+    onError: (error) => {
+      toast.error(
+        error instanceof Error
+          ? error.message
+          : t("pages.accounts.tokens.revoke_error"),
+      )
+    },
+  })
+
+  const setField =
+    (key: keyof TokenForm) => (e: React.ChangeEvent<HTMLInputElement>) =>
+      setForm((f) => ({ ...f, [key]: e.target.value }))
+
+  return (
+    <Card>
+      <CardContent className="space-y-4">
+        <div className="flex items-center justify-between gap-4">
+          <div className="flex items-center gap-2 font-medium">
+            <IconKey className="size-4" />
+            {t("pages.accounts.tokens.title")}
+          </div>
+          {!creating && (
+            <Button
+              variant="outline"
+              size="sm"
+              onClick={() => {
+                setPlaintext(null)
+                setCreating(true)
+              }}
+            >
+              <IconPlus className="size-4" />
+              {t("pages.accounts.tokens.add")}
+            </Button>
+          )}
+        </div>
+        <p className="text-muted-foreground text-xs">
+          {t("pages.accounts.tokens.description")}
+        </p>
+
+        {plaintext && (
+          <div className="bg-muted/50 space-y-1 rounded-lg p-3 text-xs">
+            <div>{t("pages.accounts.tokens.copy_once")}</div>
+            <div className="font-mono break-all select-all">{plaintext}</div>
+          </div>
+        )}
+
+        {creating && (
+          <div className="space-y-4 rounded-lg border p-4">
+            <Field label={t("pages.accounts.tokens.field.name")} required>
+              <Input
+                value={form.name}
+                onChange={setField("name")}
+                placeholder="ci-deploy"
+              />
+            </Field>
+            <Field label={t("pages.accounts.tokens.field.role")}>
+              <Select
+                value={form.role}
+                onValueChange={(value) =>
+                  setForm((f) => ({ ...f, role: value as DashboardRole }))
+                }
+              >
+                <SelectTrigger className="w-full">
+                  <SelectValue />
+                </SelectTrigger>
+                <SelectContent>
+                  {assignableRoles.map((r) => (
+                    <SelectItem key={r} value={r}>
+                      {t(`pages.accounts.roles.${r}`)}
+                    </SelectItem>
+                  ))}
+                </SelectContent>
+              </Select>
+            </Field>
+            <Field
+              label={t("pages.accounts.tokens.field.scopes")}
+              hint={t("pages.accounts.tokens.field.scopes_hint")}
+            >
+              <Input
+                value={form.scopes}
+                onChange={setField("scopes")}
+                placeholder="gateway, sessions"
+              />
+            </Field>
+            <Field
+              label={t("pages.accounts.tokens.field.cidrs")}
+              hint={t("pages.accounts.tokens.field.cidrs_hint")}
+            >
+              <Input
+                value={form.cidrs}
+                onChange={setField("cidrs")}
+                placeholder="10.0.0.0/8"
+              />
+            </Field>
+            <Field
+              label={t("pages.accounts.tokens.field.expires")}
+              hint={t("pages.accounts.tokens.field.expires_hint")}
+            >
+              <Input
+                value={form.expiresInDays}
+                onChange={setField("expiresInDays")}
+                inputMode="numeric"
+              />
+            </Field>
+            <div className="flex justify-end gap-2">
+              <Button
+                variant="ghost"
+                size="sm"
+                onClick={() => setCreating(false)}
+              >
+                {t("common.cancel")}
+              </Button>
+              <Button
+                size="sm"
+                disabled={!form.name.trim() || createMutation.isPending}
+                onClick={() => createMutation.mutate()}
+              >
+                {createMutation.isPending && (
+                  <IconLoader2 className="size-4 animate-spin" />
+                )}
+                {t("pages.accounts.tokens.create")}
+              </Button>
+            </div>
+          </div>
+        )}
+
+        {tokensQuery.isLoading ? (
+          <Skeleton className="h-16 w-full" />
+        ) : tokensQuery.isError ? (
+          <p className="text-destructive text-sm">
+            {t("pages.accounts.tokens.load_error")}
+          </p>
+        ) : tokens.length === 0 ? (
+          <p className="text-muted-foreground text-sm">
+            {t("pages.accounts.tokens.empty")}
+          </p>
+        ) : (
+          <div className="divide-border/60 divide-y">
+            {tokens.map((token) => (
+              <div
+                key={token.id}
+                className="flex items-center justify-between gap-4 py-2"
+              >
+                <div className="min-w-0 space-y-1">
+                  <div className="flex items-center gap-2 text-sm">
+                    <span className="truncate font-medium">{token.name}</span>
+                    <Badge variant="secondary">
+                      {t(`pages.accounts.roles.${token.role}`)}
+                    </Badge>
+                    {showOwner && (
+                      <span className="text-muted-foreground text-xs">
+                        {token.username}
+                      </span>
+                    )}
+                  </div>
+                  <div className="text-muted-foreground text-xs">
+                    {t("pages.accounts.tokens.scopes", {
+                      scopes:
+                        token.scopes?.join(", ") ??
+                        t("pages.accounts.tokens.all_scopes"),
+                    })}
+                    {token.allowed_cidrs &&
+                      ` · ${token.allowed_cidrs.join(", ")}`}
+                  </div>
+                  <div className="text-muted-foreground text-xs">
+                    {token.last_used_at
+                      ? t("pages.accounts.tokens.last_used", {
+                          time: dayjs(token.last_used_at).format(
+                            "YYYY-MM-DD HH:mm",
+                          ),
+                        })
+                      : t("pages.accounts.tokens.never_used")}
+                    {" · "}
+                    {token.expires_at
+                      ? t("pages.accounts.tokens.expires", {
+                          time: dayjs(token.expires_at).format("YYYY-MM-DD"),
+                        })
+                      : t("pages.accounts.tokens.no_expiry")}
+                  </div>
+                </div>
+                <Button
+                  variant="ghost"
+                  size="icon-sm"
+                  onClick={() => setPendingRevoke(token)}
+                >
+                  <IconTrash className="size-4" />
+                </Button>
+              </div>
+            ))}
+          </div>
+        )}
+      </CardContent>
+
+      <DeleteConfirmDialog
+        open={pendingRevoke !== null}
+        title={t("pages.accounts.tokens.revoke_title")}
+        description={t("pages.accounts.tokens.revoke_description", {
+          name: pendingRevoke?.name ?? "",
+        })}
+        confirmLabel={t("pages.accounts.tokens.revoke")}
+        isPending={revokeMutation.isPending}
+        onOpenChange={(open) => !open && setPendingRevoke(null)}
+        onConfirm={() => pendingRevoke && revokeMutation.mutate(pendingRevoke)}
+      />
+    </Card>
+  )
+}
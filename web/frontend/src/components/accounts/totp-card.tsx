@@ -0,0 +1,142 @@
+import { IconLoader2, IconShieldLock } from "@tabler/icons-react"
+import { useMutation, useQueryClient } from "@tanstack/react-query"
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { toast } from "sonner"
+
+import {
+  type TOTPEnrollment,
+  confirmTOTP,
+  disableTOTP,
+  enrollTOTP,
+} from "@/api/accounts"
+import { Field } from "@/components/shared-form"
+import { Badge } from "@/components/ui/badge"
+import { Button } from "@/components/ui/button"
+import { Card, CardContent } from "@/components/ui/card"
+import { Input } from "@/components/ui/input"
+
+interface TOTPCardProps {
+  enabled: boolean
+}
+
+export function TOTPCard({ enabled }: TOTPCardProps) {
+  const { t } = useTranslation()
+  const queryClient = useQueryClient()
+  const [enrollment, setEnrollment] = useState<TOTPEnrollment | null>(null)
+  const [code, setCode] = useState("")
+
+  const onDone = (message: string) => {
+    toast.success(message)
+    setEnrollment(null)
+    setCode("")
+    void queryClient.invalidateQueries({ queryKey: ["launcher-auth-status"] })
+  }
+  const onError = (error: unknown) => {
+    toast.error(
+      error instanceof Error ? error.message : t("pages.accounts.totp.error"),
+    )
+  }
+
+  const enrollMutation = useMutation({
+    mutationFn: enrollTOTP,
+    onSuccess: setEnrollment,
+    onError,
+  })
+  const confirmMutation = useMutation({
+    mutationFn: confirmTOTP,
+    onSuccess: () => onDone(t("pages.accounts.totp.enabled_success")),
+    onError,
+  })
+  const disableMutation = useMutation({
+    mutationFn: disableTOTP,
+    onSuccess: () => onDone(t("pages.accounts.totp.disabled_success")),
+    onError,
+  })
+
+  const pending = confirmMutation.isPending || disableMutation.isPending
+
+  return (
+    <Card>
+      <CardContent className="space-y-4">
+        <div className="flex items-center justify-between gap-4">
+          <div className="flex items-center gap-2 font-medium">
+            <IconShieldLock className="size-4" />
+            {t("pages.accounts.totp.title")}
+          </div>
+          <Badge variant={enabled ? "default" : "outline"}>
+            {enabled
+              ? t("pages.accounts.totp.status_on")
+              : t("pages.accounts.totp.status_off")}
+          </Badge>
+        </div>
+        <p className="text-muted-foreground text-xs">
+          {t("pages.accounts.totp.description")}
+        </p>
+
+        {enrollment && (
+          <div className="bg-muted/50 space-y-2 rounded-lg p-3 text-xs">
+            <div>{t("pages.accounts.totp.enroll_hint")}</div>
+            <div className="font-mono break-all select-all">
+              {enrollment.secret}
+            </div>
+            <div className="text-muted-foreground font-mono break-all select-all">
+              {enrollment.uri}
+            </div>
+          </div>
+        )}
+
+        {(enabled || enrollment) && (
+          <Field label={t("pages.accounts.totp.code")}>
+            <Input
+              value={code}
+              onChange={(e) => setCode(e.target.value.trim())}
+              inputMode="numeric"
+              autoComplete="one-time-code"
+              maxLength={6}
+              placeholder="123456"
+            />
+          </Field>
+        )}
+
+        <div className="flex justify-end gap-2">
+          {enabled ? (
+            <Button
+              variant="outline"
+              size="sm"
+              disabled={pending || code.length !== 6}
+              onClick={() => disableMutation.mutate(code)}
+            >
+              {disableMutation.isPending && (
+                <IconLoader2 className="size-4 animate-spin" />
+              )}
+              {t("pages.accounts.totp.disable")}
+            </Button>
+          ) : enrollment ? (
+            <Button
+              size="sm"
+              disabled={pending || code.length !== 6}
+              onClick={() => confirmMutation.mutate(code)}
+            >
+              {confirmMutation.isPending && (
+                <IconLoader2 className="size-4 animate-spin" />
+              )}
+              {t("pages.accounts.totp.confirm")}
+            </Button>
+          ) : (
+            <Button
+              size="sm"
+              disabled={enrollMutation.isPending}
+              onClick={() => enrollMutation.mutate()}
+            >
+              {enrollMutation.isPending && (
+                <IconLoader2 className="size-4 animate-spin" />
+              )}
+              {t("pages.accounts.totp.enable")}
+            </Button>
+          )}
+        </div>
+      </CardContent>
+    </Card>
+  )
+}
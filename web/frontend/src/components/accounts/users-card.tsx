@@ -0,0 +1,340 @@
+import {
+  IconLoader2,
+  IconPlus,
+  IconShieldOff,
+  IconTrash,
+  IconUsers,
+} from "@tabler/icons-react"
+import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { toast } from "sonner"
+
+import {
+  type DashboardUser,
+  createUser,
+  deleteUser,
+  getUsers,
+  resetUserTOTP,
+  updateUser,
+} from "@/api/accounts"
+import type { DashboardRole } from "@/api/launcher-auth"
+import { DeleteConfirmDialog } from "@/components/agent/delete-confirm-dialog"
+import { Field } from "@/components/shared-form"
+import { Badge } from "@/components/ui/badge"
+import { Button } from "@/components/ui/button"
+import { Card, CardContent } from "@/components/ui/card"
+import { Input } from "@/components/ui/input"
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "@/components/ui/select"
+import { Skeleton } from "@/components/ui/skeleton"
+
+const ROLES: DashboardRole[] = ["viewer", "operator", "admin"]
+
+interface UsersCardProps {
+  currentUser: string
+}
+
+function RoleSelect({
+  value,
+  disabled,
+  onChange,
+}: {
+  value: DashboardRole
+  disabled?: boolean
+  onChange: (role: DashboardRole) => void
+}) {
+  const { t } = useTranslation()
+  return (
+    <Select
+      value={value}
+      disabled={disabled}
+      onValueChange={(role) => onChange(role as DashboardRole)}
+    >
+      <SelectTrigger className="w-32">
+        <SelectValue />
+      </SelectTrigger>
+      <SelectContent>
+        {ROLES.map((role) => (
+          <SelectItem key={role} value={role}>
+            {t(`pages.accounts.roles.${role}`)}
+          </SelectItem>
+        ))}
+      </SelectContent>
+    </Select>
+  )
+}
+
+export function UsersCard({ currentUser }: UsersCardProps) {
+  const { t } = useTranslation()
+  const queryClient = useQueryClient()
+  const [creating, setCreating] = useState(false)
+  const [username, setUsername] = useState("")
+  const [password, setPassword] = useState("")
+  const [role, setRole] = useState<DashboardRole>("viewer")
+  const [pendingDelete, setPendingDelete] = useState<DashboardUser | null>(
+    null,
+  )
+  const [resetting, setResetting] = useState<string | null>(null)
+  const [newPassword, setNewPassword] = useState("")
+
+  const usersQuery = useQuery({
+    queryKey: ["dashboard-users"],
+    queryFn: getUsers,
+  })
+  const users = usersQuery.data?.users ?? []
+
+  const invalidate = () =>
+    queryClient.invalidateQueries({ queryKey: ["dashboard-users"] })
+  const onError = (error: unknown) => {
+    toast.error(
+      error instanceof Error ? error.message : t("pages.accounts.users.error"),
+    )
+  }
+
+  const createMutation = useMutation({
+    mutationFn: () =>
+      createUser({ username: username.trim(), password, role }),
+    onSuccess: () => {
+      toast.success(t("pages.accounts.users.create_success"))
+      setCreating(false)
+      setUsername("")
+      setPassword("")
+      setRole("viewer")
+      void invalidate()
+    },
+    onError,
+  })
+
+  const updateMutation = useMutation({
+    mutationFn: ({
+      name,
+      input,
+    }: {
+      name: string
+      input: { role?: DashboardRole; password?: string }
+    }) => updateUser(name, input),
+    onSuccess: () => {
+      toast.success(t("pages.accounts.users.update_success"))
+      setResetting(null)
+      setNewPassword("")
+      void invalidate()
+    },
+    onError,
+  })
+
+  const resetTOTPMutation = useMutation({
+    mutationFn: resetUserTOTP,
+    onSuccess: () => {
+      toast.success(t("pages.accounts.users.totp_reset_success"))
+      void invalidate()
+    },
+    onError,
+  })
+
+  const deleteMutation = useMutation({
+    mutationFn: (user: DashboardUser) => deleteUser(user.username),
+    onSuccess: () => {
+      toast.success(t("pages.accounts.users.delete_success"))
+      setPendingDelete(null)
+      void invalidate()
+    },
+    onError,
+  })
+
+  return (
+    <Card>
+      <CardContent className="space-y-4">
+        <div className="flex items-center justify-between gap-4">
+          <div className="flex items-center gap-2 font-medium">
+            <IconUsers className="size-4" />
+            {t("pages.accounts.users.title")}
+          </div>
+          {!creating && (
+            <Button
+              variant="outline"
+              size="sm"
+              onClick={() => setCreating(true)}
+            >
+              <IconPlus className="size-4" />
+              {t("pages.accounts.users.add")}
+            </Button>
+          )}
+        </div>
+        <p className="text-muted-foreground text-xs">
+          {t("pages.accounts.users.description")}
+        </p>
+
+        {creating && (
+          <div className="space-y-4 rounded-lg border p-4">
+            <Field label={t("pages.accounts.users.field.username")} required>
+              <Input
+                value={username}
+                onChange={(e) => setUsername(e.target.value)}
+                autoComplete="off"
+              />
+            </Field>
+            <Field
+              label={t("pages.accounts.users.field.password")}
+              hint={t("pages.accounts.users.field.password_hint")}
+              required
+            >
+              <Input
+                type="password"
+                value={password}
+                onChange={(e) => setPassword(e.target.value)}
+                autoComplete="new-password"
+              />
+            </Field>
+            <Field label={t("pages.accounts.users.field.role")}>
+              <RoleSelect value={role} onChange={setRole} />
+            </Field>
+            <div className="flex justify-end gap-2">
+              <Button
+                variant="ghost"
+                size="sm"
+                onClick={() => setCreating(false)}
+              >
+                {t("common.cancel")}
+              </Button>
+              <Button
+                size="sm"
+                disabled={
+                  !username.trim() ||
+                  password.length < 8 ||
+                  createMutation.isPending
+                }
+                onClick={() => createMutation.mutate()}
+              >
+                {createMutation.isPending && (
+                  <IconLoader2 className="size-4 animate-spin" />
+                )}
+                {t("pages.accounts.users.create")}
+              </Button>
+            </div>
+          </div>
+        )}
+
+        {usersQuery.isLoading ? (
+          <Skeleton className="h-16 w-full" />
+        ) : usersQuery.isError ? (
+          <p className="text-destructive text-sm">
+            {t("pages.accounts.users.load_error")}
+          </p>
+        ) : (
+          <div className="divide-border/60 divide-y">
+            {users.map((user) => {
+              const isSelf =
+                user.username.toLowerCase() === currentUser.toLowerCase()
+              return (
+                <div key={user.id} className="space-y-2 py-2">
+                  <div className="flex items-center justify-between gap-4">
+                    <div className="flex min-w-0 items-center gap-2 text-sm">
+                      <span className="truncate font-medium">
+                        {user.username}
+                      </span>
+                      {isSelf && (
+                        <Badge variant="outline">
+                          {t("pages.accounts.users.you")}
+                        </Badge>
+                      )}
+                      {user.totp_enabled && (
+                        <Badge variant="secondary">TOTP</Badge>
+                      )}
+                    </div>
+                    <div className="flex items-center gap-1">
+                      <RoleSelect
+                        value={user.role}
+                        disabled={updateMutation.isPending}
+                        onChange={(next) =>
+                          updateMutation.mutate({
+                            name: user.username,
+                            input: { role: next },
+                          })
+                        }
+                      />
+                      <Button
+                        variant="ghost"
+                        size="sm"
+                        onClick={() =>
+                          setResetting(
+                            resetting === user.username ? null : user.username,
+                          )
+                        }
+                      >
+                        {t("pages.accounts.users.reset_password")}
+                      </Button>
+                      {user.totp_enabled && (
+                        <Button
+                          variant="ghost"
+                          size="icon-sm"
+                          title={t("pages.accounts.users.reset_totp")}
+                          disabled={resetTOTPMutation.isPending}
+                          onClick={() => resetTOTPMutation.mutate(user.username)}
+                        >
+                          <IconShieldOff className="size-4" />
+                        </Button>
+                      )}
+                      <Button
+                        variant="ghost"
+                        size="icon-sm"
+                        disabled={isSelf}
+                        onClick={() => setPendingDelete(user)}
+                      >
+                        <IconTrash className="size-4" />
+                      </Button>
+                    </div>
+                  </div>
+                  {resetting === user.username && (
+                    <div className="flex items-center gap-2">
+                      <Input
+                        type="password"
+                        value={newPassword}
+                        onChange={(e) => setNewPassword(e.target.value)}
+                        placeholder={t(
+                          "pages.accounts.users.field.password_hint",
+                        )}
+                        autoComplete="new-password"
+                      />
+                      <Button
+                        size="sm"
+                        disabled={
+                          newPassword.length < 8 || updateMutation.isPending
+                        }
+                        onClick={() =>
+                          updateMutation.mutate({
+                            name: user.username,
+                            input: { password: newPassword },
+                          })
+                        }
+                      >
+                        {t("common.save")}
+                      </Button>
+                    </div>
+                  )}
+                </div>
+              )
+            })}
+          </div>
+        )}
+      </CardContent>
+
+      <DeleteConfirmDialog
+        open={pendingDelete !== null}
+        title={t("pages.accounts.users.delete_title")}
+        description={t("pages.accounts.users.delete_description", {
+          username: pendingDelete?.username ?? "",
+        })}
+        confirmLabel={t("pages.accounts.users.delete")}
+        isPending={deleteMutation.isPending}
+        onOpenChange={(open) => !open && setPendingDelete(null)}
+        onConfirm={() => pendingDelete && deleteMutation.mutate(pendingDelete)}
+      />
+    </Card>
+  )
+}
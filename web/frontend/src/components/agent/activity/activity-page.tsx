@@ -0,0 +1,155 @@
+import { IconPlayerPause, IconPlayerPlay, IconTrash } from "@tabler/icons-react"
+import dayjs from "dayjs"
+import { useDeferredValue, useState } from "react"
+import { useTranslation } from "react-i18next"
+
+import type { AgentEvent } from "@/api/agent-events"
+import { PageHeader } from "@/components/page-header"
+import { Badge } from "@/components/ui/badge"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+
+import { type AgentEventStreamStatus, useAgentEvents } from "./use-agent-events"
+
+function kindVariant(event: AgentEvent) {
+  if (
+    event.kind === "error" ||
+    event.data?.status === "error" ||
+    event.data?.is_error === true
+  ) {
+    return "destructive" as const
+  }
+  if (event.kind === "turn_start" || event.kind === "turn_end") {
+    return "default" as const
+  }
+  return "secondary" as const
+}
+
+function summarize(event: AgentEvent): string {
+  if (!event.data) {
+    return ""
+  }
+  return Object.entries(event.data)
+    .map(([key, value]) => `${key}=${String(value)}`)
+    .join(" ")
+}
+
+function StatusDot({ status }: { status: AgentEventStreamStatus }) {
+  const { t } = useTranslation()
+  const color =
+    status === "open"
+      ? "bg-green-500"
+      : status === "error"
+        ? "bg-destructive"
+        : "bg-yellow-500"
+  return (
+    <span className="text-muted-foreground flex items-center gap-2 text-sm">
+      <span className={`size-2 rounded-full ${color}`} />
+      {t(`pages.agent.activity.status.${status}`)}
+    </span>
+  )
+}
+
+export function ActivityPage() {
+  const { t } = useTranslation()
+  const [session, setSession] = useState("")
+  const [agent, setAgent] = useState("")
+  const [paused, setPaused] = useState(false)
+  const filter = {
+    session: useDeferredValue(session),
+    agent: useDeferredValue(agent),
+  }
+  const { events, status, clear } = useAgentEvents(filter, paused)
+
+  return (
+    <div className="flex h-full flex-col">
+      <PageHeader
+        title={t("navigation.activity")}
+        titleExtra={!paused && <StatusDot status={status} />}
+        children={
+          <>
+            <Button
+              variant="outline"
+              size="sm"
+              onClick={() => setPaused((p) => !p)}
+            >
+              {paused ? (
+                <IconPlayerPlay className="size-4" />
+              ) : (
+                <IconPlayerPause className="size-4" />
+              )}
+              {paused
+                ? t("pages.agent.activity.resume")
+                : t("pages.agent.activity.pause")}
+            </Button>
+            <Button
+              variant="outline"
+              size="sm"
+              onClick={clear}
+              disabled={events.length === 0}
+            >
+              <IconTrash className="size-4" />
+              {t("pages.agent.activity.clear")}
+            </Button>
+          </>
+        }
+      />
+
+      <div className="flex flex-1 flex-col gap-4 overflow-hidden p-4 sm:p-8">
+        <div className="flex flex-col gap-2 sm:flex-row">
+          <Input
+            value={session}
+            onChange={(e) => setSession(e.target.value)}
+            placeholder={t("pages.agent.activity.filter_session")}
+          />
+          <Input
+            value={agent}
+            onChange={(e) => setAgent(e.target.value)}
+            placeholder={t("pages.agent.activity.filter_agent")}
+            className="sm:max-w-xs"
+          />
+        </div>
+
+        <div className="border-border/60 flex-1 overflow-auto rounded-xl border">
+          {events.length === 0 ? (
+            <p className="text-muted-foreground py-16 text-center text-sm">
+              {t("pages.agent.activity.empty")}
+            </p>
+          ) : (
+            <ol className="divide-border/60 divide-y">
+              {events.map((event, index) => (
+                <li
+                  key={`${event.time}-${event.turn_id}-${index}`}
+                  className="flex flex-col gap-1 px-4 py-2 text-sm sm:flex-row sm:items-baseline sm:gap-4"
+                >
+                  <span className="text-muted-foreground shrink-0 font-mono text-xs">
+                    {dayjs(event.time).format("HH:mm:ss.SSS")}
+                  </span>
+                  <Badge variant={kindVariant(event)}>{event.kind}</Badge>
+                  <span className="text-muted-foreground shrink-0 text-xs">
+                    {[event.agent_id, event.channel, event.chat_id]
+                      .filter(Boolean)
+                      .join(" · ")}
+                  </span>
+                  <span className="min-w-0 truncate font-mono text-xs">
+                    {summarize(event)}
+                  </span>
+                  {event.session_key && (
+                    <button
+                      type="button"
+                      className="text-muted-foreground hover:text-foreground ml-auto shrink-0 font-mono text-xs"
+                      title={t("pages.agent.activity.filter_by_session")}
+                      onClick={() => setSession(event.session_key ?? "")}
+                    >
+                      {event.session_key.slice(0, 14)}
+                    </button>
+                  )}
+                </li>
+              ))}
+            </ol>
+          )}
+        </div>
+      </div>
+    </div>
+  )
+}
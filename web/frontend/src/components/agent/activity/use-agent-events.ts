@@ -0,0 +1,38 @@
+import { useEffect, useState } from "react"
+
+import {
+  type AgentEvent,
+  type AgentEventFilter,
+  agentEventsURL,
+} from "@/api/agent-events"
+
+const MAX_EVENTS = 500
+
+export type AgentEventStreamStatus = "connecting" | "open" | "error"
+
+export function useAgentEvents(filter: AgentEventFilter, paused: boolean) {
+  const [events, setEvents] = useState<AgentEvent[]>([])
+  const [status, setStatus] = useState<AgentEventStreamStatus>("connecting")
+  const url = agentEventsURL(filter)
+
+  useEffect(() => {
+    if (paused) {
+      return
+    }
+    setStatus("connecting")
+    const source = new EventSource(url, { withCredentials: true })
+    source.onopen = () => setStatus("open")
+    source.onerror = () => setStatus("error")
+    source.onmessage = (message) => {
+      try {
+        const event = JSON.parse(message.data) as AgentEvent
+        setEvents((prev) => [event, ...prev].slice(0, MAX_EVENTS))
+      } catch {
+        // ignore malformed events
+      }
+    }
+    return () => source.close()
+  }, [url, paused])
+
+  return { events, status, clear: () => setEvents([]) }
+}
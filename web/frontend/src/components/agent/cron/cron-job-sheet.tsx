@@ -0,0 +1,355 @@
+import { IconLoader2 } from "@tabler/icons-react"
+import dayjs from "dayjs"
+import { useEffect, useState } from "react"
+import { useTranslation } from "react-i18next"
+
+import {
+  type CronJob,
+  type CronJobInput,
+  type CronSchedule,
+  createCronJob,
+  updateCronJob,
+} from "@/api/cron"
+import { AdvancedSection, Field } from "@/components/shared-form"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "@/components/ui/select"
+import {
+  Sheet,
+  SheetContent,
+  SheetDescription,
+  SheetFooter,
+  SheetHeader,
+  SheetTitle,
+} from "@/components/ui/sheet"
+import { Textarea } from "@/components/ui/textarea"
+
+interface CronForm {
+  name: string
+  kind: CronSchedule["kind"]
+  everySeconds: string
+  expr: string
+  at: string
+  message: string
+  channel: string
+  to: string
+  maxRetries: string
+  timeoutSeconds: string
+  concurrency: string
+  catchUp: string
+}
+
+const EMPTY_FORM: CronForm = {
+  name: "",
+  kind: "cron",
+  everySeconds: "3600",
+  expr: "0 9 * * *",
+  at: "",
+  message: "",
+  channel: "",
+  to: "",
+  maxRetries: "",
+  timeoutSeconds: "",
+  concurrency: "default",
+  catchUp: "default",
+}
+
+function formFromJob(job: CronJob): CronForm {
+  const policy = job.policy ?? {}
+  return {
+    name: job.name,
+    kind: job.schedule.kind,
+    everySeconds: job.schedule.everyMs
+      ? String(Math.round(job.schedule.everyMs / 1000))
+      : EMPTY_FORM.everySeconds,
+    expr: job.schedule.expr ?? EMPTY_FORM.expr,
+    at: job.schedule.atMs
+      ? dayjs(job.schedule.atMs).format("YYYY-MM-DDTHH:mm")
+      : "",
+    message: job.payload.message,
+    channel: job.payload.channel ?? "",
+    to: job.payload.to ?? "",
+    maxRetries: policy.maxRetries ? String(policy.maxRetries) : "",
+    timeoutSeconds: policy.timeoutMs
+      ? String(Math.round(policy.timeoutMs / 1000))
+      : "",
+    concurrency: policy.concurrency || "default",
+    catchUp: policy.catchUp || "default",
+  }
+}
+
+function inputFromForm(form: CronForm): CronJobInput {
+  let schedule: CronSchedule
+  switch (form.kind) {
+    case "every":
+      schedule = { kind: "every", everyMs: Number(form.everySeconds) * 1000 }
+      break
+    case "at":
+      schedule = { kind: "at", atMs: dayjs(form.at).valueOf() }
+      break
+    default:
+      schedule = { kind: "cron", expr: form.expr.trim() }
+  }
+  return {
+    name: form.name.trim(),
+    schedule,
+    message: form.message,
+    channel: form.channel.trim() || undefined,
+    to: form.to.trim() || undefined,
+    policy: {
+      maxRetries: form.maxRetries ? Number(form.maxRetries) : undefined,
+      timeoutMs: form.timeoutSeconds
+        ? Number(form.timeoutSeconds) * 1000
+        : undefined,
+      concurrency:
+        form.concurrency === "default"
+          ? undefined
+          : (form.concurrency as "skip" | "queue" | "allow"),
+      catchUp:
+        form.catchUp === "default"
+          ? undefined
+          : (form.catchUp as "skip" | "once"),
+    },
+  }
+}
+
+interface CronJobSheetProps {
+  open: boolean
+  job: CronJob | null
+  onClose: () => void
+  onSaved: () => void
+}
+
+export function CronJobSheet({
+  open,
+  job,
+  onClose,
+  onSaved,
+}: CronJobSheetProps) {
+  const { t } = useTranslation()
+  const [form, setForm] = useState<CronForm>(EMPTY_FORM)
+  const [saving, setSaving] = useState(false)
+  const [serverError, setServerError] = useState("")
+
+  useEffect(() => {
+    if (open) {
+      setForm(job ? formFromJob(job) : EMPTY_FORM)
+      setServerError("")
+    }
+  }, [open, job])
+
+  const setField =
+    (key: keyof CronForm) =>
+    (e: React.ChangeEvent<HTMLInputElement | HTMLTextAreaElement>) =>
+      setForm((f) => ({ ...f, [key]: e.target.value }))
+
+  const canSave =
+    form.name.trim() !== "" &&
+    form.message.trim() !== "" &&
+    (form.kind !== "at" || form.at !== "")
+
+  const handleSave = async () => {
+    setSaving(true)
+    setServerError("")
+    try {
+      const input = inputFromForm(form)
+      if (job) {
+        await updateCronJob(job.id, input)
+      } else {
+        await createCronJob(input)
+      }
+      onSaved()
+      onClose()
+    } catch (e) {
+      setServerError(
+        e instanceof Error ? e.message : t("pages.agent.cron.save_error"),
+      )
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  return (
+    <Sheet open={open} onOpenChange={(v) => !v && onClose()}>
+      <SheetContent
+        side="right"
+        className="flex flex-col gap-0 p-0 data-[side=right]:!w-full data-[side=right]:sm:!w-[560px] data-[side=right]:sm:!max-w-[560px]"
+      >
+        <SheetHeader className="border-b-muted border-b px-6 py-5">
+          <SheetTitle className="text-base">
+            {job
+              ? t("pages.agent.cron.edit_title")
+              : t("pages.agent.cron.add_title")}
+          </SheetTitle>
+          <SheetDescription className="text-xs">
+            {t("pages.agent.cron.sheet_description")}
+          </SheetDescription>
+        </SheetHeader>
+
+        <div className="min-h-0 flex-1 overflow-y-auto">
+          <div className="space-y-5 px-6 py-5">
+            <Field label={t("pages.agent.cron.field.name")} required>
+              <Input value={form.name} onChange={setField("name")} />
+            </Field>
+
+            <Field label={t("pages.agent.cron.field.schedule")} required>
+              <Select
+                value={form.kind}
+                onValueChange={(kind) =>
+                  setForm((f) => ({ ...f, kind: kind as CronForm["kind"] }))
+                }
+              >
+                <SelectTrigger className="w-full">
+                  <SelectValue />
+                </SelectTrigger>
+                <SelectContent>
+                  <SelectItem value="cron">
+                    {t("pages.agent.cron.kind.cron")}
+                  </SelectItem>
+                  <SelectItem value="every">
+                    {t("pages.agent.cron.kind.every")}
+                  </SelectItem>
+                  <SelectItem value="at">
+                    {t("pages.agent.cron.kind.at")}
+                  </SelectItem>
+                </SelectContent>
+              </Select>
+              {form.kind === "cron" && (
+                <Input
+                  value={form.expr}
+                  onChange={setField("expr")}
+                  placeholder="0 9 * * *"
+                  className="font-mono text-sm"
+                />
+              )}
+              {form.kind === "every" && (
+                <Input
+                  type="number"
+                  min={1}
+                  value={form.everySeconds}
+                  onChange={setField("everySeconds")}
+                  placeholder={t("pages.agent.cron.field.every_placeholder")}
+                />
+              )}
+              {form.kind === "at" && (
+                <Input
+                  type="datetime-local"
+                  value={form.at}
+                  onChange={setField("at")}
+                />
+              )}
+            </Field>
+
+            <Field
+              label={t("pages.agent.cron.field.message")}
+              hint={t("pages.agent.cron.field.message_hint")}
+              required
+            >
+              <Textarea
+                value={form.message}
+                onChange={setField("message")}
+                rows={4}
+              />
+            </Field>
+
+            <AdvancedSection>
+              <Field
+                label={t("pages.agent.cron.field.channel")}
+                hint={t("pages.agent.cron.field.channel_hint")}
+              >
+                <Input value={form.channel} onChange={setField("channel")} />
+              </Field>
+              <Field label={t("pages.agent.cron.field.to")}>
+                <Input value={form.to} onChange={setField("to")} />
+              </Field>
+              <Field label={t("pages.agent.cron.field.retries")}>
+                <Input
+                  type="number"
+                  min={0}
+                  value={form.maxRetries}
+                  onChange={setField("maxRetries")}
+                />
+              </Field>
+              <Field
+                label={t("pages.agent.cron.field.timeout")}
+                hint={t("pages.agent.cron.field.timeout_hint")}
+              >
+                <Input
+                  type="number"
+                  min={0}
+                  value={form.timeoutSeconds}
+                  onChange={setField("timeoutSeconds")}
+                />
+              </Field>
+              <Field label={t("pages.agent.cron.field.concurrency")}>
+                <PolicySelect
+                  value={form.concurrency}
+                  options={["default", "skip", "queue", "allow"]}
+                  onChange={(concurrency) =>
+                    setForm((f) => ({ ...f, concurrency }))
+                  }
+                />
+              </Field>
+              <Field label={t("pages.agent.cron.field.catch_up")}>
+                <PolicySelect
+                  value={form.catchUp}
+                  options={["default", "skip", "once"]}
+                  onChange={(catchUp) => setForm((f) => ({ ...f, catchUp }))}
+                />
+              </Field>
+            </AdvancedSection>
+
+            {serverError && (
+              <p className="text-destructive bg-destructive/10 rounded-md px-3 py-2 text-sm">
+                {serverError}
+              </p>
+            )}
+          </div>
+        </div>
+
+        <SheetFooter className="border-t-muted border-t px-6 py-4">
+          <Button variant="ghost" onClick={onClose} disabled={saving}>
+            {t("common.cancel")}
+          </Button>
+          <Button onClick={handleSave} disabled={!canSave || saving}>
+            {saving && <IconLoader2 className="size-4 animate-spin" />}
+            {t("common.save")}
+          </Button>
+        </SheetFooter>
+      </SheetContent>
+    </Sheet>
+  )
+}
+
+function PolicySelect({
+  value,
+  options,
+  onChange,
+}: {
+  value: string
+  options: string[]
+  onChange: (value: string) => void
+}) {
+  const { t } = useTranslation()
+
+  return (
+    <Select value={value} onValueChange={onChange}>
+      <SelectTrigger className="w-full">
+        <SelectValue />
+      </SelectTrigger>
+      <SelectContent>
+        {options.map((option) => (
+          <SelectItem key={option} value={option}>
+            {t(`pages.agent.cron.policy.${option}`)}
+          </SelectItem>
+        ))}
+      </SelectContent>
+    </Select>
+  )
+}
@@ -0,0 +1,320 @@
+import {
+  IconChevronDown,
+  IconChevronRight,
+  IconLoader2,
+  IconPencil,
+  IconPlayerPlay,
+  IconPlus,
+  IconTrash,
+} from "@tabler/icons-react"
+import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
+import dayjs from "dayjs"
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { toast } from "sonner"
+
+import {
+  type CronJob,
+  type CronSchedule,
+  deleteCronJob,
+  getCronHistory,
+  getCronJobs,
+  runCronJob,
+  setCronJobEnabled,
+} from "@/api/cron"
+import { CronJobSheet } from "@/components/agent/cron/cron-job-sheet"
+import { DeleteConfirmDialog } from "@/components/agent/delete-confirm-dialog"
+import { PageHeader } from "@/components/page-header"
+import { Badge } from "@/components/ui/badge"
+import { Button } from "@/components/ui/button"
+import { Card, CardContent } from "@/components/ui/card"
+import { Skeleton } from "@/components/ui/skeleton"
+import { Switch } from "@/components/ui/switch"
+
+const TIME_FORMAT = "YYYY-MM-DD HH:mm"
+
+function describeSchedule(
+  schedule: CronSchedule,
+  t: (key: string, options?: Record<string, unknown>) => string,
+): string {
+  switch (schedule.kind) {
+    case "every":
+      return t("pages.agent.cron.schedule_every", {
+        seconds: Math.round((schedule.everyMs ?? 0) / 1000),
+      })
+    case "at":
+      return t("pages.agent.cron.schedule_at", {
+        time: dayjs(schedule.atMs).format(TIME_FORMAT),
+      })
+    default:
+      return schedule.tz ? `${schedule.expr} (${schedule.tz})` : schedule.expr!
+  }
+}
+
+function statusVariant(status?: string) {
+  switch (status) {
+    case "ok":
+      return "secondary" as const
+    case "error":
+    case "timeout":
+      return "destructive" as const
+    default:
+      return "outline" as const
+  }
+}
+
+export function CronPage() {
+  const { t } = useTranslation()
+  const queryClient = useQueryClient()
+  const [sheetOpen, setSheetOpen] = useState(false)
+  const [editingJob, setEditingJob] = useState<CronJob | null>(null)
+  const [pendingDelete, setPendingDelete] = useState<CronJob | null>(null)
+  const [expandedJob, setExpandedJob] = useState<string | null>(null)
+
+  const jobsQuery = useQuery({
+    queryKey: ["cron-jobs"],
+    queryFn: getCronJobs,
+    refetchInterval: 15_000,
+  })
+
+  const invalidate = () =>
+    queryClient.invalidateQueries({ queryKey: ["cron-jobs"] })
+
+  const onError = (fallback: string) => (error: unknown) => {
+    toast.error(error instanceof Error ? error.message : t(fallback))
+  }
+
+  const enableMutation = useMutation({
+    mutationFn: ({ id, enabled }: { id: string; enabled: boolean }) =>
+      setCronJobEnabled(id, enabled),
+    onSuccess: () => void invalidate(),
+    onError: onError("pages.agent.cron.save_error"),
+  })
+
+  const runMutation = useMutation({
+    mutationFn: runCronJob,
+    onSuccess: () => {
+      toast.success(t("pages.agent.cron.run_success"))
+      void invalidate()
+    },
+    onError: onError("pages.agent.cron.run_error"),
+  })
+
+  const deleteMutation = useMutation({
+    mutationFn: deleteCronJob,
+    onSuccess: () => {
+      toast.success(t("pages.agent.cron.delete_success"))
+      setPendingDelete(null)
+      void invalidate()
+    },
+    onError: onError("pages.agent.cron.delete_error"),
+  })
+
+  const openSheet = (job: CronJob | null) => {
+    setEditingJob(job)
+    setSheetOpen(true)
+  }
+
+  const jobs = jobsQuery.data?.jobs ?? []
+
+  return (
+    <div className="flex h-full flex-col">
+      <PageHeader title={t("navigation.cron")}>
+        <Button size="sm" onClick={() => openSheet(null)}>
+          <IconPlus className="size-4" />
+          {t("pages.agent.cron.add")}
+        </Button>
+      </PageHeader>
+
+      <div className="flex-1 overflow-auto p-4 sm:p-8">
+        <div className="mx-auto max-w-4xl space-y-4">
+          <p className="text-muted-foreground text-sm">
+            {t("pages.agent.cron.description")}
+          </p>
+
+          {jobsQuery.isLoading ? (
+            <Skeleton className="h-32 w-full rounded-xl" />
+          ) : jobsQuery.isError ? (
+            <p className="text-destructive text-sm">
+              {t("pages.agent.cron.load_error")}
+            </p>
+          ) : jobs.length === 0 ? (
+            <Card>
+              <CardContent className="text-muted-foreground py-10 text-center text-sm">
+                {t("pages.agent.cron.empty")}
+              </CardContent>
+            </Card>
+          ) : (
+            jobs.map((job) => (
+              <Card key={job.id}>
+                <CardContent className="space-y-3">
+                  <div className="flex items-start justify-between gap-4">
+                    <div className="min-w-0 space-y-1">
+                      <div className="flex items-center gap-2">
+                        <span className="text-foreground/90 truncate font-medium">
+                          {job.name}
+                        </span>
+                        {job.state.lastStatus && (
+                          <Badge variant={statusVariant(job.state.lastStatus)}>
+                            {job.state.lastStatus}
+                          </Badge>
+                        )}
+                      </div>
+                      <div className="text-muted-foreground font-mono text-xs">
+                        {describeSchedule(job.schedule, t)}
+                      </div>
+                      <div className="text-muted-foreground text-xs">
+                        {job.enabled && job.state.nextRunAtMs
+                          ? t("pages.agent.cron.next_run", {
+                              time: dayjs(job.state.nextRunAtMs).format(
+                                TIME_FORMAT,
+                              ),
+                            })
+                          : t("pages.agent.cron.not_scheduled")}
+                      </div>
+                      {job.state.lastError && (
+                        <div className="text-destructive text-xs">
+                          {job.state.lastError}
+                        </div>
+                      )}
+                    </div>
+                    <Switch
+                      checked={job.enabled}
+                      disabled={enableMutation.isPending}
+                      onCheckedChange={(enabled) =>
+                        enableMutation.mutate({ id: job.id, enabled })
+                      }
+                    />
+                  </div>
+
+                  <div className="flex flex-wrap items-center gap-2">
+                    <Button
+                      variant="outline"
+                      size="sm"
+                      disabled={runMutation.isPending}
+                      onClick={() => runMutation.mutate(job.id)}
+                    >
+                      {runMutation.isPending &&
+                      runMutation.variables === job.id ? (
+                        <IconLoader2 className="size-4 animate-spin" />
+                      ) : (
+                        <IconPlayerPlay className="size-4" />
+                      )}
+                      {t("pages.agent.cron.run_now")}
+                    </Button>
+                    <Button
+                      variant="outline"
+                      size="sm"
+                      onClick={() => openSheet(job)}
+                    >
+                      <IconPencil className="size-4" />
+                      {t("pages.agent.cron.edit")}
+                    </Button>
+                    <Button
+                      variant="outline"
+                      size="sm"
+                      onClick={() => setPendingDelete(job)}
+                    >
+                      <IconTrash className="size-4" />
+                      {t("pages.agent.cron.delete")}
+                    </Button>
+                    <Button
+                      variant="ghost"
+                      size="sm"
+                      className="ml-auto"
+                      onClick={() =>
+                        setExpandedJob(expandedJob === job.id ? null : job.id)
+                      }
+                    >
+                      {expandedJob === job.id ? (
+                        <IconChevronDown className="size-4" />
+                      ) : (
+                        <IconChevronRight className="size-4" />
+                      )}
+                      {t("pages.agent.cron.history")}
+                    </Button>
+                  </div>
+
+                  {expandedJob === job.id && <CronHistory jobId={job.id} />}
+                </CardContent>
+              </Card>
+            ))
+          )}
+        </div>
+      </div>
+
+      <CronJobSheet
+        open={sheetOpen}
+        job={editingJob}
+        onClose={() => setSheetOpen(false)}
+        onSaved={() => {
+          toast.success(t("pages.agent.cron.save_success"))
+          void invalidate()
+        }}
+      />
+
+      <DeleteConfirmDialog
+        open={pendingDelete !== null}
+        title={t("pages.agent.cron.delete_title")}
+        description={t("pages.agent.cron.delete_description", {
+          name: pendingDelete?.name,
+        })}
+        confirmLabel={t("pages.agent.cron.delete")}
+        isPending={deleteMutation.isPending}
+        onOpenChange={(open) => !open && setPendingDelete(null)}
+        onConfirm={() =>
+          pendingDelete && deleteMutation.mutate(pendingDelete.id)
+        }
+      />
+    </div>
+  )
+}
+
+function CronHistory({ jobId }: { jobId: string }) {
+  const { t } = useTranslation()
+
+  const historyQuery = useQuery({
+    queryKey: ["cron-history", jobId],
+    queryFn: () => getCronHistory(jobId),
+  })
+
+  if (historyQuery.isLoading) {
+    return <Skeleton className="h-16 w-full" />
+  }
+  const runs = historyQuery.data?.runs ?? []
+  if (runs.length === 0) {
+    return (
+      <p className="text-muted-foreground text-xs">
+        {t("pages.agent.cron.history_empty")}
+      </p>
+    )
+  }
+
+  return (
+    <div className="divide-border/60 divide-y rounded-md border text-xs">
+      {runs.map((run) => (
+        <div
+          key={`${run.startedAtMs}-${run.attempt}`}
+          className="flex items-start gap-3 px-3 py-2"
+        >
+          <Badge variant={statusVariant(run.status)}>{run.status}</Badge>
+          <div className="min-w-0 flex-1">
+            <div className="text-muted-foreground">
+              {t("pages.agent.cron.history_entry", {
+                time: dayjs(run.startedAtMs).format("YYYY-MM-DD HH:mm:ss"),
+                trigger: run.trigger,
+                attempt: run.attempt,
+                duration: run.durationMs,
+              })}
+            </div>
+            {(run.error || run.output) && (
+              <div className="mt-1 line-clamp-3 break-words whitespace-pre-wrap">
+                {run.error || run.output}
+              </div>
+            )}
+          </div>
+        </div>
+      ))}
+    </div>
+  )
+}
@@ -0,0 +1,177 @@
+import { IconLoader2 } from "@tabler/icons-react"
+import { useEffect, useState } from "react"
+import { useTranslation } from "react-i18next"
+
+import { type BuiltinHook, saveBuiltinHook } from "@/api/hooks"
+import { Field } from "@/components/shared-form"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "@/components/ui/select"
+import {
+  Sheet,
+  SheetContent,
+  SheetDescription,
+  SheetFooter,
+  SheetHeader,
+  SheetTitle,
+} from "@/components/ui/sheet"
+import { Textarea } from "@/components/ui/textarea"
+
+interface BuiltinHookSheetProps {
+  open: boolean
+  hook: BuiltinHook | null
+  availableBuiltins: string[]
+  onClose: () => void
+  onSaved: () => void
+}
+
+export function BuiltinHookSheet({
+  open,
+  hook,
+  availableBuiltins,
+  onClose,
+  onSaved,
+}: BuiltinHookSheetProps) {
+  const { t } = useTranslation()
+  const [name, setName] = useState("")
+  const [priority, setPriority] = useState("")
+  const [configText, setConfigText] = useState("")
+  const [saving, setSaving] = useState(false)
+  const [serverError, setServerError] = useState("")
+
+  useEffect(() => {
+    if (open) {
+      setName(hook?.name ?? "")
+      setPriority(hook?.priority ? String(hook.priority) : "")
+      setConfigText(
+        hook?.config === undefined ? "" : JSON.stringify(hook.config, null, 2),
+      )
+      setServerError("")
+    }
+  }, [open, hook])
+
+  const handleSave = async () => {
+    let config: unknown
+    if (configText.trim() !== "") {
+      try {
+        config = JSON.parse(configText)
+      } catch {
+        setServerError(t("pages.agent.hooks.invalid_json"))
+        return
+      }
+    }
+
+    setSaving(true)
+    setServerError("")
+    try {
+      await saveBuiltinHook(name.trim(), {
+        enabled: hook?.enabled ?? true,
+        priority: priority ? Number(priority) : undefined,
+        config,
+      })
+      onSaved()
+      onClose()
+    } catch (e) {
+      setServerError(
+        e instanceof Error ? e.message : t("pages.agent.hooks.save_error"),
+      )
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  return (
+    <Sheet open={open} onOpenChange={(v) => !v && onClose()}>
+      <SheetContent
+        side="right"
+        className="flex flex-col gap-0 p-0 data-[side=right]:!w-full data-[side=right]:sm:!w-[560px] data-[side=right]:sm:!max-w-[560px]"
+      >
+        <SheetHeader className="border-b-muted border-b px-6 py-5">
+          <SheetTitle className="text-base">
+            {hook
+              ? t("pages.agent.hooks.edit_builtin_title", { name: hook.name })
+              : t("pages.agent.hooks.add_builtin_title")}
+          </SheetTitle>
+          <SheetDescription className="text-xs">
+            {t("pages.agent.hooks.builtin_sheet_description")}
+          </SheetDescription>
+        </SheetHeader>
+
+        <div className="min-h-0 flex-1 overflow-y-auto">
+          <div className="space-y-5 px-6 py-5">
+            <Field label={t("pages.agent.hooks.field.name")} required>
+              {hook || availableBuiltins.length === 0 ? (
+                <Input
+                  value={name}
+                  onChange={(e) => setName(e.target.value)}
+                  disabled={hook !== null}
+                />
+              ) : (
+                <Select value={name} onValueChange={setName}>
+                  <SelectTrigger className="w-full">
+                    <SelectValue
+                      placeholder={t("pages.agent.hooks.field.select_builtin")}
+                    />
+                  </SelectTrigger>
+                  <SelectContent>
+                    {availableBuiltins.map((builtin) => (
+                      <SelectItem key={builtin} value={builtin}>
+                        {builtin}
+                      </SelectItem>
+                    ))}
+                  </SelectContent>
+                </Select>
+              )}
+            </Field>
+
+            <Field
+              label={t("pages.agent.hooks.field.priority")}
+              hint={t("pages.agent.hooks.field.priority_hint")}
+            >
+              <Input
+                type="number"
+                value={priority}
+                onChange={(e) => setPriority(e.target.value)}
+              />
+            </Field>
+
+            <Field
+              label={t("pages.agent.hooks.field.config")}
+              hint={t("pages.agent.hooks.field.config_hint")}
+            >
+              <Textarea
+                value={configText}
+                onChange={(e) => setConfigText(e.target.value)}
+                rows={8}
+                placeholder="{}"
+                className="font-mono text-sm"
+              />
+            </Field>
+
+            {serverError && (
+              <p className="text-destructive bg-destructive/10 rounded-md px-3 py-2 text-sm">
+                {serverError}
+              </p>
+            )}
+          </div>
+        </div>
+
+        <SheetFooter className="border-t-muted border-t px-6 py-4">
+          <Button variant="ghost" onClick={onClose} disabled={saving}>
+            {t("common.cancel")}
+          </Button>
+          <Button onClick={handleSave} disabled={!name.trim() || saving}>
+            {saving && <IconLoader2 className="size-4 animate-spin" />}
+            {t("common.save")}
+          </Button>
+        </SheetFooter>
+      </SheetContent>
+    </Sheet>
+  )
+}
@@ -0,0 +1,381 @@
+import {
+  IconLoader2,
+  IconPencil,
+  IconPlus,
+  IconTrash,
+} from "@tabler/icons-react"
+import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
+import { type ReactNode, useEffect, useState } from "react"
+import { useTranslation } from "react-i18next"
+import { toast } from "sonner"
+
+import {
+  type BuiltinHook,
+  type HookDefaults,
+  type ProcessHook,
+  deleteBuiltinHook,
+  deleteProcessHook,
+  getHooks,
+  saveBuiltinHook,
+  saveProcessHook,
+  updateHookSettings,
+} from "@/api/hooks"
+import { DeleteConfirmDialog } from "@/components/agent/delete-confirm-dialog"
+import { BuiltinHookSheet } from "@/components/agent/hooks/builtin-hook-sheet"
+import { ProcessHookSheet } from "@/components/agent/hooks/process-hook-sheet"
+import { PageHeader } from "@/components/page-header"
+import { Field, SwitchCardField } from "@/components/shared-form"
+import { Badge } from "@/components/ui/badge"
+import { Button } from "@/components/ui/button"
+import { Card, CardContent } from "@/components/ui/card"
+import { Input } from "@/components/ui/input"
+import { Skeleton } from "@/components/ui/skeleton"
+import { Switch } from "@/components/ui/switch"
+import { showSaveSuccessOrRestartToast } from "@/lib/restart-required"
+import { refreshGatewayState } from "@/store/gateway"
+
+type PendingDelete =
+  | { kind: "builtin"; name: string }
+  | { kind: "process"; name: string }
+
+const TIMEOUT_FIELDS = [
+  "observer_timeout_ms",
+  "interceptor_timeout_ms",
+  "approval_timeout_ms",
+] as const
+
+export function HooksPage() {
+  const { t } = useTranslation()
+  const queryClient = useQueryClient()
+  const [enabled, setEnabled] = useState(false)
+  const [defaults, setDefaults] = useState<HookDefaults>({})
+  const [builtinSheet, setBuiltinSheet] = useState<{
+    open: boolean
+    hook: BuiltinHook | null
+  }>({ open: false, hook: null })
+  const [processSheet, setProcessSheet] = useState<{
+    open: boolean
+    hook: ProcessHook | null
+  }>({ open: false, hook: null })
+  const [pendingDelete, setPendingDelete] = useState<PendingDelete | null>(
+    null,
+  )
+
+  const hooksQuery = useQuery({
+    queryKey: ["hooks"],
+    queryFn: getHooks,
+  })
+
+  useEffect(() => {
+    if (hooksQuery.data) {
+      setEnabled(hooksQuery.data.enabled)
+      setDefaults(hooksQuery.data.defaults)
+    }
+  }, [hooksQuery.data])
+
+  // Hooks are mounted when the gateway starts, so every change may need a
+  // gateway reload before it takes effect.
+  const onConfigSaved = async (message: string) => {
+    void queryClient.invalidateQueries({ queryKey: ["hooks"] })
+    const gateway = await refreshGatewayState({ force: true })
+    showSaveSuccessOrRestartToast(
+      t,
+      message,
+      t("navigation.hooks"),
+      gateway?.restartRequired === true,
+    )
+  }
+
+  const onError = (fallback: string) => (error: unknown) => {
+    toast.error(error instanceof Error ? error.message : t(fallback))
+  }
+
+  const settingsMutation = useMutation({
+    mutationFn: () => updateHookSettings(enabled, defaults),
+    onSuccess: () => onConfigSaved(t("pages.agent.hooks.save_success")),
+    onError: onError("pages.agent.hooks.save_error"),
+  })
+
+  const toggleMutation = useMutation({
+    mutationFn: ({
+      hook,
+      kind,
+      enabled,
+    }: {
+      hook: BuiltinHook | ProcessHook
+      kind: "builtin" | "process"
+      enabled: boolean
+    }) =>
+      kind === "builtin"
+        ? saveBuiltinHook(hook.name, { ...hook, enabled })
+        : saveProcessHook(hook.name, { ...hook, enabled }),
+    onSuccess: () => onConfigSaved(t("pages.agent.hooks.save_success")),
+    onError: onError("pages.agent.hooks.save_error"),
+  })
+
+  const deleteMutation = useMutation({
+    mutationFn: (target: PendingDelete) =>
+      target.kind === "builtin"
+        ? deleteBuiltinHook(target.name)
+        : deleteProcessHook(target.name),
+    onSuccess: () => {
+      setPendingDelete(null)
+      return onConfigSaved(t("pages.agent.hooks.delete_success"))
+    },
+    onError: onError("pages.agent.hooks.delete_error"),
+  })
+
+  const data = hooksQuery.data
+
+  return (
+    <div className="flex h-full flex-col">
+      <PageHeader title={t("navigation.hooks")} />
+
+      <div className="flex-1 overflow-auto p-4 sm:p-8">
+        <div className="mx-auto max-w-4xl space-y-6">
+          <p className="text-muted-foreground text-sm">
+            {t("pages.agent.hooks.description")}
+          </p>
+
+          {hooksQuery.isLoading ? (
+            <Skeleton className="h-32 w-full rounded-xl" />
+          ) : hooksQuery.isError || !data ? (
+            <p className="text-destructive text-sm">
+              {t("pages.agent.hooks.load_error")}
+            </p>
+          ) : (
+            <>
+              {!data.gateway_running && (
+                <p className="bg-muted text-muted-foreground rounded-md px-3 py-2 text-sm">
+                  {t("pages.agent.hooks.gateway_stopped")}
+                </p>
+              )}
+              {data.init_error && (
+                <p className="text-destructive bg-destructive/10 rounded-md px-3 py-2 text-sm">
+                  {data.init_error}
+                </p>
+              )}
+
+              <Card>
+                <CardContent className="space-y-4">
+                  <SwitchCardField
+                    label={t("pages.agent.hooks.enabled")}
+                    hint={t("pages.agent.hooks.enabled_hint")}
+                    checked={enabled}
+                    onCheckedChange={setEnabled}
+                  />
+                  <div className="grid gap-4 sm:grid-cols-3">
+                    {TIMEOUT_FIELDS.map((field) => (
+                      <Field
+                        key={field}
+                        label={t(`pages.agent.hooks.defaults.${field}`)}
+                      >
+                        <Input
+                          type="number"
+                          min={0}
+                          value={defaults[field] ?? ""}
+                          onChange={(e) =>
+                            setDefaults((d) => ({
+                              ...d,
+                              [field]: e.target.value
+                                ? Number(e.target.value)
+                                : undefined,
+                            }))
+                          }
+                        />
+                      </Field>
+                    ))}
+                  </div>
+                  <div className="flex justify-end">
+                    <Button
+                      size="sm"
+                      disabled={settingsMutation.isPending}
+                      onClick={() => settingsMutation.mutate()}
+                    >
+                      {settingsMutation.isPending && (
+                        <IconLoader2 className="size-4 animate-spin" />
+                      )}
+                      {t("common.save")}
+                    </Button>
+                  </div>
+                </CardContent>
+              </Card>
+
+              <HookSection
+                title={t("pages.agent.hooks.builtins")}
+                empty={t("pages.agent.hooks.builtins_empty")}
+                onAdd={() => setBuiltinSheet({ open: true, hook: null })}
+              >
+                {data.builtins.map((hook) => (
+                  <HookRow
+                    key={hook.name}
+                    name={hook.name}
+                    detail={
+                      data.available_builtins.length > 0 &&
+                      !data.available_builtins.includes(hook.name)
+                        ? t("pages.agent.hooks.builtin_unknown")
+                        : undefined
+                    }
+                    enabled={hook.enabled}
+                    mounted={hook.mounted}
+                    toggleDisabled={toggleMutation.isPending}
+                    onToggle={(enabled) =>
+                      toggleMutation.mutate({ hook, kind: "builtin", enabled })
+                    }
+                    onEdit={() => setBuiltinSheet({ open: true, hook })}
+                    onDelete={() =>
+                      setPendingDelete({ kind: "builtin", name: hook.name })
+                    }
+                  />
+                ))}
+              </HookSection>
+
+              <HookSection
+                title={t("pages.agent.hooks.processes")}
+                empty={t("pages.agent.hooks.processes_empty")}
+                onAdd={() => setProcessSheet({ open: true, hook: null })}
+              >
+                {data.processes.map((hook) => (
+                  <HookRow
+                    key={hook.name}
+                    name={hook.name}
+                    detail={(hook.command ?? []).join(" ")}
+                    enabled={hook.enabled}
+                    mounted={hook.mounted}
+                    toggleDisabled={toggleMutation.isPending}
+                    onToggle={(enabled) =>
+                      toggleMutation.mutate({ hook, kind: "process", enabled })
+                    }
+                    onEdit={() => setProcessSheet({ open: true, hook })}
+                    onDelete={() =>
+                      setPendingDelete({ kind: "process", name: hook.name })
+                    }
+                  />
+                ))}
+              </HookSection>
+            </>
+          )}
+        </div>
+      </div>
+
+      <BuiltinHookSheet
+        open={builtinSheet.open}
+        hook={builtinSheet.hook}
+        availableBuiltins={data?.available_builtins ?? []}
+        onClose={() => setBuiltinSheet((s) => ({ ...s, open: false }))}
+        onSaved={() => void onConfigSaved(t("pages.agent.hooks.save_success"))}
+      />
+
+      <ProcessHookSheet
+        open={processSheet.open}
+        hook={processSheet.hook}
+        onClose={() => setProcessSheet((s) => ({ ...s, open: false }))}
+        onSaved={() => void onConfigSaved(t("pages.agent.hooks.save_success"))}
+      />
+
+      <DeleteConfirmDialog
+        open={pendingDelete !== null}
+        title={t("pages.agent.hooks.delete_title")}
+        description={t("pages.agent.hooks.delete_description", {
+          name: pendingDelete?.name,
+        })}
+        confirmLabel={t("pages.agent.hooks.delete")}
+        isPending={deleteMutation.isPending}
+        onOpenChange={(open) => !open && setPendingDelete(null)}
+        onConfirm={() => pendingDelete && deleteMutation.mutate(pendingDelete)}
+      />
+    </div>
+  )
+}
+
+function HookSection({
+  title,
+  empty,
+  onAdd,
+  children,
+}: {
+  title: string
+  empty: string
+  onAdd: () => void
+  children: ReactNode[]
+}) {
+  const { t } = useTranslation()
+
+  return (
+    <section className="space-y-3">
+      <div className="flex items-center justify-between">
+        <h3 className="text-foreground/90 font-medium">{title}</h3>
+        <Button variant="outline" size="sm" onClick={onAdd}>
+          <IconPlus className="size-4" />
+          {t("pages.agent.hooks.add")}
+        </Button>
+      </div>
+      {children.length === 0 ? (
+        <Card>
+          <CardContent className="text-muted-foreground py-6 text-center text-sm">
+            {empty}
+          </CardContent>
+        </Card>
+      ) : (
+        <Card>
+          <CardContent className="divide-border/60 divide-y py-0">
+            {children}
+          </CardContent>
+        </Card>
+      )}
+    </section>
+  )
+}
+
+function HookRow({
+  name,
+  detail,
+  enabled,
+  mounted,
+  toggleDisabled,
+  onToggle,
+  onEdit,
+  onDelete,
+}: {
+  name: string
+  detail?: string
+  enabled: boolean
+  mounted: boolean
+  toggleDisabled: boolean
+  onToggle: (enabled: boolean) => void
+  onEdit: () => void
+  onDelete: () => void
+}) {
+  const { t } = useTranslation()
+
+  return (
+    <div className="flex items-center justify-between gap-4 py-3">
+      <div className="min-w-0 space-y-1">
+        <div className="flex items-center gap-2">
+          <span className="truncate font-mono text-sm">{name}</span>
+          {mounted && (
+            <Badge variant="secondary">{t("pages.agent.hooks.mounted")}</Badge>
+          )}
+        </div>
+        {detail && (
+          <div className="text-muted-foreground truncate font-mono text-xs">
+            {detail}
+          </div>
+        )}
+      </div>
+      <div className="flex shrink-0 items-center gap-2">
+        <Button variant="ghost" size="icon-sm" onClick={onEdit}>
+          <IconPencil className="size-4" />
+        </Button>
+        <Button variant="ghost" size="icon-sm" onClick={onDelete}>
+          <IconTrash className="size-4" />
+        </Button>
+        <Switch
+          checked={enabled}
+          disabled={toggleDisabled}
+          onCheckedChange={onToggle}
+        />
+      </div>
+    </div>
+  )
+}
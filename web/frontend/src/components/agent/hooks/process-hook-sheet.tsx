@@ -0,0 +1,246 @@
+import { IconLoader2 } from "@tabler/icons-react"
+import { useEffect, useState } from "react"
+import { useTranslation } from "react-i18next"
+
+import {
+  PROCESS_HOOK_INTERCEPTS,
+  type ProcessHook,
+  saveProcessHook,
+} from "@/api/hooks"
+import {
+  AdvancedSection,
+  Field,
+  SwitchCardField,
+} from "@/components/shared-form"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import {
+  Sheet,
+  SheetContent,
+  SheetDescription,
+  SheetFooter,
+  SheetHeader,
+  SheetTitle,
+} from "@/components/ui/sheet"
+import { Textarea } from "@/components/ui/textarea"
+import { formatPairs, parseLines, parsePairs } from "@/lib/key-value-lines"
+
+interface ProcessForm {
+  name: string
+  command: string
+  dir: string
+  env: string
+  observe: string
+  intercept: string[]
+  priority: string
+}
+
+const EMPTY_FORM: ProcessForm = {
+  name: "",
+  command: "",
+  dir: "",
+  env: "",
+  observe: "",
+  intercept: [],
+  priority: "",
+}
+
+function formFromHook(hook: ProcessHook): ProcessForm {
+  return {
+    name: hook.name,
+    command: (hook.command ?? []).join("\n"),
+    dir: hook.dir ?? "",
+    env: formatPairs(hook.env, "="),
+    observe: (hook.observe ?? []).join("\n"),
+    intercept: hook.intercept ?? [],
+    priority: hook.priority ? String(hook.priority) : "",
+  }
+}
+
+interface ProcessHookSheetProps {
+  open: boolean
+  hook: ProcessHook | null
+  onClose: () => void
+  onSaved: () => void
+}
+
+export function ProcessHookSheet({
+  open,
+  hook,
+  onClose,
+  onSaved,
+}: ProcessHookSheetProps) {
+  const { t } = useTranslation()
+  const [form, setForm] = useState<ProcessForm>(EMPTY_FORM)
+  const [saving, setSaving] = useState(false)
+  const [serverError, setServerError] = useState("")
+
+  useEffect(() => {
+    if (open) {
+      setForm(hook ? formFromHook(hook) : EMPTY_FORM)
+      setServerError("")
+    }
+  }, [open, hook])
+
+  const setField =
+    (key: keyof ProcessForm) =>
+    (e: React.ChangeEvent<HTMLInputElement | HTMLTextAreaElement>) =>
+      setForm((f) => ({ ...f, [key]: e.target.value }))
+
+  const toggleIntercept = (intercept: string, checked: boolean) =>
+    setForm((f) => ({
+      ...f,
+      intercept: checked
+        ? [...f.intercept, intercept]
+        : f.intercept.filter((item) => item !== intercept),
+    }))
+
+  const canSave =
+    form.name.trim() !== "" && parseLines(form.command).length > 0
+
+  const handleSave = async () => {
+    setSaving(true)
+    setServerError("")
+    try {
+      const env = parsePairs(form.env, "=")
+      await saveProcessHook(form.name.trim(), {
+        enabled: hook?.enabled ?? true,
+        priority: form.priority ? Number(form.priority) : undefined,
+        transport: hook?.transport,
+        command: parseLines(form.command),
+        dir: form.dir.trim() || undefined,
+        env: Object.keys(env).length > 0 ? env : undefined,
+        observe: parseLines(form.observe),
+        intercept: form.intercept,
+      })
+      onSaved()
+      onClose()
+    } catch (e) {
+      setServerError(
+        e instanceof Error ? e.message : t("pages.agent.hooks.save_error"),
+      )
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  return (
+    <Sheet open={open} onOpenChange={(v) => !v && onClose()}>
+      <SheetContent
+        side="right"
+        className="flex flex-col gap-0 p-0 data-[side=right]:!w-full data-[side=right]:sm:!w-[560px] data-[side=right]:sm:!max-w-[560px]"
+      >
+        <SheetHeader className="border-b-muted border-b px-6 py-5">
+          <SheetTitle className="text-base">
+            {hook
+              ? t("pages.agent.hooks.edit_process_title", { name: hook.name })
+              : t("pages.agent.hooks.add_process_title")}
+          </SheetTitle>
+          <SheetDescription className="text-xs">
+            {t("pages.agent.hooks.process_sheet_description")}
+          </SheetDescription>
+        </SheetHeader>
+
+        <div className="min-h-0 flex-1 overflow-y-auto">
+          <div className="space-y-5 px-6 py-5">
+            <Field label={t("pages.agent.hooks.field.name")} required>
+              <Input
+                value={form.name}
+                onChange={setField("name")}
+                disabled={hook !== null}
+              />
+            </Field>
+
+            <Field
+              label={t("pages.agent.hooks.field.command")}
+              hint={t("pages.agent.hooks.field.command_hint")}
+              required
+            >
+              <Textarea
+                value={form.command}
+                onChange={setField("command")}
+                rows={3}
+                placeholder={"python3\n/opt/hooks/review.py"}
+                className="font-mono text-sm"
+              />
+            </Field>
+
+            <Field
+              label={t("pages.agent.hooks.field.observe")}
+              hint={t("pages.agent.hooks.field.observe_hint")}
+            >
+              <Textarea
+                value={form.observe}
+                onChange={setField("observe")}
+                rows={3}
+                className="font-mono text-sm"
+              />
+            </Field>
+
+            <Field
+              label={t("pages.agent.hooks.field.intercept")}
+              hint={t("pages.agent.hooks.field.intercept_hint")}
+            >
+              <div className="border-border/60 divide-border/60 divide-y rounded-lg border px-4">
+                {PROCESS_HOOK_INTERCEPTS.map((intercept) => (
+                  <SwitchCardField
+                    key={intercept}
+                    label={intercept}
+                    transparent
+                    checked={form.intercept.includes(intercept)}
+                    onCheckedChange={(checked) =>
+                      toggleIntercept(intercept, checked)
+                    }
+                  />
+                ))}
+              </div>
+            </Field>
+
+            <AdvancedSection>
+              <Field
+                label={t("pages.agent.hooks.field.priority")}
+                hint={t("pages.agent.hooks.field.priority_hint")}
+              >
+                <Input
+                  type="number"
+                  value={form.priority}
+                  onChange={setField("priority")}
+                />
+              </Field>
+              <Field label={t("pages.agent.hooks.field.dir")}>
+                <Input value={form.dir} onChange={setField("dir")} />
+              </Field>
+              <Field
+                label={t("pages.agent.hooks.field.env")}
+                hint={t("pages.agent.hooks.field.env_hint")}
+              >
+                <Textarea
+                  value={form.env}
+                  onChange={setField("env")}
+                  rows={3}
+                  className="font-mono text-sm"
+                />
+              </Field>
+            </AdvancedSection>
+
+            {serverError && (
+              <p className="text-destructive bg-destructive/10 rounded-md px-3 py-2 text-sm">
+                {serverError}
+              </p>
+            )}
+          </div>
+        </div>
+
+        <SheetFooter className="border-t-muted border-t px-6 py-4">
+          <Button variant="ghost" onClick={onClose} disabled={saving}>
+            {t("common.cancel")}
+          </Button>
+          <Button onClick={handleSave} disabled={!canSave || saving}>
+            {saving && <IconLoader2 className="size-4 animate-spin" />}
+            {t("common.save")}
+          </Button>
+        </SheetFooter>
+      </SheetContent>
+    </Sheet>
+  )
+}
@@ -0,0 +1,122 @@
+import { IconLinkOff, IconLoader2 } from "@tabler/icons-react"
+import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
+import dayjs from "dayjs"
+import { useMemo } from "react"
+import { useTranslation } from "react-i18next"
+import { toast } from "sonner"
+
+import {
+  type IdentityLink,
+  deleteIdentityLink,
+  getIdentityLinks,
+} from "@/api/identity-links"
+import { PageHeader } from "@/components/page-header"
+import { Button } from "@/components/ui/button"
+import { Card, CardContent } from "@/components/ui/card"
+import { Skeleton } from "@/components/ui/skeleton"
+
+export function IdentityLinksPage() {
+  const { t } = useTranslation()
+  const queryClient = useQueryClient()
+
+  const linksQuery = useQuery({
+    queryKey: ["identity-links"],
+    queryFn: getIdentityLinks,
+  })
+
+  const groups = useMemo(() => {
+    const byCanonical = new Map<string, IdentityLink[]>()
+    for (const link of linksQuery.data?.links ?? []) {
+      const members = byCanonical.get(link.canonical) ?? []
+      members.push(link)
+      byCanonical.set(link.canonical, members)
+    }
+    return [...byCanonical.entries()]
+  }, [linksQuery.data?.links])
+
+  const unlinkMutation = useMutation({
+    mutationFn: deleteIdentityLink,
+    onSuccess: () => {
+      toast.success(t("pages.agent.identity_links.unlink_success"))
+      void queryClient.invalidateQueries({ queryKey: ["identity-links"] })
+    },
+    onError: (error) => {
+      toast.error(
+        error instanceof Error
+          ? error.message
+          : t("pages.agent.identity_links.unlink_error"),
+      )
+    },
+  })
+
+  return (
+    <div className="flex h-full flex-col">
+      <PageHeader title={t("navigation.identity_links")} />
+
+      <div className="flex-1 overflow-auto p-4 sm:p-8">
+        <div className="mx-auto max-w-3xl space-y-6">
+          <p className="text-muted-foreground text-sm">
+            {t("pages.agent.identity_links.description")}
+          </p>
+
+          {linksQuery.isLoading ? (
+            <Skeleton className="h-32 w-full rounded-xl" />
+          ) : linksQuery.isError ? (
+            <p className="text-destructive text-sm">
+              {t("pages.agent.identity_links.load_error")}
+            </p>
+          ) : groups.length === 0 ? (
+            <Card>
+              <CardContent className="text-muted-foreground py-10 text-center text-sm">
+                {t("pages.agent.identity_links.empty")}
+              </CardContent>
+            </Card>
+          ) : (
+            groups.map(([canonical, members]) => (
+              <Card key={canonical}>
+                <CardContent className="space-y-3">
+                  <div className="text-foreground/90 font-medium">
+                    {canonical}
+                  </div>
+                  {members.map((link) => (
+                    <div
+                      key={link.identity}
+                      className="flex items-center justify-between gap-4"
+                    >
+                      <div className="min-w-0">
+                        <div className="truncate font-mono text-sm">
+                          {link.identity}
+                        </div>
+                        <div className="text-muted-foreground text-xs">
+                          {t("pages.agent.identity_links.linked_at", {
+                            time: dayjs(link.linked_at).format(
+                              "YYYY-MM-DD HH:mm",
+                            ),
+                          })}
+                        </div>
+                      </div>
+                      <Button
+                        variant="outline"
+                        size="sm"
+                        disabled={unlinkMutation.isPending}
+                        onClick={() => unlinkMutation.mutate(link.identity)}
+                      >
+                        {unlinkMutation.isPending &&
+                        unlinkMutation.variables === link.identity ? (
+                          <IconLoader2 className="size-4 animate-spin" />
+                        ) : (
+                          <IconLinkOff className="size-4" />
+                        )}
+                        {t("pages.agent.identity_links.unlink")}
+                      </Button>
+                    </div>
+                  ))}
+                </CardContent>
+              </Card>
+            ))
+          )}
+        </div>
+      </div>
+    </div>
+  )
+}
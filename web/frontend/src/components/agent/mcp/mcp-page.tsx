@@ -0,0 +1,286 @@
+import {
+  IconLoader2,
+  IconPencil,
+  IconPlugConnected,
+  IconPlus,
+  IconRefresh,
+  IconTrash,
+} from "@tabler/icons-react"
+import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { toast } from "sonner"
+
+import {
+  type MCPServer,
+  type MCPServerStatus,
+  deleteMCPServer,
+  getMCPServers,
+  restartMCPServer,
+  setMCPServerEnabled,
+  testMCPServer,
+} from "@/api/mcp"
+import { DeleteConfirmDialog } from "@/components/agent/delete-confirm-dialog"
+import { MCPServerSheet } from "@/components/agent/mcp/mcp-server-sheet"
+import { PageHeader } from "@/components/page-header"
+import { Badge } from "@/components/ui/badge"
+import { Button } from "@/components/ui/button"
+import { Card, CardContent } from "@/components/ui/card"
+import { Skeleton } from "@/components/ui/skeleton"
+import { Switch } from "@/components/ui/switch"
+import { showSaveSuccessOrRestartToast } from "@/lib/restart-required"
+import { refreshGatewayState } from "@/store/gateway"
+
+const STATUS_VARIANTS: Record<
+  MCPServerStatus,
+  "default" | "secondary" | "destructive" | "outline"
+> = {
+  connected: "default",
+  failed: "destructive",
+  pending: "secondary",
+  disabled: "outline",
+  unknown: "outline",
+}
+
+export function MCPPage() {
+  const { t } = useTranslation()
+  const queryClient = useQueryClient()
+  const [sheetOpen, setSheetOpen] = useState(false)
+  const [editingServer, setEditingServer] = useState<MCPServer | null>(null)
+  const [pendingDelete, setPendingDelete] = useState<MCPServer | null>(null)
+
+  const serversQuery = useQuery({
+    queryKey: ["mcp-servers"],
+    queryFn: getMCPServers,
+    refetchInterval: 15_000,
+  })
+
+  const invalidate = () =>
+    queryClient.invalidateQueries({ queryKey: ["mcp-servers"] })
+
+  // Server definitions live in config, so every change may need the gateway
+  // to reload before it takes effect.
+  const onConfigSaved = async (message: string) => {
+    void invalidate()
+    const gateway = await refreshGatewayState({ force: true })
+    showSaveSuccessOrRestartToast(
+      t,
+      message,
+      t("navigation.mcp"),
+      gateway?.restartRequired === true,
+    )
+  }
+
+  const onError = (fallback: string) => (error: unknown) => {
+    toast.error(error instanceof Error ? error.message : t(fallback))
+  }
+
+  const enableMutation = useMutation({
+    mutationFn: ({ name, enabled }: { name: string; enabled: boolean }) =>
+      setMCPServerEnabled(name, enabled),
+    onSuccess: () => onConfigSaved(t("pages.agent.mcp.save_success")),
+    onError: onError("pages.agent.mcp.save_error"),
+  })
+
+  const testMutation = useMutation({
+    mutationFn: testMCPServer,
+    onSuccess: (result) => {
+      toast.success(
+        t("pages.agent.mcp.test_success", { count: result.tool_count }),
+        { description: result.tools.join(", ") || undefined },
+      )
+    },
+    onError: onError("pages.agent.mcp.test_error"),
+  })
+
+  const restartMutation = useMutation({
+    mutationFn: restartMCPServer,
+    onSuccess: () => {
+      toast.success(t("pages.agent.mcp.restart_success"))
+      void invalidate()
+    },
+    onError: onError("pages.agent.mcp.restart_error"),
+  })
+
+  const deleteMutation = useMutation({
+    mutationFn: deleteMCPServer,
+    onSuccess: () => {
+      setPendingDelete(null)
+      return onConfigSaved(t("pages.agent.mcp.delete_success"))
+    },
+    onError: onError("pages.agent.mcp.delete_error"),
+  })
+
+  const openSheet = (server: MCPServer | null) => {
+    setEditingServer(server)
+    setSheetOpen(true)
+  }
+
+  const data = serversQuery.data
+  const servers = data?.servers ?? []
+
+  return (
+    <div className="flex h-full flex-col">
+      <PageHeader title={t("navigation.mcp")}>
+        <Button size="sm" onClick={() => openSheet(null)}>
+          <IconPlus className="size-4" />
+          {t("pages.agent.mcp.add")}
+        </Button>
+      </PageHeader>
+
+      <div className="flex-1 overflow-auto p-4 sm:p-8">
+        <div className="mx-auto max-w-4xl space-y-4">
+          <p className="text-muted-foreground text-sm">
+            {t("pages.agent.mcp.description")}
+          </p>
+
+          {data && !data.enabled && (
+            <p className="bg-muted text-muted-foreground rounded-md px-3 py-2 text-sm">
+              {t("pages.agent.mcp.tool_disabled")}
+            </p>
+          )}
+          {data && !data.gateway_running && (
+            <p className="bg-muted text-muted-foreground rounded-md px-3 py-2 text-sm">
+              {t("pages.agent.mcp.gateway_stopped")}
+            </p>
+          )}
+          {data?.init_error && (
+            <p className="text-destructive bg-destructive/10 rounded-md px-3 py-2 text-sm">
+              {data.init_error}
+            </p>
+          )}
+
+          {serversQuery.isLoading ? (
+            <Skeleton className="h-32 w-full rounded-xl" />
+          ) : serversQuery.isError ? (
+            <p className="text-destructive text-sm">
+              {t("pages.agent.mcp.load_error")}
+            </p>
+          ) : servers.length === 0 ? (
+            <Card>
+              <CardContent className="text-muted-foreground py-10 text-center text-sm">
+                {t("pages.agent.mcp.empty")}
+              </CardContent>
+            </Card>
+          ) : (
+            servers.map((server) => (
+              <Card key={server.name}>
+                <CardContent className="space-y-3">
+                  <div className="flex items-start justify-between gap-4">
+                    <div className="min-w-0 space-y-1">
+                      <div className="flex items-center gap-2">
+                        <span className="text-foreground/90 truncate font-medium">
+                          {server.name}
+                        </span>
+                        <Badge variant="outline">{server.type}</Badge>
+                        <Badge variant={STATUS_VARIANTS[server.status]}>
+                          {t(`pages.agent.mcp.status.${server.status}`)}
+                        </Badge>
+                      </div>
+                      <div className="text-muted-foreground truncate font-mono text-xs">
+                        {server.type === "stdio"
+                          ? [server.command, ...(server.args ?? [])].join(" ")
+                          : server.url}
+                      </div>
+                      {server.status === "connected" && (
+                        <div className="text-muted-foreground text-xs">
+                          {t("pages.agent.mcp.tool_count", {
+                            count: server.tool_count,
+                          })}
+                        </div>
+                      )}
+                      {server.error && (
+                        <div className="text-destructive text-xs break-words">
+                          {server.error}
+                        </div>
+                      )}
+                    </div>
+                    <Switch
+                      checked={server.enabled}
+                      disabled={enableMutation.isPending}
+                      onCheckedChange={(enabled) =>
+                        enableMutation.mutate({ name: server.name, enabled })
+                      }
+                    />
+                  </div>
+
+                  <div className="flex flex-wrap items-center gap-2">
+                    <Button
+                      variant="outline"
+                      size="sm"
+                      disabled={testMutation.isPending}
+                      onClick={() => testMutation.mutate(server.name)}
+                    >
+                      {testMutation.isPending &&
+                      testMutation.variables === server.name ? (
+                        <IconLoader2 className="size-4 animate-spin" />
+                      ) : (
+                        <IconPlugConnected className="size-4" />
+                      )}
+                      {t("pages.agent.mcp.test")}
+                    </Button>
+                    <Button
+                      variant="outline"
+                      size="sm"
+                      disabled={
+                        restartMutation.isPending ||
+                        !data?.gateway_running ||
+                        !server.enabled
+                      }
+                      onClick={() => restartMutation.mutate(server.name)}
+                    >
+                      {restartMutation.isPending &&
+                      restartMutation.variables === server.name ? (
+                        <IconLoader2 className="size-4 animate-spin" />
+                      ) : (
+                        <IconRefresh className="size-4" />
+                      )}
+                      {t("pages.agent.mcp.restart")}
+                    </Button>
+                    <Button
+                      variant="outline"
+                      size="sm"
+                      onClick={() => openSheet(server)}
+                    >
+                      <IconPencil className="size-4" />
+                      {t("pages.agent.mcp.edit")}
+                    </Button>
+                    <Button
+                      variant="outline"
+                      size="sm"
+                      onClick={() => setPendingDelete(server)}
+                    >
+                      <IconTrash className="size-4" />
+                      {t("pages.agent.mcp.delete")}
+                    </Button>
+                  </div>
+                </CardContent>
+              </Card>
+            ))
+          )}
+        </div>
+      </div>
+
+      <MCPServerSheet
+        open={sheetOpen}
+        server={editingServer}
+        onClose={() => setSheetOpen(false)}
+        onSaved={() => void onConfigSaved(t("pages.agent.mcp.save_success"))}
+      />
+
+      <DeleteConfirmDialog
+        open={pendingDelete !== null}
+        title={t("pages.agent.mcp.delete_title")}
+        description={t("pages.agent.mcp.delete_description", {
+          name: pendingDelete?.name,
+        })}
+        confirmLabel={t("pages.agent.mcp.delete")}
+        isPending={deleteMutation.isPending}
+        onOpenChange={(open) => !open && setPendingDelete(null)}
+        onConfirm={() =>
+          pendingDelete && deleteMutation.mutate(pendingDelete.name)
+        }
+      />
+    </div>
+  )
+}
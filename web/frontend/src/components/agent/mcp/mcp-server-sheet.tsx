@@ -0,0 +1,318 @@
+import { IconLoader2 } from "@tabler/icons-react"
+import { useEffect, useState } from "react"
+import { useTranslation } from "react-i18next"
+
+import {
+  type MCPServer,
+  type MCPServerInput,
+  createMCPServer,
+  updateMCPServer,
+} from "@/api/mcp"
+import { AdvancedSection, Field } from "@/components/shared-form"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "@/components/ui/select"
+import {
+  Sheet,
+  SheetContent,
+  SheetDescription,
+  SheetFooter,
+  SheetHeader,
+  SheetTitle,
+} from "@/components/ui/sheet"
+import { Textarea } from "@/components/ui/textarea"
+import { formatPairs, parseLines, parsePairs } from "@/lib/key-value-lines"
+
+interface MCPForm {
+  name: string
+  type: MCPServerInput["type"]
+  command: string
+  args: string
+  env: string
+  envFile: string
+  url: string
+  headers: string
+  deferred: "default" | "on" | "off"
+}
+
+const EMPTY_FORM: MCPForm = {
+  name: "",
+  type: "stdio",
+  command: "",
+  args: "",
+  env: "",
+  envFile: "",
+  url: "",
+  headers: "",
+  deferred: "default",
+}
+
+function formFromServer(server: MCPServer): MCPForm {
+  return {
+    name: server.name,
+    type: server.type,
+    command: server.command ?? "",
+    args: (server.args ?? []).join("\n"),
+    env: formatPairs(server.env, "="),
+    envFile: server.env_file ?? "",
+    url: server.url ?? "",
+    headers: formatPairs(server.headers, ": "),
+    deferred:
+      server.deferred === undefined
+        ? "default"
+        : server.deferred
+          ? "on"
+          : "off",
+  }
+}
+
+function inputFromForm(form: MCPForm): MCPServerInput {
+  const input: MCPServerInput = {
+    name: form.name.trim(),
+    type: form.type,
+    deferred: form.deferred === "default" ? undefined : form.deferred === "on",
+  }
+  if (form.type === "stdio") {
+    input.command = form.command.trim()
+    input.args = parseLines(form.args)
+    input.env = parsePairs(form.env, "=")
+    input.env_file = form.envFile.trim()
+  } else {
+    input.url = form.url.trim()
+    input.headers = parsePairs(form.headers, ":")
+  }
+  return input
+}
+
+interface MCPServerSheetProps {
+  open: boolean
+  server: MCPServer | null
+  onClose: () => void
+  onSaved: () => void
+}
+
+export function MCPServerSheet({
+  open,
+  server,
+  onClose,
+  onSaved,
+}: MCPServerSheetProps) {
+  const { t } = useTranslation()
+  const [form, setForm] = useState<MCPForm>(EMPTY_FORM)
+  const [saving, setSaving] = useState(false)
+  const [serverError, setServerError] = useState("")
+
+  useEffect(() => {
+    if (open) {
+      setForm(server ? formFromServer(server) : EMPTY_FORM)
+      setServerError("")
+    }
+  }, [open, server])
+
+  const setField =
+    (key: keyof MCPForm) =>
+    (e: React.ChangeEvent<HTMLInputElement | HTMLTextAreaElement>) =>
+      setForm((f) => ({ ...f, [key]: e.target.value }))
+
+  const canSave =
+    form.name.trim() !== "" &&
+    (form.type === "stdio"
+      ? form.command.trim() !== ""
+      : form.url.trim() !== "")
+
+  const handleSave = async () => {
+    setSaving(true)
+    setServerError("")
+    try {
+      const input = inputFromForm(form)
+      if (server) {
+        await updateMCPServer(server.name, input)
+      } else {
+        await createMCPServer(input)
+      }
+      onSaved()
+      onClose()
+    } catch (e) {
+      setServerError(
+        e instanceof Error ? e.message : t("pages.agent.mcp.save_error"),
+      )
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  return (
+    <Sheet open={open} onOpenChange={(v) => !v && onClose()}>
+      <SheetContent
+        side="right"
+        className="flex flex-col gap-0 p-0 data-[side=right]:!w-full data-[side=right]:sm:!w-[560px] data-[side=right]:sm:!max-w-[560px]"
+      >
+        <SheetHeader className="border-b-muted border-b px-6 py-5">
+          <SheetTitle className="text-base">
+            {server
+              ? t("pages.agent.mcp.edit_title", { name: server.name })
+              : t("pages.agent.mcp.add_title")}
+          </SheetTitle>
+          <SheetDescription className="text-xs">
+            {t("pages.agent.mcp.sheet_description")}
+          </SheetDescription>
+        </SheetHeader>
+
+        <div className="min-h-0 flex-1 overflow-y-auto">
+          <div className="space-y-5 px-6 py-5">
+            <Field
+              label={t("pages.agent.mcp.field.name")}
+              hint={t("pages.agent.mcp.field.name_hint")}
+              required
+            >
+              <Input
+                value={form.name}
+                onChange={setField("name")}
+                disabled={server !== null}
+                placeholder="filesystem"
+              />
+            </Field>
+
+            <Field label={t("pages.agent.mcp.field.type")} required>
+              <Select
+                value={form.type}
+                onValueChange={(type) =>
+                  setForm((f) => ({ ...f, type: type as MCPForm["type"] }))
+                }
+              >
+                <SelectTrigger className="w-full">
+                  <SelectValue />
+                </SelectTrigger>
+                <SelectContent>
+                  <SelectItem value="stdio">stdio</SelectItem>
+                  <SelectItem value="http">http</SelectItem>
+                  <SelectItem value="sse">sse</SelectItem>
+                </SelectContent>
+              </Select>
+            </Field>
+
+            {form.type === "stdio" ? (
+              <>
+                <Field label={t("pages.agent.mcp.field.command")} required>
+                  <Input
+                    value={form.command}
+                    onChange={setField("command")}
+                    placeholder="npx"
+                    className="font-mono text-sm"
+                  />
+                </Field>
+                <Field
+                  label={t("pages.agent.mcp.field.args")}
+                  hint={t("pages.agent.mcp.field.args_hint")}
+                >
+                  <Textarea
+                    value={form.args}
+                    onChange={setField("args")}
+                    rows={3}
+                    className="font-mono text-sm"
+                  />
+                </Field>
+                <Field
+                  label={t("pages.agent.mcp.field.env")}
+                  hint={t("pages.agent.mcp.field.env_hint")}
+                >
+                  <Textarea
+                    value={form.env}
+                    onChange={setField("env")}
+                    rows={3}
+                    placeholder="API_KEY=..."
+                    className="font-mono text-sm"
+                  />
+                </Field>
+              </>
+            ) : (
+              <>
+                <Field label={t("pages.agent.mcp.field.url")} required>
+                  <Input
+                    value={form.url}
+                    onChange={setField("url")}
+                    placeholder="https://example.com/mcp"
+                  />
+                </Field>
+                <Field
+                  label={t("pages.agent.mcp.field.headers")}
+                  hint={t("pages.agent.mcp.field.headers_hint")}
+                >
+                  <Textarea
+                    value={form.headers}
+                    onChange={setField("headers")}
+                    rows={3}
+                    placeholder="Authorization: Bearer ..."
+                    className="font-mono text-sm"
+                  />
+                </Field>
+              </>
+            )}
+
+            <AdvancedSection>
+              {form.type === "stdio" && (
+                <Field
+                  label={t("pages.agent.mcp.field.env_file")}
+                  hint={t("pages.agent.mcp.field.env_file_hint")}
+                >
+                  <Input value={form.envFile} onChange={setField("envFile")} />
+                </Field>
+              )}
+              <Field
+                label={t("pages.agent.mcp.field.deferred")}
+                hint={t("pages.agent.mcp.field.deferred_hint")}
+              >
+                <Select
+                  value={form.deferred}
+                  onValueChange={(deferred) =>
+                    setForm((f) => ({
+                      ...f,
+                      deferred: deferred as MCPForm["deferred"],
+                    }))
+                  }
+                >
+                  <SelectTrigger className="w-full">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    <SelectItem value="default">
+                      {t("pages.agent.mcp.deferred.default")}
+                    </SelectItem>
+                    <SelectItem value="on">
+                      {t("pages.agent.mcp.deferred.on")}
+                    </SelectItem>
+                    <SelectItem value="off">
+                      {t("pages.agent.mcp.deferred.off")}
+                    </SelectItem>
+                  </SelectContent>
+                </Select>
+              </Field>
+            </AdvancedSection>
+
+            {serverError && (
+              <p className="text-destructive bg-destructive/10 rounded-md px-3 py-2 text-sm">
+                {serverError}
+              </p>
+            )}
+          </div>
+        </div>
+
+        <SheetFooter className="border-t-muted border-t px-6 py-4">
+          <Button variant="ghost" onClick={onClose} disabled={saving}>
+            {t("common.cancel")}
+          </Button>
+          <Button onClick={handleSave} disabled={!canSave || saving}>
+            {saving && <IconLoader2 className="size-4 animate-spin" />}
+            {t("common.save")}
+          </Button>
+        </SheetFooter>
+      </SheetContent>
+    </Sheet>
+  )
+}
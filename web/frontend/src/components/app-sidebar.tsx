@@ -1,15 +1,22 @@
 import { IconChevronRight } from "@tabler/icons-react"
 import {
+  IconActivity,
   IconAtom,
   IconChevronsDown,
   IconChevronsUp,
+  IconClock,
+  IconHistory,
   IconKey,
+  IconLink,
   IconListDetails,
   IconMessageCircle,
+  IconPlug,
   IconSearch,
   IconSettings,
   IconSparkles,
   IconTools,
+  IconUsers,
+  IconWebhook,
 } from "@tabler/icons-react"
 import { Link, useRouterState } from "@tanstack/react-router"
 import * as React from "react"
@@ -99,6 +106,12 @@ export function AppSidebar({ ...props }: React.ComponentProps<typeof Sidebar>) {
             icon: IconMessageCircle,
             translateTitle: true,
           },
+          {
+            title: "navigation.sessions",
+            url: "/sessions",
+            icon: IconHistory,
+            translateTitle: true,
+          },
         ],
       },
       {
@@ -150,6 +163,36 @@ export function AppSidebar({ ...props }: React.ComponentProps<typeof Sidebar>) {
             icon: IconTools,
             translateTitle: true,
           },
+          {
+            title: "navigation.mcp",
+            url: "/agent/mcp",
+            icon: IconPlug,
+            translateTitle: true,
+          },
+          {
+            title: "navigation.hooks",
+            url: "/agent/hooks",
+            icon: IconWebhook,
+            translateTitle: true,
+          },
+          {
+            title: "navigation.cron",
+            url: "/agent/cron",
+            icon: IconClock,
+            translateTitle: true,
+          },
+          {
+            title: "navigation.activity",
+            url: "/agent/activity",
+            icon: IconActivity,
+            translateTitle: true,
+          },
+          {
+            title: "navigation.identity_links",
+            url: "/agent/identity-links",
+            icon: IconLink,
+            translateTitle: true,
+          },
         ],
       },
       {
@@ -167,6 +210,12 @@ export function AppSidebar({ ...props }: React.ComponentProps<typeof Sidebar>) {
             icon: IconListDetails,
             translateTitle: true,
           },
+          {
+            title: "navigation.accounts",
+            url: "/accounts",
+            icon: IconUsers,
+            translateTitle: true,
+          },
         ],
       },
     ]
@@ -1,4 +1,11 @@
-import { IconArrowUp, IconPhotoPlus, IconX } from "@tabler/icons-react"
+import {
+  IconArrowUp,
+  IconArrowsMinimize,
+  IconPhotoPlus,
+  IconPlayerStopFilled,
+  IconRefresh,
+  IconX,
+} from "@tabler/icons-react"
 import type { KeyboardEvent } from "react"
 import { useTranslation } from "react-i18next"
 import TextareaAutosize from "react-textarea-autosize"
@@ -33,6 +40,10 @@ interface ChatComposerProps {
   onRemoveAttachment: (index: number) => void
   onSend: () => void
   onContextDetail?: () => void
+  onStop?: () => void
+  onRetry?: () => void
+  onCompact?: () => void
+  isTyping?: boolean
   inputDisabledReason: ChatInputDisabledReason | null
   canSend: boolean
   contextUsage?: ContextUsage
@@ -46,6 +57,10 @@ export function ChatComposer({
   onRemoveAttachment,
   onSend,
   onContextDetail,
+  onStop,
+  onRetry,
+  onCompact,
+  isTyping = false,
   inputDisabledReason,
   canSend,
   contextUsage,
@@ -124,13 +139,52 @@ export function ChatComposer({
             >
               <IconPhotoPlus className="size-4" />
             </Button>
+            {onRetry && (
+              <Button
+                type="button"
+                variant="ghost"
+                size="icon"
+                className="text-muted-foreground hover:text-foreground h-8 w-8 rounded-full"
+                onClick={onRetry}
+                disabled={!canInput || isTyping}
+                aria-label={t("chat.retry")}
+                title={t("chat.retry")}
+              >
+                <IconRefresh className="size-4" />
+              </Button>
+            )}
+            {onCompact && (
+              <Button
+                type="button"
+                variant="ghost"
+                size="icon"
+                className="text-muted-foreground hover:text-foreground h-8 w-8 rounded-full"
+                onClick={onCompact}
+                disabled={!canInput || isTyping}
+                aria-label={t("chat.compact")}
+                title={t("chat.compact")}
+              >
+                <IconArrowsMinimize className="size-4" />
+              </Button>
+            )}
           </div>
 
           <div className="flex items-center gap-1.5">
             {contextUsage && (
               <ContextUsageRing usage={contextUsage} onDetailClick={onContextDetail} />
             )}
-            {canInput ? (
+            {canInput && isTyping && onStop ? (
+              <Button
+                type="button"
+                size="icon"
+                className="size-8 rounded-full bg-violet-500 text-white transition-transform hover:bg-violet-600 active:scale-95"
+                onClick={onStop}
+                aria-label={t("chat.stop")}
+                title={t("chat.stop")}
+              >
+                <IconPlayerStopFilled className="size-3.5" />
+              </Button>
+            ) : canInput ? (
               <Tooltip delayDuration={700}>
                 <TooltipTrigger asChild>
                   <span tabIndex={!canSend ? 0 : undefined}>
@@ -376,6 +376,10 @@ export function ChatPage() {
             setInput("")
           }
         }}
+        onStop={() => sendMessage({ content: "/stop", attachments: [] })}
+        onRetry={() => sendMessage({ content: "/retry", attachments: [] })}
+        onCompact={() => sendMessage({ content: "/compact", attachments: [] })}
+        isTyping={isTyping}
         inputDisabledReason={inputDisabledReason}
         canSend={canSubmit}
         contextUsage={contextUsage}
@@ -38,6 +38,7 @@ const PROVIDER_ALIASES: Record<string, string> = {
   "z-ai": "zai",
   google: "gemini",
   "google-antigravity": "antigravity",
+  "ollama-native": "ollama",
 }
 
 export function getProviderKey(provider?: string): string {
@@ -0,0 +1,213 @@
+import { IconLoader2, IconMessageCircle, IconSend } from "@tabler/icons-react"
+import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
+import { useNavigate } from "@tanstack/react-router"
+import { useEffect, useState } from "react"
+import { useTranslation } from "react-i18next"
+import { toast } from "sonner"
+
+import {
+  type BrowsedSession,
+  continueSession,
+  getBrowsedSession,
+} from "@/api/session-browser"
+import { Badge } from "@/components/ui/badge"
+import { Button } from "@/components/ui/button"
+import { Label } from "@/components/ui/label"
+import {
+  Sheet,
+  SheetContent,
+  SheetDescription,
+  SheetHeader,
+  SheetTitle,
+} from "@/components/ui/sheet"
+import { Skeleton } from "@/components/ui/skeleton"
+import { Switch } from "@/components/ui/switch"
+import { Textarea } from "@/components/ui/textarea"
+import { switchChatSession } from "@/features/chat/controller"
+
+interface SessionDetailSheetProps {
+  session: BrowsedSession | null
+  onClose: () => void
+}
+
+export function SessionDetailSheet({
+  session,
+  onClose,
+}: SessionDetailSheetProps) {
+  const { t } = useTranslation()
+  const navigate = useNavigate()
+  const queryClient = useQueryClient()
+  const [message, setMessage] = useState("")
+  const [deliver, setDeliver] = useState(false)
+
+  useEffect(() => {
+    setMessage("")
+    setDeliver(false)
+  }, [session?.key])
+
+  const detailQuery = useQuery({
+    queryKey: ["session-browser", "detail", session?.key],
+    queryFn: () => getBrowsedSession(session?.key ?? ""),
+    enabled: session !== null,
+  })
+
+  const continueMutation = useMutation({
+    mutationFn: (key: string) => continueSession(key, message.trim(), deliver),
+    onSuccess: () => {
+      setMessage("")
+      void queryClient.invalidateQueries({ queryKey: ["session-browser"] })
+    },
+    onError: (error) => {
+      toast.error(
+        error instanceof Error
+          ? error.message
+          : t("pages.sessions.continue_error"),
+      )
+    },
+  })
+
+  const openInChat = async (picoId: string) => {
+    await switchChatSession(picoId)
+    void navigate({ to: "/" })
+  }
+
+  const picoId = session?.pico_id
+  const scopeValues = Object.entries(session?.scope?.values ?? {})
+  const canDeliver = Boolean(session?.chat_id) && session?.channel !== "linked"
+
+  return (
+    <Sheet open={session !== null} onOpenChange={(v) => !v && onClose()}>
+      <SheetContent
+        side="right"
+        className="flex flex-col gap-0 p-0 data-[side=right]:!w-full data-[side=right]:sm:!w-[640px] data-[side=right]:sm:!max-w-[640px]"
+      >
+        <SheetHeader className="border-b-muted border-b px-6 py-5">
+          <SheetTitle className="truncate text-base">
+            {session?.title}
+          </SheetTitle>
+          <SheetDescription className="truncate font-mono text-xs">
+            {session?.key}
+          </SheetDescription>
+          {session && (
+            <div className="flex flex-wrap items-center gap-2 pt-1 text-xs">
+              <Badge variant="secondary">{session.channel || "—"}</Badge>
+              <Badge variant="outline">{session.agent_id}</Badge>
+              {session.account && (
+                <Badge variant="outline">{session.account}</Badge>
+              )}
+              {scopeValues.map(([dimension, value]) => (
+                <span
+                  key={dimension}
+                  className="text-muted-foreground font-mono"
+                >
+                  {dimension}={value}
+                </span>
+              ))}
+            </div>
+          )}
+        </SheetHeader>
+
+        <div className="min-h-0 flex-1 space-y-3 overflow-y-auto px-6 py-5">
+          {detailQuery.isLoading ? (
+            <Skeleton className="h-40 w-full rounded-xl" />
+          ) : detailQuery.isError ? (
+            <p className="text-destructive text-sm">
+              {t("pages.sessions.load_error")}
+            </p>
+          ) : (
+            <>
+              {detailQuery.data?.summary && (
+                <p className="bg-muted/60 text-muted-foreground rounded-md px-3 py-2 text-xs whitespace-pre-wrap">
+                  {detailQuery.data.summary}
+                </p>
+              )}
+              {detailQuery.data?.messages
+                .filter((msg) => msg.kind !== "thought")
+                .map((msg, index) => (
+                  <div
+                    key={index}
+                    className={
+                      msg.role === "user"
+                        ? "bg-primary/10 ml-8 rounded-lg px-3 py-2 text-sm"
+                        : "bg-muted mr-8 rounded-lg px-3 py-2 text-sm"
+                    }
+                  >
+                    {msg.kind === "tool_calls" ? (
+                      <span className="text-muted-foreground font-mono text-xs">
+                        {msg.tool_calls
+                          ?.map((call) => call.function?.name)
+                          .filter(Boolean)
+                          .join(", ")}
+                      </span>
+                    ) : (
+                      <span className="whitespace-pre-wrap">{msg.content}</span>
+                    )}
+                  </div>
+                ))}
+            </>
+          )}
+        </div>
+
+        {session && (
+          <div className="border-t-muted space-y-3 border-t px-6 py-4">
+            {picoId ? (
+              <Button
+                variant="outline"
+                className="w-full"
+                onClick={() => void openInChat(picoId)}
+              >
+                <IconMessageCircle className="size-4" />
+                {t("pages.sessions.open_in_chat")}
+              </Button>
+            ) : session.continuable ? (
+              <>
+                <Textarea
+                  value={message}
+                  onChange={(e) => setMessage(e.target.value)}
+                  placeholder={t("pages.sessions.continue_placeholder")}
+                  rows={3}
+                />
+                <div className="flex items-center justify-between gap-4">
+                  <div className="flex items-center gap-2">
+                    <Switch
+                      id="session-deliver"
+                      checked={deliver}
+                      disabled={!canDeliver}
+                      onCheckedChange={setDeliver}
+                    />
+                    <Label
+                      htmlFor="session-deliver"
+                      className="text-muted-foreground text-xs"
+                    >
+                      {t("pages.sessions.deliver", {
+                        channel: session.channel,
+                      })}
+                    </Label>
+                  </div>
+                  <Button
+                    size="sm"
+                    disabled={
+                      message.trim() === "" || continueMutation.isPending
+                    }
+                    onClick={() => continueMutation.mutate(session.key)}
+                  >
+                    {continueMutation.isPending ? (
+                      <IconLoader2 className="size-4 animate-spin" />
+                    ) : (
+                      <IconSend className="size-4" />
+                    )}
+                    {t("pages.sessions.continue")}
+                  </Button>
+                </div>
+              </>
+            ) : (
+              <p className="text-muted-foreground text-xs">
+                {t("pages.sessions.not_continuable")}
+              </p>
+            )}
+          </div>
+        )}
+      </SheetContent>
+    </Sheet>
+  )
+}
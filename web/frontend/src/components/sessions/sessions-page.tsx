@@ -0,0 +1,277 @@
+import {
+  IconChevronLeft,
+  IconChevronRight,
+  IconMessageCircle,
+} from "@tabler/icons-react"
+import { useQuery } from "@tanstack/react-query"
+import dayjs from "dayjs"
+import { useDeferredValue, useEffect, useState } from "react"
+import { useTranslation } from "react-i18next"
+
+import { type BrowsedSession, browseSessions } from "@/api/session-browser"
+import { PageHeader } from "@/components/page-header"
+import { Badge } from "@/components/ui/badge"
+import { Button } from "@/components/ui/button"
+import { Card, CardContent } from "@/components/ui/card"
+import { Input } from "@/components/ui/input"
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "@/components/ui/select"
+import { Skeleton } from "@/components/ui/skeleton"
+
+import { SessionDetailSheet } from "./session-detail-sheet"
+
+const PAGE_SIZE = 20
+const ALL = "all"
+
+interface SessionFilters {
+  q: string
+  channel: string
+  agent: string
+  sender: string
+  since: string
+  until: string
+}
+
+const EMPTY_FILTERS: SessionFilters = {
+  q: "",
+  channel: ALL,
+  agent: ALL,
+  sender: "",
+  since: "",
+  until: "",
+}
+
+export function SessionsPage() {
+  const { t } = useTranslation()
+  const [filters, setFilters] = useState<SessionFilters>(EMPTY_FILTERS)
+  const [offset, setOffset] = useState(0)
+  const [selected, setSelected] = useState<BrowsedSession | null>(null)
+  const deferred = useDeferredValue(filters)
+
+  useEffect(() => {
+    setOffset(0)
+  }, [deferred])
+
+  const sessionsQuery = useQuery({
+    queryKey: ["session-browser", deferred, offset],
+    queryFn: () =>
+      browseSessions({
+        q: deferred.q.trim(),
+        channel: deferred.channel === ALL ? "" : deferred.channel,
+        agent: deferred.agent === ALL ? "" : deferred.agent,
+        sender: deferred.sender.trim(),
+        since: deferred.since,
+        until: deferred.until,
+        offset,
+        limit: PAGE_SIZE,
+      }),
+  })
+
+  const setFilter =
+    (key: keyof SessionFilters) => (e: React.ChangeEvent<HTMLInputElement>) =>
+      setFilters((f) => ({ ...f, [key]: e.target.value }))
+
+  const data = sessionsQuery.data
+  const total = data?.total ?? 0
+
+  return (
+    <div className="flex h-full flex-col">
+      <PageHeader title={t("navigation.sessions")} />
+
+      <div className="flex flex-1 flex-col gap-4 overflow-hidden p-4 sm:p-8">
+        <div className="flex flex-col gap-2">
+          <Input
+            value={filters.q}
+            onChange={setFilter("q")}
+            placeholder={t("pages.sessions.search_placeholder")}
+          />
+          <div className="flex flex-col gap-2 sm:flex-row">
+            <FacetSelect
+              value={filters.channel}
+              options={data?.channels ?? []}
+              allLabel={t("pages.sessions.all_channels")}
+              onChange={(channel) => setFilters((f) => ({ ...f, channel }))}
+            />
+            <FacetSelect
+              value={filters.agent}
+              options={data?.agents ?? []}
+              allLabel={t("pages.sessions.all_agents")}
+              onChange={(agent) => setFilters((f) => ({ ...f, agent }))}
+            />
+            <Input
+              value={filters.sender}
+              onChange={setFilter("sender")}
+              placeholder={t("pages.sessions.filter_sender")}
+            />
+            <Input
+              type="date"
+              value={filters.since}
+              onChange={setFilter("since")}
+              aria-label={t("pages.sessions.since")}
+              className="sm:max-w-40"
+            />
+            <Input
+              type="date"
+              value={filters.until}
+              onChange={setFilter("until")}
+              aria-label={t("pages.sessions.until")}
+              className="sm:max-w-40"
+            />
+          </div>
+          {data?.search_backend && (
+            <p className="text-muted-foreground text-xs">
+              {t(`pages.sessions.search_backend.${data.search_backend}`)}
+            </p>
+          )}
+        </div>
+
+        <div className="flex-1 space-y-3 overflow-auto">
+          {sessionsQuery.isLoading ? (
+            <Skeleton className="h-32 w-full rounded-xl" />
+          ) : sessionsQuery.isError ? (
+            <p className="text-destructive text-sm">
+              {t("pages.sessions.load_error")}
+            </p>
+          ) : data?.sessions.length === 0 ? (
+            <Card>
+              <CardContent className="text-muted-foreground py-10 text-center text-sm">
+                {t("pages.sessions.empty")}
+              </CardContent>
+            </Card>
+          ) : (
+            data?.sessions.map((session) => (
+              <SessionRow
+                key={session.key}
+                session={session}
+                onOpen={() => setSelected(session)}
+              />
+            ))
+          )}
+        </div>
+
+        {total > PAGE_SIZE && (
+          <div className="flex items-center justify-end gap-2 text-sm">
+            <span className="text-muted-foreground">
+              {t("pages.sessions.range", {
+                from: offset + 1,
+                to: Math.min(offset + PAGE_SIZE, total),
+                total,
+              })}
+            </span>
+            <Button
+              variant="outline"
+              size="icon-sm"
+              disabled={offset === 0}
+              onClick={() => setOffset((o) => Math.max(o - PAGE_SIZE, 0))}
+            >
+              <IconChevronLeft className="size-4" />
+            </Button>
+            <Button
+              variant="outline"
+              size="icon-sm"
+              disabled={offset + PAGE_SIZE >= total}
+              onClick={() => setOffset((o) => o + PAGE_SIZE)}
+            >
+              <IconChevronRight className="size-4" />
+            </Button>
+          </div>
+        )}
+      </div>
+
+      <SessionDetailSheet
+        session={selected}
+        onClose={() => setSelected(null)}
+      />
+    </div>
+  )
+}
+
+function FacetSelect({
+  value,
+  options,
+  allLabel,
+  onChange,
+}: {
+  value: string
+  options: string[]
+  allLabel: string
+  onChange: (value: string) => void
+}) {
+  return (
+    <Select value={value} onValueChange={onChange}>
+      <SelectTrigger className="w-full sm:max-w-48">
+        <SelectValue />
+      </SelectTrigger>
+      <SelectContent>
+        <SelectItem value={ALL}>{allLabel}</SelectItem>
+        {options.map((option) => (
+          <SelectItem key={option} value={option}>
+            {option}
+          </SelectItem>
+        ))}
+      </SelectContent>
+    </Select>
+  )
+}
+
+function SessionRow({
+  session,
+  onOpen,
+}: {
+  session: BrowsedSession
+  onOpen: () => void
+}) {
+  const { t } = useTranslation()
+  const who = [session.sender, session.chat_id].filter(Boolean).join(" · ")
+
+  return (
+    <Card
+      className="hover:bg-accent/40 cursor-pointer transition-colors"
+      onClick={onOpen}
+    >
+      <CardContent className="space-y-2">
+        <div className="flex items-start justify-between gap-4">
+          <div className="min-w-0 space-y-1">
+            <div className="truncate font-medium">{session.title}</div>
+            <div className="flex flex-wrap items-center gap-2 text-xs">
+              <Badge variant="secondary">{session.channel || "—"}</Badge>
+              <Badge variant="outline">{session.agent_id}</Badge>
+              {who && (
+                <span className="text-muted-foreground truncate font-mono">
+                  {who}
+                </span>
+              )}
+            </div>
+          </div>
+          <div className="text-muted-foreground shrink-0 text-right text-xs">
+            <div>{dayjs(session.updated).format("YYYY-MM-DD HH:mm")}</div>
+            <div className="flex items-center justify-end gap-1">
+              <IconMessageCircle className="size-3" />
+              {t("pages.sessions.message_count", {
+                count: session.message_count,
+              })}
+            </div>
+          </div>
+        </div>
+        {session.matches?.map((match, index) => (
+          <p
+            key={index}
+            className="bg-muted/60 text-muted-foreground rounded-md px-3 py-2 text-xs"
+          >
+            {match.role && (
+              <span className="text-foreground/80 mr-2 font-medium">
+                {match.role}
+              </span>
+            )}
+            {match.snippet}
+          </p>
+        ))}
+      </CardContent>
+    </Card>
+  )
+}
@@ -0,0 +1,31 @@
+// formatPairs renders a map as one "key<sep>value" pair per line.
+export function formatPairs(
+  pairs: Record<string, string> | undefined,
+  sep: string,
+): string {
+  return Object.entries(pairs ?? {})
+    .map(([key, value]) => `${key}${sep}${value}`)
+    .join("\n")
+}
+
+// parsePairs reads lines written by formatPairs, skipping blank lines and
+// lines without a separator. Keys and values are trimmed.
+export function parsePairs(text: string, sep: string): Record<string, string> {
+  const pairs: Record<string, string> = {}
+  for (const line of text.split("\n")) {
+    const idx = line.indexOf(sep)
+    if (idx <= 0) {
+      continue
+    }
+    pairs[line.slice(0, idx).trim()] = line.slice(idx + sep.length).trim()
+  }
+  return pairs
+}
+
+// parseLines splits text into trimmed, non-empty lines.
+export function parseLines(text: string): string[] {
+  return text
+    .split("\n")
+    .map((line) => line.trim())
+    .filter(Boolean)
+}
@@ -9,6 +9,7 @@
 // Additionally, you should also exclude this file from your linter and/or formatter to prevent it from being checked or modified.
 
 import { Route as rootRouteImport } from './routes/__root'
+import { Route as SessionsRouteImport } from './routes/sessions'
 import { Route as ModelsRouteImport } from './routes/models'
 import { Route as LogsRouteImport } from './routes/logs'
 import { Route as LauncherSetupRouteImport } from './routes/launcher-setup'
@@ -16,14 +17,25 @@ import { Route as LauncherLoginRouteImport } from './routes/launcher-login'
 import { Route as CredentialsRouteImport } from './routes/credentials'
 import { Route as ConfigRouteImport } from './routes/config'
 import { Route as AgentRouteImport } from './routes/agent'
+import { Route as AccountsRouteImport } from './routes/accounts'
 import { Route as ChannelsRouteRouteImport } from './routes/channels/route'
 import { Route as IndexRouteImport } from './routes/index'
 import { Route as ConfigRawRouteImport } from './routes/config.raw'
 import { Route as ChannelsNameRouteImport } from './routes/channels/$name'
 import { Route as AgentToolsRouteImport } from './routes/agent/tools'
 import { Route as AgentSkillsRouteImport } from './routes/agent/skills'
+import { Route as AgentMcpRouteImport } from './routes/agent/mcp'
+import { Route as AgentIdentityLinksRouteImport } from './routes/agent/identity-links'
 import { Route as AgentHubRouteImport } from './routes/agent/hub'
+import { Route as AgentHooksRouteImport } from './routes/agent/hooks'
+import { Route as AgentCronRouteImport } from './routes/agent/cron'
+import { Route as AgentActivityRouteImport } from './routes/agent/activity'
 
+const SessionsRoute = SessionsRouteImport.update({
+  id: '/sessions',
+  path: '/sessions',
+  getParentRoute: () => rootRouteImport,
+} as any)
 const ModelsRoute = ModelsRouteImport.update({
   id: '/models',
   path: '/models',
@@ -59,6 +71,11 @@ const AgentRoute = AgentRouteImport.update({
   path: '/agent',
   getParentRoute: () => rootRouteImport,
 } as any)
+const AccountsRoute = AccountsRouteImport.update({
+  id: '/accounts',
+  path: '/accounts',
+  getParentRoute: () => rootRouteImport,
+} as any)
 const ChannelsRouteRoute = ChannelsRouteRouteImport.update({
   id: '/channels',
   path: '/channels',
@@ -89,23 +106,55 @@ const AgentSkillsRoute = AgentSkillsRouteImport.update({
   path: '/skills',
   getParentRoute: () => AgentRoute,
 } as any)
+const AgentMcpRoute = AgentMcpRouteImport.update({
+  id: '/mcp',
+  path: '/mcp',
+  getParentRoute: () => AgentRoute,
+} as any)
+const AgentIdentityLinksRoute = AgentIdentityLinksRouteImport.update({
+  id: '/identity-links',
+  path: '/identity-links',
+  getParentRoute: () => AgentRoute,
+} as any)
 const AgentHubRoute = AgentHubRouteImport.update({
   id: '/hub',
   path: '/hub',
   getParentRoute: () => AgentRoute,
 } as any)
+const AgentHooksRoute = AgentHooksRouteImport.update({
+  id: '/hooks',
+  path: '/hooks',
+  getParentRoute: () => AgentRoute,
+} as any)
+const AgentCronRoute = AgentCronRouteImport.update({
+  id: '/cron',
+  path: '/cron',
+  getParentRoute: () => AgentRoute,
+} as any)
+const AgentActivityRoute = AgentActivityRouteImport.update({
+  id: '/activity',
+  path: '/activity',
+  getParentRoute: () => AgentRoute,
+} as any)
 
 export interface FileRoutesByFullPath {
   '/': typeof IndexRoute
   '/channels': typeof ChannelsRouteRouteWithChildren
   '/agent': typeof AgentRouteWithChildren
+  '/accounts': typeof AccountsRoute
   '/config': typeof ConfigRouteWithChildren
   '/credentials': typeof CredentialsRoute
   '/launcher-login': typeof LauncherLoginRoute
   '/launcher-setup': typeof LauncherSetupRoute
   '/logs': typeof LogsRoute
   '/models': typeof ModelsRoute
+  '/sessions': typeof SessionsRoute
+  '/agent/activity': typeof AgentActivityRoute
+  '/agent/cron': typeof AgentCronRoute
+  '/agent/hooks': typeof AgentHooksRoute
   '/agent/hub': typeof AgentHubRoute
+  '/agent/identity-links': typeof AgentIdentityLinksRoute
+  '/agent/mcp': typeof AgentMcpRoute
   '/agent/skills': typeof AgentSkillsRoute
   '/agent/tools': typeof AgentToolsRoute
   '/channels/$name': typeof ChannelsNameRoute
@@ -115,13 +164,20 @@ export interface FileRoutesByTo {
   '/': typeof IndexRoute
   '/channels': typeof ChannelsRouteRouteWithChildren
   '/agent': typeof AgentRouteWithChildren
+  '/accounts': typeof AccountsRoute
   '/config': typeof ConfigRouteWithChildren
   '/credentials': typeof CredentialsRoute
   '/launcher-login': typeof LauncherLoginRoute
   '/launcher-setup': typeof LauncherSetupRoute
   '/logs': typeof LogsRoute
   '/models': typeof ModelsRoute
+  '/sessions': typeof SessionsRoute
+  '/agent/activity': typeof AgentActivityRoute
+  '/agent/cron': typeof AgentCronRoute
+  '/agent/hooks': typeof AgentHooksRoute
   '/agent/hub': typeof AgentHubRoute
+  '/agent/identity-links': typeof AgentIdentityLinksRoute
+  '/agent/mcp': typeof AgentMcpRoute
   '/agent/skills': typeof AgentSkillsRoute
   '/agent/tools': typeof AgentToolsRoute
   '/channels/$name': typeof ChannelsNameRoute
@@ -132,13 +188,20 @@ export interface FileRoutesById {
   '/': typeof IndexRoute
   '/channels': typeof ChannelsRouteRouteWithChildren
   '/agent': typeof AgentRouteWithChildren
+  '/accounts': typeof AccountsRoute
   '/config': typeof ConfigRouteWithChildren
   '/credentials': typeof CredentialsRoute
   '/launcher-login': typeof LauncherLoginRoute
   '/launcher-setup': typeof LauncherSetupRoute
   '/logs': typeof LogsRoute
   '/models': typeof ModelsRoute
+  '/sessions': typeof SessionsRoute
+  '/agent/activity': typeof AgentActivityRoute
+  '/agent/cron': typeof AgentCronRoute
+  '/agent/hooks': typeof AgentHooksRoute
   '/agent/hub': typeof AgentHubRoute
+  '/agent/identity-links': typeof AgentIdentityLinksRoute
+  '/agent/mcp': typeof AgentMcpRoute
   '/agent/skills': typeof AgentSkillsRoute
   '/agent/tools': typeof AgentToolsRoute
   '/channels/$name': typeof ChannelsNameRoute
@@ -150,13 +213,20 @@ export interface FileRouteTypes {
     | '/'
     | '/channels'
     | '/agent'
+    | '/accounts'
     | '/config'
     | '/credentials'
     | '/launcher-login'
     | '/launcher-setup'
     | '/logs'
     | '/models'
+    | '/sessions'
+    | '/agent/activity'
+    | '/agent/cron'
+    | '/agent/hooks'
     | '/agent/hub'
+    | '/agent/identity-links'
+    | '/agent/mcp'
     | '/agent/skills'
     | '/agent/tools'
     | '/channels/$name'
@@ -166,13 +236,20 @@ export interface FileRouteTypes {
     | '/'
     | '/channels'
     | '/agent'
+    | '/accounts'
     | '/config'
     | '/credentials'
     | '/launcher-login'
     | '/launcher-setup'
     | '/logs'
     | '/models'
+    | '/sessions'
+    | '/agent/activity'
+    | '/agent/cron'
+    | '/agent/hooks'
     | '/agent/hub'
+    | '/agent/identity-links'
+    | '/agent/mcp'
     | '/agent/skills'
     | '/agent/tools'
     | '/channels/$name'
@@ -182,13 +259,20 @@ export interface FileRouteTypes {
     | '/'
     | '/channels'
     | '/agent'
+    | '/accounts'
     | '/config'
     | '/credentials'
     | '/launcher-login'
     | '/launcher-setup'
     | '/logs'
     | '/models'
+    | '/sessions'
+    | '/agent/activity'
+    | '/agent/cron'
+    | '/agent/hooks'
     | '/agent/hub'
+    | '/agent/identity-links'
+    | '/agent/mcp'
     | '/agent/skills'
     | '/agent/tools'
     | '/channels/$name'
@@ -199,16 +283,25 @@ export interface RootRouteChildren {
   IndexRoute: typeof IndexRoute
   ChannelsRouteRoute: typeof ChannelsRouteRouteWithChildren
   AgentRoute: typeof AgentRouteWithChildren
+  AccountsRoute: typeof AccountsRoute
   ConfigRoute: typeof ConfigRouteWithChildren
   CredentialsRoute: typeof CredentialsRoute
   LauncherLoginRoute: typeof LauncherLoginRoute
   LauncherSetupRoute: typeof LauncherSetupRoute
   LogsRoute: typeof LogsRoute
   ModelsRoute: typeof ModelsRoute
+  SessionsRoute: typeof SessionsRoute
 }
 
 declare module '@tanstack/react-router' {
   interface FileRoutesByPath {
+    '/sessions': {
+      id: '/sessions'
+      path: '/sessions'
+      fullPath: '/sessions'
+      preLoaderRoute: typeof SessionsRouteImport
+      parentRoute: typeof rootRouteImport
+    }
     '/models': {
       id: '/models'
       path: '/models'
@@ -258,6 +351,13 @@ declare module '@tanstack/react-router' {
       preLoaderRoute: typeof AgentRouteImport
       parentRoute: typeof rootRouteImport
     }
+    '/accounts': {
+      id: '/accounts'
+      path: '/accounts'
+      fullPath: '/accounts'
+      preLoaderRoute: typeof AccountsRouteImport
+      parentRoute: typeof rootRouteImport
+    }
     '/channels': {
       id: '/channels'
       path: '/channels'
@@ -300,6 +400,20 @@ declare module '@tanstack/react-router' {
       preLoaderRoute: typeof AgentSkillsRouteImport
       parentRoute: typeof AgentRoute
     }
+    '/agent/mcp': {
+      id: '/agent/mcp'
+      path: '/mcp'
+      fullPath: '/agent/mcp'
+      preLoaderRoute: typeof AgentMcpRouteImport
+      parentRoute: typeof AgentRoute
+    }
+    '/agent/identity-links': {
+      id: '/agent/identity-links'
+      path: '/identity-links'
+      fullPath: '/agent/identity-links'
+      preLoaderRoute: typeof AgentIdentityLinksRouteImport
+      parentRoute: typeof AgentRoute
+    }
     '/agent/hub': {
       id: '/agent/hub'
       path: '/hub'
@@ -307,6 +421,27 @@ declare module '@tanstack/react-router' {
       preLoaderRoute: typeof AgentHubRouteImport
       parentRoute: typeof AgentRoute
     }
+    '/agent/hooks': {
+      id: '/agent/hooks'
+      path: '/hooks'
+      fullPath: '/agent/hooks'
+      preLoaderRoute: typeof AgentHooksRouteImport
+      parentRoute: typeof AgentRoute
+    }
+    '/agent/cron': {
+      id: '/agent/cron'
+      path: '/cron'
+      fullPath: '/agent/cron'
+      preLoaderRoute: typeof AgentCronRouteImport
+      parentRoute: typeof AgentRoute
+    }
+    '/agent/activity': {
+      id: '/agent/activity'
+      path: '/activity'
+      fullPath: '/agent/activity'
+      preLoaderRoute: typeof AgentActivityRouteImport
+      parentRoute: typeof AgentRoute
+    }
   }
 }
 
@@ -323,13 +458,23 @@ const ChannelsRouteRouteWithChildren = ChannelsRouteRoute._addFileChildren(
 )
 
 interface AgentRouteChildren {
+  AgentActivityRoute: typeof AgentActivityRoute
+  AgentCronRoute: typeof AgentCronRoute
+  AgentHooksRoute: typeof AgentHooksRoute
   AgentHubRoute: typeof AgentHubRoute
+  AgentIdentityLinksRoute: typeof AgentIdentityLinksRoute
+  AgentMcpRoute: typeof AgentMcpRoute
   AgentSkillsRoute: typeof AgentSkillsRoute
   AgentToolsRoute: typeof AgentToolsRoute
 }
 
 const AgentRouteChildren: AgentRouteChildren = {
+  AgentActivityRoute: AgentActivityRoute,
+  AgentCronRoute: AgentCronRoute,
+  AgentHooksRoute: AgentHooksRoute,
   AgentHubRoute: AgentHubRoute,
+  AgentIdentityLinksRoute: AgentIdentityLinksRoute,
+  AgentMcpRoute: AgentMcpRoute,
   AgentSkillsRoute: AgentSkillsRoute,
   AgentToolsRoute: AgentToolsRoute,
 }
@@ -351,12 +496,14 @@ const rootRouteChildren: RootRouteChildren = {
   IndexRoute: IndexRoute,
   ChannelsRouteRoute: ChannelsRouteRouteWithChildren,
   AgentRoute: AgentRouteWithChildren,
+  AccountsRoute: AccountsRoute,
   ConfigRoute: ConfigRouteWithChildren,
   CredentialsRoute: CredentialsRoute,
   LauncherLoginRoute: LauncherLoginRoute,
   LauncherSetupRoute: LauncherSetupRoute,
   LogsRoute: LogsRoute,
   ModelsRoute: ModelsRoute,
+  SessionsRoute: SessionsRoute,
 }
 export const routeTree = rootRouteImport
   ._addFileChildren(rootRouteChildren)
@@ -0,0 +1,7 @@
+import { createFileRoute } from "@tanstack/react-router"
+
+import { AccountsPage } from "@/components/accounts/accounts-page"
+
+export const Route = createFileRoute("/accounts")({
+  component: AccountsPage,
+})
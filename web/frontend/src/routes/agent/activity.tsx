@@ -0,0 +1,11 @@
+import { createFileRoute } from "@tanstack/react-router"
+
+import { ActivityPage } from "@/components/agent/activity/activity-page"
+
+export const Route = createFileRoute("/agent/activity")({
+  component: AgentActivityRoute,
+})
+
+function AgentActivityRoute() {
+  return <ActivityPage />
+}
@@ -0,0 +1,11 @@
+import { createFileRoute } from "@tanstack/react-router"
+
+import { CronPage } from "@/components/agent/cron/cron-page"
+
+export const Route = createFileRoute("/agent/cron")({
+  component: AgentCronRoute,
+})
+
+function AgentCronRoute() {
+  return <CronPage />
+}
@@ -0,0 +1,11 @@
+import { createFileRoute } from "@tanstack/react-router"
+
+import { HooksPage } from "@/components/agent/hooks/hooks-page"
+
+export const Route = createFileRoute("/agent/hooks")({
+  component: AgentHooksRoute,
+})
+
+function AgentHooksRoute() {
+  return <HooksPage />
+}
@@ -0,0 +1,11 @@
+import { createFileRoute } from "@tanstack/react-router"
+
+import { IdentityLinksPage } from "@/components/agent/identity-links/identity-links-page"
+
+export const Route = createFileRoute("/agent/identity-links")({
+  component: AgentIdentityLinksRoute,
+})
+
+function AgentIdentityLinksRoute() {
+  return <IdentityLinksPage />
+}
@@ -4,6 +4,7 @@ import * as React from "react"
 import { useTranslation } from "react-i18next"
 
 import {
+  type LoginCredentials,
   getLauncherAuthStatus,
   postLauncherDashboardLogin,
 } from "@/api/launcher-auth"
@@ -28,7 +29,11 @@ import { useTheme } from "@/hooks/use-theme"
 function LauncherLoginPage() {
   const { t, i18n } = useTranslation()
   const { theme, toggleTheme } = useTheme()
+  const [username, setUsername] = React.useState("")
   const [password, setPassword] = React.useState("")
+  const [totpCode, setTotpCode] = React.useState("")
+  const [multiUser, setMultiUser] = React.useState(false)
+  const [totpRequired, setTotpRequired] = React.useState(false)
   const [submitting, setSubmitting] = React.useState(false)
   const [error, setError] = React.useState("")
 
@@ -38,7 +43,9 @@ function LauncherLoginPage() {
       .then((s) => {
         if (!s.initialized) {
           globalThis.location.assign("/launcher-setup")
+          return
         }
+        setMultiUser(s.multi_user)
       })
       .catch(() => {
         /* network error — stay on login page */
@@ -46,11 +53,11 @@ function LauncherLoginPage() {
   }, [])
 
   const loginWithPassword = React.useCallback(
-    async (passwordValue: string) => {
+    async (credentials: LoginCredentials) => {
       setError("")
       setSubmitting(true)
       try {
-        const result = await postLauncherDashboardLogin(passwordValue)
+        const result = await postLauncherDashboardLogin(credentials)
         if (result.ok) {
           globalThis.location.assign("/")
           return
@@ -59,6 +66,13 @@ function LauncherLoginPage() {
           globalThis.location.assign("/launcher-setup")
           return
         }
+        if (result.status === 401 && result.totpRequired) {
+          setTotpRequired(true)
+          if (credentials.totpCode) {
+            setError(t("launcherLogin.errorTotp"))
+          }
+          return
+        }
         if (result.status === 401) {
           setError(t("launcherLogin.errorInvalid"))
           return
@@ -75,7 +89,7 @@ function LauncherLoginPage() {
 
   const onSubmit = async (e: React.FormEvent<HTMLFormElement>) => {
     e.preventDefault()
-    await loginWithPassword(password)
+    await loginWithPassword({ username, password, totpCode })
   }
 
   return (
@@ -119,6 +133,21 @@ function LauncherLoginPage() {
           </CardHeader>
           <CardContent>
             <form className="flex flex-col gap-4" onSubmit={onSubmit}>
+              {multiUser ? (
+                <div className="flex flex-col gap-2">
+                  <Label htmlFor="launcher-username">
+                    {t("launcherLogin.usernameLabel")}
+                  </Label>
+                  <Input
+                    id="launcher-username"
+                    name="username"
+                    autoComplete="username"
+                    value={username}
+                    onChange={(e) => setUsername(e.target.value)}
+                    placeholder={t("launcherLogin.usernamePlaceholder")}
+                  />
+                </div>
+              ) : null}
               <div className="flex flex-col gap-2">
                 <Label htmlFor="launcher-password">
                   {t("launcherLogin.passwordLabel")}
@@ -134,6 +163,24 @@ function LauncherLoginPage() {
                   placeholder={t("launcherLogin.passwordPlaceholder")}
                 />
               </div>
+              {totpRequired ? (
+                <div className="flex flex-col gap-2">
+                  <Label htmlFor="launcher-totp">
+                    {t("launcherLogin.totpLabel")}
+                  </Label>
+                  <Input
+                    id="launcher-totp"
+                    name="totp"
+                    inputMode="numeric"
+                    autoComplete="one-time-code"
+                    autoFocus
+                    required
+                    value={totpCode}
+                    onChange={(e) => setTotpCode(e.target.value)}
+                    placeholder="123456"
+                  />
+                </div>
+              ) : null}
               <Button type="submit" disabled={submitting}>
                 {submitting ? t("labels.loading") : t("launcherLogin.submit")}
               </Button>